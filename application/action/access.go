@@ -0,0 +1,47 @@
+package action
+
+import (
+	"errors"
+	"fmt"
+
+	permissionDto "github.com/labbs/nexo/application/permission/dto"
+	"github.com/labbs/nexo/domain"
+	"github.com/labbs/nexo/infrastructure/helpers/apperrors"
+)
+
+// checkScopeAccess verifies that the user can read the space and the database
+// an action is scoped to, so that nobody triggers actions on the events of
+// resources they cannot see. Unknown resources are denied the same way.
+func (app *ActionApplication) checkScopeAccess(spaceId, databaseId *string, userId string) error {
+	if spaceId != nil {
+		if err := app.checkReadAccess(domain.PermissionTypeSpace, *spaceId, userId); err != nil {
+			return err
+		}
+	}
+	if databaseId != nil {
+		if err := app.checkReadAccess(domain.PermissionTypeDatabase, *databaseId, userId); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (app *ActionApplication) checkReadAccess(resourceType domain.PermissionType, resourceId, userId string) error {
+	result, err := app.PermissionApplication.GetEffectivePermission(permissionDto.GetEffectivePermissionInput{
+		RequesterId:  userId,
+		ResourceType: string(resourceType),
+		ResourceId:   resourceId,
+	})
+	if errors.Is(err, apperrors.ErrSpaceNotFound) || errors.Is(err, apperrors.ErrDatabaseNotFound) {
+		return apperrors.ErrAccessDenied
+	}
+	if err != nil {
+		return fmt.Errorf("failed to resolve %s permission: %w", resourceType, err)
+	}
+
+	role := result.Permission.Role
+	if role == nil || !role.Includes(domain.PermissionRoleViewer) {
+		return apperrors.ErrAccessDenied
+	}
+	return nil
+}
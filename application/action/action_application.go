@@ -1,23 +1,42 @@
 package action
 
 import (
+	"sync"
+
+	"github.com/go-co-op/gocron/v2"
+	"github.com/labbs/nexo/application/ports"
 	"github.com/labbs/nexo/domain"
 	"github.com/labbs/nexo/infrastructure/config"
 	"github.com/rs/zerolog"
 )
 
 type ActionApplication struct {
-	Config        config.Config
-	Logger        zerolog.Logger
-	ActionPers    domain.ActionPers
-	ActionRunPers domain.ActionRunPers
+	Config              config.Config
+	Logger              zerolog.Logger
+	ActionPers          domain.ActionPers
+	ActionRunPers       domain.ActionRunPers
+	DatabaseApplication ports.DatabasePort
+	DocumentApplication ports.DocumentPort
+	WebhookApplication  ports.WebhookPort
+	// PermissionApplication checks the access of the owners to the resources
+	PermissionApplication ports.PermissionPort
+	// Scheduler runs the actions triggered by a schedule
+	Scheduler gocron.Scheduler
+
+	stepExecutors map[domain.ActionStepType]StepExecutor
+	// running holds the ids of the scheduled actions being executed, see
+	// runScheduledAction
+	running sync.Map
 }
 
 func NewActionApplication(config config.Config, logger zerolog.Logger, actionPers domain.ActionPers, actionRunPers domain.ActionRunPers) *ActionApplication {
-	return &ActionApplication{
+	app := &ActionApplication{
 		Config:        config,
 		Logger:        logger,
 		ActionPers:    actionPers,
 		ActionRunPers: actionRunPers,
+		stepExecutors: make(map[domain.ActionStepType]StepExecutor),
 	}
+	app.registerDefaultStepExecutors()
+	return app
 }
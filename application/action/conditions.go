@@ -0,0 +1,253 @@
+package action
+
+import (
+	"cmp"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/labbs/nexo/domain"
+)
+
+// Conditions reuse the filter vocabulary of database views (domain.FilterConfig):
+// every "and" rule must match and, when there are "or" rules, at least one of
+// them must match; a rule with "and"/"or" rules is a nested group. The property of a rule is a path (see lookupPath), e.g.
+// "changes.name.to" or "steps[0].result.row_id". A path that does not exist
+// is evaluated as an empty value.
+
+// parseConditions decodes a {"and": [...], "or": [...]} config value
+func parseConditions(value any) (*domain.FilterConfig, error) {
+	if value == nil {
+		return nil, nil
+	}
+	var filter domain.FilterConfig
+	if err := decodeConfigValue(value, &filter); err != nil {
+		return nil, fmt.Errorf("invalid conditions: %w", err)
+	}
+	return &filter, nil
+}
+
+// matchConditions evaluates conditions against data. Nil conditions always match.
+func matchConditions(filter *domain.FilterConfig, data map[string]any) (bool, error) {
+	if filter == nil {
+		return true, nil
+	}
+	return matchGroup(filter.And, filter.Or, data)
+}
+
+// matchGroup evaluates a group of rules, rules may themselves be groups
+func matchGroup(and, or []domain.FilterRule, data map[string]any) (bool, error) {
+	for _, rule := range and {
+		ok, err := matchRule(rule, data)
+		if err != nil || !ok {
+			return false, err
+		}
+	}
+
+	if len(or) == 0 {
+		return true, nil
+	}
+	for _, rule := range or {
+		ok, err := matchRule(rule, data)
+		if err != nil {
+			return false, err
+		}
+		if ok {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func matchRule(rule domain.FilterRule, data map[string]any) (bool, error) {
+	if rule.IsGroup() {
+		return matchGroup(rule.And, rule.Or, data)
+	}
+
+	actual, err := lookupPath(rule.Property, data)
+	if err != nil {
+		actual = nil
+	}
+
+	switch rule.Condition {
+	case "eq":
+		return valuesMatch(actual, rule.Value), nil
+	case "neq":
+		return !valuesMatch(actual, rule.Value), nil
+	case "gt", "lt", "gte", "lte":
+		cmp, ok := compareValues(actual, rule.Value)
+		if !ok {
+			return false, nil
+		}
+		switch rule.Condition {
+		case "gt":
+			return cmp > 0, nil
+		case "lt":
+			return cmp < 0, nil
+		case "gte":
+			return cmp >= 0, nil
+		default:
+			return cmp <= 0, nil
+		}
+	case "contains":
+		return containsValue(actual, rule.Value), nil
+	case "not_contains":
+		return !containsValue(actual, rule.Value), nil
+	case "starts_with":
+		return actual != nil && strings.HasPrefix(strings.ToLower(templateText(actual)), strings.ToLower(templateText(rule.Value))), nil
+	case "ends_with":
+		return actual != nil && strings.HasSuffix(strings.ToLower(templateText(actual)), strings.ToLower(templateText(rule.Value))), nil
+	case domain.FilterIsAnyOf, domain.FilterIsNoneOf, domain.FilterContainsAny, domain.FilterContainsAll:
+		expected, _ := rule.Value.([]any)
+		matched := 0
+		for _, value := range expected {
+			if valuesMatch(actual, value) {
+				matched++
+			}
+		}
+		switch rule.Condition {
+		case domain.FilterIsNoneOf:
+			return matched == 0, nil
+		case domain.FilterContainsAll:
+			return len(expected) > 0 && matched == len(expected), nil
+		default:
+			return matched > 0, nil
+		}
+	case domain.FilterBetween:
+		bounds, ok := rule.Value.([]any)
+		if !ok || len(bounds) != 2 {
+			return false, fmt.Errorf("between expects a [from, to] range on %q", rule.Property)
+		}
+		if c, ok := compareValues(actual, bounds[0]); bounds[0] != nil && (!ok || c < 0) {
+			return false, nil
+		}
+		if c, ok := compareValues(actual, bounds[1]); bounds[1] != nil && (!ok || c > 0) {
+			return false, nil
+		}
+		return actual != nil, nil
+	case domain.FilterBefore, domain.FilterAfter, domain.FilterOnOrBefore, domain.FilterOnOrAfter:
+		c, ok := compareValues(actual, rule.Value)
+		if !ok {
+			return false, nil
+		}
+		switch rule.Condition {
+		case domain.FilterBefore:
+			return c < 0, nil
+		case domain.FilterAfter:
+			return c > 0, nil
+		case domain.FilterOnOrBefore:
+			return c <= 0, nil
+		default:
+			return c >= 0, nil
+		}
+	case domain.FilterRelative:
+		date, ok := parseDate(actual)
+		if !ok {
+			return false, nil
+		}
+		start, end, ok := domain.RelativeDateRange(templateText(rule.Value), time.Now())
+		if !ok {
+			return false, fmt.Errorf("unknown relative date %q on %q", templateText(rule.Value), rule.Property)
+		}
+		return !date.Before(start) && date.Before(end), nil
+	case domain.FilterIsChecked:
+		return actual == true || actual == "true", nil
+	case domain.FilterIsNotChecked:
+		return actual != true && actual != "true", nil
+	case "is_empty":
+		return isEmptyCondition(actual), nil
+	case "is_not_empty":
+		return !isEmptyCondition(actual), nil
+	default:
+		return false, fmt.Errorf("unsupported condition %q on %q", rule.Condition, rule.Property)
+	}
+}
+
+// valuesMatch compares two values; numbers are compared numerically and
+// lists (e.g. multi_select values) match when one of their items does.
+func valuesMatch(actual, expected any) bool {
+	if items, ok := actual.([]any); ok {
+		for _, item := range items {
+			if valuesMatch(item, expected) {
+				return true
+			}
+		}
+		return false
+	}
+	if actual == nil || expected == nil {
+		return actual == nil && expected == nil
+	}
+	if a, ok := toNumber(actual); ok {
+		if b, ok := toNumber(expected); ok {
+			return a == b
+		}
+	}
+	return templateText(actual) == templateText(expected)
+}
+
+// compareValues compares numbers, then dates (RFC 3339 or YYYY-MM-DD), then strings
+func compareValues(actual, expected any) (int, bool) {
+	if actual == nil || expected == nil {
+		return 0, false
+	}
+	if a, ok := toNumber(actual); ok {
+		if b, ok := toNumber(expected); ok {
+			return cmp.Compare(a, b), true
+		}
+	}
+	if a, ok := parseDate(actual); ok {
+		if b, ok := parseDate(expected); ok {
+			return a.Compare(b), true
+		}
+	}
+	return strings.Compare(templateText(actual), templateText(expected)), true
+}
+
+// parseDate parses an RFC 3339 date or a YYYY-MM-DD day (midnight UTC)
+func parseDate(value any) (time.Time, bool) {
+	text := templateText(value)
+	if date, err := time.Parse(time.RFC3339, text); err == nil {
+		return date, true
+	}
+	date, err := time.Parse(time.DateOnly, text)
+	return date, err == nil
+}
+
+func containsValue(actual, expected any) bool {
+	if actual == nil {
+		return false
+	}
+	if items, ok := actual.([]any); ok {
+		return valuesMatch(items, expected)
+	}
+	return strings.Contains(strings.ToLower(templateText(actual)), strings.ToLower(templateText(expected)))
+}
+
+func isEmptyCondition(value any) bool {
+	if isEmptyValue(value) {
+		return true
+	}
+	v := reflect.ValueOf(value)
+	switch v.Kind() {
+	case reflect.Slice, reflect.Map:
+		return v.Len() == 0
+	}
+	return false
+}
+
+func toNumber(value any) (float64, bool) {
+	switch v := value.(type) {
+	case float64:
+		return v, true
+	case int:
+		return float64(v), true
+	case int64:
+		return float64(v), true
+	case string:
+		f, err := strconv.ParseFloat(strings.TrimSpace(v), 64)
+		return f, err == nil
+	}
+	return 0, false
+}
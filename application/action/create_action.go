@@ -11,12 +11,20 @@ import (
 )
 
 func (app *ActionApplication) CreateAction(input dto.CreateActionInput) (*dto.CreateActionOutput, error) {
+	if err := validateTriggerConfig(domain.ActionTriggerType(input.TriggerType), input.TriggerConfig); err != nil {
+		return nil, err
+	}
+
+	if err := app.checkScopeAccess(input.SpaceId, input.DatabaseId, input.UserId); err != nil {
+		return nil, err
+	}
+
 	// Build steps JSONB
 	stepsJSON, err := json.Marshal(input.Steps)
 	if err != nil {
 		return nil, fmt.Errorf("failed to marshal steps: %w", err)
 	}
-	var steps domain.JSONB
+	var steps domain.JSONBArray
 	json.Unmarshal(stepsJSON, &steps)
 
 	// Build trigger config JSONB
@@ -44,6 +52,10 @@ func (app *ActionApplication) CreateAction(input dto.CreateActionInput) (*dto.Cr
 		return nil, fmt.Errorf("failed to create action: %w", err)
 	}
 
+	if err := app.scheduleAction(*action); err != nil {
+		app.Logger.Error().Err(err).Str("component", "application.action.create_action").Str("action_id", action.Id).Msg("failed to schedule action")
+	}
+
 	return &dto.CreateActionOutput{
 		Id:          action.Id,
 		Name:        action.Name,
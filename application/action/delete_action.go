@@ -21,5 +21,7 @@ func (app *ActionApplication) DeleteAction(input dto.DeleteActionInput) error {
 		return fmt.Errorf("failed to delete action: %w", err)
 	}
 
+	app.unscheduleAction(input.ActionId)
+
 	return nil
 }
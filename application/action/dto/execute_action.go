@@ -5,4 +5,8 @@ type ExecuteActionInput struct {
 	SpaceId     *string
 	DatabaseId  *string
 	TriggerData map[string]any
+
+	// CanRead reports whether the owner of an action can read the resource
+	// of the event. A nil function allows every action.
+	CanRead func(userId string) bool
 }
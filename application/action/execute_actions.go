@@ -1,8 +1,8 @@
 package action
 
 import (
-	"encoding/json"
 	"fmt"
+	"slices"
 	"time"
 
 	"github.com/google/uuid"
@@ -10,6 +10,9 @@ import (
 	"github.com/labbs/nexo/domain"
 )
 
+// maxActionChain is the number of actions that can trigger each other in a row
+const maxActionChain = 5
+
 // ExecuteActions triggers all matching actions for an event
 func (app *ActionApplication) ExecuteActions(input dto.ExecuteActionInput) {
 	logger := app.Logger.With().Str("component", "action.execute").Str("trigger", input.TriggerType).Logger()
@@ -20,20 +23,49 @@ func (app *ActionApplication) ExecuteActions(input dto.ExecuteActionInput) {
 		return
 	}
 
+	// The changes made by the steps of an action carry the chain of actions
+	// that led to them, so that an action whose steps trigger it again,
+	// directly (e.g. create_document on document.created) or through other
+	// actions, is skipped rather than looping forever.
+	chain, _ := input.TriggerData["action_chain"].([]string)
+
 	for _, action := range actions {
 		a := action
+		if input.CanRead != nil && !input.CanRead(a.UserId) {
+			logger.Debug().Str("action_id", a.Id).Msg("action owner cannot read the resource, skipping")
+			continue
+		}
+		matched, err := app.matchesTrigger(a, input.TriggerData, newScope(a, input.TriggerData, time.Now()))
+		if err != nil {
+			logger.Warn().Err(err).Str("action_id", a.Id).Msg("failed to evaluate trigger conditions")
+			_ = app.ActionPers.RecordFailure(a.Id, fmt.Sprintf("trigger conditions: %s", err))
+			continue
+		}
+		if !matched {
+			continue
+		}
+		if slices.Contains(chain, a.Id) {
+			logger.Warn().Str("action_id", a.Id).Strs("action_chain", chain).Msg("action triggered by its own steps, skipping trigger")
+			continue
+		}
+		if len(chain) >= maxActionChain {
+			logger.Warn().Str("action_id", a.Id).Strs("action_chain", chain).Msg("too many chained actions, skipping trigger")
+			continue
+		}
 		go func() {
 			defer func() {
 				if r := recover(); r != nil {
 					app.Logger.Error().Any("panic", r).Str("action_id", a.Id).Msg("action execution panicked")
 				}
 			}()
-			app.executeAction(a, input.TriggerData)
+			app.executeAction(a, input.TriggerData, append(slices.Clone(chain), a.Id))
 		}()
 	}
 }
 
-func (app *ActionApplication) executeAction(action domain.Action, triggerData map[string]any) {
+// executeAction runs the steps of an action. chain lists the actions that led
+// to the run, ending with this one.
+func (app *ActionApplication) executeAction(action domain.Action, triggerData map[string]any, chain []string) {
 	logger := app.Logger.With().
 		Str("component", "action.execute").
 		Str("action_id", action.Id).
@@ -45,37 +77,15 @@ func (app *ActionApplication) executeAction(action domain.Action, triggerData ma
 	// Update last run
 	_ = app.ActionPers.UpdateLastRun(action.Id)
 
-	// Parse steps
-	var steps []dto.ActionStep
-	if action.Steps != nil {
-		stepsJSON, err := json.Marshal(action.Steps)
-		if err != nil {
-			logger.Error().Err(err).Msg("failed to marshal action steps")
-			return
-		}
-		if err := json.Unmarshal(stepsJSON, &steps); err != nil {
-			logger.Error().Err(err).Msg("failed to unmarshal action steps")
-			return
-		}
-	}
-
-	// Execute each step
-	stepsResult := make([]map[string]any, len(steps))
-	var execError error
-
-	for i, step := range steps {
-		result, err := app.executeStep(step, triggerData)
-		stepsResult[i] = map[string]any{
-			"step":    i + 1,
-			"type":    step.Type,
-			"success": err == nil,
-			"result":  result,
-		}
-		if err != nil {
-			stepsResult[i]["error"] = err.Error()
-			execError = err
-			break // Stop on first error
-		}
+	var stepsResult []map[string]any
+	steps, execError := parseSteps(action.Steps)
+	if execError == nil {
+		stepsResult, execError = app.runSteps(&stepRun{
+			action:      action,
+			triggerData: triggerData,
+			scope:       newScope(action, triggerData, start),
+			chain:       chain,
+		}, steps, "steps")
 	}
 
 	duration := int(time.Since(start).Milliseconds())
@@ -104,26 +114,3 @@ func (app *ActionApplication) executeAction(action domain.Action, triggerData ma
 		logger.Error().Err(err).Msg("failed to record action run")
 	}
 }
-
-func (app *ActionApplication) executeStep(step dto.ActionStep, triggerData map[string]any) (any, error) {
-	// This is a simplified implementation - in production, you'd have actual step executors
-	switch domain.ActionStepType(step.Type) {
-	case domain.StepSendWebhook:
-		// Would call webhook service here
-		return map[string]string{"status": "webhook_sent"}, nil
-	case domain.StepSendEmail:
-		// Would call email service here
-		return map[string]string{"status": "email_sent"}, nil
-	case domain.StepSendSlack:
-		// Would call Slack API here
-		return map[string]string{"status": "slack_sent"}, nil
-	case domain.StepUpdateProperty:
-		// Would update property in database
-		return map[string]string{"status": "property_updated"}, nil
-	case domain.StepAddComment:
-		// Would add comment
-		return map[string]string{"status": "comment_added"}, nil
-	default:
-		return nil, fmt.Errorf("unsupported step type: %s", step.Type)
-	}
-}
@@ -0,0 +1,141 @@
+package action
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/labbs/nexo/application/action/dto"
+	"github.com/labbs/nexo/domain"
+)
+
+// stepRun holds the state shared by the steps of one action run
+type stepRun struct {
+	action      domain.Action
+	triggerData map[string]any
+	// scope is the evaluation context of templates and conditions
+	scope map[string]any
+	chain []string
+}
+
+// newScope returns the evaluation context of an action run: the trigger data,
+// the action, the run time, and the results of the steps executed so far
+// ("steps" for the top-level steps, "branch" for the steps of the current if branch).
+func newScope(action domain.Action, triggerData map[string]any, now time.Time) map[string]any {
+	return map[string]any{
+		"trigger": triggerData,
+		"steps":   []any{},
+		"action": map[string]any{
+			"id":          action.Id,
+			"name":        action.Name,
+			"user_id":     action.UserId,
+			"space_id":    stringOrNil(action.SpaceId),
+			"database_id": stringOrNil(action.DatabaseId),
+		},
+		"now": now.UTC().Format(time.RFC3339),
+	}
+}
+
+func parseSteps(value any) ([]dto.ActionStep, error) {
+	var steps []dto.ActionStep
+	if value == nil {
+		return steps, nil
+	}
+	if err := decodeConfigValue(value, &steps); err != nil {
+		return nil, fmt.Errorf("invalid steps: %w", err)
+	}
+	return steps, nil
+}
+
+// runSteps executes steps in order, stopping at the first failure. Each
+// result is appended to scope[key] so that later steps can refer to it.
+func (app *ActionApplication) runSteps(run *stepRun, steps []dto.ActionStep, key string) ([]map[string]any, error) {
+	previous, hadPrevious := run.scope[key]
+	run.scope[key] = []any{}
+	if key != "steps" {
+		defer func() {
+			if hadPrevious {
+				run.scope[key] = previous
+			} else {
+				delete(run.scope, key)
+			}
+		}()
+	}
+
+	results := make([]map[string]any, 0, len(steps))
+	for i, step := range steps {
+		result, err := app.runStep(run, step)
+
+		stepResult := map[string]any{
+			"step":    i + 1,
+			"type":    step.Type,
+			"success": err == nil,
+			"result":  result,
+		}
+		results = append(results, stepResult)
+		if err != nil {
+			stepResult["error"] = err.Error()
+			return results, fmt.Errorf("step %d (%s): %w", i+1, step.Type, err)
+		}
+		run.scope[key] = append(run.scope[key].([]any), stepResult)
+	}
+
+	return results, nil
+}
+
+func (app *ActionApplication) runStep(run *stepRun, step dto.ActionStep) (map[string]any, error) {
+	if domain.ActionStepType(step.Type) == domain.StepIf {
+		return app.runIfStep(run, step.Config)
+	}
+
+	config, err := renderConfig(step.Config, run.scope)
+	if err != nil {
+		return nil, err
+	}
+
+	return app.executeStep(StepContext{
+		Action:      run.action,
+		Config:      config,
+		TriggerData: run.triggerData,
+		ActionChain: run.chain,
+	}, step.Type)
+}
+
+// runIfStep runs the "then" steps when the conditions match and the "else"
+// steps otherwise. Condition properties are paths in the run scope, e.g.
+// "trigger.document.name" or "steps[0].result.row_id". The branch steps are
+// only rendered when they run, so they can use the results of the previous ones.
+// Config: conditions, then, else.
+func (app *ActionApplication) runIfStep(run *stepRun, config map[string]any) (map[string]any, error) {
+	rendered, err := renderValue(config["conditions"], run.scope)
+	if err != nil {
+		return nil, err
+	}
+	conditions, err := parseConditions(rendered)
+	if err != nil {
+		return nil, err
+	}
+	if conditions == nil {
+		return nil, fmt.Errorf("config.conditions is required")
+	}
+
+	matched, err := matchConditions(conditions, run.scope)
+	if err != nil {
+		return nil, err
+	}
+
+	branch := "then"
+	if !matched {
+		branch = "else"
+	}
+	steps, err := parseSteps(config[branch])
+	if err != nil {
+		return nil, fmt.Errorf("config.%s: %w", branch, err)
+	}
+
+	results, err := app.runSteps(run, steps, "branch")
+	return map[string]any{
+		"matched": matched,
+		"branch":  branch,
+		"steps":   results,
+	}, err
+}
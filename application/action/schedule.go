@@ -0,0 +1,239 @@
+package action
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/go-co-op/gocron/v2"
+	"github.com/labbs/nexo/domain"
+	"github.com/labbs/nexo/infrastructure/helpers/apperrors"
+	"github.com/robfig/cron/v3"
+)
+
+// Missed-run policies of scheduled actions, applied at startup to the runs
+// that were due while the server was down.
+const (
+	MissedRunsSkip    = "skip"     // wait for the next run (default)
+	MissedRunsRunOnce = "run_once" // run once immediately, however many runs were missed
+)
+
+// scheduleConfig is the TriggerConfig of a schedule action:
+// {"cron": "0 9 * * 1-5", "timezone": "Europe/Paris", "missed_runs": "run_once"}
+type scheduleConfig struct {
+	Cron       string
+	Timezone   string
+	MissedRuns string
+	// Spec is the cron expression prefixed with its timezone
+	Spec     string
+	Schedule cron.Schedule
+}
+
+func parseScheduleConfig(config domain.JSONB) (*scheduleConfig, error) {
+	expression, _ := config["cron"].(string)
+	if expression == "" {
+		return nil, errors.New("trigger_config.cron is required for schedule triggers")
+	}
+
+	timezone, _ := config["timezone"].(string)
+	if timezone == "" {
+		timezone = "UTC"
+	}
+	if _, err := time.LoadLocation(timezone); err != nil {
+		return nil, fmt.Errorf("invalid trigger_config.timezone %q", timezone)
+	}
+
+	missedRuns, _ := config["missed_runs"].(string)
+	switch missedRuns {
+	case "":
+		missedRuns = MissedRunsSkip
+	case MissedRunsSkip, MissedRunsRunOnce:
+	default:
+		return nil, fmt.Errorf("invalid trigger_config.missed_runs %q (expected %q or %q)", missedRuns, MissedRunsSkip, MissedRunsRunOnce)
+	}
+
+	spec := "CRON_TZ=" + timezone + " " + expression
+	schedule, err := cron.ParseStandard(spec)
+	if err != nil {
+		return nil, fmt.Errorf("invalid trigger_config.cron %q: %w", expression, err)
+	}
+
+	return &scheduleConfig{
+		Cron:       expression,
+		Timezone:   timezone,
+		MissedRuns: missedRuns,
+		Spec:       spec,
+		Schedule:   schedule,
+	}, nil
+}
+
+// validateTriggerConfig checks the trigger config of the trigger types that need one
+func validateTriggerConfig(triggerType domain.ActionTriggerType, config domain.JSONB) error {
+	if triggerType == domain.TriggerSchedule {
+		if _, err := parseScheduleConfig(config); err != nil {
+			return fmt.Errorf("%w: %s", apperrors.ErrInvalidInput, err)
+		}
+	}
+	return nil
+}
+
+// ScheduleActions registers every active scheduled action with the cron
+// scheduler. It is called once at startup and applies the missed-run policy
+// of each action.
+func (app *ActionApplication) ScheduleActions() error {
+	logger := app.Logger.With().Str("component", "application.action.schedule_actions").Logger()
+
+	actions, err := app.ActionPers.GetActiveByTrigger(domain.TriggerSchedule, nil, nil)
+	if err != nil {
+		logger.Error().Err(err).Msg("failed to get scheduled actions")
+		return fmt.Errorf("failed to get scheduled actions: %w", err)
+	}
+
+	now := time.Now()
+	for _, action := range actions {
+		if err := app.scheduleAction(action); err != nil {
+			logger.Warn().Err(err).Str("action_id", action.Id).Msg("failed to schedule action")
+			continue
+		}
+
+		config, _ := parseScheduleConfig(action.TriggerConfig)
+		if config.MissedRuns != MissedRunsRunOnce {
+			continue
+		}
+		if runAt, missed := missedRun(action, config.Schedule, now); missed {
+			logger.Info().Str("action_id", action.Id).Msg("running missed scheduled action")
+			go app.runScheduledAction(action.Id, &runAt)
+		}
+	}
+
+	logger.Info().Int("count", len(actions)).Msg("scheduled actions registered")
+	return nil
+}
+
+// scheduleAction (re)registers an action with the cron scheduler. Inactive
+// actions and actions with another trigger type are only unregistered.
+func (app *ActionApplication) scheduleAction(action domain.Action) error {
+	if app.Scheduler == nil {
+		return nil
+	}
+
+	app.unscheduleAction(action.Id)
+
+	if !action.Active || action.TriggerType != domain.TriggerSchedule {
+		return nil
+	}
+
+	config, err := parseScheduleConfig(action.TriggerConfig)
+	if err != nil {
+		return err
+	}
+
+	_, err = app.Scheduler.NewJob(
+		gocron.CronJob(config.Spec, false),
+		gocron.NewTask(app.runScheduledAction, action.Id, (*time.Time)(nil)),
+		gocron.WithName("action:"+action.Id),
+		gocron.WithTags(action.Id),
+		gocron.WithSingletonMode(gocron.LimitModeReschedule),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to schedule action: %w", err)
+	}
+
+	return nil
+}
+
+func (app *ActionApplication) unscheduleAction(actionId string) {
+	if app.Scheduler == nil {
+		return
+	}
+	app.Scheduler.RemoveByTags(actionId)
+}
+
+// runScheduledAction runs a scheduled action with the latest version of its
+// definition, for the run due now or for the missed run due at missedRunAt.
+// Every node schedules the actions: the run is claimed first, for a single
+// node to execute it.
+func (app *ActionApplication) runScheduledAction(actionId string, missedRunAt *time.Time) {
+	logger := app.Logger.With().Str("component", "application.action.run_scheduled_action").Str("action_id", actionId).Logger()
+
+	defer func() {
+		if r := recover(); r != nil {
+			logger.Error().Any("panic", r).Msg("scheduled action execution panicked")
+		}
+	}()
+
+	action, err := app.ActionPers.GetById(actionId)
+	if err != nil {
+		logger.Warn().Err(err).Msg("scheduled action not found, unscheduling")
+		app.unscheduleAction(actionId)
+		return
+	}
+	if !action.Active || action.TriggerType != domain.TriggerSchedule {
+		app.unscheduleAction(actionId)
+		return
+	}
+
+	config, err := parseScheduleConfig(action.TriggerConfig)
+	if err != nil {
+		logger.Warn().Err(err).Msg("invalid schedule")
+		return
+	}
+
+	now := time.Now()
+	// Cron expressions have a minute precision: the nodes running the same
+	// run get the same time
+	runAt := now.Truncate(time.Minute)
+	if missedRunAt != nil {
+		runAt = *missedRunAt
+	}
+	triggerData := map[string]any{
+		"schedule": map[string]any{
+			"cron":         config.Cron,
+			"timezone":     config.Timezone,
+			"scheduled_at": now.UTC().Format(time.RFC3339),
+			"missed":       missedRunAt != nil,
+		},
+	}
+
+	matched, err := app.matchesTrigger(*action, triggerData, newScope(*action, triggerData, now))
+	if err != nil {
+		logger.Warn().Err(err).Msg("failed to evaluate trigger conditions")
+		_ = app.ActionPers.RecordFailure(action.Id, fmt.Sprintf("trigger conditions: %s", err))
+		return
+	}
+	if !matched {
+		return
+	}
+
+	claimed, err := app.ActionPers.ClaimScheduledRun(action.Id, runAt)
+	if err != nil {
+		logger.Error().Err(err).Msg("failed to claim scheduled run")
+		return
+	}
+	if !claimed {
+		logger.Debug().Time("run_at", runAt).Msg("scheduled run claimed by another node, skipping it")
+		return
+	}
+
+	if _, busy := app.running.LoadOrStore(action.Id, struct{}{}); busy {
+		logger.Warn().Msg("action already running, skipping scheduled run")
+		return
+	}
+	defer app.running.Delete(action.Id)
+
+	app.executeAction(*action, triggerData, []string{action.Id})
+}
+
+// missedRun returns the first run that was due between the last activity of
+// the action (its last run or last update) and now, if any.
+func missedRun(action domain.Action, schedule cron.Schedule, now time.Time) (time.Time, bool) {
+	since := action.UpdatedAt
+	if action.LastRunAt != nil && action.LastRunAt.After(since) {
+		since = *action.LastRunAt
+	}
+	if since.IsZero() {
+		return time.Time{}, false
+	}
+	runAt := schedule.Next(since)
+	return runAt, !runAt.After(now)
+}
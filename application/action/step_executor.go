@@ -0,0 +1,68 @@
+package action
+
+import (
+	"fmt"
+
+	"github.com/labbs/nexo/domain"
+)
+
+// StepContext is what a step executor receives for one step of a run
+type StepContext struct {
+	Action      domain.Action
+	Config      map[string]any
+	TriggerData map[string]any
+	// ActionChain lists the actions that led to the run, ending with this
+	// one: steps pass it to the changes they make, see ExecuteActions
+	ActionChain []string
+}
+
+// StepExecutor executes one type of action step. The returned map is stored
+// as the step result in ActionRun.StepsResult.
+type StepExecutor interface {
+	Execute(ctx StepContext) (map[string]any, error)
+}
+
+// StepExecutorFunc adapts a function to the StepExecutor interface
+type StepExecutorFunc func(ctx StepContext) (map[string]any, error)
+
+func (f StepExecutorFunc) Execute(ctx StepContext) (map[string]any, error) {
+	return f(ctx)
+}
+
+// RegisterStepExecutor registers the executor for a step type,
+// replacing any executor previously registered for it.
+func (app *ActionApplication) RegisterStepExecutor(stepType domain.ActionStepType, executor StepExecutor) {
+	app.stepExecutors[stepType] = executor
+}
+
+func (app *ActionApplication) registerDefaultStepExecutors() {
+	// Notification steps
+	app.RegisterStepExecutor(domain.StepSendEmail, StepExecutorFunc(app.sendEmailStep))
+	app.RegisterStepExecutor(domain.StepSendSlack, StepExecutorFunc(app.sendSlackStep))
+	app.RegisterStepExecutor(domain.StepSendWebhook, StepExecutorFunc(app.sendWebhookStep))
+
+	// Document steps
+	app.RegisterStepExecutor(domain.StepCreateDocument, StepExecutorFunc(app.createDocumentStep))
+	app.RegisterStepExecutor(domain.StepUpdateDocument, StepExecutorFunc(app.updateDocumentStep))
+	app.RegisterStepExecutor(domain.StepMoveDocument, StepExecutorFunc(app.moveDocumentStep))
+	app.RegisterStepExecutor(domain.StepDuplicateDocument, StepExecutorFunc(app.duplicateDocumentStep))
+
+	// Database steps
+	app.RegisterStepExecutor(domain.StepCreateRow, StepExecutorFunc(app.createRowStep))
+	app.RegisterStepExecutor(domain.StepUpdateRow, StepExecutorFunc(app.updateRowStep))
+	app.RegisterStepExecutor(domain.StepDeleteRow, StepExecutorFunc(app.deleteRowStep))
+	app.RegisterStepExecutor(domain.StepUpdateProperty, StepExecutorFunc(app.updatePropertyStep))
+
+	// Misc steps
+	app.RegisterStepExecutor(domain.StepAddComment, StepExecutorFunc(app.addCommentStep))
+	app.RegisterStepExecutor(domain.StepAssignUser, StepExecutorFunc(app.assignUserStep))
+	app.RegisterStepExecutor(domain.StepSetReminder, StepExecutorFunc(app.setReminderStep))
+}
+
+func (app *ActionApplication) executeStep(ctx StepContext, stepType string) (map[string]any, error) {
+	executor, ok := app.stepExecutors[domain.ActionStepType(stepType)]
+	if !ok {
+		return nil, fmt.Errorf("unsupported step type: %s", stepType)
+	}
+	return executor.Execute(ctx)
+}
@@ -0,0 +1,113 @@
+package action
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// String returns the string value of a step config key, or "" if it is unset
+func (ctx StepContext) String(key string) string {
+	s, _ := ctx.Config[key].(string)
+	return s
+}
+
+// RequireString returns the string value of a step config key, failing if it is empty
+func (ctx StepContext) RequireString(key string) (string, error) {
+	s := ctx.String(key)
+	if s == "" {
+		return "", fmt.Errorf("config.%s is required", key)
+	}
+	return s, nil
+}
+
+// Map returns the object value of a step config key, or nil if it is unset
+func (ctx StepContext) Map(key string) map[string]any {
+	m, _ := ctx.Config[key].(map[string]any)
+	return m
+}
+
+// SpaceId returns the space a step applies to: the configured one, then the
+// space of the triggering resource, then the space the action is scoped to.
+func (ctx StepContext) SpaceId() string {
+	if id := ctx.String("space_id"); id != "" {
+		return id
+	}
+	if id, _ := ctx.resource()["space_id"].(string); id != "" {
+		return id
+	}
+	if ctx.Action.SpaceId != nil {
+		return *ctx.Action.SpaceId
+	}
+	return ""
+}
+
+// DatabaseId returns the database a step applies to: the configured one, then
+// the database of the triggering resource, then the database the action is scoped to.
+func (ctx StepContext) DatabaseId() string {
+	if id := ctx.String("database_id"); id != "" {
+		return id
+	}
+	if id, _ := ctx.resource()["database_id"].(string); id != "" {
+		return id
+	}
+	if ctx.Action.DatabaseId != nil {
+		return *ctx.Action.DatabaseId
+	}
+	return ""
+}
+
+// DocumentId returns the document a step applies to: the configured one,
+// then the triggering document, then the document of the triggering comment.
+func (ctx StepContext) DocumentId() string {
+	if id := ctx.String("document_id"); id != "" {
+		return id
+	}
+	resource := ctx.resource()
+	if resource["type"] == "document" {
+		if id, _ := resource["id"].(string); id != "" {
+			return id
+		}
+	}
+	if comment, ok := ctx.TriggerData["comment"].(map[string]any); ok {
+		if id, _ := comment["document_id"].(string); id != "" {
+			return id
+		}
+	}
+	return ""
+}
+
+// RowId returns the row a step applies to: the configured one, then the triggering row
+func (ctx StepContext) RowId() string {
+	if id := ctx.String("row_id"); id != "" {
+		return id
+	}
+	resource := ctx.resource()
+	if resource["type"] == "row" {
+		if id, _ := resource["id"].(string); id != "" {
+			return id
+		}
+	}
+	return ""
+}
+
+func (ctx StepContext) resource() map[string]any {
+	resource, _ := ctx.TriggerData["resource"].(map[string]any)
+	return resource
+}
+
+// decodeConfigValue converts a config value (decoded from JSON) into out
+func decodeConfigValue(value any, out any) error {
+	raw, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(raw, out)
+}
+
+// required fails with a config error when value is empty
+func required(key, value string) error {
+	if value == "" {
+		return fmt.Errorf("config.%s is required", key)
+	}
+	return nil
+}
@@ -0,0 +1,115 @@
+package action
+
+import (
+	"fmt"
+
+	databaseDto "github.com/labbs/nexo/application/database/dto"
+)
+
+// createRowStep creates a database row.
+// Config: database_id, properties, content.
+func (app *ActionApplication) createRowStep(ctx StepContext) (map[string]any, error) {
+	databaseId := ctx.DatabaseId()
+	if err := required("database_id", databaseId); err != nil {
+		return nil, err
+	}
+
+	result, err := app.DatabaseApplication.CreateRow(databaseDto.CreateRowInput{
+		UserId:      ctx.Action.UserId,
+		ActionChain: ctx.ActionChain,
+		DatabaseId:  databaseId,
+		Properties:  ctx.Map("properties"),
+		Content:     ctx.Map("content"),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create row: %w", err)
+	}
+
+	return map[string]any{
+		"row_id":      result.Id,
+		"database_id": databaseId,
+		"properties":  result.Properties,
+	}, nil
+}
+
+// updateRowStep merges properties into a database row and optionally
+// replaces its content.
+// Config: database_id, row_id, properties, content.
+func (app *ActionApplication) updateRowStep(ctx StepContext) (map[string]any, error) {
+	databaseId, rowId := ctx.DatabaseId(), ctx.RowId()
+	if err := required("database_id", databaseId); err != nil {
+		return nil, err
+	}
+	if err := required("row_id", rowId); err != nil {
+		return nil, err
+	}
+
+	return app.updateRowProperties(ctx, databaseId, rowId, ctx.Map("properties"), ctx.Map("content"))
+}
+
+// deleteRowStep deletes a database row.
+// Config: database_id, row_id.
+func (app *ActionApplication) deleteRowStep(ctx StepContext) (map[string]any, error) {
+	databaseId, rowId := ctx.DatabaseId(), ctx.RowId()
+	if err := required("database_id", databaseId); err != nil {
+		return nil, err
+	}
+	if err := required("row_id", rowId); err != nil {
+		return nil, err
+	}
+
+	if err := app.DatabaseApplication.DeleteRow(databaseDto.DeleteRowInput{
+		UserId:      ctx.Action.UserId,
+		ActionChain: ctx.ActionChain,
+		DatabaseId:  databaseId,
+		RowId:       rowId,
+	}); err != nil {
+		return nil, fmt.Errorf("failed to delete row: %w", err)
+	}
+
+	return map[string]any{
+		"row_id":      rowId,
+		"database_id": databaseId,
+		"deleted":     true,
+	}, nil
+}
+
+// updatePropertyStep sets a single property of a database row.
+// Config: database_id, row_id, property, value.
+func (app *ActionApplication) updatePropertyStep(ctx StepContext) (map[string]any, error) {
+	databaseId, rowId := ctx.DatabaseId(), ctx.RowId()
+	if err := required("database_id", databaseId); err != nil {
+		return nil, err
+	}
+	if err := required("row_id", rowId); err != nil {
+		return nil, err
+	}
+	property, err := ctx.RequireString("property")
+	if err != nil {
+		return nil, err
+	}
+
+	return app.updateRowProperties(ctx, databaseId, rowId, map[string]any{property: ctx.Config["value"]}, nil)
+}
+
+// updateRowProperties merges updates into the properties of a row and
+// optionally replaces its content.
+func (app *ActionApplication) updateRowProperties(ctx StepContext, databaseId, rowId string, updates, content map[string]any) (map[string]any, error) {
+	result, err := app.DatabaseApplication.PatchRow(databaseDto.PatchRowInput{
+		UserId:      ctx.Action.UserId,
+		ActionChain: ctx.ActionChain,
+		DatabaseId:  databaseId,
+		RowId:       rowId,
+		Properties:  updates,
+		Content:     content,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to update row: %w", err)
+	}
+
+	return map[string]any{
+		"row_id":      rowId,
+		"database_id": databaseId,
+		"properties":  result.Properties,
+	}, nil
+}
@@ -0,0 +1,200 @@
+package action
+
+import (
+	"fmt"
+
+	documentDto "github.com/labbs/nexo/application/document/dto"
+	"github.com/labbs/nexo/domain"
+)
+
+// createDocumentStep creates a document.
+// Config: space_id, name, parent_id, content (BlockNote blocks).
+func (app *ActionApplication) createDocumentStep(ctx StepContext) (map[string]any, error) {
+	spaceId := ctx.SpaceId()
+	if err := required("space_id", spaceId); err != nil {
+		return nil, err
+	}
+	name, err := ctx.RequireString("name")
+	if err != nil {
+		return nil, err
+	}
+
+	content, err := stepBlocks(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := app.DocumentApplication.CreateDocument(documentDto.CreateDocumentInput{
+		Name:        name,
+		UserId:      ctx.Action.UserId,
+		ActionChain: ctx.ActionChain,
+		SpaceId:     spaceId,
+		Content:     content,
+		ParentId:    optionalString(ctx.String("parent_id")),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create document: %w", err)
+	}
+
+	return documentResult(result.Document), nil
+}
+
+// updateDocumentStep updates the name, content or metadata of a document.
+// Config: space_id, document_id, name, content, metadata.
+func (app *ActionApplication) updateDocumentStep(ctx StepContext) (map[string]any, error) {
+	spaceId, documentId := ctx.SpaceId(), ctx.DocumentId()
+	if err := required("space_id", spaceId); err != nil {
+		return nil, err
+	}
+	if err := required("document_id", documentId); err != nil {
+		return nil, err
+	}
+
+	input := documentDto.UpdateDocumentInput{
+		UserId:      ctx.Action.UserId,
+		ActionChain: ctx.ActionChain,
+		SpaceId:     spaceId,
+		DocumentId:  documentId,
+		Name:        optionalString(ctx.String("name")),
+	}
+
+	if _, ok := ctx.Config["content"]; ok {
+		content, err := stepBlocks(ctx)
+		if err != nil {
+			return nil, err
+		}
+		input.Content = &content
+	}
+
+	if metadata := ctx.Map("metadata"); metadata != nil {
+		m := domain.JSONB(metadata)
+		input.Metadata = &m
+	}
+
+	result, err := app.DocumentApplication.UpdateDocument(input)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update document: %w", err)
+	}
+
+	return documentResult(result.Document), nil
+}
+
+// moveDocumentStep moves a document under another parent, or to the root
+// of its space when parent_id is empty.
+// Config: space_id, document_id, parent_id.
+func (app *ActionApplication) moveDocumentStep(ctx StepContext) (map[string]any, error) {
+	spaceId, documentId := ctx.SpaceId(), ctx.DocumentId()
+	if err := required("space_id", spaceId); err != nil {
+		return nil, err
+	}
+	if err := required("document_id", documentId); err != nil {
+		return nil, err
+	}
+
+	result, err := app.DocumentApplication.MoveDocument(documentDto.MoveDocumentInput{
+		UserId:      ctx.Action.UserId,
+		ActionChain: ctx.ActionChain,
+		SpaceId:     spaceId,
+		DocumentId:  documentId,
+		NewParentId: optionalString(ctx.String("parent_id")),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to move document: %w", err)
+	}
+
+	return documentResult(result.Document), nil
+}
+
+// duplicateDocumentStep copies a document with its content and config.
+// Config: space_id, document_id, name (default "<name> (copy)"),
+// parent_id (default: the parent of the source document).
+func (app *ActionApplication) duplicateDocumentStep(ctx StepContext) (map[string]any, error) {
+	spaceId, documentId := ctx.SpaceId(), ctx.DocumentId()
+	if err := required("space_id", spaceId); err != nil {
+		return nil, err
+	}
+	if err := required("document_id", documentId); err != nil {
+		return nil, err
+	}
+
+	source, err := app.DocumentApplication.GetDocumentWithSpace(documentDto.GetDocumentWithSpaceInput{
+		UserId:     ctx.Action.UserId,
+		SpaceId:    spaceId,
+		DocumentId: &documentId,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get document to duplicate: %w", err)
+	}
+
+	name := ctx.String("name")
+	if name == "" {
+		name = source.Document.Name + " (copy)"
+	}
+
+	parentId := source.Document.ParentId
+	if _, ok := ctx.Config["parent_id"]; ok {
+		parentId = optionalString(ctx.String("parent_id"))
+	}
+
+	created, err := app.DocumentApplication.CreateDocument(documentDto.CreateDocumentInput{
+		Name:        name,
+		UserId:      ctx.Action.UserId,
+		ActionChain: ctx.ActionChain,
+		SpaceId:     spaceId,
+		Content:     source.Document.Content,
+		ParentId:    parentId,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create duplicate document: %w", err)
+	}
+
+	config := domain.DocumentConfig{
+		FullWidth:        source.Document.Config.FullWidth,
+		Icon:             source.Document.Config.Icon,
+		Lock:             source.Document.Config.Lock,
+		HeaderBackground: source.Document.Config.HeaderBackground,
+	}
+	if config != (domain.DocumentConfig{}) {
+		updated, err := app.DocumentApplication.UpdateDocument(documentDto.UpdateDocumentInput{
+			UserId:      ctx.Action.UserId,
+			ActionChain: ctx.ActionChain,
+			SpaceId:     spaceId,
+			DocumentId:  created.Document.Id,
+			Config:      &config,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to copy document config: %w", err)
+		}
+		created.Document = updated.Document
+	}
+
+	result := documentResult(created.Document)
+	result["source_document_id"] = documentId
+	return result, nil
+}
+
+func stepBlocks(ctx StepContext) ([]documentDto.Block, error) {
+	var blocks []documentDto.Block
+	if content, ok := ctx.Config["content"]; ok && content != nil {
+		if err := decodeConfigValue(content, &blocks); err != nil {
+			return nil, fmt.Errorf("config.content must be a list of blocks: %w", err)
+		}
+	}
+	return blocks, nil
+}
+
+func documentResult(doc *domain.Document) map[string]any {
+	return map[string]any{
+		"document_id": doc.Id,
+		"slug":        doc.Slug,
+		"name":        doc.Name,
+		"space_id":    doc.SpaceId,
+	}
+}
+
+func optionalString(s string) *string {
+	if s == "" {
+		return nil
+	}
+	return &s
+}
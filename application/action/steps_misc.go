@@ -0,0 +1,123 @@
+package action
+
+import (
+	"fmt"
+	"slices"
+	"time"
+
+	databaseDto "github.com/labbs/nexo/application/database/dto"
+	documentDto "github.com/labbs/nexo/application/document/dto"
+)
+
+// addCommentStep adds a comment to a document, as the owner of the action.
+// Config: document_id, content, parent_id, block_id.
+func (app *ActionApplication) addCommentStep(ctx StepContext) (map[string]any, error) {
+	documentId := ctx.DocumentId()
+	if err := required("document_id", documentId); err != nil {
+		return nil, err
+	}
+	content, err := ctx.RequireString("content")
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := app.DocumentApplication.CreateComment(documentDto.CreateCommentInput{
+		UserId:      ctx.Action.UserId,
+		ActionChain: ctx.ActionChain,
+		DocumentId:  documentId,
+		ParentId:    optionalString(ctx.String("parent_id")),
+		Content:     content,
+		BlockId:     optionalString(ctx.String("block_id")),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to add comment: %w", err)
+	}
+
+	return map[string]any{
+		"comment_id":  result.CommentId,
+		"document_id": documentId,
+	}, nil
+}
+
+// assignUserStep sets a person property of a database row. With mode "add"
+// the user is appended to the people already assigned instead of replacing them.
+// Config: database_id, row_id, property, user_id, mode.
+func (app *ActionApplication) assignUserStep(ctx StepContext) (map[string]any, error) {
+	databaseId, rowId := ctx.DatabaseId(), ctx.RowId()
+	if err := required("database_id", databaseId); err != nil {
+		return nil, err
+	}
+	if err := required("row_id", rowId); err != nil {
+		return nil, err
+	}
+	property, err := ctx.RequireString("property")
+	if err != nil {
+		return nil, err
+	}
+	userId, err := ctx.RequireString("user_id")
+	if err != nil {
+		return nil, err
+	}
+
+	assignees := []any{userId}
+	if ctx.String("mode") == "add" {
+		row, err := app.DatabaseApplication.GetRow(databaseDto.GetRowInput{
+			UserId:     ctx.Action.UserId,
+			DatabaseId: databaseId,
+			RowId:      rowId,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to get row: %w", err)
+		}
+		if current, ok := row.Properties[property].([]any); ok {
+			if slices.Contains(current, any(userId)) {
+				assignees = current
+			} else {
+				assignees = append(slices.Clone(current), userId)
+			}
+		}
+	}
+
+	return app.updateRowProperties(ctx, databaseId, rowId, map[string]any{property: assignees}, nil)
+}
+
+// setReminderStep sets a date property of a database row, either to a fixed
+// date or relative to the time the action runs.
+// Config: database_id, row_id, property, and at (RFC 3339) or in (duration, e.g. "72h").
+func (app *ActionApplication) setReminderStep(ctx StepContext) (map[string]any, error) {
+	databaseId, rowId := ctx.DatabaseId(), ctx.RowId()
+	if err := required("database_id", databaseId); err != nil {
+		return nil, err
+	}
+	if err := required("row_id", rowId); err != nil {
+		return nil, err
+	}
+	property, err := ctx.RequireString("property")
+	if err != nil {
+		return nil, err
+	}
+
+	var remindAt time.Time
+	switch {
+	case ctx.String("at") != "":
+		remindAt, err = time.Parse(time.RFC3339, ctx.String("at"))
+		if err != nil {
+			return nil, fmt.Errorf("config.at must be an RFC 3339 date: %w", err)
+		}
+	case ctx.String("in") != "":
+		delay, err := time.ParseDuration(ctx.String("in"))
+		if err != nil {
+			return nil, fmt.Errorf("config.in must be a duration: %w", err)
+		}
+		remindAt = time.Now().Add(delay)
+	default:
+		return nil, fmt.Errorf("config.at or config.in is required")
+	}
+
+	result, err := app.updateRowProperties(ctx, databaseId, rowId, map[string]any{property: remindAt.UTC().Format(time.RFC3339)}, nil)
+	if err != nil {
+		return nil, err
+	}
+	result["remind_at"] = remindAt.UTC().Format(time.RFC3339)
+	return result, nil
+}
@@ -0,0 +1,79 @@
+package action
+
+import (
+	"errors"
+	"fmt"
+
+	webhookDto "github.com/labbs/nexo/application/webhook/dto"
+)
+
+// sendEmailStep has no transport to send through yet: fail the step
+// explicitly rather than report an email that was never sent.
+func (app *ActionApplication) sendEmailStep(ctx StepContext) (map[string]any, error) {
+	return nil, errors.New("email delivery is not configured")
+}
+
+// sendSlackStep posts a message to a Slack incoming webhook.
+// Config: webhook_url, text.
+func (app *ActionApplication) sendSlackStep(ctx StepContext) (map[string]any, error) {
+	webhookUrl, err := ctx.RequireString("webhook_url")
+	if err != nil {
+		return nil, err
+	}
+	text, err := ctx.RequireString("text")
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := app.WebhookApplication.SendRequest(webhookDto.SendRequestInput{
+		Url:  webhookUrl,
+		Body: map[string]any{"text": text},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to send slack message: %w", err)
+	}
+
+	return map[string]any{
+		"status_code": result.StatusCode,
+		"duration":    result.Duration,
+	}, nil
+}
+
+// sendWebhookStep sends a JSON request to an arbitrary URL.
+// Config: url, method (default POST), headers, body (default: the trigger
+// data), secret (optional, signs the body).
+func (app *ActionApplication) sendWebhookStep(ctx StepContext) (map[string]any, error) {
+	url, err := ctx.RequireString("url")
+	if err != nil {
+		return nil, err
+	}
+
+	headers := make(map[string]string)
+	for key, value := range ctx.Map("headers") {
+		if s, ok := value.(string); ok {
+			headers[key] = s
+		}
+	}
+
+	body, ok := ctx.Config["body"]
+	if !ok {
+		body = ctx.TriggerData
+	}
+
+	result, err := app.WebhookApplication.SendRequest(webhookDto.SendRequestInput{
+		Url:     url,
+		Method:  ctx.String("method"),
+		Secret:  ctx.String("secret"),
+		Headers: headers,
+		Body:    body,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to send webhook: %w", err)
+	}
+
+	return map[string]any{
+		"status_code": result.StatusCode,
+		"response":    result.Response,
+		"duration":    result.Duration,
+	}, nil
+}
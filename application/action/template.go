@@ -0,0 +1,313 @@
+package action
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Step configs may reference the trigger data and the results of previous
+// steps with {{ }} expressions, e.g. "{{trigger.document.name}}" or
+// "{{steps[0].result.row_id}}". An expression is a path, optionally followed
+// by filters: "{{trigger.document.name | upper}}", "{{trigger.after.icon | default \"📄\"}}".
+//
+// A string made of a single expression is replaced by the value itself (so
+// numbers, lists and objects keep their type); otherwise every expression is
+// rendered as text inside the string.
+
+var templateExpression = regexp.MustCompile(`{{\s*(.*?)\s*}}`)
+
+// renderConfig returns a copy of the step config with every template expression evaluated
+func renderConfig(config map[string]any, scope map[string]any) (map[string]any, error) {
+	rendered, err := renderValue(config, scope)
+	if err != nil {
+		return nil, err
+	}
+	m, _ := rendered.(map[string]any)
+	return m, nil
+}
+
+func renderValue(value any, scope map[string]any) (any, error) {
+	switch v := value.(type) {
+	case string:
+		return renderString(v, scope)
+	case map[string]any:
+		out := make(map[string]any, len(v))
+		for key, item := range v {
+			rendered, err := renderValue(item, scope)
+			if err != nil {
+				return nil, err
+			}
+			out[key] = rendered
+		}
+		return out, nil
+	case []any:
+		out := make([]any, len(v))
+		for i, item := range v {
+			rendered, err := renderValue(item, scope)
+			if err != nil {
+				return nil, err
+			}
+			out[i] = rendered
+		}
+		return out, nil
+	default:
+		return value, nil
+	}
+}
+
+func renderString(s string, scope map[string]any) (any, error) {
+	matches := templateExpression.FindAllStringSubmatchIndex(s, -1)
+	if len(matches) == 0 {
+		return s, nil
+	}
+
+	// The whole string is one expression: keep the type of the value
+	if len(matches) == 1 && matches[0][0] == 0 && matches[0][1] == len(s) {
+		return evaluateExpression(s[matches[0][2]:matches[0][3]], scope)
+	}
+
+	var b strings.Builder
+	last := 0
+	for _, m := range matches {
+		b.WriteString(s[last:m[0]])
+		value, err := evaluateExpression(s[m[2]:m[3]], scope)
+		if err != nil {
+			return nil, err
+		}
+		b.WriteString(templateText(value))
+		last = m[1]
+	}
+	b.WriteString(s[last:])
+	return b.String(), nil
+}
+
+// evaluateExpression evaluates "path | filter arg | filter ..."
+func evaluateExpression(expression string, scope map[string]any) (any, error) {
+	parts := splitFilters(expression)
+
+	value, lookupErr := lookupPath(strings.TrimSpace(parts[0]), scope)
+
+	for _, part := range parts[1:] {
+		name, arg, err := parseFilter(strings.TrimSpace(part))
+		if err != nil {
+			return nil, fmt.Errorf("invalid expression %q: %w", expression, err)
+		}
+
+		if name == "default" {
+			if lookupErr != nil || isEmptyValue(value) {
+				value, lookupErr = arg, nil
+			}
+			continue
+		}
+		if lookupErr != nil {
+			break
+		}
+
+		switch name {
+		case "upper":
+			value = strings.ToUpper(templateText(value))
+		case "lower":
+			value = strings.ToLower(templateText(value))
+		case "trim":
+			value = strings.TrimSpace(templateText(value))
+		case "json":
+			raw, err := json.Marshal(value)
+			if err != nil {
+				return nil, fmt.Errorf("invalid expression %q: %w", expression, err)
+			}
+			value = string(raw)
+		default:
+			return nil, fmt.Errorf("invalid expression %q: unknown filter %q", expression, name)
+		}
+	}
+
+	if lookupErr != nil {
+		return nil, fmt.Errorf("invalid expression %q: %w", expression, lookupErr)
+	}
+	return value, nil
+}
+
+// splitFilters splits an expression on the pipes that are not inside quotes
+func splitFilters(expression string) []string {
+	var parts []string
+	var quote rune
+	start := 0
+	for i, r := range expression {
+		switch {
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			}
+		case r == '"' || r == '\'':
+			quote = r
+		case r == '|':
+			parts = append(parts, expression[start:i])
+			start = i + 1
+		}
+	}
+	return append(parts, expression[start:])
+}
+
+// parseFilter parses `name` or `name "argument"`
+func parseFilter(filter string) (string, any, error) {
+	name, rest, _ := strings.Cut(filter, " ")
+	rest = strings.TrimSpace(rest)
+	if name == "" {
+		return "", nil, fmt.Errorf("empty filter")
+	}
+	if rest == "" {
+		return name, nil, nil
+	}
+	arg, err := parseLiteral(rest)
+	if err != nil {
+		return "", nil, fmt.Errorf("filter %q: %w", name, err)
+	}
+	return name, arg, nil
+}
+
+// parseLiteral parses a quoted string, a number, true, false or null
+func parseLiteral(s string) (any, error) {
+	if len(s) >= 2 && s[0] == '\'' && s[len(s)-1] == '\'' {
+		return s[1 : len(s)-1], nil
+	}
+	var value any
+	if err := json.Unmarshal([]byte(s), &value); err != nil {
+		return nil, fmt.Errorf("invalid literal %s", s)
+	}
+	return value, nil
+}
+
+// lookupPath resolves a path such as `steps[0].result.row_id` or
+// `trigger.after["custom field"]` in the scope.
+func lookupPath(path string, scope map[string]any) (any, error) {
+	if path == "" {
+		return nil, fmt.Errorf("empty path")
+	}
+
+	var current any = scope
+	rest := path
+	first := true
+	for rest != "" {
+		var key string
+		index := -1
+
+		switch {
+		case rest[0] == '[':
+			end := strings.IndexByte(rest, ']')
+			if end < 0 {
+				return nil, fmt.Errorf("unclosed [ in %q", path)
+			}
+			inner := strings.TrimSpace(rest[1:end])
+			rest = rest[end+1:]
+			if n, err := strconv.Atoi(inner); err == nil {
+				index = n
+			} else if unquoted, err := parseLiteral(inner); err == nil {
+				s, ok := unquoted.(string)
+				if !ok {
+					return nil, fmt.Errorf("invalid index %s in %q", inner, path)
+				}
+				key = s
+			} else {
+				return nil, fmt.Errorf("invalid index %s in %q", inner, path)
+			}
+		default:
+			if !first {
+				if rest[0] != '.' {
+					return nil, fmt.Errorf("unexpected %q in %q", rest[0], path)
+				}
+				rest = rest[1:]
+			}
+			end := strings.IndexAny(rest, ".[")
+			if end < 0 {
+				end = len(rest)
+			}
+			key, rest = rest[:end], rest[end:]
+			if key == "" {
+				return nil, fmt.Errorf("empty segment in %q", path)
+			}
+		}
+		first = false
+
+		next, ok := lookupSegment(current, key, index)
+		if !ok {
+			return nil, fmt.Errorf("%q not found", path)
+		}
+		current = next
+	}
+
+	return current, nil
+}
+
+func lookupSegment(value any, key string, index int) (any, bool) {
+	if value == nil {
+		return nil, false
+	}
+	v := reflect.ValueOf(value)
+	for v.Kind() == reflect.Pointer || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return nil, false
+		}
+		v = v.Elem()
+	}
+
+	switch v.Kind() {
+	case reflect.Map:
+		if index >= 0 {
+			key = strconv.Itoa(index)
+		}
+		if v.Type().Key().Kind() != reflect.String {
+			return nil, false
+		}
+		item := v.MapIndex(reflect.ValueOf(key).Convert(v.Type().Key()))
+		if !item.IsValid() {
+			return nil, false
+		}
+		return item.Interface(), true
+	case reflect.Slice, reflect.Array:
+		if index < 0 || index >= v.Len() {
+			return nil, false
+		}
+		return v.Index(index).Interface(), true
+	default:
+		return nil, false
+	}
+}
+
+func isEmptyValue(value any) bool {
+	if value == nil {
+		return true
+	}
+	s, ok := value.(string)
+	return ok && s == ""
+}
+
+// templateText renders a value inside a string: scalars as text, lists and objects as JSON
+func templateText(value any) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case bool, int, int64:
+		return fmt.Sprint(v)
+	default:
+		raw, err := json.Marshal(v)
+		if err != nil {
+			return fmt.Sprint(v)
+		}
+		return string(raw)
+	}
+}
+
+func stringOrNil(s *string) any {
+	if s == nil {
+		return nil
+	}
+	return *s
+}
@@ -0,0 +1,64 @@
+package action
+
+import (
+	"fmt"
+
+	"github.com/labbs/nexo/domain"
+)
+
+// matchesTrigger reports whether an event satisfies the trigger config of an action:
+//   - "conditions": {"and": [...], "or": [...]} rules whose property is a path
+//     in the trigger data, e.g. {"property": "changes.name.to", "condition": "contains", "value": "RFC"}
+//   - for property.changed: "property" (id or name of the property) and
+//     optionally "from" and "to", the values before and after the change.
+func (app *ActionApplication) matchesTrigger(action domain.Action, triggerData map[string]any, scope map[string]any) (bool, error) {
+	config := map[string]any(action.TriggerConfig)
+	if config == nil {
+		return true, nil
+	}
+
+	if action.TriggerType == domain.TriggerPropertyChanged {
+		ok, err := matchesPropertyChange(config, triggerData, scope)
+		if err != nil || !ok {
+			return false, err
+		}
+	}
+
+	rendered, err := renderValue(config["conditions"], scope)
+	if err != nil {
+		return false, err
+	}
+	conditions, err := parseConditions(rendered)
+	if err != nil {
+		return false, err
+	}
+	return matchConditions(conditions, triggerData)
+}
+
+func matchesPropertyChange(config, triggerData, scope map[string]any) (bool, error) {
+	rendered, err := renderValue(map[string]any{
+		"property": config["property"],
+		"from":     config["from"],
+		"to":       config["to"],
+	}, scope)
+	if err != nil {
+		return false, err
+	}
+	expected := rendered.(map[string]any)
+
+	change, _ := triggerData["property"].(map[string]any)
+	if change == nil {
+		return false, fmt.Errorf("trigger data has no property change")
+	}
+
+	if property, _ := expected["property"].(string); property != "" && change["id"] != property && change["name"] != property {
+		return false, nil
+	}
+	if _, ok := config["from"]; ok && !valuesMatch(change["from"], expected["from"]) {
+		return false, nil
+	}
+	if _, ok := config["to"]; ok && !valuesMatch(change["to"], expected["to"]) {
+		return false, nil
+	}
+	return true, nil
+}
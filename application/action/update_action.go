@@ -41,7 +41,7 @@ func (app *ActionApplication) UpdateAction(input dto.UpdateActionInput) error {
 		if err != nil {
 			return fmt.Errorf("failed to marshal steps: %w", err)
 		}
-		var steps domain.JSONB
+		var steps domain.JSONBArray
 		json.Unmarshal(stepsJSON, &steps)
 		action.Steps = steps
 	}
@@ -50,11 +50,19 @@ func (app *ActionApplication) UpdateAction(input dto.UpdateActionInput) error {
 		action.Active = *input.Active
 	}
 
+	if err := validateTriggerConfig(action.TriggerType, action.TriggerConfig); err != nil {
+		return err
+	}
+
 	action.UpdatedAt = time.Now()
 
 	if err := app.ActionPers.Update(action); err != nil {
 		return fmt.Errorf("failed to update action: %w", err)
 	}
 
+	if err := app.scheduleAction(*action); err != nil {
+		app.Logger.Error().Err(err).Str("component", "application.action.update_action").Str("action_id", action.Id).Msg("failed to schedule action")
+	}
+
 	return nil
 }
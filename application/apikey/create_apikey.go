@@ -10,6 +10,10 @@ import (
 )
 
 func (app *ApiKeyApplication) CreateApiKey(input dto.CreateApiKeyInput) (*dto.CreateApiKeyOutput, error) {
+	if err := validateScopes(input.Scopes); err != nil {
+		return nil, err
+	}
+
 	// Generate the API key
 	plainKey, err := generateApiKey()
 	if err != nil {
@@ -5,8 +5,9 @@ type ValidateApiKeyInput struct {
 }
 
 type ValidateApiKeyOutput struct {
-	Valid   bool
-	UserId  string
-	Scopes  []string
-	Expired bool
+	Valid    bool
+	ApiKeyId string
+	UserId   string
+	Scopes   []string
+	Expired  bool
 }
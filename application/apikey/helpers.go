@@ -4,6 +4,10 @@ import (
 	"crypto/rand"
 	"crypto/sha256"
 	"encoding/hex"
+	"fmt"
+
+	"github.com/labbs/nexo/domain"
+	"github.com/labbs/nexo/infrastructure/helpers/apperrors"
 )
 
 // generateApiKey generates a secure random API key
@@ -20,3 +24,13 @@ func hashApiKey(key string) string {
 	hash := sha256.Sum256([]byte(key))
 	return hex.EncodeToString(hash[:])
 }
+
+// validateScopes checks that the scopes can be granted to an API key
+func validateScopes(scopes []string) error {
+	for _, scope := range scopes {
+		if !domain.ApiKeyScope(scope).IsValid() {
+			return fmt.Errorf("%w: unknown scope %q", apperrors.ErrInvalidInput, scope)
+		}
+	}
+	return nil
+}
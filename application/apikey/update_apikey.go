@@ -25,6 +25,9 @@ func (app *ApiKeyApplication) UpdateApiKey(input dto.UpdateApiKeyInput) error {
 	}
 
 	if input.Scopes != nil {
+		if err := validateScopes(*input.Scopes); err != nil {
+			return err
+		}
 		apiKey.Permissions = domain.JSONB{
 			"scopes": *input.Scopes,
 		}
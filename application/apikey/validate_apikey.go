@@ -37,8 +37,9 @@ func (app *ApiKeyApplication) ValidateApiKey(input dto.ValidateApiKeyInput) (*dt
 	}
 
 	return &dto.ValidateApiKeyOutput{
-		Valid:  true,
-		UserId: apiKey.UserId,
-		Scopes: scopes,
+		Valid:    true,
+		ApiKeyId: apiKey.Id,
+		UserId:   apiKey.UserId,
+		Scopes:   scopes,
 	}, nil
 }
@@ -0,0 +1,59 @@
+package attachment
+
+import (
+	"fmt"
+
+	permissionDto "github.com/labbs/nexo/application/permission/dto"
+	"github.com/labbs/nexo/domain"
+	"github.com/labbs/nexo/infrastructure/helpers/apperrors"
+)
+
+// checkAccess verifies that the user has the required role on the resource
+// of an attachment, and returns the space of the resource.
+func (a *AttachmentApplication) checkAccess(userId string, resourceType domain.AttachmentResourceType, resourceId string, requiredRole domain.PermissionRole) (string, error) {
+	switch resourceType {
+	case domain.AttachmentResourceDocument:
+		doc, err := a.DocumentPers.GetDocumentWithPermissions(resourceId, userId)
+		if err != nil {
+			return "", fmt.Errorf("document not found: %w", err)
+		}
+		if !doc.HasPermission(userId, requiredRole) {
+			return "", apperrors.ErrAccessDenied
+		}
+		return doc.SpaceId, nil
+
+	case domain.AttachmentResourceDrawing:
+		drawing, err := a.DrawingPers.GetById(resourceId)
+		if err != nil {
+			return "", fmt.Errorf("drawing not found: %w", err)
+		}
+		return drawing.SpaceId, a.checkResourceAccess(userId, domain.PermissionTypeDrawing, drawing.Id, requiredRole)
+
+	case domain.AttachmentResourceDatabase:
+		database, err := a.DatabasePers.GetById(resourceId)
+		if err != nil {
+			return "", fmt.Errorf("database not found: %w", err)
+		}
+		return database.SpaceId, a.checkResourceAccess(userId, domain.PermissionTypeDatabase, database.Id, requiredRole)
+
+	default:
+		return "", fmt.Errorf("%w: unknown resource type %q", apperrors.ErrInvalidInput, resourceType)
+	}
+}
+
+// checkResourceAccess verifies the role of the user on a drawing or a
+// database: the one their permissions give, else the role in their space.
+func (a *AttachmentApplication) checkResourceAccess(userId string, resourceType domain.PermissionType, resourceId string, requiredRole domain.PermissionRole) error {
+	result, err := a.PermissionApplication.GetEffectivePermission(permissionDto.GetEffectivePermissionInput{
+		RequesterId:  userId,
+		ResourceType: string(resourceType),
+		ResourceId:   resourceId,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to resolve %s permission: %w", resourceType, err)
+	}
+	if role := result.Permission.Role; role == nil || !role.Includes(requiredRole) {
+		return apperrors.ErrAccessDenied
+	}
+	return nil
+}
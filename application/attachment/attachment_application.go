@@ -0,0 +1,31 @@
+package attachment
+
+import (
+	"github.com/labbs/nexo/application/ports"
+	"github.com/labbs/nexo/domain"
+	"github.com/labbs/nexo/infrastructure/config"
+	"github.com/rs/zerolog"
+)
+
+type AttachmentApplication struct {
+	Config                config.Config
+	Logger                zerolog.Logger
+	AttachmentPers        domain.AttachmentPers
+	Storage               domain.FileStorage
+	DocumentPers          domain.DocumentPers
+	DrawingPers           domain.DrawingPers
+	DatabasePers          domain.DatabasePers
+	PermissionApplication ports.PermissionPort
+}
+
+func NewAttachmentApplication(config config.Config, logger zerolog.Logger, attachmentPers domain.AttachmentPers, storage domain.FileStorage, documentPers domain.DocumentPers, drawingPers domain.DrawingPers, databasePers domain.DatabasePers) *AttachmentApplication {
+	return &AttachmentApplication{
+		Config:         config,
+		Logger:         logger,
+		AttachmentPers: attachmentPers,
+		Storage:        storage,
+		DocumentPers:   documentPers,
+		DrawingPers:    drawingPers,
+		DatabasePers:   databasePers,
+	}
+}
@@ -0,0 +1,31 @@
+package attachment
+
+import (
+	"fmt"
+
+	"github.com/labbs/nexo/application/attachment/dto"
+	"github.com/labbs/nexo/domain"
+)
+
+// DeleteAttachment removes an attachment and its content. The user must be
+// able to edit the resource.
+func (a *AttachmentApplication) DeleteAttachment(input dto.DeleteAttachmentInput) error {
+	logger := a.Logger.With().Str("component", "application.attachment.delete").Logger()
+
+	attachment, err := a.getAttachment(input.UserId, input.AttachmentId, domain.PermissionRoleEditor)
+	if err != nil {
+		return err
+	}
+
+	if err := a.AttachmentPers.Delete(attachment.Id); err != nil {
+		logger.Error().Err(err).Str("attachment_id", attachment.Id).Msg("failed to delete attachment")
+		return fmt.Errorf("failed to delete attachment: %w", err)
+	}
+
+	// The metadata is gone: a leftover file is only wasted space
+	if err := a.Storage.Delete(attachment.StorageKey); err != nil {
+		logger.Warn().Err(err).Str("attachment_id", attachment.Id).Msg("failed to remove stored attachment")
+	}
+
+	return nil
+}
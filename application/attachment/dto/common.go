@@ -0,0 +1,17 @@
+package dto
+
+import "time"
+
+// Attachment represents the metadata of an attached file for the application layer
+type Attachment struct {
+	Id            string
+	SpaceId       string
+	ResourceType  string
+	ResourceId    string
+	Name          string
+	ContentType   string
+	Size          int64
+	CreatedBy     string
+	CreatedByName string
+	CreatedAt     time.Time
+}
@@ -0,0 +1,6 @@
+package dto
+
+type DeleteAttachmentInput struct {
+	UserId       string
+	AttachmentId string
+}
@@ -0,0 +1,23 @@
+package dto
+
+import "io"
+
+type GetAttachmentInput struct {
+	UserId       string
+	AttachmentId string
+}
+
+type GetAttachmentOutput struct {
+	Attachment Attachment
+}
+
+type GetContentInput struct {
+	UserId       string
+	AttachmentId string
+}
+
+// GetContentOutput holds the content of the file, the caller closes it
+type GetContentOutput struct {
+	Attachment Attachment
+	Content    io.ReadCloser
+}
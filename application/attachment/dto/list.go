@@ -0,0 +1,11 @@
+package dto
+
+type ListAttachmentsInput struct {
+	UserId       string
+	ResourceType string
+	ResourceId   string
+}
+
+type ListAttachmentsOutput struct {
+	Attachments []Attachment
+}
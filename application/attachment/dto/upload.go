@@ -0,0 +1,17 @@
+package dto
+
+import "io"
+
+type UploadInput struct {
+	UserId       string
+	ResourceType string
+	ResourceId   string
+	Name         string
+	ContentType  string
+	Size         int64
+	Content      io.Reader
+}
+
+type UploadOutput struct {
+	Attachment Attachment
+}
@@ -0,0 +1,64 @@
+package attachment
+
+import (
+	"fmt"
+
+	"github.com/labbs/nexo/application/attachment/dto"
+	"github.com/labbs/nexo/domain"
+)
+
+// getAttachment loads an attachment the user can access with the role.
+func (a *AttachmentApplication) getAttachment(userId, attachmentId string, requiredRole domain.PermissionRole) (*domain.Attachment, error) {
+	attachment, err := a.AttachmentPers.GetById(attachmentId)
+	if err != nil {
+		return nil, fmt.Errorf("attachment not found: %w", err)
+	}
+	if _, err := a.checkAccess(userId, attachment.ResourceType, attachment.ResourceId, requiredRole); err != nil {
+		return nil, err
+	}
+	return attachment, nil
+}
+
+func (a *AttachmentApplication) GetAttachment(input dto.GetAttachmentInput) (*dto.GetAttachmentOutput, error) {
+	attachment, err := a.getAttachment(input.UserId, input.AttachmentId, domain.PermissionRoleViewer)
+	if err != nil {
+		return nil, err
+	}
+	return &dto.GetAttachmentOutput{Attachment: toDto(attachment)}, nil
+}
+
+// GetContent opens the content of an attachment for download.
+func (a *AttachmentApplication) GetContent(input dto.GetContentInput) (*dto.GetContentOutput, error) {
+	logger := a.Logger.With().Str("component", "application.attachment.get_content").Logger()
+
+	attachment, err := a.getAttachment(input.UserId, input.AttachmentId, domain.PermissionRoleViewer)
+	if err != nil {
+		return nil, err
+	}
+
+	content, err := a.Storage.Get(attachment.StorageKey)
+	if err != nil {
+		logger.Error().Err(err).Str("attachment_id", attachment.Id).Msg("failed to read attachment")
+		return nil, fmt.Errorf("failed to read attachment: %w", err)
+	}
+
+	return &dto.GetContentOutput{Attachment: toDto(attachment), Content: content}, nil
+}
+
+func (a *AttachmentApplication) ListAttachments(input dto.ListAttachmentsInput) (*dto.ListAttachmentsOutput, error) {
+	resourceType := domain.AttachmentResourceType(input.ResourceType)
+	if _, err := a.checkAccess(input.UserId, resourceType, input.ResourceId, domain.PermissionRoleViewer); err != nil {
+		return nil, err
+	}
+
+	attachments, err := a.AttachmentPers.GetByResource(resourceType, input.ResourceId)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list attachments: %w", err)
+	}
+
+	output := &dto.ListAttachmentsOutput{Attachments: make([]dto.Attachment, len(attachments))}
+	for i := range attachments {
+		output.Attachments[i] = toDto(&attachments[i])
+	}
+	return output, nil
+}
@@ -0,0 +1,91 @@
+package attachment
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/labbs/nexo/application/attachment/dto"
+	"github.com/labbs/nexo/domain"
+	"github.com/labbs/nexo/infrastructure/helpers/apperrors"
+)
+
+const defaultContentType = "application/octet-stream"
+
+// Upload stores a file attached to a document, a drawing or a database. The
+// user must be able to edit the resource.
+func (a *AttachmentApplication) Upload(input dto.UploadInput) (*dto.UploadOutput, error) {
+	logger := a.Logger.With().Str("component", "application.attachment.upload").Logger()
+
+	resourceType := domain.AttachmentResourceType(input.ResourceType)
+	if !resourceType.IsValid() {
+		return nil, fmt.Errorf("%w: unknown resource type %q", apperrors.ErrInvalidInput, input.ResourceType)
+	}
+	name := filepath.Base(strings.ReplaceAll(input.Name, "\\", "/"))
+	if name == "" || name == "." || name == "/" {
+		return nil, fmt.Errorf("%w: missing file name", apperrors.ErrInvalidInput)
+	}
+	if maxSize := int64(a.Config.Storage.MaxUploadSize) * 1024 * 1024; maxSize > 0 && input.Size > maxSize {
+		return nil, fmt.Errorf("%w: file larger than %d MB", apperrors.ErrInvalidInput, a.Config.Storage.MaxUploadSize)
+	}
+
+	spaceId, err := a.checkAccess(input.UserId, resourceType, input.ResourceId, domain.PermissionRoleEditor)
+	if err != nil {
+		return nil, err
+	}
+
+	contentType := input.ContentType
+	if contentType == "" {
+		contentType = defaultContentType
+	}
+
+	attachment := &domain.Attachment{
+		Id:           uuid.New().String(),
+		SpaceId:      spaceId,
+		ResourceType: resourceType,
+		ResourceId:   input.ResourceId,
+		Name:         name,
+		ContentType:  contentType,
+		Size:         input.Size,
+		CreatedBy:    input.UserId,
+		CreatedAt:    time.Now(),
+	}
+	attachment.StorageKey = spaceId + "/" + attachment.Id
+
+	if err := a.Storage.Put(attachment.StorageKey, input.Content, input.Size, contentType); err != nil {
+		logger.Error().Err(err).Str("attachment_id", attachment.Id).Msg("failed to store attachment")
+		return nil, fmt.Errorf("failed to store attachment: %w", err)
+	}
+
+	if err := a.AttachmentPers.Create(attachment); err != nil {
+		logger.Error().Err(err).Str("attachment_id", attachment.Id).Msg("failed to create attachment")
+		if err := a.Storage.Delete(attachment.StorageKey); err != nil {
+			logger.Warn().Err(err).Str("attachment_id", attachment.Id).Msg("failed to remove stored attachment")
+		}
+		return nil, fmt.Errorf("failed to create attachment: %w", err)
+	}
+
+	// Reload with the user who uploaded the file
+	if created, err := a.AttachmentPers.GetById(attachment.Id); err == nil {
+		attachment = created
+	}
+
+	return &dto.UploadOutput{Attachment: toDto(attachment)}, nil
+}
+
+func toDto(attachment *domain.Attachment) dto.Attachment {
+	return dto.Attachment{
+		Id:            attachment.Id,
+		SpaceId:       attachment.SpaceId,
+		ResourceType:  string(attachment.ResourceType),
+		ResourceId:    attachment.ResourceId,
+		Name:          attachment.Name,
+		ContentType:   attachment.ContentType,
+		Size:          attachment.Size,
+		CreatedBy:     attachment.CreatedBy,
+		CreatedByName: attachment.User.Username,
+		CreatedAt:     attachment.CreatedAt,
+	}
+}
@@ -0,0 +1,31 @@
+package database
+
+import (
+	"fmt"
+
+	permissionDto "github.com/labbs/nexo/application/permission/dto"
+	"github.com/labbs/nexo/domain"
+	"github.com/labbs/nexo/infrastructure/helpers/apperrors"
+)
+
+// checkDatabaseAccess verifies that the user has at least the required role
+// on the database. The role is the one the permissions of the database give
+// to the user or their groups, else their role in the space, so that a
+// database can be shared beyond its space or denied within it. Viewers can
+// read the rows and the views, changing them requires the editor role.
+func (app *DatabaseApplication) checkDatabaseAccess(database *domain.Database, userId string, requiredRole domain.PermissionRole) error {
+	result, err := app.PermissionApplication.GetEffectivePermission(permissionDto.GetEffectivePermissionInput{
+		RequesterId:  userId,
+		ResourceType: string(domain.PermissionTypeDatabase),
+		ResourceId:   database.Id,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to resolve database permission: %w", err)
+	}
+
+	role := result.Permission.Role
+	if role == nil || !role.Includes(requiredRole) {
+		return apperrors.ErrAccessDenied
+	}
+	return nil
+}
@@ -3,9 +3,8 @@ package database
 import (
 	"fmt"
 
-	"github.com/labbs/nexo/infrastructure/helpers/apperrors"
 	"github.com/labbs/nexo/application/database/dto"
-	spaceDto "github.com/labbs/nexo/application/space/dto"
+	"github.com/labbs/nexo/domain"
 )
 
 func (app *DatabaseApplication) BulkDeleteRows(input dto.BulkDeleteRowsInput) error {
@@ -14,19 +13,34 @@ func (app *DatabaseApplication) BulkDeleteRows(input dto.BulkDeleteRowsInput) er
 		return fmt.Errorf("database not found: %w", err)
 	}
 
-	// Verify user has access to the space
-	spaceResult, err := app.SpaceApplication.GetSpaceById(spaceDto.GetSpaceByIdInput{SpaceId: database.SpaceId})
-	if err != nil {
-		return fmt.Errorf("space not found: %w", err)
+	// Verify user can edit the database
+	if err := app.checkDatabaseAccess(database, input.UserId, domain.PermissionRoleEditor); err != nil {
+		return err
 	}
 
-	if spaceResult.Space.GetUserRole(input.UserId) == nil {
-		return apperrors.ErrAccessDenied
+	// Keep the deleted rows to publish their events
+	var deleted []*domain.DatabaseRow
+	if app.EventApplication != nil {
+		for _, rowId := range input.RowIds {
+			if row, err := app.DatabaseRowPers.GetById(rowId); err == nil && row.DatabaseId == input.DatabaseId {
+				deleted = append(deleted, row)
+			}
+		}
 	}
 
 	if err := app.DatabaseRowPers.BulkDelete(input.RowIds); err != nil {
 		return fmt.Errorf("failed to delete rows: %w", err)
 	}
 
+	if err := app.DatabaseRowPers.DeleteRelations(input.RowIds); err != nil {
+		app.Logger.Warn().Err(err).Str("database_id", input.DatabaseId).Msg("failed to delete row relations")
+	}
+
+	app.removeRowsFromIndex(input.RowIds)
+
+	for _, row := range deleted {
+		app.publishRowEvent(domain.WebhookEventRowDeleted, input.UserId, nil, database, row, nil)
+	}
+
 	return nil
 }
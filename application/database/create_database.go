@@ -14,13 +14,13 @@ import (
 )
 
 func (app *DatabaseApplication) CreateDatabase(input dto.CreateDatabaseInput) (*dto.CreateDatabaseOutput, error) {
-	// Verify user has access to the space
+	// Verify user can edit the space
 	spaceResult, err := app.SpaceApplication.GetSpaceById(spaceDto.GetSpaceByIdInput{SpaceId: input.SpaceId})
 	if err != nil {
 		return nil, fmt.Errorf("space not found: %w", err)
 	}
 
-	if spaceResult.Space.GetUserRole(input.UserId) == nil {
+	if !spaceResult.Space.HasPermission(input.UserId, string(domain.PermissionRoleEditor)) {
 		return nil, apperrors.ErrAccessDenied
 	}
 
@@ -82,6 +82,14 @@ func (app *DatabaseApplication) CreateDatabase(input dto.CreateDatabaseInput) (*
 		UpdatedAt:   time.Now(),
 	}
 
+	if err := validateFormulas(database); err != nil {
+		return nil, err
+	}
+
+	if err := app.prepareRelations(database, nil, input.UserId); err != nil {
+		return nil, err
+	}
+
 	if err := app.DatabasePers.Create(database); err != nil {
 		return nil, fmt.Errorf("failed to create database: %w", err)
 	}
@@ -99,7 +107,7 @@ func (app *DatabaseApplication) CreateDatabase(input dto.CreateDatabaseInput) (*
 	}
 
 	// Create sample rows for new spreadsheet databases only (not for document databases)
-	if dbType == domain.DatabaseTypeSpreadsheet {
+	if dbType == domain.DatabaseTypeSpreadsheet && !input.WithoutSampleRows {
 		// Find the first "title" type column to use for sample data names
 		now := time.Now()
 		var titleColumnId string
@@ -126,6 +134,7 @@ func (app *DatabaseApplication) CreateDatabase(input dto.CreateDatabaseInput) (*
 					CreatedAt: now,
 					UpdatedAt: now,
 				}
+				newFormulaEvaluator(database).store(row)
 				// Ignore errors for sample data - not critical
 				app.DatabaseRowPers.Create(row)
 			}
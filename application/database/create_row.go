@@ -5,9 +5,7 @@ import (
 	"time"
 
 	"github.com/google/uuid"
-	"github.com/labbs/nexo/infrastructure/helpers/apperrors"
 	"github.com/labbs/nexo/application/database/dto"
-	spaceDto "github.com/labbs/nexo/application/space/dto"
 	"github.com/labbs/nexo/domain"
 )
 
@@ -17,20 +15,15 @@ func (app *DatabaseApplication) CreateRow(input dto.CreateRowInput) (*dto.Create
 		return nil, fmt.Errorf("database not found: %w", err)
 	}
 
-	// Verify user has access to the space
-	spaceResult, err := app.SpaceApplication.GetSpaceById(spaceDto.GetSpaceByIdInput{SpaceId: database.SpaceId})
-	if err != nil {
-		return nil, fmt.Errorf("space not found: %w", err)
-	}
-
-	if spaceResult.Space.GetUserRole(input.UserId) == nil {
-		return nil, apperrors.ErrAccessDenied
+	// Verify user can edit the database
+	if err := app.checkDatabaseAccess(database, input.UserId, domain.PermissionRoleEditor); err != nil {
+		return nil, err
 	}
 
 	row := &domain.DatabaseRow{
 		Id:            uuid.New().String(),
 		DatabaseId:    input.DatabaseId,
-		Properties:    domain.JSONB(input.Properties),
+		Properties:    domain.JSONB(withoutComputedProperties(database, input.Properties)),
 		Content:       domain.JSONB(input.Content),
 		ShowInSidebar: input.ShowInSidebar,
 		CreatedBy:     input.UserId,
@@ -38,13 +31,26 @@ func (app *DatabaseApplication) CreateRow(input dto.CreateRowInput) (*dto.Create
 		UpdatedAt:     time.Now(),
 	}
 
+	relations, err := app.resolveRelations(database, row, nil, input.UserId)
+	if err != nil {
+		return nil, err
+	}
+	newFormulaEvaluator(database).store(row)
+
 	if err := app.DatabaseRowPers.Create(row); err != nil {
 		return nil, fmt.Errorf("failed to create row: %w", err)
 	}
 
+	if err := app.saveRelations(row.Id, relations); err != nil {
+		return nil, err
+	}
+
+	app.indexRow(database, row)
+	app.publishRowEvent(domain.WebhookEventRowCreated, input.UserId, input.ActionChain, database, nil, row)
+
 	return &dto.CreateRowOutput{
 		Id:         row.Id,
-		Properties: input.Properties,
+		Properties: map[string]any(row.Properties),
 		CreatedAt:  row.CreatedAt,
 	}, nil
 }
@@ -6,9 +6,7 @@ import (
 	"time"
 
 	"github.com/google/uuid"
-	"github.com/labbs/nexo/infrastructure/helpers/apperrors"
 	"github.com/labbs/nexo/application/database/dto"
-	spaceDto "github.com/labbs/nexo/application/space/dto"
 	"github.com/labbs/nexo/domain"
 )
 
@@ -18,14 +16,13 @@ func (app *DatabaseApplication) CreateView(input dto.CreateViewInput) (*dto.Crea
 		return nil, fmt.Errorf("database not found: %w", err)
 	}
 
-	// Verify user has access to the space
-	spaceResult, err := app.SpaceApplication.GetSpaceById(spaceDto.GetSpaceByIdInput{SpaceId: database.SpaceId})
-	if err != nil {
-		return nil, fmt.Errorf("space not found: %w", err)
+	// Verify user can edit the database
+	if err := app.checkDatabaseAccess(database, input.UserId, domain.PermissionRoleEditor); err != nil {
+		return nil, err
 	}
 
-	if spaceResult.Space.GetUserRole(input.UserId) == nil {
-		return nil, apperrors.ErrAccessDenied
+	if err := validateViewFilter(database, input.Filter); err != nil {
+		return nil, err
 	}
 
 	// Parse existing views
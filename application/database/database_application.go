@@ -14,6 +14,8 @@ type DatabaseApplication struct {
 	DatabaseRowPers       domain.DatabaseRowPers
 	SpaceApplication      ports.SpacePort
 	PermissionApplication ports.PermissionPort
+	EventApplication      ports.EventPort
+	SearchApplication     ports.SearchPort
 }
 
 func NewDatabaseApplication(config config.Config, logger zerolog.Logger, databasePers domain.DatabasePers, databaseRowPers domain.DatabaseRowPers) *DatabaseApplication {
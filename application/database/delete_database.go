@@ -3,9 +3,8 @@ package database
 import (
 	"fmt"
 
-	"github.com/labbs/nexo/infrastructure/helpers/apperrors"
 	"github.com/labbs/nexo/application/database/dto"
-	spaceDto "github.com/labbs/nexo/application/space/dto"
+	"github.com/labbs/nexo/domain"
 )
 
 func (app *DatabaseApplication) DeleteDatabase(input dto.DeleteDatabaseInput) error {
@@ -14,14 +13,9 @@ func (app *DatabaseApplication) DeleteDatabase(input dto.DeleteDatabaseInput) er
 		return fmt.Errorf("database not found: %w", err)
 	}
 
-	// Verify user has access to the space
-	spaceResult, err := app.SpaceApplication.GetSpaceById(spaceDto.GetSpaceByIdInput{SpaceId: database.SpaceId})
-	if err != nil {
-		return fmt.Errorf("space not found: %w", err)
-	}
-
-	if spaceResult.Space.GetUserRole(input.UserId) == nil {
-		return apperrors.ErrAccessDenied
+	// Verify user can edit the database
+	if err := app.checkDatabaseAccess(database, input.UserId, domain.PermissionRoleEditor); err != nil {
+		return err
 	}
 
 	if err := app.DatabasePers.Delete(input.DatabaseId); err != nil {
@@ -5,7 +5,7 @@ import (
 
 	"github.com/labbs/nexo/infrastructure/helpers/apperrors"
 	"github.com/labbs/nexo/application/database/dto"
-	spaceDto "github.com/labbs/nexo/application/space/dto"
+	"github.com/labbs/nexo/domain"
 )
 
 func (app *DatabaseApplication) DeleteRow(input dto.DeleteRowInput) error {
@@ -23,19 +23,21 @@ func (app *DatabaseApplication) DeleteRow(input dto.DeleteRowInput) error {
 		return fmt.Errorf("database not found: %w", err)
 	}
 
-	// Verify user has access to the space
-	spaceResult, err := app.SpaceApplication.GetSpaceById(spaceDto.GetSpaceByIdInput{SpaceId: database.SpaceId})
-	if err != nil {
-		return fmt.Errorf("space not found: %w", err)
-	}
-
-	if spaceResult.Space.GetUserRole(input.UserId) == nil {
-		return apperrors.ErrAccessDenied
+	// Verify user can edit the database
+	if err := app.checkDatabaseAccess(database, input.UserId, domain.PermissionRoleEditor); err != nil {
+		return err
 	}
 
 	if err := app.DatabaseRowPers.Delete(input.RowId); err != nil {
 		return fmt.Errorf("failed to delete row: %w", err)
 	}
 
+	if err := app.DatabaseRowPers.DeleteRelations([]string{input.RowId}); err != nil {
+		app.Logger.Warn().Err(err).Str("row_id", input.RowId).Msg("failed to delete row relations")
+	}
+
+	app.removeRowsFromIndex([]string{input.RowId})
+	app.publishRowEvent(domain.WebhookEventRowDeleted, input.UserId, input.ActionChain, database, row, nil)
+
 	return nil
 }
@@ -5,9 +5,7 @@ import (
 	"fmt"
 	"time"
 
-	"github.com/labbs/nexo/infrastructure/helpers/apperrors"
 	"github.com/labbs/nexo/application/database/dto"
-	spaceDto "github.com/labbs/nexo/application/space/dto"
 	"github.com/labbs/nexo/domain"
 )
 
@@ -17,14 +15,9 @@ func (app *DatabaseApplication) DeleteView(input dto.DeleteViewInput) error {
 		return fmt.Errorf("database not found: %w", err)
 	}
 
-	// Verify user has access to the space
-	spaceResult, err := app.SpaceApplication.GetSpaceById(spaceDto.GetSpaceByIdInput{SpaceId: database.SpaceId})
-	if err != nil {
-		return fmt.Errorf("space not found: %w", err)
-	}
-
-	if spaceResult.Space.GetUserRole(input.UserId) == nil {
-		return apperrors.ErrAccessDenied
+	// Verify user can edit the database
+	if err := app.checkDatabaseAccess(database, input.UserId, domain.PermissionRoleEditor); err != nil {
+		return err
 	}
 
 	// Parse existing views
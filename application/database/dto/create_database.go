@@ -11,6 +11,9 @@ type CreateDatabaseInput struct {
 	Icon        string
 	Schema      []PropertySchema
 	Type        string // "spreadsheet" or "document", defaults to "spreadsheet"
+	// WithoutSampleRows creates a spreadsheet without its sample rows, for
+	// the databases whose rows are added next (e.g. imports)
+	WithoutSampleRows bool
 }
 
 type CreateDatabaseOutput struct {
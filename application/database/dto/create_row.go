@@ -8,6 +8,9 @@ type CreateRowInput struct {
 	Properties    map[string]any
 	Content       map[string]any
 	ShowInSidebar bool
+	// ActionChain lists the actions whose steps make the change, oldest
+	// first, see ActionApplication.ExecuteActions
+	ActionChain []string
 }
 
 type CreateRowOutput struct {
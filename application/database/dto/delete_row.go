@@ -4,6 +4,9 @@ type DeleteRowInput struct {
 	UserId     string
 	DatabaseId string
 	RowId      string
+	// ActionChain lists the actions whose steps make the change, oldest
+	// first, see ActionApplication.ExecuteActions
+	ActionChain []string
 }
 
 type BulkDeleteRowsInput struct {
@@ -0,0 +1,18 @@
+package dto
+
+type PatchRowInput struct {
+	UserId     string
+	DatabaseId string
+	RowId      string
+	// Properties are merged into the properties of the row
+	Properties map[string]any
+	// Content replaces the content of the row when not nil
+	Content map[string]any
+	// ActionChain lists the actions whose steps make the change, oldest
+	// first, see ActionApplication.ExecuteActions
+	ActionChain []string
+}
+
+type PatchRowOutput struct {
+	Properties map[string]any
+}
@@ -7,4 +7,7 @@ type UpdateRowInput struct {
 	Properties    map[string]any
 	Content       map[string]any
 	ShowInSidebar *bool
+	// ActionChain lists the actions whose steps make the change, oldest
+	// first, see ActionApplication.ExecuteActions
+	ActionChain []string
 }
@@ -0,0 +1,82 @@
+package database
+
+import (
+	"reflect"
+
+	eventDto "github.com/labbs/nexo/application/event/dto"
+	"github.com/labbs/nexo/domain"
+)
+
+// publishRowEvent publishes a row event scoped to the space and database of
+// the row. before or after may be nil (respectively on creation and deletion).
+// chain is the ActionChain of the input making the change.
+func (app *DatabaseApplication) publishRowEvent(event domain.WebhookEvent, userId string, chain []string, database *domain.Database, before, after *domain.DatabaseRow) {
+	app.publishRowEventWithData(event, userId, chain, database, before, after, nil)
+}
+
+// publishPropertyChangedEvents publishes one property.changed event per
+// property whose value differs between the two versions of a row.
+func (app *DatabaseApplication) publishPropertyChangedEvents(userId string, chain []string, database *domain.Database, before, after *domain.DatabaseRow) {
+	for propertyId, change := range propertyChanges(before.Properties, after.Properties) {
+		property := map[string]any{
+			"id":   propertyId,
+			"from": change[0],
+			"to":   change[1],
+		}
+		if schema := schemaProperty(database, propertyId); schema != nil {
+			property["name"] = schema["name"]
+			property["type"] = schema["type"]
+		}
+		app.publishRowEventWithData(domain.WebhookEventPropertyChanged, userId, chain, database, before, after, map[string]any{"property": property})
+	}
+}
+
+func (app *DatabaseApplication) publishRowEventWithData(event domain.WebhookEvent, userId string, chain []string, database *domain.Database, before, after *domain.DatabaseRow, data map[string]any) {
+	if app.EventApplication == nil {
+		return
+	}
+
+	row := after
+	if row == nil {
+		row = before
+	}
+
+	app.EventApplication.Publish(eventDto.PublishEventInput{
+		Event:        event,
+		ActorId:      userId,
+		SpaceId:      &database.SpaceId,
+		DatabaseId:   &database.Id,
+		ResourceType: eventDto.ResourceTypeRow,
+		ResourceId:   row.Id,
+		Before:       eventDto.RowSnapshot(before),
+		After:        eventDto.RowSnapshot(after),
+		Data:         data,
+		ActionChain:  chain,
+	})
+}
+
+// propertyChanges returns {propertyId: [from, to]} for every property that changed
+func propertyChanges(before, after map[string]any) map[string][2]any {
+	changes := make(map[string][2]any)
+	for key, oldValue := range before {
+		if newValue := after[key]; !reflect.DeepEqual(oldValue, newValue) {
+			changes[key] = [2]any{oldValue, newValue}
+		}
+	}
+	for key, newValue := range after {
+		if _, ok := before[key]; !ok && newValue != nil {
+			changes[key] = [2]any{nil, newValue}
+		}
+	}
+	return changes
+}
+
+// schemaProperty returns the schema entry ({id, name, type, options}) of a property
+func schemaProperty(database *domain.Database, propertyId string) map[string]any {
+	for _, item := range database.Schema {
+		if property, ok := item.(map[string]any); ok && property["id"] == propertyId {
+			return property
+		}
+	}
+	return nil
+}
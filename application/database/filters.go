@@ -0,0 +1,235 @@
+package database
+
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/labbs/nexo/domain"
+	"github.com/labbs/nexo/infrastructure/helpers/apperrors"
+)
+
+// maxFilterDepth is the maximum nesting of filter groups
+const maxFilterDepth = 5
+
+// systemProperties are the row columns that can be filtered on without being
+// declared in the schema
+var systemProperties = map[string]domain.PropertyType{
+	"created_time": domain.PropertyTypeCreatedTime,
+	"updated_time": domain.PropertyTypeUpdatedTime,
+	"created_by":   domain.PropertyTypeCreatedBy,
+	"updated_by":   domain.PropertyTypeUpdatedBy,
+}
+
+// convertFilterConfigToDomain converts the view filter config, a tree of
+// {"and": [...], "or": [...]} groups, to the domain filter config
+func convertFilterConfigToDomain(filter map[string]any) *domain.FilterConfig {
+	if filter == nil {
+		return nil
+	}
+
+	result := &domain.FilterConfig{}
+	filterJSON, _ := json.Marshal(filter)
+	if err := json.Unmarshal(filterJSON, result); err != nil {
+		return nil
+	}
+	return result
+}
+
+// validateViewFilter checks the filter of a view against the database schema
+func validateViewFilter(database *domain.Database, filter map[string]any) error {
+	if len(filter) == 0 {
+		return nil
+	}
+	result := &domain.FilterConfig{}
+	filterJSON, _ := json.Marshal(filter)
+	if err := json.Unmarshal(filterJSON, result); err != nil {
+		return fmt.Errorf("%w: invalid filter: %s", apperrors.ErrInvalidInput, err.Error())
+	}
+	_, err := resolveFilter(database, result, true)
+	return err
+}
+
+// legacyConditions are the conditions of the views saved before the
+// conditions depended on the property type, which allowed them on every
+// property: see resolveFilterRule
+var legacyConditions = []string{
+	domain.FilterEq, domain.FilterNeq,
+	domain.FilterGt, domain.FilterLt, domain.FilterGte, domain.FilterLte,
+	domain.FilterContains, domain.FilterNotContains,
+	domain.FilterStartsWith, domain.FilterEndsWith,
+	domain.FilterIsEmpty, domain.FilterIsNotEmpty,
+}
+
+// resolveFilter sets the property type of every rule of the filter from the
+// database schema. When strict, a rule on an unknown property, with a condition
+// that the property type does not allow or with an invalid value is an
+// ErrInvalidInput error. Otherwise, for the filters of saved views, rules on
+// unknown properties and legacy conditions the type does not allow are
+// applied to the plain value, as they were when the view was saved, and the
+// rules that no longer apply to their property (e.g. after its type changed)
+// are removed from the filter and returned.
+func resolveFilter(database *domain.Database, filter *domain.FilterConfig, strict bool) ([]domain.FilterRule, error) {
+	if filter == nil {
+		return nil, nil
+	}
+	var skipped []domain.FilterRule
+	var err error
+	if filter.And, err = resolveFilterRules(database, filter.And, strict, 1, &skipped); err != nil {
+		return nil, fmt.Errorf("%w: %s", apperrors.ErrInvalidInput, err.Error())
+	}
+	if filter.Or, err = resolveFilterRules(database, filter.Or, strict, 1, &skipped); err != nil {
+		return nil, fmt.Errorf("%w: %s", apperrors.ErrInvalidInput, err.Error())
+	}
+	return skipped, nil
+}
+
+// resolveFilterRules resolves the rules and returns the ones that apply;
+// the others are added to skipped
+func resolveFilterRules(database *domain.Database, rules []domain.FilterRule, strict bool, depth int, skipped *[]domain.FilterRule) ([]domain.FilterRule, error) {
+	resolved := rules[:0]
+	for _, rule := range rules {
+		applies, err := resolveFilterRule(database, &rule, strict, depth, skipped)
+		if err != nil {
+			return nil, err
+		}
+		if applies {
+			resolved = append(resolved, rule)
+		}
+	}
+	return resolved, nil
+}
+
+func resolveFilterRule(database *domain.Database, rule *domain.FilterRule, strict bool, depth int, skipped *[]domain.FilterRule) (bool, error) {
+	if rule.IsGroup() {
+		if depth >= maxFilterDepth {
+			return false, fmt.Errorf("filter groups cannot be nested more than %d levels deep", maxFilterDepth)
+		}
+		var err error
+		if rule.And, err = resolveFilterRules(database, rule.And, strict, depth+1, skipped); err != nil {
+			return false, err
+		}
+		if rule.Or, err = resolveFilterRules(database, rule.Or, strict, depth+1, skipped); err != nil {
+			return false, err
+		}
+		// A group whose rules were all skipped is dropped with them
+		return rule.IsGroup(), nil
+	}
+
+	propertyType, ok := systemProperties[rule.Property]
+	if property := schemaProperty(database, rule.Property); property != nil {
+		schemaType, _ := property["type"].(string)
+		propertyType, ok = domain.PropertyType(schemaType), true
+
+		// Formulas using rollups are not stored, as rollups
+		if id, _ := property["id"].(string); propertyType == domain.PropertyTypeFormula && rollupFormula(database, id) {
+			if !strict {
+				*skipped = append(*skipped, *rule)
+				return false, nil
+			}
+			return false, fmt.Errorf("formula property %q uses rollups and cannot be filtered", rule.Property)
+		}
+	}
+	if !ok {
+		if !strict {
+			// Not in the schema: filtered as a plain value
+			return true, nil
+		}
+		return false, fmt.Errorf("unknown filter property %q", rule.Property)
+	}
+
+	condition, ok := propertyType.FilterCondition(rule.Condition)
+	if !ok && !strict && slices.Contains(legacyConditions, rule.Condition) {
+		// e.g. contains on a select or gt on a text: filtered as a plain value
+		rule.Type = ""
+		return true, nil
+	}
+	if ok {
+		if err := validateFilterValue(condition.Value, rule.Value); err != nil {
+			if strict {
+				return false, fmt.Errorf("invalid value for %q on property %q: %w", condition.Condition, rule.Property, err)
+			}
+			ok = false
+		}
+	}
+	if !ok {
+		if !strict {
+			*skipped = append(*skipped, *rule)
+			return false, nil
+		}
+		return false, fmt.Errorf("condition %q is not allowed on %s property %q", rule.Condition, propertyType, rule.Property)
+	}
+
+	rule.Condition = condition.Condition
+	rule.Type = propertyType
+	return true, nil
+}
+
+// validateFilterValue checks a rule value against the kind of value its
+// condition expects. Empty values are allowed: the rule is then ignored, as
+// while a filter is being edited.
+func validateFilterValue(kind domain.FilterValueKind, value any) error {
+	if value == nil || value == "" {
+		return nil
+	}
+
+	switch kind {
+	case domain.FilterValueNumber:
+		if !isFilterNumber(value) {
+			return fmt.Errorf("a number is expected")
+		}
+	case domain.FilterValueBoolean:
+		if _, ok := value.(bool); !ok {
+			return fmt.Errorf("true or false is expected")
+		}
+	case domain.FilterValueOptions:
+		if _, ok := value.([]any); !ok {
+			return fmt.Errorf("a list is expected")
+		}
+	case domain.FilterValueDate:
+		if !isFilterDate(value) {
+			return fmt.Errorf("a date (YYYY-MM-DD or RFC 3339) is expected")
+		}
+	case domain.FilterValueRange:
+		bounds, ok := value.([]any)
+		if !ok || len(bounds) != 2 {
+			return fmt.Errorf("a [from, to] range is expected")
+		}
+		for _, bound := range bounds {
+			if bound != nil && bound != "" && !isFilterNumber(bound) && !isFilterDate(bound) {
+				return fmt.Errorf("range bounds must be numbers or dates")
+			}
+		}
+	case domain.FilterValueRelative:
+		if s, ok := value.(string); !ok || !slices.Contains(domain.RelativeDates, s) {
+			return fmt.Errorf("one of %s is expected", strings.Join(domain.RelativeDates, ", "))
+		}
+	}
+	return nil
+}
+
+func isFilterNumber(value any) bool {
+	switch v := value.(type) {
+	case float64:
+		return true
+	case string:
+		_, err := strconv.ParseFloat(strings.TrimSpace(v), 64)
+		return err == nil
+	}
+	return false
+}
+
+func isFilterDate(value any) bool {
+	s, ok := value.(string)
+	if !ok {
+		return false
+	}
+	if _, err := time.Parse(time.DateOnly, s); err == nil {
+		return true
+	}
+	_, err := time.Parse(time.RFC3339, s)
+	return err == nil
+}
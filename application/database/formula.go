@@ -0,0 +1,366 @@
+package database
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// Formula properties are computed by the server from an expression stored in
+// the schema (options.expression), e.g.
+//
+//	if(prop("Done"), "✓", formatDate(dateAdd(prop("Due"), 7, "days"), "YYYY-MM-DD"))
+//
+// The language has number, string and boolean literals, the operators
+// + - * / % ^, == != < > <= >=, && || ! (precedence from the lowest: ||, &&,
+// !, comparisons, + -, * / %, unary -, ^), parentheses and function calls
+// (see formulaFunctions). prop("Name") reads another property of the row by
+// name or id.
+
+// formulaNode is a node of a parsed formula
+type formulaNode interface {
+	eval(env *formulaEnv) (any, error)
+}
+
+type formulaLiteral struct {
+	value any
+}
+
+type formulaUnary struct {
+	operator string
+	operand  formulaNode
+}
+
+type formulaBinary struct {
+	operator    string
+	left, right formulaNode
+}
+
+type formulaCall struct {
+	name string
+	args []formulaNode
+}
+
+// formulaToken is a token of a formula: a number, a string, an identifier or
+// an operator / punctuation
+type formulaToken struct {
+	kind  byte // 'n' number, 's' string, 'i' identifier, 'o' operator, 0 end
+	text  string
+	value any
+	pos   int
+}
+
+// parseFormula parses a formula expression
+func parseFormula(expression string) (formulaNode, error) {
+	tokens, err := tokenizeFormula(expression)
+	if err != nil {
+		return nil, err
+	}
+	p := &formulaParser{tokens: tokens}
+	node, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if token := p.peek(); token.kind != 0 {
+		return nil, fmt.Errorf("unexpected %q at position %d", token.text, token.pos+1)
+	}
+	return node, nil
+}
+
+func tokenizeFormula(expression string) ([]formulaToken, error) {
+	var tokens []formulaToken
+	runes := []rune(expression)
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		switch {
+		case unicode.IsSpace(r):
+			i++
+		case unicode.IsDigit(r) || r == '.' && i+1 < len(runes) && unicode.IsDigit(runes[i+1]):
+			start := i
+			for i < len(runes) && (unicode.IsDigit(runes[i]) || runes[i] == '.') {
+				i++
+			}
+			number, err := strconv.ParseFloat(string(runes[start:i]), 64)
+			if err != nil {
+				return nil, fmt.Errorf("invalid number %q at position %d", string(runes[start:i]), start+1)
+			}
+			tokens = append(tokens, formulaToken{kind: 'n', text: string(runes[start:i]), value: number, pos: start})
+		case r == '"' || r == '\'':
+			start := i
+			var text strings.Builder
+			for i++; i < len(runes) && runes[i] != r; i++ {
+				if runes[i] == '\\' && i+1 < len(runes) {
+					i++
+					switch runes[i] {
+					case 'n':
+						text.WriteRune('\n')
+						continue
+					case 't':
+						text.WriteRune('\t')
+						continue
+					}
+				}
+				text.WriteRune(runes[i])
+			}
+			if i >= len(runes) {
+				return nil, fmt.Errorf("unterminated string at position %d", start+1)
+			}
+			i++
+			tokens = append(tokens, formulaToken{kind: 's', text: string(runes[start:i]), value: text.String(), pos: start})
+		case unicode.IsLetter(r) || r == '_':
+			start := i
+			for i < len(runes) && (unicode.IsLetter(runes[i]) || unicode.IsDigit(runes[i]) || runes[i] == '_') {
+				i++
+			}
+			tokens = append(tokens, formulaToken{kind: 'i', text: string(runes[start:i]), pos: start})
+		default:
+			operator := string(r)
+			if i+1 < len(runes) {
+				switch two := string(runes[i : i+2]); two {
+				case "==", "!=", "<=", ">=", "&&", "||":
+					operator = two
+				}
+			}
+			if len(operator) == 1 && !strings.ContainsRune("+-*/%^<>!(),", r) {
+				return nil, fmt.Errorf("unexpected %q at position %d", operator, i+1)
+			}
+			tokens = append(tokens, formulaToken{kind: 'o', text: operator, pos: i})
+			i += len([]rune(operator))
+		}
+	}
+	return tokens, nil
+}
+
+type formulaParser struct {
+	tokens []formulaToken
+	pos    int
+}
+
+func (p *formulaParser) peek() formulaToken {
+	if p.pos < len(p.tokens) {
+		return p.tokens[p.pos]
+	}
+	return formulaToken{pos: -1}
+}
+
+// accept consumes the next token when it is one of the operators
+func (p *formulaParser) accept(operators ...string) (string, bool) {
+	token := p.peek()
+	if token.kind != 'o' && token.kind != 'i' {
+		return "", false
+	}
+	for _, operator := range operators {
+		if token.text == operator {
+			p.pos++
+			return operator, true
+		}
+	}
+	return "", false
+}
+
+func (p *formulaParser) expect(operator string) error {
+	if _, ok := p.accept(operator); !ok {
+		token := p.peek()
+		if token.kind == 0 {
+			return fmt.Errorf("expected %q at the end of the formula", operator)
+		}
+		return fmt.Errorf("expected %q at position %d, got %q", operator, token.pos+1, token.text)
+	}
+	return nil
+}
+
+func (p *formulaParser) parseOr() (formulaNode, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		if _, ok := p.accept("||", "or"); !ok {
+			return left, nil
+		}
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = formulaBinary{operator: "||", left: left, right: right}
+	}
+}
+
+func (p *formulaParser) parseAnd() (formulaNode, error) {
+	left, err := p.parseNot()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		if _, ok := p.accept("&&", "and"); !ok {
+			return left, nil
+		}
+		right, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		left = formulaBinary{operator: "&&", left: left, right: right}
+	}
+}
+
+func (p *formulaParser) parseNot() (formulaNode, error) {
+	if _, ok := p.accept("!", "not"); ok {
+		operand, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		return formulaUnary{operator: "!", operand: operand}, nil
+	}
+	return p.parseComparison()
+}
+
+func (p *formulaParser) parseComparison() (formulaNode, error) {
+	left, err := p.parseAdditive()
+	if err != nil {
+		return nil, err
+	}
+	operator, ok := p.accept("==", "!=", "<=", ">=", "<", ">")
+	if !ok {
+		return left, nil
+	}
+	right, err := p.parseAdditive()
+	if err != nil {
+		return nil, err
+	}
+	return formulaBinary{operator: operator, left: left, right: right}, nil
+}
+
+func (p *formulaParser) parseAdditive() (formulaNode, error) {
+	left, err := p.parseMultiplicative()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		operator, ok := p.accept("+", "-")
+		if !ok {
+			return left, nil
+		}
+		right, err := p.parseMultiplicative()
+		if err != nil {
+			return nil, err
+		}
+		left = formulaBinary{operator: operator, left: left, right: right}
+	}
+}
+
+func (p *formulaParser) parseMultiplicative() (formulaNode, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		operator, ok := p.accept("*", "/", "%")
+		if !ok {
+			return left, nil
+		}
+		right, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		left = formulaBinary{operator: operator, left: left, right: right}
+	}
+}
+
+func (p *formulaParser) parseUnary() (formulaNode, error) {
+	if _, ok := p.accept("-"); ok {
+		operand, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return formulaUnary{operator: "-", operand: operand}, nil
+	}
+	return p.parsePower()
+}
+
+func (p *formulaParser) parsePower() (formulaNode, error) {
+	base, err := p.parsePrimary()
+	if err != nil {
+		return nil, err
+	}
+	if _, ok := p.accept("^"); !ok {
+		return base, nil
+	}
+	exponent, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	return formulaBinary{operator: "^", left: base, right: exponent}, nil
+}
+
+func (p *formulaParser) parsePrimary() (formulaNode, error) {
+	token := p.peek()
+	switch token.kind {
+	case 0:
+		return nil, fmt.Errorf("unexpected end of the formula")
+	case 'n', 's':
+		p.pos++
+		return formulaLiteral{value: token.value}, nil
+	case 'o':
+		if token.text != "(" {
+			return nil, fmt.Errorf("unexpected %q at position %d", token.text, token.pos+1)
+		}
+		p.pos++
+		node, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		return node, p.expect(")")
+	}
+
+	// Identifier: constant or function call
+	switch token.text {
+	case "true", "false":
+		p.pos++
+		return formulaLiteral{value: token.text == "true"}, nil
+	case "null":
+		p.pos++
+		return formulaLiteral{value: nil}, nil
+	}
+	name := token.text
+	if _, ok := formulaFunctions[name]; !ok {
+		return nil, fmt.Errorf("unknown function %q at position %d", name, token.pos+1)
+	}
+	p.pos++
+	if err := p.expect("("); err != nil {
+		return nil, err
+	}
+	call := formulaCall{name: name}
+	if _, ok := p.accept(")"); ok {
+		return call, call.checkArity()
+	}
+	for {
+		arg, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		call.args = append(call.args, arg)
+		if _, ok := p.accept(","); !ok {
+			break
+		}
+	}
+	if err := p.expect(")"); err != nil {
+		return nil, err
+	}
+	return call, call.checkArity()
+}
+
+func (c formulaCall) checkArity() error {
+	function := formulaFunctions[c.name]
+	if len(c.args) < function.min || function.max >= 0 && len(c.args) > function.max {
+		switch {
+		case function.min == function.max:
+			return fmt.Errorf("%s expects %d argument(s), got %d", c.name, function.min, len(c.args))
+		case function.max < 0:
+			return fmt.Errorf("%s expects at least %d argument(s), got %d", c.name, function.min, len(c.args))
+		default:
+			return fmt.Errorf("%s expects %d to %d arguments, got %d", c.name, function.min, function.max, len(c.args))
+		}
+	}
+	return nil
+}
@@ -0,0 +1,339 @@
+package database
+
+import (
+	"cmp"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/labbs/nexo/domain"
+)
+
+// formulaEnv is the row a formula is evaluated on. Formulas referencing other
+// formulas are evaluated on demand, circular references are errors.
+type formulaEnv struct {
+	row        *domain.DatabaseRow
+	properties map[string]map[string]any // schema properties by id
+	names      map[string]string         // property ids by lower-case name
+	formulas   map[string]formulaNode    // parsed formulas by property id
+	values     map[string]any            // evaluated formulas by property id
+	evaluating map[string]bool
+	now        time.Time
+}
+
+// prop returns the value of a property of the row, by name or id
+func (env *formulaEnv) prop(name string) (any, error) {
+	id := name
+	if _, ok := env.properties[id]; !ok {
+		if id, ok = env.names[strings.ToLower(name)]; !ok {
+			return nil, fmt.Errorf("unknown property %q", name)
+		}
+	}
+
+	if formula, ok := env.formulas[id]; ok {
+		if value, ok := env.values[id]; ok {
+			return value, nil
+		}
+		if env.evaluating[id] {
+			return nil, fmt.Errorf("circular reference to property %q", name)
+		}
+		env.evaluating[id] = true
+		value, err := formula.eval(env)
+		delete(env.evaluating, id)
+		if err != nil {
+			return nil, err
+		}
+		env.values[id] = value
+		return value, nil
+	}
+
+	propertyType, _ := env.properties[id]["type"].(string)
+	switch domain.PropertyType(propertyType) {
+	case domain.PropertyTypeCreatedTime:
+		return env.row.CreatedAt, nil
+	case domain.PropertyTypeUpdatedTime:
+		return env.row.UpdatedAt, nil
+	case domain.PropertyTypeCreatedBy:
+		return env.row.CreatedBy, nil
+	case domain.PropertyTypeUpdatedBy:
+		return env.row.UpdatedBy, nil
+	case domain.PropertyTypeDate:
+		// Dates are stored as strings, or as {"start", "end"} ranges
+		if value, ok := env.row.Properties[id].(map[string]any); ok {
+			return value["start"], nil
+		}
+	}
+	return env.row.Properties[id], nil
+}
+
+func (n formulaLiteral) eval(_ *formulaEnv) (any, error) {
+	return n.value, nil
+}
+
+func (n formulaUnary) eval(env *formulaEnv) (any, error) {
+	value, err := n.operand.eval(env)
+	if err != nil {
+		return nil, err
+	}
+	if n.operator == "!" {
+		return !formulaTruthy(value), nil
+	}
+	number, err := formulaNumber(value)
+	if err != nil {
+		return nil, err
+	}
+	return -number, nil
+}
+
+func (n formulaBinary) eval(env *formulaEnv) (any, error) {
+	left, err := n.left.eval(env)
+	if err != nil {
+		return nil, err
+	}
+
+	// Short-circuit
+	switch n.operator {
+	case "&&":
+		if !formulaTruthy(left) {
+			return false, nil
+		}
+	case "||":
+		if formulaTruthy(left) {
+			return true, nil
+		}
+	}
+
+	right, err := n.right.eval(env)
+	if err != nil {
+		return nil, err
+	}
+
+	switch n.operator {
+	case "&&", "||":
+		return formulaTruthy(right), nil
+	case "==":
+		return formulaEqual(left, right), nil
+	case "!=":
+		return !formulaEqual(left, right), nil
+	case "<", ">", "<=", ">=":
+		c, err := formulaCompare(left, right)
+		if err != nil {
+			return nil, err
+		}
+		switch n.operator {
+		case "<":
+			return c < 0, nil
+		case ">":
+			return c > 0, nil
+		case "<=":
+			return c <= 0, nil
+		default:
+			return c >= 0, nil
+		}
+	case "+":
+		// + concatenates as soon as one side is text
+		_, leftText := left.(string)
+		_, rightText := right.(string)
+		if leftText || rightText {
+			return formulaText(left) + formulaText(right), nil
+		}
+	}
+
+	a, err := formulaNumber(left)
+	if err != nil {
+		return nil, err
+	}
+	b, err := formulaNumber(right)
+	if err != nil {
+		return nil, err
+	}
+	switch n.operator {
+	case "+":
+		return a + b, nil
+	case "-":
+		return a - b, nil
+	case "*":
+		return a * b, nil
+	case "/":
+		if b == 0 {
+			return nil, fmt.Errorf("division by zero")
+		}
+		return a / b, nil
+	case "%":
+		if b == 0 {
+			return nil, fmt.Errorf("division by zero")
+		}
+		return math.Mod(a, b), nil
+	case "^":
+		return math.Pow(a, b), nil
+	}
+	return nil, fmt.Errorf("unknown operator %q", n.operator)
+}
+
+func (n formulaCall) eval(env *formulaEnv) (any, error) {
+	// if only evaluates the branch it returns
+	if n.name == "if" {
+		condition, err := n.args[0].eval(env)
+		if err != nil {
+			return nil, err
+		}
+		if formulaTruthy(condition) {
+			return n.args[1].eval(env)
+		}
+		return n.args[2].eval(env)
+	}
+
+	args := make([]any, len(n.args))
+	for i, arg := range n.args {
+		value, err := arg.eval(env)
+		if err != nil {
+			return nil, err
+		}
+		args[i] = value
+	}
+	value, err := formulaFunctions[n.name].call(env, args)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", n.name, err)
+	}
+	return value, nil
+}
+
+// formulaTruthy converts a value to a boolean: false, null, 0, "" and empty
+// lists are false
+func formulaTruthy(value any) bool {
+	switch v := value.(type) {
+	case nil:
+		return false
+	case bool:
+		return v
+	case float64:
+		return v != 0
+	case string:
+		return v != ""
+	case []any:
+		return len(v) > 0
+	}
+	return true
+}
+
+// formulaNumber converts a value to a number: empty values are 0, booleans
+// are 0 or 1 and text must be numeric
+func formulaNumber(value any) (float64, error) {
+	switch v := value.(type) {
+	case nil:
+		return 0, nil
+	case float64:
+		return v, nil
+	case int:
+		return float64(v), nil
+	case bool:
+		if v {
+			return 1, nil
+		}
+		return 0, nil
+	case string:
+		if strings.TrimSpace(v) == "" {
+			return 0, nil
+		}
+		number, err := strconv.ParseFloat(strings.TrimSpace(v), 64)
+		if err != nil {
+			return 0, fmt.Errorf("%q is not a number", v)
+		}
+		return number, nil
+	}
+	return 0, fmt.Errorf("%s is not a number", formulaText(value))
+}
+
+// formulaDate converts a value to a date: RFC 3339 or YYYY-MM-DD (midnight UTC)
+func formulaDate(value any) (time.Time, error) {
+	switch v := value.(type) {
+	case time.Time:
+		return v, nil
+	case string:
+		if date, err := time.Parse(time.RFC3339, v); err == nil {
+			return date, nil
+		}
+		if date, err := time.Parse(time.DateOnly, v); err == nil {
+			return date, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("%s is not a date", formulaText(value))
+}
+
+// formulaText converts a value to text
+func formulaText(value any) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case bool:
+		return strconv.FormatBool(v)
+	case time.Time:
+		return v.Format(time.RFC3339)
+	case []any:
+		items := make([]string, len(v))
+		for i, item := range v {
+			items[i] = formulaText(item)
+		}
+		return strings.Join(items, ", ")
+	}
+	return fmt.Sprint(value)
+}
+
+func formulaEqual(a, b any) bool {
+	if a == nil || b == nil {
+		return formulaText(a) == formulaText(b)
+	}
+	if c, err := formulaCompare(a, b); err == nil {
+		return c == 0
+	}
+	return formulaText(a) == formulaText(b)
+}
+
+// formulaCompare compares numbers, dates, then text
+func formulaCompare(a, b any) (int, error) {
+	_, aNumber := a.(float64)
+	_, bNumber := b.(float64)
+	if aNumber || bNumber {
+		x, errA := formulaNumber(a)
+		y, errB := formulaNumber(b)
+		if errA == nil && errB == nil {
+			return cmp.Compare(x, y), nil
+		}
+	}
+	_, aDate := a.(time.Time)
+	_, bDate := b.(time.Time)
+	if aDate || bDate {
+		x, errA := formulaDate(a)
+		y, errB := formulaDate(b)
+		if errA == nil && errB == nil {
+			return x.Compare(y), nil
+		}
+		return 0, fmt.Errorf("cannot compare %s and %s", formulaText(a), formulaText(b))
+	}
+	_, aBool := a.(bool)
+	_, bBool := b.(bool)
+	if aBool || bBool {
+		return 0, fmt.Errorf("cannot compare %s and %s", formulaText(a), formulaText(b))
+	}
+	return strings.Compare(formulaText(a), formulaText(b)), nil
+}
+
+// formulaResult converts the result of a formula to the value stored in the
+// row properties: dates as RFC 3339 text, NaN and infinities as null
+func formulaResult(value any) any {
+	switch v := value.(type) {
+	case time.Time:
+		return v.UTC().Format(time.RFC3339)
+	case float64:
+		if math.IsNaN(v) || math.IsInf(v, 0) {
+			return nil
+		}
+	}
+	return value
+}
@@ -0,0 +1,367 @@
+package database
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// formulaFunction is a function of the formula language. max is -1 for
+// functions taking any number of arguments.
+type formulaFunction struct {
+	min, max int
+	call     func(env *formulaEnv, args []any) (any, error)
+}
+
+// formulaFunctions are the functions of the formula language, by name.
+// if is evaluated by formulaCall.eval so that only one branch is evaluated.
+var formulaFunctions map[string]formulaFunction
+
+func init() {
+	formulaFunctions = map[string]formulaFunction{
+		// Properties and conditions
+		"prop":  {1, 1, formulaProp},
+		"if":    {3, 3, nil},
+		"empty": {1, 1, func(_ *formulaEnv, args []any) (any, error) { return !formulaTruthy(args[0]), nil }},
+
+		// Numbers
+		"abs":      {1, 1, numberFunction(math.Abs)},
+		"ceil":     {1, 1, numberFunction(math.Ceil)},
+		"floor":    {1, 1, numberFunction(math.Floor)},
+		"sqrt":     {1, 1, numberFunction(math.Sqrt)},
+		"round":    {1, 2, formulaRound},
+		"pow":      {2, 2, formulaPow},
+		"min":      {1, -1, formulaMin},
+		"max":      {1, -1, formulaMax},
+		"sum":      {1, -1, formulaSum},
+		"toNumber": {1, 1, func(_ *formulaEnv, args []any) (any, error) { return formulaNumber(args[0]) }},
+
+		// Text
+		"concat":     {1, -1, formulaConcat},
+		"format":     {1, 1, func(_ *formulaEnv, args []any) (any, error) { return formulaText(args[0]), nil }},
+		"length":     {1, 1, formulaLength},
+		"upper":      {1, 1, textFunction(strings.ToUpper)},
+		"lower":      {1, 1, textFunction(strings.ToLower)},
+		"trim":       {1, 1, textFunction(strings.TrimSpace)},
+		"contains":   {2, 2, formulaContains},
+		"replace":    {3, 3, formulaReplace(1)},
+		"replaceAll": {3, 3, formulaReplace(-1)},
+		"slice":      {2, 3, formulaSlice},
+		"join":       {2, 2, formulaJoin},
+
+		// Dates
+		"now":          {0, 0, func(env *formulaEnv, _ []any) (any, error) { return env.now, nil }},
+		"today":        {0, 0, formulaToday},
+		"parseDate":    {1, 1, func(_ *formulaEnv, args []any) (any, error) { return formulaDate(args[0]) }},
+		"dateAdd":      {3, 3, formulaDateAdd(1)},
+		"dateSubtract": {3, 3, formulaDateAdd(-1)},
+		"dateBetween":  {3, 3, formulaDateBetween},
+		"formatDate":   {1, 2, formulaFormatDate},
+		"year":         {1, 1, dateFunction(func(t time.Time) int { return t.Year() })},
+		"month":        {1, 1, dateFunction(func(t time.Time) int { return int(t.Month()) })},
+		"day":          {1, 1, dateFunction(func(t time.Time) int { return t.Day() })},
+	}
+}
+
+func formulaProp(env *formulaEnv, args []any) (any, error) {
+	name, ok := args[0].(string)
+	if !ok {
+		return nil, fmt.Errorf("the property name must be text")
+	}
+	return env.prop(name)
+}
+
+func numberFunction(f func(float64) float64) func(*formulaEnv, []any) (any, error) {
+	return func(_ *formulaEnv, args []any) (any, error) {
+		number, err := formulaNumber(args[0])
+		if err != nil {
+			return nil, err
+		}
+		return f(number), nil
+	}
+}
+
+func textFunction(f func(string) string) func(*formulaEnv, []any) (any, error) {
+	return func(_ *formulaEnv, args []any) (any, error) {
+		return f(formulaText(args[0])), nil
+	}
+}
+
+func dateFunction(f func(time.Time) int) func(*formulaEnv, []any) (any, error) {
+	return func(_ *formulaEnv, args []any) (any, error) {
+		if args[0] == nil {
+			return nil, nil
+		}
+		date, err := formulaDate(args[0])
+		if err != nil {
+			return nil, err
+		}
+		return float64(f(date)), nil
+	}
+}
+
+// formulaNumbers converts the arguments to numbers, lists are flattened
+func formulaNumbers(args []any) ([]float64, error) {
+	var numbers []float64
+	for _, arg := range args {
+		if items, ok := arg.([]any); ok {
+			itemNumbers, err := formulaNumbers(items)
+			if err != nil {
+				return nil, err
+			}
+			numbers = append(numbers, itemNumbers...)
+			continue
+		}
+		number, err := formulaNumber(arg)
+		if err != nil {
+			return nil, err
+		}
+		numbers = append(numbers, number)
+	}
+	return numbers, nil
+}
+
+func formulaRound(_ *formulaEnv, args []any) (any, error) {
+	number, err := formulaNumber(args[0])
+	if err != nil {
+		return nil, err
+	}
+	digits := 0.0
+	if len(args) > 1 {
+		if digits, err = formulaNumber(args[1]); err != nil {
+			return nil, err
+		}
+	}
+	scale := math.Pow(10, math.Trunc(digits))
+	return math.Round(number*scale) / scale, nil
+}
+
+func formulaPow(_ *formulaEnv, args []any) (any, error) {
+	numbers, err := formulaNumbers(args)
+	if err != nil {
+		return nil, err
+	}
+	return math.Pow(numbers[0], numbers[1]), nil
+}
+
+func formulaMin(_ *formulaEnv, args []any) (any, error) {
+	numbers, err := formulaNumbers(args)
+	if err != nil || len(numbers) == 0 {
+		return nil, err
+	}
+	result := numbers[0]
+	for _, number := range numbers[1:] {
+		result = math.Min(result, number)
+	}
+	return result, nil
+}
+
+func formulaMax(_ *formulaEnv, args []any) (any, error) {
+	numbers, err := formulaNumbers(args)
+	if err != nil || len(numbers) == 0 {
+		return nil, err
+	}
+	result := numbers[0]
+	for _, number := range numbers[1:] {
+		result = math.Max(result, number)
+	}
+	return result, nil
+}
+
+func formulaSum(_ *formulaEnv, args []any) (any, error) {
+	numbers, err := formulaNumbers(args)
+	if err != nil {
+		return nil, err
+	}
+	sum := 0.0
+	for _, number := range numbers {
+		sum += number
+	}
+	return sum, nil
+}
+
+func formulaConcat(_ *formulaEnv, args []any) (any, error) {
+	var text strings.Builder
+	for _, arg := range args {
+		text.WriteString(formulaText(arg))
+	}
+	return text.String(), nil
+}
+
+// formulaLength returns the number of characters of text or of items of a list
+func formulaLength(_ *formulaEnv, args []any) (any, error) {
+	if items, ok := args[0].([]any); ok {
+		return float64(len(items)), nil
+	}
+	return float64(utf8.RuneCountInString(formulaText(args[0]))), nil
+}
+
+// formulaContains reports whether text contains a substring, or a list an item
+func formulaContains(_ *formulaEnv, args []any) (any, error) {
+	if items, ok := args[0].([]any); ok {
+		for _, item := range items {
+			if formulaEqual(item, args[1]) {
+				return true, nil
+			}
+		}
+		return false, nil
+	}
+	return strings.Contains(formulaText(args[0]), formulaText(args[1])), nil
+}
+
+func formulaReplace(count int) func(*formulaEnv, []any) (any, error) {
+	return func(_ *formulaEnv, args []any) (any, error) {
+		return strings.Replace(formulaText(args[0]), formulaText(args[1]), formulaText(args[2]), count), nil
+	}
+}
+
+// formulaSlice returns the characters of text from start (included) to end
+// (excluded, defaults to the end of the text)
+func formulaSlice(_ *formulaEnv, args []any) (any, error) {
+	runes := []rune(formulaText(args[0]))
+	bounds := []int{0, len(runes)}
+	for i, arg := range args[1:] {
+		number, err := formulaNumber(arg)
+		if err != nil {
+			return nil, err
+		}
+		bound := int(number)
+		if bound < 0 {
+			bound += len(runes)
+		}
+		bounds[i] = max(0, min(bound, len(runes)))
+	}
+	if bounds[0] > bounds[1] {
+		return "", nil
+	}
+	return string(runes[bounds[0]:bounds[1]]), nil
+}
+
+func formulaJoin(_ *formulaEnv, args []any) (any, error) {
+	items, ok := args[0].([]any)
+	if !ok {
+		return formulaText(args[0]), nil
+	}
+	texts := make([]string, len(items))
+	for i, item := range items {
+		texts[i] = formulaText(item)
+	}
+	return strings.Join(texts, formulaText(args[1])), nil
+}
+
+func formulaToday(env *formulaEnv, _ []any) (any, error) {
+	now := env.now.UTC()
+	return time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC), nil
+}
+
+// formulaDateAdd adds (sign 1) or subtracts (sign -1) an amount of a unit
+// (years, months, weeks, days, hours, minutes, seconds) to a date
+func formulaDateAdd(sign int) func(*formulaEnv, []any) (any, error) {
+	return func(_ *formulaEnv, args []any) (any, error) {
+		if args[0] == nil {
+			return nil, nil
+		}
+		date, err := formulaDate(args[0])
+		if err != nil {
+			return nil, err
+		}
+		amount, err := formulaNumber(args[1])
+		if err != nil {
+			return nil, err
+		}
+		n := sign * int(amount)
+		switch strings.TrimSuffix(formulaText(args[2]), "s") {
+		case "year":
+			return date.AddDate(n, 0, 0), nil
+		case "quarter":
+			return date.AddDate(0, 3*n, 0), nil
+		case "month":
+			return date.AddDate(0, n, 0), nil
+		case "week":
+			return date.AddDate(0, 0, 7*n), nil
+		case "day":
+			return date.AddDate(0, 0, n), nil
+		case "hour":
+			return date.Add(time.Duration(n) * time.Hour), nil
+		case "minute":
+			return date.Add(time.Duration(n) * time.Minute), nil
+		case "second":
+			return date.Add(time.Duration(n) * time.Second), nil
+		}
+		return nil, fmt.Errorf("unknown unit %q", formulaText(args[2]))
+	}
+}
+
+// formulaDateBetween returns the number of whole units between two dates,
+// negative when the first date is before the second
+func formulaDateBetween(_ *formulaEnv, args []any) (any, error) {
+	if args[0] == nil || args[1] == nil {
+		return nil, nil
+	}
+	a, err := formulaDate(args[0])
+	if err != nil {
+		return nil, err
+	}
+	b, err := formulaDate(args[1])
+	if err != nil {
+		return nil, err
+	}
+
+	switch unit := strings.TrimSuffix(formulaText(args[2]), "s"); unit {
+	case "year", "quarter", "month":
+		months := (a.Year()-b.Year())*12 + int(a.Month()) - int(b.Month())
+		// An incomplete month does not count
+		if months > 0 && a.AddDate(0, -months, 0).Before(b) {
+			months--
+		} else if months < 0 && a.AddDate(0, -months, 0).After(b) {
+			months++
+		}
+		switch unit {
+		case "year":
+			return float64(months / 12), nil
+		case "quarter":
+			return float64(months / 3), nil
+		}
+		return float64(months), nil
+	case "week":
+		return math.Trunc(a.Sub(b).Hours() / (24 * 7)), nil
+	case "day":
+		return math.Trunc(a.Sub(b).Hours() / 24), nil
+	case "hour":
+		return math.Trunc(a.Sub(b).Hours()), nil
+	case "minute":
+		return math.Trunc(a.Sub(b).Minutes()), nil
+	case "second":
+		return math.Trunc(a.Sub(b).Seconds()), nil
+	}
+	return nil, fmt.Errorf("unknown unit %q", formulaText(args[2]))
+}
+
+// dateLayout converts the tokens of a date format (YYYY, MMMM, MMM, MM, DD,
+// HH, mm, ss) to a Go layout
+var dateLayout = strings.NewReplacer(
+	"YYYY", "2006", "YY", "06",
+	"MMMM", "January", "MMM", "Jan", "MM", "01",
+	"DD", "02", "HH", "15", "mm", "04", "ss", "05",
+)
+
+// formulaFormatDate formats a date, by default as YYYY-MM-DD, or
+// YYYY-MM-DD HH:mm when it has a time
+func formulaFormatDate(_ *formulaEnv, args []any) (any, error) {
+	if args[0] == nil {
+		return "", nil
+	}
+	date, err := formulaDate(args[0])
+	if err != nil {
+		return nil, err
+	}
+	layout := "YYYY-MM-DD"
+	if len(args) > 1 {
+		layout = formulaText(args[1])
+	} else if date.Hour() != 0 || date.Minute() != 0 || date.Second() != 0 {
+		layout = "YYYY-MM-DD HH:mm"
+	}
+	return date.Format(dateLayout.Replace(layout)), nil
+}
@@ -0,0 +1,255 @@
+package database
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/labbs/nexo/domain"
+)
+
+var formulaNow = time.Date(2026, 3, 15, 10, 30, 0, 0, time.UTC)
+
+// formulaSchema is the schema of the rows the formulas are evaluated on
+var formulaSchema = domain.JSONBArray{
+	map[string]any{"id": "p1", "name": "Price", "type": "number"},
+	map[string]any{"id": "p2", "name": "Name", "type": "text"},
+	map[string]any{"id": "p3", "name": "Due", "type": "date"},
+	map[string]any{"id": "p4", "name": "Tags", "type": "multi_select"},
+	map[string]any{"id": "p5", "name": "Total", "type": "rollup"},
+	map[string]any{"id": "p6", "name": "Notes", "type": "text"},
+	map[string]any{"id": "f1", "name": "Double", "type": "formula", "options": map[string]any{"expression": `prop("Price") * 2`}},
+	map[string]any{"id": "f2", "name": "Age", "type": "formula", "options": map[string]any{"expression": `dateBetween(today(), prop("Due"), "days")`}},
+	map[string]any{"id": "f3", "name": "Share", "type": "formula", "options": map[string]any{"expression": `prop("Double") / prop("Total")`}},
+	map[string]any{"id": "f4", "name": "Late", "type": "formula", "options": map[string]any{"expression": `prop("Age") > 0`}},
+}
+
+var formulaRow = domain.JSONB{
+	"p1": float64(21),
+	"p2": "Nexo",
+	"p3": map[string]any{"start": "2026-03-01", "end": nil},
+	"p4": []any{"a", "b"},
+	"p5": float64(84),
+}
+
+// evalFormula evaluates an expression on formulaRow at formulaNow
+func evalFormula(t *testing.T, expression string) (any, error) {
+	t.Helper()
+	formula, err := parseFormula(expression)
+	if err != nil {
+		t.Fatalf("parseFormula(%q): %v", expression, err)
+	}
+	evaluator := newFormulaEvaluator(&domain.Database{Schema: formulaSchema})
+	env := &formulaEnv{
+		row:        &domain.DatabaseRow{Properties: formulaRow},
+		properties: evaluator.properties,
+		names:      evaluator.names,
+		formulas:   evaluator.formulas,
+		values:     map[string]any{},
+		evaluating: map[string]bool{},
+		now:        formulaNow,
+	}
+	value, err := formula.eval(env)
+	return formulaResult(value), err
+}
+
+func TestFormulaValues(t *testing.T) {
+	tests := []struct {
+		expression string
+		want       any
+	}{
+		// Precedence
+		{`1 + 2 * 3`, float64(7)},
+		{`(1 + 2) * 3`, float64(9)},
+		{`2 ^ 3 ^ 2`, float64(512)},
+		{`-2 ^ 2`, float64(-4)},
+		{`10 - 4 - 3`, float64(3)},
+		{`7 % 4 * 2`, float64(6)},
+		{`1 < 2 && 2 < 1 || true`, true},
+		{`!1 == 2`, true},
+		{`"a" + 1 + 2`, "a12"},
+		{`1 + 2 + "a"`, "3a"},
+
+		// Properties, by name or id, and formulas referencing formulas
+		{`prop("price") + prop("p1")`, float64(42)},
+		{`prop("Double")`, float64(42)},
+		{`prop("Share")`, float64(0.5)},
+		{`prop("Due")`, "2026-03-01"},
+		{`length(prop("Tags"))`, float64(2)},
+
+		// Null and NaN
+		{`empty(prop("Notes"))`, true},
+		{`prop("Notes") + 1`, float64(1)},
+		{`prop("Notes") == ""`, true},
+		{`year(prop("Notes"))`, nil},
+		{`sqrt(-1)`, nil},
+		{`1 / 0 > 1`, nil},
+		{`empty("")`, true},
+		{`if(0, "yes", "no")`, "no"},
+		{`if(true, 1, 1 / 0)`, float64(1)},
+
+		// Numbers
+		{`round(2.345, 2)`, float64(2.35)},
+		{`round(-2.5)`, float64(-3)},
+		{`min(3, 1, 2)`, float64(1)},
+		{`max(1, prop("Notes"), -2)`, float64(1)},
+		{`sum(1, 2, 3)`, float64(6)},
+		{`toNumber("12.5")`, float64(12.5)},
+
+		// Text
+		{`upper(prop("Name"))`, "NEXO"},
+		{`concat("a", 1, true)`, "a1true"},
+		{`contains(prop("Tags"), "b")`, true},
+		{`replaceAll("a-b-c", "-", "+")`, "a+b+c"},
+		{`join(prop("Tags"), "/")`, "a/b"},
+		{`length("héllo")`, float64(5)},
+		{`slice("héllo", 1, 3)`, "él"},
+		{`slice("hello", -3)`, "llo"},
+		{`slice("hello", 1, -1)`, "ell"},
+		{`slice("hello", -10, 2)`, "he"},
+		{`slice("hello", 4, 2)`, ""},
+
+		// Dates
+		{`now()`, "2026-03-15T10:30:00Z"},
+		{`today()`, "2026-03-15T00:00:00Z"},
+		{`prop("Age")`, float64(14)},
+		{`prop("Late")`, true},
+		{`dateAdd(prop("Due"), 2, "weeks")`, "2026-03-15T00:00:00Z"},
+		{`dateSubtract("2026-03-31", 1, "month")`, "2026-03-03T00:00:00Z"},
+		{`dateBetween("2026-03-31", "2026-01-31", "months")`, float64(2)},
+		{`dateBetween("2026-03-30", "2026-01-31", "months")`, float64(1)},
+		{`dateBetween("2026-01-31", "2026-03-30", "months")`, float64(-1)},
+		{`dateBetween("2026-01-31", "2026-03-31", "months")`, float64(-2)},
+		{`dateBetween("2027-03-14", "2026-03-15", "years")`, float64(0)},
+		{`dateBetween("2027-03-15", "2026-03-15", "years")`, float64(1)},
+		{`dateBetween(now(), "2026-03-15", "hours")`, float64(10)},
+		{`formatDate(prop("Due"), "DD MMMM YYYY")`, "01 March 2026"},
+		{`formatDate(now())`, "2026-03-15 10:30"},
+		{`year(prop("Due")) * 100 + month(prop("Due"))`, float64(202603)},
+	}
+
+	for _, tt := range tests {
+		got, err := evalFormula(t, tt.expression)
+		if err != nil {
+			// Errors evaluate to null, as in formulaEvaluator.compute
+			got = nil
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s = %#v, want %#v", tt.expression, got, tt.want)
+		}
+	}
+}
+
+func TestFormulaErrors(t *testing.T) {
+	tests := []struct {
+		expression string
+		err        string
+	}{
+		{`1 / 0`, "division by zero"},
+		{`5 % 0`, "division by zero"},
+		{`"a" * 2`, `"a" is not a number`},
+		{`prop("Missing")`, `unknown property "Missing"`},
+		{`prop(1)`, "the property name must be text"},
+		{`now() < 1`, "cannot compare"},
+		{`true < false`, "cannot compare"},
+		{`dateAdd(now(), 1, "fortnight")`, `unknown unit "fortnight"`},
+		{`parseDate("tomorrow")`, "tomorrow is not a date"},
+	}
+
+	for _, tt := range tests {
+		_, err := evalFormula(t, tt.expression)
+		if err == nil || !strings.Contains(err.Error(), tt.err) {
+			t.Errorf("%s: err = %v, want %q", tt.expression, err, tt.err)
+		}
+	}
+}
+
+func TestParseFormulaErrors(t *testing.T) {
+	tests := []struct {
+		expression string
+		err        string
+	}{
+		{`1 +`, "unexpected end of the formula"},
+		{`(1 + 2`, `expected ")" at the end of the formula`},
+		{`1 2`, `unexpected "2" at position 3`},
+		{`"open`, "unterminated string at position 1"},
+		{`1.2.3`, `invalid number "1.2.3" at position 1`},
+		{`1 # 2`, `unexpected "#" at position 3`},
+		{`nope(1)`, `unknown function "nope" at position 1`},
+		{`abs()`, "abs expects 1 argument(s), got 0"},
+		{`if(true, 1)`, "if expects 3 argument(s), got 2"},
+		{`min()`, "min expects at least 1 argument(s), got 0"},
+		{`round(1, 2, 3)`, "round expects 1 to 2 arguments, got 3"},
+		{`now(1)`, "now expects 0 argument(s), got 1"},
+	}
+
+	for _, tt := range tests {
+		_, err := parseFormula(tt.expression)
+		if err == nil || err.Error() != tt.err {
+			t.Errorf("parseFormula(%q): err = %v, want %q", tt.expression, err, tt.err)
+		}
+	}
+}
+
+func TestValidateFormulas(t *testing.T) {
+	formula := func(id, expression string) map[string]any {
+		return map[string]any{"id": id, "name": id, "type": "formula", "options": map[string]any{"expression": expression}}
+	}
+	tests := []struct {
+		name   string
+		schema domain.JSONBArray
+		err    string
+	}{
+		{"valid", formulaSchema, ""},
+		{"invalid", domain.JSONBArray{formula("a", `1 +`)}, `invalid formula for property "a"`},
+		{"unknown property", domain.JSONBArray{formula("a", `prop("b")`)}, `references unknown property "b"`},
+		{"self reference", domain.JSONBArray{formula("a", `prop("a")`)}, `circular reference in the formula of property "a"`},
+		{"indirect cycle", domain.JSONBArray{formula("a", `prop("b")`), formula("b", `prop("a") + 1`)}, "circular reference"},
+	}
+
+	for _, tt := range tests {
+		err := validateFormulas(&domain.Database{Schema: tt.schema})
+		if tt.err == "" && err != nil || tt.err != "" && (err == nil || !strings.Contains(err.Error(), tt.err)) {
+			t.Errorf("%s: err = %v, want %q", tt.name, err, tt.err)
+		}
+	}
+}
+
+func TestFormulaDependencies(t *testing.T) {
+	evaluator := newFormulaEvaluator(&domain.Database{Schema: formulaSchema})
+
+	for id, want := range map[string][2]bool{"f1": {false, false}, "f2": {true, false}, "f3": {false, true}, "f4": {true, false}} {
+		if got := [2]bool{evaluator.timeDependent[id], evaluator.rollupDependent[id]}; got != want {
+			t.Errorf("%s: time and rollup dependent = %v, want %v", id, got, want)
+		}
+	}
+	if newFormulaEvaluator(&domain.Database{Schema: formulaSchema[:5]}) != nil {
+		t.Errorf("newFormulaEvaluator of a schema without formula is not nil")
+	}
+}
+
+func TestFormulaStore(t *testing.T) {
+	evaluator := newFormulaEvaluator(&domain.Database{Schema: formulaSchema})
+	row := &domain.DatabaseRow{Properties: domain.JSONB{"p1": float64(21), "p5": float64(84)}}
+
+	if !evaluator.store(row) {
+		t.Fatalf("store of a new row reports no change")
+	}
+	if got := row.Properties["f1"]; got != float64(42) {
+		t.Errorf("stored Double = %v, want 42", got)
+	}
+	if got, ok := row.Properties["f3"]; !ok || got != nil {
+		t.Errorf("stored Share = %v, want null", got)
+	}
+	if evaluator.store(row) {
+		t.Errorf("store of an unchanged row reports a change")
+	}
+
+	if !evaluator.apply(row) {
+		t.Fatalf("apply reports no change")
+	}
+	if got := row.Properties["f3"]; got != float64(0.5) {
+		t.Errorf("read Share = %v, want 0.5", got)
+	}
+}
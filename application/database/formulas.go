@@ -0,0 +1,317 @@
+package database
+
+import (
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/labbs/nexo/domain"
+	"github.com/labbs/nexo/infrastructure/helpers/apperrors"
+)
+
+// Formula values are computed when a row is written and stored in its
+// properties, so that formula columns can be filtered and sorted like the
+// others; they are computed again when rows are read, and every row is updated
+// when the formulas of the schema change. The formulas using now() or today()
+// are refreshed by a job, see RefreshTimeFormulas; the formulas using rollups,
+// which depend on the reader, are not stored.
+
+// formulaEvaluator computes the formula properties of the rows of a database
+type formulaEvaluator struct {
+	properties map[string]map[string]any
+	names      map[string]string
+	formulas   map[string]formulaNode
+	// timeDependent and rollupDependent are the formulas using now() or
+	// today(), or rollups, directly or through other formulas
+	timeDependent   map[string]bool
+	rollupDependent map[string]bool
+}
+
+// formulaExpression returns the expression of a formula property, "" when the
+// property is not a formula
+func formulaExpression(property map[string]any) string {
+	if property["type"] != string(domain.PropertyTypeFormula) {
+		return ""
+	}
+	options, _ := property["options"].(map[string]any)
+	expression, _ := options["expression"].(string)
+	return strings.TrimSpace(expression)
+}
+
+// newFormulaEvaluator parses the formulas of the database schema; it returns
+// nil when the schema has no formula. Invalid formulas evaluate to null.
+func newFormulaEvaluator(database *domain.Database) *formulaEvaluator {
+	evaluator := &formulaEvaluator{
+		properties: map[string]map[string]any{},
+		names:      map[string]string{},
+		formulas:   map[string]formulaNode{},
+	}
+	for _, item := range database.Schema {
+		property, ok := item.(map[string]any)
+		if !ok {
+			continue
+		}
+		id, _ := property["id"].(string)
+		name, _ := property["name"].(string)
+		evaluator.properties[id] = property
+		evaluator.names[strings.ToLower(name)] = id
+
+		if expression := formulaExpression(property); expression != "" {
+			formula, err := parseFormula(expression)
+			if err != nil {
+				formula = formulaLiteral{value: nil}
+			}
+			evaluator.formulas[id] = formula
+		}
+	}
+	if len(evaluator.formulas) == 0 {
+		return nil
+	}
+	evaluator.resolveDependencies()
+	return evaluator
+}
+
+// propertyId returns the id of a property referenced by name or id
+func (e *formulaEvaluator) propertyId(name string) (string, bool) {
+	if _, ok := e.properties[name]; ok {
+		return name, true
+	}
+	id, ok := e.names[strings.ToLower(name)]
+	return id, ok
+}
+
+// resolveDependencies finds the formulas using now() or today(), or rollups,
+// directly or through the formulas they reference
+func (e *formulaEvaluator) resolveDependencies() {
+	e.timeDependent = map[string]bool{}
+	e.rollupDependent = map[string]bool{}
+	visited := map[string]bool{}
+	var visit func(id string)
+	visit = func(id string) {
+		if visited[id] {
+			return
+		}
+		visited[id] = true
+		formula := e.formulas[id]
+		if formulaUses(formula, "now", "today") {
+			e.timeDependent[id] = true
+		}
+		for _, name := range formulaReferences(formula) {
+			referenced, ok := e.propertyId(name)
+			if !ok {
+				continue
+			}
+			if e.properties[referenced]["type"] == string(domain.PropertyTypeRollup) {
+				e.rollupDependent[id] = true
+			}
+			if _, ok := e.formulas[referenced]; ok {
+				visit(referenced)
+				e.timeDependent[id] = e.timeDependent[id] || e.timeDependent[referenced]
+				e.rollupDependent[id] = e.rollupDependent[id] || e.rollupDependent[referenced]
+			}
+		}
+	}
+	for id := range e.formulas {
+		visit(id)
+	}
+}
+
+// apply computes the formula properties of a row being read, once its rollups
+// are computed, and reports whether a value changed. A formula that fails
+// (e.g. a division by zero) is null.
+func (e *formulaEvaluator) apply(row *domain.DatabaseRow) bool {
+	return e.compute(row, false)
+}
+
+// store computes the formula properties of a row being written and reports
+// whether a value changed. The formulas using rollups are stored as null.
+func (e *formulaEvaluator) store(row *domain.DatabaseRow) bool {
+	return e.compute(row, true)
+}
+
+func (e *formulaEvaluator) compute(row *domain.DatabaseRow, stored bool) bool {
+	if e == nil {
+		return false
+	}
+	if row.Properties == nil {
+		row.Properties = domain.JSONB{}
+	}
+
+	env := &formulaEnv{
+		row:        row,
+		properties: e.properties,
+		names:      e.names,
+		formulas:   e.formulas,
+		values:     map[string]any{},
+		evaluating: map[string]bool{},
+		now:        time.Now(),
+	}
+	changed := false
+	for id := range e.formulas {
+		value, err := env.prop(id)
+		if err != nil || (stored && e.rollupDependent[id]) {
+			value = nil
+		}
+		value = formulaResult(value)
+		if current, ok := row.Properties[id]; !ok || !reflect.DeepEqual(current, value) {
+			changed = true
+		}
+		row.Properties[id] = value
+	}
+	return changed
+}
+
+// validateFormulas checks the formulas of a schema: they must parse, only
+// reference existing properties and not reference themselves, even indirectly
+func validateFormulas(database *domain.Database) error {
+	evaluator := newFormulaEvaluator(database)
+	if evaluator == nil {
+		return nil
+	}
+
+	references := map[string][]string{}
+	for id := range evaluator.formulas {
+		property := evaluator.properties[id]
+		formula, err := parseFormula(formulaExpression(property))
+		if err != nil {
+			return fmt.Errorf("%w: invalid formula for property %q: %s", apperrors.ErrInvalidInput, property["name"], err.Error())
+		}
+		for _, name := range formulaReferences(formula) {
+			referenced, ok := evaluator.propertyId(name)
+			if !ok {
+				return fmt.Errorf("%w: formula of property %q references unknown property %q", apperrors.ErrInvalidInput, property["name"], name)
+			}
+			references[id] = append(references[id], referenced)
+		}
+	}
+
+	// Depth-first search of a reference cycle
+	const (
+		visiting = 1
+		done     = 2
+	)
+	state := map[string]int{}
+	var visit func(id string) error
+	visit = func(id string) error {
+		switch state[id] {
+		case visiting:
+			return fmt.Errorf("%w: circular reference in the formula of property %q", apperrors.ErrInvalidInput, evaluator.properties[id]["name"])
+		case done:
+			return nil
+		}
+		state[id] = visiting
+		for _, referenced := range references[id] {
+			if err := visit(referenced); err != nil {
+				return err
+			}
+		}
+		state[id] = done
+		return nil
+	}
+	for id := range evaluator.formulas {
+		if err := visit(id); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// formulaReferences returns the properties referenced by prop("...") calls
+func formulaReferences(node formulaNode) []string {
+	var references []string
+	switch n := node.(type) {
+	case formulaUnary:
+		references = formulaReferences(n.operand)
+	case formulaBinary:
+		references = append(formulaReferences(n.left), formulaReferences(n.right)...)
+	case formulaCall:
+		if n.name == "prop" {
+			if literal, ok := n.args[0].(formulaLiteral); ok {
+				if name, ok := literal.value.(string); ok {
+					references = append(references, name)
+				}
+			}
+		}
+		for _, arg := range n.args {
+			references = append(references, formulaReferences(arg)...)
+		}
+	}
+	return references
+}
+
+// formulaUses reports whether a formula calls one of the functions
+func formulaUses(node formulaNode, functions ...string) bool {
+	switch n := node.(type) {
+	case formulaUnary:
+		return formulaUses(n.operand, functions...)
+	case formulaBinary:
+		return formulaUses(n.left, functions...) || formulaUses(n.right, functions...)
+	case formulaCall:
+		if slices.Contains(functions, n.name) {
+			return true
+		}
+		for _, arg := range n.args {
+			if formulaUses(arg, functions...) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// rollupFormula reports whether a property is a formula using rollups, which
+// is not stored
+func rollupFormula(database *domain.Database, propertyId string) bool {
+	evaluator := newFormulaEvaluator(database)
+	return evaluator != nil && evaluator.rollupDependent[propertyId]
+}
+
+// refreshFormulas computes the formulas of every row of the database again,
+// after its schema changed
+func (app *DatabaseApplication) refreshFormulas(database *domain.Database) error {
+	return app.refreshRows(database, newFormulaEvaluator(database))
+}
+
+// RefreshTimeFormulas computes again the formulas using now() or today(),
+// whose stored values get out of date
+func (app *DatabaseApplication) RefreshTimeFormulas() error {
+	logger := app.Logger.With().Str("component", "application.database.refresh_time_formulas").Logger()
+
+	databases, err := app.DatabasePers.GetWithFormulas()
+	if err != nil {
+		return fmt.Errorf("failed to get databases with formulas: %w", err)
+	}
+	for i := range databases {
+		evaluator := newFormulaEvaluator(&databases[i])
+		if evaluator == nil || len(evaluator.timeDependent) == 0 {
+			continue
+		}
+		if err := app.refreshRows(&databases[i], evaluator); err != nil {
+			logger.Warn().Err(err).Str("database_id", databases[i].Id).Msg("failed to refresh formulas")
+		}
+	}
+	return nil
+}
+
+// refreshRows stores the formulas of every row of the database again
+func (app *DatabaseApplication) refreshRows(database *domain.Database, evaluator *formulaEvaluator) error {
+	if evaluator == nil {
+		return nil
+	}
+
+	rows, err := app.DatabaseRowPers.GetByDatabaseId(database.Id, 0, 0)
+	if err != nil {
+		return fmt.Errorf("failed to get rows: %w", err)
+	}
+	for i := range rows {
+		if !evaluator.store(&rows[i]) {
+			continue
+		}
+		if err := app.DatabaseRowPers.UpdateProperties(rows[i].Id, rows[i].Properties); err != nil {
+			return fmt.Errorf("failed to update row %s: %w", rows[i].Id, err)
+		}
+	}
+	return nil
+}
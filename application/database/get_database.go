@@ -4,9 +4,8 @@ import (
 	"encoding/json"
 	"fmt"
 
-	"github.com/labbs/nexo/infrastructure/helpers/apperrors"
 	"github.com/labbs/nexo/application/database/dto"
-	spaceDto "github.com/labbs/nexo/application/space/dto"
+	"github.com/labbs/nexo/domain"
 )
 
 func (app *DatabaseApplication) GetDatabase(input dto.GetDatabaseInput) (*dto.GetDatabaseOutput, error) {
@@ -15,14 +14,9 @@ func (app *DatabaseApplication) GetDatabase(input dto.GetDatabaseInput) (*dto.Ge
 		return nil, fmt.Errorf("database not found: %w", err)
 	}
 
-	// Verify user has access to the space
-	spaceResult, err := app.SpaceApplication.GetSpaceById(spaceDto.GetSpaceByIdInput{SpaceId: database.SpaceId})
-	if err != nil {
-		return nil, fmt.Errorf("space not found: %w", err)
-	}
-
-	if spaceResult.Space.GetUserRole(input.UserId) == nil {
-		return nil, apperrors.ErrAccessDenied
+	// Verify user can read the database
+	if err := app.checkDatabaseAccess(database, input.UserId, domain.PermissionRoleViewer); err != nil {
+		return nil, err
 	}
 
 	// Parse schema
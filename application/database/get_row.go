@@ -5,7 +5,7 @@ import (
 
 	"github.com/labbs/nexo/infrastructure/helpers/apperrors"
 	"github.com/labbs/nexo/application/database/dto"
-	spaceDto "github.com/labbs/nexo/application/space/dto"
+	"github.com/labbs/nexo/domain"
 )
 
 func (app *DatabaseApplication) GetRow(input dto.GetRowInput) (*dto.GetRowOutput, error) {
@@ -23,15 +23,15 @@ func (app *DatabaseApplication) GetRow(input dto.GetRowInput) (*dto.GetRowOutput
 		return nil, fmt.Errorf("database not found: %w", err)
 	}
 
-	// Verify user has access to the space
-	spaceResult, err := app.SpaceApplication.GetSpaceById(spaceDto.GetSpaceByIdInput{SpaceId: database.SpaceId})
-	if err != nil {
-		return nil, fmt.Errorf("space not found: %w", err)
+	// Verify user can read the database
+	if err := app.checkDatabaseAccess(database, input.UserId, domain.PermissionRoleViewer); err != nil {
+		return nil, err
 	}
 
-	if spaceResult.Space.GetUserRole(input.UserId) == nil {
-		return nil, apperrors.ErrAccessDenied
+	if err := app.computeRollups(database, []*domain.DatabaseRow{row}, input.UserId); err != nil {
+		return nil, err
 	}
+	newFormulaEvaluator(database).apply(row)
 
 	output := &dto.GetRowOutput{
 		Id:            row.Id,
@@ -5,32 +5,45 @@ import (
 
 	"github.com/labbs/nexo/infrastructure/helpers/apperrors"
 	"github.com/labbs/nexo/application/database/dto"
-	spaceDto "github.com/labbs/nexo/application/space/dto"
+	permissionDto "github.com/labbs/nexo/application/permission/dto"
+	"github.com/labbs/nexo/domain"
 )
 
+// ListDatabases lists the databases of a space the user can read, including
+// those shared with users who cannot read the space.
 func (app *DatabaseApplication) ListDatabases(input dto.ListDatabasesInput) (*dto.ListDatabasesOutput, error) {
-	// Verify user has access to the space
-	spaceResult, err := app.SpaceApplication.GetSpaceById(spaceDto.GetSpaceByIdInput{SpaceId: input.SpaceId})
+	databases, err := app.DatabasePers.GetBySpaceId(input.SpaceId)
 	if err != nil {
-		return nil, fmt.Errorf("space not found: %w", err)
+		return nil, fmt.Errorf("failed to list databases: %w", err)
 	}
 
-	if spaceResult.Space.GetUserRole(input.UserId) == nil {
-		return nil, apperrors.ErrAccessDenied
+	// Resolve the role of the user on every database at once
+	ids := make([]string, len(databases))
+	for i, db := range databases {
+		ids[i] = db.Id
 	}
-
-	databases, err := app.DatabasePers.GetBySpaceId(input.SpaceId)
+	roles, err := app.PermissionApplication.GetEffectiveRoles(permissionDto.GetEffectiveRolesInput{
+		UserId:       input.UserId,
+		ResourceType: string(domain.PermissionTypeDatabase),
+		SpaceId:      input.SpaceId,
+		ResourceIds:  ids,
+	})
 	if err != nil {
-		return nil, fmt.Errorf("failed to list databases: %w", err)
+		return nil, fmt.Errorf("failed to resolve database permissions: %w", err)
 	}
 
 	output := &dto.ListDatabasesOutput{
-		Databases: make([]dto.DatabaseItem, len(databases)),
+		Databases: make([]dto.DatabaseItem, 0, len(databases)),
 	}
 
-	for i, db := range databases {
+	for _, db := range databases {
+		// Skip the databases the user cannot read
+		if role := roles.Roles[db.Id]; role == nil || !role.Includes(domain.PermissionRoleViewer) {
+			continue
+		}
+
 		rowCount, _ := app.DatabaseRowPers.GetRowCount(db.Id)
-		output.Databases[i] = dto.DatabaseItem{
+		output.Databases = append(output.Databases, dto.DatabaseItem{
 			Id:          db.Id,
 			DocumentId:  db.DocumentId,
 			Name:        db.Name,
@@ -41,7 +54,12 @@ func (app *DatabaseApplication) ListDatabases(input dto.ListDatabasesInput) (*dt
 			CreatedBy:   db.User.Username,
 			CreatedAt:   db.CreatedAt,
 			UpdatedAt:   db.UpdatedAt,
-		}
+		})
+	}
+
+	// Outside the space, and none of its databases is shared with the user
+	if len(output.Databases) == 0 && (roles.SpaceRole == nil || !roles.SpaceRole.Includes(domain.PermissionRoleViewer)) {
+		return nil, apperrors.ErrAccessDenied
 	}
 
 	return output, nil
@@ -4,9 +4,7 @@ import (
 	"encoding/json"
 	"fmt"
 
-	"github.com/labbs/nexo/infrastructure/helpers/apperrors"
 	"github.com/labbs/nexo/application/database/dto"
-	spaceDto "github.com/labbs/nexo/application/space/dto"
 	"github.com/labbs/nexo/domain"
 )
 
@@ -16,14 +14,9 @@ func (app *DatabaseApplication) ListRows(input dto.ListRowsInput) (*dto.ListRows
 		return nil, fmt.Errorf("database not found: %w", err)
 	}
 
-	// Verify user has access to the space
-	spaceResult, err := app.SpaceApplication.GetSpaceById(spaceDto.GetSpaceByIdInput{SpaceId: database.SpaceId})
-	if err != nil {
-		return nil, fmt.Errorf("space not found: %w", err)
-	}
-
-	if spaceResult.Space.GetUserRole(input.UserId) == nil {
-		return nil, apperrors.ErrAccessDenied
+	// Verify user can read the database
+	if err := app.checkDatabaseAccess(database, input.UserId, domain.PermissionRoleViewer); err != nil {
+		return nil, err
 	}
 
 	limit := input.Limit
@@ -49,6 +42,13 @@ func (app *DatabaseApplication) ListRows(input dto.ListRowsInput) (*dto.ListRows
 				// Convert view filter to domain filter
 				if view.Filter != nil {
 					queryOptions.Filter = convertFilterConfigToDomain(view.Filter)
+					skipped, err := resolveFilter(database, queryOptions.Filter, false)
+					if err != nil {
+						return nil, err
+					}
+					for _, rule := range skipped {
+						app.Logger.Warn().Str("database_id", database.Id).Str("view_id", view.Id).Str("property", rule.Property).Str("condition", rule.Condition).Msg("view filter rule no longer applies to its property, skipping it")
+					}
 				}
 				// Convert view sort to domain sort
 				if len(view.Sort) > 0 {
@@ -95,7 +95,18 @@ func (app *DatabaseApplication) ListRows(input dto.ListRowsInput) (*dto.ListRows
 		TotalCount: totalCount,
 	}
 
+	// Rollups first, formulas may use them
+	rowRefs := make([]*domain.DatabaseRow, len(rows))
+	for i := range rows {
+		rowRefs[i] = &rows[i]
+	}
+	if err := app.computeRollups(database, rowRefs, input.UserId); err != nil {
+		return nil, err
+	}
+
+	formulas := newFormulaEvaluator(database)
 	for i, row := range rows {
+		formulas.apply(&row)
 		rowItem := dto.RowItem{
 			Id:            row.Id,
 			Properties:    map[string]any(row.Properties),
@@ -125,47 +136,3 @@ func (app *DatabaseApplication) ListRows(input dto.ListRowsInput) (*dto.ListRows
 
 	return output, nil
 }
-
-// convertFilterConfigToDomain converts the DTO filter config to domain filter config
-func convertFilterConfigToDomain(filter map[string]any) *domain.FilterConfig {
-	if filter == nil {
-		return nil
-	}
-
-	result := &domain.FilterConfig{}
-
-	// Handle "and" filters
-	if andRules, ok := filter["and"].([]any); ok {
-		for _, r := range andRules {
-			if rule, ok := r.(map[string]any); ok {
-				result.And = append(result.And, domain.FilterRule{
-					Property:  getString(rule, "property"),
-					Condition: getString(rule, "condition"),
-					Value:     rule["value"],
-				})
-			}
-		}
-	}
-
-	// Handle "or" filters
-	if orRules, ok := filter["or"].([]any); ok {
-		for _, r := range orRules {
-			if rule, ok := r.(map[string]any); ok {
-				result.Or = append(result.Or, domain.FilterRule{
-					Property:  getString(rule, "property"),
-					Condition: getString(rule, "condition"),
-					Value:     rule["value"],
-				})
-			}
-		}
-	}
-
-	return result
-}
-
-func getString(m map[string]any, key string) string {
-	if v, ok := m[key].(string); ok {
-		return v
-	}
-	return ""
-}
@@ -0,0 +1,254 @@
+package database
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+
+	"github.com/google/uuid"
+	"github.com/labbs/nexo/domain"
+	"github.com/labbs/nexo/infrastructure/helpers/apperrors"
+)
+
+// Relation properties link rows to the rows of a database of the same space:
+//
+//	{"id": "tasks", "type": "relation", "options": {"database_id": "…", "two_way": true}}
+//
+// The links are stored in the database_row_relation table and copied to the
+// row properties as a list of row ids. A two-way relation has a relation
+// property on the other database (options.related_property_id), created when
+// the relation is, and both sides are kept in sync.
+
+// relationProperties returns the relation properties of the database schema
+func relationProperties(database *domain.Database) []domain.RelationProperty {
+	var relations []domain.RelationProperty
+	for _, item := range database.Schema {
+		property, ok := item.(map[string]any)
+		if !ok || property["type"] != string(domain.PropertyTypeRelation) {
+			continue
+		}
+		options, _ := property["options"].(map[string]any)
+		relation := domain.RelationProperty{DatabaseId: database.Id}
+		relation.PropertyId, _ = property["id"].(string)
+		relation.RelatedDatabaseId, _ = options["database_id"].(string)
+		relation.RelatedPropertyId, _ = options["related_property_id"].(string)
+		if relation.RelatedDatabaseId != "" {
+			relations = append(relations, relation)
+		}
+	}
+	return relations
+}
+
+// relationRowIds returns the row ids of a relation value: a list of ids or of
+// {"id": …} objects, or a single id
+func relationRowIds(value any) []string {
+	var ids []string
+	add := func(item any) {
+		if object, ok := item.(map[string]any); ok {
+			item = object["id"]
+		}
+		if id, ok := item.(string); ok && id != "" && !slices.Contains(ids, id) {
+			ids = append(ids, id)
+		}
+	}
+	if items, ok := value.([]any); ok {
+		for _, item := range items {
+			add(item)
+		}
+	} else {
+		add(value)
+	}
+	return ids
+}
+
+// resolveRelations sets the relation properties of a row to the ids of the
+// related rows that exist in the related database, and returns them to be
+// saved with saveRelations once the row is stored. Relations missing from the
+// properties are emptied. previous are the properties of the row before the
+// write, nil for a new row: changing the links to another database requires
+// the viewer role on it, and the editor role for a two-way relation, whose
+// other side is changed too.
+func (app *DatabaseApplication) resolveRelations(database *domain.Database, row *domain.DatabaseRow, previous domain.JSONB, userId string) (map[domain.RelationProperty][]string, error) {
+	relations := relationProperties(database)
+	if len(relations) == 0 {
+		return nil, nil
+	}
+	if row.Properties == nil {
+		row.Properties = domain.JSONB{}
+	}
+
+	resolved := make(map[domain.RelationProperty][]string, len(relations))
+	for _, relation := range relations {
+		ids := relationRowIds(row.Properties[relation.PropertyId])
+		if !slices.Equal(ids, relationRowIds(previous[relation.PropertyId])) {
+			if err := app.checkRelationAccess(database, relation, userId); err != nil {
+				return nil, err
+			}
+		}
+		related, err := app.DatabaseRowPers.GetByIds(ids)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get related rows: %w", err)
+		}
+		existing := ids[:0]
+		for _, id := range ids {
+			if slices.ContainsFunc(related, func(r domain.DatabaseRow) bool {
+				return r.Id == id && r.DatabaseId == relation.RelatedDatabaseId
+			}) {
+				existing = append(existing, id)
+			}
+		}
+
+		values := make([]any, len(existing))
+		for i, id := range existing {
+			values[i] = id
+		}
+		row.Properties[relation.PropertyId] = values
+		resolved[relation] = existing
+	}
+	return resolved, nil
+}
+
+// checkRelationAccess verifies that the user can change the links of a
+// relation to another database
+func (app *DatabaseApplication) checkRelationAccess(database *domain.Database, relation domain.RelationProperty, userId string) error {
+	if relation.RelatedDatabaseId == database.Id {
+		return nil
+	}
+	related, err := app.DatabasePers.GetById(relation.RelatedDatabaseId)
+	if err != nil {
+		if errors.Is(err, apperrors.ErrDatabaseNotFound) {
+			// Nothing can be linked to a deleted database
+			return nil
+		}
+		return fmt.Errorf("failed to get related database: %w", err)
+	}
+	requiredRole := domain.PermissionRoleViewer
+	if relation.RelatedPropertyId != "" {
+		requiredRole = domain.PermissionRoleEditor
+	}
+	return app.checkDatabaseAccess(related, userId, requiredRole)
+}
+
+// saveRelations stores the links resolved by resolveRelations
+func (app *DatabaseApplication) saveRelations(rowId string, relations map[domain.RelationProperty][]string) error {
+	for relation, ids := range relations {
+		if err := app.DatabaseRowPers.SetRelations(relation, rowId, ids); err != nil {
+			return fmt.Errorf("failed to save relation %s: %w", relation.PropertyId, err)
+		}
+	}
+	return nil
+}
+
+// prepareRelations checks the relation and rollup properties of a schema
+// before it is saved, and creates the other side of new two-way relations.
+// previous is the schema being replaced, nil for a new database: creating a
+// relation to another database, or changing the other side of a two-way
+// relation, requires the editor role on that database.
+func (app *DatabaseApplication) prepareRelations(database *domain.Database, previous domain.JSONBArray, userId string) error {
+	for _, item := range database.Schema {
+		property, ok := item.(map[string]any)
+		if !ok {
+			continue
+		}
+		switch property["type"] {
+		case string(domain.PropertyTypeRelation):
+			if err := app.prepareRelation(database, property, previous, userId); err != nil {
+				return err
+			}
+		case string(domain.PropertyTypeRollup):
+			if err := validateRollup(database, property); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (app *DatabaseApplication) prepareRelation(database *domain.Database, property map[string]any, previous domain.JSONBArray, userId string) error {
+	options, _ := property["options"].(map[string]any)
+	if options == nil {
+		options = map[string]any{}
+		property["options"] = options
+	}
+	propertyId, _ := property["id"].(string)
+	relatedDatabaseId, _ := options["database_id"].(string)
+	if relatedDatabaseId == "" {
+		return fmt.Errorf("%w: relation property %q has no database_id", apperrors.ErrInvalidInput, property["name"])
+	}
+
+	// Relations to the database itself are stored in its own schema
+	related := database
+	if relatedDatabaseId != database.Id {
+		var err error
+		related, err = app.DatabasePers.GetById(relatedDatabaseId)
+		if err != nil || related.SpaceId != database.SpaceId {
+			return fmt.Errorf("%w: relation property %q must target a database of the same space", apperrors.ErrInvalidInput, property["name"])
+		}
+		if !hasRelation(previous, propertyId, relatedDatabaseId) {
+			if err := app.checkDatabaseAccess(related, userId, domain.PermissionRoleEditor); err != nil {
+				return err
+			}
+		}
+	}
+
+	relatedPropertyId, _ := options["related_property_id"].(string)
+	twoWay, _ := options["two_way"].(bool)
+	if relatedPropertyId == "" && !twoWay {
+		return nil
+	}
+
+	if relatedPropertyId != "" {
+		// Existing two-way relation: the other side must point back to this property
+		other := schemaProperty(related, relatedPropertyId)
+		if other == nil || relatedPropertyId == propertyId {
+			return fmt.Errorf("%w: related property %q of relation %q does not exist", apperrors.ErrInvalidInput, relatedPropertyId, property["name"])
+		}
+		otherOptions, _ := other["options"].(map[string]any)
+		if other["type"] != string(domain.PropertyTypeRelation) || otherOptions["database_id"] != database.Id {
+			return fmt.Errorf("%w: related property %q of relation %q is not a relation to this database", apperrors.ErrInvalidInput, relatedPropertyId, property["name"])
+		}
+		if otherOptions["related_property_id"] == propertyId {
+			return nil
+		}
+		otherOptions["related_property_id"] = propertyId
+		otherOptions["two_way"] = true
+	} else {
+		// New two-way relation: create the property of the other side
+		relatedPropertyId = uuid.New().String()
+		related.Schema = append(related.Schema, map[string]any{
+			"id":   relatedPropertyId,
+			"name": database.Name,
+			"type": string(domain.PropertyTypeRelation),
+			"options": map[string]any{
+				"database_id":         database.Id,
+				"related_property_id": propertyId,
+				"two_way":             true,
+			},
+		})
+		options["related_property_id"] = relatedPropertyId
+	}
+
+	if related != database {
+		if err := app.checkDatabaseAccess(related, userId, domain.PermissionRoleEditor); err != nil {
+			return err
+		}
+		if err := app.DatabasePers.Update(related); err != nil {
+			return fmt.Errorf("failed to update related database: %w", err)
+		}
+	}
+	return nil
+}
+
+// hasRelation reports whether a schema has the relation property to the
+// related database
+func hasRelation(schema domain.JSONBArray, propertyId, relatedDatabaseId string) bool {
+	for _, item := range schema {
+		property, ok := item.(map[string]any)
+		if !ok || property["id"] != propertyId || property["type"] != string(domain.PropertyTypeRelation) {
+			continue
+		}
+		options, _ := property["options"].(map[string]any)
+		return options["database_id"] == relatedDatabaseId
+	}
+	return false
+}
@@ -0,0 +1,226 @@
+package database
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"slices"
+
+	"github.com/labbs/nexo/domain"
+	"github.com/labbs/nexo/infrastructure/helpers/apperrors"
+)
+
+// Rollup properties aggregate a property of the rows related through a
+// relation property of the same database:
+//
+//	{"id": "total", "type": "rollup", "options": {"relation_property_id": "tasks", "property_id": "estimate", "function": "sum"}}
+//
+// They are computed when rows are read and are not stored.
+
+// rollupFunctions are the aggregations of rollup properties
+var rollupFunctions = []string{"count", "sum", "avg", "min", "max", "percent_checked"}
+
+type rollupProperty struct {
+	id                 string
+	relationPropertyId string
+	propertyId         string
+	function           string
+}
+
+func rollupProperties(database *domain.Database) []rollupProperty {
+	var rollups []rollupProperty
+	for _, item := range database.Schema {
+		property, ok := item.(map[string]any)
+		if !ok || property["type"] != string(domain.PropertyTypeRollup) {
+			continue
+		}
+		options, _ := property["options"].(map[string]any)
+		var rollup rollupProperty
+		rollup.id, _ = property["id"].(string)
+		rollup.relationPropertyId, _ = options["relation_property_id"].(string)
+		rollup.propertyId, _ = options["property_id"].(string)
+		rollup.function, _ = options["function"].(string)
+		if rollup.relationPropertyId != "" && slices.Contains(rollupFunctions, rollup.function) {
+			rollups = append(rollups, rollup)
+		}
+	}
+	return rollups
+}
+
+// validateRollup checks the options of a rollup property
+func validateRollup(database *domain.Database, property map[string]any) error {
+	options, _ := property["options"].(map[string]any)
+	relationPropertyId, _ := options["relation_property_id"].(string)
+	if relation := schemaProperty(database, relationPropertyId); relation == nil || relation["type"] != string(domain.PropertyTypeRelation) {
+		return fmt.Errorf("%w: rollup property %q must reference a relation property of the database", apperrors.ErrInvalidInput, property["name"])
+	}
+	function, _ := options["function"].(string)
+	if !slices.Contains(rollupFunctions, function) {
+		return fmt.Errorf("%w: rollup property %q has an unknown function %q", apperrors.ErrInvalidInput, property["name"], function)
+	}
+	if propertyId, _ := options["property_id"].(string); propertyId == "" && function != "count" {
+		return fmt.Errorf("%w: rollup property %q has no property_id", apperrors.ErrInvalidInput, property["name"])
+	}
+	return nil
+}
+
+// computeRollups sets the rollup properties of the rows. Rollups over a
+// database the user cannot read are left empty.
+func (app *DatabaseApplication) computeRollups(database *domain.Database, rows []*domain.DatabaseRow, userId string) error {
+	rollups := rollupProperties(database)
+	if len(rollups) == 0 || len(rows) == 0 {
+		return nil
+	}
+
+	readable, err := app.readableRelations(database, userId)
+	if err != nil {
+		return err
+	}
+	rollups = slices.DeleteFunc(rollups, func(rollup rollupProperty) bool {
+		if readable[rollup.relationPropertyId] {
+			return false
+		}
+		for _, row := range rows {
+			if row.Properties == nil {
+				row.Properties = domain.JSONB{}
+			}
+			row.Properties[rollup.id] = nil
+		}
+		return true
+	})
+	if len(rollups) == 0 {
+		return nil
+	}
+
+	rowIds := make([]string, len(rows))
+	for i, row := range rows {
+		rowIds[i] = row.Id
+		if row.Properties == nil {
+			row.Properties = domain.JSONB{}
+		}
+	}
+
+	// Related rows, by relation property
+	relations := map[string]map[string][]string{}
+	relatedRows := map[string]domain.DatabaseRow{}
+	for _, rollup := range rollups {
+		if _, ok := relations[rollup.relationPropertyId]; ok {
+			continue
+		}
+		related, err := app.DatabaseRowPers.GetRelations(rollup.relationPropertyId, rowIds)
+		if err != nil {
+			return fmt.Errorf("failed to get relations: %w", err)
+		}
+		relations[rollup.relationPropertyId] = related
+
+		var missing []string
+		for _, ids := range related {
+			for _, id := range ids {
+				if _, ok := relatedRows[id]; !ok && !slices.Contains(missing, id) {
+					missing = append(missing, id)
+				}
+			}
+		}
+		found, err := app.DatabaseRowPers.GetByIds(missing)
+		if err != nil {
+			return fmt.Errorf("failed to get related rows: %w", err)
+		}
+		for _, row := range found {
+			relatedRows[row.Id] = row
+		}
+	}
+
+	for _, row := range rows {
+		for _, rollup := range rollups {
+			var values []any
+			for _, id := range relations[rollup.relationPropertyId][row.Id] {
+				if related, ok := relatedRows[id]; ok {
+					values = append(values, related.Properties[rollup.propertyId])
+				}
+			}
+			row.Properties[rollup.id] = rollupValue(rollup.function, values)
+		}
+	}
+	return nil
+}
+
+// readableRelations returns the relation properties of the database whose
+// related database the user can read, by property id.
+func (app *DatabaseApplication) readableRelations(database *domain.Database, userId string) (map[string]bool, error) {
+	readable := map[string]bool{}
+	access := map[string]bool{database.Id: true}
+	for _, relation := range relationProperties(database) {
+		allowed, ok := access[relation.RelatedDatabaseId]
+		if !ok {
+			related, err := app.DatabasePers.GetById(relation.RelatedDatabaseId)
+			if err == nil {
+				err = app.checkDatabaseAccess(related, userId, domain.PermissionRoleViewer)
+			}
+			switch {
+			case err == nil:
+				allowed = true
+			case errors.Is(err, apperrors.ErrAccessDenied), errors.Is(err, apperrors.ErrDatabaseNotFound):
+				allowed = false
+			default:
+				return nil, err
+			}
+			access[relation.RelatedDatabaseId] = allowed
+		}
+		readable[relation.PropertyId] = allowed
+	}
+	return readable, nil
+}
+
+// rollupValue aggregates the values of the related rows. Values that are not
+// numbers are ignored by sum, avg, min and max; avg, min, max and
+// percent_checked are null without related rows.
+func rollupValue(function string, values []any) any {
+	if function == "count" {
+		return float64(len(values))
+	}
+	if function == "percent_checked" {
+		if len(values) == 0 {
+			return nil
+		}
+		checked := 0
+		for _, value := range values {
+			if value == true {
+				checked++
+			}
+		}
+		return math.Round(float64(checked)*10000/float64(len(values))) / 100
+	}
+
+	var numbers []float64
+	for _, value := range values {
+		if value == nil || value == "" {
+			continue
+		}
+		if number, err := formulaNumber(value); err == nil {
+			numbers = append(numbers, number)
+		}
+	}
+	if function == "sum" {
+		sum := 0.0
+		for _, number := range numbers {
+			sum += number
+		}
+		return sum
+	}
+	if len(numbers) == 0 {
+		return nil
+	}
+	switch function {
+	case "avg":
+		sum := 0.0
+		for _, number := range numbers {
+			sum += number
+		}
+		return sum / float64(len(numbers))
+	case "min":
+		return slices.Min(numbers)
+	case "max":
+		return slices.Max(numbers)
+	}
+	return nil
+}
@@ -0,0 +1,25 @@
+package database
+
+import (
+	searchDto "github.com/labbs/nexo/application/search/dto"
+	"github.com/labbs/nexo/domain"
+)
+
+// indexRow updates the search index entry of a row. Rows of a deleted
+// database keep their entries: search leaves them out.
+func (app *DatabaseApplication) indexRow(database *domain.Database, row *domain.DatabaseRow) {
+	if app.SearchApplication == nil {
+		return
+	}
+	app.SearchApplication.IndexRow(searchDto.IndexRowInput{Database: database, Row: row})
+}
+
+func (app *DatabaseApplication) removeRowsFromIndex(rowIds []string) {
+	if app.SearchApplication == nil {
+		return
+	}
+	app.SearchApplication.RemoveFromIndex(searchDto.RemoveFromIndexInput{
+		ResourceType: domain.SearchResourceRow,
+		ResourceIds:  rowIds,
+	})
+}
@@ -5,9 +5,7 @@ import (
 	"fmt"
 	"time"
 
-	"github.com/labbs/nexo/infrastructure/helpers/apperrors"
 	"github.com/labbs/nexo/application/database/dto"
-	spaceDto "github.com/labbs/nexo/application/space/dto"
 	"github.com/labbs/nexo/domain"
 )
 
@@ -17,14 +15,9 @@ func (app *DatabaseApplication) UpdateDatabase(input dto.UpdateDatabaseInput) er
 		return fmt.Errorf("database not found: %w", err)
 	}
 
-	// Verify user has access to the space
-	spaceResult, err := app.SpaceApplication.GetSpaceById(spaceDto.GetSpaceByIdInput{SpaceId: database.SpaceId})
-	if err != nil {
-		return fmt.Errorf("space not found: %w", err)
-	}
-
-	if spaceResult.Space.GetUserRole(input.UserId) == nil {
-		return apperrors.ErrAccessDenied
+	// Verify user can edit the database
+	if err := app.checkDatabaseAccess(database, input.UserId, domain.PermissionRoleEditor); err != nil {
+		return err
 	}
 
 	if input.Name != nil {
@@ -46,7 +39,16 @@ func (app *DatabaseApplication) UpdateDatabase(input dto.UpdateDatabaseInput) er
 		}
 		var schema domain.JSONBArray
 		json.Unmarshal(schemaJSON, &schema)
+		previous := database.Schema
 		database.Schema = schema
+
+		if err := validateFormulas(database); err != nil {
+			return err
+		}
+
+		if err := app.prepareRelations(database, previous, input.UserId); err != nil {
+			return err
+		}
 	}
 
 	if input.DefaultView != nil {
@@ -59,5 +61,12 @@ func (app *DatabaseApplication) UpdateDatabase(input dto.UpdateDatabaseInput) er
 		return fmt.Errorf("failed to update database: %w", err)
 	}
 
+	// Formulas may have changed: store the new values of every row
+	if input.Schema != nil {
+		if err := app.refreshFormulas(database); err != nil {
+			return fmt.Errorf("failed to refresh formulas: %w", err)
+		}
+	}
+
 	return nil
 }
@@ -6,37 +6,69 @@ import (
 
 	"github.com/labbs/nexo/infrastructure/helpers/apperrors"
 	"github.com/labbs/nexo/application/database/dto"
-	spaceDto "github.com/labbs/nexo/application/space/dto"
 	"github.com/labbs/nexo/domain"
 )
 
 func (app *DatabaseApplication) UpdateRow(input dto.UpdateRowInput) error {
+	_, err := app.updateRow(input, false)
+	return err
+}
+
+// PatchRow merges properties into the stored properties of a row, where
+// UpdateRow replaces them
+func (app *DatabaseApplication) PatchRow(input dto.PatchRowInput) (*dto.PatchRowOutput, error) {
+	row, err := app.updateRow(dto.UpdateRowInput{
+		UserId:      input.UserId,
+		DatabaseId:  input.DatabaseId,
+		RowId:       input.RowId,
+		Properties:  input.Properties,
+		Content:     input.Content,
+		ActionChain: input.ActionChain,
+	}, true)
+	if err != nil {
+		return nil, err
+	}
+	return &dto.PatchRowOutput{Properties: map[string]any(row.Properties)}, nil
+}
+
+// updateRow writes the input to the row, merging its properties into the
+// stored ones when merge is set
+func (app *DatabaseApplication) updateRow(input dto.UpdateRowInput, merge bool) (*domain.DatabaseRow, error) {
 	row, err := app.DatabaseRowPers.GetById(input.RowId)
 	if err != nil {
-		return fmt.Errorf("row not found: %w", err)
+		return nil, fmt.Errorf("row not found: %w", err)
 	}
 
 	if row.DatabaseId != input.DatabaseId {
-		return apperrors.ErrRowNotFound
+		return nil, apperrors.ErrRowNotFound
 	}
 
 	database, err := app.DatabasePers.GetById(input.DatabaseId)
 	if err != nil {
-		return fmt.Errorf("database not found: %w", err)
+		return nil, fmt.Errorf("database not found: %w", err)
 	}
 
-	// Verify user has access to the space
-	spaceResult, err := app.SpaceApplication.GetSpaceById(spaceDto.GetSpaceByIdInput{SpaceId: database.SpaceId})
-	if err != nil {
-		return fmt.Errorf("space not found: %w", err)
+	// Verify user can edit the database
+	if err := app.checkDatabaseAccess(database, input.UserId, domain.PermissionRoleEditor); err != nil {
+		return nil, err
 	}
 
-	if spaceResult.Space.GetUserRole(input.UserId) == nil {
-		return apperrors.ErrAccessDenied
-	}
+	before := *row
 
 	if input.Properties != nil {
-		row.Properties = domain.JSONB(input.Properties)
+		properties := input.Properties
+		if merge {
+			properties = make(map[string]any, len(row.Properties)+len(input.Properties))
+			for key, value := range row.Properties {
+				properties[key] = value
+			}
+			for key, value := range input.Properties {
+				properties[key] = value
+			}
+		}
+		// Formulas and rollups are computed, the values sent for them are
+		// dropped
+		row.Properties = domain.JSONB(withoutComputedProperties(database, properties))
 	}
 
 	if input.Content != nil {
@@ -49,10 +81,39 @@ func (app *DatabaseApplication) UpdateRow(input dto.UpdateRowInput) error {
 
 	row.UpdatedBy = input.UserId
 	row.UpdatedAt = time.Now()
+	relations, err := app.resolveRelations(database, row, before.Properties, input.UserId)
+	if err != nil {
+		return nil, err
+	}
+	newFormulaEvaluator(database).store(row)
 
 	if err := app.DatabaseRowPers.Update(row); err != nil {
-		return fmt.Errorf("failed to update row: %w", err)
+		return nil, fmt.Errorf("failed to update row: %w", err)
+	}
+
+	if err := app.saveRelations(row.Id, relations); err != nil {
+		return nil, err
 	}
 
-	return nil
+	app.indexRow(database, row)
+	app.publishRowEvent(domain.WebhookEventRowUpdated, input.UserId, input.ActionChain, database, &before, row)
+	app.publishPropertyChangedEvents(input.UserId, input.ActionChain, database, &before, row)
+
+	return row, nil
+}
+
+// withoutComputedProperties returns the properties without the formula and
+// rollup properties of the database schema
+func withoutComputedProperties(database *domain.Database, properties map[string]any) map[string]any {
+	stripped := make(map[string]any, len(properties))
+	for key, value := range properties {
+		if property := schemaProperty(database, key); property != nil {
+			switch property["type"] {
+			case string(domain.PropertyTypeFormula), string(domain.PropertyTypeRollup):
+				continue
+			}
+		}
+		stripped[key] = value
+	}
+	return stripped
 }
@@ -0,0 +1,68 @@
+package document
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/labbs/nexo/application/document/dto"
+	eventDto "github.com/labbs/nexo/application/event/dto"
+	"github.com/labbs/nexo/domain"
+	"github.com/labbs/nexo/infrastructure/helpers/apperrors"
+)
+
+// SaveCollaborationSnapshot saves the content of a document edited in a
+// collaboration room, with a version of the new content.
+func (a *DocumentApplication) SaveCollaborationSnapshot(input dto.SaveCollaborationSnapshotInput) (*dto.SaveCollaborationSnapshotOutput, error) {
+	logger := a.Logger.With().Str("component", "application.document.save_collaboration_snapshot").Logger()
+
+	document, err := a.DocumentPers.GetDocumentWithPermissions(input.DocumentId, input.UserId)
+	if err != nil {
+		logger.Error().Err(err).Str("document_id", input.DocumentId).Msg("failed to get document for collaboration snapshot")
+		return nil, fmt.Errorf("failed to get document for collaboration snapshot: %w", err)
+	}
+
+	if !document.HasPermission(input.UserId, domain.PermissionRoleEditor) {
+		return nil, apperrors.ErrAccessDenied
+	}
+
+	content := dto.BlocksToJSON(input.Content)
+	if bytes.Equal(content, dto.BlocksToJSON(dto.JSONToBlocks(document.Content))) {
+		return &dto.SaveCollaborationSnapshotOutput{Saved: false}, nil
+	}
+
+	before := eventDto.DocumentSnapshot(document)
+	document.Content = content
+
+	err = a.DocumentPers.Update(document, input.UserId)
+	if err != nil {
+		logger.Error().Err(err).Str("document_id", input.DocumentId).Msg("failed to save collaboration snapshot")
+		return nil, fmt.Errorf("failed to save collaboration snapshot: %w", err)
+	}
+
+	if _, err := a.createVersionFromDocument(document, input.UserId, "Collaboration snapshot"); err != nil {
+		logger.Error().Err(err).Str("document_id", input.DocumentId).Msg("failed to create version of collaboration snapshot")
+	}
+
+	a.indexDocument(document)
+	a.publishDocumentEvent(domain.WebhookEventDocumentUpdated, input.UserId, nil, before, eventDto.DocumentSnapshot(document))
+
+	return &dto.SaveCollaborationSnapshotOutput{Saved: true}, nil
+}
+
+// resetCollaboration drops the collaborative state of a document whose
+// content was replaced outside of its room (update, restore, import):
+// otherwise the next snapshot of the room would bring the old content back.
+func (a *DocumentApplication) resetCollaboration(documentId string) {
+	if a.CollaborationRooms != nil {
+		a.CollaborationRooms.ResetRoom("document:" + documentId)
+	}
+}
+
+// recheckCollaborationAccess applies a change of the access to a document
+// (lock, inheritance, parent) to the clients connected to the collaboration
+// rooms of the documents.
+func (a *DocumentApplication) recheckCollaborationAccess(documentId string) {
+	if a.CollaborationRooms != nil {
+		a.CollaborationRooms.RecheckAccess(domain.PermissionTypeDocument, documentId)
+	}
+}
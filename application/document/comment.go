@@ -12,10 +12,14 @@ import (
 
 func (app *DocumentApplication) CreateComment(input dto.CreateCommentInput) (*dto.CreateCommentOutput, error) {
 	// Verify user has access to the document
-	_, err := app.DocumentPers.GetDocumentByIdOrSlugWithUserPermissions("", &input.DocumentId, nil, input.UserId)
+	doc, err := app.DocumentPers.GetDocumentWithPermissions(input.DocumentId, input.UserId)
 	if err != nil {
 		return nil, fmt.Errorf("document not found or access denied: %w", err)
 	}
+	// Commenting requires at least the commenter role, viewers can only read
+	if !doc.HasPermission(input.UserId, domain.PermissionRoleCommenter) {
+		return nil, apperrors.ErrAccessDenied
+	}
 
 	comment := &domain.Comment{
 		Id:         uuid.New().String(),
@@ -33,6 +37,9 @@ func (app *DocumentApplication) CreateComment(input dto.CreateCommentInput) (*dt
 		return nil, fmt.Errorf("failed to create comment: %w", err)
 	}
 
+	app.indexComment(doc, comment)
+	app.publishCommentEvent(domain.WebhookEventCommentCreated, input.UserId, input.ActionChain, doc.SpaceId, nil, comment)
+
 	return &dto.CreateCommentOutput{
 		CommentId: comment.Id,
 	}, nil
@@ -40,10 +47,13 @@ func (app *DocumentApplication) CreateComment(input dto.CreateCommentInput) (*dt
 
 func (app *DocumentApplication) GetComments(input dto.GetCommentsInput) (*dto.GetCommentsOutput, error) {
 	// Verify user has access to the document
-	_, err := app.DocumentPers.GetDocumentByIdOrSlugWithUserPermissions("", &input.DocumentId, nil, input.UserId)
+	doc, err := app.DocumentPers.GetDocumentWithPermissions(input.DocumentId, input.UserId)
 	if err != nil {
 		return nil, fmt.Errorf("document not found or access denied: %w", err)
 	}
+	if !doc.HasPermission(input.UserId, domain.PermissionRoleViewer) {
+		return nil, apperrors.ErrAccessDenied
+	}
 
 	comments, err := app.CommentPers.GetByDocumentId(input.DocumentId)
 	if err != nil {
@@ -82,6 +92,7 @@ func (app *DocumentApplication) UpdateComment(input dto.UpdateCommentInput) erro
 		return apperrors.ErrAccessDenied
 	}
 
+	before := *comment
 	comment.Content = input.Content
 	comment.UpdatedAt = time.Now()
 
@@ -89,6 +100,9 @@ func (app *DocumentApplication) UpdateComment(input dto.UpdateCommentInput) erro
 		return fmt.Errorf("failed to update comment: %w", err)
 	}
 
+	app.indexComment(&comment.Document, comment)
+	app.publishCommentEvent(domain.WebhookEventCommentUpdated, input.UserId, nil, comment.Document.SpaceId, &before, comment)
+
 	return nil
 }
 
@@ -107,6 +121,9 @@ func (app *DocumentApplication) DeleteComment(input dto.DeleteCommentInput) erro
 		return fmt.Errorf("failed to delete comment: %w", err)
 	}
 
+	app.removeCommentFromIndex(comment.Id)
+	app.publishCommentEvent(domain.WebhookEventCommentDeleted, input.UserId, nil, comment.Document.SpaceId, comment, nil)
+
 	return nil
 }
 
@@ -116,15 +133,25 @@ func (app *DocumentApplication) ResolveComment(input dto.ResolveCommentInput) er
 		return fmt.Errorf("comment not found: %w", err)
 	}
 
-	// Verify user has access to the document (any user with document access can resolve)
-	_, err = app.DocumentPers.GetDocumentByIdOrSlugWithUserPermissions("", &comment.DocumentId, nil, input.UserId)
+	// Verify user can comment on the document (any commenter can resolve)
+	doc, err := app.DocumentPers.GetDocumentWithPermissions(comment.DocumentId, input.UserId)
 	if err != nil {
 		return fmt.Errorf("access denied: %w", err)
 	}
+	if !doc.HasPermission(input.UserId, domain.PermissionRoleCommenter) {
+		return apperrors.ErrAccessDenied
+	}
 
 	if err := app.CommentPers.Resolve(input.CommentId, input.Resolved); err != nil {
 		return fmt.Errorf("failed to resolve comment: %w", err)
 	}
 
+	// Only resolving (not reopening) maps to comment.resolved
+	if input.Resolved && !comment.Resolved {
+		before := *comment
+		comment.Resolved = true
+		app.publishCommentEvent(domain.WebhookEventCommentResolved, input.UserId, nil, doc.SpaceId, &before, comment)
+	}
+
 	return nil
 }
@@ -7,6 +7,7 @@ import (
 	"github.com/gosimple/slug"
 	"github.com/labbs/nexo/infrastructure/helpers/apperrors"
 	"github.com/labbs/nexo/application/document/dto"
+	eventDto "github.com/labbs/nexo/application/event/dto"
 	permissionDto "github.com/labbs/nexo/application/permission/dto"
 	spaceDto "github.com/labbs/nexo/application/space/dto"
 	"github.com/labbs/nexo/domain"
@@ -81,5 +82,8 @@ func (a *DocumentApplication) CreateDocument(input dto.CreateDocumentInput) (*dt
 		UpdatedAt: spaceDetail.UpdatedAt,
 	}
 
+	a.indexDocument(document)
+	a.publishDocumentEvent(domain.WebhookEventDocumentCreated, input.UserId, input.ActionChain, nil, eventDto.DocumentSnapshot(document))
+
 	return &dto.CreateDocumentOutput{Document: document}, nil
 }
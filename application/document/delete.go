@@ -4,6 +4,7 @@ import (
 	"fmt"
 
 	"github.com/labbs/nexo/application/document/dto"
+	eventDto "github.com/labbs/nexo/application/event/dto"
 	"github.com/labbs/nexo/domain"
 )
 
@@ -30,5 +31,7 @@ func (a *DocumentApplication) DeleteDocument(input dto.DeleteDocumentInput) erro
 		return err
 	}
 
+	a.publishDocumentEvent(domain.WebhookEventDocumentDeleted, input.UserId, nil, eventDto.DocumentSnapshot(document), nil)
+
 	return nil
 }
@@ -15,6 +15,11 @@ type DocumentApplication struct {
 	DocumentVersionPers   domain.DocumentVersionPers
 	SpaceApplication      ports.SpacePort
 	PermissionApplication ports.PermissionPort
+	EventApplication      ports.EventPort
+	SearchApplication     ports.SearchPort
+	CollaborationPresence ports.PresencePort
+	CollaborationRooms    ports.CollaborationPort
+	AttachmentApplication ports.AttachmentPort
 }
 
 func NewDocumentApplication(config config.Config, logger zerolog.Logger, documentPers domain.DocumentPers, commentPers domain.CommentPers, documentVersionPers domain.DocumentVersionPers) *DocumentApplication {
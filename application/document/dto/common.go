@@ -1,6 +1,7 @@
 package dto
 
 import (
+	"bytes"
 	"encoding/json"
 	"time"
 
@@ -29,9 +30,12 @@ type Document struct {
 	SpaceId  string
 	Space    DocumentSpace
 	Public   bool
-	Content  []Block
-	Config   DocumentConfig
-	Metadata map[string]any
+	// BreakInheritance is set when the document does not inherit the
+	// permissions of its parent documents
+	BreakInheritance bool
+	Content          []Block
+	Config           DocumentConfig
+	Metadata         map[string]any
 
 	CreatedAt time.Time
 	UpdatedAt time.Time
@@ -52,14 +56,147 @@ type Block struct {
 	Props    map[string]any  `json:"props"`
 	Content  []InlineContent `json:"content"`
 	Children []Block         `json:"children"`
+
+	// TableContent replaces Content for table blocks, BlockNote stores their
+	// rows as a "tableContent" object in the content field.
+	TableContent *TableContent `json:"-"`
+}
+
+// TableContent represents the rows of a table block
+type TableContent struct {
+	Type         string     `json:"type"` // "tableContent"
+	ColumnWidths []any      `json:"columnWidths,omitempty"`
+	HeaderRows   int        `json:"headerRows,omitempty"`
+	Rows         []TableRow `json:"rows"`
+}
+
+// TableRow represents a row of a table block
+type TableRow struct {
+	Cells []TableCell `json:"cells"`
+}
+
+// TableCell represents a cell of a table block
+type TableCell struct {
+	Type    string          `json:"type"` // "tableCell"
+	Props   map[string]any  `json:"props,omitempty"`
+	Content []InlineContent `json:"content"`
+}
+
+func (b Block) MarshalJSON() ([]byte, error) {
+	type block Block
+	if b.TableContent == nil {
+		return json.Marshal(block(b))
+	}
+	return json.Marshal(struct {
+		block
+		Content *TableContent `json:"content"`
+	}{block(b), b.TableContent})
+}
+
+func (b *Block) UnmarshalJSON(data []byte) error {
+	type block Block
+	var raw struct {
+		block
+		Content json.RawMessage `json:"content"`
+	}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	*b = Block(raw.block)
+
+	content := bytes.TrimSpace(raw.Content)
+	switch {
+	case len(content) == 0 || bytes.Equal(content, []byte("null")):
+	case content[0] == '{':
+		b.TableContent = &TableContent{}
+		return json.Unmarshal(content, b.TableContent)
+	default:
+		return json.Unmarshal(content, &b.Content)
+	}
+	return nil
+}
+
+// UnmarshalJSON also accepts the cells of older BlockNote versions, stored
+// as an array of inline content.
+func (c *TableCell) UnmarshalJSON(data []byte) error {
+	type cell TableCell
+	if data = bytes.TrimSpace(data); len(data) > 0 && data[0] == '[' {
+		*c = TableCell{Type: "tableCell"}
+		return json.Unmarshal(data, &c.Content)
+	}
+	return json.Unmarshal(data, (*cell)(c))
 }
 
 // InlineContent represents inline content (text, links, etc.)
 type InlineContent struct {
-	Type   string          `json:"type"` // "text", "link"
+	Type   string          `json:"type"` // "text", "link" or a custom inline node (e.g. "mention")
 	Text   string          `json:"text,omitempty"`
 	Href   string          `json:"href,omitempty"`
 	Styles map[string]bool `json:"styles"`
+	// StyleValues holds the styles with a value (textColor, backgroundColor),
+	// stored along with Styles in the "styles" object
+	StyleValues map[string]string `json:"-"`
+	// Props holds the attributes of custom inline nodes (e.g. mentions)
+	Props map[string]any `json:"props,omitempty"`
+}
+
+// AllStyles returns the styles and the styles with a value in one map, as
+// BlockNote stores them.
+func (c InlineContent) AllStyles() map[string]any {
+	if c.Styles == nil && c.StyleValues == nil {
+		return nil
+	}
+	styles := make(map[string]any, len(c.Styles)+len(c.StyleValues))
+	for key, value := range c.Styles {
+		styles[key] = value
+	}
+	for key, value := range c.StyleValues {
+		styles[key] = value
+	}
+	return styles
+}
+
+// SetStyles splits BlockNote styles into Styles and StyleValues
+func (c *InlineContent) SetStyles(styles map[string]any) {
+	c.Styles = map[string]bool{}
+	c.StyleValues = nil
+	for key, value := range styles {
+		switch v := value.(type) {
+		case bool:
+			if v {
+				c.Styles[key] = true
+			}
+		case string:
+			if c.StyleValues == nil {
+				c.StyleValues = map[string]string{}
+			}
+			c.StyleValues[key] = v
+		}
+	}
+}
+
+func (c InlineContent) MarshalJSON() ([]byte, error) {
+	type inline InlineContent
+	return json.Marshal(struct {
+		inline
+		Styles map[string]any `json:"styles"`
+	}{inline(c), c.AllStyles()})
+}
+
+func (c *InlineContent) UnmarshalJSON(data []byte) error {
+	type inline InlineContent
+	var raw struct {
+		inline
+		Styles map[string]any `json:"styles"`
+	}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	*c = InlineContent(raw.inline)
+	if raw.Styles != nil {
+		c.SetStyles(raw.Styles)
+	}
+	return nil
 }
 
 // BlockNote block types
@@ -75,6 +212,7 @@ const (
 	BlockTypeAudio            = "audio"
 	BlockTypeFile             = "file"
 	BlockTypeCodeBlock        = "codeBlock"
+	BlockTypeQuote            = "quote"
 	BlockTypeColumn           = "column"
 	BlockTypeColumnList       = "columnList"
 )
@@ -125,5 +263,7 @@ type SearchResultItem struct {
 	SpaceId   string
 	SpaceName string
 	Icon      string
+	Snippet   string  // HTML with the matched terms in <mark> elements
+	BlockId   *string // best matching block
 	UpdatedAt time.Time
 }
@@ -8,6 +8,9 @@ type CreateDocumentInput struct {
 	SpaceId  string
 	Content  []Block
 	ParentId *string
+	// ActionChain lists the actions whose steps make the change, oldest
+	// first, see ActionApplication.ExecuteActions
+	ActionChain []string
 }
 
 type CreateDocumentOutput struct {
@@ -6,6 +6,9 @@ type CreateCommentInput struct {
 	ParentId   *string
 	Content    string
 	BlockId    *string
+	// ActionChain lists the actions whose steps make the change, oldest
+	// first, see ActionApplication.ExecuteActions
+	ActionChain []string
 }
 
 type CreateCommentOutput struct {
@@ -0,0 +1,13 @@
+package dto
+
+import "github.com/labbs/nexo/domain"
+
+type GetEffectivePermissionInput struct {
+	DocumentId string
+	UserId     string
+}
+
+type GetEffectivePermissionOutput struct {
+	SpaceId    string
+	Permission domain.EffectivePermission
+}
@@ -0,0 +1,25 @@
+package dto
+
+// Formats of the document exports
+const (
+	ExportFormatMarkdown = "markdown"
+	ExportFormatHTML     = "html"
+	ExportFormatPDF      = "pdf"
+	ExportFormatZip      = "zip"
+)
+
+type ExportDocumentInput struct {
+	UserId     string
+	SpaceId    string
+	DocumentId string
+	Format     string
+	// Recursive includes the documents under the document, the zip format
+	// is always recursive
+	Recursive bool
+}
+
+type ExportDocumentOutput struct {
+	Filename    string
+	ContentType string
+	Content     []byte
+}
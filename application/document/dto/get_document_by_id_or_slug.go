@@ -2,8 +2,9 @@ package dto
 
 // DocumentPermission represents a permission entry for a document
 type DocumentPermission struct {
-	UserId *string
-	Role   string
+	UserId  *string
+	GroupId *string
+	Role    string
 }
 
 // DocumentSpaceInfo contains the space info embedded in a document
@@ -21,48 +22,35 @@ type DocumentDetail struct {
 	Config      DocumentConfig
 	Space       DocumentSpaceInfo
 	Permissions []DocumentPermission
+
+	// UserId is the user the document was loaded for, UserRole and
+	// ManagePermissions their access resolved from the permissions of the
+	// document, of their groups and of the space
+	UserId            string
+	UserRole          *string
+	ManagePermissions bool
 }
 
 // HasPermission checks if a user has at least the required role on this document
 func (d *DocumentDetail) HasPermission(userId string, requiredRole string) bool {
-	// 1. Check document-level permissions first
-	for _, perm := range d.Permissions {
-		if perm.UserId != nil && *perm.UserId == userId {
-			if perm.Role == "denied" {
-				return false
-			}
-			return docRoleHasPermission(perm.Role, requiredRole)
-		}
-	}
-
-	// 2. Inherit from space - simplified check
-	// For public spaces, viewers can see
-	if d.Space.Type == "public" && requiredRole == "viewer" {
-		return true
+	if userId != d.UserId || d.UserRole == nil || *d.UserRole == "denied" {
+		return false
 	}
-
-	return false
+	return docRoleHasPermission(*d.UserRole, requiredRole)
 }
 
-// CanManagePermissions returns true if the user can manage document permissions
+// CanManagePermissions returns true if the user can manage document permissions:
+// owners of the document and admins of the space
 func (d *DocumentDetail) CanManagePermissions(userId string) bool {
-	// Check if user is owner of this document
-	for _, perm := range d.Permissions {
-		if perm.UserId != nil && *perm.UserId == userId && perm.Role == "owner" {
-			return true
-		}
-	}
-
-	// Space admin/owner check would need the space permissions loaded
-	// The space-level check is done via the space data embedded in the document
-	return false
+	return userId == d.UserId && d.ManagePermissions
 }
 
 func docRoleHasPermission(userRole, requiredRole string) bool {
 	roleHierarchy := map[string]int{
-		"viewer": 1,
-		"editor": 2,
-		"owner":  3,
+		"viewer":    1,
+		"commenter": 2,
+		"editor":    3,
+		"owner":     4,
 	}
 	return roleHierarchy[userRole] >= roleHierarchy[requiredRole]
 }
@@ -0,0 +1,14 @@
+package dto
+
+import "github.com/labbs/nexo/domain"
+
+type GetPresenceInput struct {
+	UserId     string
+	SpaceId    string
+	DocumentId *string
+	Slug       *string
+}
+
+type GetPresenceOutput struct {
+	Users []domain.Presence
+}
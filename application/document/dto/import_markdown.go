@@ -0,0 +1,18 @@
+package dto
+
+import "github.com/labbs/nexo/domain"
+
+type ImportMarkdownInput struct {
+	UserId   string
+	SpaceId  string
+	ParentId *string
+	// DocumentId replaces the content of an existing document instead of
+	// creating a new one
+	DocumentId *string
+	Name       string
+	Markdown   string
+}
+
+type ImportMarkdownOutput struct {
+	Document *domain.Document
+}
@@ -7,6 +7,9 @@ type MoveDocumentInput struct {
 	SpaceId     string
 	DocumentId  string
 	NewParentId *string
+	// ActionChain lists the actions whose steps make the change, oldest
+	// first, see ActionApplication.ExecuteActions
+	ActionChain []string
 }
 
 type MoveDocumentOutput struct {
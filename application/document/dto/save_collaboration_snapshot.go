@@ -0,0 +1,13 @@
+package dto
+
+type SaveCollaborationSnapshotInput struct {
+	// User who sent the last update of the collaboration room
+	UserId     string
+	DocumentId string
+	Content    []Block
+}
+
+type SaveCollaborationSnapshotOutput struct {
+	// Saved is false when the content did not change
+	Saved bool
+}
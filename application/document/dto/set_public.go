@@ -6,3 +6,10 @@ type SetPublicInput struct {
 	DocumentId string
 	Public     bool
 }
+
+type SetBreakInheritanceInput struct {
+	UserId           string
+	SpaceId          string
+	DocumentId       string
+	BreakInheritance bool
+}
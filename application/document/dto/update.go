@@ -11,6 +11,9 @@ type UpdateDocumentInput struct {
 	ParentId   *string
 	Config     *domain.DocumentConfig
 	Metadata   *domain.JSONB
+	// ActionChain lists the actions whose steps make the change, oldest
+	// first, see ActionApplication.ExecuteActions
+	ActionChain []string
 }
 
 type UpdateDocumentOutput struct {
@@ -0,0 +1,24 @@
+package document
+
+import (
+	"fmt"
+
+	"github.com/labbs/nexo/application/document/dto"
+)
+
+// GetEffectivePermission returns the role of a user on a document with the
+// grants it results from. It does not check the access of the user.
+func (a *DocumentApplication) GetEffectivePermission(input dto.GetEffectivePermissionInput) (*dto.GetEffectivePermissionOutput, error) {
+	logger := a.Logger.With().Str("component", "application.document.get_effective_permission").Logger()
+
+	doc, err := a.DocumentPers.GetDocumentWithPermissions(input.DocumentId, input.UserId)
+	if err != nil {
+		logger.Error().Err(err).Str("document_id", input.DocumentId).Msg("failed to get document")
+		return nil, fmt.Errorf("document not found: %w", err)
+	}
+
+	return &dto.GetEffectivePermissionOutput{
+		SpaceId:    doc.SpaceId,
+		Permission: doc.EffectivePermission(input.UserId),
+	}, nil
+}
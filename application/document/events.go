@@ -0,0 +1,59 @@
+package document
+
+import (
+	eventDto "github.com/labbs/nexo/application/event/dto"
+	"github.com/labbs/nexo/domain"
+)
+
+// publishDocumentEvent publishes a document event. before or after may be nil
+// (respectively on creation and deletion). chain is the ActionChain of the
+// input making the change.
+func (a *DocumentApplication) publishDocumentEvent(event domain.WebhookEvent, userId string, chain []string, before, after map[string]any) {
+	if a.EventApplication == nil {
+		return
+	}
+
+	current := after
+	if current == nil {
+		current = before
+	}
+	resourceId, _ := current["id"].(string)
+	spaceId, _ := current["space_id"].(string)
+
+	a.EventApplication.Publish(eventDto.PublishEventInput{
+		Event:        event,
+		ActorId:      userId,
+		SpaceId:      &spaceId,
+		ResourceType: eventDto.ResourceTypeDocument,
+		ResourceId:   resourceId,
+		Before:       before,
+		After:        after,
+		ActionChain:  chain,
+	})
+}
+
+// publishCommentEvent publishes a comment event scoped to the space of the
+// commented document.
+func (a *DocumentApplication) publishCommentEvent(event domain.WebhookEvent, userId string, chain []string, spaceId string, before, after *domain.Comment) {
+	if a.EventApplication == nil {
+		return
+	}
+
+	resourceId := ""
+	if after != nil {
+		resourceId = after.Id
+	} else if before != nil {
+		resourceId = before.Id
+	}
+
+	a.EventApplication.Publish(eventDto.PublishEventInput{
+		Event:        event,
+		ActorId:      userId,
+		SpaceId:      &spaceId,
+		ResourceType: eventDto.ResourceTypeComment,
+		ResourceId:   resourceId,
+		Before:       eventDto.CommentSnapshot(before),
+		After:        eventDto.CommentSnapshot(after),
+		ActionChain:  chain,
+	})
+}
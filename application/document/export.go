@@ -0,0 +1,320 @@
+package document
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"mime"
+	"regexp"
+	"strings"
+
+	attachmentDto "github.com/labbs/nexo/application/attachment/dto"
+	"github.com/labbs/nexo/application/document/dto"
+	"github.com/labbs/nexo/application/document/html"
+	"github.com/labbs/nexo/application/document/markdown"
+	"github.com/labbs/nexo/application/document/pdf"
+	"github.com/labbs/nexo/domain"
+	"github.com/labbs/nexo/infrastructure/helpers/apperrors"
+)
+
+const (
+	// maxExportDocuments limits the size of the tree of a recursive export
+	maxExportDocuments = 500
+	// maxExportImageSize limits the size of an image embedded in an export
+	maxExportImageSize = 20 << 20
+	// maxExportImagesSize limits the size of all the images of an export,
+	// which is built in memory: the images after it are exported as links
+	maxExportImagesSize = 100 << 20
+)
+
+// attachmentURL matches the download url of an attachment
+var attachmentURL = regexp.MustCompile(`/attachment/([0-9a-fA-F-]{36})/content`)
+
+// exportedDocument is a document of an export with its level in the tree
+type exportedDocument struct {
+	document *domain.Document
+	blocks   []dto.Block
+	depth    int
+	parent   *exportedDocument
+}
+
+// ExportDocument converts the content of a document the user can read to
+// the requested format. A recursive export includes the documents under it
+// the user can read, in a single file for html and pdf, with a table of
+// contents.
+func (a *DocumentApplication) ExportDocument(input dto.ExportDocumentInput) (*dto.ExportDocumentOutput, error) {
+	logger := a.Logger.With().Str("component", "application.document.export_document").Logger()
+
+	document, err := a.DocumentPers.GetDocumentByIdOrSlugWithUserPermissions(input.SpaceId, &input.DocumentId, &input.DocumentId, input.UserId)
+	if err != nil {
+		logger.Error().Err(err).Msg("failed to get document for export")
+		return nil, fmt.Errorf("failed to get document for export: %w", err)
+	}
+
+	documents := []*exportedDocument{{document: document, blocks: dto.JSONToBlocks(document.Content)}}
+	if input.Recursive || input.Format == dto.ExportFormatZip {
+		if documents, err = a.exportTree(document, input.UserId); err != nil {
+			logger.Error().Err(err).Msg("failed to get the documents of the export")
+			return nil, err
+		}
+	}
+	images := a.exportImages(input.UserId)
+
+	switch input.Format {
+	case dto.ExportFormatMarkdown:
+		if len(documents) > 1 {
+			return nil, fmt.Errorf("%w: recursive exports are available as html, pdf or zip", apperrors.ErrInvalidInput)
+		}
+		content := "# " + document.Name + "\n"
+		if body := markdown.Render(documents[0].blocks); body != "" {
+			content += "\n" + body
+		}
+		return &dto.ExportDocumentOutput{
+			Filename:    document.Slug + ".md",
+			ContentType: "text/markdown; charset=utf-8",
+			Content:     []byte(content),
+		}, nil
+
+	case dto.ExportFormatHTML:
+		return &dto.ExportDocumentOutput{
+			Filename:    document.Slug + ".html",
+			ContentType: "text/html; charset=utf-8",
+			Content:     []byte(exportHTML(documents, images.dataURI)),
+		}, nil
+
+	case dto.ExportFormatPDF:
+		pages := make([]pdf.Document, len(documents))
+		for i, d := range documents {
+			pages[i] = pdf.Document{Title: d.document.Name, Depth: d.depth, Blocks: d.blocks}
+		}
+		content, err := pdf.Renderer{Images: images.content}.Render(document.Name, pages)
+		if err != nil {
+			logger.Error().Err(err).Msg("failed to render pdf")
+			return nil, fmt.Errorf("failed to render pdf: %w", err)
+		}
+		return &dto.ExportDocumentOutput{
+			Filename:    document.Slug + ".pdf",
+			ContentType: "application/pdf",
+			Content:     content,
+		}, nil
+
+	case dto.ExportFormatZip:
+		content, err := exportZip(documents, images)
+		if err != nil {
+			logger.Error().Err(err).Msg("failed to write zip")
+			return nil, fmt.Errorf("failed to write zip: %w", err)
+		}
+		return &dto.ExportDocumentOutput{
+			Filename:    document.Slug + ".zip",
+			ContentType: "application/zip",
+			Content:     content,
+		}, nil
+
+	default:
+		return nil, fmt.Errorf("%w: unsupported export format %q", apperrors.ErrInvalidInput, input.Format)
+	}
+}
+
+// exportTree lists a document and the documents under it the user can read,
+// depth first in the order of the tree.
+func (a *DocumentApplication) exportTree(root *domain.Document, userId string) ([]*exportedDocument, error) {
+	documents := []*exportedDocument{{document: root, blocks: dto.JSONToBlocks(root.Content)}}
+	visited := map[string]bool{root.Id: true}
+
+	var walk func(parent *exportedDocument) error
+	walk = func(parent *exportedDocument) error {
+		children, err := a.DocumentPers.GetChildDocumentsWithUserPermissions(parent.document.Id, userId)
+		if err != nil {
+			return fmt.Errorf("failed to get child documents: %w", err)
+		}
+		for i := range children {
+			child := &children[i]
+			if visited[child.Id] {
+				continue
+			}
+			if len(documents) >= maxExportDocuments {
+				return fmt.Errorf("%w: the export is limited to %d documents", apperrors.ErrInvalidInput, maxExportDocuments)
+			}
+			visited[child.Id] = true
+			exported := &exportedDocument{document: child, blocks: dto.JSONToBlocks(child.Content), depth: parent.depth + 1, parent: parent}
+			documents = append(documents, exported)
+			if err := walk(exported); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	if err := walk(documents[0]); err != nil {
+		return nil, err
+	}
+	return documents, nil
+}
+
+// exportImages loads the image attachments of an export, once per image
+type exportImages struct {
+	load   func(url string) ([]byte, string)
+	loaded map[string]exportImage
+}
+
+type exportImage struct {
+	id          string
+	data        []byte
+	contentType string
+}
+
+func (a *DocumentApplication) exportImages(userId string) *exportImages {
+	remaining := int64(maxExportImagesSize)
+	return &exportImages{
+		loaded: make(map[string]exportImage),
+		load: func(url string) ([]byte, string) {
+			m := attachmentURL.FindStringSubmatch(url)
+			if m == nil || a.AttachmentApplication == nil {
+				return nil, ""
+			}
+			result, err := a.AttachmentApplication.GetContent(attachmentDto.GetContentInput{UserId: userId, AttachmentId: m[1]})
+			if err != nil {
+				a.Logger.Warn().Err(err).Str("attachment_id", m[1]).Msg("failed to load the image of an export")
+				return nil, ""
+			}
+			defer result.Content.Close()
+			size := result.Attachment.Size
+			if !strings.HasPrefix(result.Attachment.ContentType, "image/") || size > maxExportImageSize || size > remaining {
+				return nil, ""
+			}
+			data, err := io.ReadAll(io.LimitReader(result.Content, min(remaining, maxExportImageSize)))
+			if err != nil {
+				return nil, ""
+			}
+			remaining -= int64(len(data))
+			return data, result.Attachment.ContentType
+		},
+	}
+}
+
+// image returns the attachment of an image url, or false for the images
+// which are not attachments the user can read.
+func (i *exportImages) image(url string) (exportImage, bool) {
+	image, ok := i.loaded[url]
+	if !ok {
+		data, contentType := i.load(url)
+		if data != nil {
+			image = exportImage{id: attachmentURL.FindStringSubmatch(url)[1], data: data, contentType: contentType}
+		}
+		i.loaded[url] = image
+	}
+	return image, image.data != nil
+}
+
+func (i *exportImages) content(url string) []byte {
+	image, _ := i.image(url)
+	return image.data
+}
+
+func (i *exportImages) dataURI(url string) string {
+	image, ok := i.image(url)
+	if !ok {
+		return ""
+	}
+	return "data:" + image.contentType + ";base64," + base64.StdEncoding.EncodeToString(image.data)
+}
+
+// exportHTML renders the documents in a single page, with a table of
+// contents when there are several documents.
+func exportHTML(documents []*exportedDocument, images func(url string) string) string {
+	renderer := html.Renderer{Images: images}
+	root := documents[0].document
+
+	if len(documents) == 1 {
+		return html.Page(root.Name, html.Title("", root.Name)+renderer.Blocks(documents[0].blocks))
+	}
+
+	entries := make([]html.TocEntry, len(documents))
+	for i, d := range documents {
+		entries[i] = html.TocEntry{Title: d.document.Name, Href: "#" + d.document.Slug, Depth: d.depth}
+	}
+	var body strings.Builder
+	body.WriteString(html.Toc(entries))
+	for _, d := range documents {
+		body.WriteString(`<section class="document">` + "\n")
+		body.WriteString(html.Title(d.document.Slug, d.document.Name))
+		body.WriteString(renderer.Blocks(d.blocks))
+		body.WriteString("</section>\n")
+	}
+	return html.Page(root.Name, body.String())
+}
+
+// exportZip writes a page per document, an index.html table of contents
+// and the images in an assets folder.
+func exportZip(documents []*exportedDocument, images *exportImages) ([]byte, error) {
+	var out bytes.Buffer
+	archive := zip.NewWriter(&out)
+	add := func(name string, content []byte) error {
+		w, err := archive.Create(name)
+		if err != nil {
+			return err
+		}
+		_, err = w.Write(content)
+		return err
+	}
+
+	assets := make(map[string]string)
+	renderer := html.Renderer{Images: func(url string) string {
+		image, ok := images.image(url)
+		if !ok {
+			return ""
+		}
+		if _, written := assets[image.id]; !written {
+			extension := ".img"
+			if extensions, _ := mime.ExtensionsByType(image.contentType); len(extensions) > 0 {
+				extension = extensions[0]
+			}
+			assets[image.id] = "assets/" + image.id + extension
+			if err := add(assets[image.id], image.data); err != nil {
+				return ""
+			}
+		}
+		return assets[image.id]
+	}}
+
+	file := func(d *exportedDocument) string {
+		return d.document.Slug + ".html"
+	}
+	entries := make([]html.TocEntry, len(documents))
+	for i, d := range documents {
+		entries[i] = html.TocEntry{Title: d.document.Name, Href: file(d), Depth: d.depth}
+	}
+	root := documents[0].document
+	if err := add("index.html", []byte(html.Page(root.Name, html.Title("", root.Name)+html.Toc(entries)))); err != nil {
+		return nil, err
+	}
+
+	for _, d := range documents {
+		// Links to the parents, and to the children under the content
+		breadcrumb := make([]html.TocEntry, d.depth+1)
+		breadcrumb[0] = html.TocEntry{Title: "Contents", Href: "index.html"}
+		for parent := d.parent; parent != nil; parent = parent.parent {
+			breadcrumb[parent.depth+1] = html.TocEntry{Title: parent.document.Name, Href: file(parent)}
+		}
+		var children []html.TocEntry
+		for _, child := range documents {
+			if child.parent == d {
+				children = append(children, html.TocEntry{Title: child.document.Name, Href: file(child)})
+			}
+		}
+
+		body := html.Breadcrumb(breadcrumb) + html.Title("", d.document.Name) + renderer.Blocks(d.blocks)
+		if len(children) > 0 {
+			body += "<h2>Pages</h2>\n" + html.Toc(children)
+		}
+		if err := add(file(d), []byte(html.Page(d.document.Name, body))); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := archive.Close(); err != nil {
+		return nil, err
+	}
+	return out.Bytes(), nil
+}
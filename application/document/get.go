@@ -26,6 +26,8 @@ func (a *DocumentApplication) GetDocumentWithSpace(input dto.GetDocumentWithSpac
 		SpaceId:  document.SpaceId,
 		ParentId: document.ParentId,
 		Public:   document.Public,
+
+		BreakInheritance: document.BreakInheritance,
 		Content:  dto.JSONToBlocks(document.Content),
 		Config: dto.DocumentConfig{
 			Icon:             document.Config.Icon,
@@ -19,8 +19,9 @@ func (a *DocumentApplication) GetDocumentByIdOrSlugWithUserPermissions(input dto
 	permissions := make([]dto.DocumentPermission, len(doc.Permissions))
 	for i, p := range doc.Permissions {
 		permissions[i] = dto.DocumentPermission{
-			UserId: p.UserId,
-			Role:   string(p.Role),
+			UserId:  p.UserId,
+			GroupId: p.GroupId,
+			Role:    string(p.Role),
 		}
 	}
 
@@ -39,7 +40,13 @@ func (a *DocumentApplication) GetDocumentByIdOrSlugWithUserPermissions(input dto
 			Type:    string(doc.Space.Type),
 			OwnerId: doc.Space.OwnerId,
 		},
-		Permissions: permissions,
+		Permissions:       permissions,
+		UserId:            input.UserId,
+		ManagePermissions: doc.CanManagePermissions(input.UserId),
+	}
+	if role := doc.GetUserRole(input.UserId); role != nil {
+		userRole := string(*role)
+		detail.UserRole = &userRole
 	}
 
 	return &dto.GetDocumentByIdOrSlugWithUserPermissionsOutput{Document: detail}, nil
@@ -0,0 +1,113 @@
+package html
+
+import (
+	"encoding/xml"
+	"regexp"
+	"strings"
+)
+
+// rawTextElements hold text which is not markup, they are removed before
+// parsing.
+var rawTextElements = regexp.MustCompile(`(?is)<(script|style|template)\b.*?</(script|style|template)\s*>`)
+
+// Node is an element or a text of a parsed HTML document. Text nodes have
+// no tag.
+type Node struct {
+	Tag      string
+	Attrs    map[string]string
+	Text     string
+	Children []*Node
+}
+
+// ParseDocument parses an HTML document, or a fragment, in a tree of nodes.
+// The parser is lenient: unclosed elements are closed by their parent, and
+// the content after a syntax error is dropped.
+func ParseDocument(source string) *Node {
+	root := &Node{Tag: "#document"}
+	decoder := xml.NewDecoder(strings.NewReader(rawTextElements.ReplaceAllString(source, "")))
+	decoder.Strict = false
+	decoder.AutoClose = xml.HTMLAutoClose
+	decoder.Entity = xml.HTMLEntity
+
+	stack := []*Node{root}
+	for {
+		token, err := decoder.Token()
+		if err != nil {
+			break
+		}
+		parent := stack[len(stack)-1]
+		switch t := token.(type) {
+		case xml.StartElement:
+			node := &Node{Tag: strings.ToLower(t.Name.Local), Attrs: make(map[string]string, len(t.Attr))}
+			for _, attr := range t.Attr {
+				node.Attrs[strings.ToLower(attr.Name.Local)] = attr.Value
+			}
+			parent.Children = append(parent.Children, node)
+			stack = append(stack, node)
+		case xml.EndElement:
+			if len(stack) > 1 {
+				stack = stack[:len(stack)-1]
+			}
+		case xml.CharData:
+			if n := len(parent.Children); n > 0 && parent.Children[n-1].Tag == "" {
+				parent.Children[n-1].Text += string(t)
+			} else {
+				parent.Children = append(parent.Children, &Node{Text: string(t)})
+			}
+		}
+	}
+	return root
+}
+
+// Attr returns the value of an attribute, or "" when it is not set
+func (n *Node) Attr(name string) string {
+	return n.Attrs[name]
+}
+
+// HasClass reports whether the class attribute of the node has a class
+func (n *Node) HasClass(class string) bool {
+	for _, c := range strings.Fields(n.Attr("class")) {
+		if c == class {
+			return true
+		}
+	}
+	return false
+}
+
+// Find returns the first node of the tree, depth first, that matches
+func (n *Node) Find(match func(*Node) bool) *Node {
+	if match(n) {
+		return n
+	}
+	for _, child := range n.Children {
+		if found := child.Find(match); found != nil {
+			return found
+		}
+	}
+	return nil
+}
+
+// FindAll returns the nodes of the tree that match, depth first. The
+// nodes under a matching node are not searched.
+func (n *Node) FindAll(match func(*Node) bool) []*Node {
+	if match(n) {
+		return []*Node{n}
+	}
+	var found []*Node
+	for _, child := range n.Children {
+		found = append(found, child.FindAll(match)...)
+	}
+	return found
+}
+
+// TextContent returns the text of the node and of the nodes under it
+func (n *Node) TextContent() string {
+	if n.Tag == "" {
+		return n.Text
+	}
+	var b strings.Builder
+	for _, child := range n.Children {
+		b.WriteString(child.TextContent())
+	}
+	return b.String()
+}
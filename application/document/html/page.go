@@ -0,0 +1,98 @@
+package html
+
+import (
+	"html"
+	"strings"
+)
+
+// styles of the exported pages, with the colors of BlockNote
+const styles = `
+body { max-width: 860px; margin: 40px auto; padding: 0 24px; font-family: -apple-system, BlinkMacSystemFont, "Segoe UI", Helvetica, Arial, sans-serif; font-size: 16px; line-height: 1.6; color: #1f2328; }
+h1, h2, h3, h4, h5, h6 { line-height: 1.25; margin: 1.4em 0 0.5em; }
+a { color: #0b6e99; }
+pre { background: #f6f8fa; border-radius: 6px; padding: 12px 16px; overflow-x: auto; }
+code { font-family: ui-monospace, SFMono-Regular, Menlo, Consolas, monospace; font-size: 0.9em; }
+p code, li code, td code, th code { background: #f0f0f0; border-radius: 4px; padding: 0.1em 0.3em; }
+blockquote { margin: 1em 0; padding: 0 1em; border-left: 4px solid #d0d7de; color: #59636e; }
+table { border-collapse: collapse; margin: 1em 0; }
+th, td { border: 1px solid #d0d7de; padding: 6px 12px; vertical-align: top; }
+th { background: #f6f8fa; }
+figure { margin: 1em 0; }
+figure img { max-width: 100%; }
+figcaption { font-size: 0.9em; color: #59636e; }
+ul.checklist { list-style: none; padding-left: 1.2em; }
+.children { margin-left: 1.5em; }
+.columns { display: flex; gap: 24px; }
+.column { flex: 1; min-width: 0; }
+nav.toc ul { list-style: none; padding-left: 1.2em; }
+nav.toc > ul { padding-left: 0; }
+nav.breadcrumb { font-size: 0.9em; }
+section.document + section.document { border-top: 1px solid #d0d7de; margin-top: 3em; }
+.color-gray { color: #9b9a97; } .color-brown { color: #64473a; } .color-red { color: #e03e3e; }
+.color-orange { color: #d9730d; } .color-yellow { color: #dfab01; } .color-green { color: #4d6461; }
+.color-blue { color: #0b6e99; } .color-purple { color: #6940a5; } .color-pink { color: #ad1a72; }
+.background-gray { background: #ebeced; } .background-brown { background: #e9e5e3; } .background-red { background: #fbe4e4; }
+.background-orange { background: #f6e9d9; } .background-yellow { background: #fbf3db; } .background-green { background: #ddedea; }
+.background-blue { background: #ddebf1; } .background-purple { background: #eae4f2; } .background-pink { background: #f4dfeb; }
+@media print { body { margin: 0; max-width: none; } section.document { break-before: page; } section.document:first-of-type { break-before: auto; } }
+`
+
+// Page wraps a body in a standalone page with its styles
+func Page(title, body string) string {
+	var b strings.Builder
+	b.WriteString("<!DOCTYPE html>\n<html>\n<head>\n<meta charset=\"utf-8\">\n")
+	b.WriteString("<meta name=\"viewport\" content=\"width=device-width, initial-scale=1\">\n")
+	b.WriteString("<title>" + html.EscapeString(title) + "</title>\n")
+	b.WriteString("<style>" + styles + "</style>\n</head>\n<body>\n")
+	b.WriteString(body)
+	b.WriteString("</body>\n</html>\n")
+	return b.String()
+}
+
+// TocEntry is a document of a table of contents, Depth is its level in
+// the exported tree.
+type TocEntry struct {
+	Title string
+	Href  string
+	Depth int
+}
+
+// Toc renders a table of contents, nested by the depth of the entries
+func Toc(entries []TocEntry) string {
+	var b strings.Builder
+	b.WriteString("<nav class=\"toc\">\n")
+	depth := -1
+	for _, entry := range entries {
+		for ; depth < entry.Depth; depth++ {
+			b.WriteString("<ul>\n")
+		}
+		for ; depth > entry.Depth; depth-- {
+			b.WriteString("</ul>\n")
+		}
+		b.WriteString(`<li><a href="` + html.EscapeString(entry.Href) + `">` + html.EscapeString(entry.Title) + "</a></li>\n")
+	}
+	for ; depth >= 0; depth-- {
+		b.WriteString("</ul>\n")
+	}
+	b.WriteString("</nav>\n")
+	return b.String()
+}
+
+// Title renders the title of a document, with an anchor for the links of
+// the table of contents.
+func Title(id, title string) string {
+	anchor := ""
+	if id != "" {
+		anchor = ` id="` + html.EscapeString(id) + `"`
+	}
+	return "<h1" + anchor + ">" + html.EscapeString(title) + "</h1>\n"
+}
+
+// Breadcrumb renders links to the parents of a page
+func Breadcrumb(entries []TocEntry) string {
+	links := make([]string, len(entries))
+	for i, entry := range entries {
+		links[i] = `<a href="` + html.EscapeString(entry.Href) + `">` + html.EscapeString(entry.Title) + "</a>"
+	}
+	return "<nav class=\"breadcrumb\">" + strings.Join(links, " / ") + "</nav>\n"
+}
@@ -0,0 +1,455 @@
+package html
+
+import (
+	"maps"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/gofiber/fiber/v2/utils"
+	"github.com/labbs/nexo/application/document/dto"
+)
+
+var (
+	whitespace     = regexp.MustCompile(`[ \t\r\n\f]+`)
+	languageClass  = regexp.MustCompile(`(?:^|\s)(?:language|lang)-(\S+)`)
+	syntaxLanguage = regexp.MustCompile(`brush:\s*([\w+#-]+)`)
+)
+
+// Elements converted to blocks, the others are inline
+var blockElements = map[string]bool{
+	"address": true, "article": true, "aside": true, "audio": true, "blockquote": true, "body": true,
+	"dd": true, "details": true, "div": true, "dl": true, "dt": true, "fieldset": true, "figcaption": true,
+	"figure": true, "footer": true, "form": true, "h1": true, "h2": true, "h3": true, "h4": true, "h5": true,
+	"h6": true, "head": true, "header": true, "hr": true, "html": true, "img": true, "li": true, "main": true,
+	"nav": true, "ol": true, "p": true, "pre": true, "section": true, "summary": true, "table": true,
+	"ul": true, "video": true, "#document": true,
+}
+
+// Elements without content of the document
+var skippedElements = map[string]bool{
+	"head": true, "title": true, "meta": true, "link": true, "hr": true, "input": true, "button": true,
+	"select": true, "textarea": true, "noscript": true, "svg": true, "iframe": true, "object": true, "map": true,
+}
+
+// Parse converts HTML to blocks: headings, paragraphs, lists, task lists,
+// code blocks, quotes, tables, images, video and audio. The other elements
+// are unwrapped, and the text outside of a block becomes a paragraph.
+func Parse(source string) []dto.Block {
+	root := ParseDocument(source)
+	if body := root.Find(func(n *Node) bool { return n.Tag == "body" }); body != nil {
+		root = body
+	}
+	return root.Blocks()
+}
+
+// Blocks converts the content of a node to blocks, see Parse
+func (n *Node) Blocks() []dto.Block {
+	c := &converter{blocks: []dto.Block{}}
+	c.container(n)
+	return c.blocks
+}
+
+// converter appends the blocks of the nodes it visits
+type converter struct {
+	blocks []dto.Block
+}
+
+// container converts the children of a node, the consecutive inline nodes
+// form a paragraph.
+func (c *converter) container(n *Node) {
+	var inline []*Node
+	flush := func() {
+		if content := inlineContent(inline); len(content) > 0 {
+			c.blocks = append(c.blocks, newBlock(dto.BlockTypeParagraph, content))
+		}
+		inline = nil
+	}
+	for _, child := range n.Children {
+		if isInline(child) {
+			inline = append(inline, child)
+			continue
+		}
+		flush()
+		c.block(child)
+	}
+	flush()
+}
+
+func (c *converter) block(n *Node) {
+	switch n.Tag {
+	case "h1", "h2", "h3", "h4", "h5", "h6":
+		block := newBlock(dto.BlockTypeHeading, inlineContent(n.Children))
+		block.Props["level"] = min(int(n.Tag[1]-'0'), 3)
+		c.blocks = append(c.blocks, block)
+
+	case "p":
+		if content := inlineContent(n.Children); len(content) > 0 && !containsBlock(n) {
+			c.blocks = append(c.blocks, newBlock(dto.BlockTypeParagraph, content))
+		} else {
+			c.container(n)
+		}
+
+	case "ul", "ol":
+		c.list(n)
+
+	case "li":
+		c.blocks = append(c.blocks, listItem(n, dto.BlockTypeBulletListItem))
+
+	case "pre":
+		c.blocks = append(c.blocks, codeBlock(n))
+
+	case "blockquote":
+		c.blocks = append(c.blocks, nestedBlock(dto.BlockTypeQuote, n.Blocks()))
+
+	case "table":
+		if block, ok := table(n); ok {
+			c.blocks = append(c.blocks, block)
+		}
+
+	case "img":
+		if block, ok := image(n, ""); ok {
+			c.blocks = append(c.blocks, block)
+		}
+
+	case "figure":
+		img := n.Find(func(n *Node) bool { return n.Tag == "img" })
+		caption := n.Find(func(n *Node) bool { return n.Tag == "figcaption" })
+		if img == nil {
+			c.container(n)
+			break
+		}
+		text := ""
+		if caption != nil {
+			text = collapse(caption.TextContent())
+		}
+		if block, ok := image(img, strings.TrimSpace(text)); ok {
+			c.blocks = append(c.blocks, block)
+		}
+
+	case "video", "audio":
+		url := n.Attr("src")
+		if source := n.Find(func(n *Node) bool { return n.Tag == "source" }); url == "" && source != nil {
+			url = source.Attr("src")
+		}
+		if url != "" {
+			blockType := dto.BlockTypeVideo
+			if n.Tag == "audio" {
+				blockType = dto.BlockTypeAudio
+			}
+			block := newBlock(blockType, nil)
+			block.Props = map[string]any{"url": url, "caption": "", "name": "", "showPreview": true}
+			c.blocks = append(c.blocks, block)
+		}
+
+	default:
+		if !skippedElements[n.Tag] {
+			c.container(n)
+		}
+	}
+}
+
+// list converts the items of a list, the items of a task list (an input
+// checkbox or a "checked" class, as in Confluence) are check list items.
+func (c *converter) list(n *Node) {
+	blockType := dto.BlockTypeBulletListItem
+	if n.Tag == "ol" {
+		blockType = dto.BlockTypeNumberedListItem
+	}
+	first := true
+	for _, child := range n.Children {
+		if child.Tag == "" {
+			continue
+		}
+		if child.Tag != "li" {
+			c.block(child)
+			continue
+		}
+		item := listItem(child, blockType)
+		if start, err := strconv.Atoi(n.Attr("start")); err == nil && first && blockType == dto.BlockTypeNumberedListItem && start != 1 {
+			item.Props["start"] = start
+		}
+		first = false
+		c.blocks = append(c.blocks, item)
+	}
+}
+
+// listItem converts an item: its text up to the first block is the content
+// of the item, the blocks are its children.
+func listItem(n *Node, blockType string) dto.Block {
+	checkbox := n.Find(func(n *Node) bool { return n.Tag == "input" && strings.EqualFold(n.Attr("type"), "checkbox") })
+	if checkbox != nil || n.HasClass("checked") || n.Attr("data-inline-task-id") != "" {
+		blockType = dto.BlockTypeCheckListItem
+	}
+
+	children := n.Blocks()
+	var content []dto.InlineContent
+	if len(children) > 0 && children[0].Type == dto.BlockTypeParagraph {
+		content = children[0].Content
+		children = children[1:]
+	}
+	block := newBlock(blockType, content)
+	block.Children = children
+	if blockType == dto.BlockTypeCheckListItem {
+		checked := n.HasClass("checked")
+		if checkbox != nil {
+			_, checked = checkbox.Attrs["checked"]
+		}
+		block.Props["checked"] = checked
+	}
+	return block
+}
+
+// codeBlock converts a pre element, its language is taken from the class
+// of the element or of its code element, or from the parameters of the
+// Confluence code macro.
+func codeBlock(n *Node) dto.Block {
+	language := ""
+	for _, node := range []*Node{n, n.Find(func(n *Node) bool { return n.Tag == "code" })} {
+		if node == nil || language != "" {
+			continue
+		}
+		if m := languageClass.FindStringSubmatch(node.Attr("class")); m != nil {
+			language = m[1]
+		} else if m := syntaxLanguage.FindStringSubmatch(node.Attr("data-syntaxhighlighter-params")); m != nil {
+			language = m[1]
+		}
+	}
+
+	code := strings.TrimPrefix(strings.ReplaceAll(n.TextContent(), "\r\n", "\n"), "\n")
+	block := newBlock(dto.BlockTypeCodeBlock, textContent(strings.TrimRight(code, "\n")))
+	block.Props = map[string]any{"language": strings.ToLower(language)}
+	return block
+}
+
+// table converts a table, its first rows are header rows when they only
+// have th cells.
+func table(n *Node) (dto.Block, bool) {
+	rows := n.FindAll(func(child *Node) bool { return child.Tag == "tr" })
+	content := &dto.TableContent{Type: "tableContent"}
+	header := true
+	for _, row := range rows {
+		var cells []dto.TableCell
+		allHeaders := true
+		for _, cell := range row.Children {
+			if cell.Tag != "td" && cell.Tag != "th" {
+				continue
+			}
+			allHeaders = allHeaders && cell.Tag == "th"
+			var text []dto.InlineContent
+			for i, block := range cell.Blocks() {
+				if i > 0 {
+					text = appendText(text, "\n", map[string]bool{}, "")
+				}
+				for _, inline := range block.Content {
+					text = appendText(text, inline.Text, inline.Styles, inline.Href)
+				}
+			}
+			if text == nil {
+				text = []dto.InlineContent{}
+			}
+			cells = append(cells, dto.TableCell{
+				Type: "tableCell",
+				Props: map[string]any{
+					"backgroundColor": "default",
+					"textColor":       "default",
+					"textAlignment":   "left",
+					"colspan":         positiveInt(cell.Attr("colspan")),
+					"rowspan":         positiveInt(cell.Attr("rowspan")),
+				},
+				Content: text,
+			})
+		}
+		if len(cells) == 0 {
+			continue
+		}
+		if header = header && allHeaders; header {
+			content.HeaderRows++
+		}
+		content.Rows = append(content.Rows, dto.TableRow{Cells: cells})
+	}
+	if len(content.Rows) == 0 {
+		return dto.Block{}, false
+	}
+
+	block := newBlock(dto.BlockTypeTable, nil)
+	block.Props = map[string]any{"textColor": "default"}
+	block.TableContent = content
+	return block, true
+}
+
+func image(n *Node, caption string) (dto.Block, bool) {
+	url := n.Attr("src")
+	if url == "" || strings.HasPrefix(url, "data:") {
+		return dto.Block{}, false
+	}
+	if caption == "" {
+		caption = n.Attr("alt")
+	}
+	block := newBlock(dto.BlockTypeImage, nil)
+	block.Props = map[string]any{
+		"url":           url,
+		"caption":       caption,
+		"name":          "",
+		"textAlignment": "left",
+		"showPreview":   true,
+	}
+	if width, err := strconv.Atoi(n.Attr("width")); err == nil && width > 0 {
+		block.Props["previewWidth"] = width
+	}
+	return block, true
+}
+
+// inlineContent converts inline nodes to text runs. The white space is
+// collapsed as in a browser.
+func inlineContent(nodes []*Node) []dto.InlineContent {
+	content := []dto.InlineContent{}
+	for _, n := range nodes {
+		content = appendInline(content, n, map[string]bool{}, "")
+	}
+
+	// Trim the spaces at the start and the end of the block and of its lines
+	for i := range content {
+		text := content[i].Text
+		if i == 0 || strings.HasSuffix(content[i-1].Text, "\n") {
+			text = strings.TrimLeft(text, " ")
+		}
+		text = strings.ReplaceAll(strings.ReplaceAll(text, " \n", "\n"), "\n ", "\n")
+		content[i].Text = text
+	}
+	if n := len(content); n > 0 {
+		content[n-1].Text = strings.TrimRight(content[n-1].Text, " ")
+	}
+	trimmed := content[:0]
+	for _, inline := range content {
+		if inline.Text != "" {
+			trimmed = append(trimmed, inline)
+		}
+	}
+	return trimmed
+}
+
+func appendInline(content []dto.InlineContent, n *Node, styles map[string]bool, href string) []dto.InlineContent {
+	switch n.Tag {
+	case "":
+		text := collapse(n.Text)
+		if len(content) > 0 && strings.HasSuffix(content[len(content)-1].Text, " ") {
+			text = strings.TrimLeft(text, " ")
+		}
+		return appendText(content, text, styles, href)
+	case "br":
+		return appendText(content, "\n", styles, href)
+	case "img", "input", "script", "style", "svg":
+		return content
+	case "strong", "b":
+		styles = withStyle(styles, "bold")
+	case "em", "i", "cite", "var", "dfn":
+		styles = withStyle(styles, "italic")
+	case "u", "ins":
+		styles = withStyle(styles, "underline")
+	case "s", "strike", "del":
+		styles = withStyle(styles, "strike")
+	case "code", "tt", "kbd", "samp":
+		styles = withStyle(styles, "code")
+	case "a":
+		if link := n.Attr("href"); link != "" && !strings.HasPrefix(link, "#") {
+			href = link
+		}
+	}
+	for _, child := range n.Children {
+		content = appendInline(content, child, styles, href)
+	}
+	return content
+}
+
+// appendText appends a text run, merged with the previous run when they
+// have the same styles and link.
+func appendText(content []dto.InlineContent, text string, styles map[string]bool, href string) []dto.InlineContent {
+	if text == "" {
+		return content
+	}
+	if n := len(content); n > 0 && content[n-1].Href == href && maps.Equal(content[n-1].Styles, styles) {
+		content[n-1].Text += text
+		return content
+	}
+	inline := dto.InlineContent{Type: "text", Text: text, Styles: maps.Clone(styles)}
+	if inline.Styles == nil {
+		inline.Styles = map[string]bool{}
+	}
+	if href != "" {
+		inline.Type = "link"
+		inline.Href = href
+	}
+	return append(content, inline)
+}
+
+// isInline reports whether a node is part of the paragraph around it: a
+// text or an inline element without blocks.
+func isInline(n *Node) bool {
+	return n.Tag == "" || !blockElements[n.Tag] && !containsBlock(n)
+}
+
+// containsBlock reports whether there is a block element under a node,
+// such as an image in a link.
+func containsBlock(n *Node) bool {
+	for _, child := range n.Children {
+		if blockElements[child.Tag] || containsBlock(child) {
+			return true
+		}
+	}
+	return false
+}
+
+// nestedBlock creates a block with the text of the first paragraph of its
+// content and the other blocks as children.
+func nestedBlock(blockType string, content []dto.Block) dto.Block {
+	if len(content) > 0 && content[0].Type == dto.BlockTypeParagraph {
+		block := newBlock(blockType, content[0].Content)
+		block.Children = content[1:]
+		return block
+	}
+	block := newBlock(blockType, nil)
+	block.Children = content
+	return block
+}
+
+func newBlock(blockType string, content []dto.InlineContent) dto.Block {
+	if content == nil {
+		content = []dto.InlineContent{}
+	}
+	return dto.Block{
+		ID:   utils.UUIDv4(),
+		Type: blockType,
+		Props: map[string]any{
+			"textColor":       "default",
+			"backgroundColor": "default",
+			"textAlignment":   "left",
+		},
+		Content:  content,
+		Children: []dto.Block{},
+	}
+}
+
+func textContent(text string) []dto.InlineContent {
+	if text == "" {
+		return []dto.InlineContent{}
+	}
+	return []dto.InlineContent{{Type: "text", Text: text, Styles: map[string]bool{}}}
+}
+
+func withStyle(styles map[string]bool, style string) map[string]bool {
+	styles = maps.Clone(styles)
+	styles[style] = true
+	return styles
+}
+
+func collapse(text string) string {
+	return whitespace.ReplaceAllString(text, " ")
+}
+
+func positiveInt(value string) int {
+	if n, err := strconv.Atoi(value); err == nil && n > 0 {
+		return n
+	}
+	return 1
+}
@@ -0,0 +1,280 @@
+// Package html renders the BlockNote blocks of a document as standalone
+// HTML pages.
+package html
+
+import (
+	"fmt"
+	"html"
+	"strings"
+
+	"github.com/labbs/nexo/application/document/dto"
+)
+
+// Renderer converts blocks to HTML. Images returns the source of an image
+// block, to embed the attachments in the page, or "" to keep its url.
+type Renderer struct {
+	Images func(url string) string
+}
+
+// Blocks renders blocks. The items of a list are grouped in the same list
+// element, the children of a block are indented under it.
+func (r Renderer) Blocks(blocks []dto.Block) string {
+	var b strings.Builder
+	for i := 0; i < len(blocks); {
+		tag, class := listTag(blocks[i].Type)
+		if tag == "" {
+			r.block(&b, blocks[i])
+			i++
+			continue
+		}
+
+		// Consecutive items of the same type form a list
+		j := i
+		for j < len(blocks) && blocks[j].Type == blocks[i].Type {
+			j++
+		}
+		b.WriteString("<" + tag + class)
+		if start := intProp(blocks[i].Props, "start", 1); tag == "ol" && start != 1 {
+			fmt.Fprintf(&b, ` start="%d"`, start)
+		}
+		b.WriteString(">\n")
+		for _, item := range blocks[i:j] {
+			r.listItem(&b, item)
+		}
+		b.WriteString("</" + tag + ">\n")
+		i = j
+	}
+	return b.String()
+}
+
+func listTag(blockType string) (string, string) {
+	switch blockType {
+	case dto.BlockTypeBulletListItem:
+		return "ul", ""
+	case dto.BlockTypeNumberedListItem:
+		return "ol", ""
+	case dto.BlockTypeCheckListItem:
+		return "ul", ` class="checklist"`
+	}
+	return "", ""
+}
+
+func (r Renderer) listItem(b *strings.Builder, item dto.Block) {
+	b.WriteString("<li" + blockAttributes(item.Props) + ">")
+	if item.Type == dto.BlockTypeCheckListItem {
+		if checked, _ := item.Props["checked"].(bool); checked {
+			b.WriteString(`<input type="checkbox" checked disabled> `)
+		} else {
+			b.WriteString(`<input type="checkbox" disabled> `)
+		}
+	}
+	b.WriteString(Inline(item.Content))
+	if len(item.Children) > 0 {
+		b.WriteString("\n" + r.Blocks(item.Children))
+	}
+	b.WriteString("</li>\n")
+}
+
+func (r Renderer) block(b *strings.Builder, block dto.Block) {
+	attributes := blockAttributes(block.Props)
+
+	switch block.Type {
+	case dto.BlockTypeHeading:
+		level := min(max(intProp(block.Props, "level", 1), 1), 6)
+		fmt.Fprintf(b, "<h%d%s>%s</h%d>\n", level, attributes, Inline(block.Content), level)
+
+	case dto.BlockTypeCodeBlock:
+		class := ""
+		if language := stringProp(block.Props, "language"); language != "" {
+			class = ` class="language-` + html.EscapeString(language) + `"`
+		}
+		b.WriteString("<pre><code" + class + ">" + html.EscapeString(plainText(block.Content)) + "</code></pre>\n")
+
+	case dto.BlockTypeQuote:
+		b.WriteString("<blockquote" + attributes + ">" + Inline(block.Content) + "</blockquote>\n")
+
+	case dto.BlockTypeTable:
+		r.table(b, block.TableContent)
+
+	case dto.BlockTypeImage:
+		url := stringProp(block.Props, "url")
+		if url == "" {
+			break
+		}
+		source := safeURL(url)
+		if r.Images != nil {
+			if embedded := r.Images(url); embedded != "" {
+				source = embedded
+			}
+		}
+		caption := stringProp(block.Props, "caption")
+		b.WriteString("<figure" + attributes + `><img src="` + html.EscapeString(source) + `" alt="` + html.EscapeString(firstNonEmpty(caption, stringProp(block.Props, "name"))) + `">`)
+		if caption != "" {
+			b.WriteString("<figcaption>" + html.EscapeString(caption) + "</figcaption>")
+		}
+		b.WriteString("</figure>\n")
+
+	case dto.BlockTypeVideo, dto.BlockTypeAudio, dto.BlockTypeFile:
+		url := stringProp(block.Props, "url")
+		if url == "" {
+			break
+		}
+		name := firstNonEmpty(stringProp(block.Props, "name"), stringProp(block.Props, "caption"), url)
+		b.WriteString(`<p class="file"><a href="` + html.EscapeString(safeURL(url)) + `">` + html.EscapeString(name) + "</a></p>\n")
+
+	case dto.BlockTypeColumnList:
+		b.WriteString(`<div class="columns">` + "\n")
+		for _, column := range block.Children {
+			b.WriteString(`<div class="column">` + "\n" + r.Blocks(column.Children) + "</div>\n")
+		}
+		b.WriteString("</div>\n")
+		return
+
+	default:
+		// Paragraphs, and the custom blocks as their text
+		if len(block.Content) > 0 || len(block.Children) == 0 {
+			b.WriteString("<p" + attributes + ">" + Inline(block.Content) + "</p>\n")
+		}
+	}
+
+	if len(block.Children) > 0 {
+		b.WriteString(`<div class="children">` + "\n" + r.Blocks(block.Children) + "</div>\n")
+	}
+}
+
+func (r Renderer) table(b *strings.Builder, content *dto.TableContent) {
+	if content == nil || len(content.Rows) == 0 {
+		return
+	}
+	b.WriteString("<table>\n")
+	for i, row := range content.Rows {
+		cellTag := "td"
+		if i < content.HeaderRows {
+			cellTag = "th"
+		}
+		b.WriteString("<tr>")
+		for _, cell := range row.Cells {
+			b.WriteString("<" + cellTag)
+			if colspan := intProp(cell.Props, "colspan", 1); colspan > 1 {
+				fmt.Fprintf(b, ` colspan="%d"`, colspan)
+			}
+			if rowspan := intProp(cell.Props, "rowspan", 1); rowspan > 1 {
+				fmt.Fprintf(b, ` rowspan="%d"`, rowspan)
+			}
+			b.WriteString(blockAttributes(cell.Props) + ">" + Inline(cell.Content) + "</" + cellTag + ">")
+		}
+		b.WriteString("</tr>\n")
+	}
+	b.WriteString("</table>\n")
+}
+
+// Inline renders inline content, the consecutive runs of a link are written
+// in the same link.
+func Inline(content []dto.InlineContent) string {
+	var b strings.Builder
+	for i := 0; i < len(content); {
+		href := ""
+		if content[i].Type == "link" {
+			href = content[i].Href
+		}
+		j := i + 1
+		for j < len(content) && content[j].Type == content[i].Type && content[j].Href == content[i].Href {
+			j++
+		}
+
+		if href != "" {
+			b.WriteString(`<a href="` + html.EscapeString(safeURL(href)) + `">`)
+		}
+		for _, inline := range content[i:j] {
+			b.WriteString(run(inline))
+		}
+		if href != "" {
+			b.WriteString("</a>")
+		}
+		i = j
+	}
+	return b.String()
+}
+
+func run(inline dto.InlineContent) string {
+	text := strings.ReplaceAll(html.EscapeString(inline.Text), "\n", "<br>")
+	for _, style := range []struct{ name, tag string }{
+		{"code", "code"},
+		{"strike", "s"},
+		{"underline", "u"},
+		{"italic", "em"},
+		{"bold", "strong"},
+	} {
+		if inline.Styles[style.name] {
+			text = "<" + style.tag + ">" + text + "</" + style.tag + ">"
+		}
+	}
+	return text
+}
+
+// blockAttributes converts the colors and the alignment of a block to the
+// classes and the style of its element.
+func blockAttributes(props map[string]any) string {
+	var classes []string
+	if color := stringProp(props, "textColor"); color != "" && color != "default" {
+		classes = append(classes, "color-"+color)
+	}
+	if color := stringProp(props, "backgroundColor"); color != "" && color != "default" {
+		classes = append(classes, "background-"+color)
+	}
+
+	var attributes string
+	if len(classes) > 0 {
+		attributes = ` class="` + html.EscapeString(strings.Join(classes, " ")) + `"`
+	}
+	switch alignment := stringProp(props, "textAlignment"); alignment {
+	case "center", "right", "justify":
+		attributes += ` style="text-align: ` + alignment + `"`
+	}
+	return attributes
+}
+
+// safeURL drops the urls with a scheme which could run a script
+func safeURL(url string) string {
+	scheme, _, found := strings.Cut(url, ":")
+	if !found || strings.ContainsAny(scheme, "/?#") {
+		return url
+	}
+	switch strings.ToLower(scheme) {
+	case "http", "https", "mailto":
+		return url
+	}
+	return "#"
+}
+
+func plainText(content []dto.InlineContent) string {
+	var b strings.Builder
+	for _, inline := range content {
+		b.WriteString(inline.Text)
+	}
+	return b.String()
+}
+
+func stringProp(props map[string]any, key string) string {
+	value, _ := props[key].(string)
+	return value
+}
+
+func intProp(props map[string]any, key string, fallback int) int {
+	switch value := props[key].(type) {
+	case float64:
+		return int(value)
+	case int:
+		return value
+	}
+	return fallback
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, value := range values {
+		if value != "" {
+			return value
+		}
+	}
+	return ""
+}
@@ -0,0 +1,70 @@
+package document
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/labbs/nexo/application/document/dto"
+	"github.com/labbs/nexo/application/document/markdown"
+	"github.com/labbs/nexo/infrastructure/helpers/apperrors"
+)
+
+// ImportMarkdown creates a document from markdown, or replaces the content
+// of an existing document. A level 1 heading at the start of the markdown
+// is the name of the document, as written by the markdown export.
+func (a *DocumentApplication) ImportMarkdown(input dto.ImportMarkdownInput) (*dto.ImportMarkdownOutput, error) {
+	blocks, err := markdown.Parse(input.Markdown)
+	if err != nil {
+		return nil, err
+	}
+
+	name := strings.TrimSpace(input.Name)
+	if len(blocks) > 0 && blocks[0].Type == dto.BlockTypeHeading && blocks[0].Props["level"] == 1 {
+		title := strings.TrimSpace(blockText(blocks[0]))
+		if name == "" || name == title {
+			name = title
+			blocks = blocks[1:]
+		}
+	}
+
+	if input.DocumentId != nil {
+		var newName *string
+		if name != "" {
+			newName = &name
+		}
+		result, err := a.UpdateDocument(dto.UpdateDocumentInput{
+			UserId:     input.UserId,
+			SpaceId:    input.SpaceId,
+			DocumentId: *input.DocumentId,
+			Name:       newName,
+			Content:    &blocks,
+		})
+		if err != nil {
+			return nil, err
+		}
+		return &dto.ImportMarkdownOutput{Document: result.Document}, nil
+	}
+
+	if name == "" {
+		return nil, fmt.Errorf("%w: the document has no name", apperrors.ErrInvalidInput)
+	}
+	result, err := a.CreateDocument(dto.CreateDocumentInput{
+		Name:     name,
+		UserId:   input.UserId,
+		SpaceId:  input.SpaceId,
+		Content:  blocks,
+		ParentId: input.ParentId,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &dto.ImportMarkdownOutput{Document: result.Document}, nil
+}
+
+func blockText(block dto.Block) string {
+	var b strings.Builder
+	for _, inline := range block.Content {
+		b.WriteString(inline.Text)
+	}
+	return b.String()
+}
@@ -0,0 +1,379 @@
+package markdown
+
+import (
+	"maps"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/labbs/nexo/application/document/dto"
+)
+
+var (
+	autolink = regexp.MustCompile(`^<((?:https?|mailto):[^\s<>]*)>`)
+	bareURL  = regexp.MustCompile(`^https?://[^\s<]*[^\s<.,:;!?"')\]*_~]`)
+)
+
+// inlineParser converts the text of a block to text runs, each run holds
+// the styles and the link of its text.
+type inlineParser struct {
+	content []dto.InlineContent
+	// text of the last run, the runs merged with it are written to it
+	text strings.Builder
+	// closers holds the position of the bracket or parenthesis closing the
+	// one at each position of the text (-1 when none), so that reading a
+	// link does not scan the rest of the text again
+	closers []int
+}
+
+// parseInline converts markdown inline text: emphasis, strong emphasis,
+// strikethrough, code spans, links, autolinks and <u> tags. Line breaks are
+// spaces, except the hard breaks (a backslash or two spaces at the end of
+// the line).
+func parseInline(text string) []dto.InlineContent {
+	p := &inlineParser{content: []dto.InlineContent{}, closers: matchClosers(text)}
+	p.parse(text, 0, map[string]bool{}, "")
+	p.endRun()
+	return p.content
+}
+
+// parse converts the text at offset in the text of the block
+func (p *inlineParser) parse(text string, offset int, styles map[string]bool, href string) {
+	// Scans of delimiter runs which found no closing run, by delimiter and
+	// length: the runs of the same kind after them have none either, unless
+	// they are in a code span the scan skipped
+	unclosed := map[[2]int]*emphasisScan{}
+
+	var buf strings.Builder
+	flush := func() {
+		p.add(buf.String(), styles, href)
+		buf.Reset()
+	}
+
+	for i := 0; i < len(text); {
+		c := text[i]
+		switch {
+		case c == '\\' && i+1 < len(text) && text[i+1] == '\n':
+			buf.WriteString("\n")
+			i += 2
+
+		case c == '\\' && i+1 < len(text) && isPunctuation(text[i+1]):
+			buf.WriteByte(text[i+1])
+			i += 2
+
+		case c == '\n':
+			line := strings.TrimRight(buf.String(), " ")
+			hard := strings.HasSuffix(buf.String(), "  ")
+			buf.Reset()
+			buf.WriteString(line)
+			if hard {
+				buf.WriteString("\n")
+			} else {
+				buf.WriteString(" ")
+			}
+			i++
+			for i < len(text) && text[i] == ' ' {
+				i++
+			}
+
+		case c == '`':
+			n := runLength(text, i)
+			end := closingCode(text, i+n, n)
+			if end < 0 {
+				buf.WriteString(text[i : i+n])
+				i += n
+				continue
+			}
+			flush()
+			code := strings.ReplaceAll(text[i+n:end], "\n", " ")
+			if len(code) > 2 && code[0] == ' ' && code[len(code)-1] == ' ' && strings.TrimSpace(code) != "" {
+				code = code[1 : len(code)-1]
+			}
+			p.add(code, withStyle(styles, "code"), href)
+			i = end + n
+
+		case c == '[' || c == '!' && i+1 < len(text) && text[i+1] == '[':
+			open := i
+			if c == '!' {
+				open++
+			}
+			labelEnd, url, end := p.parseLink(text, offset, open)
+			if end < 0 || href != "" {
+				buf.WriteByte(c)
+				i++
+				continue
+			}
+			flush()
+			p.parse(text[open+1:labelEnd], offset+open+1, styles, url)
+			i = end
+
+		case c == '<':
+			if m := autolink.FindStringSubmatch(text[i:]); m != nil && href == "" {
+				flush()
+				p.add(m[1], styles, m[1])
+				i += len(m[0])
+				continue
+			}
+			if strings.HasPrefix(text[i:], "<u>") {
+				if end := strings.Index(text[i+3:], "</u>"); end >= 0 {
+					flush()
+					p.parse(text[i+3:i+3+end], offset+i+3, withStyle(styles, "underline"), href)
+					i += 3 + end + 4
+					continue
+				}
+			}
+			buf.WriteByte(c)
+			i++
+
+		case c == 'h' && href == "" && (i == 0 || !isAlphanumeric(text[i-1])) && bareURL.MatchString(text[i:]):
+			url := bareURL.FindString(text[i:])
+			flush()
+			p.add(url, styles, url)
+			i += len(url)
+
+		case c == '*' || c == '_' || c == '~':
+			n := runLength(text, i)
+			end := -1
+			var inner string
+			key := [2]int{int(c), n}
+			if scan := unclosed[key]; scan == nil || scan.skipped(i) {
+				scan = &emphasisScan{}
+				inner, end = emphasis(text, i, n, scan)
+				if scan.done {
+					unclosed[key] = scan
+				}
+			}
+			if end < 0 {
+				buf.WriteString(text[i : i+n])
+				i += n
+				continue
+			}
+			flush()
+			nested := styles
+			switch {
+			case c == '~':
+				nested = withStyle(nested, "strike")
+			case n >= 3:
+				nested = withStyle(withStyle(nested, "bold"), "italic")
+			case n == 2:
+				nested = withStyle(nested, "bold")
+			default:
+				nested = withStyle(nested, "italic")
+			}
+			p.parse(inner, offset+i+n, nested, href)
+			i = end
+
+		default:
+			buf.WriteByte(c)
+			i++
+		}
+	}
+	flush()
+}
+
+// add appends a text run, merged with the previous run when they have the
+// same styles and link.
+func (p *inlineParser) add(text string, styles map[string]bool, href string) {
+	if text == "" {
+		return
+	}
+	if n := len(p.content); n > 0 && p.content[n-1].Href == href && maps.Equal(p.content[n-1].Styles, styles) {
+		p.text.WriteString(text)
+		return
+	}
+
+	p.endRun()
+	inline := dto.InlineContent{Type: "text", Styles: maps.Clone(styles)}
+	if href != "" {
+		inline.Type = "link"
+		inline.Href = href
+	}
+	p.content = append(p.content, inline)
+	p.text.WriteString(text)
+}
+
+// endRun sets the text of the last run
+func (p *inlineParser) endRun() {
+	if n := len(p.content); n > 0 && p.text.Len() > 0 {
+		p.content[n-1].Text = p.text.String()
+		p.text.Reset()
+	}
+}
+
+// emphasisScan records a scan for a closing delimiter run
+type emphasisScan struct {
+	// done when the scan reached the end of the text
+	done bool
+	// codeSpans are the code spans the scan skipped
+	codeSpans [][2]int
+}
+
+// skipped reports whether the scan skipped the position in a code span
+func (s *emphasisScan) skipped(i int) bool {
+	// The spans are in the order of the text
+	n := sort.Search(len(s.codeSpans), func(k int) bool { return s.codeSpans[k][1] > i })
+	return n < len(s.codeSpans) && s.codeSpans[n][0] <= i
+}
+
+// emphasis finds the closing delimiter of the run of n delimiters at start,
+// and returns the text between them and the end of the closing delimiter.
+func emphasis(text string, start, n int, scan *emphasisScan) (string, int) {
+	c := text[start]
+	after := start + n
+	if n > 3 || c == '~' && n > 2 || after >= len(text) || isSpace(text[after]) {
+		return "", -1
+	}
+	// An underscore inside a word does not start an emphasis
+	if c == '_' && start > 0 && isAlphanumeric(text[start-1]) {
+		return "", -1
+	}
+
+	for i := after; i < len(text); {
+		switch {
+		case text[i] == '\\':
+			i += 2
+		case text[i] == '`':
+			m := runLength(text, i)
+			if end := closingCode(text, i+m, m); end >= 0 {
+				scan.codeSpans = append(scan.codeSpans, [2]int{i, end + m})
+				i = end + m
+			} else {
+				i += m
+			}
+		case text[i] == c:
+			m := runLength(text, i)
+			closes := m >= n && !isSpace(text[i-1]) && i > after
+			if c == '_' && i+m < len(text) && isAlphanumeric(text[i+m]) {
+				closes = false
+			}
+			if closes {
+				// The delimiters of a longer run close the innermost emphasis
+				return text[after : i+m-n], i + m
+			}
+			i += m
+		default:
+			i++
+		}
+	}
+	scan.done = true
+	return "", -1
+}
+
+// parseLink reads a link or an image at the opening bracket: the end of its
+// label, its destination and the end of the link.
+func (p *inlineParser) parseLink(text string, offset, open int) (int, string, int) {
+	closing := p.closer(text, offset, open)
+	if closing < 0 || closing+1 >= len(text) || text[closing+1] != '(' {
+		return 0, "", -1
+	}
+	end := p.closer(text, offset, closing+1)
+	if end < 0 {
+		return 0, "", -1
+	}
+
+	target := strings.TrimSpace(text[closing+2 : end])
+	var url string
+	if strings.HasPrefix(target, "<") {
+		if gt := strings.Index(target, ">"); gt > 0 {
+			url = target[1:gt]
+		}
+	} else {
+		url, _, _ = strings.Cut(target, " ")
+	}
+	if url == "" {
+		return 0, "", -1
+	}
+	return closing, unescape(url), end + 1
+}
+
+// closer returns the position in text of the bracket or parenthesis closing
+// the one at i, -1 when it is not closed within text
+func (p *inlineParser) closer(text string, offset, i int) int {
+	closing := p.closers[offset+i] - offset
+	if closing <= i || closing >= len(text) {
+		return -1
+	}
+	return closing
+}
+
+// matchClosers pairs the brackets, and the parentheses, of a text. Escaped
+// ones are skipped.
+func matchClosers(text string) []int {
+	closers := make([]int, len(text))
+	var brackets, parens []int
+	for i := 0; i < len(text); i++ {
+		closers[i] = -1
+		switch text[i] {
+		case '\\':
+			if i+1 < len(text) {
+				closers[i+1] = -1
+			}
+			i++
+		case '[':
+			brackets = append(brackets, i)
+		case '(':
+			parens = append(parens, i)
+		case ']':
+			if n := len(brackets); n > 0 {
+				closers[brackets[n-1]] = i
+				brackets = brackets[:n-1]
+			}
+		case ')':
+			if n := len(parens); n > 0 {
+				closers[parens[n-1]] = i
+				parens = parens[:n-1]
+			}
+		}
+	}
+	return closers
+}
+
+// closingCode finds the run of exactly n backticks closing a code span
+func closingCode(text string, from, n int) int {
+	for i := from; i < len(text); {
+		if text[i] != '`' {
+			i++
+			continue
+		}
+		m := runLength(text, i)
+		if m == n {
+			return i
+		}
+		i += m
+	}
+	return -1
+}
+
+func runLength(text string, start int) int {
+	n := 1
+	for start+n < len(text) && text[start+n] == text[start] {
+		n++
+	}
+	return n
+}
+
+func withStyle(styles map[string]bool, style string) map[string]bool {
+	nested := maps.Clone(styles)
+	nested[style] = true
+	return nested
+}
+
+// unescape removes the backslashes escaping punctuation
+func unescape(text string) string {
+	var b strings.Builder
+	for i := 0; i < len(text); i++ {
+		if text[i] == '\\' && i+1 < len(text) && isPunctuation(text[i+1]) {
+			i++
+		}
+		b.WriteByte(text[i])
+	}
+	return b.String()
+}
+
+func isPunctuation(c byte) bool {
+	return strings.IndexByte("!\"#$%&'()*+,-./:;<=>?@[\\]^_`{|}~", c) >= 0
+}
+
+func isSpace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\n'
+}
@@ -0,0 +1,222 @@
+package markdown
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/labbs/nexo/application/document/dto"
+)
+
+// parse parses markdown which must be accepted
+func parse(t *testing.T, source string) []dto.Block {
+	t.Helper()
+	blocks, err := Parse(source)
+	if err != nil {
+		t.Fatalf("Parse(%q): %v", source, err)
+	}
+	return blocks
+}
+
+// shape describes the types of blocks and their children, e.g.
+// "bulletListItem(bulletListItem) paragraph"
+func shape(blocks []dto.Block) string {
+	parts := make([]string, len(blocks))
+	for i, block := range blocks {
+		parts[i] = block.Type
+		if len(block.Children) > 0 {
+			parts[i] += "(" + shape(block.Children) + ")"
+		}
+	}
+	return strings.Join(parts, " ")
+}
+
+func TestRoundTrip(t *testing.T) {
+	sources := []string{
+		"# Title\n\nSome *emphasis*, **strong**, ~~strike~~, `code`, <u>under</u> and a [link](https://example.com).\n",
+		"## Sub\n\n### Section\n",
+		"- one\n- two\n  - nested\n    - deeper\n- three\n",
+		"1. one\n2. two\n   1. nested\n",
+		"3. three\n4. four\n",
+		"- [ ] todo\n- [x] done\n  - [ ] sub\n",
+		"- item\n\n1. other list\n",
+		"| Name | Age |\n| --- | ---: |\n| Ann | 42 |\n| Bob \\| Jr | 7 |\n",
+		"```go\nfunc main() {\n}\n```\n",
+		"````\n```\nnested fence\n```\n````\n",
+		"> quoted text\n>\n> - item\n",
+		"![alt](https://example.com/a.png)\n",
+		"1\\. not a list\n\n\\# not a heading\n\n\\> not a quote\n",
+		"a snake_case word and 2 \\* 3\n",
+		"line\\\nbreak\n",
+		"***bold italic*** and [**bold link**](<https://example.com/a_(b)>)\n",
+	}
+
+	for _, source := range sources {
+		if got := Render(parse(t, source)); got != source {
+			t.Errorf("Render(Parse(%q)) = %q", source, got)
+		}
+	}
+}
+
+func TestParseNormalizes(t *testing.T) {
+	tests := []struct {
+		source string
+		want   string
+	}{
+		{"Heading\n===\n\nSub\n---\n", "# Heading\n\n## Sub\n"},
+		{"* a\r\n* b\r\n\r\n\r\n1) x\r\n", "- a\n- b\n\n1. x\n"},
+		{"> quoted\n> text\nlazy\n", "> quoted text lazy\n"},
+		{"- item\n\tindented\n", "- item indented\n"},
+		{"    indented code\n", "```\nindented code\n```\n"},
+		{"a\n\n---\n\nb\n", "a\n\nb\n"},
+		{"#### Deep\n", "### Deep\n"},
+		{"| a | b |\n| :-: | - |\n| 1 |\n", "| a | b |\n| :---: | --- |\n| 1 |  |\n"},
+		{"<https://example.com> and https://example.com/x.", "<https://example.com> and <https://example.com/x>.\n"},
+	}
+
+	for _, tt := range tests {
+		if got := Render(parse(t, tt.source)); got != tt.want {
+			t.Errorf("Render(Parse(%q)) = %q, want %q", tt.source, got, tt.want)
+		}
+	}
+}
+
+func TestParseNesting(t *testing.T) {
+	tests := []struct {
+		source string
+		want   string
+	}{
+		{"- a\n  - b\n    - c\n- d\n", "bulletListItem(bulletListItem(bulletListItem)) bulletListItem"},
+		{"1. a\n\n   para\n\n   ```\n   code\n   ```\n", "numberedListItem(paragraph codeBlock)"},
+		{"- [x] a\n  - [ ] b\n", "checkListItem(checkListItem)"},
+		{"> a\n>\n> > b\n", "quote(quote)"},
+		{"- \n  # heading\n", "bulletListItem(heading)"},
+		{"| a |\n| - |\n| 1 |\n\n![i](x.png)\n", "table image"},
+	}
+
+	for _, tt := range tests {
+		if got := shape(parse(t, tt.source)); got != tt.want {
+			t.Errorf("Parse(%q) = %s, want %s", tt.source, got, tt.want)
+		}
+	}
+}
+
+func TestParseProps(t *testing.T) {
+	blocks := parse(t, "3. three\n4. four\n\n- [x] done\n\n```js\nx\n```\n\n## Title\n")
+	props := []struct {
+		key  string
+		want any
+	}{
+		{"start", 3},
+		{"start", nil},
+		{"checked", true},
+		{"language", "js"},
+		{"level", 2},
+	}
+	if len(blocks) != len(props) {
+		t.Fatalf("Parse = %s, want %d blocks", shape(blocks), len(props))
+	}
+	for i, prop := range props {
+		if got := blocks[i].Props[prop.key]; got != prop.want {
+			t.Errorf("block %d (%s): %s = %v, want %v", i, blocks[i].Type, prop.key, got, prop.want)
+		}
+	}
+
+	table := parse(t, "| Name | Age |\n| :-- | --: |\n| Ann | 42 |\n")[0].TableContent
+	if table == nil || table.HeaderRows != 1 || len(table.Rows) != 2 {
+		t.Fatalf("TableContent = %+v, want a header row and a row", table)
+	}
+	if got := table.Rows[1].Cells[1].Props["textAlignment"]; got != "right" {
+		t.Errorf("textAlignment = %v, want right", got)
+	}
+}
+
+func TestParseInline(t *testing.T) {
+	text := func(text string, styles ...string) dto.InlineContent {
+		inline := dto.InlineContent{Type: "text", Text: text, Styles: map[string]bool{}}
+		for _, style := range styles {
+			inline.Styles[style] = true
+		}
+		return inline
+	}
+	link := func(inline dto.InlineContent, href string) dto.InlineContent {
+		inline.Type = "link"
+		inline.Href = href
+		return inline
+	}
+
+	tests := []struct {
+		source string
+		want   []dto.InlineContent
+	}{
+		{"plain", []dto.InlineContent{text("plain")}},
+		{"a *b* c", []dto.InlineContent{text("a "), text("b", "italic"), text(" c")}},
+		{"**a _b_**", []dto.InlineContent{text("a ", "bold"), text("b", "bold", "italic")}},
+		{"~~a~~<u>b</u>", []dto.InlineContent{text("a", "strike"), text("b", "underline")}},
+		{"`a *b*`", []dto.InlineContent{text("a *b*", "code")}},
+		{"``a ` b``", []dto.InlineContent{text("a ` b", "code")}},
+		{"[a **b**](https://x.y)", []dto.InlineContent{link(text("a "), "https://x.y"), link(text("b", "bold"), "https://x.y")}},
+		{"*unclosed and \\*escaped\\*", []dto.InlineContent{text("*unclosed and *escaped*")}},
+		{"[not a link] (x)", []dto.InlineContent{text("[not a link] (x)")}},
+		{"a\nb  \nc", []dto.InlineContent{text("a b\nc")}},
+	}
+
+	for _, tt := range tests {
+		if got := parseInline(tt.source); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("parseInline(%q) =\n%+v\nwant\n%+v", tt.source, got, tt.want)
+		}
+	}
+}
+
+func TestParseSizeLimit(t *testing.T) {
+	if _, err := Parse(strings.Repeat("a", MaxSourceSize)); err != nil {
+		t.Errorf("Parse of %d bytes: %v", MaxSourceSize, err)
+	}
+	if _, err := Parse(strings.Repeat("a", MaxSourceSize+1)); err == nil {
+		t.Errorf("Parse of %d bytes: no error", MaxSourceSize+1)
+	}
+}
+
+func TestParseNestingDepth(t *testing.T) {
+	depth := func(blocks []dto.Block) int {
+		n := 0
+		for len(blocks) > 0 {
+			n++
+			blocks = blocks[0].Children
+		}
+		return n
+	}
+
+	for _, marker := range []string{"> ", "- "} {
+		blocks := parse(t, strings.Repeat(marker, 1000)+"text")
+		if got := depth(blocks); got != maxNestingDepth {
+			t.Errorf("%q x 1000: depth = %d, want %d", marker, got, maxNestingDepth)
+		}
+	}
+}
+
+// The inputs which made the parser read the same text again and again must
+// be parsed in linear time
+func TestParsePathologicalInputs(t *testing.T) {
+	const n = 50000
+	sources := map[string]string{
+		"brackets":        strings.Repeat("[", n) + strings.Repeat("]", n),
+		"open brackets":   strings.Repeat("[a](", n),
+		"emphasis":        strings.Repeat("*a ", n),
+		"underscores":     strings.Repeat("_a", n),
+		"backticks":       strings.Repeat("`a``", n),
+		"nested quotes":   strings.Repeat(">", n) + " a",
+		"long list items": strings.Repeat("- "+strings.Repeat("a", 1000)+"\n", 1000),
+		"thematic":        strings.Repeat("- ", n) + "a",
+		"table cells":     strings.Repeat("|", n) + "\n" + strings.Repeat("|-", n) + "\n",
+	}
+
+	for name, source := range sources {
+		start := time.Now()
+		parse(t, source)
+		if elapsed := time.Since(start); elapsed > 2*time.Second {
+			t.Errorf("%s: Parse took %v", name, elapsed)
+		}
+	}
+}
@@ -0,0 +1,426 @@
+package markdown
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/gofiber/fiber/v2/utils"
+	"github.com/labbs/nexo/application/document/dto"
+	"github.com/labbs/nexo/infrastructure/helpers/apperrors"
+)
+
+const (
+	// MaxSourceSize is the size of the largest markdown Parse accepts
+	MaxSourceSize = 2 << 20
+	// maxNestingDepth is the deepest nesting of quotes and list items: the
+	// quote and list markers nested deeper are kept as text
+	maxNestingDepth = 32
+)
+
+// quoteLine and listItemLine do not match the content of the quote or the
+// list item, the rest of the line, so that long lines are not read again at
+// every nesting level.
+var (
+	fenceLine      = regexp.MustCompile("^( {0,3})(`{3,}|~{3,})[ \t]*([^ \t`]*)")
+	headingLine    = regexp.MustCompile(`^ {0,3}(#{1,6})(?:[ \t]+|$)(.*?)(?:[ \t]+#+)?[ \t]*$`)
+	thematicLine   = regexp.MustCompile(`^ {0,3}(?:(?:\*[ \t]*){3,}|(?:-[ \t]*){3,}|(?:_[ \t]*){3,})$`)
+	quoteLine      = regexp.MustCompile(`^ {0,3}> ?`)
+	listItemLine   = regexp.MustCompile(`^( {0,3})([-+*]|\d{1,9}[.)])([ \t]+|$)`)
+	taskItem       = regexp.MustCompile(`^\[([ xX])\](?:[ \t]+|$)(.*)$`)
+	setextLine     = regexp.MustCompile(`^ {0,3}(=+|-+)[ \t]*$`)
+	tableDelimLine = regexp.MustCompile(`^ {0,3}\|?[ \t]*:?-+:?[ \t]*(?:\|[ \t]*:?-+:?[ \t]*)*\|?[ \t]*$`)
+	imageLine      = regexp.MustCompile(`^!\[((?:[^\]\\]|\\.)*)\]\(\s*<?([^)\s>]+)>?(?:\s+"[^"]*")?\s*\)$`)
+)
+
+// Parse converts GitHub Flavored Markdown to blocks: headings, paragraphs,
+// bullet, numbered and task lists, code blocks, quotes, tables and images.
+// The blocks nested in a list item become the children of the item, the
+// thematic breaks and raw HTML blocks have no equivalent and are dropped or
+// kept as text.
+func Parse(source string) ([]dto.Block, error) {
+	if len(source) > MaxSourceSize {
+		return nil, fmt.Errorf("%w: markdown is larger than %d bytes", apperrors.ErrInvalidInput, MaxSourceSize)
+	}
+	source = strings.ReplaceAll(source, "\r\n", "\n")
+	source = strings.ReplaceAll(source, "\r", "\n")
+	lines := strings.Split(source, "\n")
+	for i, line := range lines {
+		lines[i] = expandTabs(line)
+	}
+	return parseBlocks(lines, 0), nil
+}
+
+// parseBlocks parses the lines of a nesting level. The quotes and list items
+// rewrite their lines in place to the lines of their content, which are
+// parsed as the next level: lines are never copied.
+func parseBlocks(lines []string, depth int) []dto.Block {
+	blocks := []dto.Block{}
+	for i := 0; i < len(lines); {
+		line := lines[i]
+
+		switch {
+		case isBlank(line):
+			i++
+
+		case fenceLine.MatchString(line):
+			var block dto.Block
+			block, i = parseFencedCode(lines, i)
+			blocks = append(blocks, block)
+
+		case headingLine.MatchString(line):
+			m := headingLine.FindStringSubmatch(line)
+			block := newBlock(dto.BlockTypeHeading, parseInline(m[2]))
+			block.Props["level"] = min(len(m[1]), 3)
+			blocks = append(blocks, block)
+			i++
+
+		case isThematicBreak(line):
+			i++
+
+		case depth < maxNestingDepth && quoteLine.MatchString(line):
+			var block dto.Block
+			block, i = parseQuote(lines, i, depth)
+			blocks = append(blocks, block)
+
+		case depth < maxNestingDepth && listItemLine.MatchString(line):
+			var block dto.Block
+			block, i = parseListItem(lines, i, depth)
+			// Only the first item of a numbered list has its start
+			if len(blocks) > 0 && blocks[len(blocks)-1].Type == block.Type {
+				delete(block.Props, "start")
+			}
+			blocks = append(blocks, block)
+
+		case indentOf(line) >= 4:
+			var block dto.Block
+			block, i = parseIndentedCode(lines, i)
+			blocks = append(blocks, block)
+
+		case isTableStart(lines, i):
+			var block dto.Block
+			block, i = parseTable(lines, i)
+			blocks = append(blocks, block)
+
+		default:
+			var block dto.Block
+			block, i = parseParagraph(lines, i)
+			blocks = append(blocks, block)
+		}
+	}
+	return blocks
+}
+
+func parseFencedCode(lines []string, start int) (dto.Block, int) {
+	m := fenceLine.FindStringSubmatch(lines[start])
+	indent, fence, language := len(m[1]), m[2], m[3]
+
+	var code []string
+	i := start + 1
+	for ; i < len(lines); i++ {
+		line := lines[i]
+		trimmed := strings.TrimSpace(line)
+		if indentOf(line) <= 3 && strings.HasPrefix(trimmed, fence) && strings.Trim(trimmed, fence[:1]) == "" {
+			i++
+			break
+		}
+		code = append(code, trimIndent(line, indent))
+	}
+
+	block := newBlock(dto.BlockTypeCodeBlock, textContent(strings.Join(code, "\n")))
+	block.Props = map[string]any{"language": language}
+	return block, i
+}
+
+func parseIndentedCode(lines []string, start int) (dto.Block, int) {
+	var code []string
+	i := start
+	for ; i < len(lines) && (isBlank(lines[i]) || indentOf(lines[i]) >= 4); i++ {
+		code = append(code, trimIndent(lines[i], 4))
+	}
+	for len(code) > 0 && isBlank(code[len(code)-1]) {
+		code = code[:len(code)-1]
+	}
+
+	block := newBlock(dto.BlockTypeCodeBlock, textContent(strings.Join(code, "\n")))
+	block.Props = map[string]any{"language": ""}
+	return block, i
+}
+
+// parseQuote reads the lines of a quote and the lines continuing its last
+// paragraph. The first paragraph of the quote is its text, the other blocks
+// are its children.
+func parseQuote(lines []string, start, depth int) (dto.Block, int) {
+	i := start
+	for ; i < len(lines); i++ {
+		if m := quoteLine.FindString(lines[i]); m != "" {
+			lines[i] = lines[i][len(m):]
+			continue
+		}
+		if isBlank(lines[i]) || isBlank(lines[i-1]) || startsBlock(lines, i) {
+			break
+		}
+	}
+
+	return nestedBlock(dto.BlockTypeQuote, parseBlocks(lines[start:i], depth+1)), i
+}
+
+// parseListItem reads the lines of a list item: the lines indented to its
+// content, and the lines continuing its first paragraph.
+func parseListItem(lines []string, start, depth int) (dto.Block, int) {
+	m := listItemLine.FindStringSubmatch(lines[start])
+	marker, spaces, first := m[2], len(m[3]), lines[start][len(m[0]):]
+	contentIndent := len(m[1]) + len(marker) + spaces
+	if spaces > 4 || first == "" {
+		contentIndent = len(m[1]) + len(marker) + 1
+	}
+
+	blockType := dto.BlockTypeBulletListItem
+	number := 0
+	if n, err := strconv.Atoi(marker[:len(marker)-1]); err == nil {
+		blockType = dto.BlockTypeNumberedListItem
+		number = n
+	}
+	checked := false
+	if task := taskItem.FindStringSubmatch(first); task != nil && blockType == dto.BlockTypeBulletListItem {
+		blockType = dto.BlockTypeCheckListItem
+		checked = task[1] != " "
+		first = task[2]
+	}
+
+	lines[start] = first
+	i := start + 1
+	for ; i < len(lines); i++ {
+		line := lines[i]
+		switch {
+		case isBlank(line):
+			lines[i] = ""
+			continue
+		case indentOf(line) >= contentIndent:
+			lines[i] = line[contentIndent:]
+			continue
+		}
+		if isBlank(lines[i-1]) || startsBlock(lines, i) {
+			break
+		}
+		lines[i] = strings.TrimLeft(line, " ")
+	}
+
+	inner := parseBlocks(lines[start:i], depth+1)
+	var block dto.Block
+	if isBlank(first) {
+		block = newBlock(blockType, []dto.InlineContent{})
+		block.Children = inner
+	} else {
+		block = nestedBlock(blockType, inner)
+	}
+	switch {
+	case blockType == dto.BlockTypeCheckListItem:
+		block.Props["checked"] = checked
+	case blockType == dto.BlockTypeNumberedListItem && number != 1:
+		block.Props["start"] = number
+	}
+	return block, i
+}
+
+func isTableStart(lines []string, i int) bool {
+	if i+1 >= len(lines) || !strings.Contains(lines[i], "|") || !tableDelimLine.MatchString(lines[i+1]) {
+		return false
+	}
+	return len(splitRow(lines[i])) == len(splitRow(lines[i+1]))
+}
+
+// parseTable reads a table, its first row is the header
+func parseTable(lines []string, start int) (dto.Block, int) {
+	header := splitRow(lines[start])
+	alignments := make([]string, len(header))
+	for c, delimiter := range splitRow(lines[start+1]) {
+		switch {
+		case strings.HasPrefix(delimiter, ":") && strings.HasSuffix(delimiter, ":"):
+			alignments[c] = "center"
+		case strings.HasSuffix(delimiter, ":"):
+			alignments[c] = "right"
+		default:
+			alignments[c] = "left"
+		}
+	}
+
+	row := func(values []string) dto.TableRow {
+		cells := make([]dto.TableCell, len(header))
+		for c := range cells {
+			cells[c] = dto.TableCell{
+				Type: "tableCell",
+				Props: map[string]any{
+					"backgroundColor": "default",
+					"textColor":       "default",
+					"textAlignment":   alignments[c],
+					"colspan":         1,
+					"rowspan":         1,
+				},
+				Content: []dto.InlineContent{},
+			}
+			if c < len(values) {
+				cells[c].Content = parseInline(strings.ReplaceAll(values[c], "<br>", "\\\n"))
+			}
+		}
+		return dto.TableRow{Cells: cells}
+	}
+
+	content := &dto.TableContent{Type: "tableContent", HeaderRows: 1, Rows: []dto.TableRow{row(header)}}
+	i := start + 2
+	for ; i < len(lines) && !isBlank(lines[i]) && !startsBlock(lines, i); i++ {
+		content.Rows = append(content.Rows, row(splitRow(lines[i])))
+	}
+
+	block := newBlock(dto.BlockTypeTable, nil)
+	block.Props = map[string]any{"textColor": "default"}
+	block.TableContent = content
+	return block, i
+}
+
+// splitRow splits a table row on the pipes which are not escaped
+func splitRow(line string) []string {
+	line = strings.TrimSpace(line)
+	line = strings.TrimPrefix(line, "|")
+	if strings.HasSuffix(line, "|") && !strings.HasSuffix(line, "\\|") {
+		line = line[:len(line)-1]
+	}
+
+	var cells []string
+	var cell strings.Builder
+	for i := 0; i < len(line); i++ {
+		switch {
+		case line[i] == '\\' && i+1 < len(line) && line[i+1] == '|':
+			cell.WriteByte('|')
+			i++
+		case line[i] == '|':
+			cells = append(cells, strings.TrimSpace(cell.String()))
+			cell.Reset()
+		default:
+			cell.WriteByte(line[i])
+		}
+	}
+	return append(cells, strings.TrimSpace(cell.String()))
+}
+
+// parseParagraph reads the lines of a paragraph, which is a heading when it
+// is underlined (setext heading) and an image when it is a single image.
+func parseParagraph(lines []string, start int) (dto.Block, int) {
+	text := []string{strings.TrimSpace(lines[start])}
+	i := start + 1
+	for ; i < len(lines); i++ {
+		line := lines[i]
+		if m := setextLine.FindStringSubmatch(line); m != nil {
+			block := newBlock(dto.BlockTypeHeading, parseInline(strings.Join(text, "\n")))
+			block.Props["level"] = 1
+			if m[1][0] == '-' {
+				block.Props["level"] = 2
+			}
+			return block, i + 1
+		}
+		if isBlank(line) || startsBlock(lines, i) {
+			break
+		}
+		text = append(text, strings.TrimLeft(line, " "))
+	}
+
+	source := strings.Join(text, "\n")
+	if m := imageLine.FindStringSubmatch(source); m != nil {
+		block := newBlock(dto.BlockTypeImage, nil)
+		block.Props = map[string]any{
+			"url":           m[2],
+			"caption":       unescape(m[1]),
+			"name":          "",
+			"textAlignment": "left",
+			"showPreview":   true,
+		}
+		return block, i
+	}
+	return newBlock(dto.BlockTypeParagraph, parseInline(source)), i
+}
+
+// startsBlock reports whether a line interrupts a paragraph
+func startsBlock(lines []string, i int) bool {
+	line := lines[i]
+	if indentOf(line) >= 4 {
+		return false
+	}
+	if m := listItemLine.FindString(line); m != "" && len(m) < len(line) {
+		return true
+	}
+	return fenceLine.MatchString(line) || headingLine.MatchString(line) || isThematicBreak(line) ||
+		quoteLine.MatchString(line) || isTableStart(lines, i)
+}
+
+// nestedBlock creates a block with the text of the first paragraph of its
+// content and the other blocks as children.
+func nestedBlock(blockType string, content []dto.Block) dto.Block {
+	if len(content) > 0 && content[0].Type == dto.BlockTypeParagraph {
+		block := newBlock(blockType, content[0].Content)
+		block.Children = content[1:]
+		return block
+	}
+	block := newBlock(blockType, []dto.InlineContent{})
+	block.Children = content
+	return block
+}
+
+func newBlock(blockType string, content []dto.InlineContent) dto.Block {
+	if content == nil {
+		content = []dto.InlineContent{}
+	}
+	return dto.Block{
+		ID:   utils.UUIDv4(),
+		Type: blockType,
+		Props: map[string]any{
+			"textColor":       "default",
+			"backgroundColor": "default",
+			"textAlignment":   "left",
+		},
+		Content:  content,
+		Children: []dto.Block{},
+	}
+}
+
+func textContent(text string) []dto.InlineContent {
+	if text == "" {
+		return []dto.InlineContent{}
+	}
+	return []dto.InlineContent{{Type: "text", Text: text, Styles: map[string]bool{}}}
+}
+
+// isThematicBreak matches the lines made of the characters of a thematic
+// break before the regexp, which is slow on long lines.
+func isThematicBreak(line string) bool {
+	return strings.TrimLeft(line, " \t*-_") == "" && thematicLine.MatchString(line)
+}
+
+func isBlank(line string) bool {
+	return strings.TrimSpace(line) == ""
+}
+
+func indentOf(line string) int {
+	return len(line) - len(strings.TrimLeft(line, " "))
+}
+
+func trimIndent(line string, indent int) string {
+	return line[min(indent, indentOf(line)):]
+}
+
+// expandTabs replaces the tabs of the indentation by spaces, to the next
+// multiple of 4 columns.
+func expandTabs(line string) string {
+	var b strings.Builder
+	for i := 0; i < len(line); i++ {
+		switch line[i] {
+		case '\t':
+			b.WriteString(strings.Repeat(" ", 4-b.Len()%4))
+		case ' ':
+			b.WriteByte(' ')
+		default:
+			return b.String() + line[i:]
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,386 @@
+// Package markdown converts the BlockNote blocks of a document from and to
+// GitHub Flavored Markdown.
+package markdown
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/labbs/nexo/application/document/dto"
+)
+
+// Render converts blocks to GitHub Flavored Markdown. The children of list
+// items are indented under their item, the children of the other blocks
+// follow them. Underline, which has no markdown syntax, is written as <u>.
+func Render(blocks []dto.Block) string {
+	lines := renderBlocks(blocks)
+	if len(lines) == 0 {
+		return ""
+	}
+	return strings.Join(lines, "\n") + "\n"
+}
+
+func renderBlocks(blocks []dto.Block) []string {
+	var lines []string
+	var previous string
+	number := 0
+	for _, block := range blocks {
+		if block.Type != dto.BlockTypeNumberedListItem {
+			number = 0
+		} else if number == 0 {
+			number = intProp(block.Props, "start", 1)
+		} else {
+			number++
+		}
+
+		blockLines := renderBlock(block, number)
+		if len(blockLines) == 0 {
+			continue
+		}
+		// Items of the same list are kept together, any other block starts
+		// after an empty line.
+		if len(lines) > 0 && !(isListItem(block.Type) && block.Type == previous) {
+			lines = append(lines, "")
+		}
+		lines = append(lines, blockLines...)
+		previous = block.Type
+	}
+	return lines
+}
+
+func renderBlock(block dto.Block, number int) []string {
+	text := renderInline(block.Content)
+
+	switch block.Type {
+	case dto.BlockTypeHeading:
+		level := min(max(intProp(block.Props, "level", 1), 1), 6)
+		lines := []string{strings.Repeat("#", level) + " " + strings.ReplaceAll(text, "\\\n", " ")}
+		return appendChildren(lines, block.Children)
+
+	case dto.BlockTypeBulletListItem:
+		return listItem("- ", text, block.Children)
+
+	case dto.BlockTypeNumberedListItem:
+		return listItem(fmt.Sprintf("%d. ", number), text, block.Children)
+
+	case dto.BlockTypeCheckListItem:
+		marker := "- [ ] "
+		if checked, _ := block.Props["checked"].(bool); checked {
+			marker = "- [x] "
+		}
+		return listItem(marker, text, block.Children)
+
+	case dto.BlockTypeCodeBlock:
+		lines := codeBlock(plainText(block.Content), stringProp(block.Props, "language"))
+		return appendChildren(lines, block.Children)
+
+	case dto.BlockTypeQuote:
+		var quoted []string
+		if text != "" {
+			quoted = escapeLineStarts(strings.Split(text, "\n"))
+		}
+		quoted = appendChildren(quoted, block.Children)
+		lines := make([]string, len(quoted))
+		for i, line := range quoted {
+			lines[i] = strings.TrimRight("> "+line, " ")
+		}
+		return lines
+
+	case dto.BlockTypeTable:
+		return appendChildren(table(block.TableContent), block.Children)
+
+	case dto.BlockTypeImage:
+		url := stringProp(block.Props, "url")
+		if url == "" {
+			return renderBlocks(block.Children)
+		}
+		alt := firstNonEmpty(stringProp(block.Props, "caption"), stringProp(block.Props, "name"))
+		return appendChildren([]string{"![" + escapeText(alt) + "](" + destination(url) + ")"}, block.Children)
+
+	case dto.BlockTypeVideo, dto.BlockTypeAudio, dto.BlockTypeFile:
+		url := stringProp(block.Props, "url")
+		if url == "" {
+			return renderBlocks(block.Children)
+		}
+		name := firstNonEmpty(stringProp(block.Props, "name"), stringProp(block.Props, "caption"), url)
+		return appendChildren([]string{"[" + escapeText(name) + "](" + destination(url) + ")"}, block.Children)
+	}
+
+	// Paragraphs, and the blocks without a markdown equivalent (columns,
+	// custom blocks, ...) are written as their text.
+	var lines []string
+	if text != "" {
+		lines = escapeLineStarts(strings.Split(text, "\n"))
+	}
+	return appendChildren(lines, block.Children)
+}
+
+func isListItem(blockType string) bool {
+	switch blockType {
+	case dto.BlockTypeBulletListItem, dto.BlockTypeNumberedListItem, dto.BlockTypeCheckListItem:
+		return true
+	}
+	return false
+}
+
+// listItem writes an item with its children indented to its content
+func listItem(marker, text string, children []dto.Block) []string {
+	indent := strings.Repeat(" ", len(marker))
+	if strings.HasPrefix(marker, "- [") {
+		indent = "  "
+	}
+
+	textLines := escapeLineStarts(strings.Split(text, "\n"))
+	lines := []string{strings.TrimRight(marker+textLines[0], " ")}
+	for _, line := range textLines[1:] {
+		lines = append(lines, indent+line)
+	}
+
+	childLines := renderBlocks(children)
+	// A paragraph right after the item would continue its text
+	if len(childLines) > 0 && !isListItem(children[0].Type) {
+		lines = append(lines, "")
+	}
+	for _, line := range childLines {
+		if line == "" {
+			lines = append(lines, "")
+			continue
+		}
+		lines = append(lines, indent+line)
+	}
+	return lines
+}
+
+func appendChildren(lines []string, children []dto.Block) []string {
+	childLines := renderBlocks(children)
+	if len(lines) > 0 && len(childLines) > 0 {
+		lines = append(lines, "")
+	}
+	return append(lines, childLines...)
+}
+
+func codeBlock(code, language string) []string {
+	fence := "```"
+	for strings.Contains(code, fence) {
+		fence += "`"
+	}
+	lines := []string{fence + language}
+	if code != "" {
+		lines = append(lines, strings.Split(code, "\n")...)
+	}
+	return append(lines, fence)
+}
+
+func table(content *dto.TableContent) []string {
+	if content == nil || len(content.Rows) == 0 {
+		return nil
+	}
+	columns := 0
+	for _, row := range content.Rows {
+		columns = max(columns, len(row.Cells))
+	}
+	if columns == 0 {
+		return nil
+	}
+
+	// GitHub Flavored Markdown tables always have a single header row
+	cells := func(row dto.TableRow) string {
+		values := make([]string, columns)
+		for i := range values {
+			if i < len(row.Cells) {
+				text := renderInline(row.Cells[i].Content)
+				text = strings.ReplaceAll(text, "\\\n", "<br>")
+				values[i] = strings.ReplaceAll(text, "|", "\\|")
+			}
+		}
+		return "| " + strings.Join(values, " | ") + " |"
+	}
+
+	lines := []string{cells(content.Rows[0])}
+	delimiters := make([]string, columns)
+	for i := range delimiters {
+		delimiters[i] = "---"
+		if i < len(content.Rows[0].Cells) {
+			switch stringProp(content.Rows[0].Cells[i].Props, "textAlignment") {
+			case "center":
+				delimiters[i] = ":---:"
+			case "right":
+				delimiters[i] = "---:"
+			}
+		}
+	}
+	lines = append(lines, "| "+strings.Join(delimiters, " | ")+" |")
+	for _, row := range content.Rows[1:] {
+		lines = append(lines, cells(row))
+	}
+	return lines
+}
+
+// renderInline converts inline content to markdown, the consecutive runs of
+// a link are written in the same link.
+func renderInline(content []dto.InlineContent) string {
+	var b strings.Builder
+	for i := 0; i < len(content); {
+		href := linkHref(content[i])
+		j := i + 1
+		for j < len(content) && linkHref(content[j]) == href {
+			j++
+		}
+
+		var runs strings.Builder
+		for _, inline := range content[i:j] {
+			runs.WriteString(renderRun(inline))
+		}
+		switch {
+		case href != "" && j == i+1 && content[i].Text == href && len(content[i].Styles) == 0 && autolink.MatchString("<"+href+">"):
+			b.WriteString("<" + href + ">")
+		case href != "":
+			b.WriteString("[" + runs.String() + "](" + destination(href) + ")")
+		default:
+			b.WriteString(runs.String())
+		}
+		i = j
+	}
+	return b.String()
+}
+
+func linkHref(inline dto.InlineContent) string {
+	if inline.Type == "link" {
+		return inline.Href
+	}
+	return ""
+}
+
+// renderRun writes a text run with its styles, the spaces around the text
+// are kept out of the delimiters which would not apply otherwise.
+func renderRun(inline dto.InlineContent) string {
+	text := inline.Text
+	if text == "" {
+		return ""
+	}
+	if inline.Styles["code"] {
+		text = codeSpan(strings.ReplaceAll(text, "\n", " "))
+	} else {
+		text = strings.ReplaceAll(escapeText(text), "\n", "\\\n")
+	}
+
+	trimmed := strings.TrimSpace(text)
+	if trimmed == "" {
+		return text
+	}
+	leading := text[:strings.Index(text, trimmed)]
+	trailing := text[len(leading)+len(trimmed):]
+
+	for _, style := range []struct{ name, open, close string }{
+		{"strike", "~~", "~~"},
+		{"italic", "*", "*"},
+		{"bold", "**", "**"},
+		{"underline", "<u>", "</u>"},
+	} {
+		if inline.Styles[style.name] {
+			trimmed = style.open + trimmed + style.close
+		}
+	}
+	return leading + trimmed + trailing
+}
+
+func codeSpan(text string) string {
+	fence := "`"
+	for strings.Contains(text, fence) {
+		fence += "`"
+	}
+	if strings.HasPrefix(text, "`") || strings.HasSuffix(text, "`") {
+		text = " " + text + " "
+	}
+	return fence + text + fence
+}
+
+// escapeText escapes the characters which would be read as markdown. An
+// underscore inside a word can not start an emphasis and is kept as is.
+func escapeText(text string) string {
+	var b strings.Builder
+	for i := 0; i < len(text); i++ {
+		c := text[i]
+		switch c {
+		case '\\', '`', '*', '[', ']', '<', '~':
+			b.WriteByte('\\')
+		case '_':
+			if i == 0 || i == len(text)-1 || !isAlphanumeric(text[i-1]) || !isAlphanumeric(text[i+1]) {
+				b.WriteByte('\\')
+			}
+		}
+		b.WriteByte(c)
+	}
+	return b.String()
+}
+
+var orderedMarker = regexp.MustCompile(`^(\d{1,9})([.)])(\s|$)`)
+
+// escapeLineStarts escapes the start of the lines of a paragraph which
+// would be read as another block.
+func escapeLineStarts(lines []string) []string {
+	for i, line := range lines {
+		// Leading spaces would start an indented code block
+		line = strings.TrimLeft(line, " \t")
+		switch {
+		case line == "":
+		case strings.ContainsRune("#>+-=|", rune(line[0])):
+			line = "\\" + line
+		default:
+			if m := orderedMarker.FindStringSubmatchIndex(line); m != nil {
+				line = line[:m[3]] + "\\" + line[m[4]:]
+			}
+		}
+		lines[i] = line
+	}
+	return lines
+}
+
+func destination(url string) string {
+	if strings.ContainsAny(url, " ()<>") {
+		return "<" + strings.NewReplacer("<", "%3C", ">", "%3E").Replace(url) + ">"
+	}
+	return url
+}
+
+func plainText(content []dto.InlineContent) string {
+	var b strings.Builder
+	for _, inline := range content {
+		b.WriteString(inline.Text)
+	}
+	return b.String()
+}
+
+func stringProp(props map[string]any, key string) string {
+	value, _ := props[key].(string)
+	return value
+}
+
+func intProp(props map[string]any, key string, fallback int) int {
+	switch value := props[key].(type) {
+	case float64:
+		return int(value)
+	case int:
+		return value
+	case string:
+		var n int
+		if _, err := fmt.Sscanf(value, "%d", &n); err == nil {
+			return n
+		}
+	}
+	return fallback
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, value := range values {
+		if value != "" {
+			return value
+		}
+	}
+	return ""
+}
+
+func isAlphanumeric(c byte) bool {
+	return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c >= 0x80
+}
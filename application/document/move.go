@@ -4,6 +4,8 @@ import (
 	"fmt"
 
 	"github.com/labbs/nexo/application/document/dto"
+	eventDto "github.com/labbs/nexo/application/event/dto"
+	"github.com/labbs/nexo/domain"
 )
 
 func (a *DocumentApplication) MoveDocument(input dto.MoveDocumentInput) (*dto.MoveDocumentOutput, error) {
@@ -21,5 +23,9 @@ func (a *DocumentApplication) MoveDocument(input dto.MoveDocumentInput) (*dto.Mo
 		logger.Error().Err(err).Msg("failed to move document")
 		return nil, err
 	}
+
+	a.recheckCollaborationAccess(moved.Id)
+	a.publishDocumentEvent(domain.WebhookEventDocumentMoved, input.UserId, input.ActionChain, eventDto.DocumentSnapshot(doc), eventDto.DocumentSnapshot(moved))
+
 	return &dto.MoveDocumentOutput{Document: moved}, nil
 }
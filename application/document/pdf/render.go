@@ -0,0 +1,409 @@
+// Package pdf lays out the BlockNote blocks of documents on the pages of a
+// PDF document.
+package pdf
+
+import (
+	"bytes"
+	"fmt"
+	"math"
+	"strings"
+
+	"github.com/labbs/nexo/application/document/dto"
+	pdfwriter "github.com/labbs/nexo/infrastructure/pdf"
+)
+
+const (
+	margin       = 56.0
+	contentWidth = pdfwriter.PageWidth - 2*margin
+	pageBottom   = pdfwriter.PageHeight - margin
+	bodySize     = 10.5
+	codeSize     = 9.0
+	lineSpacing  = 1.45
+	indentWidth  = 18.0
+)
+
+var (
+	textColor   = pdfwriter.Color{R: 0.12, G: 0.14, B: 0.16}
+	mutedColor  = pdfwriter.Color{R: 0.35, G: 0.39, B: 0.43}
+	linkColor   = pdfwriter.Color{R: 0.04, G: 0.43, B: 0.6}
+	borderColor = pdfwriter.Color{R: 0.82, G: 0.84, B: 0.87}
+	shadeColor  = pdfwriter.Color{R: 0.96, G: 0.97, B: 0.98}
+)
+
+var headingSizes = map[int]float64{1: 20, 2: 16, 3: 13.5, 4: 12, 5: 11, 6: 10.5}
+
+// Document is a document to render, Depth is its level in the exported
+// tree for the table of contents.
+type Document struct {
+	Title  string
+	Depth  int
+	Blocks []dto.Block
+}
+
+// Renderer renders documents as PDF. Images returns the content of the
+// image of an image block, or nil to write a link to the image instead.
+type Renderer struct {
+	Images func(url string) []byte
+}
+
+// Render lays out the documents, each on new pages. With several documents
+// a table of contents linking to the documents is added at the start.
+func (r Renderer) Render(title string, documents []Document) ([]byte, error) {
+	l := &layout{
+		doc:    pdfwriter.New(title),
+		images: r.Images,
+		cache:  make(map[string]*pdfwriter.Image),
+	}
+
+	starts := make([]*pdfwriter.Page, len(documents))
+	for i, document := range documents {
+		l.newPage()
+		starts[i] = l.page
+		l.title(document.Title)
+		l.blocks(document.Blocks, margin)
+	}
+	if len(documents) > 1 {
+		l.toc(documents, starts)
+	}
+	l.pageNumbers()
+
+	var out bytes.Buffer
+	if err := l.doc.Write(&out); err != nil {
+		return nil, err
+	}
+	return out.Bytes(), nil
+}
+
+type layout struct {
+	doc    *pdfwriter.Document
+	page   *pdfwriter.Page
+	y      float64 // top of the next line
+	images func(url string) []byte
+	cache  map[string]*pdfwriter.Image
+}
+
+func (l *layout) newPage() {
+	l.page = l.doc.AddPage()
+	l.y = margin
+}
+
+// ensure starts a new page when the height does not fit on the page
+func (l *layout) ensure(height float64) {
+	if l.y+height > pageBottom && l.y > margin {
+		l.newPage()
+	}
+}
+
+func (l *layout) title(title string) {
+	l.text([]dto.InlineContent{{Type: "text", Text: title}}, textOptions{x: margin, width: contentWidth, size: 24, bold: true})
+	l.page.Line(margin, l.y+4, margin+contentWidth, l.y+4, 0.5, borderColor)
+	l.y += 18
+}
+
+func (l *layout) blocks(blocks []dto.Block, x float64) {
+	number := 0
+	for _, block := range blocks {
+		if block.Type != dto.BlockTypeNumberedListItem {
+			number = 0
+		} else if number == 0 {
+			number = intProp(block.Props, "start", 1)
+		} else {
+			number++
+		}
+		l.block(block, x, number)
+	}
+}
+
+func (l *layout) block(block dto.Block, x float64, number int) {
+	width := margin + contentWidth - x
+	options := textOptions{x: x, width: width, size: bodySize, alignment: stringProp(block.Props, "textAlignment")}
+
+	switch block.Type {
+	case dto.BlockTypeHeading:
+		level := min(max(intProp(block.Props, "level", 1), 1), 6)
+		options.size, options.bold = headingSizes[level], true
+		l.y += options.size * 0.6
+		// Keep the heading with the first line of the next block
+		l.ensure(options.size*lineSpacing + bodySize*lineSpacing*2)
+		l.text(block.Content, options)
+		l.y += 4
+
+	case dto.BlockTypeBulletListItem, dto.BlockTypeNumberedListItem, dto.BlockTypeCheckListItem:
+		l.ensure(bodySize * lineSpacing)
+		baseline := l.y + bodySize*1.05
+		switch block.Type {
+		case dto.BlockTypeBulletListItem:
+			l.page.Text(x+4, baseline, pdfwriter.Helvetica, bodySize, textColor, "•")
+		case dto.BlockTypeNumberedListItem:
+			l.page.Text(x, baseline, pdfwriter.Helvetica, bodySize, textColor, fmt.Sprintf("%d.", number))
+		default:
+			size := bodySize * 0.8
+			l.page.StrokeRect(x+1, baseline-size, size, size, 0.8, mutedColor)
+			if checked, _ := block.Props["checked"].(bool); checked {
+				l.page.Line(x+2.5, baseline-size/2, x+1+size*0.4, baseline-1.5, 1.2, textColor)
+				l.page.Line(x+1+size*0.4, baseline-1.5, x+size, baseline-size+1, 1.2, textColor)
+				options.color = &mutedColor
+			}
+		}
+		options.x, options.width = x+indentWidth, width-indentWidth
+		l.text(block.Content, options)
+		l.y += 2
+		l.blocks(block.Children, x+indentWidth)
+		return
+
+	case dto.BlockTypeCodeBlock:
+		l.code(plainText(block.Content), x, width)
+
+	case dto.BlockTypeQuote:
+		options.x, options.width, options.color = x+12, width-12, &mutedColor
+		options.decorate = func(top, height float64) {
+			l.page.Rect(x, top, 3, height, borderColor)
+		}
+		l.text(block.Content, options)
+		l.y += 6
+
+	case dto.BlockTypeTable:
+		l.table(block.TableContent, x, width)
+
+	case dto.BlockTypeImage:
+		l.image(block, x, width)
+
+	case dto.BlockTypeVideo, dto.BlockTypeAudio, dto.BlockTypeFile:
+		if url := stringProp(block.Props, "url"); url != "" {
+			name := firstNonEmpty(stringProp(block.Props, "name"), stringProp(block.Props, "caption"), url)
+			l.text([]dto.InlineContent{{Type: "link", Text: name, Href: url}}, options)
+			l.y += 6
+		}
+
+	case dto.BlockTypeColumnList:
+		for _, column := range block.Children {
+			l.blocks(column.Children, x)
+		}
+		return
+
+	default:
+		// Paragraphs, and the custom blocks as their text
+		if len(block.Content) > 0 {
+			l.text(block.Content, options)
+			l.y += 6
+		} else if len(block.Children) == 0 {
+			l.y += bodySize * lineSpacing
+		}
+	}
+
+	l.blocks(block.Children, x+indentWidth)
+}
+
+// code writes the lines of a code block on a shaded background, the long
+// lines are wrapped.
+func (l *layout) code(code string, x, width float64) {
+	const padding = 8.0
+	height := codeSize * lineSpacing
+	perLine := max(int((width-2*padding)/pdfwriter.TextWidth(pdfwriter.Courier, codeSize, "m")), 1)
+
+	var lines []string
+	for _, line := range strings.Split(strings.ReplaceAll(code, "\t", "    "), "\n") {
+		characters := []rune(line)
+		for len(characters) > perLine {
+			lines = append(lines, string(characters[:perLine]))
+			characters = characters[perLine:]
+		}
+		lines = append(lines, string(characters))
+	}
+
+	l.ensure(height + 2*padding)
+	l.page.Rect(x, l.y, width, padding, shadeColor)
+	l.y += padding
+	for _, line := range lines {
+		if l.y+height > pageBottom {
+			l.newPage()
+		}
+		l.page.Rect(x, l.y, width, height, shadeColor)
+		l.page.Text(x+padding, l.y+codeSize*1.05, pdfwriter.Courier, codeSize, textColor, line)
+		l.y += height
+	}
+	l.page.Rect(x, l.y, width, padding, shadeColor)
+	l.y += padding + 8
+}
+
+// table writes a table with columns of the same width, the first row is
+// shaded when it is a header.
+func (l *layout) table(content *dto.TableContent, x, width float64) {
+	if content == nil || len(content.Rows) == 0 {
+		return
+	}
+	columns := 0
+	for _, row := range content.Rows {
+		columns = max(columns, len(row.Cells))
+	}
+	if columns == 0 {
+		return
+	}
+
+	const padding = 5.0
+	columnWidth := width / float64(columns)
+	height := bodySize * lineSpacing
+	for r, row := range content.Rows {
+		header := r < content.HeaderRows
+		cells := make([][]line, len(row.Cells))
+		rowHeight := height
+		for c, cell := range row.Cells {
+			cells[c] = wrap(cell.Content, textOptions{width: columnWidth - 2*padding, size: bodySize, bold: header})
+			rowHeight = max(rowHeight, float64(len(cells[c]))*height)
+		}
+		rowHeight += 2 * padding
+
+		l.ensure(rowHeight)
+		if header {
+			l.page.Rect(x, l.y, width, rowHeight, shadeColor)
+		}
+		for c := range columns {
+			cellX := x + float64(c)*columnWidth
+			l.page.StrokeRect(cellX, l.y, columnWidth, rowHeight, 0.5, borderColor)
+			if c < len(cells) {
+				options := textOptions{x: cellX + padding, width: columnWidth - 2*padding, size: bodySize, alignment: stringProp(row.Cells[c].Props, "textAlignment")}
+				l.drawLines(cells[c], options, l.y+padding)
+			}
+		}
+		l.y += rowHeight
+	}
+	l.y += 10
+}
+
+// image draws an image block scaled to the width of the content, or a link
+// to the image when its content is not available.
+func (l *layout) image(block dto.Block, x, width float64) {
+	url := stringProp(block.Props, "url")
+	if url == "" {
+		return
+	}
+	caption := stringProp(block.Props, "caption")
+
+	img, ok := l.cache[url]
+	if !ok && l.images != nil {
+		if data := l.images(url); data != nil {
+			img, _ = l.doc.AddImage(data)
+		}
+		l.cache[url] = img
+	}
+	if img == nil {
+		text := firstNonEmpty(caption, stringProp(block.Props, "name"), url)
+		l.text([]dto.InlineContent{{Type: "link", Text: text, Href: url}}, textOptions{x: x, width: width, size: bodySize})
+		l.y += 6
+		return
+	}
+
+	// Images are laid out at 96 DPI, up to the width of the content and
+	// two thirds of the height of the page.
+	w := math.Min(float64(img.Width)*0.75, width)
+	if preview := intProp(block.Props, "previewWidth", 0); preview > 0 {
+		w = math.Min(float64(preview)*0.75, width)
+	}
+	h := w * float64(img.Height) / float64(img.Width)
+	if maxHeight := (pageBottom - margin) * 2 / 3; h > maxHeight {
+		w, h = w*maxHeight/h, maxHeight
+	}
+
+	l.ensure(h)
+	imageX := x
+	switch stringProp(block.Props, "textAlignment") {
+	case "center":
+		imageX = x + (width-w)/2
+	case "right":
+		imageX = x + width - w
+	}
+	l.page.Image(img, imageX, l.y, w, h)
+	l.y += h + 4
+	if caption != "" {
+		l.text([]dto.InlineContent{{Type: "text", Text: caption, Styles: map[string]bool{"italic": true}}}, textOptions{x: x, width: width, size: bodySize * 0.9, color: &mutedColor})
+	}
+	l.y += 8
+}
+
+// toc inserts the pages of the table of contents, with a link to the first
+// page of each document.
+func (l *layout) toc(documents []Document, starts []*pdfwriter.Page) {
+	const size = 11.0
+	height := size * 1.8
+	perPage := int((pageBottom - margin - 50) / height)
+	count := (len(documents) + perPage - 1) / perPage
+
+	pages := make([]*pdfwriter.Page, count)
+	for i := range pages {
+		pages[i] = l.doc.InsertPage(i)
+	}
+	numbers := make(map[*pdfwriter.Page]int)
+	for i, page := range l.doc.Pages() {
+		numbers[page] = i + 1
+	}
+
+	l.page, l.y = pages[0], margin
+	l.title("Contents")
+	for i, document := range documents {
+		if i > 0 && i%perPage == 0 {
+			l.page, l.y = pages[i/perPage], margin
+		}
+		x := margin + float64(min(document.Depth, 6))*indentWidth
+		number := fmt.Sprint(numbers[starts[i]])
+		numberWidth := pdfwriter.TextWidth(pdfwriter.Helvetica, size, number)
+		title := truncate(document.Title, pdfwriter.Helvetica, size, margin+contentWidth-numberWidth-12-x)
+
+		baseline := l.y + size*1.1
+		l.page.Text(x, baseline, pdfwriter.Helvetica, size, linkColor, title)
+		l.page.Text(margin+contentWidth-numberWidth, baseline, pdfwriter.Helvetica, size, mutedColor, number)
+		l.page.LinkPage(x, l.y, margin+contentWidth-x, height, starts[i], margin)
+		l.y += height
+	}
+}
+
+func (l *layout) pageNumbers() {
+	pages := l.doc.Pages()
+	for i, page := range pages {
+		text := fmt.Sprintf("%d / %d", i+1, len(pages))
+		width := pdfwriter.TextWidth(pdfwriter.Helvetica, 8, text)
+		page.Text((pdfwriter.PageWidth-width)/2, pdfwriter.PageHeight-margin/2, pdfwriter.Helvetica, 8, mutedColor, text)
+	}
+}
+
+func truncate(text string, font pdfwriter.Font, size, width float64) string {
+	if pdfwriter.TextWidth(font, size, text) <= width {
+		return text
+	}
+	characters := []rune(text)
+	for len(characters) > 0 && pdfwriter.TextWidth(font, size, string(characters)+"…") > width {
+		characters = characters[:len(characters)-1]
+	}
+	return string(characters) + "…"
+}
+
+func plainText(content []dto.InlineContent) string {
+	var b strings.Builder
+	for _, inline := range content {
+		b.WriteString(inline.Text)
+	}
+	return b.String()
+}
+
+func stringProp(props map[string]any, key string) string {
+	value, _ := props[key].(string)
+	return value
+}
+
+func intProp(props map[string]any, key string, fallback int) int {
+	switch value := props[key].(type) {
+	case float64:
+		return int(value)
+	case int:
+		return value
+	}
+	return fallback
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, value := range values {
+		if value != "" {
+			return value
+		}
+	}
+	return ""
+}
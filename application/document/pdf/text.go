@@ -0,0 +1,191 @@
+package pdf
+
+import (
+	"strings"
+
+	"github.com/labbs/nexo/application/document/dto"
+	pdfwriter "github.com/labbs/nexo/infrastructure/pdf"
+)
+
+type textOptions struct {
+	x, width  float64
+	size      float64
+	bold      bool
+	color     *pdfwriter.Color
+	alignment string
+	// decorate draws the decoration of each line, such as the bar of a quote
+	decorate func(top, height float64)
+}
+
+// piece is a word, or a part of a word with other styles, placed on a line
+type piece struct {
+	text      string
+	font      pdfwriter.Font
+	href      string
+	code      bool
+	underline bool
+	strike    bool
+	x, width  float64
+}
+
+type line struct {
+	pieces []piece
+	width  float64
+}
+
+// text writes inline content wrapped to the width of the options, starting
+// a new page when a line does not fit.
+func (l *layout) text(content []dto.InlineContent, options textOptions) {
+	height := options.size * lineSpacing
+	for _, line := range wrap(content, options) {
+		if l.y+height > pageBottom {
+			l.newPage()
+		}
+		if options.decorate != nil {
+			options.decorate(l.y, height)
+		}
+		l.drawLine(line, options, l.y)
+		l.y += height
+	}
+}
+
+// drawLines writes lines from top, on the current page
+func (l *layout) drawLines(lines []line, options textOptions, top float64) {
+	for _, line := range lines {
+		l.drawLine(line, options, top)
+		top += options.size * lineSpacing
+	}
+}
+
+func (l *layout) drawLine(line line, options textOptions, top float64) {
+	x := options.x
+	switch options.alignment {
+	case "center":
+		x += (options.width - line.width) / 2
+	case "right":
+		x += options.width - line.width
+	}
+	baseline := top + options.size*1.05
+
+	for _, p := range line.pieces {
+		color := textColor
+		if options.color != nil {
+			color = *options.color
+		}
+		if p.href != "" {
+			color = linkColor
+		}
+		if p.code {
+			l.page.Rect(x+p.x-1, top+options.size*0.2, p.width+2, options.size*1.15, shadeColor)
+		}
+		l.page.Text(x+p.x, baseline, p.font, options.size, color, p.text)
+		if p.underline || p.href != "" {
+			l.page.Line(x+p.x, baseline+1.5, x+p.x+p.width, baseline+1.5, 0.5, color)
+		}
+		if p.strike {
+			l.page.Line(x+p.x, baseline-options.size*0.3, x+p.x+p.width, baseline-options.size*0.3, 0.5, color)
+		}
+		if p.href != "" {
+			l.page.LinkURI(x+p.x, top, p.width, options.size*lineSpacing, p.href)
+		}
+	}
+}
+
+// wrap splits inline content in lines which fit the width of the options.
+// The words longer than a line are split.
+func wrap(content []dto.InlineContent, options textOptions) []line {
+	var lines []line
+	current := line{}
+	flush := func() {
+		lines = append(lines, current)
+		current = line{}
+	}
+
+	// spaced is set by a space before the next word, which may be in the
+	// next run
+	spaced := false
+	for _, inline := range content {
+		template := piece{
+			font:      font(inline.Styles, options.bold),
+			code:      inline.Styles["code"],
+			underline: inline.Styles["underline"],
+			strike:    inline.Styles["strike"],
+		}
+		if inline.Type == "link" {
+			template.href = inline.Href
+		}
+		space := pdfwriter.TextWidth(template.font, options.size, " ")
+
+		for i, segment := range strings.Split(inline.Text, "\n") {
+			if i > 0 {
+				flush()
+				spaced = false
+			}
+			for j, word := range strings.Split(segment, " ") {
+				if j > 0 {
+					spaced = true
+				}
+				if word == "" {
+					continue
+				}
+
+				p := template
+				p.text = word
+				p.width = pdfwriter.TextWidth(p.font, options.size, word)
+				gap := 0.0
+				if spaced && len(current.pieces) > 0 {
+					gap = space
+				}
+				if len(current.pieces) > 0 && current.width+gap+p.width > options.width {
+					flush()
+					gap = 0
+				}
+
+				// Split the words longer than a line
+				for p.width > options.width && len([]rune(p.text)) > 1 {
+					characters := []rune(p.text)
+					n := len(characters) - 1
+					for n > 1 && pdfwriter.TextWidth(p.font, options.size, string(characters[:n])) > options.width-current.width {
+						n--
+					}
+					head := p
+					head.text = string(characters[:n])
+					head.width = pdfwriter.TextWidth(p.font, options.size, head.text)
+					head.x = current.width
+					current.pieces = append(current.pieces, head)
+					current.width += head.width
+					flush()
+					gap = 0
+					p.text = string(characters[n:])
+					p.width = pdfwriter.TextWidth(p.font, options.size, p.text)
+				}
+
+				p.x = current.width + gap
+				current.pieces = append(current.pieces, p)
+				current.width = p.x + p.width
+				spaced = false
+			}
+		}
+	}
+	if len(current.pieces) > 0 || len(lines) == 0 {
+		flush()
+	}
+	return lines
+}
+
+func font(styles map[string]bool, bold bool) pdfwriter.Font {
+	bold = bold || styles["bold"]
+	switch {
+	case styles["code"] && bold:
+		return pdfwriter.CourierBold
+	case styles["code"]:
+		return pdfwriter.Courier
+	case bold && styles["italic"]:
+		return pdfwriter.HelveticaBoldOblique
+	case bold:
+		return pdfwriter.HelveticaBold
+	case styles["italic"]:
+		return pdfwriter.HelveticaOblique
+	}
+	return pdfwriter.Helvetica
+}
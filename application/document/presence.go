@@ -0,0 +1,27 @@
+package document
+
+import (
+	"fmt"
+
+	"github.com/labbs/nexo/application/document/dto"
+	"github.com/labbs/nexo/infrastructure/helpers/apperrors"
+)
+
+// GetPresence returns the users editing a document in its collaboration room.
+func (a *DocumentApplication) GetPresence(input dto.GetPresenceInput) (*dto.GetPresenceOutput, error) {
+	if input.DocumentId == nil && input.Slug == nil {
+		return nil, apperrors.ErrInvalidInput
+	}
+
+	// Verify user has access to the document
+	doc, err := a.DocumentPers.GetDocumentByIdOrSlugWithUserPermissions(input.SpaceId, input.DocumentId, input.Slug, input.UserId)
+	if err != nil {
+		return nil, fmt.Errorf("document not found or access denied: %w", err)
+	}
+
+	output := &dto.GetPresenceOutput{}
+	if a.CollaborationPresence != nil {
+		output.Users = a.CollaborationPresence.RoomPresence("document:" + doc.Id)
+	}
+	return output, nil
+}
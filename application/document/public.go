@@ -3,17 +3,50 @@ package document
 import (
 	"github.com/labbs/nexo/infrastructure/helpers/apperrors"
 	"github.com/labbs/nexo/application/document/dto"
+	eventDto "github.com/labbs/nexo/application/event/dto"
+	"github.com/labbs/nexo/domain"
 )
 
 func (c *DocumentApplication) SetPublic(input dto.SetPublicInput) error {
 	logger := c.Logger.With().Str("component", "application.document.set_public").Logger()
 
+	// Snapshot taken beforehand for the event payload
+	var before map[string]any
+	if doc, err := c.DocumentPers.GetDocumentWithPermissions(input.DocumentId, input.UserId); err == nil {
+		before = eventDto.DocumentSnapshot(doc)
+	}
+
 	err := c.DocumentPers.SetPublic(input.DocumentId, input.Public, input.UserId)
 	if err != nil {
 		logger.Error().Err(err).Str("document_id", input.DocumentId).Msg("failed to set document public status")
 		return err
 	}
 
+	if before != nil {
+		after := make(map[string]any, len(before))
+		for k, v := range before {
+			after[k] = v
+		}
+		after["public"] = input.Public
+		c.publishDocumentEvent(domain.WebhookEventDocumentUpdated, input.UserId, nil, before, after)
+	}
+
+	return nil
+}
+
+// SetBreakInheritance makes a document and its sub pages stop, or resume,
+// inheriting the permissions of the parent documents and of the space. It
+// requires to be able to manage the permissions of the document.
+func (c *DocumentApplication) SetBreakInheritance(input dto.SetBreakInheritanceInput) error {
+	logger := c.Logger.With().Str("component", "application.document.set_break_inheritance").Logger()
+
+	err := c.DocumentPers.SetBreakInheritance(input.DocumentId, input.BreakInheritance, input.UserId)
+	if err != nil {
+		logger.Error().Err(err).Str("document_id", input.DocumentId).Msg("failed to set document inheritance")
+		return err
+	}
+
+	c.recheckCollaborationAccess(input.DocumentId)
 	return nil
 }
 
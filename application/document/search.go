@@ -5,31 +5,42 @@ import (
 
 	"github.com/labbs/nexo/infrastructure/helpers/apperrors"
 	"github.com/labbs/nexo/application/document/dto"
+	searchDto "github.com/labbs/nexo/application/search/dto"
+	"github.com/labbs/nexo/domain"
 )
 
+// Search runs a full-text search restricted to documents
 func (app *DocumentApplication) Search(input dto.SearchInput) (*dto.SearchOutput, error) {
 	if len(input.Query) < 2 {
 		return nil, apperrors.ErrInvalidInput
 	}
 
-	docs, err := app.DocumentPers.Search(input.Query, input.UserId, input.SpaceId, input.Limit)
+	result, err := app.SearchApplication.Search(searchDto.SearchInput{
+		UserId:  input.UserId,
+		Query:   input.Query,
+		SpaceId: input.SpaceId,
+		Types:   []string{string(domain.SearchResourceDocument)},
+		Limit:   input.Limit,
+	})
 	if err != nil {
 		return nil, fmt.Errorf("failed to search documents: %w", err)
 	}
 
 	output := &dto.SearchOutput{
-		Results: make([]dto.SearchResultItem, len(docs)),
+		Results: make([]dto.SearchResultItem, len(result.Results)),
 	}
 
-	for i, doc := range docs {
+	for i, hit := range result.Results {
 		output.Results[i] = dto.SearchResultItem{
-			Id:        doc.Id,
-			Name:      doc.Name,
-			Slug:      doc.Slug,
-			SpaceId:   doc.SpaceId,
-			SpaceName: doc.Space.Name,
-			Icon:      doc.Config.Icon,
-			UpdatedAt: doc.UpdatedAt,
+			Id:        hit.Id,
+			Name:      hit.Title,
+			Slug:      hit.Slug,
+			SpaceId:   hit.SpaceId,
+			SpaceName: hit.SpaceName,
+			Icon:      hit.Icon,
+			Snippet:   hit.Snippet,
+			BlockId:   hit.BlockId,
+			UpdatedAt: hit.UpdatedAt,
 		}
 	}
 
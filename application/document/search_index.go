@@ -0,0 +1,32 @@
+package document
+
+import (
+	searchDto "github.com/labbs/nexo/application/search/dto"
+	"github.com/labbs/nexo/domain"
+)
+
+// indexDocument updates the search index entry of a document. Trashed
+// documents keep their entry: search leaves them out until they are restored.
+func (a *DocumentApplication) indexDocument(doc *domain.Document) {
+	if a.SearchApplication == nil {
+		return
+	}
+	a.SearchApplication.IndexDocument(searchDto.IndexDocumentInput{Document: doc})
+}
+
+func (a *DocumentApplication) indexComment(doc *domain.Document, comment *domain.Comment) {
+	if a.SearchApplication == nil {
+		return
+	}
+	a.SearchApplication.IndexComment(searchDto.IndexCommentInput{Document: doc, Comment: comment})
+}
+
+func (a *DocumentApplication) removeCommentFromIndex(commentId string) {
+	if a.SearchApplication == nil {
+		return
+	}
+	a.SearchApplication.RemoveFromIndex(searchDto.RemoveFromIndexInput{
+		ResourceType: domain.SearchResourceComment,
+		ResourceIds:  []string{commentId},
+	})
+}
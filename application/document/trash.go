@@ -4,6 +4,8 @@ import (
 	"fmt"
 
 	"github.com/labbs/nexo/application/document/dto"
+	eventDto "github.com/labbs/nexo/application/event/dto"
+	"github.com/labbs/nexo/domain"
 )
 
 func (c *DocumentApplication) GetTrash(input dto.GetTrashInput) (*dto.GetTrashOutput, error) {
@@ -27,5 +29,9 @@ func (c *DocumentApplication) RestoreDocument(input dto.RestoreDocumentInput) er
 		return err
 	}
 
+	if restored, err := c.DocumentPers.GetDocumentWithPermissions(input.DocumentId, input.UserId); err == nil {
+		c.publishDocumentEvent(domain.WebhookEventDocumentRestored, input.UserId, nil, nil, eventDto.DocumentSnapshot(restored))
+	}
+
 	return nil
 }
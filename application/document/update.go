@@ -1,11 +1,13 @@
 package document
 
 import (
+	"bytes"
 	"fmt"
 
 	"github.com/gosimple/slug"
 	"github.com/labbs/nexo/infrastructure/helpers/apperrors"
 	"github.com/labbs/nexo/application/document/dto"
+	eventDto "github.com/labbs/nexo/application/event/dto"
 	"github.com/labbs/nexo/domain"
 	"github.com/labbs/nexo/infrastructure/helpers/shortuuid"
 )
@@ -24,6 +26,8 @@ func (a *DocumentApplication) UpdateDocument(input dto.UpdateDocumentInput) (*dt
 		return nil, apperrors.ErrAccessDenied
 	}
 
+	before := eventDto.DocumentSnapshot(document)
+
 	// Update name only if provided
 	if input.Name != nil && *input.Name != "" && document.Name != *input.Name {
 		document.Name = *input.Name
@@ -31,12 +35,24 @@ func (a *DocumentApplication) UpdateDocument(input dto.UpdateDocumentInput) (*dt
 	}
 
 	// Update content only if provided
+	contentReplaced := false
 	if input.Content != nil {
-		document.Content = dto.BlocksToJSON(*input.Content)
+		content := dto.BlocksToJSON(*input.Content)
+		contentReplaced = !bytes.Equal(content, dto.BlocksToJSON(dto.JSONToBlocks(document.Content)))
+		document.Content = content
 	}
 
+	// Locks and the permissions inherited from the parents change the access
+	// of the clients connected to the document
+	accessChanged := false
+
 	// Update parentId if provided
 	if input.ParentId != nil {
+		previousParentId := ""
+		if document.ParentId != nil {
+			previousParentId = *document.ParentId
+		}
+		accessChanged = *input.ParentId != previousParentId
 		// If parentId is empty string, set to nil (move to root)
 		if *input.ParentId == "" {
 			document.ParentId = nil
@@ -47,6 +63,7 @@ func (a *DocumentApplication) UpdateDocument(input dto.UpdateDocumentInput) (*dt
 
 	// Update config if provided
 	if input.Config != nil {
+		accessChanged = accessChanged || input.Config.Lock != document.Config.Lock
 		document.Config = *input.Config
 	}
 
@@ -66,5 +83,14 @@ func (a *DocumentApplication) UpdateDocument(input dto.UpdateDocumentInput) (*dt
 		return nil, fmt.Errorf("failed to update document: %w", err)
 	}
 
+	if contentReplaced {
+		a.resetCollaboration(document.Id)
+	}
+	if accessChanged {
+		a.recheckCollaborationAccess(document.Id)
+	}
+	a.indexDocument(document)
+	a.publishDocumentEvent(domain.WebhookEventDocumentUpdated, input.UserId, input.ActionChain, before, eventDto.DocumentSnapshot(document))
+
 	return &dto.UpdateDocumentOutput{Document: document}, nil
 }
@@ -8,6 +8,7 @@ import (
 	"github.com/google/uuid"
 	"github.com/labbs/nexo/infrastructure/helpers/apperrors"
 	"github.com/labbs/nexo/application/document/dto"
+	eventDto "github.com/labbs/nexo/application/event/dto"
 	"github.com/labbs/nexo/domain"
 )
 
@@ -110,6 +111,8 @@ func (app *DocumentApplication) RestoreVersion(input dto.RestoreVersionInput) er
 		return fmt.Errorf("failed to create backup version: %w", err)
 	}
 
+	before := eventDto.DocumentSnapshot(doc)
+
 	// Restore the document to the selected version
 	doc.Name = version.Name
 	doc.Content = version.Content
@@ -119,6 +122,10 @@ func (app *DocumentApplication) RestoreVersion(input dto.RestoreVersionInput) er
 		return fmt.Errorf("failed to restore document: %w", err)
 	}
 
+	app.resetCollaboration(doc.Id)
+	app.indexDocument(doc)
+	app.publishDocumentEvent(domain.WebhookEventDocumentUpdated, input.UserId, nil, before, eventDto.DocumentSnapshot(doc))
+
 	return nil
 }
 
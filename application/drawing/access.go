@@ -0,0 +1,31 @@
+package drawing
+
+import (
+	"fmt"
+
+	permissionDto "github.com/labbs/nexo/application/permission/dto"
+	"github.com/labbs/nexo/domain"
+	"github.com/labbs/nexo/infrastructure/helpers/apperrors"
+)
+
+// checkDrawingAccess verifies that the user has at least the required role
+// on the drawing. The role is the one the permissions of the drawing give to
+// the user or their groups, else their role in the space, so that a drawing
+// can be shared beyond its space or denied within it. Viewers can read the
+// drawing, changing it requires the editor role.
+func (app *DrawingApplication) checkDrawingAccess(drawing *domain.Drawing, userId string, requiredRole domain.PermissionRole) error {
+	result, err := app.PermissionApplication.GetEffectivePermission(permissionDto.GetEffectivePermissionInput{
+		RequesterId:  userId,
+		ResourceType: string(domain.PermissionTypeDrawing),
+		ResourceId:   drawing.Id,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to resolve drawing permission: %w", err)
+	}
+
+	role := result.Permission.Role
+	if role == nil || !role.Includes(requiredRole) {
+		return apperrors.ErrAccessDenied
+	}
+	return nil
+}
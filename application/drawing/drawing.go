@@ -22,6 +22,7 @@ type DrawingApplication struct {
 	DrawingPers           domain.DrawingPers
 	SpaceApplication      ports.SpacePort
 	PermissionApplication ports.PermissionPort
+	SearchApplication     ports.SearchPort
 }
 
 func NewDrawingApplication(config config.Config, logger zerolog.Logger, drawingPers domain.DrawingPers) *DrawingApplication {
@@ -33,13 +34,13 @@ func NewDrawingApplication(config config.Config, logger zerolog.Logger, drawingP
 }
 
 func (app *DrawingApplication) CreateDrawing(input dto.CreateDrawingInput) (*dto.CreateDrawingOutput, error) {
-	// Verify user has access to the space
+	// Verify user can edit the space
 	spaceResult, err := app.SpaceApplication.GetSpaceById(spaceDto.GetSpaceByIdInput{SpaceId: input.SpaceId})
 	if err != nil {
 		return nil, fmt.Errorf("space not found: %w", err)
 	}
 
-	if spaceResult.Space.GetUserRole(input.UserId) == nil {
+	if !spaceResult.Space.HasPermission(input.UserId, string(domain.PermissionRoleEditor)) {
 		return nil, apperrors.ErrAccessDenied
 	}
 
@@ -110,6 +111,8 @@ func (app *DrawingApplication) CreateDrawing(input dto.CreateDrawingInput) (*dto
 		app.Logger.Warn().Err(err).Str("drawing_id", drawing.Id).Str("user_id", input.UserId).Msg("failed to create creator permission")
 	}
 
+	app.indexDrawing(drawing)
+
 	return &dto.CreateDrawingOutput{
 		Id:        drawing.Id,
 		Name:      drawing.Name,
@@ -117,28 +120,40 @@ func (app *DrawingApplication) CreateDrawing(input dto.CreateDrawingInput) (*dto
 	}, nil
 }
 
+// ListDrawings lists the drawings of a space the user can read, including
+// those shared with users who cannot read the space.
 func (app *DrawingApplication) ListDrawings(input dto.ListDrawingsInput) (*dto.ListDrawingsOutput, error) {
-	// Verify user has access to the space
-	spaceResult, err := app.SpaceApplication.GetSpaceById(spaceDto.GetSpaceByIdInput{SpaceId: input.SpaceId})
+	drawings, err := app.DrawingPers.GetBySpaceId(input.SpaceId)
 	if err != nil {
-		return nil, fmt.Errorf("space not found: %w", err)
+		return nil, fmt.Errorf("failed to list drawings: %w", err)
 	}
 
-	if spaceResult.Space.GetUserRole(input.UserId) == nil {
-		return nil, apperrors.ErrAccessDenied
+	// Resolve the role of the user on every drawing at once
+	ids := make([]string, len(drawings))
+	for i, d := range drawings {
+		ids[i] = d.Id
 	}
-
-	drawings, err := app.DrawingPers.GetBySpaceId(input.SpaceId)
+	roles, err := app.PermissionApplication.GetEffectiveRoles(permissionDto.GetEffectiveRolesInput{
+		UserId:       input.UserId,
+		ResourceType: string(domain.PermissionTypeDrawing),
+		SpaceId:      input.SpaceId,
+		ResourceIds:  ids,
+	})
 	if err != nil {
-		return nil, fmt.Errorf("failed to list drawings: %w", err)
+		return nil, fmt.Errorf("failed to resolve drawing permissions: %w", err)
 	}
 
 	output := &dto.ListDrawingsOutput{
-		Drawings: make([]dto.DrawingItem, len(drawings)),
+		Drawings: make([]dto.DrawingItem, 0, len(drawings)),
 	}
 
-	for i, d := range drawings {
-		output.Drawings[i] = dto.DrawingItem{
+	for _, d := range drawings {
+		// Skip the drawings the user cannot read
+		if role := roles.Roles[d.Id]; role == nil || !role.Includes(domain.PermissionRoleViewer) {
+			continue
+		}
+
+		output.Drawings = append(output.Drawings, dto.DrawingItem{
 			Id:         d.Id,
 			DocumentId: d.DocumentId,
 			Name:       d.Name,
@@ -147,7 +162,12 @@ func (app *DrawingApplication) ListDrawings(input dto.ListDrawingsInput) (*dto.L
 			CreatedBy:  d.User.Username,
 			CreatedAt:  d.CreatedAt,
 			UpdatedAt:  d.UpdatedAt,
-		}
+		})
+	}
+
+	// Outside the space, and none of its drawings is shared with the user
+	if len(output.Drawings) == 0 && (roles.SpaceRole == nil || !roles.SpaceRole.Includes(domain.PermissionRoleViewer)) {
+		return nil, apperrors.ErrAccessDenied
 	}
 
 	return output, nil
@@ -159,14 +179,9 @@ func (app *DrawingApplication) GetDrawing(input dto.GetDrawingInput) (*dto.GetDr
 		return nil, fmt.Errorf("drawing not found: %w", err)
 	}
 
-	// Verify user has access to the space
-	spaceResult, err := app.SpaceApplication.GetSpaceById(spaceDto.GetSpaceByIdInput{SpaceId: drawing.SpaceId})
-	if err != nil {
-		return nil, fmt.Errorf("space not found: %w", err)
-	}
-
-	if spaceResult.Space.GetUserRole(input.UserId) == nil {
-		return nil, apperrors.ErrAccessDenied
+	// Verify user can read the drawing
+	if err := app.checkDrawingAccess(drawing, input.UserId, domain.PermissionRoleViewer); err != nil {
+		return nil, err
 	}
 
 	// Convert JSONB to slices/maps
@@ -210,14 +225,9 @@ func (app *DrawingApplication) UpdateDrawing(input dto.UpdateDrawingInput) error
 		return fmt.Errorf("drawing not found: %w", err)
 	}
 
-	// Verify user has access to the space
-	spaceResult, err := app.SpaceApplication.GetSpaceById(spaceDto.GetSpaceByIdInput{SpaceId: drawing.SpaceId})
-	if err != nil {
-		return fmt.Errorf("space not found: %w", err)
-	}
-
-	if spaceResult.Space.GetUserRole(input.UserId) == nil {
-		return apperrors.ErrAccessDenied
+	// Verify user can edit the drawing
+	if err := app.checkDrawingAccess(drawing, input.UserId, domain.PermissionRoleEditor); err != nil {
+		return err
 	}
 
 	if input.Name != nil {
@@ -268,6 +278,8 @@ func (app *DrawingApplication) UpdateDrawing(input dto.UpdateDrawingInput) error
 		return fmt.Errorf("failed to update drawing: %w", err)
 	}
 
+	app.indexDrawing(drawing)
+
 	return nil
 }
 
@@ -277,14 +289,9 @@ func (app *DrawingApplication) MoveDrawing(input dto.MoveDrawingInput) (*dto.Mov
 		return nil, fmt.Errorf("drawing not found: %w", err)
 	}
 
-	// Verify user has access to the space
-	spaceResult, err := app.SpaceApplication.GetSpaceById(spaceDto.GetSpaceByIdInput{SpaceId: drawing.SpaceId})
-	if err != nil {
-		return nil, fmt.Errorf("space not found: %w", err)
-	}
-
-	if spaceResult.Space.GetUserRole(input.UserId) == nil {
-		return nil, apperrors.ErrAccessDenied
+	// Verify user can edit the drawing
+	if err := app.checkDrawingAccess(drawing, input.UserId, domain.PermissionRoleEditor); err != nil {
+		return nil, err
 	}
 
 	drawing.DocumentId = input.DocumentId
@@ -306,19 +313,16 @@ func (app *DrawingApplication) DeleteDrawing(input dto.DeleteDrawingInput) error
 		return fmt.Errorf("drawing not found: %w", err)
 	}
 
-	// Verify user has access to the space
-	spaceResult, err := app.SpaceApplication.GetSpaceById(spaceDto.GetSpaceByIdInput{SpaceId: drawing.SpaceId})
-	if err != nil {
-		return fmt.Errorf("space not found: %w", err)
-	}
-
-	if spaceResult.Space.GetUserRole(input.UserId) == nil {
-		return apperrors.ErrAccessDenied
+	// Verify user can edit the drawing
+	if err := app.checkDrawingAccess(drawing, input.UserId, domain.PermissionRoleEditor); err != nil {
+		return err
 	}
 
 	if err := app.DrawingPers.Delete(input.DrawingId); err != nil {
 		return fmt.Errorf("failed to delete drawing: %w", err)
 	}
 
+	app.removeDrawingFromIndex(input.DrawingId)
+
 	return nil
 }
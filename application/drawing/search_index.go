@@ -0,0 +1,23 @@
+package drawing
+
+import (
+	searchDto "github.com/labbs/nexo/application/search/dto"
+	"github.com/labbs/nexo/domain"
+)
+
+func (app *DrawingApplication) indexDrawing(drawing *domain.Drawing) {
+	if app.SearchApplication == nil {
+		return
+	}
+	app.SearchApplication.IndexDrawing(searchDto.IndexDrawingInput{Drawing: drawing})
+}
+
+func (app *DrawingApplication) removeDrawingFromIndex(drawingId string) {
+	if app.SearchApplication == nil {
+		return
+	}
+	app.SearchApplication.RemoveFromIndex(searchDto.RemoveFromIndexInput{
+		ResourceType: domain.SearchResourceDrawing,
+		ResourceIds:  []string{drawingId},
+	})
+}
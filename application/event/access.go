@@ -0,0 +1,92 @@
+package event
+
+import (
+	"errors"
+
+	"github.com/labbs/nexo/application/event/dto"
+	permissionDto "github.com/labbs/nexo/application/permission/dto"
+	"github.com/labbs/nexo/domain"
+	"github.com/labbs/nexo/infrastructure/helpers/apperrors"
+)
+
+// readAccess returns a function reporting whether a user can read the
+// resource of an event, so that webhooks and actions only receive the events
+// their owner could see. The answers are memoized for the event, as several
+// subscribers often share an owner.
+func (app *EventApplication) readAccess(input dto.PublishEventInput) func(userId string) bool {
+	cache := map[string]bool{}
+	return func(userId string) bool {
+		allowed, ok := cache[userId]
+		if !ok {
+			allowed = app.canRead(input, userId)
+			cache[userId] = allowed
+		}
+		return allowed
+	}
+}
+
+// canRead checks the access of the user to the resource of the event: the
+// database of a row, the document of a comment, the document or the space.
+// Deleted documents and databases fall back to their space. A deleted space
+// can only be checked for the user who deleted it.
+func (app *EventApplication) canRead(input dto.PublishEventInput, userId string) bool {
+	if userId == "" || app.PermissionApplication == nil {
+		return false
+	}
+
+	type resource struct {
+		resourceType domain.PermissionType
+		resourceId   string
+	}
+	var candidates []resource
+	add := func(resourceType domain.PermissionType, resourceId string) {
+		if resourceId != "" {
+			candidates = append(candidates, resource{resourceType, resourceId})
+		}
+	}
+
+	switch {
+	case input.DatabaseId != nil:
+		add(domain.PermissionTypeDatabase, *input.DatabaseId)
+	case input.ResourceType == dto.ResourceTypeDocument:
+		add(domain.PermissionTypeDocument, input.ResourceId)
+	case input.ResourceType == dto.ResourceTypeComment:
+		add(domain.PermissionTypeDocument, commentDocumentId(input))
+	}
+	if input.SpaceId != nil {
+		add(domain.PermissionTypeSpace, *input.SpaceId)
+	}
+
+	for _, candidate := range candidates {
+		result, err := app.PermissionApplication.GetEffectivePermission(permissionDto.GetEffectivePermissionInput{
+			RequesterId:  userId,
+			ResourceType: string(candidate.resourceType),
+			ResourceId:   candidate.resourceId,
+		})
+		switch {
+		case err == nil:
+			role := result.Permission.Role
+			return role != nil && role.Includes(domain.PermissionRoleViewer)
+		case errors.Is(err, apperrors.ErrDocumentNotFound), errors.Is(err, apperrors.ErrDatabaseNotFound):
+			// Deleted with the event, check the space instead
+			continue
+		case errors.Is(err, apperrors.ErrSpaceNotFound):
+			return userId == input.ActorId
+		default:
+			app.Logger.Warn().Err(err).Str("user_id", userId).Str("resource_id", candidate.resourceId).Msg("failed to check event access")
+			return false
+		}
+	}
+
+	return false
+}
+
+// commentDocumentId returns the document of a comment event
+func commentDocumentId(input dto.PublishEventInput) string {
+	snapshot := input.After
+	if snapshot == nil {
+		snapshot = input.Before
+	}
+	documentId, _ := snapshot["document_id"].(string)
+	return documentId
+}
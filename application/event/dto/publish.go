@@ -0,0 +1,26 @@
+package dto
+
+import "github.com/labbs/nexo/domain"
+
+type PublishEventInput struct {
+	Event   domain.WebhookEvent
+	ActorId string
+
+	// Scope used to select the subscribed webhooks and actions
+	SpaceId    *string
+	DatabaseId *string
+
+	ResourceType string
+	ResourceId   string
+
+	// Snapshots of the resource around the mutation (nil on create/delete)
+	Before map[string]any
+	After  map[string]any
+
+	// Additional top-level payload fields specific to the event
+	Data map[string]any
+
+	// Ids of the actions whose steps led to the event, oldest first, exposed
+	// as payload["action_chain"] to stop action cycles
+	ActionChain []string
+}
@@ -0,0 +1,90 @@
+package dto
+
+import "github.com/labbs/nexo/domain"
+
+// Resource types used in event payloads
+const (
+	ResourceTypeDocument = "document"
+	ResourceTypeComment  = "comment"
+	ResourceTypeSpace    = "space"
+	ResourceTypeRow      = "row"
+)
+
+// DocumentSnapshot returns the event representation of a document.
+// The content is left out on purpose to keep payloads small.
+func DocumentSnapshot(doc *domain.Document) map[string]any {
+	if doc == nil {
+		return nil
+	}
+	return map[string]any{
+		"id":        doc.Id,
+		"name":      doc.Name,
+		"slug":      doc.Slug,
+		"space_id":  doc.SpaceId,
+		"parent_id": stringOrNil(doc.ParentId),
+		"public":    doc.Public,
+		"position":  doc.Position,
+		"config": map[string]any{
+			"full_width":        doc.Config.FullWidth,
+			"icon":              doc.Config.Icon,
+			"lock":              doc.Config.Lock,
+			"header_background": doc.Config.HeaderBackground,
+		},
+		"metadata": map[string]any(doc.Metadata),
+	}
+}
+
+// CommentSnapshot returns the event representation of a comment
+func CommentSnapshot(comment *domain.Comment) map[string]any {
+	if comment == nil {
+		return nil
+	}
+	return map[string]any{
+		"id":          comment.Id,
+		"document_id": comment.DocumentId,
+		"parent_id":   stringOrNil(comment.ParentId),
+		"user_id":     comment.UserId,
+		"content":     comment.Content,
+		"block_id":    stringOrNil(comment.BlockId),
+		"resolved":    comment.Resolved,
+	}
+}
+
+// SpaceSnapshot returns the event representation of a space
+func SpaceSnapshot(space *domain.Space) map[string]any {
+	if space == nil {
+		return nil
+	}
+	return map[string]any{
+		"id":         space.Id,
+		"name":       space.Name,
+		"slug":       space.Slug,
+		"icon":       space.Icon,
+		"icon_color": space.IconColor,
+		"type":       string(space.Type),
+		"owner_id":   stringOrNil(space.OwnerId),
+	}
+}
+
+// RowSnapshot returns the event representation of a database row.
+// The page content is left out, like for documents.
+func RowSnapshot(row *domain.DatabaseRow) map[string]any {
+	if row == nil {
+		return nil
+	}
+	return map[string]any{
+		"id":              row.Id,
+		"database_id":     row.DatabaseId,
+		"properties":      map[string]any(row.Properties),
+		"show_in_sidebar": row.ShowInSidebar,
+		"created_by":      row.CreatedBy,
+		"updated_by":      row.UpdatedBy,
+	}
+}
+
+func stringOrNil(s *string) any {
+	if s == nil {
+		return nil
+	}
+	return *s
+}
@@ -0,0 +1,26 @@
+package event
+
+import (
+	"github.com/labbs/nexo/application/ports"
+	"github.com/labbs/nexo/infrastructure/config"
+	"github.com/rs/zerolog"
+)
+
+// EventApplication publishes domain events emitted by the other application
+// services once their mutations have been committed. Each event is fanned out
+// to the webhooks and the automation actions subscribed to it.
+type EventApplication struct {
+	Config                config.Config
+	Logger                zerolog.Logger
+	UserApplication       ports.UserPort
+	WebhookApplication    ports.WebhookPort
+	ActionApplication     ports.ActionPort
+	PermissionApplication ports.PermissionPort
+}
+
+func NewEventApplication(config config.Config, logger zerolog.Logger) *EventApplication {
+	return &EventApplication{
+		Config: config,
+		Logger: logger,
+	}
+}
@@ -0,0 +1,27 @@
+package event
+
+import "reflect"
+
+// diffSnapshots returns the fields that differ between two snapshots as
+// {field: {"from": old, "to": new}}. Fields missing on one side are reported
+// with a nil value on that side.
+func diffSnapshots(before, after map[string]any) map[string]any {
+	changes := map[string]any{}
+	if before == nil || after == nil {
+		return changes
+	}
+
+	for key, oldValue := range before {
+		newValue, ok := after[key]
+		if !ok || !reflect.DeepEqual(oldValue, newValue) {
+			changes[key] = map[string]any{"from": oldValue, "to": newValue}
+		}
+	}
+	for key, newValue := range after {
+		if _, ok := before[key]; !ok {
+			changes[key] = map[string]any{"from": nil, "to": newValue}
+		}
+	}
+
+	return changes
+}
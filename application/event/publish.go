@@ -0,0 +1,115 @@
+package event
+
+import (
+	actionDto "github.com/labbs/nexo/application/action/dto"
+	"github.com/labbs/nexo/application/event/dto"
+	userDto "github.com/labbs/nexo/application/user/dto"
+	webhookDto "github.com/labbs/nexo/application/webhook/dto"
+)
+
+// Publish dispatches an event to the matching webhooks and actions.
+// It must only be called once the mutation has been persisted; delivery
+// failures are logged and never bubble up to the caller. Only the webhook
+// deliveries are queued before it returns, they are sent and the actions are
+// matched and run in the background.
+func (app *EventApplication) Publish(input dto.PublishEventInput) {
+	logger := app.Logger.With().
+		Str("component", "application.event.publish").
+		Str("event", string(input.Event)).
+		Str("resource_id", input.ResourceId).
+		Logger()
+
+	defer func() {
+		if r := recover(); r != nil {
+			logger.Error().Any("panic", r).Msg("event publishing panicked")
+		}
+	}()
+
+	payload := app.buildPayload(input)
+	canRead := app.readAccess(input)
+
+	if app.WebhookApplication != nil {
+		app.WebhookApplication.TriggerWebhooks(webhookDto.TriggerWebhookInput{
+			Event:   string(input.Event),
+			SpaceId: input.SpaceId,
+			Payload: payload,
+			CanRead: canRead,
+		})
+	}
+
+	if app.ActionApplication != nil {
+		app.executeActionsAsync(actionDto.ExecuteActionInput{
+			TriggerType: string(input.Event),
+			SpaceId:     input.SpaceId,
+			DatabaseId:  input.DatabaseId,
+			TriggerData: payload,
+			CanRead:     canRead,
+		})
+	}
+
+	logger.Debug().Msg("event published")
+}
+
+// executeActionsAsync matches and runs the actions of an event off the
+// request path. The memoized CanRead of the event is handed over once the
+// webhooks are queued, as it is not safe for concurrent use.
+func (app *EventApplication) executeActionsAsync(input actionDto.ExecuteActionInput) {
+	go func() {
+		defer func() {
+			if r := recover(); r != nil {
+				app.Logger.Error().Any("panic", r).Str("trigger", input.TriggerType).Msg("action execution panicked")
+			}
+		}()
+		app.ActionApplication.ExecuteActions(input)
+	}()
+}
+
+// buildPayload builds the payload shared by every event:
+// actor, resource, before/after snapshots and the list of changed fields.
+// The current state of the resource is also exposed under its type
+// (e.g. payload["document"]) for convenience.
+func (app *EventApplication) buildPayload(input dto.PublishEventInput) map[string]any {
+	actor := map[string]any{"id": input.ActorId}
+	if input.ActorId != "" && app.UserApplication != nil {
+		if result, err := app.UserApplication.GetByUserId(userDto.GetByUserIdInput{UserId: input.ActorId}); err == nil {
+			actor["username"] = result.User.Username
+			actor["email"] = result.User.Email
+		}
+	}
+
+	resource := map[string]any{
+		"type": input.ResourceType,
+		"id":   input.ResourceId,
+	}
+	if input.SpaceId != nil {
+		resource["space_id"] = *input.SpaceId
+	}
+	if input.DatabaseId != nil {
+		resource["database_id"] = *input.DatabaseId
+	}
+
+	payload := map[string]any{
+		"actor":    actor,
+		"resource": resource,
+		"before":   input.Before,
+		"after":    input.After,
+		"changes":  diffSnapshots(input.Before, input.After),
+	}
+
+	current := input.After
+	if current == nil {
+		current = input.Before
+	}
+	if input.ResourceType != "" && current != nil {
+		payload[input.ResourceType] = current
+	}
+
+	for key, value := range input.Data {
+		payload[key] = value
+	}
+	if len(input.ActionChain) > 0 {
+		payload["action_chain"] = input.ActionChain
+	}
+
+	return payload
+}
@@ -21,5 +21,7 @@ func (app *GroupApplication) AddMember(input dto.AddMemberInput) error {
 		return err
 	}
 
+	// The roles of the members come from the permissions of their groups
+	app.recheckCollaborationAccess()
 	return nil
 }
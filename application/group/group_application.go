@@ -8,10 +8,11 @@ import (
 )
 
 type GroupApplication struct {
-	Config          config.Config
-	Logger          zerolog.Logger
-	GroupPers       domain.GroupPers
-	UserApplication ports.UserPort
+	Config             config.Config
+	Logger             zerolog.Logger
+	GroupPers          domain.GroupPers
+	UserApplication    ports.UserPort
+	CollaborationRooms ports.CollaborationPort
 }
 
 func NewGroupApplication(config config.Config, logger zerolog.Logger, groupPers domain.GroupPers) *GroupApplication {
@@ -21,3 +22,11 @@ func NewGroupApplication(config config.Config, logger zerolog.Logger, groupPers
 		GroupPers: groupPers,
 	}
 }
+
+// recheckCollaborationAccess applies a membership change to the clients
+// connected to the collaboration rooms
+func (app *GroupApplication) recheckCollaborationAccess() {
+	if app.CollaborationRooms != nil {
+		app.CollaborationRooms.RecheckAccess("", "")
+	}
+}
@@ -13,5 +13,7 @@ func (app *GroupApplication) RemoveMember(input dto.RemoveMemberInput) error {
 		return err
 	}
 
+	// The roles of the members come from the permissions of their groups
+	app.recheckCollaborationAccess()
 	return nil
 }
@@ -0,0 +1,99 @@
+package importer
+
+import (
+	"archive/zip"
+	"bytes"
+	"fmt"
+	"io"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/labbs/nexo/infrastructure/helpers/apperrors"
+)
+
+// maxArchiveFileSize limits the size of a file read from an archive
+const maxArchiveFileSize = 512 << 20
+
+// archive is the content of an export zip. The zips it contains, such as
+// the parts of a large Notion export, are merged in it.
+type archive struct {
+	files map[string]*zip.File
+}
+
+func openArchive(r io.ReaderAt, size int64) (*archive, error) {
+	reader, err := zip.NewReader(r, size)
+	if err != nil {
+		return nil, fmt.Errorf("%w: the file is not a zip archive", apperrors.ErrInvalidInput)
+	}
+
+	a := &archive{files: make(map[string]*zip.File)}
+	for _, file := range reader.File {
+		name := cleanPath(file.Name)
+		if file.FileInfo().IsDir() || name == "" || strings.HasPrefix(path.Base(name), ".") || strings.HasPrefix(name, "__MACOSX/") {
+			continue
+		}
+		if strings.EqualFold(path.Ext(name), ".zip") {
+			data, err := readFile(file)
+			if err != nil {
+				return nil, err
+			}
+			inner, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+			if err != nil {
+				continue
+			}
+			for _, file := range inner.File {
+				if name := cleanPath(file.Name); !file.FileInfo().IsDir() && name != "" {
+					a.files[name] = file
+				}
+			}
+			continue
+		}
+		a.files[name] = file
+	}
+	if len(a.files) == 0 {
+		return nil, fmt.Errorf("%w: the archive is empty", apperrors.ErrInvalidInput)
+	}
+	return a, nil
+}
+
+// paths returns the paths of the files, sorted
+func (a *archive) paths() []string {
+	paths := make([]string, 0, len(a.files))
+	for name := range a.files {
+		paths = append(paths, name)
+	}
+	sort.Strings(paths)
+	return paths
+}
+
+func (a *archive) read(name string) ([]byte, error) {
+	file, ok := a.files[name]
+	if !ok {
+		return nil, fmt.Errorf("%s is not in the archive", name)
+	}
+	return readFile(file)
+}
+
+func readFile(file *zip.File) ([]byte, error) {
+	if file.UncompressedSize64 > maxArchiveFileSize {
+		return nil, fmt.Errorf("%w: %s is too large", apperrors.ErrInvalidInput, file.Name)
+	}
+	r, err := file.Open()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", file.Name, err)
+	}
+	defer r.Close()
+	data, err := io.ReadAll(io.LimitReader(r, maxArchiveFileSize))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", file.Name, err)
+	}
+	return data, nil
+}
+
+// cleanPath normalizes the path of a file in an archive, the paths going
+// above the archive stay in it.
+func cleanPath(name string) string {
+	name = path.Clean("/" + strings.ReplaceAll(name, "\\", "/"))
+	return strings.TrimPrefix(name, "/")
+}
@@ -0,0 +1,158 @@
+package importer
+
+import (
+	"fmt"
+	"path"
+	"regexp"
+	"strings"
+
+	"github.com/labbs/nexo/application/document/html"
+	"github.com/labbs/nexo/infrastructure/helpers/apperrors"
+)
+
+var (
+	// confluenceFileId is the id of a page in the name of its file, e.g.
+	// "Release-notes_65538.html" or "65538.html"
+	confluenceFileId = regexp.MustCompile(`(?:^|_)(\d+)\.html$`)
+	// confluenceURLId is the id of a page in a link to the Confluence site
+	confluenceURLId = regexp.MustCompile(`(?:pageId=|/pages/)(\d+)`)
+)
+
+// parseConfluence reads a Confluence space export, HTML format. The tree of
+// the pages is the list of the pages of index.html, the content of a page
+// is its "main-content" element.
+func parseConfluence(a *archive) (*source, error) {
+	src := &source{keys: make(map[string]*page), linkId: confluenceLinkId}
+
+	index := ""
+	for _, name := range a.paths() {
+		if path.Base(name) == "index.html" && (index == "" || strings.Count(name, "/") < strings.Count(index, "/")) {
+			index = name
+		}
+	}
+	if index == "" {
+		return nil, fmt.Errorf("%w: no index.html found in the archive, is it a Confluence HTML export?", apperrors.ErrInvalidInput)
+	}
+	root := path.Dir(index)
+
+	pages := make(map[string]*page)
+	var files []string
+	for _, name := range a.paths() {
+		if name == index || !strings.EqualFold(path.Ext(name), ".html") || path.Dir(name) != root {
+			continue
+		}
+		p, err := confluencePage(a, name)
+		if err != nil {
+			return nil, err
+		}
+		pages[name] = p
+		files = append(files, name)
+		src.keys[name] = p
+		if m := confluenceFileId.FindStringSubmatch(path.Base(name)); m != nil {
+			src.keys[m[1]] = p
+		}
+	}
+
+	data, err := a.read(index)
+	if err != nil {
+		return nil, err
+	}
+	placed := make(map[*page]bool)
+	var tree func(list *html.Node) []*page
+	tree = func(list *html.Node) []*page {
+		var result []*page
+		for _, item := range list.Children {
+			if item.Tag != "li" {
+				continue
+			}
+			link := item.Find(func(n *html.Node) bool { return n.Tag == "a" })
+			if link == nil {
+				continue
+			}
+			target, _ := resolvePath(index, link.Attr("href"))
+			p := pages[target]
+			if p == nil || placed[p] {
+				continue
+			}
+			placed[p] = true
+			for _, child := range item.Children {
+				if child.Tag == "ul" || child.Tag == "ol" {
+					p.children = append(p.children, tree(child)...)
+				}
+			}
+			result = append(result, p)
+		}
+		return result
+	}
+
+	// The list of the pages is the first list linking to pages of the export
+	list := html.ParseDocument(string(data)).Find(func(n *html.Node) bool {
+		if n.Tag != "ul" {
+			return false
+		}
+		link := n.Find(func(n *html.Node) bool { return n.Tag == "a" })
+		if link == nil {
+			return false
+		}
+		target, _ := resolvePath(index, link.Attr("href"))
+		return pages[target] != nil
+	})
+	if list != nil {
+		src.pages = tree(list)
+	}
+
+	// Pages missing from the index are imported at the root
+	for _, name := range files {
+		if !placed[pages[name]] {
+			src.pages = append(src.pages, pages[name])
+		}
+	}
+	if len(src.pages) == 0 {
+		return nil, fmt.Errorf("%w: no Confluence page found in the archive", apperrors.ErrInvalidInput)
+	}
+	return src, nil
+}
+
+// confluencePage reads a page. Its title is prefixed by the name of the
+// space in the export ("Space : Page").
+func confluencePage(a *archive, name string) (*page, error) {
+	data, err := a.read(name)
+	if err != nil {
+		return nil, err
+	}
+	document := html.ParseDocument(string(data))
+
+	title := ""
+	for _, match := range []func(n *html.Node) bool{
+		func(n *html.Node) bool { return n.Attr("id") == "title-text" },
+		func(n *html.Node) bool { return n.Tag == "title" },
+	} {
+		if node := document.Find(match); node != nil && title == "" {
+			title = strings.Join(strings.Fields(node.TextContent()), " ")
+		}
+	}
+	if _, pageTitle, found := strings.Cut(title, " : "); found {
+		title = pageTitle
+	}
+	if title == "" {
+		title = strings.TrimSuffix(confluenceFileId.ReplaceAllString(path.Base(name), ""), ".html")
+	}
+
+	content := document.Find(func(n *html.Node) bool { return n.Attr("id") == "main-content" })
+	if content == nil {
+		content = document.Find(func(n *html.Node) bool { return n.Tag == "body" })
+	}
+	if content == nil {
+		content = document
+	}
+	return &page{title: title, file: name, blocks: content.Blocks()}, nil
+}
+
+// confluenceLinkId returns the id of the page of a link to the Confluence
+// site
+func confluenceLinkId(href string) string {
+	if m := confluenceURLId.FindStringSubmatch(href); m != nil {
+		return m[1]
+	}
+	return ""
+}
@@ -0,0 +1,30 @@
+package dto
+
+import "io"
+
+// Sources of an import
+const (
+	SourceNotion     = "notion"
+	SourceConfluence = "confluence"
+)
+
+// ImportInput is an export archive of another wiki, imported in a space or
+// under a document of the space when ParentId is set.
+type ImportInput struct {
+	UserId   string
+	SpaceId  string
+	ParentId *string
+	Source   string
+	Archive  io.ReaderAt
+	Size     int64
+}
+
+type ImportOutput struct {
+	Documents   int
+	Databases   int
+	Rows        int
+	Attachments int
+	// Warnings lists the content which could not be imported, such as the
+	// images missing from the archive
+	Warnings []string
+}
@@ -0,0 +1,325 @@
+package importer
+
+import (
+	"bytes"
+	"fmt"
+	"mime"
+	"path"
+	"strings"
+
+	attachmentDto "github.com/labbs/nexo/application/attachment/dto"
+	databaseDto "github.com/labbs/nexo/application/database/dto"
+	documentDto "github.com/labbs/nexo/application/document/dto"
+	"github.com/labbs/nexo/application/importer/dto"
+	spaceDto "github.com/labbs/nexo/application/space/dto"
+	"github.com/labbs/nexo/domain"
+	"github.com/labbs/nexo/infrastructure/helpers/apperrors"
+)
+
+// maxWarnings limits the number of warnings of an import
+const maxWarnings = 100
+
+// Import recreates the pages of an export archive as documents, its
+// databases with their rows, and uploads the files the pages reference as
+// attachments. The links between the pages are rewritten to the imported
+// documents. The import stops at the first error, the documents already
+// created are kept.
+func (a *ImporterApplication) Import(input dto.ImportInput) (*dto.ImportOutput, error) {
+	logger := a.Logger.With().Str("component", "application.importer.import").Str("source", input.Source).Logger()
+
+	spaceResult, err := a.SpaceApplication.GetSpaceById(spaceDto.GetSpaceByIdInput{SpaceId: input.SpaceId})
+	if err != nil {
+		logger.Error().Err(err).Msg("failed to get space for import")
+		return nil, fmt.Errorf("failed to get space for import: %w", err)
+	}
+	if input.ParentId == nil && !spaceResult.Space.HasPermission(input.UserId, "editor") {
+		return nil, apperrors.ErrAccessDenied
+	}
+
+	archive, err := openArchive(input.Archive, input.Size)
+	if err != nil {
+		return nil, err
+	}
+	var src *source
+	switch input.Source {
+	case dto.SourceNotion:
+		src, err = parseNotion(archive)
+	case dto.SourceConfluence:
+		src, err = parseConfluence(archive)
+	default:
+		return nil, fmt.Errorf("%w: unsupported import source %q", apperrors.ErrInvalidInput, input.Source)
+	}
+	if err != nil {
+		logger.Error().Err(err).Msg("failed to read archive")
+		return nil, err
+	}
+
+	w := &writer{
+		app:      a,
+		input:    input,
+		archive:  archive,
+		source:   src,
+		output:   &dto.ImportOutput{Warnings: []string{}},
+		uploaded: make(map[string]string),
+	}
+
+	// Documents and databases are created first, so that the links of the
+	// content can point to any of them
+	for _, p := range src.pages {
+		if err := w.createDocument(p, input.ParentId); err != nil {
+			logger.Error().Err(err).Str("file", p.file).Msg("failed to create document")
+			return w.output, err
+		}
+	}
+	for _, t := range src.databases {
+		if err := w.createDatabase(t, input.ParentId); err != nil {
+			logger.Error().Err(err).Str("file", t.file).Msg("failed to create database")
+			return w.output, err
+		}
+	}
+
+	var tables []*table
+	src.walk(func(p *page) {
+		if err == nil {
+			err = w.writeContent(p)
+			tables = append(tables, p.databases...)
+		}
+	})
+	if err != nil {
+		logger.Error().Err(err).Msg("failed to write content")
+		return w.output, err
+	}
+	for _, t := range append(tables, src.databases...) {
+		if err := w.writeRows(t); err != nil {
+			logger.Error().Err(err).Str("file", t.file).Msg("failed to create rows")
+			return w.output, err
+		}
+	}
+
+	logger.Info().Int("documents", w.output.Documents).Int("databases", w.output.Databases).Int("rows", w.output.Rows).
+		Int("attachments", w.output.Attachments).Int("warnings", len(w.output.Warnings)).Msg("import completed")
+	return w.output, nil
+}
+
+// writer creates the content of a source
+type writer struct {
+	app     *ImporterApplication
+	input   dto.ImportInput
+	archive *archive
+	source  *source
+	output  *dto.ImportOutput
+	// uploaded holds the urls of the uploaded files, by resource and path
+	uploaded map[string]string
+}
+
+func (w *writer) createDocument(p *page, parentId *string) error {
+	result, err := w.app.DocumentApplication.CreateDocument(documentDto.CreateDocumentInput{
+		Name:     p.title,
+		UserId:   w.input.UserId,
+		SpaceId:  w.input.SpaceId,
+		ParentId: parentId,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create document %q: %w", p.title, err)
+	}
+	p.documentId, p.documentSlug = result.Document.Id, result.Document.Slug
+	w.output.Documents++
+
+	for _, t := range p.databases {
+		if err := w.createDatabase(t, &p.documentId); err != nil {
+			return err
+		}
+	}
+	for _, child := range p.children {
+		if err := w.createDocument(child, &p.documentId); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// createDatabase creates a database with the columns of a table. The
+// databases whose rows have content are document databases.
+func (w *writer) createDatabase(t *table, documentId *string) error {
+	t.properties = inferProperties(t)
+	schema := make([]databaseDto.PropertySchema, len(t.properties))
+	for i, p := range t.properties {
+		schema[i] = p.schema
+	}
+	databaseType := domain.DatabaseTypeSpreadsheet
+	for _, p := range t.pages {
+		if len(p.blocks) > 0 {
+			databaseType = domain.DatabaseTypeDocument
+		}
+	}
+
+	result, err := w.app.DatabaseApplication.CreateDatabase(databaseDto.CreateDatabaseInput{
+		UserId:            w.input.UserId,
+		SpaceId:           w.input.SpaceId,
+		DocumentId:        documentId,
+		Name:              t.title,
+		Schema:            schema,
+		Type:              string(databaseType),
+		WithoutSampleRows: true,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create database %q: %w", t.title, err)
+	}
+	t.databaseId = result.Id
+	w.output.Databases++
+	return nil
+}
+
+func (w *writer) writeContent(p *page) error {
+	if len(p.blocks) == 0 {
+		return nil
+	}
+	blocks := w.rewrite(p.file, p.blocks, domain.AttachmentResourceDocument, p.documentId)
+	_, err := w.app.DocumentApplication.UpdateDocument(documentDto.UpdateDocumentInput{
+		UserId:     w.input.UserId,
+		SpaceId:    w.input.SpaceId,
+		DocumentId: p.documentId,
+		Content:    &blocks,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to write the content of %q: %w", p.title, err)
+	}
+	return nil
+}
+
+// writeRows creates the rows of a database, the content of a row is stored
+// as {"blocks": [...]}.
+func (w *writer) writeRows(t *table) error {
+	for i, record := range t.records {
+		properties := make(map[string]any)
+		for c, value := range record {
+			if c < len(t.properties) && strings.TrimSpace(value) != "" {
+				properties[t.properties[c].schema.Id] = t.properties[c].convert(strings.TrimSpace(value))
+			}
+		}
+		var content map[string]any
+		if p := t.pages[i]; p != nil && len(p.blocks) > 0 {
+			content = map[string]any{"blocks": w.rewrite(p.file, p.blocks, domain.AttachmentResourceDatabase, t.databaseId)}
+		}
+
+		if _, err := w.app.DatabaseApplication.CreateRow(databaseDto.CreateRowInput{
+			UserId:     w.input.UserId,
+			DatabaseId: t.databaseId,
+			Properties: properties,
+			Content:    content,
+		}); err != nil {
+			return fmt.Errorf("failed to create a row of %q: %w", t.title, err)
+		}
+		w.output.Rows++
+	}
+	return nil
+}
+
+// rewrite points the links of blocks to the imported documents, and the
+// files of the archive they reference to uploaded attachments.
+func (w *writer) rewrite(file string, blocks []documentDto.Block, resourceType domain.AttachmentResourceType, resourceId string) []documentDto.Block {
+	result := make([]documentDto.Block, len(blocks))
+	for i, block := range blocks {
+		block.Content = w.rewriteInline(file, block.Content, resourceType, resourceId)
+		if block.TableContent != nil {
+			tableContent := *block.TableContent
+			tableContent.Rows = make([]documentDto.TableRow, len(block.TableContent.Rows))
+			for r, row := range block.TableContent.Rows {
+				cells := make([]documentDto.TableCell, len(row.Cells))
+				for c, cell := range row.Cells {
+					cell.Content = w.rewriteInline(file, cell.Content, resourceType, resourceId)
+					cells[c] = cell
+				}
+				tableContent.Rows[r] = documentDto.TableRow{Cells: cells}
+			}
+			block.TableContent = &tableContent
+		}
+
+		switch block.Type {
+		case documentDto.BlockTypeImage, documentDto.BlockTypeVideo, documentDto.BlockTypeAudio, documentDto.BlockTypeFile:
+			if url, _ := block.Props["url"].(string); url != "" {
+				if uploaded, name := w.upload(file, url, resourceType, resourceId); uploaded != "" {
+					block.Props["url"] = uploaded
+					if current, _ := block.Props["name"].(string); current == "" {
+						block.Props["name"] = name
+					}
+				}
+			}
+		}
+
+		block.Children = w.rewrite(file, block.Children, resourceType, resourceId)
+		result[i] = block
+	}
+	return result
+}
+
+func (w *writer) rewriteInline(file string, content []documentDto.InlineContent, resourceType domain.AttachmentResourceType, resourceId string) []documentDto.InlineContent {
+	result := make([]documentDto.InlineContent, len(content))
+	for i, inline := range content {
+		if inline.Href != "" {
+			if target := w.source.find(file, inline.Href); target != nil {
+				inline.Href = documentLink(w.input.SpaceId, target)
+			} else if uploaded, _ := w.upload(file, inline.Href, resourceType, resourceId); uploaded != "" {
+				inline.Href = uploaded
+			}
+		}
+		result[i] = inline
+	}
+	return result
+}
+
+// upload uploads the file of the archive a relative url of a page points
+// to, once per resource, and returns the url and the name of the attachment.
+// Missing files are reported in the warnings.
+func (w *writer) upload(file, url string, resourceType domain.AttachmentResourceType, resourceId string) (string, string) {
+	target, ok := resolvePath(file, url)
+	if !ok {
+		return "", ""
+	}
+	name := path.Base(target)
+	key := string(resourceType) + ":" + resourceId + ":" + target
+	if uploaded, found := w.uploaded[key]; found {
+		return uploaded, name
+	}
+
+	data, err := w.archive.read(target)
+	if err != nil {
+		w.warn("%s: %s is not in the archive", file, target)
+		w.uploaded[key] = ""
+		return "", ""
+	}
+	contentType := mime.TypeByExtension(path.Ext(name))
+	result, err := w.app.AttachmentApplication.Upload(attachmentDto.UploadInput{
+		UserId:       w.input.UserId,
+		ResourceType: string(resourceType),
+		ResourceId:   resourceId,
+		Name:         name,
+		ContentType:  contentType,
+		Size:         int64(len(data)),
+		Content:      bytes.NewReader(data),
+	})
+	if err != nil {
+		w.warn("%s: failed to upload %s: %v", file, target, err)
+		w.uploaded[key] = ""
+		return "", ""
+	}
+	w.output.Attachments++
+	w.uploaded[key] = attachmentURL(result.Attachment.Id)
+	return w.uploaded[key], name
+}
+
+func (w *writer) warn(format string, args ...any) {
+	if len(w.output.Warnings) < maxWarnings {
+		w.output.Warnings = append(w.output.Warnings, fmt.Sprintf(format, args...))
+	}
+}
+
+// documentLink is the link of the application to an imported document
+func documentLink(spaceId string, p *page) string {
+	return "/space/" + spaceId + "/" + p.documentSlug
+}
+
+// attachmentURL is the download url of an attachment
+func attachmentURL(id string) string {
+	return "/api/v1/attachment/" + id + "/content"
+}
@@ -0,0 +1,25 @@
+package importer
+
+import (
+	"github.com/labbs/nexo/application/ports"
+	"github.com/labbs/nexo/infrastructure/config"
+	"github.com/rs/zerolog"
+)
+
+// ImporterApplication imports the export archives of other wikis (Notion,
+// Confluence) as documents, databases and attachments of a space.
+type ImporterApplication struct {
+	Config                config.Config
+	Logger                zerolog.Logger
+	SpaceApplication      ports.SpacePort
+	DocumentApplication   ports.DocumentPort
+	DatabaseApplication   ports.DatabasePort
+	AttachmentApplication ports.AttachmentPort
+}
+
+func NewImporterApplication(config config.Config, logger zerolog.Logger) *ImporterApplication {
+	return &ImporterApplication{
+		Config: config,
+		Logger: logger,
+	}
+}
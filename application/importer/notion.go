@@ -0,0 +1,280 @@
+package importer
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+
+	documentDto "github.com/labbs/nexo/application/document/dto"
+	"github.com/labbs/nexo/application/document/markdown"
+	"github.com/labbs/nexo/infrastructure/helpers/apperrors"
+)
+
+var (
+	// notionFileId is the id Notion appends to the names of the exported
+	// files, e.g. "Roadmap 0a1b2c3d4e5f60718293a4b5c6d7e8f9.md"
+	notionFileId = regexp.MustCompile(`\s+([0-9a-f]{32})$`)
+	// notionURLId is the id of a page in a notion.so link
+	notionURLId = regexp.MustCompile(`(?:^|[-/])([0-9a-f]{32}|[0-9a-f]{8}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{12})(?:[?#]|$)`)
+	// notionProperty is a property line written under the title of a row page
+	notionProperty = regexp.MustCompile(`^([^:]+):\s?(.*)$`)
+)
+
+// parseNotion reads a Notion export, "Markdown & CSV" format. A page is a
+// markdown file and its sub pages are in the folder of the same name. A
+// database is a CSV file, the pages of its rows are in its folder.
+func parseNotion(a *archive) (*source, error) {
+	src := &source{keys: make(map[string]*page), linkId: notionLinkId}
+
+	pages := make(map[string]*page)
+	tables := make(map[string]*table)
+	paths := a.paths()
+
+	// Newer exports write each database twice, the "_all" file has all the
+	// rows and the other only the rows of the default view
+	for _, name := range paths {
+		if strings.EqualFold(path.Ext(name), ".csv") {
+			key := strings.TrimSuffix(strings.TrimSuffix(name, path.Ext(name)), "_all")
+			if _, found := tables[key]; found && !strings.HasSuffix(strings.TrimSuffix(name, path.Ext(name)), "_all") {
+				continue
+			}
+			t, err := notionTable(a, name)
+			if err != nil {
+				return nil, err
+			}
+			tables[key] = t
+		}
+	}
+	for _, name := range paths {
+		if strings.EqualFold(path.Ext(name), ".md") {
+			p := &page{file: name, title: notionTitle(name)}
+			pages[strings.TrimSuffix(name, path.Ext(name))] = p
+			src.keys[name] = p
+			if m := notionFileId.FindStringSubmatch(strings.TrimSuffix(path.Base(name), path.Ext(name))); m != nil {
+				src.keys[m[1]] = p
+			}
+		}
+	}
+
+	// parent returns the page or the database of the folder of a file
+	parent := func(name string) (*page, *table) {
+		for dir := path.Dir(name); dir != "."; dir = path.Dir(dir) {
+			if p := pages[dir]; p != nil {
+				return p, nil
+			}
+			if t := tables[dir]; t != nil {
+				return nil, t
+			}
+		}
+		return nil, nil
+	}
+	tableParent := make(map[*table]*page)
+	rowTable := make(map[*page]*table)
+
+	for _, key := range sortedKeys(tables) {
+		t := tables[key]
+		p, _ := parent(t.file)
+		tableParent[t] = p
+		if p == nil {
+			src.databases = append(src.databases, t)
+		} else {
+			// The links to a database point to the page it is in
+			p.databases = append(p.databases, t)
+			src.keys[t.file] = p
+			src.keys[key+path.Ext(t.file)] = p
+		}
+	}
+
+	for _, key := range sortedKeys(pages) {
+		p := pages[key]
+		owner, t := parent(p.file)
+		if t != nil {
+			rowTable[p] = t
+		}
+		if err := notionContent(a, p, t); err != nil {
+			return nil, err
+		}
+		if t != nil && t.match(p) {
+			continue
+		}
+
+		// Sub pages of a row, or rows without a record, go under the page of
+		// the database
+		for t := rowTable[owner]; owner != nil && t != nil; t = rowTable[owner] {
+			owner = tableParent[t]
+		}
+		if t != nil && owner == nil {
+			owner = tableParent[t]
+		}
+		if owner == nil {
+			src.pages = append(src.pages, p)
+		} else {
+			owner.children = append(owner.children, p)
+		}
+	}
+
+	// The sub pages are in the order of the links to them in their page
+	order(src.pages, nil)
+	src.walk(func(p *page) { order(p.children, p) })
+
+	if len(src.pages) == 0 && len(src.databases) == 0 {
+		return nil, fmt.Errorf("%w: no Notion page found in the archive", apperrors.ErrInvalidInput)
+	}
+	return src, nil
+}
+
+// notionTitle returns the title of a page from its file name
+func notionTitle(name string) string {
+	base := strings.TrimSuffix(path.Base(name), path.Ext(name))
+	base = strings.TrimSuffix(notionFileId.ReplaceAllString(base, ""), "_all")
+	return strings.TrimSpace(base)
+}
+
+// notionContent reads the markdown of a page. The title of the page is its
+// first heading, and the pages of the rows of a database list the values of
+// the row under it, which are dropped.
+func notionContent(a *archive, p *page, t *table) error {
+	data, err := a.read(p.file)
+	if err != nil {
+		return err
+	}
+	lines := strings.Split(strings.ReplaceAll(string(bytes.TrimPrefix(data, []byte("\ufeff"))), "\r\n", "\n"), "\n")
+
+	i := 0
+	for i < len(lines) && strings.TrimSpace(lines[i]) == "" {
+		i++
+	}
+	if i < len(lines) && strings.HasPrefix(lines[i], "# ") {
+		p.title = strings.TrimSpace(strings.TrimPrefix(lines[i], "# "))
+		i++
+	}
+	if t != nil {
+		columns := make(map[string]bool, len(t.header))
+		for _, column := range t.header {
+			columns[column] = true
+		}
+		for i < len(lines) && strings.TrimSpace(lines[i]) == "" {
+			i++
+		}
+		for i < len(lines) {
+			m := notionProperty.FindStringSubmatch(lines[i])
+			if m == nil || !columns[m[1]] {
+				break
+			}
+			i++
+		}
+	}
+	blocks, err := markdown.Parse(strings.Join(lines[i:], "\n"))
+	if err != nil {
+		return fmt.Errorf("failed to parse %s: %w", p.file, err)
+	}
+	p.blocks = blocks
+	return nil
+}
+
+func notionTable(a *archive, name string) (*table, error) {
+	data, err := a.read(name)
+	if err != nil {
+		return nil, err
+	}
+	reader := csv.NewReader(bytes.NewReader(bytes.TrimPrefix(data, []byte("\ufeff"))))
+	reader.FieldsPerRecord = -1
+	reader.LazyQuotes = true
+	records, err := reader.ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("%w: failed to read %s: %v", apperrors.ErrInvalidInput, name, err)
+	}
+
+	t := &table{title: notionTitle(name), file: name, pages: make(map[int]*page)}
+	if len(records) > 0 {
+		t.header, t.records = records[0], records[1:]
+	}
+	if len(t.header) == 0 {
+		t.header = []string{"Name"}
+	}
+	return t, nil
+}
+
+// match links a page to the first row of the database with its title, and
+// reports whether there was one.
+func (t *table) match(p *page) bool {
+	for i, record := range t.records {
+		if _, taken := t.pages[i]; !taken && len(record) > 0 && strings.TrimSpace(record[0]) == p.title {
+			t.pages[i] = p
+			return true
+		}
+	}
+	return false
+}
+
+// order sorts pages by the position of the first link to each of them in
+// the content of their parent, then by title.
+func order(pages []*page, parent *page) {
+	position := make(map[*page]int)
+	if parent != nil {
+		n := 0
+		var visit func(blocks []documentDto.Block)
+		visit = func(blocks []documentDto.Block) {
+			for _, block := range blocks {
+				for _, inline := range block.Content {
+					if target := findSibling(pages, parent.file, inline.Href); target != nil {
+						if _, found := position[target]; !found {
+							position[target] = n
+							n++
+						}
+					}
+				}
+				visit(block.Children)
+			}
+		}
+		visit(parent.blocks)
+	}
+	sort.SliceStable(pages, func(i, j int) bool {
+		pi, iLinked := position[pages[i]]
+		pj, jLinked := position[pages[j]]
+		switch {
+		case iLinked && jLinked:
+			return pi < pj
+		case iLinked != jLinked:
+			return iLinked
+		}
+		return strings.ToLower(pages[i].title) < strings.ToLower(pages[j].title)
+	})
+}
+
+func findSibling(pages []*page, file, href string) *page {
+	if href == "" {
+		return nil
+	}
+	target, ok := resolvePath(file, href)
+	for _, p := range pages {
+		if ok && p.file == target {
+			return p
+		}
+	}
+	return nil
+}
+
+// notionLinkId returns the id of the page of a notion.so link
+func notionLinkId(href string) string {
+	if !strings.Contains(href, "notion.so/") && !strings.Contains(href, "notion.site/") {
+		return ""
+	}
+	if m := notionURLId.FindStringSubmatch(href); m != nil {
+		return strings.ReplaceAll(m[1], "-", "")
+	}
+	return ""
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,198 @@
+package importer
+
+import (
+	"net/mail"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gosimple/slug"
+	databaseDto "github.com/labbs/nexo/application/database/dto"
+	"github.com/labbs/nexo/domain"
+)
+
+// A column is a select when it has at most maxSelectOptions distinct
+// values, of at most maxOptionLength characters
+const (
+	maxSelectOptions = 30
+	maxOptionLength  = 40
+)
+
+var (
+	urlValue = regexp.MustCompile(`^https?://\S+$`)
+	// dateLayouts are the formats of the dates of the Notion and the usual
+	// CSV exports
+	dateLayouts = []string{
+		time.RFC3339,
+		"2006-01-02 15:04:05",
+		"2006-01-02T15:04",
+		"January 2, 2006 3:04 PM",
+		"January 2, 2006 15:04",
+		"2006/01/02 3:04 PM",
+		"2006-01-02",
+		"January 2, 2006",
+		"Jan 2, 2006",
+		"2006/01/02",
+	}
+)
+
+// property is a column of an imported database, with the conversion of
+// its values to the values of the rows
+type property struct {
+	schema  databaseDto.PropertySchema
+	convert func(value string) any
+}
+
+// inferProperties guesses the type of the columns of a database from their
+// values. The first column is the title of the rows.
+func inferProperties(t *table) []property {
+	properties := make([]property, len(t.header))
+	ids := make(map[string]bool)
+	for c, name := range t.header {
+		id := slug.Make(name)
+		if id == "" {
+			id = "property"
+		}
+		for base, n := id, 2; ids[id]; n++ {
+			id = base + "-" + strconv.Itoa(n)
+		}
+		ids[id] = true
+
+		var values []string
+		for _, record := range t.records {
+			if c < len(record) && strings.TrimSpace(record[c]) != "" {
+				values = append(values, strings.TrimSpace(record[c]))
+			}
+		}
+
+		p := property{schema: databaseDto.PropertySchema{Id: id, Name: name}}
+		if c == 0 {
+			p.schema.Type = string(domain.PropertyTypeTitle)
+			p.convert = func(value string) any { return value }
+		} else {
+			p.schema.Type, p.schema.Options, p.convert = inferType(values)
+		}
+		properties[c] = p
+	}
+	return properties
+}
+
+func inferType(values []string) (string, map[string]any, func(string) any) {
+	text := func(value string) any { return value }
+	if len(values) == 0 {
+		return string(domain.PropertyTypeText), nil, text
+	}
+
+	switch {
+	case all(values, func(v string) bool { return v == "Yes" || v == "No" }):
+		return string(domain.PropertyTypeCheckbox), nil, func(value string) any { return value == "Yes" }
+
+	case all(values, func(v string) bool { _, ok := parseNumber(v); return ok }):
+		return string(domain.PropertyTypeNumber), nil, func(value string) any {
+			number, _ := parseNumber(value)
+			return number
+		}
+
+	case all(values, func(v string) bool { _, ok := parseDate(v); return ok }):
+		return string(domain.PropertyTypeDate), nil, func(value string) any {
+			date, _ := parseDate(value)
+			return date
+		}
+
+	case all(values, urlValue.MatchString):
+		return string(domain.PropertyTypeUrl), nil, text
+
+	case all(values, isEmail):
+		return string(domain.PropertyTypeEmail), nil, text
+	}
+
+	// Columns of short values which repeat are selects, multi selects when
+	// values list several of them (Notion separates them with commas)
+	distinct := make(map[string]bool)
+	var options []string
+	items, multiple, short := 0, false, true
+	for _, value := range values {
+		list := splitOptions(value)
+		multiple = multiple || len(list) > 1
+		for _, item := range list {
+			items++
+			short = short && len([]rune(item)) <= maxOptionLength && !strings.Contains(item, "\n")
+			if !distinct[item] {
+				distinct[item] = true
+				options = append(options, item)
+			}
+		}
+	}
+	if !short || len(options) > maxSelectOptions || len(options) == items {
+		return string(domain.PropertyTypeText), nil, text
+	}
+
+	choices := make([]any, len(options))
+	for i, option := range options {
+		choices[i] = map[string]any{"id": option, "name": option, "color": "default"}
+	}
+	if multiple {
+		return string(domain.PropertyTypeMultiSelect), map[string]any{"options": choices}, func(value string) any {
+			items := splitOptions(value)
+			list := make([]any, len(items))
+			for i, item := range items {
+				list[i] = item
+			}
+			return list
+		}
+	}
+	return string(domain.PropertyTypeSelect), map[string]any{"options": choices}, text
+}
+
+func all(values []string, match func(string) bool) bool {
+	for _, value := range values {
+		if !match(value) {
+			return false
+		}
+	}
+	return true
+}
+
+func splitOptions(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+func parseNumber(value string) (float64, bool) {
+	value = strings.ReplaceAll(value, ",", "")
+	if strings.HasSuffix(value, "%") {
+		number, err := strconv.ParseFloat(strings.TrimSuffix(value, "%"), 64)
+		return number / 100, err == nil
+	}
+	number, err := strconv.ParseFloat(value, 64)
+	return number, err == nil
+}
+
+// parseDate converts a date to the format of the date properties: the day,
+// or RFC 3339 with a time. The start of a range is kept.
+func parseDate(value string) (string, bool) {
+	value, _, _ = strings.Cut(value, " → ")
+	value = strings.TrimSpace(value)
+	for _, layout := range dateLayouts {
+		date, err := time.Parse(layout, value)
+		if err != nil {
+			continue
+		}
+		if strings.Contains(layout, "04") {
+			return date.Format(time.RFC3339), true
+		}
+		return date.Format("2006-01-02"), true
+	}
+	return "", false
+}
+
+func isEmail(value string) bool {
+	address, err := mail.ParseAddress(value)
+	return err == nil && address.Address == value
+}
@@ -0,0 +1,89 @@
+package importer
+
+import (
+	"net/url"
+	"path"
+	"strings"
+
+	documentDto "github.com/labbs/nexo/application/document/dto"
+)
+
+// page is a page of the imported wiki, with the pages and the databases
+// under it.
+type page struct {
+	title string
+	// file is the path of the page in the archive, the relative links of its
+	// content are resolved from it
+	file   string
+	blocks []documentDto.Block
+
+	children  []*page
+	databases []*table
+
+	documentId   string
+	documentSlug string
+}
+
+// table is a database of the imported wiki, its first column is the title
+// of its rows. The rows may have a page with their content.
+type table struct {
+	title   string
+	file    string
+	header  []string
+	records [][]string
+	pages   map[int]*page
+
+	databaseId string
+	properties []property
+}
+
+// source is the tree of pages and databases of an archive
+type source struct {
+	pages     []*page
+	databases []*table
+
+	// keys holds the pages by path in the archive and by id in the wiki
+	keys map[string]*page
+	// linkId returns the id of the page a link of the wiki points to, or ""
+	linkId func(href string) string
+}
+
+// find returns the page a link of a page points to
+func (s *source) find(file, href string) *page {
+	if target, ok := resolvePath(file, href); ok {
+		if p := s.keys[target]; p != nil {
+			return p
+		}
+	}
+	if id := s.linkId(href); id != "" {
+		return s.keys[id]
+	}
+	return nil
+}
+
+func (s *source) walk(visit func(p *page)) {
+	var walk func(pages []*page)
+	walk = func(pages []*page) {
+		for _, p := range pages {
+			visit(p)
+			walk(p.children)
+		}
+	}
+	walk(s.pages)
+}
+
+// resolvePath returns the path in the archive of a relative link of a file
+func resolvePath(file, href string) (string, bool) {
+	if href == "" || strings.HasPrefix(href, "#") || strings.HasPrefix(href, "/") {
+		return "", false
+	}
+	if scheme, _, found := strings.Cut(href, ":"); found && !strings.ContainsAny(scheme, "/?#") {
+		return "", false
+	}
+	href, _, _ = strings.Cut(href, "#")
+	href, _, _ = strings.Cut(href, "?")
+	if unescaped, err := url.PathUnescape(href); err == nil {
+		href = unescaped
+	}
+	return cleanPath(path.Join(path.Dir(file), href)), true
+}
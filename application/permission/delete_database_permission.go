@@ -25,7 +25,7 @@ func (app *PermissionApplication) DeleteDatabasePermission(input databaseDto.Del
 	}
 
 	spaceRole := spaceResult.Space.GetUserRole(input.UserId)
-	if spaceRole == nil {
+	if !spaceResult.Space.IsMember(input.UserId) {
 		return apperrors.ErrAccessDenied
 	}
 
@@ -51,5 +51,6 @@ func (app *PermissionApplication) DeleteDatabasePermission(input databaseDto.Del
 		}
 	}
 
+	app.recheckCollaborationAccess(domain.PermissionTypeDatabase, input.DatabaseId)
 	return nil
 }
@@ -32,5 +32,10 @@ func (app *PermissionApplication) DeleteDocumentUserPermission(input documentDto
 		return fmt.Errorf("cannot_remove_owner")
 	}
 
-	return app.PermissionPers.DeleteUser(domain.PermissionTypeDocument, input.DocumentId, input.TargetUserId)
+	if err := app.PermissionPers.DeleteUser(domain.PermissionTypeDocument, input.DocumentId, input.TargetUserId); err != nil {
+		return err
+	}
+
+	app.recheckCollaborationAccess(domain.PermissionTypeDocument, input.DocumentId)
+	return nil
 }
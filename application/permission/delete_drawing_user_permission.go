@@ -36,5 +36,10 @@ func (app *PermissionApplication) DeleteDrawingUserPermission(input drawingDto.D
 		return fmt.Errorf("cannot_remove_owner")
 	}
 
-	return app.PermissionPers.DeleteUser(domain.PermissionTypeDrawing, input.DrawingId, input.TargetUserId)
+	if err := app.PermissionPers.DeleteUser(domain.PermissionTypeDrawing, input.DrawingId, input.TargetUserId); err != nil {
+		return err
+	}
+
+	app.recheckCollaborationAccess(domain.PermissionTypeDrawing, input.DrawingId)
+	return nil
 }
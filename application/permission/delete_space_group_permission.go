@@ -21,5 +21,10 @@ func (app *PermissionApplication) DeleteSpaceGroupPermission(input spaceDto.Dele
 		return apperrors.ErrForbidden
 	}
 
-	return app.PermissionPers.DeleteGroup(domain.PermissionTypeSpace, input.SpaceId, input.GroupId)
+	if err := app.PermissionPers.DeleteGroup(domain.PermissionTypeSpace, input.SpaceId, input.GroupId); err != nil {
+		return err
+	}
+
+	app.recheckCollaborationAccess(domain.PermissionTypeSpace, input.SpaceId)
+	return nil
 }
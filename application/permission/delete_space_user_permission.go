@@ -25,5 +25,10 @@ func (app *PermissionApplication) DeleteSpaceUserPermission(input spaceDto.Delet
 		return fmt.Errorf("cannot_remove_owner")
 	}
 
-	return app.PermissionPers.DeleteUser(domain.PermissionTypeSpace, input.SpaceId, input.TargetUserId)
+	if err := app.PermissionPers.DeleteUser(domain.PermissionTypeSpace, input.SpaceId, input.TargetUserId); err != nil {
+		return err
+	}
+
+	app.recheckCollaborationAccess(domain.PermissionTypeSpace, input.SpaceId)
+	return nil
 }
@@ -0,0 +1,20 @@
+package dto
+
+import "github.com/labbs/nexo/domain"
+
+type GetEffectivePermissionInput struct {
+	RequesterId string
+	// RequesterIsAdmin is set for the administrators of the instance, who can
+	// explain the permissions of any user
+	RequesterIsAdmin bool
+	ResourceType     string
+	ResourceId       string
+	// UserId is the user to explain the permissions of, the requester by default
+	UserId string
+}
+
+type GetEffectivePermissionOutput struct {
+	UserId     string
+	SpaceId    string
+	Permission domain.EffectivePermission
+}
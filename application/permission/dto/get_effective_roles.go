@@ -0,0 +1,19 @@
+package dto
+
+import "github.com/labbs/nexo/domain"
+
+type GetEffectiveRolesInput struct {
+	UserId string
+	// ResourceType is database or drawing, the resources inheriting the role
+	// of their space
+	ResourceType string
+	SpaceId      string
+	ResourceIds  []string
+}
+
+type GetEffectiveRolesOutput struct {
+	// SpaceRole is the role of the user in the space, nil without access
+	SpaceRole *domain.PermissionRole
+	// Roles maps each resource id to the role of the user, nil without access
+	Roles map[string]*domain.PermissionRole
+}
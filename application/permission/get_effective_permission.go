@@ -0,0 +1,99 @@
+package permission
+
+import (
+	"fmt"
+
+	databaseDto "github.com/labbs/nexo/application/database/dto"
+	documentDto "github.com/labbs/nexo/application/document/dto"
+	drawingDto "github.com/labbs/nexo/application/drawing/dto"
+	"github.com/labbs/nexo/application/permission/dto"
+	spaceDto "github.com/labbs/nexo/application/space/dto"
+	"github.com/labbs/nexo/domain"
+	"github.com/labbs/nexo/infrastructure/helpers/apperrors"
+)
+
+// GetEffectivePermission returns the role of a user on a resource with the
+// chain of grants it results from. Users can explain their own permissions,
+// explaining those of another user requires to be admin of the resource or
+// of its space.
+func (app *PermissionApplication) GetEffectivePermission(input dto.GetEffectivePermissionInput) (*dto.GetEffectivePermissionOutput, error) {
+	logger := app.Logger.With().Str("component", "application.permission.get_effective_permission").Logger()
+
+	userId := input.UserId
+	if userId == "" {
+		userId = input.RequesterId
+	}
+	resourceType := domain.PermissionType(input.ResourceType)
+
+	effective, spaceId, err := app.resolveEffectivePermission(resourceType, input.ResourceId, userId)
+	if err != nil {
+		logger.Error().Err(err).Str("resource_type", input.ResourceType).Str("resource_id", input.ResourceId).Msg("failed to resolve effective permission")
+		return nil, err
+	}
+
+	if userId != input.RequesterId && !input.RequesterIsAdmin {
+		requester, _, err := app.resolveEffectivePermission(resourceType, input.ResourceId, input.RequesterId)
+		if err != nil {
+			return nil, err
+		}
+		if requester.Role == nil || !requester.Role.Includes(domain.PermissionRoleAdmin) {
+			spaceResult, err := app.SpaceApplication.GetSpaceById(spaceDto.GetSpaceByIdInput{SpaceId: spaceId})
+			if err != nil {
+				return nil, err
+			}
+			if !spaceResult.Space.HasPermission(input.RequesterId, string(domain.PermissionRoleAdmin)) {
+				return nil, apperrors.ErrForbidden
+			}
+		}
+	}
+
+	return &dto.GetEffectivePermissionOutput{UserId: userId, SpaceId: spaceId, Permission: effective}, nil
+}
+
+// resolveEffectivePermission returns the effective permission of a user on a
+// resource and the space of the resource. Databases and drawings have the
+// role their permissions give, else the role of the user in their space.
+func (app *PermissionApplication) resolveEffectivePermission(resourceType domain.PermissionType, resourceId, userId string) (domain.EffectivePermission, string, error) {
+	var spaceId string
+	switch resourceType {
+	case domain.PermissionTypeSpace:
+		result, err := app.SpaceApplication.GetEffectivePermission(spaceDto.GetEffectivePermissionInput{SpaceId: resourceId, UserId: userId})
+		if err != nil {
+			return domain.EffectivePermission{}, "", err
+		}
+		return result.Permission, resourceId, nil
+	case domain.PermissionTypeDocument:
+		result, err := app.DocumentApplication.GetEffectivePermission(documentDto.GetEffectivePermissionInput{DocumentId: resourceId, UserId: userId})
+		if err != nil {
+			return domain.EffectivePermission{}, "", err
+		}
+		return result.Permission, result.SpaceId, nil
+	case domain.PermissionTypeDatabase:
+		result, err := app.DatabaseApplication.GetDatabaseById(databaseDto.GetDatabaseByIdInput{DatabaseId: resourceId})
+		if err != nil {
+			return domain.EffectivePermission{}, "", err
+		}
+		spaceId = result.Database.SpaceId
+	case domain.PermissionTypeDrawing:
+		result, err := app.DrawingApplication.GetDrawingById(drawingDto.GetDrawingByIdInput{DrawingId: resourceId})
+		if err != nil {
+			return domain.EffectivePermission{}, "", err
+		}
+		spaceId = result.Drawing.SpaceId
+	default:
+		return domain.EffectivePermission{}, "", fmt.Errorf("%w: unknown resource type %q", apperrors.ErrInvalidInput, resourceType)
+	}
+
+	permissions, err := app.PermissionPers.ListByResourceForUser(resourceType, resourceId, userId)
+	if err != nil {
+		return domain.EffectivePermission{}, "", err
+	}
+	space, err := app.SpaceApplication.GetEffectivePermission(spaceDto.GetEffectivePermissionInput{SpaceId: spaceId, UserId: userId})
+	if err != nil {
+		return domain.EffectivePermission{}, "", err
+	}
+
+	effective := domain.ExplainPermissions(permissions, userId)
+	effective.Inherit(space.Permission, domain.PermissionGrantSourceSpace, domain.PermissionTypeSpace, spaceId, space.Permission.Role)
+	return effective, spaceId, nil
+}
@@ -0,0 +1,45 @@
+package permission
+
+import (
+	"fmt"
+
+	"github.com/labbs/nexo/application/permission/dto"
+	spaceDto "github.com/labbs/nexo/application/space/dto"
+	"github.com/labbs/nexo/domain"
+	"github.com/labbs/nexo/infrastructure/helpers/apperrors"
+)
+
+// GetEffectiveRoles returns the roles of a user on several databases or
+// drawings of a space, as resolveEffectivePermission does for one of them,
+// with a query for the space and one for the permissions of all of them.
+func (app *PermissionApplication) GetEffectiveRoles(input dto.GetEffectiveRolesInput) (*dto.GetEffectiveRolesOutput, error) {
+	resourceType := domain.PermissionType(input.ResourceType)
+	if resourceType != domain.PermissionTypeDatabase && resourceType != domain.PermissionTypeDrawing {
+		return nil, fmt.Errorf("%w: unsupported resource type %q", apperrors.ErrInvalidInput, resourceType)
+	}
+
+	space, err := app.SpaceApplication.GetEffectivePermission(spaceDto.GetEffectivePermissionInput{SpaceId: input.SpaceId, UserId: input.UserId})
+	if err != nil {
+		return nil, err
+	}
+	permissions, err := app.PermissionPers.ListByResourcesForUser(resourceType, input.ResourceIds, input.UserId)
+	if err != nil {
+		return nil, err
+	}
+
+	byResource := make(map[string][]domain.Permission)
+	for _, perm := range permissions {
+		byResource[perm.ResourceId()] = append(byResource[perm.ResourceId()], perm)
+	}
+
+	output := &dto.GetEffectiveRolesOutput{
+		SpaceRole: space.Permission.Role,
+		Roles:     make(map[string]*domain.PermissionRole, len(input.ResourceIds)),
+	}
+	for _, resourceId := range input.ResourceIds {
+		effective := domain.ExplainPermissions(byResource[resourceId], input.UserId)
+		effective.Inherit(space.Permission, domain.PermissionGrantSourceSpace, domain.PermissionTypeSpace, input.SpaceId, space.Permission.Role)
+		output.Roles[resourceId] = effective.Role
+	}
+	return output, nil
+}
@@ -23,7 +23,7 @@ func (app *PermissionApplication) ListDatabasePermissions(input databaseDto.List
 		return nil, fmt.Errorf("space not found: %w", err)
 	}
 
-	if spaceResult.Space.GetUserRole(input.UserId) == nil {
+	if !spaceResult.Space.IsMember(input.UserId) {
 		return nil, apperrors.ErrAccessDenied
 	}
 
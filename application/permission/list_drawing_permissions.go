@@ -24,7 +24,7 @@ func (app *PermissionApplication) ListDrawingPermissions(input drawingDto.ListDr
 		return nil, fmt.Errorf("space not found: %w", err)
 	}
 
-	if spaceResult.Space.GetUserRole(input.RequesterId) == nil {
+	if !spaceResult.Space.IsMember(input.RequesterId) {
 		return nil, apperrors.ErrForbidden
 	}
 
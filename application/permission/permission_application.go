@@ -15,6 +15,7 @@ type PermissionApplication struct {
 	DrawingApplication  ports.DrawingPort
 	DocumentApplication ports.DocumentPort
 	DatabaseApplication ports.DatabasePort
+	CollaborationRooms  ports.CollaborationPort
 }
 
 func NewPermissionApplication(
@@ -28,3 +29,11 @@ func NewPermissionApplication(
 		PermissionPers: permissionPers,
 	}
 }
+
+// recheckCollaborationAccess applies a permission change to the clients
+// connected to the collaboration rooms of the resource
+func (app *PermissionApplication) recheckCollaborationAccess(resourceType domain.PermissionType, resourceId string) {
+	if app.CollaborationRooms != nil {
+		app.CollaborationRooms.RecheckAccess(resourceType, resourceId)
+	}
+}
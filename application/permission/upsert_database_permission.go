@@ -25,7 +25,7 @@ func (app *PermissionApplication) UpsertDatabasePermission(input databaseDto.Ups
 	}
 
 	spaceRole := spaceResult.Space.GetUserRole(input.UserId)
-	if spaceRole == nil {
+	if !spaceResult.Space.IsMember(input.UserId) {
 		return apperrors.ErrAccessDenied
 	}
 
@@ -57,5 +57,6 @@ func (app *PermissionApplication) UpsertDatabasePermission(input databaseDto.Ups
 		}
 	}
 
+	app.recheckCollaborationAccess(domain.PermissionTypeDatabase, input.DatabaseId)
 	return nil
 }
@@ -32,5 +32,10 @@ func (app *PermissionApplication) UpsertDocumentUserPermission(input documentDto
 		return fmt.Errorf("cannot_change_owner_role")
 	}
 
-	return app.PermissionPers.UpsertUser(domain.PermissionTypeDocument, input.DocumentId, input.TargetUserId, domain.PermissionRole(input.Role))
+	if err := app.PermissionPers.UpsertUser(domain.PermissionTypeDocument, input.DocumentId, input.TargetUserId, domain.PermissionRole(input.Role)); err != nil {
+		return err
+	}
+
+	app.recheckCollaborationAccess(domain.PermissionTypeDocument, input.DocumentId)
+	return nil
 }
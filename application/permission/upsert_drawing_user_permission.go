@@ -36,5 +36,10 @@ func (app *PermissionApplication) UpsertDrawingUserPermission(input drawingDto.U
 		return fmt.Errorf("cannot_change_owner_role")
 	}
 
-	return app.PermissionPers.UpsertUser(domain.PermissionTypeDrawing, input.DrawingId, input.TargetUserId, domain.PermissionRole(input.Role))
+	if err := app.PermissionPers.UpsertUser(domain.PermissionTypeDrawing, input.DrawingId, input.TargetUserId, domain.PermissionRole(input.Role)); err != nil {
+		return err
+	}
+
+	app.recheckCollaborationAccess(domain.PermissionTypeDrawing, input.DrawingId)
+	return nil
 }
@@ -21,5 +21,10 @@ func (app *PermissionApplication) UpsertSpaceGroupPermission(input spaceDto.Upse
 		return apperrors.ErrForbidden
 	}
 
-	return app.PermissionPers.UpsertGroup(domain.PermissionTypeSpace, input.SpaceId, input.GroupId, domain.PermissionRole(input.Role))
+	if err := app.PermissionPers.UpsertGroup(domain.PermissionTypeSpace, input.SpaceId, input.GroupId, domain.PermissionRole(input.Role)); err != nil {
+		return err
+	}
+
+	app.recheckCollaborationAccess(domain.PermissionTypeSpace, input.SpaceId)
+	return nil
 }
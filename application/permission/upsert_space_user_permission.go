@@ -25,5 +25,10 @@ func (app *PermissionApplication) UpsertSpaceUserPermission(input spaceDto.Upser
 		return fmt.Errorf("cannot_change_owner_role")
 	}
 
-	return app.PermissionPers.UpsertUser(domain.PermissionTypeSpace, input.SpaceId, input.TargetUserId, domain.PermissionRole(input.Role))
+	if err := app.PermissionPers.UpsertUser(domain.PermissionTypeSpace, input.SpaceId, input.TargetUserId, domain.PermissionRole(input.Role)); err != nil {
+		return err
+	}
+
+	app.recheckCollaborationAccess(domain.PermissionTypeSpace, input.SpaceId)
+	return nil
 }
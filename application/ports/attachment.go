@@ -0,0 +1,13 @@
+package ports
+
+import (
+	"github.com/labbs/nexo/application/attachment/dto"
+)
+
+type AttachmentPort interface {
+	Upload(input dto.UploadInput) (*dto.UploadOutput, error)
+	GetAttachment(input dto.GetAttachmentInput) (*dto.GetAttachmentOutput, error)
+	GetContent(input dto.GetContentInput) (*dto.GetContentOutput, error)
+	ListAttachments(input dto.ListAttachmentsInput) (*dto.ListAttachmentsOutput, error)
+	DeleteAttachment(input dto.DeleteAttachmentInput) error
+}
@@ -0,0 +1,13 @@
+package ports
+
+import "github.com/labbs/nexo/domain"
+
+type CollaborationPort interface {
+	// Drop the Y.js state of a room whose content was replaced outside of
+	// it, and disconnect its clients, on every node
+	ResetRoom(roomId string)
+	// Check again the access of the clients of the rooms depending on the
+	// permissions of a resource, on every node, after they changed. An empty
+	// resource type matches every room.
+	RecheckAccess(resourceType domain.PermissionType, resourceId string)
+}
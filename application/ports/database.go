@@ -25,6 +25,7 @@ type DatabasePort interface {
 	ListRows(input dto.ListRowsInput) (*dto.ListRowsOutput, error)
 	GetRow(input dto.GetRowInput) (*dto.GetRowOutput, error)
 	UpdateRow(input dto.UpdateRowInput) error
+	PatchRow(input dto.PatchRowInput) (*dto.PatchRowOutput, error)
 	DeleteRow(input dto.DeleteRowInput) error
 	BulkDeleteRows(input dto.BulkDeleteRowsInput) error
 }
@@ -14,6 +14,7 @@ type DocumentPort interface {
 	DeleteDocument(input dto.DeleteDocumentInput) error
 	GetDocumentByIdOrSlugWithUserPermissions(input dto.GetDocumentByIdOrSlugWithUserPermissionsInput) (*dto.GetDocumentByIdOrSlugWithUserPermissionsOutput, error)
 	HasDocumentsInSpace(input dto.HasDocumentsInSpaceInput) (*dto.HasDocumentsInSpaceOutput, error)
+	GetEffectivePermission(input dto.GetEffectivePermissionInput) (*dto.GetEffectivePermissionOutput, error)
 
 	// Search
 	Search(input dto.SearchInput) (*dto.SearchOutput, error)
@@ -29,6 +30,9 @@ type DocumentPort interface {
 	SetPublic(input dto.SetPublicInput) error
 	GetPublicDocument(input dto.GetPublicDocumentInput) (*dto.GetPublicDocumentOutput, error)
 
+	// Permissions
+	SetBreakInheritance(input dto.SetBreakInheritanceInput) error
+
 	// Versions
 	ListVersions(input dto.ListVersionsInput) (*dto.ListVersionsOutput, error)
 	GetVersion(input dto.GetVersionInput) (*dto.GetVersionOutput, error)
@@ -41,4 +45,11 @@ type DocumentPort interface {
 	UpdateComment(input dto.UpdateCommentInput) error
 	DeleteComment(input dto.DeleteCommentInput) error
 	ResolveComment(input dto.ResolveCommentInput) error
+
+	// Import / export
+	ExportDocument(input dto.ExportDocumentInput) (*dto.ExportDocumentOutput, error)
+	ImportMarkdown(input dto.ImportMarkdownInput) (*dto.ImportMarkdownOutput, error)
+
+	// Collaboration
+	SaveCollaborationSnapshot(input dto.SaveCollaborationSnapshotInput) (*dto.SaveCollaborationSnapshotOutput, error)
 }
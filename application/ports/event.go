@@ -0,0 +1,9 @@
+package ports
+
+import (
+	"github.com/labbs/nexo/application/event/dto"
+)
+
+type EventPort interface {
+	Publish(input dto.PublishEventInput)
+}
@@ -12,6 +12,11 @@ type PermissionPort interface {
 	// Internal permission assignment (no authorization checks)
 	AssignOwnerPermission(input permissionDto.AssignOwnerPermissionInput) error
 
+	// Effective permission of a user on a resource, with the grants it results from
+	GetEffectivePermission(input permissionDto.GetEffectivePermissionInput) (*permissionDto.GetEffectivePermissionOutput, error)
+	// Roles of a user on several databases or drawings of a space
+	GetEffectiveRoles(input permissionDto.GetEffectiveRolesInput) (*permissionDto.GetEffectiveRolesOutput, error)
+
 	// Space permissions
 	ListSpacePermissions(input spaceDto.ListSpacePermissionsInput) (*spaceDto.ListSpacePermissionsOutput, error)
 	UpsertSpaceUserPermission(input spaceDto.UpsertSpaceUserPermissionInput) error
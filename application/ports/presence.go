@@ -0,0 +1,12 @@
+package ports
+
+import (
+	"github.com/labbs/nexo/domain"
+)
+
+type PresencePort interface {
+	// Users connected to a collaboration room, on every node
+	RoomPresence(roomId string) []domain.Presence
+	// Rooms with connected users, on every node
+	ActiveRooms() []domain.RoomPresence
+}
@@ -0,0 +1,16 @@
+package ports
+
+import (
+	"github.com/labbs/nexo/application/search/dto"
+)
+
+type SearchPort interface {
+	Search(input dto.SearchInput) (*dto.SearchOutput, error)
+
+	// Index maintenance, called after the resources are saved
+	IndexDocument(input dto.IndexDocumentInput)
+	IndexComment(input dto.IndexCommentInput)
+	IndexRow(input dto.IndexRowInput)
+	IndexDrawing(input dto.IndexDrawingInput)
+	RemoveFromIndex(input dto.RemoveFromIndexInput)
+}
@@ -9,6 +9,7 @@ type SpacePort interface {
 	CreateSpace(input dto.CreateSpaceInput) (*dto.CreateSpaceOutput, error)
 	GetSpacesForUser(input dto.GetSpacesForUserInput) (*dto.GetSpacesForUserOutput, error)
 	GetSpaceById(input dto.GetSpaceByIdInput) (*dto.GetSpaceByIdOutput, error)
+	GetEffectivePermission(input dto.GetEffectivePermissionInput) (*dto.GetEffectivePermissionOutput, error)
 	UpdateSpace(input dto.UpdateSpaceInput) (*dto.UpdateSpaceOutput, error)
 	DeleteSpace(input dto.DeleteSpaceInput) error
 }
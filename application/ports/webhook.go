@@ -11,5 +11,9 @@ type WebhookPort interface {
 	UpdateWebhook(input dto.UpdateWebhookInput) error
 	DeleteWebhook(input dto.DeleteWebhookInput) error
 	GetDeliveries(input dto.GetDeliveriesInput) (*dto.GetDeliveriesOutput, error)
+	Redeliver(input dto.RedeliverInput) (*dto.RedeliverOutput, error)
+	AdminRedeliver(deliveryId string) (*dto.RedeliverOutput, error)
 	TriggerWebhooks(input dto.TriggerWebhookInput)
+	SendRequest(input dto.SendRequestInput) (*dto.SendRequestOutput, error)
+	ProcessPendingDeliveries() error
 }
@@ -0,0 +1,26 @@
+package dto
+
+import "github.com/labbs/nexo/domain"
+
+type IndexDocumentInput struct {
+	Document *domain.Document
+}
+
+type IndexCommentInput struct {
+	Document *domain.Document
+	Comment  *domain.Comment
+}
+
+type IndexRowInput struct {
+	Database *domain.Database
+	Row      *domain.DatabaseRow
+}
+
+type IndexDrawingInput struct {
+	Drawing *domain.Drawing
+}
+
+type RemoveFromIndexInput struct {
+	ResourceType domain.SearchResourceType
+	ResourceIds  []string
+}
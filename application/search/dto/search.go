@@ -0,0 +1,34 @@
+package dto
+
+import "time"
+
+type SearchInput struct {
+	UserId  string
+	Query   string
+	SpaceId *string
+	Types   []string // document, row, drawing, comment (all when empty)
+	Limit   int
+	Offset  int
+}
+
+type SearchOutput struct {
+	Results []SearchResult
+}
+
+// SearchResult is a resource matching a search. The snippet is HTML escaped
+// text with the matched terms in <mark> elements.
+type SearchResult struct {
+	Type       string
+	Id         string
+	Title      string
+	Snippet    string
+	Icon       string
+	SpaceId    string
+	SpaceName  string
+	DocumentId *string // document of a document or comment
+	Slug       string  // slug of this document
+	DatabaseId *string // database of a row
+	BlockId    *string // matched block of a document, commented block of a comment
+	Rank       float64
+	UpdatedAt  time.Time
+}
@@ -0,0 +1,254 @@
+package search
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
+
+	documentDto "github.com/labbs/nexo/application/document/dto"
+	"github.com/labbs/nexo/domain"
+)
+
+// Block props holding text in BlockNote blocks (image captions, file names, ...)
+var textBlockProps = []string{"caption", "name"}
+
+// Row properties whose values are identifiers, dates or flags rather than text
+var nonTextProperties = map[domain.PropertyType]bool{
+	domain.PropertyTypeCheckbox:    true,
+	domain.PropertyTypeDate:        true,
+	domain.PropertyTypeRelation:    true,
+	domain.PropertyTypeCreatedTime: true,
+	domain.PropertyTypeUpdatedTime: true,
+	domain.PropertyTypeCreatedBy:   true,
+	domain.PropertyTypeUpdatedBy:   true,
+	domain.PropertyTypePerson:      true,
+	domain.PropertyTypeFiles:       true,
+	domain.PropertyTypeImage:       true,
+}
+
+func documentEntry(document *domain.Document) *domain.SearchEntry {
+	var blocks domain.JSONBArray
+	var content []string
+	for _, block := range flattenBlocks(documentDto.JSONToBlocks(document.Content)) {
+		text := blockText(block)
+		if text == "" {
+			continue
+		}
+		blocks = append(blocks, map[string]any{"id": block.ID, "text": text})
+		content = append(content, text)
+	}
+
+	return &domain.SearchEntry{
+		ResourceType: domain.SearchResourceDocument,
+		ResourceId:   document.Id,
+		SpaceId:      document.SpaceId,
+		DocumentId:   &document.Id,
+		Title:        document.Name,
+		Icon:         document.Config.Icon,
+		Content:      strings.Join(content, "\n"),
+		Blocks:       blocks,
+		UpdatedAt:    document.UpdatedAt,
+	}
+}
+
+func commentEntry(document *domain.Document, comment *domain.Comment) *domain.SearchEntry {
+	var blocks domain.JSONBArray
+	if comment.BlockId != nil {
+		blocks = domain.JSONBArray{map[string]any{"id": *comment.BlockId, "text": comment.Content}}
+	}
+
+	return &domain.SearchEntry{
+		ResourceType: domain.SearchResourceComment,
+		ResourceId:   comment.Id,
+		SpaceId:      document.SpaceId,
+		DocumentId:   &document.Id,
+		Title:        document.Name,
+		Icon:         document.Config.Icon,
+		Content:      comment.Content,
+		Blocks:       blocks,
+		UpdatedAt:    comment.UpdatedAt,
+	}
+}
+
+// rowEntry indexes the title property of a row as its title, and the text of
+// its other properties and of its page content as its content
+func rowEntry(database *domain.Database, row *domain.DatabaseRow) *domain.SearchEntry {
+	var title string
+	var content []string
+
+	for _, item := range database.Schema {
+		property, ok := item.(map[string]any)
+		if !ok {
+			continue
+		}
+		id, _ := property["id"].(string)
+		propertyType := domain.PropertyType(fmt.Sprint(property["type"]))
+		value, ok := row.Properties[id]
+		if !ok || nonTextProperties[propertyType] {
+			continue
+		}
+
+		text := propertyText(property, value)
+		if text == "" {
+			continue
+		}
+		if propertyType == domain.PropertyTypeTitle && title == "" {
+			title = text
+			continue
+		}
+		content = append(content, text)
+	}
+
+	content = collectText(map[string]any(row.Content), content)
+
+	return &domain.SearchEntry{
+		ResourceType: domain.SearchResourceRow,
+		ResourceId:   row.Id,
+		SpaceId:      database.SpaceId,
+		DatabaseId:   &database.Id,
+		Title:        title,
+		Icon:         database.Icon,
+		Content:      strings.Join(content, "\n"),
+		UpdatedAt:    row.UpdatedAt,
+	}
+}
+
+// drawingEntry indexes the text elements of an Excalidraw drawing
+func drawingEntry(drawing *domain.Drawing) *domain.SearchEntry {
+	var content []string
+	for _, item := range drawing.Elements {
+		element, ok := item.(map[string]any)
+		if !ok {
+			continue
+		}
+		if deleted, _ := element["isDeleted"].(bool); deleted {
+			continue
+		}
+		if text, _ := element["text"].(string); strings.TrimSpace(text) != "" {
+			content = append(content, text)
+		}
+	}
+
+	return &domain.SearchEntry{
+		ResourceType: domain.SearchResourceDrawing,
+		ResourceId:   drawing.Id,
+		SpaceId:      drawing.SpaceId,
+		Title:        drawing.Name,
+		Icon:         drawing.Icon,
+		Content:      strings.Join(content, "\n"),
+		UpdatedAt:    drawing.UpdatedAt,
+	}
+}
+
+// flattenBlocks lists the blocks of a tree, parents before their children
+func flattenBlocks(blocks []documentDto.Block) []documentDto.Block {
+	var flat []documentDto.Block
+	for _, block := range blocks {
+		flat = append(flat, block)
+		flat = append(flat, flattenBlocks(block.Children)...)
+	}
+	return flat
+}
+
+// blockText returns the plain text of a block, without its children
+func blockText(block documentDto.Block) string {
+	var b strings.Builder
+	for _, inline := range block.Content {
+		b.WriteString(inline.Text)
+	}
+	if block.TableContent != nil {
+		for _, row := range block.TableContent.Rows {
+			for _, cell := range row.Cells {
+				if b.Len() > 0 {
+					b.WriteString(" ")
+				}
+				for _, inline := range cell.Content {
+					b.WriteString(inline.Text)
+				}
+			}
+		}
+	}
+	for _, prop := range textBlockProps {
+		if text, _ := block.Props[prop].(string); text != "" {
+			if b.Len() > 0 {
+				b.WriteString(" ")
+			}
+			b.WriteString(text)
+		}
+	}
+	return strings.TrimSpace(b.String())
+}
+
+// collectText gathers the "text" fields of the page content of a row
+func collectText(value any, texts []string) []string {
+	switch v := value.(type) {
+	case map[string]any:
+		if text, ok := v["text"].(string); ok && strings.TrimSpace(text) != "" {
+			texts = append(texts, strings.TrimSpace(text))
+		}
+		for _, key := range slices.Sorted(maps.Keys(v)) {
+			if key != "text" {
+				texts = collectText(v[key], texts)
+			}
+		}
+	case []any:
+		for _, item := range v {
+			texts = collectText(item, texts)
+		}
+	}
+	return texts
+}
+
+// propertyText returns the text of a property value, with the names of the
+// chosen options for select properties
+func propertyText(property map[string]any, value any) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return strings.TrimSpace(optionName(property, v))
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case bool:
+		return ""
+	case []any:
+		var parts []string
+		for _, item := range v {
+			if text := propertyText(property, item); text != "" {
+				parts = append(parts, text)
+			}
+		}
+		return strings.Join(parts, " ")
+	case map[string]any:
+		// Options stored as objects
+		for _, key := range []string{"name", "label", "value"} {
+			if text, ok := v[key].(string); ok {
+				return strings.TrimSpace(text)
+			}
+		}
+		return ""
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// optionName returns the name of the option with the given id, or the value
+// itself when it is not an option id
+func optionName(property map[string]any, value string) string {
+	options, _ := property["options"].(map[string]any)
+	choices, _ := options["options"].([]any)
+	for _, item := range choices {
+		choice, ok := item.(map[string]any)
+		if !ok || choice["id"] != value {
+			continue
+		}
+		for _, key := range []string{"name", "label", "value"} {
+			if name, ok := choice[key].(string); ok {
+				return name
+			}
+		}
+	}
+	return value
+}
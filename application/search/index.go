@@ -0,0 +1,39 @@
+package search
+
+import (
+	"github.com/labbs/nexo/application/search/dto"
+	"github.com/labbs/nexo/domain"
+)
+
+// Resources are indexed after they are saved: a failure is only logged, the
+// resources missing from the index are indexed by IndexMissing.
+
+func (app *SearchApplication) IndexDocument(input dto.IndexDocumentInput) {
+	_ = app.index(documentEntry(input.Document))
+}
+
+func (app *SearchApplication) IndexComment(input dto.IndexCommentInput) {
+	_ = app.index(commentEntry(input.Document, input.Comment))
+}
+
+func (app *SearchApplication) IndexRow(input dto.IndexRowInput) {
+	_ = app.index(rowEntry(input.Database, input.Row))
+}
+
+func (app *SearchApplication) IndexDrawing(input dto.IndexDrawingInput) {
+	_ = app.index(drawingEntry(input.Drawing))
+}
+
+func (app *SearchApplication) RemoveFromIndex(input dto.RemoveFromIndexInput) {
+	if err := app.SearchPers.Remove(input.ResourceType, input.ResourceIds); err != nil {
+		app.Logger.Warn().Err(err).Str("resource_type", string(input.ResourceType)).Strs("resource_ids", input.ResourceIds).Msg("failed to remove resources from the search index")
+	}
+}
+
+func (app *SearchApplication) index(entry *domain.SearchEntry) error {
+	err := app.SearchPers.Index(entry)
+	if err != nil {
+		app.Logger.Warn().Err(err).Str("resource_type", string(entry.ResourceType)).Str("resource_id", entry.ResourceId).Msg("failed to index resource for search")
+	}
+	return err
+}
@@ -0,0 +1,79 @@
+package search
+
+import "fmt"
+
+const indexBatchSize = 200
+
+// IndexMissing indexes the resources that are not in the search index yet:
+// the existing data on the first start, and the resources whose indexing
+// failed. It stops at the first batch with a failure, to retry on next run.
+func (app *SearchApplication) IndexMissing() error {
+	logger := app.Logger.With().Str("component", "application.search.index_missing").Logger()
+	indexed := 0
+
+	for {
+		documents, err := app.SearchPers.UnindexedDocuments(indexBatchSize)
+		if err != nil {
+			return fmt.Errorf("failed to list unindexed documents: %w", err)
+		}
+		failed := false
+		for i := range documents {
+			failed = app.index(documentEntry(&documents[i])) != nil || failed
+		}
+		indexed += len(documents)
+		if failed || len(documents) < indexBatchSize {
+			break
+		}
+	}
+
+	for {
+		comments, err := app.SearchPers.UnindexedComments(indexBatchSize)
+		if err != nil {
+			return fmt.Errorf("failed to list unindexed comments: %w", err)
+		}
+		failed := false
+		for i := range comments {
+			failed = app.index(commentEntry(&comments[i].Document, &comments[i])) != nil || failed
+		}
+		indexed += len(comments)
+		if failed || len(comments) < indexBatchSize {
+			break
+		}
+	}
+
+	for {
+		rows, err := app.SearchPers.UnindexedRows(indexBatchSize)
+		if err != nil {
+			return fmt.Errorf("failed to list unindexed rows: %w", err)
+		}
+		failed := false
+		for i := range rows {
+			failed = app.index(rowEntry(&rows[i].Database, &rows[i])) != nil || failed
+		}
+		indexed += len(rows)
+		if failed || len(rows) < indexBatchSize {
+			break
+		}
+	}
+
+	for {
+		drawings, err := app.SearchPers.UnindexedDrawings(indexBatchSize)
+		if err != nil {
+			return fmt.Errorf("failed to list unindexed drawings: %w", err)
+		}
+		failed := false
+		for i := range drawings {
+			failed = app.index(drawingEntry(&drawings[i])) != nil || failed
+		}
+		indexed += len(drawings)
+		if failed || len(drawings) < indexBatchSize {
+			break
+		}
+	}
+
+	if indexed > 0 {
+		logger.Info().Int("count", indexed).Msg("indexed resources for search")
+	}
+
+	return nil
+}
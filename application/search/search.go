@@ -0,0 +1,135 @@
+package search
+
+import (
+	"fmt"
+	"html"
+	"strings"
+	"unicode"
+
+	"github.com/labbs/nexo/application/search/dto"
+	"github.com/labbs/nexo/domain"
+	"github.com/labbs/nexo/infrastructure/helpers/apperrors"
+)
+
+const maxQueryTerms = 8
+
+func (app *SearchApplication) Search(input dto.SearchInput) (*dto.SearchOutput, error) {
+	terms := queryTerms(input.Query)
+	if len(strings.TrimSpace(input.Query)) < 2 || len(terms) == 0 {
+		return nil, fmt.Errorf("%w: query must be at least 2 characters", apperrors.ErrInvalidInput)
+	}
+
+	types := make([]domain.SearchResourceType, 0, len(input.Types))
+	for _, t := range input.Types {
+		resourceType := domain.SearchResourceType(t)
+		if !isSearchResourceType(resourceType) {
+			return nil, fmt.Errorf("%w: unknown search type %q", apperrors.ErrInvalidInput, t)
+		}
+		types = append(types, resourceType)
+	}
+
+	hits, err := app.SearchPers.Search(domain.SearchQuery{
+		Terms:   terms,
+		UserId:  input.UserId,
+		SpaceId: input.SpaceId,
+		Types:   types,
+		Limit:   input.Limit,
+		Offset:  input.Offset,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to search: %w", err)
+	}
+
+	output := &dto.SearchOutput{
+		Results: make([]dto.SearchResult, len(hits)),
+	}
+
+	for i, hit := range hits {
+		result := dto.SearchResult{
+			Type:       string(hit.ResourceType),
+			Id:         hit.ResourceId,
+			Title:      hit.Title,
+			Snippet:    highlightSnippet(hit.Snippet),
+			Icon:       hit.Icon,
+			SpaceId:    hit.SpaceId,
+			SpaceName:  hit.SpaceName,
+			DocumentId: hit.DocumentId,
+			DatabaseId: hit.DatabaseId,
+			BlockId:    matchedBlock(hit.Blocks, terms),
+			Rank:       hit.Rank,
+			UpdatedAt:  hit.UpdatedAt,
+		}
+		if hit.ResourceType == domain.SearchResourceDocument {
+			result.Slug = hit.Slug
+		}
+		output.Results[i] = result
+	}
+
+	return output, nil
+}
+
+// queryTerms splits a query into lowercase words
+func queryTerms(query string) []string {
+	words := strings.FieldsFunc(strings.ToLower(query), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+
+	var terms []string
+	seen := map[string]bool{}
+	for _, word := range words {
+		if seen[word] {
+			continue
+		}
+		seen[word] = true
+		terms = append(terms, word)
+		if len(terms) == maxQueryTerms {
+			break
+		}
+	}
+	return terms
+}
+
+func isSearchResourceType(resourceType domain.SearchResourceType) bool {
+	for _, t := range domain.SearchResourceTypes {
+		if t == resourceType {
+			return true
+		}
+	}
+	return false
+}
+
+// highlightSnippet escapes a snippet for HTML and wraps the matched terms in
+// <mark> elements
+func highlightSnippet(snippet string) string {
+	snippet = strings.Join(strings.Fields(snippet), " ")
+	snippet = html.EscapeString(snippet)
+	snippet = strings.ReplaceAll(snippet, domain.SearchHighlightStart, "<mark>")
+	return strings.ReplaceAll(snippet, domain.SearchHighlightEnd, "</mark>")
+}
+
+// matchedBlock returns the id of the block matching the most terms
+func matchedBlock(blocks domain.JSONBArray, terms []string) *string {
+	var best string
+	bestMatches := 0
+	for _, item := range blocks {
+		block, ok := item.(map[string]any)
+		if !ok {
+			continue
+		}
+		id, _ := block["id"].(string)
+		text, _ := block["text"].(string)
+		text = strings.ToLower(text)
+
+		matches := 0
+		for _, term := range terms {
+			matches += strings.Count(text, term)
+		}
+		if id != "" && matches > bestMatches {
+			best, bestMatches = id, matches
+		}
+	}
+	if best == "" {
+		return nil
+	}
+	return &best
+}
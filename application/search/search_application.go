@@ -0,0 +1,21 @@
+package search
+
+import (
+	"github.com/labbs/nexo/domain"
+	"github.com/labbs/nexo/infrastructure/config"
+	"github.com/rs/zerolog"
+)
+
+type SearchApplication struct {
+	Config     config.Config
+	Logger     zerolog.Logger
+	SearchPers domain.SearchPers
+}
+
+func NewSearchApplication(config config.Config, logger zerolog.Logger, searchPers domain.SearchPers) *SearchApplication {
+	return &SearchApplication{
+		Config:     config,
+		Logger:     logger,
+		SearchPers: searchPers,
+	}
+}
@@ -8,13 +8,12 @@ import (
 )
 
 type SessionApplication struct {
-	Config              config.Config
-	Logger              zerolog.Logger
-	SessionPers         domain.SessionPers
-	UserApplication     ports.UserPort
-	SpaceApplication    ports.SpacePort
-	DatabaseApplication ports.DatabasePort
-	DrawingApplication  ports.DrawingPort
+	Config                config.Config
+	Logger                zerolog.Logger
+	SessionPers           domain.SessionPers
+	UserApplication       ports.UserPort
+	ApiKeyApplication     ports.ApiKeyPort
+	PermissionApplication ports.PermissionPort
 }
 
 func NewSessionApplication(config config.Config, logger zerolog.Logger, sessionPers domain.SessionPers) *SessionApplication {
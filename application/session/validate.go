@@ -1,14 +1,13 @@
 package session
 
 import (
-	"fmt"
+	"strings"
 	"time"
 
 	fiberoapi "github.com/labbs/fiber-oapi"
 	"github.com/labbs/nexo/application/session/dto"
-	databaseDto "github.com/labbs/nexo/application/database/dto"
-	drawingDto "github.com/labbs/nexo/application/drawing/dto"
-	spaceDto "github.com/labbs/nexo/application/space/dto"
+	apikeyDto "github.com/labbs/nexo/application/apikey/dto"
+	permissionDto "github.com/labbs/nexo/application/permission/dto"
 	userDto "github.com/labbs/nexo/application/user/dto"
 	"github.com/labbs/nexo/domain"
 	"github.com/labbs/nexo/infrastructure/helpers/apperrors"
@@ -18,6 +17,10 @@ import (
 func (c *SessionApplication) ValidateToken(input dto.ValidateTokenInput) (*dto.ValidateTokenOutput, error) {
 	logger := c.Logger.With().Str("component", "application.session.validate_token").Logger()
 
+	if strings.HasPrefix(input.Token, apiKeyPrefix) {
+		return c.validateApiKey(input.Token)
+	}
+
 	sessionId, err := tokenutil.GetSessionIdFromToken(input.Token, c.Config)
 	if err != nil {
 		logger.Error().Err(err).Msg("failed to get session id from token")
@@ -53,6 +56,58 @@ func (c *SessionApplication) ValidateToken(input dto.ValidateTokenInput) (*dto.V
 	return &dto.ValidateTokenOutput{AuthContext: ctx}, nil
 }
 
+// apiKeyPrefix starts the API keys, which are accepted as bearer tokens
+// alongside the session JWTs
+const apiKeyPrefix = "zk_"
+
+// validateApiKey authenticates an API key: the context is the one of the user
+// owning the key, restricted to the scopes of the key
+func (c *SessionApplication) validateApiKey(key string) (*dto.ValidateTokenOutput, error) {
+	logger := c.Logger.With().Str("component", "application.session.validate_api_key").Logger()
+
+	if c.ApiKeyApplication == nil {
+		return nil, apperrors.ErrInvalidToken
+	}
+
+	result, err := c.ApiKeyApplication.ValidateApiKey(apikeyDto.ValidateApiKeyInput{Key: key})
+	if err != nil {
+		logger.Error().Err(err).Msg("failed to validate api key")
+		return nil, apperrors.ErrInvalidToken
+	}
+	if !result.Valid {
+		if result.Expired {
+			return nil, apperrors.ErrSessionExpired
+		}
+		return nil, apperrors.ErrInvalidToken
+	}
+
+	userResult, err := c.UserApplication.GetByUserId(userDto.GetByUserIdInput{UserId: result.UserId})
+	if err != nil {
+		logger.Error().Err(err).Str("user_id", result.UserId).Msg("failed to get user for role population")
+		return nil, apperrors.ErrInvalidToken
+	}
+	if !userResult.User.Active {
+		logger.Warn().Str("api_key_id", result.ApiKeyId).Str("user_id", result.UserId).Msg("api key of an inactive user")
+		return nil, apperrors.ErrInvalidToken
+	}
+
+	scopes := result.Scopes
+	if scopes == nil {
+		scopes = []string{}
+	}
+
+	ctx := &fiberoapi.AuthContext{
+		UserID: result.UserId,
+		Roles:  []string{string(userResult.User.Role)},
+		Scopes: scopes,
+		Claims: map[string]any{
+			"api_key_id": result.ApiKeyId,
+		},
+	}
+
+	return &dto.ValidateTokenOutput{AuthContext: ctx}, nil
+}
+
 func (c *SessionApplication) HasRole(input dto.HasRoleInput) bool {
 	for _, r := range input.Context.Roles {
 		if r == input.Role {
@@ -62,7 +117,12 @@ func (c *SessionApplication) HasRole(input dto.HasRoleInput) bool {
 	return false
 }
 
+// HasScope reports whether the context holds the scope. Sessions hold every
+// scope, API keys only the scopes they were granted.
 func (c *SessionApplication) HasScope(input dto.HasScopeInput) bool {
+	if _, ok := input.Context.Claims["api_key_id"]; !ok {
+		return true
+	}
 	for _, s := range input.Context.Scopes {
 		if s == input.Scope {
 			return true
@@ -99,8 +159,12 @@ func actionToRequiredRole(action string) string {
 	switch action {
 	case "read":
 		return "viewer"
+	case "comment":
+		return "commenter"
 	case "create", "write":
 		return "editor"
+	case "share":
+		return "admin"
 	case "delete":
 		return "owner"
 	default:
@@ -108,34 +172,75 @@ func actionToRequiredRole(action string) string {
 	}
 }
 
+// canAccessSpace checks the role of the user in the space, which honors the
+// reading access to public spaces
 func (c *SessionApplication) canAccessSpace(userID, spaceID, requiredRole string) (bool, error) {
-	result, err := c.SpaceApplication.GetSpaceById(spaceDto.GetSpaceByIdInput{SpaceId: spaceID})
-	if err != nil {
-		return false, err
-	}
-	return result.Space.HasPermission(userID, requiredRole), nil
+	return c.hasEffectiveRole(userID, domain.PermissionTypeSpace, spaceID, requiredRole)
 }
 
+// canAccessDatabase checks the role of the user on the database, which
+// honors the permissions of the database over the ones of its space
 func (c *SessionApplication) canAccessDatabase(userID, databaseID, requiredRole string) (bool, error) {
-	result, err := c.DatabaseApplication.GetDatabaseById(databaseDto.GetDatabaseByIdInput{DatabaseId: databaseID})
-	if err != nil {
-		return false, err
-	}
-	return c.canAccessSpace(userID, result.Database.SpaceId, requiredRole)
+	return c.hasEffectiveRole(userID, domain.PermissionTypeDatabase, databaseID, requiredRole)
 }
 
+// canAccessDrawing checks the role of the user on the drawing, which honors
+// the permissions of the drawing over the ones of its space
 func (c *SessionApplication) canAccessDrawing(userID, drawingID, requiredRole string) (bool, error) {
-	result, err := c.DrawingApplication.GetDrawingById(drawingDto.GetDrawingByIdInput{DrawingId: drawingID})
+	return c.hasEffectiveRole(userID, domain.PermissionTypeDrawing, drawingID, requiredRole)
+}
+
+func (c *SessionApplication) hasEffectiveRole(userID string, resourceType domain.PermissionType, resourceID, requiredRole string) (bool, error) {
+	result, err := c.PermissionApplication.GetEffectivePermission(permissionDto.GetEffectivePermissionInput{
+		RequesterId:  userID,
+		ResourceType: string(resourceType),
+		ResourceId:   resourceID,
+	})
 	if err != nil {
 		return false, err
 	}
-	return c.canAccessSpace(userID, result.Drawing.SpaceId, requiredRole)
+	role := result.Permission.Role
+	return role != nil && role.Includes(domain.PermissionRole(requiredRole)), nil
 }
 
+// resourceActions are the actions GetUserPermissions reports on a resource
+var resourceActions = []string{"read", "comment", "create", "write", "share", "delete"}
+
+// GetUserPermissions returns the actions the user can do on a resource,
+// from the effective permission the permission application resolves.
 func (c *SessionApplication) GetUserPermissions(input dto.GetUserPermissionsInput) (*dto.GetUserPermissionsOutput, error) {
 	logger := c.Logger.With().Str("component", "application.session.get_user_permissions").Logger()
 
-	logger.Warn().Msg("not implemented")
+	permission := &fiberoapi.ResourcePermission{
+		ResourceType: input.ResourceType,
+		ResourceID:   input.ResourceID,
+		Actions:      []string{},
+	}
+
+	// Admin can do everything, as in CanAccessResource
+	isAdmin := c.HasRole(dto.HasRoleInput{Context: input.Context, Role: string(domain.RoleAdmin)})
+	if isAdmin {
+		permission.Actions = append(permission.Actions, resourceActions...)
+		return &dto.GetUserPermissionsOutput{Permission: permission}, nil
+	}
+
+	result, err := c.PermissionApplication.GetEffectivePermission(permissionDto.GetEffectivePermissionInput{
+		RequesterId:  input.Context.UserID,
+		ResourceType: input.ResourceType,
+		ResourceId:   input.ResourceID,
+	})
+	if err != nil {
+		logger.Error().Err(err).Str("resource_type", input.ResourceType).Str("resource_id", input.ResourceID).Msg("failed to get effective permission")
+		return nil, err
+	}
+
+	if role := result.Permission.Role; role != nil {
+		for _, action := range resourceActions {
+			if role.Includes(domain.PermissionRole(actionToRequiredRole(action))) {
+				permission.Actions = append(permission.Actions, action)
+			}
+		}
+	}
 
-	return nil, fmt.Errorf("not implemented")
+	return &dto.GetUserPermissionsOutput{Permission: permission}, nil
 }
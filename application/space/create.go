@@ -5,6 +5,7 @@ import (
 
 	"github.com/gofiber/fiber/v2/utils"
 	"github.com/gosimple/slug"
+	eventDto "github.com/labbs/nexo/application/event/dto"
 	permissionDto "github.com/labbs/nexo/application/permission/dto"
 	"github.com/labbs/nexo/application/space/dto"
 	"github.com/labbs/nexo/domain"
@@ -40,6 +41,8 @@ func (c *SpaceApplication) CreatePrivateSpaceForUser(input dto.CreatePrivateSpac
 		logger.Warn().Err(err).Str("space_id", space.Id).Str("user_id", input.UserId).Msg("failed to create owner permission")
 	}
 
+	c.publishSpaceEvent(domain.WebhookEventSpaceCreated, input.UserId, nil, eventDto.SpaceSnapshot(space))
+
 	return &dto.CreatePrivateSpaceForUserOutput{Space: space}, nil
 }
 
@@ -74,5 +77,11 @@ func (c *SpaceApplication) CreateSpace(input dto.CreateSpaceInput) (*dto.CreateS
 		}
 	}
 
+	actorId := ""
+	if input.OwnerId != nil {
+		actorId = *input.OwnerId
+	}
+	c.publishSpaceEvent(domain.WebhookEventSpaceCreated, actorId, nil, eventDto.SpaceSnapshot(space))
+
 	return &dto.CreateSpaceOutput{Space: space}, nil
 }
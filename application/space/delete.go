@@ -3,6 +3,7 @@ package space
 import (
 	"github.com/labbs/nexo/infrastructure/helpers/apperrors"
 	docDto "github.com/labbs/nexo/application/document/dto"
+	eventDto "github.com/labbs/nexo/application/event/dto"
 	"github.com/labbs/nexo/application/space/dto"
 	"github.com/labbs/nexo/domain"
 )
@@ -34,5 +35,7 @@ func (c *SpaceApplication) DeleteSpace(input dto.DeleteSpaceInput) error {
 		return err
 	}
 
+	c.publishSpaceEvent(domain.WebhookEventSpaceDeleted, input.UserId, eventDto.SpaceSnapshot(space), nil)
+
 	return nil
 }
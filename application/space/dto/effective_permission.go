@@ -0,0 +1,12 @@
+package dto
+
+import "github.com/labbs/nexo/domain"
+
+type GetEffectivePermissionInput struct {
+	SpaceId string
+	UserId  string
+}
+
+type GetEffectivePermissionOutput struct {
+	Permission domain.EffectivePermission
+}
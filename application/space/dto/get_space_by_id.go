@@ -1,12 +1,19 @@
 package dto
 
-import "time"
+import (
+	"slices"
+	"time"
+
+	"github.com/labbs/nexo/domain"
+)
 
 // SpacePermission represents a permission entry for a space
 type SpacePermission struct {
 	UserId  *string
 	GroupId *string
-	Role    string
+	// MemberIds are the members of the group, for group permissions
+	MemberIds []string
+	Role      string
 }
 
 // SpaceDetail contains the space data needed by other applications
@@ -23,7 +30,9 @@ type SpaceDetail struct {
 	UpdatedAt   time.Time
 }
 
-// GetUserRole returns the user's role in this space, or nil if no role
+// GetUserRole returns the user's role in this space, or nil if no role. The
+// permissions of the user and of their groups are resolved with
+// domain.ResolveRole.
 func (s *SpaceDetail) GetUserRole(userId string) *string {
 	// Check if the user is the owner
 	if s.OwnerId != nil && *s.OwnerId == userId {
@@ -32,34 +41,42 @@ func (s *SpaceDetail) GetUserRole(userId string) *string {
 	}
 
 	// Check permissions
+	var userRole *domain.PermissionRole
+	var groupRoles []domain.PermissionRole
 	for _, perm := range s.Permissions {
-		if perm.UserId != nil && *perm.UserId == userId {
-			return &perm.Role
+		switch {
+		case perm.UserId != nil && *perm.UserId == userId:
+			role := domain.PermissionRole(perm.Role)
+			userRole = &role
+		case perm.GroupId != nil && slices.Contains(perm.MemberIds, userId):
+			groupRoles = append(groupRoles, domain.PermissionRole(perm.Role))
 		}
 	}
+	if role := domain.ResolveRole(userRole, groupRoles); role != nil {
+		resolved := string(*role)
+		return &resolved
+	}
 
 	return nil
 }
 
+// IsMember returns true if the user has a role in this space, as owner or
+// through a permission of their own or of their groups, and is not denied
+func (s *SpaceDetail) IsMember(userId string) bool {
+	userRole := s.GetUserRole(userId)
+	return userRole != nil && *userRole != "denied"
+}
+
 // HasPermission checks if the user has at least the required role level
+// through the owner and the permissions of the space. The reading access to
+// public spaces is resolved by SpaceApplication.GetEffectivePermission.
 func (s *SpaceDetail) HasPermission(userId string, requiredRole string) bool {
 	userRole := s.GetUserRole(userId)
 	if userRole == nil {
-		// For public spaces, allow reading
-		return s.Type == "public" && requiredRole == "viewer"
+		return false
 	}
 
-	return roleHasPermission(*userRole, requiredRole)
-}
-
-func roleHasPermission(userRole, requiredRole string) bool {
-	roleHierarchy := map[string]int{
-		"viewer": 1,
-		"editor": 2,
-		"admin":  3,
-		"owner":  4,
-	}
-	return roleHierarchy[userRole] >= roleHierarchy[requiredRole]
+	return domain.PermissionRole(*userRole).Includes(domain.PermissionRole(requiredRole))
 }
 
 type GetSpaceByIdInput struct {
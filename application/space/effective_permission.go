@@ -0,0 +1,21 @@
+package space
+
+import (
+	"fmt"
+
+	"github.com/labbs/nexo/application/space/dto"
+)
+
+// GetEffectivePermission returns the role of a user in a space with the
+// grants it results from. It does not check the access of the user.
+func (c *SpaceApplication) GetEffectivePermission(input dto.GetEffectivePermissionInput) (*dto.GetEffectivePermissionOutput, error) {
+	logger := c.Logger.With().Str("component", "application.space.get_effective_permission").Logger()
+
+	space, err := c.SpacePres.GetSpaceForUser(input.SpaceId, input.UserId)
+	if err != nil {
+		logger.Error().Err(err).Str("spaceId", input.SpaceId).Msg("failed to get space by id")
+		return nil, fmt.Errorf("space not found: %w", err)
+	}
+
+	return &dto.GetEffectivePermissionOutput{Permission: space.EffectivePermission(input.UserId)}, nil
+}
@@ -0,0 +1,30 @@
+package space
+
+import (
+	eventDto "github.com/labbs/nexo/application/event/dto"
+	"github.com/labbs/nexo/domain"
+)
+
+// publishSpaceEvent publishes a space event. before or after may be nil
+// (respectively on creation and deletion).
+func (c *SpaceApplication) publishSpaceEvent(event domain.WebhookEvent, userId string, before, after map[string]any) {
+	if c.EventApplication == nil {
+		return
+	}
+
+	current := after
+	if current == nil {
+		current = before
+	}
+	spaceId, _ := current["id"].(string)
+
+	c.EventApplication.Publish(eventDto.PublishEventInput{
+		Event:        event,
+		ActorId:      userId,
+		SpaceId:      &spaceId,
+		ResourceType: eventDto.ResourceTypeSpace,
+		ResourceId:   spaceId,
+		Before:       before,
+		After:        after,
+	})
+}
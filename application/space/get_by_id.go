@@ -23,6 +23,11 @@ func (c *SpaceApplication) GetSpaceById(input dto.GetSpaceByIdInput) (*dto.GetSp
 			GroupId: p.GroupId,
 			Role:    string(p.Role),
 		}
+		if p.Group != nil {
+			for _, member := range p.Group.Members {
+				permissions[i].MemberIds = append(permissions[i].MemberIds, member.Id)
+			}
+		}
 	}
 
 	detail := &dto.SpaceDetail{
@@ -13,6 +13,7 @@ type SpaceApplication struct {
 	SpacePres             domain.SpacePers
 	DocumentApplication   ports.DocumentPort
 	PermissionApplication ports.PermissionPort
+	EventApplication      ports.EventPort
 }
 
 func NewSpaceApplication(config config.Config, logger zerolog.Logger, spacePers domain.SpacePers) *SpaceApplication {
@@ -3,6 +3,7 @@ package space
 import (
 	"github.com/gosimple/slug"
 	"github.com/labbs/nexo/infrastructure/helpers/apperrors"
+	eventDto "github.com/labbs/nexo/application/event/dto"
 	"github.com/labbs/nexo/application/space/dto"
 	"github.com/labbs/nexo/domain"
 	"github.com/labbs/nexo/infrastructure/helpers/shortuuid"
@@ -21,6 +22,8 @@ func (c *SpaceApplication) UpdateSpace(input dto.UpdateSpaceInput) (*dto.UpdateS
 		return nil, apperrors.ErrForbidden
 	}
 
+	before := eventDto.SpaceSnapshot(space)
+
 	// Apply updates
 	if input.Name != nil && *input.Name != "" && space.Name != *input.Name {
 		space.Name = *input.Name
@@ -38,5 +41,7 @@ func (c *SpaceApplication) UpdateSpace(input dto.UpdateSpaceInput) (*dto.UpdateS
 		return nil, err
 	}
 
+	c.publishSpaceEvent(domain.WebhookEventSpaceUpdated, input.UserId, before, eventDto.SpaceSnapshot(space))
+
 	return &dto.UpdateSpaceOutput{Space: space}, nil
 }
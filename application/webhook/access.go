@@ -0,0 +1,33 @@
+package webhook
+
+import (
+	"errors"
+	"fmt"
+
+	permissionDto "github.com/labbs/nexo/application/permission/dto"
+	"github.com/labbs/nexo/domain"
+	"github.com/labbs/nexo/infrastructure/helpers/apperrors"
+)
+
+// checkSpaceAccess verifies that the user can read the space a webhook is
+// scoped to, so that nobody subscribes to the events of a space they cannot
+// see. Unknown spaces are denied the same way.
+func (app *WebhookApplication) checkSpaceAccess(spaceId, userId string) error {
+	result, err := app.PermissionApplication.GetEffectivePermission(permissionDto.GetEffectivePermissionInput{
+		RequesterId:  userId,
+		ResourceType: string(domain.PermissionTypeSpace),
+		ResourceId:   spaceId,
+	})
+	if errors.Is(err, apperrors.ErrSpaceNotFound) {
+		return apperrors.ErrAccessDenied
+	}
+	if err != nil {
+		return fmt.Errorf("failed to resolve space permission: %w", err)
+	}
+
+	role := result.Permission.Role
+	if role == nil || !role.Includes(domain.PermissionRoleViewer) {
+		return apperrors.ErrAccessDenied
+	}
+	return nil
+}
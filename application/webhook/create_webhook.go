@@ -10,6 +10,12 @@ import (
 )
 
 func (app *WebhookApplication) CreateWebhook(input dto.CreateWebhookInput) (*dto.CreateWebhookOutput, error) {
+	if input.SpaceId != nil {
+		if err := app.checkSpaceAccess(*input.SpaceId, input.UserId); err != nil {
+			return nil, err
+		}
+	}
+
 	secret, err := generateSecret()
 	if err != nil {
 		return nil, fmt.Errorf("failed to generate secret: %w", err)
@@ -18,10 +18,15 @@ type WebhookItem struct {
 }
 
 type DeliveryItem struct {
-	Id         string
-	Event      string
-	StatusCode int
-	Success    bool
-	Duration   int
-	CreatedAt  time.Time
+	Id            string
+	Event         string
+	Status        string
+	Attempts      int
+	NextAttemptAt *time.Time
+	Error         string
+	StatusCode    int
+	Success       bool
+	Duration      int
+	CreatedAt     time.Time
+	UpdatedAt     time.Time
 }
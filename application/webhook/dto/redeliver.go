@@ -0,0 +1,11 @@
+package dto
+
+type RedeliverInput struct {
+	UserId     string
+	WebhookId  string
+	DeliveryId string
+}
+
+type RedeliverOutput struct {
+	DeliveryId string
+}
@@ -0,0 +1,15 @@
+package dto
+
+type SendRequestInput struct {
+	Url     string
+	Method  string // defaults to POST
+	Secret  string // optional, signs the body in X-Webhook-Signature
+	Headers map[string]string
+	Body    any
+}
+
+type SendRequestOutput struct {
+	StatusCode int
+	Response   string
+	Duration   int
+}
@@ -4,4 +4,8 @@ type TriggerWebhookInput struct {
 	Event   string
 	SpaceId *string
 	Payload map[string]any
+
+	// CanRead reports whether the owner of a webhook can read the resource
+	// of the event. A nil function allows every webhook.
+	CanRead func(userId string) bool
 }
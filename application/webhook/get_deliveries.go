@@ -34,12 +34,17 @@ func (app *WebhookApplication) GetDeliveries(input dto.GetDeliveriesInput) (*dto
 
 	for i, d := range deliveries {
 		output.Deliveries[i] = dto.DeliveryItem{
-			Id:         d.Id,
-			Event:      d.Event,
-			StatusCode: d.StatusCode,
-			Success:    d.Success,
-			Duration:   d.Duration,
-			CreatedAt:  d.CreatedAt,
+			Id:            d.Id,
+			Event:         d.Event,
+			Status:        string(d.Status),
+			Attempts:      d.Attempts,
+			NextAttemptAt: d.NextAttemptAt,
+			Error:         d.Error,
+			StatusCode:    d.StatusCode,
+			Success:       d.Success,
+			Duration:      d.Duration,
+			CreatedAt:     d.CreatedAt,
+			UpdatedAt:     d.UpdatedAt,
 		}
 	}
 
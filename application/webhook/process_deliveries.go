@@ -0,0 +1,204 @@
+package webhook
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/labbs/nexo/domain"
+	"github.com/labbs/nexo/infrastructure/helpers/safehttp"
+)
+
+const (
+	defaultMaxAttempts = 8
+
+	deliveryBatchSize   = 50
+	deliveryConcurrency = 5
+	deliveryTimeout     = 30 * time.Second
+	// deliveryLease must be longer than deliveryTimeout: a claimed delivery
+	// becomes due again once its lease expires (e.g. after a crash).
+	deliveryLease = 2 * time.Minute
+
+	retryBaseDelay = 30 * time.Second
+	retryMaxDelay  = 6 * time.Hour
+)
+
+// httpClient sends the deliveries and the requests of the automation steps.
+// It refuses to connect to internal addresses, whatever the URL resolves to.
+var httpClient = safehttp.NewClient(deliveryTimeout)
+
+// ProcessPendingDeliveries attempts every delivery that is due.
+// It is run periodically by the scheduler.
+func (app *WebhookApplication) ProcessPendingDeliveries() error {
+	logger := app.Logger.With().Str("component", "webhook.process_deliveries").Logger()
+
+	deliveries, err := app.WebhookDeliveryPers.GetDue(time.Now(), deliveryBatchSize)
+	if err != nil {
+		logger.Error().Err(err).Msg("failed to get due webhook deliveries")
+		return err
+	}
+
+	sem := make(chan struct{}, deliveryConcurrency)
+	var wg sync.WaitGroup
+	for _, delivery := range deliveries {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(d domain.WebhookDelivery) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			defer func() {
+				if r := recover(); r != nil {
+					logger.Error().Any("panic", r).Str("delivery_id", d.Id).Msg("webhook delivery panicked")
+				}
+			}()
+			app.processDelivery(d)
+		}(delivery)
+	}
+	wg.Wait()
+
+	return nil
+}
+
+// processDelivery claims the delivery, makes one attempt and schedules the
+// next one on failure.
+func (app *WebhookApplication) processDelivery(delivery domain.WebhookDelivery) {
+	logger := app.Logger.With().
+		Str("component", "webhook.deliver").
+		Str("webhook_id", delivery.WebhookId).
+		Str("delivery_id", delivery.Id).
+		Str("event", delivery.Event).
+		Logger()
+
+	now := time.Now()
+	claimed, err := app.WebhookDeliveryPers.Claim(delivery.Id, now, now.Add(deliveryLease))
+	if err != nil {
+		logger.Error().Err(err).Msg("failed to claim webhook delivery")
+		return
+	}
+	if !claimed {
+		// Already handled by another worker
+		return
+	}
+
+	webhook := delivery.Webhook
+	if webhook.Id == "" || !webhook.Active {
+		delivery.Status = domain.WebhookDeliveryStatusFailed
+		delivery.Error = "webhook is inactive or deleted"
+		delivery.NextAttemptAt = nil
+		delivery.UpdatedAt = time.Now()
+		if err := app.WebhookDeliveryPers.Update(&delivery); err != nil {
+			logger.Error().Err(err).Msg("failed to update webhook delivery")
+		}
+		return
+	}
+
+	delivery.Attempts++
+	statusCode, response, duration, sendErr := app.sendDelivery(webhook, delivery)
+
+	delivery.StatusCode = statusCode
+	delivery.Response = response
+	delivery.Duration = duration
+	delivery.Success = sendErr == nil
+	delivery.UpdatedAt = time.Now()
+
+	if sendErr == nil {
+		delivery.Status = domain.WebhookDeliveryStatusSucceeded
+		delivery.Error = ""
+		delivery.NextAttemptAt = nil
+		_ = app.WebhookPers.IncrementSuccess(webhook.Id)
+		logger.Debug().Int("status_code", statusCode).Int("attempt", delivery.Attempts).Msg("webhook delivered successfully")
+	} else {
+		delivery.Error = sendErr.Error()
+		if delivery.Attempts >= app.maxAttempts() {
+			delivery.Status = domain.WebhookDeliveryStatusFailed
+			delivery.NextAttemptAt = nil
+			logger.Warn().Err(sendErr).Int("attempt", delivery.Attempts).Msg("webhook delivery failed, giving up")
+		} else {
+			next := time.Now().Add(retryDelay(delivery.Attempts))
+			delivery.Status = domain.WebhookDeliveryStatusRetrying
+			delivery.NextAttemptAt = &next
+			logger.Warn().Err(sendErr).Int("attempt", delivery.Attempts).Time("next_attempt_at", next).Msg("webhook delivery failed, will retry")
+		}
+
+		_ = app.WebhookPers.RecordFailure(webhook.Id, sendErr.Error())
+		if threshold := app.Config.Webhook.DisableAfterFailures; threshold > 0 {
+			disabled, err := app.WebhookPers.DisableIfFailing(webhook.Id, threshold)
+			if err != nil {
+				logger.Error().Err(err).Msg("failed to disable failing webhook")
+			} else if disabled {
+				logger.Warn().Int("consecutive_failures", threshold).Msg("webhook disabled after too many consecutive failures")
+			}
+		}
+	}
+
+	if err := app.WebhookDeliveryPers.Update(&delivery); err != nil {
+		logger.Error().Err(err).Msg("failed to update webhook delivery")
+	}
+}
+
+// sendDelivery makes a single HTTP attempt. Non-2xx responses are returned as errors.
+func (app *WebhookApplication) sendDelivery(webhook domain.Webhook, delivery domain.WebhookDelivery) (statusCode int, response string, duration int, err error) {
+	payloadBytes, err := json.Marshal(delivery.Payload)
+	if err != nil {
+		return 0, "", 0, fmt.Errorf("failed to marshal payload: %w", err)
+	}
+
+	// Create signature
+	signature := signPayload(payloadBytes, webhook.Secret)
+
+	start := time.Now()
+	req, err := http.NewRequest("POST", webhook.Url, bytes.NewBuffer(payloadBytes))
+	if err != nil {
+		return 0, "", 0, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	deliveryId, _ := delivery.Payload["id"].(string)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Webhook-Signature", signature)
+	req.Header.Set("X-Webhook-Event", delivery.Event)
+	req.Header.Set("X-Webhook-Delivery", deliveryId)
+	req.Header.Set("X-Webhook-Attempt", strconv.Itoa(delivery.Attempts))
+
+	resp, err := httpClient.Do(req)
+	duration = int(time.Since(start).Milliseconds())
+	if err != nil {
+		return 0, "", duration, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return resp.StatusCode, "", duration, nil
+	}
+
+	limited, _ := io.ReadAll(io.LimitReader(resp.Body, 512*1024))
+	response = string(limited)
+	if len(response) > 500 {
+		response = response[:500]
+	}
+	return resp.StatusCode, response, duration, fmt.Errorf("HTTP %d: %s", resp.StatusCode, response)
+}
+
+func (app *WebhookApplication) maxAttempts() int {
+	if app.Config.Webhook.MaxAttempts > 0 {
+		return app.Config.Webhook.MaxAttempts
+	}
+	return defaultMaxAttempts
+}
+
+// retryDelay returns the exponential backoff before the next attempt:
+// 30s, 1m, 2m, 4m... capped at retryMaxDelay.
+func retryDelay(attempts int) time.Duration {
+	delay := retryBaseDelay
+	for i := 1; i < attempts; i++ {
+		delay *= 2
+		if delay >= retryMaxDelay {
+			return retryMaxDelay
+		}
+	}
+	return delay
+}
@@ -0,0 +1,73 @@
+package webhook
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/labbs/nexo/application/webhook/dto"
+	"github.com/labbs/nexo/domain"
+	"github.com/labbs/nexo/infrastructure/helpers/apperrors"
+)
+
+// Redeliver queues a new delivery with the payload of an existing one (webhook owner only)
+func (app *WebhookApplication) Redeliver(input dto.RedeliverInput) (*dto.RedeliverOutput, error) {
+	webhook, err := app.WebhookPers.GetById(input.WebhookId)
+	if err != nil {
+		return nil, fmt.Errorf("webhook not found: %w", err)
+	}
+
+	if webhook.UserId != input.UserId {
+		return nil, apperrors.ErrAccessDenied
+	}
+
+	delivery, err := app.WebhookDeliveryPers.GetById(input.DeliveryId)
+	if err != nil {
+		return nil, fmt.Errorf("delivery not found: %w", err)
+	}
+
+	if delivery.WebhookId != webhook.Id {
+		return nil, apperrors.ErrNotFound
+	}
+
+	return app.redeliver(*delivery)
+}
+
+// AdminRedeliver queues a new delivery with the payload of an existing one (admin only)
+func (app *WebhookApplication) AdminRedeliver(deliveryId string) (*dto.RedeliverOutput, error) {
+	delivery, err := app.WebhookDeliveryPers.GetById(deliveryId)
+	if err != nil {
+		return nil, fmt.Errorf("delivery not found: %w", err)
+	}
+
+	if delivery.Webhook.Id == "" {
+		return nil, apperrors.ErrWebhookNotFound
+	}
+
+	return app.redeliver(*delivery)
+}
+
+// redeliver copies the original envelope, including its id, so that receivers
+// can deduplicate.
+func (app *WebhookApplication) redeliver(original domain.WebhookDelivery) (*dto.RedeliverOutput, error) {
+	now := time.Now()
+	delivery := &domain.WebhookDelivery{
+		Id:            uuid.New().String(),
+		WebhookId:     original.WebhookId,
+		Event:         original.Event,
+		Payload:       original.Payload,
+		Status:        domain.WebhookDeliveryStatusPending,
+		NextAttemptAt: &now,
+		CreatedAt:     now,
+		UpdatedAt:     now,
+	}
+
+	if err := app.WebhookDeliveryPers.Create(delivery); err != nil {
+		return nil, fmt.Errorf("failed to queue redelivery: %w", err)
+	}
+
+	delivery.Webhook = original.Webhook
+	app.processDeliveryAsync(*delivery)
+
+	return &dto.RedeliverOutput{DeliveryId: delivery.Id}, nil
+}
@@ -0,0 +1,71 @@
+package webhook
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/labbs/nexo/application/webhook/dto"
+	"github.com/labbs/nexo/infrastructure/helpers/safehttp"
+)
+
+// SendRequest sends a one-off JSON request to an arbitrary URL. It is used by
+// automation steps and is not recorded as a webhook delivery.
+func (app *WebhookApplication) SendRequest(input dto.SendRequestInput) (*dto.SendRequestOutput, error) {
+	if err := safehttp.ValidateURL(input.Url); err != nil {
+		return nil, fmt.Errorf("invalid url %q: %w", input.Url, err)
+	}
+
+	method := strings.ToUpper(input.Method)
+	if method == "" {
+		method = http.MethodPost
+	}
+
+	body, err := json.Marshal(input.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal body: %w", err)
+	}
+
+	req, err := http.NewRequest(method, input.Url, bytes.NewBuffer(body))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	if input.Secret != "" {
+		req.Header.Set("X-Webhook-Signature", signPayload(body, input.Secret))
+	}
+	for key, value := range input.Headers {
+		req.Header.Set(key, value)
+	}
+
+	start := time.Now()
+	resp, err := httpClient.Do(req)
+	duration := int(time.Since(start).Milliseconds())
+	if err != nil {
+		return nil, fmt.Errorf("failed to send request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	limited, _ := io.ReadAll(io.LimitReader(resp.Body, 512*1024))
+	response := string(limited)
+	if len(response) > 500 {
+		response = response[:500]
+	}
+
+	output := &dto.SendRequestOutput{
+		StatusCode: resp.StatusCode,
+		Response:   response,
+		Duration:   duration,
+	}
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return output, fmt.Errorf("HTTP %d: %s", resp.StatusCode, response)
+	}
+
+	return output, nil
+}
@@ -1,11 +1,6 @@
 package webhook
 
 import (
-	"bytes"
-	"encoding/json"
-	"fmt"
-	"io"
-	"net/http"
 	"time"
 
 	"github.com/google/uuid"
@@ -13,7 +8,10 @@ import (
 	"github.com/labbs/nexo/domain"
 )
 
-// TriggerWebhooks triggers all matching webhooks for an event
+// TriggerWebhooks queues a delivery for every webhook subscribed to the event.
+// Deliveries are persisted before any attempt so that nothing is lost when a
+// receiver is down or the server restarts; a first attempt is made right away
+// and failures are retried by ProcessPendingDeliveries.
 func (app *WebhookApplication) TriggerWebhooks(input dto.TriggerWebhookInput) {
 	logger := app.Logger.With().Str("component", "webhook.trigger").Str("event", input.Event).Logger()
 
@@ -24,105 +22,55 @@ func (app *WebhookApplication) TriggerWebhooks(input dto.TriggerWebhookInput) {
 	}
 
 	for _, webhook := range webhooks {
-		w := webhook
-		go func() {
-			defer func() {
-				if r := recover(); r != nil {
-					app.Logger.Error().Any("panic", r).Str("webhook_id", w.Id).Msg("webhook delivery panicked")
-				}
-			}()
-			app.deliverWebhook(w, input.Event, input.Payload)
-		}()
+		if input.CanRead != nil && !input.CanRead(webhook.UserId) {
+			logger.Debug().Str("webhook_id", webhook.Id).Msg("webhook owner cannot read the resource, skipping")
+			continue
+		}
+		delivery, err := app.enqueueDelivery(webhook, input.Event, input.Payload)
+		if err != nil {
+			logger.Error().Err(err).Str("webhook_id", webhook.Id).Msg("failed to queue webhook delivery")
+			continue
+		}
+		app.processDeliveryAsync(*delivery)
 	}
 }
 
-func (app *WebhookApplication) deliverWebhook(webhook domain.Webhook, event string, payload map[string]any) {
-	logger := app.Logger.With().
-		Str("component", "webhook.deliver").
-		Str("webhook_id", webhook.Id).
-		Str("event", event).
-		Logger()
-
-	// Build the webhook payload
-	webhookPayload := map[string]any{
-		"id":        uuid.New().String(),
-		"event":     event,
-		"timestamp": time.Now().UTC().Format(time.RFC3339),
-		"data":      payload,
-	}
-
-	payloadBytes, err := json.Marshal(webhookPayload)
-	if err != nil {
-		logger.Error().Err(err).Msg("failed to marshal payload")
-		return
-	}
-
-	// Create signature
-	signature := signPayload(payloadBytes, webhook.Secret)
-
-	// Make the HTTP request
-	start := time.Now()
-	req, err := http.NewRequest("POST", webhook.Url, bytes.NewBuffer(payloadBytes))
-	if err != nil {
-		logger.Error().Err(err).Msg("failed to create request")
-		app.recordDelivery(webhook.Id, event, webhookPayload, 0, "", false, 0)
-		_ = app.WebhookPers.RecordFailure(webhook.Id, err.Error())
-		return
-	}
-
-	deliveryId, _ := webhookPayload["id"].(string)
-	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("X-Webhook-Signature", signature)
-	req.Header.Set("X-Webhook-Event", event)
-	req.Header.Set("X-Webhook-Delivery", deliveryId)
+// enqueueDelivery persists a pending delivery wrapping the payload in the webhook envelope
+func (app *WebhookApplication) enqueueDelivery(webhook domain.Webhook, event string, payload map[string]any) (*domain.WebhookDelivery, error) {
+	now := time.Now()
+	deliveryId := uuid.New().String()
 
-	client := &http.Client{Timeout: 30 * time.Second}
-	resp, err := client.Do(req)
-	duration := int(time.Since(start).Milliseconds())
-
-	if err != nil {
-		logger.Error().Err(err).Msg("failed to deliver webhook")
-		app.recordDelivery(webhook.Id, event, webhookPayload, 0, err.Error(), false, duration)
-		_ = app.WebhookPers.RecordFailure(webhook.Id, err.Error())
-		return
+	delivery := &domain.WebhookDelivery{
+		Id:        deliveryId,
+		WebhookId: webhook.Id,
+		Event:     event,
+		Payload: domain.JSONB{
+			"id":        deliveryId,
+			"event":     event,
+			"timestamp": now.UTC().Format(time.RFC3339),
+			"data":      payload,
+		},
+		Status:        domain.WebhookDeliveryStatusPending,
+		NextAttemptAt: &now,
+		CreatedAt:     now,
+		UpdatedAt:     now,
 	}
-	defer resp.Body.Close()
 
-	success := resp.StatusCode >= 200 && resp.StatusCode < 300
-	responseBody := ""
-	if !success {
-		limited, _ := io.ReadAll(io.LimitReader(resp.Body, 512*1024))
-		responseBody = string(limited)
-		if len(responseBody) > 500 {
-			responseBody = responseBody[:500]
-		}
+	if err := app.WebhookDeliveryPers.Create(delivery); err != nil {
+		return nil, err
 	}
 
-	app.recordDelivery(webhook.Id, event, webhookPayload, resp.StatusCode, responseBody, success, duration)
-
-	if success {
-		_ = app.WebhookPers.IncrementSuccess(webhook.Id)
-		logger.Debug().Int("status_code", resp.StatusCode).Msg("webhook delivered successfully")
-	} else {
-		_ = app.WebhookPers.RecordFailure(webhook.Id, fmt.Sprintf("HTTP %d: %s", resp.StatusCode, responseBody))
-		logger.Warn().Int("status_code", resp.StatusCode).Msg("webhook delivery failed")
-	}
+	delivery.Webhook = webhook
+	return delivery, nil
 }
 
-func (app *WebhookApplication) recordDelivery(webhookId, event string, payload map[string]any, statusCode int, response string, success bool, duration int) {
-	delivery := &domain.WebhookDelivery{
-		Id:         uuid.New().String(),
-		WebhookId:  webhookId,
-		Event:      event,
-		Payload:    domain.JSONB(payload),
-		StatusCode: statusCode,
-		Response:   response,
-		Success:    success,
-		Duration:   duration,
-		CreatedAt:  time.Now(),
-	}
-
-	if err := app.WebhookDeliveryPers.Create(delivery); err != nil {
-		app.Logger.Error().Err(err).Msg("failed to record webhook delivery")
-	}
+func (app *WebhookApplication) processDeliveryAsync(delivery domain.WebhookDelivery) {
+	go func() {
+		defer func() {
+			if r := recover(); r != nil {
+				app.Logger.Error().Any("panic", r).Str("delivery_id", delivery.Id).Msg("webhook delivery panicked")
+			}
+		}()
+		app.processDelivery(delivery)
+	}()
 }
@@ -34,6 +34,10 @@ func (app *WebhookApplication) UpdateWebhook(input dto.UpdateWebhookInput) error
 	}
 
 	if input.Active != nil {
+		// Re-enabling a webhook (e.g. after an auto-disable) gives it a fresh start
+		if *input.Active && !webhook.Active {
+			webhook.ConsecutiveFailures = 0
+		}
 		webhook.Active = *input.Active
 	}
 
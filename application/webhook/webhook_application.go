@@ -1,6 +1,7 @@
 package webhook
 
 import (
+	"github.com/labbs/nexo/application/ports"
 	"github.com/labbs/nexo/domain"
 	"github.com/labbs/nexo/infrastructure/config"
 	"github.com/rs/zerolog"
@@ -11,6 +12,8 @@ type WebhookApplication struct {
 	Logger              zerolog.Logger
 	WebhookPers         domain.WebhookPers
 	WebhookDeliveryPers domain.WebhookDeliveryPers
+
+	PermissionApplication ports.PermissionPort
 }
 
 func NewWebhookApplication(config config.Config, logger zerolog.Logger, webhookPers domain.WebhookPers, webhookDeliveryPers domain.WebhookDeliveryPers) *WebhookApplication {
@@ -7,6 +7,7 @@ import (
 	"os"
 
 	genoapi "github.com/labbs/nexo/interfaces/cli/gen-oapi"
+	"github.com/labbs/nexo/interfaces/cli/importer"
 	"github.com/labbs/nexo/interfaces/cli/migration"
 	"github.com/labbs/nexo/interfaces/cli/server"
 
@@ -41,6 +42,7 @@ func main() {
 			server.NewInstance(version),
 			migration.NewInstance(version),
 			genoapi.NewInstance(version),
+			importer.NewInstance(version),
 		},
 	}
 
@@ -26,7 +26,7 @@ type Action struct {
 	TriggerConfig JSONB // Trigger-specific configuration
 
 	// Action steps to execute
-	Steps JSONB // [{type, config}]
+	Steps JSONBArray // [{type, config}]
 
 	// Status
 	Active       bool
@@ -36,6 +36,10 @@ type Action struct {
 	SuccessCount int
 	FailureCount int
 
+	// ScheduledRunAt is the last scheduled run claimed by a node, see
+	// ActionPers.ClaimScheduledRun
+	ScheduledRunAt *time.Time
+
 	CreatedAt time.Time
 	UpdatedAt time.Time
 	DeletedAt gorm.DeletedAt
@@ -95,6 +99,9 @@ const (
 	StepAddComment        ActionStepType = "add_comment"
 	StepAssignUser        ActionStepType = "assign_user"
 	StepSetReminder       ActionStepType = "set_reminder"
+
+	// Control flow
+	StepIf                ActionStepType = "if"
 )
 
 type ActionPers interface {
@@ -107,6 +114,9 @@ type ActionPers interface {
 	IncrementSuccess(id string) error
 	RecordFailure(id string, errorMsg string) error
 	UpdateLastRun(id string) error
+	// ClaimScheduledRun claims the run of a scheduled action due at runAt.
+	// It returns false when another node already claimed it.
+	ClaimScheduledRun(id string, runAt time.Time) (bool, error)
 }
 
 // ActionRun records individual action execution
@@ -40,9 +40,43 @@ const (
 	ApiKeyScopeReadComments    ApiKeyScope = "read:comments"
 	ApiKeyScopeWriteComments   ApiKeyScope = "write:comments"
 	ApiKeyScopeManageWebhooks  ApiKeyScope = "manage:webhooks"
+	ApiKeyScopeReadDatabases   ApiKeyScope = "read:databases"
 	ApiKeyScopeManageDatabases ApiKeyScope = "manage:databases"
+	ApiKeyScopeReadDrawings    ApiKeyScope = "read:drawings"
+	ApiKeyScopeWriteDrawings   ApiKeyScope = "write:drawings"
+	ApiKeyScopeManageActions   ApiKeyScope = "manage:actions"
+
+	// ApiKeyScopeSession is held by session tokens only, it cannot be granted
+	// to an API key: routes requiring it cannot be called with an API key
+	ApiKeyScopeSession ApiKeyScope = "session"
 )
 
+// ApiKeyScopes are the scopes that can be granted to an API key
+var ApiKeyScopes = []ApiKeyScope{
+	ApiKeyScopeReadDocuments,
+	ApiKeyScopeWriteDocuments,
+	ApiKeyScopeReadSpaces,
+	ApiKeyScopeWriteSpaces,
+	ApiKeyScopeReadComments,
+	ApiKeyScopeWriteComments,
+	ApiKeyScopeManageWebhooks,
+	ApiKeyScopeReadDatabases,
+	ApiKeyScopeManageDatabases,
+	ApiKeyScopeReadDrawings,
+	ApiKeyScopeWriteDrawings,
+	ApiKeyScopeManageActions,
+}
+
+// IsValid reports whether the scope can be granted to an API key
+func (s ApiKeyScope) IsValid() bool {
+	for _, scope := range ApiKeyScopes {
+		if scope == s {
+			return true
+		}
+	}
+	return false
+}
+
 func (k *ApiKey) HasScope(scope ApiKeyScope) bool {
 	if k.Permissions == nil {
 		return false
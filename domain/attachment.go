@@ -0,0 +1,63 @@
+package domain
+
+import (
+	"io"
+	"time"
+)
+
+// AttachmentResourceType is the kind of resource a file is attached to.
+type AttachmentResourceType string
+
+const (
+	AttachmentResourceDocument AttachmentResourceType = "document"
+	AttachmentResourceDrawing  AttachmentResourceType = "drawing"
+	AttachmentResourceDatabase AttachmentResourceType = "database"
+)
+
+func (t AttachmentResourceType) IsValid() bool {
+	switch t {
+	case AttachmentResourceDocument, AttachmentResourceDrawing, AttachmentResourceDatabase:
+		return true
+	}
+	return false
+}
+
+// Attachment is a file uploaded to a document, a drawing or a database. The
+// content is kept in the file storage under StorageKey, the access follows
+// the permissions of the resource.
+type Attachment struct {
+	Id string
+
+	SpaceId      string
+	ResourceType AttachmentResourceType
+	ResourceId   string
+
+	Name        string
+	ContentType string
+	Size        int64
+	StorageKey  string
+
+	CreatedBy string
+	User      User `gorm:"foreignKey:CreatedBy;references:Id"`
+
+	CreatedAt time.Time
+}
+
+func (a *Attachment) TableName() string {
+	return "attachment"
+}
+
+type AttachmentPers interface {
+	Create(attachment *Attachment) error
+	GetById(id string) (*Attachment, error)
+	GetByResource(resourceType AttachmentResourceType, resourceId string) ([]Attachment, error)
+	Delete(id string) error
+}
+
+// FileStorage stores the content of attachments: on the local disk or in an
+// S3-compatible bucket.
+type FileStorage interface {
+	Put(key string, content io.Reader, size int64, contentType string) error
+	Get(key string) (io.ReadCloser, error)
+	Delete(key string) error
+}
@@ -0,0 +1,37 @@
+package domain
+
+import "time"
+
+// CollaborationUpdate is a Y.js update received in a collaboration room. The
+// updates of a room, merged, give the state of its shared document; they are
+// compacted from time to time into a single update.
+type CollaborationUpdate struct {
+	Id int64 `gorm:"primaryKey;autoIncrement"`
+
+	// Room id: "document:{id}", "drawing:{id}", "row:{databaseId}:{rowId}"
+	RoomId string
+	Data   []byte
+
+	// User who sent the update
+	UserId string
+
+	// Compacted updates merge the previous updates of the room
+	Compacted bool
+
+	CreatedAt time.Time
+}
+
+func (u *CollaborationUpdate) TableName() string {
+	return "collaboration_update"
+}
+
+type CollaborationUpdatePers interface {
+	Append(update *CollaborationUpdate) error
+	GetByRoomId(roomId string) ([]CollaborationUpdate, error)
+	// Replace the updates of a room up to lastId with the compacted ones
+	Compact(roomId string, lastId int64, updates []CollaborationUpdate) error
+	// Rooms with updates received since their last compaction
+	GetRoomsToCompact() ([]string, error)
+	// Delete the updates of a room whose content was replaced
+	DeleteByRoomId(roomId string) error
+}
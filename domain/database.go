@@ -78,6 +78,8 @@ const (
 	PropertyTypeUpdatedBy   PropertyType = "updated_by"
 	PropertyTypeFiles       PropertyType = "files"
 	PropertyTypePerson      PropertyType = "person"
+	PropertyTypeCurrency    PropertyType = "currency"
+	PropertyTypeImage       PropertyType = "image"
 )
 
 // ViewType defines the types of database views
@@ -100,6 +102,8 @@ type DatabasePers interface {
 	Update(database *Database) error
 	Delete(id string) error
 	Search(query string, userId string, spaceId *string, limit int) ([]Database, error)
+	// GetWithFormulas returns the databases whose schema may have formulas
+	GetWithFormulas() ([]Database, error)
 }
 
 // DatabaseRow represents a row/page in a database
@@ -133,14 +137,60 @@ func (r *DatabaseRow) TableName() string {
 	return "database_row"
 }
 
-// FilterRule defines a single filter condition
+// DatabaseRowRelation links a row to a row of the same or another database
+// through a relation property. Two-way relations are stored in both
+// directions, each side under its own relation property.
+type DatabaseRowRelation struct {
+	Id string
+
+	PropertyId string
+	DatabaseId string
+	RowId      string
+
+	RelatedDatabaseId string
+	RelatedRowId      string
+
+	// Order of the related row in the relation property
+	Position int
+
+	CreatedAt time.Time
+}
+
+func (r *DatabaseRowRelation) TableName() string {
+	return "database_row_relation"
+}
+
+// RelationProperty is a relation property of a database schema
+type RelationProperty struct {
+	DatabaseId        string
+	PropertyId        string
+	RelatedDatabaseId string
+	RelatedPropertyId string // relation property of the other side, empty for one-way relations
+}
+
+// FilterRule defines a single filter condition, or a nested group when And or
+// Or is set (see database_filter.go for the conditions of each property type)
 type FilterRule struct {
-	Property  string      `json:"property"`
-	Condition string      `json:"condition"` // eq, neq, gt, lt, gte, lte, contains, is_empty, is_not_empty
+	Property  string      `json:"property,omitempty"`
+	Condition string      `json:"condition,omitempty"`
 	Value     any `json:"value,omitempty"`
+
+	// Nested group
+	And []FilterRule `json:"and,omitempty"`
+	Or  []FilterRule `json:"or,omitempty"`
+
+	// Type of the property, resolved from the database schema (not stored)
+	Type PropertyType `json:"-"`
 }
 
-// FilterConfig defines the filter configuration with AND/OR groups
+// IsGroup reports whether the rule is a nested group of rules
+func (r FilterRule) IsGroup() bool {
+	return len(r.And) > 0 || len(r.Or) > 0
+}
+
+// FilterConfig defines the filter configuration with AND/OR groups. Every
+// "and" rule must match and, when there are "or" rules, at least one of them
+// must match; rules may themselves be groups.
 type FilterConfig struct {
 	And []FilterRule `json:"and,omitempty"`
 	Or  []FilterRule `json:"or,omitempty"`
@@ -168,6 +218,13 @@ type DatabaseRowPers interface {
 	GetRowCount(databaseId string) (int64, error)
 	GetRowCountWithFilter(databaseId string, filter *FilterConfig) (int64, error)
 	Update(row *DatabaseRow) error
+	UpdateProperties(id string, properties JSONB) error
 	Delete(id string) error
 	BulkDelete(ids []string) error
+	GetByIds(ids []string) ([]DatabaseRow, error)
+
+	// Relations
+	SetRelations(relation RelationProperty, rowId string, relatedRowIds []string) error
+	GetRelations(propertyId string, rowIds []string) (map[string][]string, error)
+	DeleteRelations(rowIds []string) error
 }
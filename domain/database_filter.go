@@ -0,0 +1,264 @@
+package domain
+
+import (
+	"slices"
+	"time"
+)
+
+// Filter conditions of database views
+const (
+	FilterEq           = "eq"
+	FilterNeq          = "neq"
+	FilterGt           = "gt"
+	FilterLt           = "lt"
+	FilterGte          = "gte"
+	FilterLte          = "lte"
+	FilterContains     = "contains"
+	FilterNotContains  = "not_contains"
+	FilterStartsWith   = "starts_with"
+	FilterEndsWith     = "ends_with"
+	FilterIsEmpty      = "is_empty"
+	FilterIsNotEmpty   = "is_not_empty"
+	FilterIsAnyOf      = "is_any_of"
+	FilterIsNoneOf     = "is_none_of"
+	FilterContainsAny  = "contains_any"
+	FilterContainsAll  = "contains_all"
+	FilterBetween      = "between"
+	FilterBefore       = "before"
+	FilterAfter        = "after"
+	FilterOnOrBefore   = "on_or_before"
+	FilterOnOrAfter    = "on_or_after"
+	FilterRelative     = "relative"
+	FilterIsChecked    = "is_checked"
+	FilterIsNotChecked = "is_not_checked"
+)
+
+// FilterValueKind describes the value expected by a filter condition, so that
+// the UI can render the right input
+type FilterValueKind string
+
+const (
+	FilterValueNone     FilterValueKind = "none"     // no value
+	FilterValueText     FilterValueKind = "text"     // a string
+	FilterValueNumber   FilterValueKind = "number"   // a number
+	FilterValueBoolean  FilterValueKind = "boolean"  // true or false
+	FilterValueOption   FilterValueKind = "option"   // a select option, user or row id
+	FilterValueOptions  FilterValueKind = "options"  // a list of options, users or row ids
+	FilterValueDate     FilterValueKind = "date"     // YYYY-MM-DD or RFC 3339
+	FilterValueRange    FilterValueKind = "range"    // [from, to], numbers or dates
+	FilterValueRelative FilterValueKind = "relative" // one of the RelativeDates
+)
+
+// FilterCondition is a condition allowed on a property type
+type FilterCondition struct {
+	Condition string
+	Value     FilterValueKind
+}
+
+// RelativeDates are the values of the "relative" date condition
+var RelativeDates = []string{
+	"today", "yesterday", "tomorrow",
+	"this_week", "last_week", "next_week",
+	"this_month", "last_month", "next_month",
+	"this_year", "last_year", "next_year",
+	"past_7_days", "past_30_days", "next_7_days", "next_30_days",
+}
+
+var (
+	textConditions = []FilterCondition{
+		{FilterEq, FilterValueText},
+		{FilterNeq, FilterValueText},
+		{FilterContains, FilterValueText},
+		{FilterNotContains, FilterValueText},
+		{FilterStartsWith, FilterValueText},
+		{FilterEndsWith, FilterValueText},
+		{FilterIsEmpty, FilterValueNone},
+		{FilterIsNotEmpty, FilterValueNone},
+	}
+	numberConditions = []FilterCondition{
+		{FilterEq, FilterValueNumber},
+		{FilterNeq, FilterValueNumber},
+		{FilterGt, FilterValueNumber},
+		{FilterLt, FilterValueNumber},
+		{FilterGte, FilterValueNumber},
+		{FilterLte, FilterValueNumber},
+		{FilterBetween, FilterValueRange},
+		{FilterIsEmpty, FilterValueNone},
+		{FilterIsNotEmpty, FilterValueNone},
+	}
+	selectConditions = []FilterCondition{
+		{FilterEq, FilterValueOption},
+		{FilterNeq, FilterValueOption},
+		{FilterIsAnyOf, FilterValueOptions},
+		{FilterIsNoneOf, FilterValueOptions},
+		{FilterIsEmpty, FilterValueNone},
+		{FilterIsNotEmpty, FilterValueNone},
+	}
+	listConditions = []FilterCondition{
+		{FilterContains, FilterValueOption},
+		{FilterNotContains, FilterValueOption},
+		{FilterContainsAny, FilterValueOptions},
+		{FilterContainsAll, FilterValueOptions},
+		{FilterIsEmpty, FilterValueNone},
+		{FilterIsNotEmpty, FilterValueNone},
+	}
+	dateConditions = []FilterCondition{
+		{FilterEq, FilterValueDate},
+		{FilterBefore, FilterValueDate},
+		{FilterAfter, FilterValueDate},
+		{FilterOnOrBefore, FilterValueDate},
+		{FilterOnOrAfter, FilterValueDate},
+		{FilterBetween, FilterValueRange},
+		{FilterRelative, FilterValueRelative},
+		{FilterIsEmpty, FilterValueNone},
+		{FilterIsNotEmpty, FilterValueNone},
+	}
+	// created_time and updated_time are never empty
+	timestampConditions = dateConditions[:len(dateConditions)-2]
+	checkboxConditions  = []FilterCondition{
+		{FilterIsChecked, FilterValueNone},
+		{FilterIsNotChecked, FilterValueNone},
+		{FilterEq, FilterValueBoolean},
+	}
+	userConditions = []FilterCondition{
+		{FilterEq, FilterValueOption},
+		{FilterNeq, FilterValueOption},
+		{FilterIsAnyOf, FilterValueOptions},
+		{FilterIsNoneOf, FilterValueOptions},
+	}
+	// The result of a formula can be text, a number, a date or a boolean
+	formulaConditions = []FilterCondition{
+		{FilterEq, FilterValueText},
+		{FilterNeq, FilterValueText},
+		{FilterGt, FilterValueText},
+		{FilterLt, FilterValueText},
+		{FilterGte, FilterValueText},
+		{FilterLte, FilterValueText},
+		{FilterBetween, FilterValueRange},
+		{FilterContains, FilterValueText},
+		{FilterNotContains, FilterValueText},
+		{FilterStartsWith, FilterValueText},
+		{FilterEndsWith, FilterValueText},
+		{FilterIsChecked, FilterValueNone},
+		{FilterIsNotChecked, FilterValueNone},
+		{FilterIsEmpty, FilterValueNone},
+		{FilterIsNotEmpty, FilterValueNone},
+	}
+	filesConditions = []FilterCondition{
+		{FilterIsEmpty, FilterValueNone},
+		{FilterIsNotEmpty, FilterValueNone},
+	}
+)
+
+// dateConditionAliases maps the comparison conditions used on dates before
+// the date conditions existed
+var dateConditionAliases = map[string]string{
+	FilterGt:  FilterAfter,
+	FilterLt:  FilterBefore,
+	FilterGte: FilterOnOrAfter,
+	FilterLte: FilterOnOrBefore,
+}
+
+// FilterConditions returns the conditions allowed on a property type. Types
+// without conditions (rollup) cannot be filtered on.
+func (t PropertyType) FilterConditions() []FilterCondition {
+	switch t {
+	case PropertyTypeTitle, PropertyTypeText, PropertyTypeUrl, PropertyTypeEmail, PropertyTypePhone, PropertyTypeImage:
+		return textConditions
+	case PropertyTypeNumber, PropertyTypeCurrency:
+		return numberConditions
+	case PropertyTypeSelect:
+		return selectConditions
+	case PropertyTypeMultiSelect, PropertyTypePerson, PropertyTypeRelation:
+		return listConditions
+	case PropertyTypeDate:
+		return dateConditions
+	case PropertyTypeCreatedTime, PropertyTypeUpdatedTime:
+		return timestampConditions
+	case PropertyTypeCheckbox:
+		return checkboxConditions
+	case PropertyTypeCreatedBy, PropertyTypeUpdatedBy:
+		return userConditions
+	case PropertyTypeFormula:
+		return formulaConditions
+	case PropertyTypeFiles:
+		return filesConditions
+	default:
+		return nil
+	}
+}
+
+// FilterCondition returns the condition of the property type, false when the
+// condition is not allowed on this type. Legacy comparisons on dates (gt, lt,
+// gte, lte) are returned as their date condition.
+func (t PropertyType) FilterCondition(condition string) (FilterCondition, bool) {
+	if alias, ok := dateConditionAliases[condition]; ok && t.IsDate() {
+		condition = alias
+	}
+	conditions := t.FilterConditions()
+	i := slices.IndexFunc(conditions, func(c FilterCondition) bool { return c.Condition == condition })
+	if i < 0 {
+		return FilterCondition{}, false
+	}
+	return conditions[i], true
+}
+
+// IsDate reports whether values of the property type are dates
+func (t PropertyType) IsDate() bool {
+	return t == PropertyTypeDate || t == PropertyTypeCreatedTime || t == PropertyTypeUpdatedTime
+}
+
+// IsList reports whether values of the property type are lists
+func (t PropertyType) IsList() bool {
+	switch t {
+	case PropertyTypeMultiSelect, PropertyTypePerson, PropertyTypeRelation, PropertyTypeFiles:
+		return true
+	}
+	return false
+}
+
+// RelativeDateRange returns the [start, end) range of a relative date value,
+// computed in UTC. Weeks start on Monday.
+func RelativeDateRange(value string, now time.Time) (start, end time.Time, ok bool) {
+	now = now.UTC()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	week := today.AddDate(0, 0, -((int(today.Weekday()) + 6) % 7))
+	month := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+	year := time.Date(now.Year(), time.January, 1, 0, 0, 0, 0, time.UTC)
+
+	switch value {
+	case "today":
+		return today, today.AddDate(0, 0, 1), true
+	case "yesterday":
+		return today.AddDate(0, 0, -1), today, true
+	case "tomorrow":
+		return today.AddDate(0, 0, 1), today.AddDate(0, 0, 2), true
+	case "this_week":
+		return week, week.AddDate(0, 0, 7), true
+	case "last_week":
+		return week.AddDate(0, 0, -7), week, true
+	case "next_week":
+		return week.AddDate(0, 0, 7), week.AddDate(0, 0, 14), true
+	case "this_month":
+		return month, month.AddDate(0, 1, 0), true
+	case "last_month":
+		return month.AddDate(0, -1, 0), month, true
+	case "next_month":
+		return month.AddDate(0, 1, 0), month.AddDate(0, 2, 0), true
+	case "this_year":
+		return year, year.AddDate(1, 0, 0), true
+	case "last_year":
+		return year.AddDate(-1, 0, 0), year, true
+	case "next_year":
+		return year.AddDate(1, 0, 0), year.AddDate(2, 0, 0), true
+	case "past_7_days":
+		return today.AddDate(0, 0, -6), today.AddDate(0, 0, 1), true
+	case "past_30_days":
+		return today.AddDate(0, 0, -29), today.AddDate(0, 0, 1), true
+	case "next_7_days":
+		return today, today.AddDate(0, 0, 7), true
+	case "next_30_days":
+		return today, today.AddDate(0, 0, 30), true
+	}
+	return time.Time{}, time.Time{}, false
+}
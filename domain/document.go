@@ -28,6 +28,15 @@ type Document struct {
 	// Permissions spécifiques au document (optionnelles)
 	Permissions []Permission `gorm:"foreignKey:DocumentId;references:Id"`
 
+	// BreakInheritance stops the document and its sub pages from inheriting
+	// the permissions of the parent documents and of the space: only their
+	// own permissions apply, and the admins of the space keep edit access.
+	BreakInheritance bool
+	// Ancestors are the parent documents, the closest first, with the
+	// permissions of the user. They are not stored, the persistence loads
+	// them to resolve the inherited permissions.
+	Ancestors []Document `gorm:"-"`
+
 	Content datatypes.JSON
 
 	Position int
@@ -76,37 +85,68 @@ func (dc *DocumentConfig) Scan(value any) error {
 	}
 }
 
-func (d *Document) HasPermission(userId string, requiredRole PermissionRole) bool {
-	// 1. Vérifier les permissions spécifiques au document d'abord
-	for _, perm := range d.Permissions {
-		if perm.UserId != nil && *perm.UserId == userId {
-			if perm.Role == PermissionRoleDenied {
-				return false // Refus explicite
-			}
-			return d.documentRoleHasPermission(perm.Role, requiredRole)
-		}
+// GetUserRole returns the role of the user on the document: the role its
+// permissions give to the user or their groups, else the role inherited from
+// the parent document, up to the space (viewer or editor). It returns nil
+// without access.
+func (d *Document) GetUserRole(userId string) *PermissionRole {
+	return d.EffectivePermission(userId).Role
+}
+
+// EffectivePermission returns the role of the user on the document, as
+// GetUserRole, with the grants it results from.
+func (d *Document) EffectivePermission(userId string) EffectivePermission {
+	// 1. Vérifier les permissions spécifiques au document d'abord (de l'user
+	// ou de ses groupes)
+	effective := ExplainPermissions(d.Permissions, userId)
+
+	// 2. Si pas de permission spécifique, hériter du parent
+	if !d.BreakInheritance && len(d.Ancestors) > 0 {
+		parent := d.Ancestors[0]
+		parent.Ancestors = d.Ancestors[1:]
+		parent.Space = d.Space
+		inherited := parent.EffectivePermission(userId)
+		effective.Inherit(inherited, PermissionGrantSourceParent, PermissionTypeDocument, parent.Id, inherited.Role)
+		return effective
 	}
 
-	// 2. Si pas de permission spécifique, hériter du space
-	if d.Space.HasPermission(userId, PermissionRoleViewer) {
-		// Si l'user a accès au space, il peut au moins voir le document
-		if requiredRole == PermissionRoleViewer {
-			return true
-		}
-		// Pour éditer, il faut au moins être editor du space
-		if requiredRole == PermissionRoleEditor {
-			return d.Space.HasPermission(userId, PermissionRoleEditor)
+	// 3. Sinon hériter du space, seulement ses admins quand l'héritage est rompu
+	space := d.Space.EffectivePermission(userId)
+	var inherited *PermissionRole
+	if space.Role != nil {
+		var role PermissionRole
+		switch {
+		case d.BreakInheritance && !space.Role.Includes(PermissionRoleAdmin):
+			role = PermissionRoleDenied
+		case space.Role.Includes(PermissionRoleEditor):
+			role = PermissionRoleEditor
+		case space.Role.Includes(PermissionRoleCommenter):
+			role = PermissionRoleCommenter
+		case space.Role.Includes(PermissionRoleViewer):
+			role = PermissionRoleViewer
+		default:
+			role = PermissionRoleDenied
 		}
+		inherited = &role
 	}
+	effective.Inherit(space, PermissionGrantSourceSpace, PermissionTypeSpace, d.SpaceId, inherited)
+	return effective
+}
 
-	return false
+func (d *Document) HasPermission(userId string, requiredRole PermissionRole) bool {
+	role := d.GetUserRole(userId)
+	if role == nil || *role == PermissionRoleDenied {
+		return false // Pas d'accès ou refus explicite
+	}
+	return d.documentRoleHasPermission(*role, requiredRole)
 }
 
 func (d *Document) documentRoleHasPermission(userRole, requiredRole PermissionRole) bool {
 	roleHierarchy := map[PermissionRole]int{
-		PermissionRoleViewer: 1,
-		PermissionRoleEditor: 2,
-		PermissionRoleOwner:  3,
+		PermissionRoleViewer:    1,
+		PermissionRoleCommenter: 2,
+		PermissionRoleEditor:    3,
+		PermissionRoleOwner:     4,
 	}
 	return roleHierarchy[userRole] >= roleHierarchy[requiredRole]
 }
@@ -114,11 +154,9 @@ func (d *Document) documentRoleHasPermission(userRole, requiredRole PermissionRo
 // CanManagePermissions returns true if the user can manage document permissions
 // This requires being owner of the document OR admin/owner of the space
 func (d *Document) CanManagePermissions(userId string) bool {
-	// Check if user is owner of this document
-	for _, perm := range d.Permissions {
-		if perm.UserId != nil && *perm.UserId == userId && perm.Role == PermissionRoleOwner {
-			return true
-		}
+	// Check if user is owner of this document, or of a parent it inherits from
+	if role := d.GetUserRole(userId); role != nil && *role == PermissionRoleOwner {
+		return true
 	}
 
 	// Check if user is admin or owner of the space
@@ -139,9 +177,8 @@ type DocumentPers interface {
 	Restore(documentId, userId string) error
 	// Public sharing
 	SetPublic(documentId string, public bool, userId string) error
+	SetBreakInheritance(documentId string, breakInheritance bool, userId string) error
 	GetPublicDocument(spaceId string, id *string, slug *string) (*Document, error)
-	// Search
-	Search(query string, userId string, spaceId *string, limit int) ([]Document, error)
 	// Reorder
 	Reorder(spaceId string, items []ReorderItem, userId string) error
 	GetMaxPosition(spaceId string, parentId *string) (int, error)
@@ -30,6 +30,16 @@ func (g *Group) TableName() string {
 	return "group"
 }
 
+// HasMember returns true if the user is among the loaded members of the group
+func (g *Group) HasMember(userId string) bool {
+	for _, member := range g.Members {
+		if member.Id == userId {
+			return true
+		}
+	}
+	return false
+}
+
 // GroupPers defines the persistence interface for groups
 type GroupPers interface {
 	Create(group *Group) error
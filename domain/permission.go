@@ -20,11 +20,12 @@ const (
 type PermissionRole string
 
 const (
-	PermissionRoleOwner  PermissionRole = "owner"  // Full control, can manage permissions
-	PermissionRoleAdmin  PermissionRole = "admin"  // Admin access (for spaces)
-	PermissionRoleEditor PermissionRole = "editor" // Can edit
-	PermissionRoleViewer PermissionRole = "viewer" // Read-only
-	PermissionRoleDenied PermissionRole = "denied" // Explicitly deny access
+	PermissionRoleOwner     PermissionRole = "owner"     // Full control, can manage permissions
+	PermissionRoleAdmin     PermissionRole = "admin"     // Admin access (for spaces)
+	PermissionRoleEditor    PermissionRole = "editor"    // Can edit
+	PermissionRoleCommenter PermissionRole = "commenter" // Can read and comment, not edit
+	PermissionRoleViewer    PermissionRole = "viewer"    // Read-only
+	PermissionRoleDenied    PermissionRole = "denied"    // Explicitly deny access
 )
 
 // Permission represents a unified permission entry for any resource type
@@ -60,12 +61,174 @@ func (p *Permission) TableName() string {
 	return "permission"
 }
 
+// permissionRoleLevels ranks the roles, a role includes the access of the
+// roles below it
+var permissionRoleLevels = map[PermissionRole]int{
+	PermissionRoleViewer:    1,
+	PermissionRoleCommenter: 2,
+	PermissionRoleEditor:    3,
+	PermissionRoleAdmin:     4,
+	PermissionRoleOwner:     5,
+}
+
+// Includes returns true if the role gives at least the access of the
+// required role. Denied includes nothing.
+func (r PermissionRole) Includes(requiredRole PermissionRole) bool {
+	return r != PermissionRoleDenied && permissionRoleLevels[r] >= permissionRoleLevels[requiredRole]
+}
+
+// ResolveRole applies the precedence rules of the permissions of a resource:
+//   - the permission of the user takes precedence over the permissions of
+//     their groups, whatever its role, so that a user can be excepted from
+//     the role of a group
+//   - otherwise a group denied wins over the other groups of the user
+//   - otherwise the highest role of their groups applies
+//
+// It returns nil when no permission applies to the user.
+func ResolveRole(userRole *PermissionRole, groupRoles []PermissionRole) *PermissionRole {
+	if userRole != nil {
+		return userRole
+	}
+	var resolved *PermissionRole
+	for _, role := range groupRoles {
+		if role == PermissionRoleDenied {
+			denied := PermissionRoleDenied
+			return &denied
+		}
+		if resolved == nil || permissionRoleLevels[role] > permissionRoleLevels[*resolved] {
+			resolved = &role
+		}
+	}
+	return resolved
+}
+
+// ResolvePermissions returns the role the permissions of a resource give to
+// a user, directly or through the groups they are a member of. The members
+// of the groups must be loaded, at least the user.
+func ResolvePermissions(permissions []Permission, userId string) *PermissionRole {
+	return ExplainPermissions(permissions, userId).Role
+}
+
+// ResourceId returns the id of the resource of the permission
+func (p *Permission) ResourceId() string {
+	var id *string
+	switch p.Type {
+	case PermissionTypeSpace:
+		id = p.SpaceId
+	case PermissionTypeDocument:
+		id = p.DocumentId
+	case PermissionTypeDatabase:
+		id = p.DatabaseId
+	case PermissionTypeDrawing:
+		id = p.DrawingId
+	}
+	if id == nil {
+		return ""
+	}
+	return *id
+}
+
+// PermissionGrantSource defines where a grant of an effective permission
+// comes from
+type PermissionGrantSource string
+
+const (
+	PermissionGrantSourceOwner  PermissionGrantSource = "owner"  // The user owns the space
+	PermissionGrantSourceUser   PermissionGrantSource = "user"   // Permission of the user
+	PermissionGrantSourceGroup  PermissionGrantSource = "group"  // Permission of a group of the user
+	PermissionGrantSourcePublic PermissionGrantSource = "public" // Read access to a public space
+	PermissionGrantSourceSpace  PermissionGrantSource = "space"  // Role inherited from the space
+	PermissionGrantSourceParent PermissionGrantSource = "parent" // Role inherited from the parent document
+)
+
+// PermissionGrant is one of the grants an effective permission results from
+type PermissionGrant struct {
+	Source       PermissionGrantSource
+	ResourceType PermissionType
+	ResourceId   string
+	// GroupId and GroupName are set for the permissions of groups
+	GroupId   *string
+	GroupName string
+	Role      PermissionRole
+	// Applied is false when another grant took precedence
+	Applied bool
+}
+
+// EffectivePermission is the role of a user on a resource, nil without
+// access, with the grants it results from in the order they are resolved:
+// the grants of the parent resources come first.
+type EffectivePermission struct {
+	Role   *PermissionRole
+	Grants []PermissionGrant
+}
+
+// ExplainPermissions resolves the permissions of a resource for a user like
+// ResolvePermissions, and returns the grants which applied to them.
+func ExplainPermissions(permissions []Permission, userId string) EffectivePermission {
+	var userRole *PermissionRole
+	var groupRoles []PermissionRole
+	var grants []PermissionGrant
+	for _, perm := range permissions {
+		grant := PermissionGrant{ResourceType: perm.Type, ResourceId: perm.ResourceId(), Role: perm.Role}
+		switch {
+		case perm.UserId != nil && *perm.UserId == userId:
+			role := perm.Role
+			userRole = &role
+			grant.Source = PermissionGrantSourceUser
+		case perm.GroupId != nil && perm.Group != nil && perm.Group.HasMember(userId):
+			groupRoles = append(groupRoles, perm.Role)
+			grant.Source = PermissionGrantSourceGroup
+			grant.GroupId = perm.GroupId
+			grant.GroupName = perm.Group.Name
+		default:
+			continue
+		}
+		grants = append(grants, grant)
+	}
+
+	role := ResolveRole(userRole, groupRoles)
+	for i := range grants {
+		if userRole != nil {
+			grants[i].Applied = grants[i].Source == PermissionGrantSourceUser
+		} else {
+			grants[i].Applied = role != nil && grants[i].Role == *role
+		}
+	}
+	return EffectivePermission{Role: role, Grants: grants}
+}
+
+// Inherit gives the resource the role inherited from its parent when none of
+// its own grants applies. The grants of the parent come first, followed by
+// the inherited role when there is one.
+func (e *EffectivePermission) Inherit(parent EffectivePermission, source PermissionGrantSource, parentType PermissionType, parentId string, role *PermissionRole) {
+	grants := append([]PermissionGrant{}, parent.Grants...)
+	if role != nil {
+		grants = append(grants, PermissionGrant{
+			Source:       source,
+			ResourceType: parentType,
+			ResourceId:   parentId,
+			Role:         *role,
+			Applied:      e.Role == nil,
+		})
+		if e.Role == nil {
+			e.Role = role
+		}
+	}
+	e.Grants = append(grants, e.Grants...)
+}
+
 // PermissionPers is the persistence interface for permissions
 type PermissionPers interface {
 	// Generic methods
 	ListByResource(resourceType PermissionType, resourceId string) ([]Permission, error)
 	GetByResourceAndUser(resourceType PermissionType, resourceId, userId string) (*Permission, error)
 	GetByResourceAndGroup(resourceType PermissionType, resourceId, groupId string) (*Permission, error)
+	// ListByResourceForUser returns the permissions of the user and of their
+	// groups on a resource, with the group and the membership of the user
+	ListByResourceForUser(resourceType PermissionType, resourceId, userId string) ([]Permission, error)
+	// ListByResourcesForUser is ListByResourceForUser for several resources
+	// of the same type
+	ListByResourcesForUser(resourceType PermissionType, resourceIds []string, userId string) ([]Permission, error)
 	UpsertUser(resourceType PermissionType, resourceId, userId string, role PermissionRole) error
 	UpsertGroup(resourceType PermissionType, resourceId, groupId string, role PermissionRole) error
 	DeleteUser(resourceType PermissionType, resourceId, userId string) error
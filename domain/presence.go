@@ -0,0 +1,28 @@
+package domain
+
+import "time"
+
+// Presence is a user connected to a collaboration room. The connections of
+// the user to the room (browser tabs, devices, nodes) are merged.
+type Presence struct {
+	UserId    string `json:"user_id"`
+	Username  string `json:"username"`
+	AvatarUrl string `json:"avatar_url"`
+
+	Connections int `json:"connections"`
+	// ReadOnly is true when none of the connections can edit
+	ReadOnly bool `json:"read_only"`
+
+	ConnectedAt    time.Time `json:"connected_at"`
+	LastActivityAt time.Time `json:"last_activity_at"`
+}
+
+// RoomPresence is an active collaboration room with its users
+type RoomPresence struct {
+	RoomId string
+	// Resource edited in the room: "document", "drawing" or "row"
+	ResourceType string
+	ResourceId   string
+
+	Users []Presence
+}
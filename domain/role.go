@@ -7,3 +7,10 @@ const (
 	RoleAdmin Role = "admin"
 	RoleGest  Role = "guest"
 )
+
+// ReadsPublicSpaces reports whether the users of the role can read the
+// public spaces: guests only access what is shared with them, and users
+// whose role is unknown nothing.
+func (r Role) ReadsPublicSpaces() bool {
+	return r == RoleUser || r == RoleAdmin
+}
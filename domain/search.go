@@ -0,0 +1,100 @@
+package domain
+
+import "time"
+
+// SearchResourceType is the type of a resource of the search index
+type SearchResourceType string
+
+const (
+	SearchResourceDocument SearchResourceType = "document"
+	SearchResourceRow      SearchResourceType = "row"
+	SearchResourceDrawing  SearchResourceType = "drawing"
+	SearchResourceComment  SearchResourceType = "comment"
+)
+
+// SearchResourceTypes are the resource types that can be searched
+var SearchResourceTypes = []SearchResourceType{
+	SearchResourceDocument,
+	SearchResourceRow,
+	SearchResourceDrawing,
+	SearchResourceComment,
+}
+
+// SearchEntry is the plain text of a resource in the search index. Document
+// and comment entries have a DocumentId, row entries a DatabaseId: the
+// permissions of these resources apply to the entry.
+type SearchEntry struct {
+	Id int64 `gorm:"primaryKey;autoIncrement"`
+
+	ResourceType SearchResourceType
+	ResourceId   string
+
+	SpaceId    string
+	DocumentId *string
+	DatabaseId *string
+
+	Title   string
+	Icon    string
+	Content string
+
+	// Blocks holds the text of each block of a document, to find the block
+	// matching a search
+	Blocks JSONBArray // [{id, text}]
+
+	UpdatedAt time.Time
+}
+
+func (e *SearchEntry) TableName() string {
+	return "search_index"
+}
+
+// SearchQuery is a full-text search of the resources a user can read
+type SearchQuery struct {
+	Terms   []string
+	UserId  string
+	SpaceId *string
+	Types   []SearchResourceType
+	Limit   int
+	Offset  int
+}
+
+// SearchHit is a resource matching a search, best first. In the snippet, the
+// matched terms are between SearchHighlightStart and SearchHighlightEnd.
+type SearchHit struct {
+	ResourceType SearchResourceType
+	ResourceId   string
+	SpaceId      string
+	SpaceName    string
+	DocumentId   *string
+	DatabaseId   *string
+
+	Title   string
+	Snippet string
+	Rank    float64
+	Blocks  JSONBArray
+
+	// Slug of the document, for documents and comments
+	Slug string
+	Icon string
+
+	UpdatedAt time.Time
+}
+
+// Markers of the matched terms in search snippets (Unicode private use)
+const (
+	SearchHighlightStart = "\uE000"
+	SearchHighlightEnd   = "\uE001"
+)
+
+type SearchPers interface {
+	// Index replaces the entry of a resource
+	Index(entry *SearchEntry) error
+	Remove(resourceType SearchResourceType, resourceIds []string) error
+	Search(query SearchQuery) ([]SearchHit, error)
+
+	// Resources that are not indexed yet, to build the index of existing data
+	UnindexedDocuments(limit int) ([]Document, error)
+	UnindexedRows(limit int) ([]DatabaseRow, error)
+	UnindexedDrawings(limit int) ([]Drawing, error)
+	UnindexedComments(limit int) ([]Comment, error)
+}
@@ -23,6 +23,12 @@ type Space struct {
 
 	Permissions []Permission `gorm:"foreignKey:SpaceId;references:Id"`
 
+	// RequesterId and RequesterRole are the user the space was loaded for and
+	// their role on the instance, selected along with the space. Public
+	// spaces give no access to guests, nor to the users it was not loaded for.
+	RequesterId   string `gorm:"->;-:migration"`
+	RequesterRole Role   `gorm:"->;-:migration"`
+
 	CreatedAt time.Time
 	UpdatedAt time.Time
 	DeletedAt gorm.DeletedAt
@@ -46,6 +52,8 @@ func (s *Space) TableName() string {
 	return "space"
 }
 
+// GetUserRole returns the role of the user in the space: owner for its
+// owner, else the role its permissions give to the user or their groups.
 func (s *Space) GetUserRole(userId string) *PermissionRole {
 	// Check if the user is the owner
 	if s.OwnerId != nil && *s.OwnerId == userId {
@@ -54,39 +62,57 @@ func (s *Space) GetUserRole(userId string) *PermissionRole {
 	}
 
 	// Check permissions
-	for _, perm := range s.Permissions {
-		if perm.UserId != nil && *perm.UserId == userId {
-			return &perm.Role
-		}
-	}
-
-	return nil
+	return ResolvePermissions(s.Permissions, userId)
 }
 
-func (s *Space) HasPermission(userId string, requiredRole PermissionRole) bool {
-	userRole := s.GetUserRole(userId)
-	if userRole == nil {
-		// For public spaces, allow reading
-		return s.Type == SpaceTypePublic && requiredRole == PermissionRoleViewer
+// EffectivePermission returns the role of the user in the space with the
+// grants it results from: owner for its owner, else the role its permissions
+// give to the user or their groups, else viewer for a public space unless
+// the user is a guest.
+func (s *Space) EffectivePermission(userId string) EffectivePermission {
+	effective := ExplainPermissions(s.Permissions, userId)
+	switch {
+	case s.OwnerId != nil && *s.OwnerId == userId:
+		// The owner keeps full control whatever the permissions
+		for i := range effective.Grants {
+			effective.Grants[i].Applied = false
+		}
+		role := PermissionRoleOwner
+		effective.Role = &role
+		effective.Grants = append([]PermissionGrant{{
+			Source:       PermissionGrantSourceOwner,
+			ResourceType: PermissionTypeSpace,
+			ResourceId:   s.Id,
+			Role:         role,
+			Applied:      true,
+		}}, effective.Grants...)
+	case effective.Role == nil && s.Type == SpaceTypePublic && s.RequesterId == userId && s.RequesterRole.ReadsPublicSpaces():
+		// For public spaces, allow reading, except to guests
+		role := PermissionRoleViewer
+		effective.Role = &role
+		effective.Grants = append(effective.Grants, PermissionGrant{
+			Source:       PermissionGrantSourcePublic,
+			ResourceType: PermissionTypeSpace,
+			ResourceId:   s.Id,
+			Role:         role,
+			Applied:      true,
+		})
 	}
-
-	return s.roleHasPermission(*userRole, requiredRole)
+	return effective
 }
 
-func (s *Space) roleHasPermission(userRole, requiredRole PermissionRole) bool {
-	roleHierarchy := map[PermissionRole]int{
-		PermissionRoleViewer: 1,
-		PermissionRoleEditor: 2,
-		PermissionRoleAdmin:  3,
-		PermissionRoleOwner:  4,
-	}
-	return roleHierarchy[userRole] >= roleHierarchy[requiredRole]
+func (s *Space) HasPermission(userId string, requiredRole PermissionRole) bool {
+	userRole := s.EffectivePermission(userId).Role
+	return userRole != nil && userRole.Includes(requiredRole)
 }
 
 type SpacePers interface {
 	Create(space *Space) error
 	GetSpacesForUser(userId string) ([]Space, error)
 	GetSpaceById(spaceId string) (*Space, error)
+	// GetSpaceForUser returns a space loaded for the user, whose public
+	// access it resolves
+	GetSpaceForUser(spaceId, userId string) (*Space, error)
 	Update(space *Space) error
 	Delete(spaceId string) error
 	// Admin methods
@@ -30,6 +30,9 @@ type Webhook struct {
 	SuccessCount int
 	FailureCount int
 
+	// Failed attempts since the last successful delivery, used to auto-disable the webhook
+	ConsecutiveFailures int
+
 	CreatedAt time.Time
 	UpdatedAt time.Time
 	DeletedAt gorm.DeletedAt
@@ -43,13 +46,22 @@ func (w *Webhook) TableName() string {
 type WebhookEvent string
 
 const (
-	WebhookEventDocumentCreated WebhookEvent = "document.created"
-	WebhookEventDocumentUpdated WebhookEvent = "document.updated"
-	WebhookEventDocumentDeleted WebhookEvent = "document.deleted"
-	WebhookEventCommentCreated  WebhookEvent = "comment.created"
-	WebhookEventCommentResolved WebhookEvent = "comment.resolved"
-	WebhookEventSpaceCreated    WebhookEvent = "space.created"
-	WebhookEventSpaceUpdated    WebhookEvent = "space.updated"
+	WebhookEventDocumentCreated  WebhookEvent = "document.created"
+	WebhookEventDocumentUpdated  WebhookEvent = "document.updated"
+	WebhookEventDocumentDeleted  WebhookEvent = "document.deleted"
+	WebhookEventDocumentMoved    WebhookEvent = "document.moved"
+	WebhookEventDocumentRestored WebhookEvent = "document.restored"
+	WebhookEventCommentCreated   WebhookEvent = "comment.created"
+	WebhookEventCommentUpdated   WebhookEvent = "comment.updated"
+	WebhookEventCommentDeleted   WebhookEvent = "comment.deleted"
+	WebhookEventCommentResolved  WebhookEvent = "comment.resolved"
+	WebhookEventSpaceCreated     WebhookEvent = "space.created"
+	WebhookEventSpaceUpdated     WebhookEvent = "space.updated"
+	WebhookEventSpaceDeleted     WebhookEvent = "space.deleted"
+	WebhookEventRowCreated       WebhookEvent = "row.created"
+	WebhookEventRowUpdated       WebhookEvent = "row.updated"
+	WebhookEventRowDeleted       WebhookEvent = "row.deleted"
+	WebhookEventPropertyChanged  WebhookEvent = "property.changed"
 )
 
 func (w *Webhook) HasEvent(event WebhookEvent) bool {
@@ -77,23 +89,45 @@ type WebhookPers interface {
 	Delete(id string) error
 	IncrementSuccess(id string) error
 	RecordFailure(id string, errorMsg string) error
+	// DisableIfFailing deactivates the webhook once it reached the given number
+	// of consecutive failures. Returns true if the webhook has been disabled.
+	DisableIfFailing(id string, maxConsecutiveFailures int) (bool, error)
 }
 
-// WebhookDelivery records individual delivery attempts
+// WebhookDeliveryStatus is the state of a delivery in the delivery queue
+type WebhookDeliveryStatus string
+
+const (
+	WebhookDeliveryStatusPending    WebhookDeliveryStatus = "pending"    // Waiting for its first attempt
+	WebhookDeliveryStatusDelivering WebhookDeliveryStatus = "delivering" // Claimed by a worker
+	WebhookDeliveryStatusRetrying   WebhookDeliveryStatus = "retrying"   // Last attempt failed, waiting for NextAttemptAt
+	WebhookDeliveryStatusSucceeded  WebhookDeliveryStatus = "succeeded"
+	WebhookDeliveryStatusFailed     WebhookDeliveryStatus = "failed" // Gave up after the maximum number of attempts
+)
+
+// WebhookDelivery is a queued event delivery for a webhook.
+// The result fields reflect the last attempt.
 type WebhookDelivery struct {
 	Id string
 
 	WebhookId string
 	Webhook   Webhook `gorm:"foreignKey:WebhookId;references:Id"`
 
-	Event      string
-	Payload    JSONB
+	Event   string
+	Payload JSONB // Full envelope sent to the receiver
+
+	Status        WebhookDeliveryStatus
+	Attempts      int
+	NextAttemptAt *time.Time
+	Error         string
+
 	StatusCode int
 	Response   string
 	Duration   int // milliseconds
 	Success    bool
 
 	CreatedAt time.Time
+	UpdatedAt time.Time
 }
 
 func (d *WebhookDelivery) TableName() string {
@@ -102,5 +136,13 @@ func (d *WebhookDelivery) TableName() string {
 
 type WebhookDeliveryPers interface {
 	Create(delivery *WebhookDelivery) error
+	GetById(id string) (*WebhookDelivery, error)
 	GetByWebhookId(webhookId string, limit int) ([]WebhookDelivery, error)
+	// GetDue returns the deliveries waiting for an attempt (including claims
+	// whose lease expired), with their webhook preloaded.
+	GetDue(now time.Time, limit int) ([]WebhookDelivery, error)
+	// Claim marks a due delivery as being delivered until leaseUntil.
+	// Returns false if another worker claimed it first.
+	Claim(id string, now time.Time, leaseUntil time.Time) (bool, error)
+	Update(delivery *WebhookDelivery) error
 }
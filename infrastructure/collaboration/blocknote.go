@@ -0,0 +1,149 @@
+package collaboration
+
+import (
+	"github.com/labbs/nexo/application/document/dto"
+	"github.com/labbs/nexo/infrastructure/collaboration/yjs"
+)
+
+// BlockNoteFragment is the name of the XML fragment BlockNote edits in the
+// Y.js document of a document room.
+const BlockNoteFragment = "document-store"
+
+// blockNoteBlocks converts the ProseMirror nodes of a BlockNote fragment
+// (blockGroup > blockContainer > block content and nested blockGroup) to
+// the blocks stored in the document content.
+func blockNoteBlocks(nodes []yjs.XmlNode) []dto.Block {
+	blocks := []dto.Block{}
+	for _, node := range nodes {
+		switch node.Name {
+		case "blockGroup":
+			blocks = append(blocks, blockNoteBlocks(node.Children)...)
+		case "blockContainer":
+			blocks = append(blocks, blockNoteBlock(node))
+		}
+	}
+	return blocks
+}
+
+func blockNoteBlock(container yjs.XmlNode) dto.Block {
+	block := dto.Block{
+		Props:    map[string]any{},
+		Content:  []dto.InlineContent{},
+		Children: []dto.Block{},
+	}
+	for key, value := range container.Attributes {
+		if key == "id" {
+			block.ID, _ = value.(string)
+			continue
+		}
+		block.Props[key] = value
+	}
+
+	for _, child := range container.Children {
+		switch {
+		case child.IsText():
+		case child.Name == "blockGroup":
+			block.Children = append(block.Children, blockNoteBlocks(child.Children)...)
+		default:
+			block.Type = child.Name
+			for key, value := range child.Attributes {
+				block.Props[key] = value
+			}
+			if child.Name == dto.BlockTypeTable {
+				block.TableContent = tableContent(child)
+				continue
+			}
+			block.Content = inlineContent(child.Children, block.Content)
+		}
+	}
+	return block
+}
+
+// tableContent converts the rows (table > tableRow > tableCell or
+// tableHeader > tableParagraph) of a table block.
+func tableContent(table yjs.XmlNode) *dto.TableContent {
+	content := &dto.TableContent{Type: "tableContent", Rows: []dto.TableRow{}}
+	for r, row := range table.Children {
+		if row.Name != "tableRow" {
+			continue
+		}
+		cells := []dto.TableCell{}
+		for _, node := range row.Children {
+			if node.Name != "tableCell" && node.Name != "tableHeader" {
+				continue
+			}
+			if node.Name == "tableHeader" && r == content.HeaderRows {
+				content.HeaderRows = r + 1
+			}
+			cell := dto.TableCell{Type: "tableCell", Props: map[string]any{}}
+			for key, value := range node.Attributes {
+				cell.Props[key] = value
+			}
+			cell.Content = inlineContent(node.Children, []dto.InlineContent{})
+			cells = append(cells, cell)
+		}
+		content.Rows = append(content.Rows, dto.TableRow{Cells: cells})
+	}
+	return content
+}
+
+// Elements wrapping the text of a block content (e.g. in table cells)
+var blockNoteTextWrappers = map[string]bool{
+	"paragraph":      true,
+	"tableParagraph": true,
+}
+
+// inlineContent converts the text runs of a block content with all their
+// styles, and the inline nodes (mentions, ...) with their attributes.
+func inlineContent(nodes []yjs.XmlNode, content []dto.InlineContent) []dto.InlineContent {
+	for _, node := range nodes {
+		switch {
+		case node.IsText():
+			for _, run := range node.Text {
+				content = append(content, textRun(run))
+			}
+		case blockNoteTextWrappers[node.Name]:
+			content = inlineContent(node.Children, content)
+		default:
+			inline := dto.InlineContent{Type: node.Name, Styles: map[string]bool{}}
+			if len(node.Attributes) > 0 {
+				inline.Props = node.Attributes
+			}
+			for _, child := range inlineContent(node.Children, nil) {
+				inline.Text += child.Text
+			}
+			content = append(content, inline)
+		}
+	}
+	return content
+}
+
+// textRun converts a run of text. Marks are stored as text attributes: the
+// link with its href, the styles with a value (textColor, backgroundColor)
+// with a stringValue, the other styles with no or empty attributes.
+func textRun(run yjs.TextRun) dto.InlineContent {
+	inline := dto.InlineContent{Type: "text", Text: run.Text, Styles: map[string]bool{}}
+	for key, value := range run.Attributes {
+		if value == nil {
+			continue
+		}
+		attrs, _ := value.(map[string]any)
+		if key == "link" {
+			inline.Type = "link"
+			inline.Href, _ = attrs["href"].(string)
+			continue
+		}
+		if styleValue, ok := attrs["stringValue"].(string); ok {
+			if inline.StyleValues == nil {
+				inline.StyleValues = map[string]string{}
+			}
+			inline.StyleValues[key] = styleValue
+			continue
+		}
+		if enabled, ok := value.(bool); ok && !enabled {
+			continue
+		}
+		inline.Styles[key] = true
+	}
+	return inline
+}
@@ -0,0 +1,124 @@
+package collaboration
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/labbs/nexo/application/document/dto"
+	"github.com/labbs/nexo/infrastructure/collaboration/yjs"
+)
+
+func text(runs ...yjs.TextRun) yjs.XmlNode {
+	return yjs.XmlNode{Text: runs}
+}
+
+func container(id string, children ...yjs.XmlNode) yjs.XmlNode {
+	return yjs.XmlNode{Name: "blockContainer", Attributes: map[string]any{"id": id}, Children: children}
+}
+
+func TestBlockNoteBlocks(t *testing.T) {
+	fragment := []yjs.XmlNode{{Name: "blockGroup", Children: []yjs.XmlNode{
+		container("heading",
+			yjs.XmlNode{Name: "heading", Attributes: map[string]any{"level": float64(2)}, Children: []yjs.XmlNode{
+				text(yjs.TextRun{Text: "Title"}),
+			}},
+		),
+		container("paragraph",
+			yjs.XmlNode{Name: "paragraph", Attributes: map[string]any{"textAlignment": "left"}, Children: []yjs.XmlNode{
+				text(
+					yjs.TextRun{Text: "plain "},
+					yjs.TextRun{Text: "bold", Attributes: map[string]any{"bold": true, "italic": map[string]any{}}},
+					yjs.TextRun{Text: " red", Attributes: map[string]any{"textColor": map[string]any{"stringValue": "red"}}},
+					yjs.TextRun{Text: " off", Attributes: map[string]any{"underline": false, "strike": nil}},
+					yjs.TextRun{Text: "link", Attributes: map[string]any{"link": map[string]any{"href": "https://example.com"}}},
+				),
+				{Name: "mention", Attributes: map[string]any{"user": "u1"}, Children: []yjs.XmlNode{text(yjs.TextRun{Text: "@ann"})}},
+			}},
+			yjs.XmlNode{Name: "blockGroup", Children: []yjs.XmlNode{
+				container("child", yjs.XmlNode{Name: "bulletListItem"}),
+			}},
+		),
+		{Name: "ignored"},
+	}}}
+
+	want := []dto.Block{
+		{
+			ID:       "heading",
+			Type:     "heading",
+			Props:    map[string]any{"level": float64(2)},
+			Content:  []dto.InlineContent{{Type: "text", Text: "Title", Styles: map[string]bool{}}},
+			Children: []dto.Block{},
+		},
+		{
+			ID:    "paragraph",
+			Type:  "paragraph",
+			Props: map[string]any{"textAlignment": "left"},
+			Content: []dto.InlineContent{
+				{Type: "text", Text: "plain ", Styles: map[string]bool{}},
+				{Type: "text", Text: "bold", Styles: map[string]bool{"bold": true, "italic": true}},
+				{Type: "text", Text: " red", Styles: map[string]bool{}, StyleValues: map[string]string{"textColor": "red"}},
+				{Type: "text", Text: " off", Styles: map[string]bool{}},
+				{Type: "link", Text: "link", Href: "https://example.com", Styles: map[string]bool{}},
+				{Type: "mention", Text: "@ann", Styles: map[string]bool{}, Props: map[string]any{"user": "u1"}},
+			},
+			Children: []dto.Block{{
+				ID:       "child",
+				Type:     "bulletListItem",
+				Props:    map[string]any{},
+				Content:  []dto.InlineContent{},
+				Children: []dto.Block{},
+			}},
+		},
+	}
+
+	if got := blockNoteBlocks(fragment); !reflect.DeepEqual(got, want) {
+		t.Errorf("blockNoteBlocks =\n%+v\nwant\n%+v", got, want)
+	}
+}
+
+func TestBlockNoteBlocksOfAnEmptyFragment(t *testing.T) {
+	if got := blockNoteBlocks(nil); got == nil || len(got) != 0 {
+		t.Errorf("blockNoteBlocks(nil) = %#v, want an empty list", got)
+	}
+}
+
+func TestBlockNoteTable(t *testing.T) {
+	cell := func(name, content string) yjs.XmlNode {
+		return yjs.XmlNode{Name: name, Attributes: map[string]any{"colspan": float64(1)}, Children: []yjs.XmlNode{
+			{Name: "tableParagraph", Children: []yjs.XmlNode{text(yjs.TextRun{Text: content})}},
+		}}
+	}
+	fragment := []yjs.XmlNode{{Name: "blockGroup", Children: []yjs.XmlNode{
+		container("table", yjs.XmlNode{Name: "table", Children: []yjs.XmlNode{
+			{Name: "tableRow", Children: []yjs.XmlNode{cell("tableHeader", "Name"), cell("tableHeader", "Age")}},
+			{Name: "tableRow", Children: []yjs.XmlNode{cell("tableCell", "Ann"), cell("tableCell", "42")}},
+		}}),
+	}}}
+
+	blocks := blockNoteBlocks(fragment)
+	if len(blocks) != 1 || blocks[0].Type != dto.BlockTypeTable {
+		t.Fatalf("blockNoteBlocks = %+v, want a table block", blocks)
+	}
+	if len(blocks[0].Content) != 0 {
+		t.Errorf("Content = %+v, want the rows in TableContent", blocks[0].Content)
+	}
+
+	tableCell := func(content string) dto.TableCell {
+		return dto.TableCell{
+			Type:    "tableCell",
+			Props:   map[string]any{"colspan": float64(1)},
+			Content: []dto.InlineContent{{Type: "text", Text: content, Styles: map[string]bool{}}},
+		}
+	}
+	want := &dto.TableContent{
+		Type:       "tableContent",
+		HeaderRows: 1,
+		Rows: []dto.TableRow{
+			{Cells: []dto.TableCell{tableCell("Name"), tableCell("Age")}},
+			{Cells: []dto.TableCell{tableCell("Ann"), tableCell("42")}},
+		},
+	}
+	if got := blocks[0].TableContent; !reflect.DeepEqual(got, want) {
+		t.Errorf("TableContent =\n%+v\nwant\n%+v", got, want)
+	}
+}
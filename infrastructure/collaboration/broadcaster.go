@@ -0,0 +1,75 @@
+package collaboration
+
+import (
+	"sync"
+)
+
+// BroadcastKind is the kind of a message published to the other nodes.
+type BroadcastKind string
+
+const (
+	// BroadcastRoomMessage is a y-protocols message of a room
+	BroadcastRoomMessage BroadcastKind = "message"
+	// BroadcastPresence is the presence of a room on the publishing node
+	BroadcastPresence BroadcastKind = "presence"
+	// BroadcastReset drops a room whose content was replaced
+	BroadcastReset BroadcastKind = "reset"
+	// BroadcastAccess checks again the access to the rooms of a resource,
+	// whose room id is "{permission type}:{resource id}"
+	BroadcastAccess BroadcastKind = "access"
+	// BroadcastResync is delivered by a broadcaster to its own subscribers
+	// when it may have missed messages, e.g. while it reconnected: the rooms
+	// reload their state from the update log
+	BroadcastResync BroadcastKind = "resync"
+)
+
+// BroadcastMessage is a message of a room published to the other nodes.
+type BroadcastMessage struct {
+	// NodeID is the hub that published the message
+	NodeID string
+	RoomID string
+	Kind   BroadcastKind
+	Data   []byte
+}
+
+// Broadcaster shares the messages of rooms between the hubs of several
+// nodes. Every subscriber receives every message, its own included: hubs
+// skip the messages they published.
+type Broadcaster interface {
+	Publish(message BroadcastMessage) error
+	Subscribe(receive func(message BroadcastMessage))
+	Close() error
+}
+
+// MemoryBroadcaster shares messages between the hubs of a single process,
+// the default for single node deployments.
+type MemoryBroadcaster struct {
+	mu          sync.RWMutex
+	subscribers []func(message BroadcastMessage)
+}
+
+// NewMemoryBroadcaster creates an in-memory broadcaster.
+func NewMemoryBroadcaster() *MemoryBroadcaster {
+	return &MemoryBroadcaster{}
+}
+
+func (b *MemoryBroadcaster) Publish(message BroadcastMessage) error {
+	b.mu.RLock()
+	subscribers := b.subscribers
+	b.mu.RUnlock()
+
+	for _, receive := range subscribers {
+		receive(message)
+	}
+	return nil
+}
+
+func (b *MemoryBroadcaster) Subscribe(receive func(message BroadcastMessage)) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.subscribers = append(b.subscribers, receive)
+}
+
+func (b *MemoryBroadcaster) Close() error {
+	return nil
+}
@@ -0,0 +1,164 @@
+package collaboration
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/rs/zerolog"
+	"gorm.io/gorm"
+)
+
+const (
+	postgresChannel = "nexo_collaboration"
+
+	// NOTIFY payloads are limited to 8000 bytes: larger messages are stored
+	// in collaboration_broadcast and the notification refers to them
+	maxNotifyPayload = 7900
+
+	// How long stored messages are kept for the other nodes to read them
+	broadcastRetention = time.Minute
+)
+
+// postgresNotification is the payload of a notification.
+type postgresNotification struct {
+	NodeID string        `json:"node_id"`
+	RoomID string        `json:"room_id"`
+	Kind   BroadcastKind `json:"kind"`
+	Data   []byte        `json:"data,omitempty"`
+	Ref    int64         `json:"ref,omitempty"`
+}
+
+// PostgresBroadcaster shares messages between the nodes using the same
+// PostgreSQL database, with LISTEN/NOTIFY.
+type PostgresBroadcaster struct {
+	db     *gorm.DB
+	dsn    string
+	logger zerolog.Logger
+
+	mu          sync.RWMutex
+	subscribers []func(message BroadcastMessage)
+
+	cancel context.CancelFunc
+}
+
+// NewPostgresBroadcaster creates a broadcaster listening on its own
+// connection to the database, reconnecting when it is lost.
+func NewPostgresBroadcaster(dsn string, db *gorm.DB, logger zerolog.Logger) *PostgresBroadcaster {
+	ctx, cancel := context.WithCancel(context.Background())
+	b := &PostgresBroadcaster{
+		db:     db,
+		dsn:    dsn,
+		logger: logger.With().Str("component", "collaboration.broadcaster.postgres").Logger(),
+		cancel: cancel,
+	}
+	go b.listen(ctx)
+	return b
+}
+
+func (b *PostgresBroadcaster) Publish(message BroadcastMessage) error {
+	notification := postgresNotification{NodeID: message.NodeID, RoomID: message.RoomID, Kind: message.Kind, Data: message.Data}
+	payload, err := json.Marshal(notification)
+	if err != nil {
+		return err
+	}
+
+	if len(payload) > maxNotifyPayload {
+		var ref int64
+		err := b.db.Raw("INSERT INTO collaboration_broadcast (data) VALUES (?) RETURNING id", message.Data).Row().Scan(&ref)
+		if err != nil {
+			return fmt.Errorf("failed to store broadcast message: %w", err)
+		}
+		b.db.Exec("DELETE FROM collaboration_broadcast WHERE created_at < ?", time.Now().Add(-broadcastRetention))
+
+		notification.Data, notification.Ref = nil, ref
+		if payload, err = json.Marshal(notification); err != nil {
+			return err
+		}
+	}
+
+	return b.db.Exec("SELECT pg_notify(?, ?)", postgresChannel, string(payload)).Error
+}
+
+func (b *PostgresBroadcaster) Subscribe(receive func(message BroadcastMessage)) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.subscribers = append(b.subscribers, receive)
+}
+
+func (b *PostgresBroadcaster) Close() error {
+	b.cancel()
+	return nil
+}
+
+func (b *PostgresBroadcaster) listen(ctx context.Context) {
+	for reconnect := false; ctx.Err() == nil; reconnect = true {
+		err := b.listenConnection(ctx, reconnect)
+		if ctx.Err() != nil {
+			return
+		}
+		b.logger.Error().Err(err).Msg("lost the notification connection, reconnecting")
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(2 * time.Second):
+		}
+	}
+}
+
+// listenConnection listens for notifications until the connection is lost.
+// The notifications sent while it was lost are gone: once listening again,
+// the subscribers are asked to resync.
+func (b *PostgresBroadcaster) listenConnection(ctx context.Context, reconnect bool) error {
+	conn, err := pgx.Connect(ctx, b.dsn)
+	if err != nil {
+		return err
+	}
+	defer conn.Close(context.Background())
+
+	if _, err := conn.Exec(ctx, "LISTEN "+postgresChannel); err != nil {
+		return err
+	}
+	b.logger.Info().Msg("listening for collaboration messages")
+	if reconnect {
+		b.deliver(BroadcastMessage{Kind: BroadcastResync})
+	}
+
+	for {
+		n, err := conn.WaitForNotification(ctx)
+		if err != nil {
+			return err
+		}
+		b.receive(n.Payload)
+	}
+}
+
+func (b *PostgresBroadcaster) receive(payload string) {
+	var notification postgresNotification
+	if err := json.Unmarshal([]byte(payload), &notification); err != nil {
+		b.logger.Warn().Err(err).Msg("invalid collaboration notification")
+		return
+	}
+
+	if notification.Ref != 0 {
+		err := b.db.Raw("SELECT data FROM collaboration_broadcast WHERE id = ?", notification.Ref).Row().Scan(&notification.Data)
+		if err != nil {
+			b.logger.Warn().Err(err).Int64("ref", notification.Ref).Msg("failed to read broadcast message")
+			return
+		}
+	}
+
+	b.deliver(BroadcastMessage{NodeID: notification.NodeID, RoomID: notification.RoomID, Kind: notification.Kind, Data: notification.Data})
+}
+
+func (b *PostgresBroadcaster) deliver(message BroadcastMessage) {
+	b.mu.RLock()
+	subscribers := b.subscribers
+	b.mu.RUnlock()
+	for _, receive := range subscribers {
+		receive(message)
+	}
+}
@@ -0,0 +1,96 @@
+package collaboration
+
+import (
+	"strings"
+
+	"github.com/labbs/nexo/application/document/dto"
+	"github.com/labbs/nexo/domain"
+	"github.com/labbs/nexo/infrastructure/collaboration/yjs"
+)
+
+// CompactRooms compacts the update log of the rooms that received updates
+// since their last compaction.
+func (h *Hub) CompactRooms() error {
+	roomIDs, err := h.updates.GetRoomsToCompact()
+	if err != nil {
+		h.logger.Error().Err(err).Msg("failed to get collaboration rooms to compact")
+		return err
+	}
+
+	for _, roomID := range roomIDs {
+		h.compactRoom(roomID)
+	}
+	return nil
+}
+
+// compactRoom merges the update log of a room into a single update, and
+// saves the content of document rooms with a version snapshot.
+func (h *Hub) compactRoom(roomID string) {
+	h.compactMu.Lock()
+	defer h.compactMu.Unlock()
+
+	logger := h.logger.With().Str("room_id", roomID).Logger()
+
+	updates, err := h.updates.GetByRoomId(roomID)
+	if err != nil {
+		logger.Error().Err(err).Msg("failed to get room updates")
+		return
+	}
+	if len(updates) == 0 || (len(updates) == 1 && updates[0].Compacted) {
+		return
+	}
+
+	doc := yjs.NewDoc()
+	decoded := true
+	userID := ""
+	for _, update := range updates {
+		if _, err := doc.ApplyUpdate(update.Data); err != nil {
+			logger.Warn().Err(err).Int64("update_id", update.Id).Msg("failed to apply update, keeping the update log")
+			decoded = false
+		}
+		if update.UserId != "" {
+			userID = update.UserId
+		}
+	}
+
+	if !decoded {
+		// The content would miss the updates that failed: neither save it nor
+		// compact the log, so that no edit is lost
+		return
+	}
+
+	// With structs waiting for missing updates (e.g. sent by a client after
+	// the room was reset), the content is incomplete: it is not saved
+	pending := doc.PendingUpdate()
+
+	if documentID, ok := strings.CutPrefix(roomID, "document:"); ok && userID != "" && pending == nil {
+		nodes := doc.XmlFragment(BlockNoteFragment)
+		if len(nodes) > 0 {
+			_, err := h.documentApplication.SaveCollaborationSnapshot(dto.SaveCollaborationSnapshotInput{
+				UserId:     userID,
+				DocumentId: documentID,
+				Content:    blockNoteBlocks(nodes),
+			})
+			if err != nil {
+				logger.Warn().Err(err).Msg("failed to save collaboration snapshot")
+			}
+		}
+	}
+
+	state, err := doc.EncodeStateAsUpdate(nil)
+	if err != nil {
+		logger.Error().Err(err).Msg("failed to encode room state")
+		return
+	}
+	compacted := []domain.CollaborationUpdate{{Data: state, UserId: userID, Compacted: true}}
+	if pending != nil {
+		// Structs waiting for updates not received yet
+		compacted = append(compacted, domain.CollaborationUpdate{Data: pending, UserId: userID, Compacted: true})
+	}
+
+	if err := h.updates.Compact(roomID, updates[len(updates)-1].Id, compacted); err != nil {
+		logger.Error().Err(err).Msg("failed to compact room updates")
+		return
+	}
+	logger.Debug().Int("updates", len(updates)).Msg("room updates compacted")
+}
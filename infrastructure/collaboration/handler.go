@@ -2,12 +2,15 @@ package collaboration
 
 import (
 	"strings"
+	"time"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/gofiber/websocket/v2"
 	fiberoapi "github.com/labbs/fiber-oapi"
+	"github.com/labbs/nexo/application/ports"
 	"github.com/labbs/nexo/application/session"
 	sessionDto "github.com/labbs/nexo/application/session/dto"
+	userDto "github.com/labbs/nexo/application/user/dto"
 	"github.com/labbs/nexo/domain"
 	"github.com/rs/zerolog"
 )
@@ -18,15 +21,17 @@ const wsCollabPrefix = "/ws/collab/"
 type Handler struct {
 	hub          *Hub
 	sessionApp   *session.SessionApplication
+	userApp      ports.UserPort
 	documentPers domain.DocumentPers
 	logger       zerolog.Logger
 }
 
 // NewHandler creates a new collaboration WebSocket handler.
-func NewHandler(hub *Hub, sessionApp *session.SessionApplication, documentPers domain.DocumentPers, logger zerolog.Logger) *Handler {
+func NewHandler(hub *Hub, sessionApp *session.SessionApplication, userApp ports.UserPort, documentPers domain.DocumentPers, logger zerolog.Logger) *Handler {
 	return &Handler{
 		hub:          hub,
 		sessionApp:   sessionApp,
+		userApp:      userApp,
 		documentPers: documentPers,
 		logger:       logger.With().Str("component", "collaboration.handler").Logger(),
 	}
@@ -52,27 +57,48 @@ func (h *Handler) UpgradeMiddleware() fiber.Handler {
 			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "invalid token"})
 		}
 
+		// API keys are for the REST API: collaboration requires a session
+		if _, ok := result.AuthContext.Claims["api_key_id"]; ok {
+			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "api keys cannot be used for collaboration"})
+		}
+
 		c.Locals("auth_context", result.AuthContext)
 		c.Locals("user_id", result.AuthContext.UserID)
-		c.Locals("path", c.Path())
+		// The connection outlives the request, whose buffers are reused
+		c.Locals("path", strings.Clone(c.Path()))
 
 		return c.Next()
 	}
 }
 
-// canAccessRoom checks if the user has at least viewer access to the room's resource.
+// roomAccess is the access of a user to the resource of a room.
+type roomAccess int
+
+const (
+	accessNone roomAccess = iota
+	accessRead
+	accessWrite
+)
+
+// accessRecheckInterval is how often the access of a connected client is
+// checked again besides the checks requested when permissions or document
+// locks change (see Hub.RecheckAccess), in case a change is not notified.
+const accessRecheckInterval = time.Minute
+
+// roomAccess checks the access of the user to the room's resource: viewers
+// can read, editors can write unless the document is locked.
 // roomID format: "document:{id}", "drawing:{id}", "row:{databaseId}:{rowId}"
-func (h *Handler) canAccessRoom(userID string, authCtx *fiberoapi.AuthContext, roomID string) bool {
-	// Admin bypasses all checks
+func (h *Handler) roomAccess(userID string, authCtx *fiberoapi.AuthContext, roomID string) roomAccess {
+	isAdmin := false
 	for _, role := range authCtx.Roles {
 		if role == string(domain.RoleAdmin) {
-			return true
+			isAdmin = true
 		}
 	}
 
 	parts := strings.SplitN(roomID, ":", 3)
 	if len(parts) < 2 {
-		return false
+		return accessNone
 	}
 
 	resourceType := parts[0]
@@ -83,39 +109,53 @@ func (h *Handler) canAccessRoom(userID string, authCtx *fiberoapi.AuthContext, r
 		doc, err := h.documentPers.GetDocumentWithPermissions(resourceID, userID)
 		if err != nil {
 			h.logger.Warn().Err(err).Str("room_id", roomID).Msg("failed to load document for access check")
-			return false
+			return accessNone
+		}
+		switch {
+		case !isAdmin && !doc.HasPermission(userID, domain.PermissionRoleViewer):
+			return accessNone
+		case doc.Config.Lock:
+			// Locked documents are read-only for everyone
+			return accessRead
+		case isAdmin || doc.HasPermission(userID, domain.PermissionRoleEditor):
+			return accessWrite
+		default:
+			return accessRead
 		}
-		return doc.HasPermission(userID, domain.PermissionRoleViewer)
 
 	case "drawing":
-		ok, err := h.sessionApp.CanAccessResource(sessionDto.CanAccessResourceInput{
-			Context:      authCtx,
-			ResourceType: "drawing",
-			ResourceID:   resourceID,
-			Action:       "read",
-		})
-		if err != nil {
-			h.logger.Warn().Err(err).Str("room_id", roomID).Msg("failed to check drawing access")
-			return false
-		}
-		return ok
+		return h.resourceAccess(authCtx, "drawing", resourceID, roomID)
 
 	case "row", "database":
+		return h.resourceAccess(authCtx, "database", resourceID, roomID)
+
+	default:
+		return accessNone
+	}
+}
+
+// resourceAccess checks the access to a drawing or a database through the
+// permissions of its space.
+func (h *Handler) resourceAccess(authCtx *fiberoapi.AuthContext, resourceType, resourceID, roomID string) roomAccess {
+	for _, action := range []string{"write", "read"} {
 		ok, err := h.sessionApp.CanAccessResource(sessionDto.CanAccessResourceInput{
 			Context:      authCtx,
-			ResourceType: "database",
+			ResourceType: resourceType,
 			ResourceID:   resourceID,
-			Action:       "read",
+			Action:       action,
 		})
 		if err != nil {
-			h.logger.Warn().Err(err).Str("room_id", roomID).Msg("failed to check database access")
-			return false
+			h.logger.Warn().Err(err).Str("room_id", roomID).Msgf("failed to check %s access", resourceType)
+			return accessNone
+		}
+		if ok && action == "write" {
+			return accessWrite
+		}
+		if ok {
+			return accessRead
 		}
-		return ok
-
-	default:
-		return false
 	}
+	return accessNone
 }
 
 // WebSocketHandler returns the Fiber WebSocket handler for collaboration.
@@ -132,7 +172,11 @@ func (h *Handler) WebSocketHandler() fiber.Handler {
 			return
 		}
 
-		if authCtx == nil || !h.canAccessRoom(userID, authCtx, roomID) {
+		access := accessNone
+		if authCtx != nil {
+			access = h.roomAccess(userID, authCtx, roomID)
+		}
+		if access == accessNone {
 			h.logger.Warn().Str("room_id", roomID).Str("user_id", userID).Msg("unauthorized WebSocket room access")
 			c.WriteMessage(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.ClosePolicyViolation, "forbidden"))
 			return
@@ -140,14 +184,31 @@ func (h *Handler) WebSocketHandler() fiber.Handler {
 
 		room := h.hub.GetOrCreateRoom(roomID)
 		client := &Client{
-			UserID: userID,
+			UserID:      userID,
+			ConnectedAt: time.Now(),
+			recheck:     make(chan struct{}, 1),
+		}
+		client.SetReadOnly(access != accessWrite)
+		// Username and avatar are shown in the presence of the room
+		if user, err := h.userApp.GetByUserId(userDto.GetByUserIdInput{UserId: userID}); err != nil {
+			h.logger.Warn().Err(err).Str("user_id", userID).Msg("failed to load user for presence")
+		} else {
+			client.Username = user.User.Username
+			client.AvatarURL = user.User.AvatarUrl
 		}
 
-		room.AddClient(c, client)
+		if !room.AddClient(c, client) {
+			c.WriteMessage(websocket.CloseMessage, websocket.FormatCloseMessage(closeContentReplaced, "content replaced"))
+			return
+		}
+		room.Join(c, client)
+		done := make(chan struct{})
 		defer func() {
+			close(done)
 			room.RemoveClient(c)
-			h.hub.RemoveRoomIfEmpty(roomID)
+			h.hub.RemoveRoomIfEmpty(room)
 		}()
+		go h.watchAccess(c, room, client, authCtx, roomID, done)
 
 		for {
 			messageType, msg, err := c.ReadMessage()
@@ -158,9 +219,45 @@ func (h *Handler) WebSocketHandler() fiber.Handler {
 				break
 			}
 
-			if messageType == websocket.BinaryMessage {
-				room.Broadcast(c, msg)
+			if messageType != websocket.BinaryMessage {
+				continue
 			}
+
+			room.HandleMessage(c, client, msg)
 		}
 	})
 }
+
+// watchAccess checks the access of a connected client when requested and
+// every accessRecheckInterval until done is closed: a client that lost its
+// access is disconnected, whose read loop then ends, and a client whose role
+// changed becomes read-only or writable.
+func (h *Handler) watchAccess(conn *websocket.Conn, room *Room, client *Client, authCtx *fiberoapi.AuthContext, roomID string, done <-chan struct{}) {
+	ticker := time.NewTicker(accessRecheckInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-done:
+			return
+		case <-client.recheck:
+		case <-ticker.C:
+		}
+
+		access := h.roomAccess(client.UserID, authCtx, roomID)
+		if access == accessNone {
+			h.logger.Info().Str("room_id", roomID).Str("user_id", client.UserID).Msg("room access revoked")
+			client.writeMu.Lock()
+			conn.WriteMessage(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.ClosePolicyViolation, "forbidden"))
+			client.writeMu.Unlock()
+			conn.Close()
+			return
+		}
+
+		if readOnly := access != accessWrite; readOnly != client.ReadOnly() {
+			h.logger.Info().Str("room_id", roomID).Str("user_id", client.UserID).Bool("read_only", readOnly).Msg("room access changed")
+			client.SetReadOnly(readOnly)
+			room.PublishPresence()
+		}
+	}
+}
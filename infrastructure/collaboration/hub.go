@@ -1,24 +1,48 @@
 package collaboration
 
 import (
+	"strings"
 	"sync"
 
+	"github.com/google/uuid"
+	"github.com/labbs/nexo/application/ports"
+	"github.com/labbs/nexo/domain"
 	"github.com/rs/zerolog"
 )
 
 // Hub manages all collaboration rooms.
+// The messages of the rooms are shared with the hubs of the other nodes
+// through the broadcaster.
 type Hub struct {
 	mu     sync.RWMutex
 	rooms  map[string]*Room
 	logger zerolog.Logger
+
+	nodeID      string
+	broadcaster Broadcaster
+
+	updates             domain.CollaborationUpdatePers
+	documentApplication ports.DocumentPort
+	compactMu           sync.Mutex
+
+	// presence of the rooms on the other nodes
+	remoteMu       sync.RWMutex
+	remotePresence map[string]map[string]remotePresence
 }
 
 // NewHub creates a new collaboration hub.
-func NewHub(logger zerolog.Logger) *Hub {
-	return &Hub{
-		rooms:  make(map[string]*Room),
-		logger: logger.With().Str("component", "collaboration.hub").Logger(),
+func NewHub(logger zerolog.Logger, broadcaster Broadcaster, updates domain.CollaborationUpdatePers, documentApplication ports.DocumentPort) *Hub {
+	h := &Hub{
+		rooms:               make(map[string]*Room),
+		logger:              logger.With().Str("component", "collaboration.hub").Logger(),
+		nodeID:              uuid.New().String(),
+		broadcaster:         broadcaster,
+		updates:             updates,
+		documentApplication: documentApplication,
+		remotePresence:      make(map[string]map[string]remotePresence),
 	}
+	broadcaster.Subscribe(h.receive)
+	return h
 }
 
 // GetOrCreateRoom returns an existing room or creates a new one.
@@ -38,25 +62,98 @@ func (h *Hub) GetOrCreateRoom(roomID string) *Room {
 		return room
 	}
 
-	room = newRoom(roomID, h.logger)
+	room = newRoom(roomID, h.logger, h.updates, h.publish)
 	h.rooms[roomID] = room
 	h.logger.Info().Str("room_id", roomID).Msg("room created")
 	return room
 }
 
 // RemoveRoomIfEmpty removes a room if it has no more clients.
-func (h *Hub) RemoveRoomIfEmpty(roomID string) {
+func (h *Hub) RemoveRoomIfEmpty(room *Room) {
 	h.mu.Lock()
 	defer h.mu.Unlock()
 
-	room, ok := h.rooms[roomID]
-	if !ok {
+	roomID := room.id
+	if h.rooms[roomID] != room {
+		// Evicted, possibly replaced by a new room
 		return
 	}
 
 	if room.ClientCount() == 0 {
 		delete(h.rooms, roomID)
 		h.logger.Info().Str("room_id", roomID).Msg("room removed (empty)")
+
+		// Snapshot the document the last client was editing
+		go h.compactRoom(roomID)
+	}
+}
+
+// ResetRoom drops the state of a room whose content was replaced outside of
+// it: its clients are disconnected on every node and its update log is
+// deleted, so that the room starts again from the new content.
+func (h *Hub) ResetRoom(roomID string) {
+	h.evictRoom(roomID)
+	h.publish(roomID, BroadcastReset, nil)
+
+	// Not while the room is compacted: its snapshot would replace the content
+	h.compactMu.Lock()
+	defer h.compactMu.Unlock()
+	if err := h.updates.DeleteByRoomId(roomID); err != nil {
+		h.logger.Error().Err(err).Str("room_id", roomID).Msg("failed to delete room updates")
+	}
+}
+
+// evictRoom disconnects the clients of a room on this node and forgets it.
+func (h *Hub) evictRoom(roomID string) {
+	h.mu.Lock()
+	room, ok := h.rooms[roomID]
+	delete(h.rooms, roomID)
+	h.mu.Unlock()
+
+	if ok {
+		h.logger.Info().Str("room_id", roomID).Msg("room evicted (content replaced)")
+		room.evict()
+	}
+}
+
+// RecheckAccess asks the clients of the rooms depending on the permissions
+// of a resource to check their access again, on every node.
+func (h *Hub) RecheckAccess(resourceType domain.PermissionType, resourceID string) {
+	h.recheckAccess(resourceType, resourceID)
+	h.publish(string(resourceType)+":"+resourceID, BroadcastAccess, nil)
+}
+
+// recheckAccess asks the clients of the matching rooms on this node to check
+// their access again.
+func (h *Hub) recheckAccess(resourceType domain.PermissionType, resourceID string) {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	for roomID, room := range h.rooms {
+		if roomDependsOn(roomID, resourceType, resourceID) {
+			room.requestAccessCheck()
+		}
+	}
+}
+
+// roomDependsOn reports whether the access to a room depends on the
+// permissions of a resource.
+// roomID format: "document:{id}", "drawing:{id}", "row:{databaseId}:{rowId}"
+func roomDependsOn(roomID string, resourceType domain.PermissionType, resourceID string) bool {
+	parts := strings.SplitN(roomID, ":", 3)
+	if len(parts) < 2 {
+		return false
+	}
+	switch resourceType {
+	case domain.PermissionTypeDocument:
+		// Documents inherit the permissions of their parents
+		return parts[0] == "document"
+	case domain.PermissionTypeDatabase:
+		return (parts[0] == "database" || parts[0] == "row") && parts[1] == resourceID
+	case domain.PermissionTypeDrawing:
+		return parts[0] == "drawing" && parts[1] == resourceID
+	default:
+		// The rooms do not know the space of their resource
+		return true
 	}
 }
 
@@ -71,3 +168,60 @@ func (h *Hub) Stats() (rooms int, clients int) {
 	}
 	return
 }
+
+// publish shares a message or the presence of a room with the other nodes.
+func (h *Hub) publish(roomID string, kind BroadcastKind, data []byte) {
+	err := h.broadcaster.Publish(BroadcastMessage{NodeID: h.nodeID, RoomID: roomID, Kind: kind, Data: data})
+	if err != nil {
+		h.logger.Warn().Err(err).Str("room_id", roomID).Msg("failed to publish message")
+	}
+}
+
+// receive delivers a message published by another node to the clients of
+// the room on this node, or records the presence of the room on that node.
+func (h *Hub) receive(message BroadcastMessage) {
+	if message.NodeID == h.nodeID {
+		return
+	}
+	switch message.Kind {
+	case BroadcastPresence:
+		h.receivePresence(message)
+		return
+	case BroadcastReset:
+		h.evictRoom(message.RoomID)
+		return
+	case BroadcastAccess:
+		resourceType, resourceID, _ := strings.Cut(message.RoomID, ":")
+		h.recheckAccess(domain.PermissionType(resourceType), resourceID)
+		return
+	case BroadcastResync:
+		h.resync()
+		return
+	}
+
+	h.mu.RLock()
+	room, ok := h.rooms[message.RoomID]
+	h.mu.RUnlock()
+	if ok {
+		room.receive(message.Data)
+	}
+}
+
+// resync catches up with the messages the broadcaster missed: the open rooms
+// reload the updates of the other nodes from their log, the connections
+// check their access again and the presence is shared again.
+func (h *Hub) resync() {
+	h.mu.RLock()
+	rooms := make([]*Room, 0, len(h.rooms))
+	for _, room := range h.rooms {
+		rooms = append(rooms, room)
+	}
+	h.mu.RUnlock()
+
+	h.logger.Info().Int("rooms", len(rooms)).Msg("reloading the rooms after missed messages")
+	for _, room := range rooms {
+		room.reload()
+		room.requestAccessCheck()
+		room.PublishPresence()
+	}
+}
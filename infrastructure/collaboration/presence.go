@@ -0,0 +1,163 @@
+package collaboration
+
+import (
+	"encoding/json"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/labbs/nexo/domain"
+)
+
+// remotePresenceTTL is how long the presence published by another node is
+// kept without a heartbeat, in case the node stopped without leaving.
+const remotePresenceTTL = 90 * time.Second
+
+type remotePresence struct {
+	users      []domain.Presence
+	receivedAt time.Time
+}
+
+// receivePresence records the presence of a room on another node. An empty
+// presence means the room has no more clients on that node.
+func (h *Hub) receivePresence(message BroadcastMessage) {
+	var users []domain.Presence
+	if err := json.Unmarshal(message.Data, &users); err != nil {
+		h.logger.Warn().Err(err).Str("room_id", message.RoomID).Msg("failed to decode presence")
+		return
+	}
+
+	h.remoteMu.Lock()
+	defer h.remoteMu.Unlock()
+
+	nodes := h.remotePresence[message.RoomID]
+	if len(users) == 0 {
+		delete(nodes, message.NodeID)
+		if len(nodes) == 0 {
+			delete(h.remotePresence, message.RoomID)
+		}
+		return
+	}
+	if nodes == nil {
+		nodes = make(map[string]remotePresence)
+		h.remotePresence[message.RoomID] = nodes
+	}
+	nodes[message.NodeID] = remotePresence{users: users, receivedAt: time.Now()}
+}
+
+// PublishPresence shares the presence of the rooms of this node with the
+// other nodes, and forgets the presence of the nodes that stopped publishing.
+func (h *Hub) PublishPresence() {
+	h.mu.RLock()
+	rooms := make([]*Room, 0, len(h.rooms))
+	for _, room := range h.rooms {
+		rooms = append(rooms, room)
+	}
+	h.mu.RUnlock()
+
+	for _, room := range rooms {
+		room.PublishPresence()
+	}
+
+	h.remoteMu.Lock()
+	defer h.remoteMu.Unlock()
+	for roomID, nodes := range h.remotePresence {
+		for nodeID, presence := range nodes {
+			if time.Since(presence.receivedAt) > remotePresenceTTL {
+				delete(nodes, nodeID)
+			}
+		}
+		if len(nodes) == 0 {
+			delete(h.remotePresence, roomID)
+		}
+	}
+}
+
+// RoomPresence returns the users connected to a room on every node.
+func (h *Hub) RoomPresence(roomID string) []domain.Presence {
+	h.mu.RLock()
+	room, ok := h.rooms[roomID]
+	h.mu.RUnlock()
+
+	var entries []domain.Presence
+	if ok {
+		entries = room.Presence()
+	}
+	entries = append(entries, h.remoteRoomPresence(roomID)...)
+	return mergePresence(entries)
+}
+
+// ActiveRooms returns the rooms with connected users on every node.
+func (h *Hub) ActiveRooms() []domain.RoomPresence {
+	roomIDs := make(map[string]struct{})
+	h.mu.RLock()
+	for roomID := range h.rooms {
+		roomIDs[roomID] = struct{}{}
+	}
+	h.mu.RUnlock()
+	h.remoteMu.RLock()
+	for roomID := range h.remotePresence {
+		roomIDs[roomID] = struct{}{}
+	}
+	h.remoteMu.RUnlock()
+
+	rooms := make([]domain.RoomPresence, 0, len(roomIDs))
+	for roomID := range roomIDs {
+		users := h.RoomPresence(roomID)
+		if len(users) == 0 {
+			continue
+		}
+		resourceType, resourceID, _ := strings.Cut(roomID, ":")
+		rooms = append(rooms, domain.RoomPresence{
+			RoomId:       roomID,
+			ResourceType: resourceType,
+			ResourceId:   resourceID,
+			Users:        users,
+		})
+	}
+	sort.Slice(rooms, func(i, j int) bool { return rooms[i].RoomId < rooms[j].RoomId })
+	return rooms
+}
+
+func (h *Hub) remoteRoomPresence(roomID string) []domain.Presence {
+	h.remoteMu.RLock()
+	defer h.remoteMu.RUnlock()
+
+	var entries []domain.Presence
+	for _, presence := range h.remotePresence[roomID] {
+		if time.Since(presence.receivedAt) <= remotePresenceTTL {
+			entries = append(entries, presence.users...)
+		}
+	}
+	return entries
+}
+
+// mergePresence merges the connections of each user: the user is read-only
+// when none of its connections can edit, connected since its first
+// connection and active at its last change.
+func mergePresence(entries []domain.Presence) []domain.Presence {
+	byUser := make(map[string]int)
+	var users []domain.Presence
+	for _, entry := range entries {
+		i, ok := byUser[entry.UserId]
+		if !ok {
+			byUser[entry.UserId] = len(users)
+			users = append(users, entry)
+			continue
+		}
+		user := &users[i]
+		user.Connections += entry.Connections
+		user.ReadOnly = user.ReadOnly && entry.ReadOnly
+		if entry.ConnectedAt.Before(user.ConnectedAt) {
+			user.ConnectedAt = entry.ConnectedAt
+		}
+		if entry.LastActivityAt.After(user.LastActivityAt) {
+			user.LastActivityAt = entry.LastActivityAt
+		}
+		if user.Username == "" {
+			user.Username, user.AvatarUrl = entry.Username, entry.AvatarUrl
+		}
+	}
+	sort.Slice(users, func(i, j int) bool { return users[i].ConnectedAt.Before(users[j].ConnectedAt) })
+	return users
+}
@@ -0,0 +1,75 @@
+package collaboration
+
+import (
+	"github.com/labbs/nexo/infrastructure/collaboration/yjs"
+)
+
+// Message types of the y-protocols (as used by y-websocket)
+const (
+	messageSync           = 0
+	messageAwareness      = 1
+	messageQueryAwareness = 3
+)
+
+// Sync message types: a peer sends its state vector (step 1), the other
+// answers with the update it is missing (step 2), then updates follow.
+const (
+	syncStep1  = 0
+	syncStep2  = 1
+	syncUpdate = 2
+)
+
+// syncMessage is a decoded sync message.
+type syncMessage struct {
+	syncType uint64
+	// State vector of step 1, update of step 2 and updates
+	payload []byte
+}
+
+// readSyncMessage decodes a sync message, ok is false for other messages.
+func readSyncMessage(msg []byte) (message syncMessage, ok bool, err error) {
+	d := yjs.NewDecoder(msg)
+	messageType, err := d.ReadVarUint()
+	if err != nil || messageType != messageSync {
+		return message, false, err
+	}
+	if message.syncType, err = d.ReadVarUint(); err != nil {
+		return message, false, err
+	}
+	if message.payload, err = d.ReadVarUint8Array(); err != nil {
+		return message, false, err
+	}
+	// Other protocols may start with the same bytes
+	if d.HasContent() {
+		return message, false, nil
+	}
+	return message, true, nil
+}
+
+func encodeSyncMessage(syncType uint64, payload []byte) []byte {
+	e := &yjs.Encoder{}
+	e.WriteVarUint(messageSync)
+	e.WriteVarUint(syncType)
+	e.WriteVarUint8Array(payload)
+	return e.Bytes()
+}
+
+// readOnlyMessage reports whether a message can be sent by a read-only
+// client: awareness (cursors, presence) and sync step 1, which only asks for
+// the state of the room.
+func readOnlyMessage(msg []byte) bool {
+	d := yjs.NewDecoder(msg)
+	messageType, err := d.ReadVarUint()
+	if err != nil {
+		return false
+	}
+	switch messageType {
+	case messageAwareness, messageQueryAwareness:
+		return true
+	case messageSync:
+		syncType, err := d.ReadVarUint()
+		return err == nil && syncType == syncStep1
+	default:
+		return false
+	}
+}
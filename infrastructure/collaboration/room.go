@@ -1,42 +1,284 @@
 package collaboration
 
 import (
+	"encoding/json"
+	"maps"
 	"sync"
+	"sync/atomic"
+	"time"
 
 	"github.com/gofiber/websocket/v2"
+	"github.com/labbs/nexo/domain"
+	"github.com/labbs/nexo/infrastructure/collaboration/yjs"
 	"github.com/rs/zerolog"
 )
 
 // Room represents a Y.js collaboration room.
-// It acts as a pure relay: binary messages from one client are broadcast to all others.
+// Binary messages from one client are broadcast to all others. The room also
+// merges the sync updates into its own Y.js document, persisted as an update
+// log, so that clients joining later get the state without a peer.
 type Room struct {
 	id      string
 	mu      sync.RWMutex
 	clients map[*websocket.Conn]*Client
 	logger  zerolog.Logger
+
+	// publish shares the messages and the presence of the room with the
+	// other nodes
+	publish func(roomID string, kind BroadcastKind, data []byte)
+
+	docMu   sync.Mutex
+	doc     *yjs.Doc
+	updates domain.CollaborationUpdatePers
+	// relayOnly rooms could not decode their updates: they only relay
+	// messages, and peers answer the sync requests
+	relayOnly bool
+	// evicted rooms had their content replaced: they drop the messages
+	// still in flight and accept no client, see evict
+	evicted atomic.Bool
 }
 
 // Client holds metadata about a connected user.
 type Client struct {
-	UserID   string
-	Username string
-	writeMu  sync.Mutex
+	UserID    string
+	Username  string
+	AvatarURL string
+	writeMu   sync.Mutex
+
+	ConnectedAt time.Time
+	// lastActivity is the last change sent to the room (unix nanoseconds)
+	lastActivity atomic.Int64
+	// readOnly clients (viewers, locked documents) cannot change the
+	// document. Set by the connection, read by the room and its presence.
+	readOnly atomic.Bool
+	// recheck wakes the access check of the connection, see
+	// Handler.watchAccess
+	recheck chan struct{}
+}
+
+// ReadOnly reports whether the client can only read the document
+func (c *Client) ReadOnly() bool {
+	return c.readOnly.Load()
+}
+
+// SetReadOnly changes the access of the client after a permission check
+func (c *Client) SetReadOnly(readOnly bool) {
+	c.readOnly.Store(readOnly)
+}
+
+// requestAccessCheck asks the connection to check the access of the client
+// again, unless a check is already pending
+func (c *Client) requestAccessCheck() {
+	select {
+	case c.recheck <- struct{}{}:
+	default:
+	}
+}
+
+func (c *Client) touch() {
+	c.lastActivity.Store(time.Now().UnixNano())
 }
 
-func newRoom(id string, logger zerolog.Logger) *Room {
-	return &Room{
+// LastActivity returns the time of the last change the client sent to the
+// room, or of its connection.
+func (c *Client) LastActivity() time.Time {
+	if last := c.lastActivity.Load(); last != 0 {
+		return time.Unix(0, last)
+	}
+	return c.ConnectedAt
+}
+
+func newRoom(id string, logger zerolog.Logger, updates domain.CollaborationUpdatePers, publish func(roomID string, kind BroadcastKind, data []byte)) *Room {
+	room := &Room{
 		id:      id,
 		clients: make(map[*websocket.Conn]*Client),
 		logger:  logger.With().Str("component", "collaboration.room").Str("room_id", id).Logger(),
+		publish: publish,
+		doc:     yjs.NewDoc(),
+		updates: updates,
+	}
+	room.load()
+	return room
+}
+
+// load rebuilds the document of the room from its update log.
+func (r *Room) load() {
+	updates, err := r.updates.GetByRoomId(r.id)
+	if err != nil {
+		r.logger.Error().Err(err).Msg("failed to load room updates")
+		r.relayOnly = true
+		return
+	}
+	for _, update := range updates {
+		if _, err := r.doc.ApplyUpdate(update.Data); err != nil {
+			r.logger.Error().Err(err).Int64("update_id", update.Id).Msg("failed to apply stored update")
+			r.relayOnly = true
+			return
+		}
+	}
+}
+
+// reload merges the update log of the room in its document, for the updates
+// of the other nodes whose messages were missed, and sends the clients of
+// this node what changed.
+func (r *Room) reload() {
+	r.docMu.Lock()
+	if r.relayOnly || r.evicted.Load() {
+		r.docMu.Unlock()
+		return
+	}
+	updates, err := r.updates.GetByRoomId(r.id)
+	if err != nil {
+		r.docMu.Unlock()
+		r.logger.Error().Err(err).Msg("failed to reload room updates")
+		return
+	}
+	stateVector := r.doc.StateVector()
+	changed := false
+	for _, update := range updates {
+		applied, err := r.doc.ApplyUpdate(update.Data)
+		if err != nil {
+			r.logger.Warn().Err(err).Int64("update_id", update.Id).Msg("failed to apply stored update")
+			continue
+		}
+		changed = changed || applied
+	}
+	var update []byte
+	if changed {
+		update, err = r.doc.EncodeStateAsUpdate(stateVector)
+	}
+	r.docMu.Unlock()
+	if err != nil {
+		r.logger.Error().Err(err).Msg("failed to encode reloaded updates")
+		return
+	}
+
+	if update != nil {
+		r.deliver(nil, encodeSyncMessage(syncUpdate, update))
 	}
 }
 
-// AddClient registers a new WebSocket connection in the room.
-func (r *Room) AddClient(conn *websocket.Conn, client *Client) {
+// AddClient registers a new WebSocket connection in the room. It returns
+// false if the room was evicted meanwhile: the client has to reconnect.
+func (r *Room) AddClient(conn *websocket.Conn, client *Client) bool {
 	r.mu.Lock()
 	defer r.mu.Unlock()
+	if r.evicted.Load() {
+		return false
+	}
 	r.clients[conn] = client
 	r.logger.Info().Str("user_id", client.UserID).Int("clients", len(r.clients)).Msg("client joined")
+	r.publishPresenceLocked()
+	return true
+}
+
+// requestAccessCheck asks the connections of the room to check the access
+// of their client again.
+func (r *Room) requestAccessCheck() {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	for _, client := range r.clients {
+		client.requestAccessCheck()
+	}
+}
+
+// Join sends the state of the room to a client that just joined: its state
+// vector, for the client to answer with what the room is missing (unless the
+// client is read-only), and the room document as a sync step 2.
+func (r *Room) Join(conn *websocket.Conn, client *Client) {
+	r.docMu.Lock()
+	if r.relayOnly {
+		r.docMu.Unlock()
+		return
+	}
+	stateVector := r.doc.StateVector()
+	update, err := r.doc.EncodeStateAsUpdate(nil)
+	r.docMu.Unlock()
+	if err != nil {
+		r.logger.Error().Err(err).Msg("failed to encode room state")
+		return
+	}
+
+	if !client.ReadOnly() {
+		r.send(conn, client, encodeSyncMessage(syncStep1, stateVector))
+	}
+	r.send(conn, client, encodeSyncMessage(syncStep2, update))
+}
+
+// HandleMessage answers the sync requests of a client, merges its updates
+// in the room document and relays the message to the other clients. The
+// updates of read-only clients are dropped.
+func (r *Room) HandleMessage(sender *websocket.Conn, client *Client, msg []byte) {
+	if client.ReadOnly() && !readOnlyMessage(msg) {
+		r.logger.Debug().Str("user_id", client.UserID).Msg("dropped message from read-only client")
+		return
+	}
+
+	message, ok, err := readSyncMessage(msg)
+	if err != nil {
+		r.logger.Warn().Err(err).Str("user_id", client.UserID).Msg("failed to decode message")
+	}
+
+	r.docMu.Lock()
+	if r.evicted.Load() {
+		r.docMu.Unlock()
+		return
+	}
+	if !ok || r.relayOnly {
+		r.docMu.Unlock()
+		r.Broadcast(sender, msg)
+		return
+	}
+
+	switch message.syncType {
+	case syncStep1:
+		// The room answers: peers do not need the request
+		update, err := r.doc.EncodeStateAsUpdate(message.payload)
+		r.docMu.Unlock()
+		if err != nil {
+			r.logger.Warn().Err(err).Str("user_id", client.UserID).Msg("failed to encode update for state vector")
+			return
+		}
+		r.send(sender, client, encodeSyncMessage(syncStep2, update))
+		return
+
+	case syncStep2, syncUpdate:
+		changed, err := r.doc.ApplyUpdate(message.payload)
+		if err != nil {
+			// Peers would diverge from the room document: drop the update
+			r.docMu.Unlock()
+			r.logger.Warn().Err(err).Str("user_id", client.UserID).Msg("failed to apply update, dropping it")
+			return
+		}
+		if changed {
+			r.persist(client, message.payload)
+			client.touch()
+		}
+	}
+	r.docMu.Unlock()
+
+	r.Broadcast(sender, msg)
+}
+
+// persist appends an update to the log of the room.
+func (r *Room) persist(client *Client, update []byte) {
+	err := r.updates.Append(&domain.CollaborationUpdate{
+		RoomId: r.id,
+		Data:   update,
+		UserId: client.UserID,
+	})
+	if err != nil {
+		r.logger.Error().Err(err).Str("user_id", client.UserID).Msg("failed to persist update")
+	}
+}
+
+func (r *Room) send(conn *websocket.Conn, client *Client, msg []byte) {
+	client.writeMu.Lock()
+	err := conn.WriteMessage(websocket.BinaryMessage, msg)
+	client.writeMu.Unlock()
+	if err != nil {
+		r.logger.Warn().Err(err).Msg("failed to write to client")
+	}
 }
 
 // RemoveClient unregisters a WebSocket connection from the room.
@@ -45,6 +287,7 @@ func (r *Room) RemoveClient(conn *websocket.Conn) {
 	client, ok := r.clients[conn]
 	if ok {
 		delete(r.clients, conn)
+		r.publishPresenceLocked()
 	}
 	count := len(r.clients)
 	r.mu.Unlock()
@@ -54,8 +297,32 @@ func (r *Room) RemoveClient(conn *websocket.Conn) {
 	}
 }
 
-// Broadcast sends a binary message to all clients except the sender.
+// receive handles a message published by another node: updates are merged
+// in the room document (the node that received them persisted them) and the
+// message is sent to all clients.
+func (r *Room) receive(msg []byte) {
+	message, ok, _ := readSyncMessage(msg)
+	if ok && (message.syncType == syncStep2 || message.syncType == syncUpdate) {
+		r.docMu.Lock()
+		if !r.relayOnly && !r.evicted.Load() {
+			if _, err := r.doc.ApplyUpdate(message.payload); err != nil {
+				r.logger.Warn().Err(err).Msg("failed to apply update from another node")
+			}
+		}
+		r.docMu.Unlock()
+	}
+	r.deliver(nil, msg)
+}
+
+// Broadcast sends a binary message to all clients except the sender, on
+// this node and on the other nodes.
 func (r *Room) Broadcast(sender *websocket.Conn, msg []byte) {
+	r.deliver(sender, msg)
+	r.publish(r.id, BroadcastRoomMessage, msg)
+}
+
+// deliver sends a binary message to the clients of this node except the sender.
+func (r *Room) deliver(sender *websocket.Conn, msg []byte) {
 	// Snapshot targets under read lock to avoid holding the lock during IO.
 	r.mu.RLock()
 	type target struct {
@@ -71,12 +338,33 @@ func (r *Room) Broadcast(sender *websocket.Conn, msg []byte) {
 	r.mu.RUnlock()
 
 	for _, t := range targets {
-		t.client.writeMu.Lock()
-		err := t.conn.WriteMessage(websocket.BinaryMessage, msg)
-		t.client.writeMu.Unlock()
-		if err != nil {
-			r.logger.Warn().Err(err).Msg("failed to write to client")
-		}
+		r.send(t.conn, t.client, msg)
+	}
+}
+
+// closeContentReplaced is the close code of the connections of a room whose
+// content was replaced outside of it: clients drop their copy of the
+// document and load its content again before reconnecting.
+const closeContentReplaced = 4000
+
+// evict disconnects the clients of a room whose content was replaced. The
+// room no longer merges, persists or relays updates, which would bring the
+// replaced content back.
+func (r *Room) evict() {
+	r.evicted.Store(true)
+	// Wait for the updates being merged
+	r.docMu.Lock()
+	r.docMu.Unlock()
+
+	r.mu.RLock()
+	clients := maps.Clone(r.clients)
+	r.mu.RUnlock()
+
+	for conn, client := range clients {
+		client.writeMu.Lock()
+		conn.WriteMessage(websocket.CloseMessage, websocket.FormatCloseMessage(closeContentReplaced, "content replaced"))
+		client.writeMu.Unlock()
+		conn.Close()
 	}
 }
 
@@ -86,3 +374,43 @@ func (r *Room) ClientCount() int {
 	defer r.mu.RUnlock()
 	return len(r.clients)
 }
+
+// Presence returns the users connected to the room on this node, one entry
+// per connection.
+func (r *Room) Presence() []domain.Presence {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.presenceLocked()
+}
+
+func (r *Room) presenceLocked() []domain.Presence {
+	presence := make([]domain.Presence, 0, len(r.clients))
+	for _, client := range r.clients {
+		presence = append(presence, domain.Presence{
+			UserId:         client.UserID,
+			Username:       client.Username,
+			AvatarUrl:      client.AvatarURL,
+			Connections:    1,
+			ReadOnly:       client.ReadOnly(),
+			ConnectedAt:    client.ConnectedAt,
+			LastActivityAt: client.LastActivity(),
+		})
+	}
+	return presence
+}
+
+// PublishPresence shares the presence of the room with the other nodes.
+func (r *Room) PublishPresence() {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	r.publishPresenceLocked()
+}
+
+func (r *Room) publishPresenceLocked() {
+	data, err := json.Marshal(r.presenceLocked())
+	if err != nil {
+		r.logger.Warn().Err(err).Msg("failed to encode presence")
+		return
+	}
+	r.publish(r.id, BroadcastPresence, data)
+}
@@ -0,0 +1,276 @@
+package yjs
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// Content ref numbers of the V1 encoding.
+const (
+	contentDeletedRef = 1
+	contentJSONRef    = 2
+	contentBinaryRef  = 3
+	contentStringRef  = 4
+	contentEmbedRef   = 5
+	contentFormatRef  = 6
+	contentTypeRef    = 7
+	contentAnyRef     = 8
+	contentDocRef     = 9
+)
+
+// Type ref numbers of shared types.
+const (
+	TypeArray       = 0
+	TypeMap         = 1
+	TypeText        = 2
+	TypeXmlElement  = 3
+	TypeXmlFragment = 4
+	TypeXmlHook     = 5
+	TypeXmlText     = 6
+)
+
+// content is the content of an item.
+type content interface {
+	length() int
+	// countable contents take part in the length of their parent
+	countable() bool
+	// splice keeps the first offset units and returns the rest
+	splice(offset int) content
+	ref() byte
+	write(e *Encoder)
+}
+
+type contentDeleted struct{ n int }
+
+func (c *contentDeleted) length() int     { return c.n }
+func (c *contentDeleted) countable() bool { return false }
+func (c *contentDeleted) ref() byte       { return contentDeletedRef }
+func (c *contentDeleted) write(e *Encoder) {
+	e.WriteVarUint(uint64(c.n))
+}
+func (c *contentDeleted) splice(offset int) content {
+	right := &contentDeleted{n: c.n - offset}
+	c.n = offset
+	return right
+}
+
+// contentJSON holds JSON values, kept encoded
+type contentJSON struct{ values []string }
+
+func (c *contentJSON) length() int     { return len(c.values) }
+func (c *contentJSON) countable() bool { return true }
+func (c *contentJSON) ref() byte       { return contentJSONRef }
+func (c *contentJSON) write(e *Encoder) {
+	e.WriteVarUint(uint64(len(c.values)))
+	for _, value := range c.values {
+		e.WriteVarString(value)
+	}
+}
+func (c *contentJSON) splice(offset int) content {
+	right := &contentJSON{values: c.values[offset:]}
+	c.values = c.values[:offset]
+	return right
+}
+
+type contentBinary struct{ data []byte }
+
+func (c *contentBinary) length() int               { return 1 }
+func (c *contentBinary) countable() bool           { return true }
+func (c *contentBinary) ref() byte                 { return contentBinaryRef }
+func (c *contentBinary) write(e *Encoder)          { e.WriteVarUint8Array(c.data) }
+func (c *contentBinary) splice(offset int) content { panic("yjs: binary content cannot be split") }
+
+// contentString holds text as UTF-16 code units, the unit of Y.js positions
+type contentString struct{ text []uint16 }
+
+func (c *contentString) length() int     { return len(c.text) }
+func (c *contentString) countable() bool { return true }
+func (c *contentString) ref() byte       { return contentStringRef }
+func (c *contentString) write(e *Encoder) {
+	e.WriteVarString(stringFromUTF16(c.text))
+}
+func (c *contentString) splice(offset int) content {
+	right := &contentString{text: append([]uint16(nil), c.text[offset:]...)}
+	c.text = c.text[:offset:offset]
+	// Do not split surrogate pairs, as Y.js does
+	if first := c.text[offset-1]; first >= 0xd800 && first <= 0xdbff {
+		c.text[offset-1] = 0xfffd
+		right.text[0] = 0xfffd
+	}
+	return right
+}
+
+type contentEmbed struct{ value string }
+
+func (c *contentEmbed) length() int               { return 1 }
+func (c *contentEmbed) countable() bool           { return true }
+func (c *contentEmbed) ref() byte                 { return contentEmbedRef }
+func (c *contentEmbed) write(e *Encoder)          { e.WriteVarString(c.value) }
+func (c *contentEmbed) splice(offset int) content { panic("yjs: embed content cannot be split") }
+
+// contentFormat starts (or ends, with a null value) a text attribute
+type contentFormat struct {
+	key   string
+	value string // JSON
+}
+
+func (c *contentFormat) length() int     { return 1 }
+func (c *contentFormat) countable() bool { return false }
+func (c *contentFormat) ref() byte       { return contentFormatRef }
+func (c *contentFormat) write(e *Encoder) {
+	e.WriteVarString(c.key)
+	e.WriteVarString(c.value)
+}
+func (c *contentFormat) splice(offset int) content { panic("yjs: format content cannot be split") }
+
+type contentType struct{ t *Type }
+
+func (c *contentType) length() int     { return 1 }
+func (c *contentType) countable() bool { return true }
+func (c *contentType) ref() byte       { return contentTypeRef }
+func (c *contentType) write(e *Encoder) {
+	e.WriteVarUint(uint64(c.t.Ref))
+	if c.t.Ref == TypeXmlElement || c.t.Ref == TypeXmlHook {
+		e.WriteVarString(c.t.NodeName)
+	}
+}
+func (c *contentType) splice(offset int) content { panic("yjs: type content cannot be split") }
+
+// contentAny holds values with their encoding, to write them back unchanged
+type contentAny struct {
+	values []any
+	raw    [][]byte
+}
+
+func (c *contentAny) length() int     { return len(c.values) }
+func (c *contentAny) countable() bool { return true }
+func (c *contentAny) ref() byte       { return contentAnyRef }
+func (c *contentAny) write(e *Encoder) {
+	e.WriteVarUint(uint64(len(c.values)))
+	for _, raw := range c.raw {
+		e.writeRaw(raw)
+	}
+}
+func (c *contentAny) splice(offset int) content {
+	right := &contentAny{values: c.values[offset:], raw: c.raw[offset:]}
+	c.values = c.values[:offset:offset]
+	c.raw = c.raw[:offset:offset]
+	return right
+}
+
+// contentDoc is a subdocument, kept encoded
+type contentDoc struct{ raw []byte }
+
+func (c *contentDoc) length() int               { return 1 }
+func (c *contentDoc) countable() bool           { return true }
+func (c *contentDoc) ref() byte                 { return contentDocRef }
+func (c *contentDoc) write(e *Encoder)          { e.writeRaw(c.raw) }
+func (c *contentDoc) splice(offset int) content { panic("yjs: doc content cannot be split") }
+
+func readContent(d *Decoder, ref byte) (content, error) {
+	switch ref {
+	case contentDeletedRef:
+		n, err := d.ReadVarUint()
+		if err != nil {
+			return nil, err
+		}
+		if n == 0 || n > 1<<31 {
+			return nil, fmt.Errorf("yjs: invalid deleted length %d", n)
+		}
+		return &contentDeleted{n: int(n)}, nil
+	case contentJSONRef:
+		n, err := d.ReadVarUint()
+		if err != nil {
+			return nil, err
+		}
+		if n == 0 || n > uint64(len(d.buf)) {
+			return nil, ErrUnexpectedEOF
+		}
+		c := &contentJSON{values: make([]string, 0, n)}
+		for i := uint64(0); i < n; i++ {
+			value, err := d.ReadVarString()
+			if err != nil {
+				return nil, err
+			}
+			c.values = append(c.values, value)
+		}
+		return c, nil
+	case contentBinaryRef:
+		data, err := d.ReadVarUint8Array()
+		return &contentBinary{data: data}, err
+	case contentStringRef:
+		s, err := d.ReadVarString()
+		if err != nil {
+			return nil, err
+		}
+		text := utf16String(s)
+		if len(text) == 0 {
+			return nil, fmt.Errorf("yjs: empty string content")
+		}
+		return &contentString{text: text}, nil
+	case contentEmbedRef:
+		value, err := d.ReadVarString()
+		return &contentEmbed{value: value}, err
+	case contentFormatRef:
+		key, err := d.ReadVarString()
+		if err != nil {
+			return nil, err
+		}
+		value, err := d.ReadVarString()
+		return &contentFormat{key: key, value: value}, err
+	case contentTypeRef:
+		typeRef, err := d.ReadVarUint()
+		if err != nil {
+			return nil, err
+		}
+		t := &Type{Ref: int(typeRef), Map: make(map[string]*Item)}
+		switch typeRef {
+		case TypeXmlElement, TypeXmlHook:
+			if t.NodeName, err = d.ReadVarString(); err != nil {
+				return nil, err
+			}
+		case TypeArray, TypeMap, TypeText, TypeXmlFragment, TypeXmlText:
+		default:
+			return nil, fmt.Errorf("yjs: unknown type ref %d", typeRef)
+		}
+		return &contentType{t: t}, nil
+	case contentAnyRef:
+		n, err := d.ReadVarUint()
+		if err != nil {
+			return nil, err
+		}
+		if n == 0 || n > uint64(len(d.buf)) {
+			return nil, ErrUnexpectedEOF
+		}
+		c := &contentAny{values: make([]any, 0, n), raw: make([][]byte, 0, n)}
+		for i := uint64(0); i < n; i++ {
+			value, raw, err := d.ReadAny()
+			if err != nil {
+				return nil, err
+			}
+			c.values = append(c.values, value)
+			c.raw = append(c.raw, raw)
+		}
+		return c, nil
+	case contentDocRef:
+		start := d.pos
+		if _, err := d.ReadVarString(); err != nil {
+			return nil, err
+		}
+		if _, _, err := d.ReadAny(); err != nil {
+			return nil, err
+		}
+		return &contentDoc{raw: d.buf[start:d.pos]}, nil
+	default:
+		return nil, fmt.Errorf("yjs: unknown content ref %d", ref)
+	}
+}
+
+// jsonValue decodes a JSON encoded value of a format or an embed.
+func jsonValue(value string) any {
+	var v any
+	if err := json.Unmarshal([]byte(value), &v); err != nil {
+		return nil
+	}
+	return v
+}
@@ -0,0 +1,704 @@
+package yjs
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+)
+
+// ID identifies an item: the client that created it and its clock, which
+// counts the units of content created by that client.
+type ID struct {
+	Client uint64
+	Clock  uint64
+}
+
+// Type is a shared type: the root types of the document and the types nested
+// in items. Sequence types chain their children from Start, map types (and
+// the attributes of XML elements) keep the last item of each key in Map.
+type Type struct {
+	Ref      int
+	NodeName string
+
+	// Name of root types, Item of nested ones
+	Name string
+	Item *Item
+
+	Start *Item
+	Map   map[string]*Item
+}
+
+// Item is a piece of content inserted by a client.
+type Item struct {
+	ID     ID
+	Length int
+
+	Origin      *ID
+	RightOrigin *ID
+	Left, Right *Item
+
+	Parent    *Type
+	ParentSub *string
+
+	content content
+	Deleted bool
+
+	// Parent as read from the update, until the item is integrated
+	parentName *string
+	parentID   *ID
+}
+
+func (i *Item) lastID() ID {
+	return ID{Client: i.ID.Client, Clock: i.ID.Clock + uint64(i.Length) - 1}
+}
+
+// gc is a range of content that was garbage collected by its client
+type gc struct {
+	ID     ID
+	Length int
+}
+
+// entry is an item or a gc of the struct store
+type entry struct {
+	item *Item
+	gc   *gc
+}
+
+func (e entry) id() ID {
+	if e.item != nil {
+		return e.item.ID
+	}
+	return e.gc.ID
+}
+
+func (e entry) length() int {
+	if e.item != nil {
+		return e.item.Length
+	}
+	return e.gc.Length
+}
+
+func (e entry) deleted() bool {
+	return e.gc != nil || e.item.Deleted
+}
+
+type deleteRange struct {
+	Client uint64
+	Clock  uint64
+	Length uint64
+}
+
+// Doc is a Y.js document built from updates. Updates may arrive in any
+// order: the structs and deletions whose dependencies are missing wait until
+// the updates they depend on are applied.
+type Doc struct {
+	roots   map[string]*Type
+	clients map[uint64][]entry
+
+	pending        []*pendingStruct
+	pendingDeletes []deleteRange
+}
+
+type pendingStruct struct {
+	item *Item
+	gc   *gc
+}
+
+func (p *pendingStruct) id() ID {
+	if p.item != nil {
+		return p.item.ID
+	}
+	return p.gc.ID
+}
+
+func (p *pendingStruct) length() int {
+	if p.item != nil {
+		return p.item.Length
+	}
+	return p.gc.Length
+}
+
+// NewDoc creates an empty document.
+func NewDoc() *Doc {
+	return &Doc{
+		roots:   make(map[string]*Type),
+		clients: make(map[uint64][]entry),
+	}
+}
+
+// Root returns the root type with the given name, nil when nothing was
+// inserted in it.
+func (d *Doc) Root(name string) *Type {
+	return d.roots[name]
+}
+
+func (d *Doc) root(name string) *Type {
+	t, ok := d.roots[name]
+	if !ok {
+		t = &Type{Ref: -1, Name: name, Map: make(map[string]*Item)}
+		d.roots[name] = t
+	}
+	return t
+}
+
+// Pending reports whether updates are waiting for missing dependencies.
+func (d *Doc) Pending() bool {
+	return len(d.pending) > 0 || len(d.pendingDeletes) > 0
+}
+
+// state returns the next clock expected from a client.
+func (d *Doc) state(client uint64) uint64 {
+	structs := d.clients[client]
+	if len(structs) == 0 {
+		return 0
+	}
+	last := structs[len(structs)-1]
+	return last.id().Clock + uint64(last.length())
+}
+
+// StateVector returns the encoded state vector of the document.
+func (d *Doc) StateVector() []byte {
+	e := &Encoder{}
+	clients := d.sortedClients()
+	e.WriteVarUint(uint64(len(clients)))
+	for _, client := range clients {
+		e.WriteVarUint(client)
+		e.WriteVarUint(d.state(client))
+	}
+	return e.Bytes()
+}
+
+func (d *Doc) sortedClients() []uint64 {
+	clients := make([]uint64, 0, len(d.clients))
+	for client, structs := range d.clients {
+		if len(structs) > 0 {
+			clients = append(clients, client)
+		}
+	}
+	// Higher clients first, as Y.js writes them
+	slices.SortFunc(clients, func(a, b uint64) int {
+		switch {
+		case a > b:
+			return -1
+		case a < b:
+			return 1
+		}
+		return 0
+	})
+	return clients
+}
+
+// ApplyUpdate merges a V1 update in the document. It reports whether the
+// update held anything new: content, deletions, or structs waiting for
+// their dependencies.
+func (d *Doc) ApplyUpdate(update []byte) (changed bool, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("yjs: invalid update: %v", r)
+		}
+	}()
+
+	structs, deletes, err := decodeUpdate(update)
+	if err != nil {
+		return false, err
+	}
+
+	for _, s := range structs {
+		if s.id().Clock+uint64(s.length()) > d.state(s.id().Client) {
+			d.pending = append(d.pending, s)
+			changed = true
+		}
+	}
+	if d.integratePending() {
+		changed = true
+	}
+
+	d.pendingDeletes = append(d.pendingDeletes, deletes...)
+	if d.applyDeletes() {
+		changed = true
+	}
+	return changed, nil
+}
+
+// integratePending integrates the pending structs whose dependencies are
+// known, until no more can be integrated.
+func (d *Doc) integratePending() bool {
+	integrated := false
+	sort.SliceStable(d.pending, func(i, j int) bool {
+		a, b := d.pending[i].id(), d.pending[j].id()
+		if a.Client != b.Client {
+			return a.Client < b.Client
+		}
+		return a.Clock < b.Clock
+	})
+
+	for progress := true; progress; {
+		progress = false
+		remaining := d.pending[:0]
+		for _, s := range d.pending {
+			id := s.id()
+			state := d.state(id.Client)
+			switch {
+			case id.Clock+uint64(s.length()) <= state:
+				// Already known
+				continue
+			case id.Clock > state || d.missing(s):
+				remaining = append(remaining, s)
+				continue
+			}
+
+			offset := int(state - id.Clock)
+			if s.gc != nil {
+				s.gc.ID.Clock += uint64(offset)
+				s.gc.Length -= offset
+				d.add(entry{gc: s.gc})
+			} else {
+				d.integrate(s.item, offset)
+			}
+			integrated = true
+			progress = true
+		}
+		d.pending = remaining
+	}
+	return integrated
+}
+
+// missing reports whether an item depends on structs of other clients that
+// are not known yet.
+func (d *Doc) missing(s *pendingStruct) bool {
+	if s.item == nil {
+		return false
+	}
+	item := s.item
+	for _, id := range []*ID{item.Origin, item.RightOrigin, item.parentID} {
+		if id != nil && id.Client != item.ID.Client && id.Clock >= d.state(id.Client) {
+			return true
+		}
+	}
+	return false
+}
+
+func (d *Doc) add(e entry) {
+	client := e.id().Client
+	d.clients[client] = append(d.clients[client], e)
+}
+
+// find returns the index of the struct holding the clock of a client.
+func (d *Doc) find(id ID) (int, bool) {
+	structs := d.clients[id.Client]
+	index := sort.Search(len(structs), func(i int) bool {
+		return structs[i].id().Clock+uint64(structs[i].length()) > id.Clock
+	})
+	if index == len(structs) || structs[index].id().Clock > id.Clock {
+		return 0, false
+	}
+	return index, true
+}
+
+func (d *Doc) insertAt(client uint64, index int, e entry) {
+	d.clients[client] = slices.Insert(d.clients[client], index, e)
+}
+
+// itemCleanStart returns the struct starting at id, splitting the item
+// holding it when needed.
+func (d *Doc) itemCleanStart(id ID) (entry, bool) {
+	index, ok := d.find(id)
+	if !ok {
+		return entry{}, false
+	}
+	e := d.clients[id.Client][index]
+	if e.item != nil && e.item.ID.Clock < id.Clock {
+		right := d.split(e.item, int(id.Clock-e.item.ID.Clock))
+		d.insertAt(id.Client, index+1, entry{item: right})
+		return entry{item: right}, true
+	}
+	return e, true
+}
+
+// itemCleanEnd returns the struct ending at id, splitting the item holding
+// it when needed.
+func (d *Doc) itemCleanEnd(id ID) (entry, bool) {
+	index, ok := d.find(id)
+	if !ok {
+		return entry{}, false
+	}
+	e := d.clients[id.Client][index]
+	if e.item != nil && id.Clock != e.item.lastID().Clock {
+		right := d.split(e.item, int(id.Clock-e.item.ID.Clock)+1)
+		d.insertAt(id.Client, index+1, entry{item: right})
+	}
+	return e, true
+}
+
+// split cuts an item at diff and returns the right part.
+func (d *Doc) split(left *Item, diff int) *Item {
+	right := &Item{
+		ID:          ID{Client: left.ID.Client, Clock: left.ID.Clock + uint64(diff)},
+		Length:      left.Length - diff,
+		Origin:      &ID{Client: left.ID.Client, Clock: left.ID.Clock + uint64(diff) - 1},
+		RightOrigin: left.RightOrigin,
+		Left:        left,
+		Right:       left.Right,
+		Parent:      left.Parent,
+		ParentSub:   left.ParentSub,
+		Deleted:     left.Deleted,
+		content:     left.content.splice(diff),
+	}
+	left.Length = diff
+	left.Right = right
+	if right.Right != nil {
+		right.Right.Left = right
+	}
+	if right.ParentSub != nil && right.Right == nil && right.Parent != nil {
+		right.Parent.Map[*right.ParentSub] = right
+	}
+	return right
+}
+
+// integrate inserts an item in its parent with the YATA conflict resolution
+// of Y.js, so that every peer orders concurrent insertions the same way.
+func (d *Doc) integrate(item *Item, offset int) {
+	// Resolve the neighbours and the parent of the item
+	var left, right entry
+	if item.Origin != nil {
+		left, _ = d.itemCleanEnd(*item.Origin)
+	}
+	if item.RightOrigin != nil {
+		right, _ = d.itemCleanStart(*item.RightOrigin)
+	}
+	switch {
+	case left.gc != nil || right.gc != nil:
+		item.Parent = nil
+	case item.parentName != nil:
+		item.Parent = d.root(*item.parentName)
+	case item.parentID != nil:
+		item.Parent = nil
+		if index, ok := d.find(*item.parentID); ok {
+			if parent := d.clients[item.parentID.Client][index].item; parent != nil {
+				if c, ok := parent.content.(*contentType); ok {
+					item.Parent = c.t
+				}
+			}
+		}
+	case left.item != nil:
+		item.Parent = left.item.Parent
+		item.ParentSub = left.item.ParentSub
+	case right.item != nil:
+		item.Parent = right.item.Parent
+		item.ParentSub = right.item.ParentSub
+	}
+	item.Left = left.item
+	item.Right = right.item
+	if item.Origin != nil && left.item != nil {
+		lastID := left.item.lastID()
+		item.Origin = &lastID
+	}
+
+	if offset > 0 {
+		item.ID.Clock += uint64(offset)
+		if e, ok := d.itemCleanEnd(ID{Client: item.ID.Client, Clock: item.ID.Clock - 1}); ok {
+			item.Left = e.item
+			if e.gc != nil {
+				item.Parent = nil
+			}
+		}
+		item.Origin = &ID{Client: item.ID.Client, Clock: item.ID.Clock - 1}
+		item.content = item.content.splice(offset)
+		item.Length -= offset
+	}
+
+	if item.Parent == nil {
+		d.add(entry{gc: &gc{ID: item.ID, Length: item.Length}})
+		return
+	}
+	parent := item.Parent
+
+	if (item.Left == nil && (item.Right == nil || item.Right.Left != nil)) || (item.Left != nil && item.Left.Right != item.Right) {
+		o := d.firstConflict(item)
+		// Items between o and the right neighbour that are concurrent
+		// insertions are ordered by client, keeping their origins in order
+		conflicting := make(map[*Item]bool)
+		before := make(map[*Item]bool)
+		for o != nil && o != item.Right {
+			before[o] = true
+			conflicting[o] = true
+			switch {
+			case sameID(item.Origin, o.Origin):
+				if o.ID.Client < item.ID.Client {
+					item.Left = o
+					clear(conflicting)
+				} else if sameID(item.RightOrigin, o.RightOrigin) {
+					o = nil
+					continue
+				}
+			case o.Origin != nil && before[d.itemAt(*o.Origin)]:
+				if !conflicting[d.itemAt(*o.Origin)] {
+					item.Left = o
+					clear(conflicting)
+				}
+			default:
+				o = nil
+				continue
+			}
+			o = o.Right
+		}
+	}
+
+	// Reconnect the list
+	if item.Left != nil {
+		item.Right = item.Left.Right
+		item.Left.Right = item
+	} else {
+		var r *Item
+		if item.ParentSub != nil {
+			r = parent.Map[*item.ParentSub]
+			for r != nil && r.Left != nil {
+				r = r.Left
+			}
+		} else {
+			r = parent.Start
+			parent.Start = item
+		}
+		item.Right = r
+	}
+	if item.Right != nil {
+		item.Right.Left = item
+	} else if item.ParentSub != nil {
+		// The last item of a key holds its value, the others are overwritten
+		parent.Map[*item.ParentSub] = item
+		if item.Left != nil {
+			d.deleteItem(item.Left)
+		}
+	}
+	if item.ParentSub != nil && item.Right != nil {
+		// Inserted before the current value: it is overwritten already
+		d.deleteItem(item)
+	}
+	if parent.Item != nil && parent.Item.Deleted {
+		d.deleteItem(item)
+	}
+
+	d.add(entry{item: item})
+
+	switch c := item.content.(type) {
+	case *contentDeleted:
+		item.Deleted = true
+	case *contentType:
+		c.t.Item = item
+	}
+}
+
+// firstConflict returns the first item after the left neighbour of an item
+// in its parent.
+func (d *Doc) firstConflict(item *Item) *Item {
+	if item.Left != nil {
+		return item.Left.Right
+	}
+	if item.ParentSub != nil {
+		o := item.Parent.Map[*item.ParentSub]
+		for o != nil && o.Left != nil {
+			o = o.Left
+		}
+		return o
+	}
+	return item.Parent.Start
+}
+
+// itemAt returns the item holding id, nil for unknown ids and gc.
+func (d *Doc) itemAt(id ID) *Item {
+	index, ok := d.find(id)
+	if !ok {
+		return nil
+	}
+	return d.clients[id.Client][index].item
+}
+
+func sameID(a, b *ID) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
+
+// deleteItem marks an item deleted, with the content of its type.
+func (d *Doc) deleteItem(item *Item) {
+	if item.Deleted {
+		return
+	}
+	item.Deleted = true
+	if c, ok := item.content.(*contentType); ok {
+		for child := c.t.Start; child != nil; child = child.Right {
+			d.deleteItem(child)
+		}
+		for _, child := range c.t.Map {
+			d.deleteItem(child)
+		}
+	}
+}
+
+// applyDeletes applies the pending deletions of known structs, and keeps the
+// deletions of structs that are not known yet.
+func (d *Doc) applyDeletes() bool {
+	changed := false
+	var remaining []deleteRange
+	for _, r := range d.pendingDeletes {
+		state := d.state(r.Client)
+		end := r.Clock + r.Length
+		if end > state {
+			from := max(r.Clock, state)
+			remaining = append(remaining, deleteRange{Client: r.Client, Clock: from, Length: end - from})
+			end = state
+		}
+		if r.Clock >= end {
+			continue
+		}
+
+		index, ok := d.find(ID{Client: r.Client, Clock: r.Clock})
+		if !ok {
+			continue
+		}
+		structs := d.clients[r.Client]
+		if e := structs[index]; e.item != nil && !e.item.Deleted && e.item.ID.Clock < r.Clock {
+			right := d.split(e.item, int(r.Clock-e.item.ID.Clock))
+			d.insertAt(r.Client, index+1, entry{item: right})
+			index++
+		}
+		for structs = d.clients[r.Client]; index < len(structs) && structs[index].id().Clock < end; structs = d.clients[r.Client] {
+			e := structs[index]
+			if e.item != nil && !e.item.Deleted {
+				if end < e.item.ID.Clock+uint64(e.item.Length) {
+					right := d.split(e.item, int(end-e.item.ID.Clock))
+					d.insertAt(r.Client, index+1, entry{item: right})
+				}
+				d.deleteItem(e.item)
+				changed = true
+			}
+			index++
+		}
+	}
+	d.pendingDeletes = remaining
+	return changed
+}
+
+// EncodeStateAsUpdate encodes the document as a single V1 update. With a
+// state vector, the structs known by its owner are left out: the first
+// struct it partially knows is written whole, Y.js skipping the known part.
+func (d *Doc) EncodeStateAsUpdate(stateVector []byte) ([]byte, error) {
+	known := make(map[uint64]uint64)
+	if len(stateVector) > 0 {
+		sv := NewDecoder(stateVector)
+		n, err := sv.ReadVarUint()
+		if err != nil {
+			return nil, err
+		}
+		for i := uint64(0); i < n; i++ {
+			client, err := sv.ReadVarUint()
+			if err != nil {
+				return nil, err
+			}
+			if known[client], err = sv.ReadVarUint(); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	e := &Encoder{}
+	var clients []uint64
+	for _, client := range d.sortedClients() {
+		if d.state(client) > known[client] {
+			clients = append(clients, client)
+		}
+	}
+	e.WriteVarUint(uint64(len(clients)))
+	for _, client := range clients {
+		structs := d.clients[client]
+		if known[client] > 0 {
+			index, _ := d.find(ID{Client: client, Clock: known[client]})
+			structs = structs[index:]
+		}
+		e.WriteVarUint(uint64(len(structs)))
+		e.WriteVarUint(client)
+		e.WriteVarUint(structs[0].id().Clock)
+		for _, s := range structs {
+			if s.gc != nil {
+				e.WriteUint8(0)
+				e.WriteVarUint(uint64(s.gc.Length))
+			} else {
+				writeItem(e, s.item)
+			}
+		}
+	}
+	writeDeleteSet(e, d.deleteSet())
+	return e.Bytes(), nil
+}
+
+// PendingUpdate encodes the structs and deletions waiting for their
+// dependencies, nil when there are none.
+func (d *Doc) PendingUpdate() []byte {
+	if !d.Pending() {
+		return nil
+	}
+	e := &Encoder{}
+	byClient := make(map[uint64][]*pendingStruct)
+	var clients []uint64
+	for _, s := range d.pending {
+		client := s.id().Client
+		if _, ok := byClient[client]; !ok {
+			clients = append(clients, client)
+		}
+		byClient[client] = append(byClient[client], s)
+	}
+
+	// Pending structs of a client may have gaps: each run is written on its own
+	type run struct {
+		client  uint64
+		structs []*pendingStruct
+	}
+	var runs []run
+	for _, client := range clients {
+		structs := byClient[client]
+		for len(structs) > 0 {
+			n := 1
+			for n < len(structs) && structs[n].id().Clock == structs[n-1].id().Clock+uint64(structs[n-1].length()) {
+				n++
+			}
+			runs = append(runs, run{client: client, structs: structs[:n]})
+			structs = structs[n:]
+		}
+	}
+	e.WriteVarUint(uint64(len(runs)))
+	for _, r := range runs {
+		e.WriteVarUint(uint64(len(r.structs)))
+		e.WriteVarUint(r.client)
+		e.WriteVarUint(r.structs[0].id().Clock)
+		for _, s := range r.structs {
+			if s.gc != nil {
+				e.WriteUint8(0)
+				e.WriteVarUint(uint64(s.gc.Length))
+			} else {
+				writeItem(e, s.item)
+			}
+		}
+	}
+	writeDeleteSet(e, d.pendingDeletes)
+	return e.Bytes()
+}
+
+// deleteSet lists the deleted ranges of the known structs.
+func (d *Doc) deleteSet() []deleteRange {
+	var ranges []deleteRange
+	for _, client := range d.sortedClients() {
+		for _, s := range d.clients[client] {
+			if !s.deleted() {
+				continue
+			}
+			id := s.id()
+			if n := len(ranges); n > 0 && ranges[n-1].Client == client && ranges[n-1].Clock+ranges[n-1].Length == id.Clock {
+				ranges[n-1].Length += uint64(s.length())
+				continue
+			}
+			ranges = append(ranges, deleteRange{Client: client, Clock: id.Clock, Length: uint64(s.length())})
+		}
+	}
+	return ranges
+}
@@ -0,0 +1,336 @@
+package yjs
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+)
+
+// helloUpdate is the update of Y.js for ytext.insert(0, "hi") by client 1 in
+// the root text "t"
+var helloUpdate = []byte{1, 1, 1, 0, 4, 1, 1, 't', 2, 'h', 'i', 0}
+
+// updateBuilder writes the updates of a client, one struct after the other.
+type updateBuilder struct {
+	client uint64
+	clock  uint64
+	items  []*Item
+}
+
+// add appends an item to the next update and returns its id.
+func (b *updateBuilder) add(item *Item) ID {
+	item.ID = ID{Client: b.client, Clock: b.clock}
+	item.Length = item.content.length()
+	b.clock += uint64(item.Length)
+	b.items = append(b.items, item)
+	return item.ID
+}
+
+// update encodes the items added since the last update, with a delete set.
+func (b *updateBuilder) update(deletes ...deleteRange) []byte {
+	e := &Encoder{}
+	if len(b.items) == 0 {
+		e.WriteVarUint(0)
+	} else {
+		e.WriteVarUint(1)
+		e.WriteVarUint(uint64(len(b.items)))
+		e.WriteVarUint(b.client)
+		e.WriteVarUint(b.items[0].ID.Clock)
+		for _, item := range b.items {
+			writeItem(e, item)
+		}
+	}
+	writeDeleteSet(e, deletes)
+	b.items = nil
+	return e.Bytes()
+}
+
+func root(name string) *string { return &name }
+
+func stringContent(s string) content { return &contentString{text: utf16String(s)} }
+
+func anyString(s string) content {
+	e := &Encoder{}
+	e.WriteUint8(119)
+	e.WriteVarString(s)
+	return &contentAny{values: []any{s}, raw: [][]byte{e.Bytes()}}
+}
+
+func element(name string) content {
+	return &contentType{t: &Type{Ref: TypeXmlElement, NodeName: name, Map: map[string]*Item{}}}
+}
+
+func textOf(t *testing.T, d *Doc, name string) string {
+	t.Helper()
+	root := d.Root(name)
+	if root == nil {
+		return ""
+	}
+	var text string
+	for _, run := range textRuns(root) {
+		text += run.Text
+	}
+	return text
+}
+
+func apply(t *testing.T, d *Doc, update []byte) bool {
+	t.Helper()
+	changed, err := d.ApplyUpdate(update)
+	if err != nil {
+		t.Fatalf("ApplyUpdate: %v", err)
+	}
+	return changed
+}
+
+func TestApplyUpdateOfYjs(t *testing.T) {
+	d := NewDoc()
+	if !apply(t, d, helloUpdate) {
+		t.Fatal("ApplyUpdate reported no change")
+	}
+	if got := textOf(t, d, "t"); got != "hi" {
+		t.Errorf("text = %q, want %q", got, "hi")
+	}
+	if got, want := d.StateVector(), []byte{1, 1, 2}; !bytes.Equal(got, want) {
+		t.Errorf("StateVector = %v, want %v", got, want)
+	}
+	update, err := d.EncodeStateAsUpdate(nil)
+	if err != nil {
+		t.Fatalf("EncodeStateAsUpdate: %v", err)
+	}
+	if !bytes.Equal(update, helloUpdate) {
+		t.Errorf("EncodeStateAsUpdate = %v, want %v", update, helloUpdate)
+	}
+
+	if apply(t, d, helloUpdate) {
+		t.Error("applying an update twice reported a change")
+	}
+}
+
+func TestApplyUpdateRejectsTruncatedUpdates(t *testing.T) {
+	for n := 1; n < len(helloUpdate); n++ {
+		if _, err := NewDoc().ApplyUpdate(helloUpdate[:n]); err == nil {
+			t.Errorf("ApplyUpdate of %d bytes: no error", n)
+		}
+	}
+}
+
+func TestMergeOrdersConcurrentInsertsByClient(t *testing.T) {
+	a := &updateBuilder{client: 1}
+	a.add(&Item{parentName: root("t"), content: stringContent("a")})
+	b := &updateBuilder{client: 2}
+	b.add(&Item{parentName: root("t"), content: stringContent("b")})
+	updateA, updateB := a.update(), b.update()
+
+	first, second := NewDoc(), NewDoc()
+	apply(t, first, updateA)
+	apply(t, first, updateB)
+	apply(t, second, updateB)
+	apply(t, second, updateA)
+
+	for _, d := range []*Doc{first, second} {
+		if got := textOf(t, d, "t"); got != "ab" {
+			t.Errorf("text = %q, want %q", got, "ab")
+		}
+	}
+	encodedFirst, _ := first.EncodeStateAsUpdate(nil)
+	encodedSecond, _ := second.EncodeStateAsUpdate(nil)
+	if !bytes.Equal(encodedFirst, encodedSecond) {
+		t.Error("the documents differ with the order of the updates")
+	}
+}
+
+func TestInsertSplitsItems(t *testing.T) {
+	a := &updateBuilder{client: 1}
+	a.add(&Item{parentName: root("t"), content: stringContent("hi")})
+	a.add(&Item{Origin: &ID{Client: 1, Clock: 1}, content: stringContent("!")})
+	b := &updateBuilder{client: 2}
+	b.add(&Item{Origin: &ID{Client: 1, Clock: 0}, RightOrigin: &ID{Client: 1, Clock: 1}, content: stringContent("X")})
+
+	d := NewDoc()
+	apply(t, d, a.update())
+	apply(t, d, b.update())
+	if got := textOf(t, d, "t"); got != "hXi!" {
+		t.Errorf("text = %q, want %q", got, "hXi!")
+	}
+
+	// The merged state converges on another peer
+	update, err := d.EncodeStateAsUpdate(nil)
+	if err != nil {
+		t.Fatalf("EncodeStateAsUpdate: %v", err)
+	}
+	peer := NewDoc()
+	apply(t, peer, update)
+	if got := textOf(t, peer, "t"); got != "hXi!" {
+		t.Errorf("text of the peer = %q, want %q", got, "hXi!")
+	}
+}
+
+func TestDeleteSet(t *testing.T) {
+	a := &updateBuilder{client: 1}
+	a.add(&Item{parentName: root("t"), content: stringContent("hello")})
+	d := NewDoc()
+	apply(t, d, a.update())
+
+	if !apply(t, d, a.update(deleteRange{Client: 1, Clock: 1, Length: 3})) {
+		t.Error("the deletion reported no change")
+	}
+	if got := textOf(t, d, "t"); got != "ho" {
+		t.Errorf("text = %q, want %q", got, "ho")
+	}
+	if apply(t, d, a.update(deleteRange{Client: 1, Clock: 1, Length: 3})) {
+		t.Error("deleting again reported a change")
+	}
+
+	update, err := d.EncodeStateAsUpdate(nil)
+	if err != nil {
+		t.Fatalf("EncodeStateAsUpdate: %v", err)
+	}
+	peer := NewDoc()
+	apply(t, peer, update)
+	if got := textOf(t, peer, "t"); got != "ho" {
+		t.Errorf("text of the peer = %q, want %q", got, "ho")
+	}
+	if got, want := peer.deleteSet(), []deleteRange{{Client: 1, Clock: 1, Length: 3}}; !reflect.DeepEqual(got, want) {
+		t.Errorf("delete set of the peer = %v, want %v", got, want)
+	}
+}
+
+func TestEncodeStateAsUpdateSkipsKnownStructs(t *testing.T) {
+	a := &updateBuilder{client: 1}
+	a.add(&Item{parentName: root("t"), content: stringContent("hi")})
+	first := a.update()
+	a.add(&Item{Origin: &ID{Client: 1, Clock: 1}, content: stringContent(" there")})
+	second := a.update()
+
+	d := NewDoc()
+	apply(t, d, first)
+	apply(t, d, second)
+
+	peer := NewDoc()
+	apply(t, peer, first)
+	diff, err := d.EncodeStateAsUpdate(peer.StateVector())
+	if err != nil {
+		t.Fatalf("EncodeStateAsUpdate: %v", err)
+	}
+	full, _ := d.EncodeStateAsUpdate(nil)
+	if len(diff) >= len(full) {
+		t.Errorf("diff of %d bytes is not smaller than the state of %d bytes", len(diff), len(full))
+	}
+	apply(t, peer, diff)
+	if got := textOf(t, peer, "t"); got != "hi there" {
+		t.Errorf("text = %q, want %q", got, "hi there")
+	}
+	if !bytes.Equal(peer.StateVector(), d.StateVector()) {
+		t.Errorf("StateVector = %v, want %v", peer.StateVector(), d.StateVector())
+	}
+
+	upToDate, _ := d.EncodeStateAsUpdate(d.StateVector())
+	if got, want := upToDate, []byte{0, 0}; !bytes.Equal(got, want) {
+		t.Errorf("update for an up to date peer = %v, want %v", got, want)
+	}
+}
+
+func TestPendingStructs(t *testing.T) {
+	a := &updateBuilder{client: 1}
+	a.add(&Item{parentName: root("t"), content: stringContent("hi")})
+	first := a.update()
+	b := &updateBuilder{client: 2}
+	b.add(&Item{Origin: &ID{Client: 1, Clock: 1}, content: stringContent("!")})
+	second := b.update(deleteRange{Client: 1, Clock: 0, Length: 1})
+
+	d := NewDoc()
+	if !apply(t, d, second) {
+		t.Error("an update waiting for its dependencies reported no change")
+	}
+	if !d.Pending() {
+		t.Fatal("Pending = false, want true")
+	}
+	if got := textOf(t, d, "t"); got != "" {
+		t.Errorf("text = %q before the dependencies, want none", got)
+	}
+	if got, want := d.StateVector(), []byte{0}; !bytes.Equal(got, want) {
+		t.Errorf("StateVector = %v, want %v", got, want)
+	}
+
+	// The pending update is kept whole, for the peers that have the dependencies
+	peer := NewDoc()
+	apply(t, peer, first)
+	apply(t, peer, d.PendingUpdate())
+	if got := textOf(t, peer, "t"); got != "i!" {
+		t.Errorf("text of the peer = %q, want %q", got, "i!")
+	}
+
+	apply(t, d, first)
+	if d.Pending() {
+		t.Error("Pending = true once the dependencies are known")
+	}
+	if d.PendingUpdate() != nil {
+		t.Error("PendingUpdate is not nil once the dependencies are known")
+	}
+	if got := textOf(t, d, "t"); got != "i!" {
+		t.Errorf("text = %q, want %q", got, "i!")
+	}
+}
+
+func TestMapKeepsTheLastValue(t *testing.T) {
+	a := &updateBuilder{client: 1}
+	first := a.add(&Item{parentName: root("m"), ParentSub: root("k"), content: anyString("one")})
+	a.add(&Item{Origin: &first, ParentSub: root("k"), content: anyString("two")})
+
+	d := NewDoc()
+	apply(t, d, a.update())
+	if got, want := attributes(d.Root("m")), map[string]any{"k": "two"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("attributes = %v, want %v", got, want)
+	}
+	if got, want := d.deleteSet(), []deleteRange{{Client: 1, Clock: 0, Length: 1}}; !reflect.DeepEqual(got, want) {
+		t.Errorf("delete set = %v, want %v", got, want)
+	}
+}
+
+func TestXmlFragment(t *testing.T) {
+	a := &updateBuilder{client: 1}
+	group := a.add(&Item{parentName: root("document-store"), content: element("blockGroup")})
+	container := a.add(&Item{parentID: &group, content: element("blockContainer")})
+	a.add(&Item{parentID: &container, ParentSub: root("id"), content: anyString("b1")})
+	paragraph := a.add(&Item{parentID: &container, content: element("paragraph")})
+	text := a.add(&Item{parentID: &paragraph, content: &contentType{t: &Type{Ref: TypeXmlText, Map: map[string]*Item{}}}})
+	hello := a.add(&Item{parentID: &text, content: stringContent("Hello ")})
+	bold := a.add(&Item{Origin: &ID{Client: 1, Clock: hello.Clock + 5}, content: &contentFormat{key: "bold", value: "true"}})
+	world := a.add(&Item{Origin: &bold, content: stringContent("world")})
+	a.add(&Item{Origin: &ID{Client: 1, Clock: world.Clock + 4}, content: &contentFormat{key: "bold", value: "null"}})
+
+	d := NewDoc()
+	apply(t, d, a.update())
+	if d.Pending() {
+		t.Fatal("the fragment has pending structs")
+	}
+
+	want := []XmlNode{{
+		Name:       "blockGroup",
+		Attributes: map[string]any{},
+		Children: []XmlNode{{
+			Name:       "blockContainer",
+			Attributes: map[string]any{"id": "b1"},
+			Children: []XmlNode{{
+				Name:       "paragraph",
+				Attributes: map[string]any{},
+				Children: []XmlNode{{Text: []TextRun{
+					{Text: "Hello ", Attributes: map[string]any{}},
+					{Text: "world", Attributes: map[string]any{"bold": true}},
+				}}},
+			}},
+		}},
+	}}
+	if got := d.XmlFragment("document-store"); !reflect.DeepEqual(got, want) {
+		t.Errorf("XmlFragment = %+v, want %+v", got, want)
+	}
+	if got := d.XmlFragment("other"); got != nil {
+		t.Errorf("XmlFragment of a missing root = %+v, want nil", got)
+	}
+
+	// Deleting the container deletes its content
+	apply(t, d, a.update(deleteRange{Client: 1, Clock: container.Clock, Length: 1}))
+	if got := d.XmlFragment("document-store"); len(got) != 1 || len(got[0].Children) != 0 {
+		t.Errorf("XmlFragment after the deletion = %+v, want an empty blockGroup", got)
+	}
+}
@@ -0,0 +1,246 @@
+// Package yjs reads and writes the binary formats of Y.js (lib0 encoding,
+// V1 document updates) and merges updates into a document, so that the server
+// can keep the state of collaboration rooms without a JavaScript runtime.
+package yjs
+
+import (
+	"encoding/binary"
+	"errors"
+	"math"
+	"unicode/utf16"
+)
+
+// ErrUnexpectedEOF is returned when a message or an update is truncated.
+var ErrUnexpectedEOF = errors.New("yjs: unexpected end of data")
+
+// Decoder reads lib0 encoded values.
+type Decoder struct {
+	buf []byte
+	pos int
+}
+
+// NewDecoder creates a decoder over buf.
+func NewDecoder(buf []byte) *Decoder {
+	return &Decoder{buf: buf}
+}
+
+// HasContent reports whether there are bytes left to read.
+func (d *Decoder) HasContent() bool {
+	return d.pos < len(d.buf)
+}
+
+func (d *Decoder) ReadUint8() (byte, error) {
+	if d.pos >= len(d.buf) {
+		return 0, ErrUnexpectedEOF
+	}
+	b := d.buf[d.pos]
+	d.pos++
+	return b, nil
+}
+
+func (d *Decoder) readBytes(n int) ([]byte, error) {
+	if n < 0 || len(d.buf)-d.pos < n {
+		return nil, ErrUnexpectedEOF
+	}
+	b := d.buf[d.pos : d.pos+n]
+	d.pos += n
+	return b, nil
+}
+
+// ReadVarUint reads an unsigned integer of 7 bits per byte.
+func (d *Decoder) ReadVarUint() (uint64, error) {
+	var num uint64
+	var shift uint
+	for {
+		b, err := d.ReadUint8()
+		if err != nil {
+			return 0, err
+		}
+		if shift > 63 {
+			return 0, errors.New("yjs: varuint overflow")
+		}
+		num |= uint64(b&0x7f) << shift
+		if b < 0x80 {
+			return num, nil
+		}
+		shift += 7
+	}
+}
+
+// ReadVarInt reads a signed integer: the first byte holds the sign and 6 bits.
+func (d *Decoder) ReadVarInt() (int64, error) {
+	b, err := d.ReadUint8()
+	if err != nil {
+		return 0, err
+	}
+	num := int64(b & 0x3f)
+	negative := b&0x40 != 0
+	shift := uint(6)
+	for b&0x80 != 0 {
+		if b, err = d.ReadUint8(); err != nil {
+			return 0, err
+		}
+		if shift > 62 {
+			return 0, errors.New("yjs: varint overflow")
+		}
+		num |= int64(b&0x7f) << shift
+		shift += 7
+	}
+	if negative {
+		num = -num
+	}
+	return num, nil
+}
+
+// ReadVarUint8Array reads a length-prefixed byte array.
+func (d *Decoder) ReadVarUint8Array() ([]byte, error) {
+	n, err := d.ReadVarUint()
+	if err != nil {
+		return nil, err
+	}
+	if n > uint64(len(d.buf)) {
+		return nil, ErrUnexpectedEOF
+	}
+	return d.readBytes(int(n))
+}
+
+// ReadVarString reads a length-prefixed UTF-8 string.
+func (d *Decoder) ReadVarString() (string, error) {
+	b, err := d.ReadVarUint8Array()
+	return string(b), err
+}
+
+// ReadAny reads a value encoded with lib0 writeAny, and returns its encoding
+// too so that it can be written back unchanged.
+func (d *Decoder) ReadAny() (any, []byte, error) {
+	start := d.pos
+	value, err := d.readAny(0)
+	if err != nil {
+		return nil, nil, err
+	}
+	return value, d.buf[start:d.pos], nil
+}
+
+func (d *Decoder) readAny(depth int) (any, error) {
+	if depth > 64 {
+		return nil, errors.New("yjs: value nested too deeply")
+	}
+	tag, err := d.ReadUint8()
+	if err != nil {
+		return nil, err
+	}
+	switch tag {
+	case 127, 126: // undefined, null
+		return nil, nil
+	case 125:
+		return d.ReadVarInt()
+	case 124:
+		b, err := d.readBytes(4)
+		if err != nil {
+			return nil, err
+		}
+		return float64(math.Float32frombits(binary.BigEndian.Uint32(b))), nil
+	case 123:
+		b, err := d.readBytes(8)
+		if err != nil {
+			return nil, err
+		}
+		return math.Float64frombits(binary.BigEndian.Uint64(b)), nil
+	case 122:
+		b, err := d.readBytes(8)
+		if err != nil {
+			return nil, err
+		}
+		return int64(binary.BigEndian.Uint64(b)), nil
+	case 121:
+		return false, nil
+	case 120:
+		return true, nil
+	case 119:
+		return d.ReadVarString()
+	case 118:
+		n, err := d.ReadVarUint()
+		if err != nil {
+			return nil, err
+		}
+		object := make(map[string]any)
+		for i := uint64(0); i < n; i++ {
+			key, err := d.ReadVarString()
+			if err != nil {
+				return nil, err
+			}
+			if object[key], err = d.readAny(depth + 1); err != nil {
+				return nil, err
+			}
+		}
+		return object, nil
+	case 117:
+		n, err := d.ReadVarUint()
+		if err != nil {
+			return nil, err
+		}
+		if n > uint64(len(d.buf)) {
+			return nil, ErrUnexpectedEOF
+		}
+		array := make([]any, 0, n)
+		for i := uint64(0); i < n; i++ {
+			value, err := d.readAny(depth + 1)
+			if err != nil {
+				return nil, err
+			}
+			array = append(array, value)
+		}
+		return array, nil
+	case 116:
+		return d.ReadVarUint8Array()
+	default:
+		return nil, errors.New("yjs: unknown value type")
+	}
+}
+
+// Encoder writes lib0 encoded values.
+type Encoder struct {
+	buf []byte
+}
+
+// Bytes returns the encoded data.
+func (e *Encoder) Bytes() []byte {
+	return e.buf
+}
+
+func (e *Encoder) WriteUint8(b byte) {
+	e.buf = append(e.buf, b)
+}
+
+func (e *Encoder) WriteVarUint(num uint64) {
+	for num > 0x7f {
+		e.buf = append(e.buf, byte(0x80|(num&0x7f)))
+		num >>= 7
+	}
+	e.buf = append(e.buf, byte(num))
+}
+
+func (e *Encoder) WriteVarUint8Array(b []byte) {
+	e.WriteVarUint(uint64(len(b)))
+	e.buf = append(e.buf, b...)
+}
+
+func (e *Encoder) WriteVarString(s string) {
+	e.WriteVarUint8Array([]byte(s))
+}
+
+// writeRaw appends bytes that are already encoded.
+func (e *Encoder) writeRaw(b []byte) {
+	e.buf = append(e.buf, b...)
+}
+
+// utf16String converts a string to the UTF-16 code units Y.js counts in.
+func utf16String(s string) []uint16 {
+	return utf16.Encode([]rune(s))
+}
+
+// stringFromUTF16 converts UTF-16 code units back to a string, unpaired
+// surrogates becoming U+FFFD as with the TextEncoder of lib0.
+func stringFromUTF16(s []uint16) string {
+	return string(utf16.Decode(s))
+}
@@ -0,0 +1,237 @@
+package yjs
+
+import (
+	"fmt"
+)
+
+// Bits of the info byte of structs
+const (
+	infoContentRef = 0x1f
+	infoParentSub  = 0x20
+	infoRight      = 0x40
+	infoOrigin     = 0x80
+
+	gcRef   = 0
+	skipRef = 10
+)
+
+// decodeUpdate reads the structs and the delete set of a V1 update.
+func decodeUpdate(update []byte) ([]*pendingStruct, []deleteRange, error) {
+	d := NewDecoder(update)
+	var structs []*pendingStruct
+
+	clients, err := d.ReadVarUint()
+	if err != nil {
+		return nil, nil, err
+	}
+	for i := uint64(0); i < clients; i++ {
+		count, err := d.ReadVarUint()
+		if err != nil {
+			return nil, nil, err
+		}
+		if count > uint64(len(update)) {
+			return nil, nil, ErrUnexpectedEOF
+		}
+		client, err := d.ReadVarUint()
+		if err != nil {
+			return nil, nil, err
+		}
+		clock, err := d.ReadVarUint()
+		if err != nil {
+			return nil, nil, err
+		}
+		for j := uint64(0); j < count; j++ {
+			s, length, err := readStruct(d, ID{Client: client, Clock: clock})
+			if err != nil {
+				return nil, nil, err
+			}
+			if s != nil {
+				structs = append(structs, s)
+			}
+			clock += length
+		}
+	}
+
+	deletes, err := readDeleteSet(d)
+	if err != nil {
+		return nil, nil, err
+	}
+	return structs, deletes, nil
+}
+
+// readStruct reads a struct and returns it with its length. Skips, which
+// only fill gaps in merged updates, are returned as nil.
+func readStruct(d *Decoder, id ID) (*pendingStruct, uint64, error) {
+	info, err := d.ReadUint8()
+	if err != nil {
+		return nil, 0, err
+	}
+
+	switch info & infoContentRef {
+	case gcRef, skipRef:
+		length, err := d.ReadVarUint()
+		if err != nil {
+			return nil, 0, err
+		}
+		if length == 0 || length > 1<<31 {
+			return nil, 0, fmt.Errorf("yjs: invalid struct length %d", length)
+		}
+		if info&infoContentRef == skipRef {
+			return nil, length, nil
+		}
+		return &pendingStruct{gc: &gc{ID: id, Length: int(length)}}, length, nil
+	}
+
+	item := &Item{ID: id}
+	if info&infoOrigin != 0 {
+		if item.Origin, err = readID(d); err != nil {
+			return nil, 0, err
+		}
+	}
+	if info&infoRight != 0 {
+		if item.RightOrigin, err = readID(d); err != nil {
+			return nil, 0, err
+		}
+	}
+	if info&(infoOrigin|infoRight) == 0 {
+		// Without neighbours, the parent is written
+		isRoot, err := d.ReadVarUint()
+		if err != nil {
+			return nil, 0, err
+		}
+		if isRoot == 1 {
+			name, err := d.ReadVarString()
+			if err != nil {
+				return nil, 0, err
+			}
+			item.parentName = &name
+		} else if item.parentID, err = readID(d); err != nil {
+			return nil, 0, err
+		}
+		if info&infoParentSub != 0 {
+			sub, err := d.ReadVarString()
+			if err != nil {
+				return nil, 0, err
+			}
+			item.ParentSub = &sub
+		}
+	}
+
+	if item.content, err = readContent(d, info&infoContentRef); err != nil {
+		return nil, 0, err
+	}
+	item.Length = item.content.length()
+	return &pendingStruct{item: item}, uint64(item.Length), nil
+}
+
+func readID(d *Decoder) (*ID, error) {
+	client, err := d.ReadVarUint()
+	if err != nil {
+		return nil, err
+	}
+	clock, err := d.ReadVarUint()
+	if err != nil {
+		return nil, err
+	}
+	return &ID{Client: client, Clock: clock}, nil
+}
+
+func readDeleteSet(d *Decoder) ([]deleteRange, error) {
+	var ranges []deleteRange
+	clients, err := d.ReadVarUint()
+	if err != nil {
+		return nil, err
+	}
+	for i := uint64(0); i < clients; i++ {
+		client, err := d.ReadVarUint()
+		if err != nil {
+			return nil, err
+		}
+		count, err := d.ReadVarUint()
+		if err != nil {
+			return nil, err
+		}
+		if count > uint64(len(d.buf)) {
+			return nil, ErrUnexpectedEOF
+		}
+		for j := uint64(0); j < count; j++ {
+			clock, err := d.ReadVarUint()
+			if err != nil {
+				return nil, err
+			}
+			length, err := d.ReadVarUint()
+			if err != nil {
+				return nil, err
+			}
+			if length > 0 {
+				ranges = append(ranges, deleteRange{Client: client, Clock: clock, Length: length})
+			}
+		}
+	}
+	return ranges, nil
+}
+
+func writeItem(e *Encoder, item *Item) {
+	info := item.content.ref() & infoContentRef
+	if item.Origin != nil {
+		info |= infoOrigin
+	}
+	if item.RightOrigin != nil {
+		info |= infoRight
+	}
+	if item.ParentSub != nil {
+		info |= infoParentSub
+	}
+	e.WriteUint8(info)
+
+	if item.Origin != nil {
+		e.WriteVarUint(item.Origin.Client)
+		e.WriteVarUint(item.Origin.Clock)
+	}
+	if item.RightOrigin != nil {
+		e.WriteVarUint(item.RightOrigin.Client)
+		e.WriteVarUint(item.RightOrigin.Clock)
+	}
+	if item.Origin == nil && item.RightOrigin == nil {
+		parentName, parentID := item.parentName, item.parentID
+		if item.Parent != nil {
+			if item.Parent.Item == nil {
+				parentName, parentID = &item.Parent.Name, nil
+			} else {
+				parentName, parentID = nil, &item.Parent.Item.ID
+			}
+		}
+		if parentName != nil {
+			e.WriteVarUint(1)
+			e.WriteVarString(*parentName)
+		} else {
+			e.WriteVarUint(0)
+			e.WriteVarUint(parentID.Client)
+			e.WriteVarUint(parentID.Clock)
+		}
+		if item.ParentSub != nil {
+			e.WriteVarString(*item.ParentSub)
+		}
+	}
+	item.content.write(e)
+}
+
+func writeDeleteSet(e *Encoder, ranges []deleteRange) {
+	var clients []uint64
+	byClient := make(map[uint64][]deleteRange)
+	for _, r := range ranges {
+		if _, ok := byClient[r.Client]; !ok {
+			clients = append(clients, r.Client)
+		}
+		byClient[r.Client] = append(byClient[r.Client], r)
+	}
+	e.WriteVarUint(uint64(len(clients)))
+	for _, client := range clients {
+		e.WriteVarUint(client)
+		e.WriteVarUint(uint64(len(byClient[client])))
+		for _, r := range byClient[client] {
+			e.WriteVarUint(r.Clock)
+			e.WriteVarUint(r.Length)
+		}
+	}
+}
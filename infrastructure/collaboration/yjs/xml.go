@@ -0,0 +1,115 @@
+package yjs
+
+// XmlNode is a node of an XML fragment: an element with its attributes and
+// children, or a text with its formatted runs.
+type XmlNode struct {
+	// Name of elements, empty for texts
+	Name       string
+	Attributes map[string]any
+	Children   []XmlNode
+
+	Text []TextRun
+}
+
+// TextRun is a run of text sharing the same formatting attributes.
+type TextRun struct {
+	Text       string
+	Attributes map[string]any
+}
+
+// IsText reports whether the node is a text.
+func (n XmlNode) IsText() bool {
+	return n.Name == ""
+}
+
+// XmlFragment returns the children of the root XML fragment with the given
+// name, nil when the document does not have it.
+func (d *Doc) XmlFragment(name string) []XmlNode {
+	root := d.Root(name)
+	if root == nil {
+		return nil
+	}
+	return xmlChildren(root)
+}
+
+func xmlChildren(t *Type) []XmlNode {
+	var nodes []XmlNode
+	for item := t.Start; item != nil; item = item.Right {
+		if item.Deleted {
+			continue
+		}
+		c, ok := item.content.(*contentType)
+		if !ok {
+			continue
+		}
+		switch c.t.Ref {
+		case TypeXmlElement:
+			nodes = append(nodes, XmlNode{
+				Name:       c.t.NodeName,
+				Attributes: attributes(c.t),
+				Children:   xmlChildren(c.t),
+			})
+		case TypeXmlText, TypeText:
+			nodes = append(nodes, XmlNode{Text: textRuns(c.t)})
+		}
+	}
+	return nodes
+}
+
+// attributes returns the current values of the keys of a type.
+func attributes(t *Type) map[string]any {
+	values := make(map[string]any)
+	for key, item := range t.Map {
+		if item.Deleted {
+			continue
+		}
+		switch c := item.content.(type) {
+		case *contentAny:
+			values[key] = c.values[len(c.values)-1]
+		case *contentString:
+			values[key] = stringFromUTF16(c.text)
+		case *contentJSON:
+			values[key] = jsonValue(c.values[len(c.values)-1])
+		}
+	}
+	return values
+}
+
+// textRuns returns the text of a text type, split where the formatting
+// attributes change.
+func textRuns(t *Type) []TextRun {
+	var runs []TextRun
+	current := make(map[string]any)
+	var text []uint16
+
+	flush := func() {
+		if len(text) == 0 {
+			return
+		}
+		attributes := make(map[string]any, len(current))
+		for key, value := range current {
+			attributes[key] = value
+		}
+		runs = append(runs, TextRun{Text: stringFromUTF16(text), Attributes: attributes})
+		text = nil
+	}
+
+	for item := t.Start; item != nil; item = item.Right {
+		if item.Deleted {
+			continue
+		}
+		switch c := item.content.(type) {
+		case *contentString:
+			text = append(text, c.text...)
+		case *contentFormat:
+			flush()
+			if value := jsonValue(c.value); value == nil {
+				delete(current, c.key)
+			} else {
+				current[c.key] = value
+			}
+		}
+	}
+	flush()
+	return runs
+}
@@ -0,0 +1,22 @@
+package config
+
+import (
+	altsrc "github.com/urfave/cli-altsrc/v3"
+	altsrcyaml "github.com/urfave/cli-altsrc/v3/yaml"
+	"github.com/urfave/cli/v3"
+)
+
+func CollaborationFlags(cfg *Config) []cli.Flag {
+	return []cli.Flag{
+		&cli.StringFlag{
+			Name:        "collaboration.broadcaster",
+			Usage:       "How collaboration rooms are shared between nodes (memory, postgres)",
+			Value:       "memory",
+			Destination: &cfg.Collaboration.Broadcaster,
+			Sources: cli.NewValueSourceChain(
+				cli.EnvVar("COLLABORATION_BROADCASTER"),
+				altsrcyaml.YAML("collaboration.broadcaster", altsrc.NewStringPtrSourcer(&cfg.ConfigFile)),
+			),
+		},
+	}
+}
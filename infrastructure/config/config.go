@@ -65,6 +65,42 @@ type Config struct {
 		Scopes       []string
 	}
 
+	// Webhook configures the webhook delivery queue.
+	// MaxAttempts is the number of attempts before a delivery is marked as failed.
+	// DisableAfterFailures auto-disables a webhook after this many consecutive failed attempts (0 = never).
+	Webhook struct {
+		MaxAttempts          int
+		DisableAfterFailures int
+	}
+
+	// Collaboration configures the WebSocket collaboration rooms.
+	// Broadcaster shares the rooms between the nodes of a deployment: "memory"
+	// for a single node, "postgres" (LISTEN/NOTIFY) for several nodes.
+	Collaboration struct {
+		Broadcaster string
+	}
+
+	// Storage configures where the content of attachments is stored.
+	// Backend is "local" (files under Local.Path) or "s3" (any S3-compatible
+	// service: AWS, MinIO...). MaxUploadSize is in megabytes.
+	Storage struct {
+		Backend       string
+		MaxUploadSize int
+		Local         struct {
+			Path string
+		}
+		S3 struct {
+			Endpoint        string
+			Region          string
+			Bucket          string
+			AccessKeyID     string
+			SecretAccessKey string
+			// UsePathStyle addresses the bucket in the path (MinIO) instead
+			// of the host name
+			UsePathStyle bool
+		}
+	}
+
 	ExportOapi struct {
 		FileName string
 	}
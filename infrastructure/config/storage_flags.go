@@ -0,0 +1,95 @@
+package config
+
+import (
+	altsrc "github.com/urfave/cli-altsrc/v3"
+	altsrcyaml "github.com/urfave/cli-altsrc/v3/yaml"
+	"github.com/urfave/cli/v3"
+)
+
+func StorageFlags(cfg *Config) []cli.Flag {
+	return []cli.Flag{
+		&cli.StringFlag{
+			Name:        "storage.backend",
+			Usage:       "Where the content of attachments is stored (local, s3)",
+			Value:       "local",
+			Destination: &cfg.Storage.Backend,
+			Sources: cli.NewValueSourceChain(
+				cli.EnvVar("STORAGE_BACKEND"),
+				altsrcyaml.YAML("storage.backend", altsrc.NewStringPtrSourcer(&cfg.ConfigFile)),
+			),
+		},
+		&cli.IntFlag{
+			Name:        "storage.max_upload_size",
+			Usage:       "Maximum size of an attachment, in megabytes",
+			Value:       10,
+			Destination: &cfg.Storage.MaxUploadSize,
+			Sources: cli.NewValueSourceChain(
+				cli.EnvVar("STORAGE_MAX_UPLOAD_SIZE"),
+				altsrcyaml.YAML("storage.max_upload_size", altsrc.NewStringPtrSourcer(&cfg.ConfigFile)),
+			),
+		},
+		&cli.StringFlag{
+			Name:        "storage.local.path",
+			Usage:       "Directory of the attachments with the local backend",
+			Value:       "./data/attachments",
+			Destination: &cfg.Storage.Local.Path,
+			Sources: cli.NewValueSourceChain(
+				cli.EnvVar("STORAGE_LOCAL_PATH"),
+				altsrcyaml.YAML("storage.local.path", altsrc.NewStringPtrSourcer(&cfg.ConfigFile)),
+			),
+		},
+		&cli.StringFlag{
+			Name:        "storage.s3.endpoint",
+			Usage:       "URL of the S3-compatible service (e.g. https://s3.eu-west-1.amazonaws.com, http://minio:9000)",
+			Destination: &cfg.Storage.S3.Endpoint,
+			Sources: cli.NewValueSourceChain(
+				cli.EnvVar("STORAGE_S3_ENDPOINT"),
+				altsrcyaml.YAML("storage.s3.endpoint", altsrc.NewStringPtrSourcer(&cfg.ConfigFile)),
+			),
+		},
+		&cli.StringFlag{
+			Name:        "storage.s3.region",
+			Usage:       "Region of the bucket",
+			Value:       "us-east-1",
+			Destination: &cfg.Storage.S3.Region,
+			Sources: cli.NewValueSourceChain(
+				cli.EnvVar("STORAGE_S3_REGION"),
+				altsrcyaml.YAML("storage.s3.region", altsrc.NewStringPtrSourcer(&cfg.ConfigFile)),
+			),
+		},
+		&cli.StringFlag{
+			Name:        "storage.s3.bucket",
+			Usage:       "Bucket of the attachments",
+			Destination: &cfg.Storage.S3.Bucket,
+			Sources: cli.NewValueSourceChain(
+				cli.EnvVar("STORAGE_S3_BUCKET"),
+				altsrcyaml.YAML("storage.s3.bucket", altsrc.NewStringPtrSourcer(&cfg.ConfigFile)),
+			),
+		},
+		&cli.StringFlag{
+			Name:        "storage.s3.access_key_id",
+			Destination: &cfg.Storage.S3.AccessKeyID,
+			Sources: cli.NewValueSourceChain(
+				cli.EnvVar("STORAGE_S3_ACCESS_KEY_ID"),
+				altsrcyaml.YAML("storage.s3.access_key_id", altsrc.NewStringPtrSourcer(&cfg.ConfigFile)),
+			),
+		},
+		&cli.StringFlag{
+			Name:        "storage.s3.secret_access_key",
+			Destination: &cfg.Storage.S3.SecretAccessKey,
+			Sources: cli.NewValueSourceChain(
+				cli.EnvVar("STORAGE_S3_SECRET_ACCESS_KEY"),
+				altsrcyaml.YAML("storage.s3.secret_access_key", altsrc.NewStringPtrSourcer(&cfg.ConfigFile)),
+			),
+		},
+		&cli.BoolFlag{
+			Name:        "storage.s3.use_path_style",
+			Usage:       "Address the bucket in the path instead of the host name (MinIO)",
+			Destination: &cfg.Storage.S3.UsePathStyle,
+			Sources: cli.NewValueSourceChain(
+				cli.EnvVar("STORAGE_S3_USE_PATH_STYLE"),
+				altsrcyaml.YAML("storage.s3.use_path_style", altsrc.NewStringPtrSourcer(&cfg.ConfigFile)),
+			),
+		},
+	}
+}
@@ -0,0 +1,32 @@
+package config
+
+import (
+	altsrc "github.com/urfave/cli-altsrc/v3"
+	altsrcyaml "github.com/urfave/cli-altsrc/v3/yaml"
+	"github.com/urfave/cli/v3"
+)
+
+func WebhookFlags(cfg *Config) []cli.Flag {
+	return []cli.Flag{
+		&cli.IntFlag{
+			Name:        "webhook.max_attempts",
+			Usage:       "Number of delivery attempts before a webhook delivery is marked as failed",
+			Value:       8,
+			Destination: &cfg.Webhook.MaxAttempts,
+			Sources: cli.NewValueSourceChain(
+				cli.EnvVar("WEBHOOK_MAX_ATTEMPTS"),
+				altsrcyaml.YAML("webhook.max_attempts", altsrc.NewStringPtrSourcer(&cfg.ConfigFile)),
+			),
+		},
+		&cli.IntFlag{
+			Name:        "webhook.disable_after_failures",
+			Usage:       "Disable a webhook after this many consecutive failed attempts (0 = never)",
+			Value:       20,
+			Destination: &cfg.Webhook.DisableAfterFailures,
+			Sources: cli.NewValueSourceChain(
+				cli.EnvVar("WEBHOOK_DISABLE_AFTER_FAILURES"),
+				altsrcyaml.YAML("webhook.disable_after_failures", altsrc.NewStringPtrSourcer(&cfg.ConfigFile)),
+			),
+		},
+	}
+}
@@ -3,13 +3,17 @@ package infrastructure
 import (
 	"github.com/labbs/nexo/application/action"
 	"github.com/labbs/nexo/application/apikey"
+	"github.com/labbs/nexo/application/attachment"
 	"github.com/labbs/nexo/application/auth"
 	databaseApp "github.com/labbs/nexo/application/database"
 	"github.com/labbs/nexo/application/document"
 	"github.com/labbs/nexo/application/drawing"
+	"github.com/labbs/nexo/application/event"
 	"github.com/labbs/nexo/application/favorite"
 	"github.com/labbs/nexo/application/group"
+	"github.com/labbs/nexo/application/importer"
 	"github.com/labbs/nexo/application/permission"
+	"github.com/labbs/nexo/application/search"
 	"github.com/labbs/nexo/application/session"
 	"github.com/labbs/nexo/application/space"
 	"github.com/labbs/nexo/application/user"
@@ -40,9 +44,13 @@ type Deps struct {
 	DatabaseApplication   *databaseApp.DatabaseApplication
 	DrawingApplication    *drawing.DrawingApplication
 	ActionApplication     *action.ActionApplication
+	EventApplication      *event.EventApplication
 	GroupApplication      *group.GroupApplication
 	FavoriteApplication   *favorite.FavoriteApplication
 	PermissionApplication *permission.PermissionApplication
+	SearchApplication     *search.SearchApplication
+	AttachmentApplication *attachment.AttachmentApplication
+	ImporterApplication   *importer.ImporterApplication
 	PermissionPers        domain.PermissionPers
 	OAuthProviderPers     domain.OAuthProviderPers
 
@@ -4,8 +4,34 @@ import (
 	fiberoapi "github.com/labbs/fiber-oapi"
 	"github.com/labbs/nexo/application/session"
 	"github.com/labbs/nexo/application/session/dto"
+	"github.com/labbs/nexo/domain"
 )
 
+// bearerAuthScheme is the security scheme of the API: a session JWT or an API key
+const bearerAuthScheme = "bearerAuth"
+
+// RequireScopes returns the security requirement of a route that API keys can
+// only call when they were granted all the scopes. Sessions hold every scope.
+func RequireScopes(scopes ...domain.ApiKeyScope) []map[string][]string {
+	required := make([]string, len(scopes))
+	for i, scope := range scopes {
+		required[i] = string(scope)
+	}
+	return []map[string][]string{{bearerAuthScheme: required}}
+}
+
+// SessionOnly returns the security requirement of a route that cannot be
+// called with an API key
+func SessionOnly() []map[string][]string {
+	return RequireScopes(domain.ApiKeyScopeSession)
+}
+
+// MemberRoles returns the roles of a route that guests cannot call: guests
+// only access the resources shared with them
+func MemberRoles() []string {
+	return []string{string(domain.RoleUser), string(domain.RoleAdmin)}
+}
+
 // SessionAuthAdapter adapts SessionApp to fiberoapi.AuthorizationService
 type SessionAuthAdapter struct {
 	sessionApp *session.SessionApp
@@ -2,6 +2,9 @@ package http
 
 import (
 	"encoding/json"
+	"io"
+	"slices"
+	"strings"
 	"time"
 
 	"github.com/labbs/nexo/application/session"
@@ -17,6 +20,13 @@ import (
 	z "github.com/rs/zerolog"
 )
 
+// defaultBodyLimit is the maximum size of the request bodies, except uploads
+const defaultBodyLimit = 10 * 1024 * 1024 // 10 MB
+
+// uploadPaths are the routes of the uploads (attachments, wiki imports), whose
+// bodies may be as large as the maximum upload size
+var uploadPaths = []string{"/api/v1/attachment", "/api/v1/admin/import"}
+
 type Config struct {
 	Fiber     *fiber.App
 	FiberOapi *fiberoapi.OApiApp
@@ -27,14 +37,22 @@ type Config struct {
 // Will return an error if the server cannot be created (fatal)
 func Configure(_cfg config.Config, logger z.Logger, sessionApp *session.SessionApp, enableIU bool) (Config, error) {
 	var c Config
+
+	// Request bodies are streamed: their size is checked against the limit
+	// of their route before they are read, see limitBody
+	uploadLimit := (_cfg.Storage.MaxUploadSize + 1) * 1024 * 1024
+
 	fiberConfig := fiber.Config{
 		JSONEncoder:           json.Marshal,
 		JSONDecoder:           json.Unmarshal,
 		DisableStartupMessage: true,
-		BodyLimit:             10 * 1024 * 1024, // 10 MB
-		ReadTimeout:           30 * time.Second,
-		WriteTimeout:          30 * time.Second,
-		IdleTimeout:           120 * time.Second,
+		BodyLimit:             defaultBodyLimit,
+		StreamRequestBody:     true,
+		// Uploads are parsed by their handler, once their size was checked
+		DisablePreParseMultipartForm: true,
+		ReadTimeout:                  30 * time.Second,
+		WriteTimeout:                 30 * time.Second,
+		IdleTimeout:                  120 * time.Second,
 	}
 
 	r := fiber.New(fiberConfig)
@@ -60,6 +78,7 @@ func Configure(_cfg config.Config, logger z.Logger, sessionApp *session.SessionA
 		c.Set("Referrer-Policy", "strict-origin-when-cross-origin")
 		return c.Next()
 	})
+	r.Use(limitBody(uploadLimit))
 	r.Use(compress.New())
 	r.Use(requestid.New())
 
@@ -74,15 +93,15 @@ func Configure(_cfg config.Config, logger z.Logger, sessionApp *session.SessionA
 		AuthService:         authAdapter,
 		EnableAuthorization: true,
 		SecuritySchemes: map[string]fiberoapi.SecurityScheme{
-			"bearerAuth": {
+			bearerAuthScheme: {
 				Type:         "http",
 				Scheme:       "bearer",
 				BearerFormat: "JWT",
-				Description:  "JWT Bearer token",
+				Description:  "JWT Bearer token, or API key (zk_...) restricted to its scopes",
 			},
 		},
 		DefaultSecurity: []map[string][]string{
-			{"bearerAuth": {}},
+			{bearerAuthScheme: {}},
 		},
 	}
 
@@ -91,3 +110,51 @@ func Configure(_cfg config.Config, logger z.Logger, sessionApp *session.SessionA
 
 	return c, nil
 }
+
+// limitBody rejects the request bodies larger than the limit of their route,
+// before reading them: defaultBodyLimit, or uploadLimit for the uploads.
+// Uploads must announce their length; the other bodies sent in chunks are
+// read up to the limit.
+func limitBody(uploadLimit int) fiber.Handler {
+	// The unread bodies are left on the connection: it is closed
+	tooLarge := func(c *fiber.Ctx) error {
+		c.Context().SetConnectionClose()
+		return c.Status(fiber.StatusRequestEntityTooLarge).JSON(fiberoapi.ErrorResponse{Code: fiber.StatusRequestEntityTooLarge, Details: "Request body too large", Type: "BAD_REQUEST"})
+	}
+
+	return func(c *fiber.Ctx) error {
+		request := c.Request()
+		length := request.Header.ContentLength()
+
+		if isUpload(c) {
+			if length < 0 {
+				c.Context().SetConnectionClose()
+				return c.Status(fiber.StatusLengthRequired).JSON(fiberoapi.ErrorResponse{Code: fiber.StatusLengthRequired, Details: "Content-Length is required for uploads", Type: "BAD_REQUEST"})
+			}
+			if length > uploadLimit {
+				return tooLarge(c)
+			}
+			return c.Next()
+		}
+
+		if length > defaultBodyLimit {
+			return tooLarge(c)
+		}
+		if length < 0 && request.IsBodyStream() {
+			body, err := io.ReadAll(io.LimitReader(request.BodyStream(), defaultBodyLimit+1))
+			if err != nil {
+				return c.Status(fiber.StatusBadRequest).JSON(fiberoapi.ErrorResponse{Code: fiber.StatusBadRequest, Details: "Failed to read request body", Type: "BAD_REQUEST"})
+			}
+			if len(body) > defaultBodyLimit {
+				return tooLarge(c)
+			}
+			request.SetBody(body)
+		}
+		return c.Next()
+	}
+}
+
+// isUpload reports whether the request is an upload
+func isUpload(c *fiber.Ctx) bool {
+	return c.Method() == fiber.MethodPost && slices.Contains(uploadPaths, strings.TrimSuffix(c.Path(), "/"))
+}
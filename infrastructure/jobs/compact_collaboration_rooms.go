@@ -0,0 +1,25 @@
+package jobs
+
+import (
+	"time"
+
+	"github.com/go-co-op/gocron/v2"
+)
+
+// CompactCollaborationRooms compacts the update logs of the collaboration
+// rooms and snapshots the documents edited in them
+func (c *Config) CompactCollaborationRooms() error {
+	logger := c.Logger.With().Str("component", "infrastructure.jobs.compact_collaboration_rooms").Logger()
+
+	_, err := c.CronScheduler.CronScheduler.NewJob(
+		gocron.DurationJob(5*time.Minute), // Every 5 minutes
+		gocron.NewTask(func() { _ = c.CollaborationHub.CompactRooms() }),
+		gocron.WithName("CompactCollaborationRooms"),
+		gocron.WithSingletonMode(gocron.LimitModeReschedule),
+	)
+	if err != nil {
+		logger.Error().Err(err).Msg("failed to schedule CompactCollaborationRooms job")
+	}
+
+	return err
+}
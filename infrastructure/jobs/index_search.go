@@ -0,0 +1,30 @@
+package jobs
+
+import (
+	"time"
+
+	"github.com/go-co-op/gocron/v2"
+)
+
+// IndexSearch indexes the resources missing from the search index, starting
+// with the existing data when the server starts
+func (c *Config) IndexSearch() error {
+	logger := c.Logger.With().Str("component", "infrastructure.jobs.index_search").Logger()
+
+	_, err := c.CronScheduler.CronScheduler.NewJob(
+		gocron.DurationJob(10*time.Minute), // Every 10 minutes
+		gocron.NewTask(func() {
+			if err := c.SearchApp.IndexMissing(); err != nil {
+				logger.Error().Err(err).Msg("failed to index missing search entries")
+			}
+		}),
+		gocron.WithName("IndexSearch"),
+		gocron.WithSingletonMode(gocron.LimitModeReschedule),
+		gocron.WithStartAt(gocron.WithStartImmediately()),
+	)
+	if err != nil {
+		logger.Error().Err(err).Msg("failed to schedule IndexSearch job")
+	}
+
+	return err
+}
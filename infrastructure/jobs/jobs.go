@@ -1,7 +1,12 @@
 package jobs
 
 import (
+	"github.com/labbs/nexo/application/action"
+	"github.com/labbs/nexo/application/database"
+	"github.com/labbs/nexo/application/search"
 	"github.com/labbs/nexo/application/session"
+	"github.com/labbs/nexo/application/webhook"
+	"github.com/labbs/nexo/infrastructure/collaboration"
 	"github.com/labbs/nexo/infrastructure/cronscheduler"
 	"github.com/rs/zerolog"
 )
@@ -10,6 +15,12 @@ type Config struct {
 	Logger        zerolog.Logger
 	CronScheduler cronscheduler.Config
 	SessionApp    session.SessionApp
+	WebhookApp    webhook.WebhookApp
+	// ActionApp is a pointer: it registers the scheduled actions on itself
+	ActionApp        *action.ActionApplication
+	SearchApp        *search.SearchApplication
+	DatabaseApp      *database.DatabaseApplication
+	CollaborationHub *collaboration.Hub
 }
 
 func (c *Config) SetupJobs() error {
@@ -20,5 +31,35 @@ func (c *Config) SetupJobs() error {
 		return err
 	}
 
+	if err := c.ProcessWebhookDeliveries(); err != nil {
+		logger.Error().Err(err).Msg("failed to setup ProcessWebhookDeliveries job")
+		return err
+	}
+
+	if err := c.IndexSearch(); err != nil {
+		logger.Error().Err(err).Msg("failed to setup IndexSearch job")
+		return err
+	}
+
+	if err := c.RefreshTimeFormulas(); err != nil {
+		logger.Error().Err(err).Msg("failed to setup RefreshTimeFormulas job")
+		return err
+	}
+
+	if err := c.CompactCollaborationRooms(); err != nil {
+		logger.Error().Err(err).Msg("failed to setup CompactCollaborationRooms job")
+		return err
+	}
+
+	if err := c.PublishCollaborationPresence(); err != nil {
+		logger.Error().Err(err).Msg("failed to setup PublishCollaborationPresence job")
+		return err
+	}
+
+	if err := c.ActionApp.ScheduleActions(); err != nil {
+		logger.Error().Err(err).Msg("failed to register scheduled actions")
+		return err
+	}
+
 	return nil
 }
@@ -0,0 +1,23 @@
+package jobs
+
+import (
+	"time"
+
+	"github.com/go-co-op/gocron/v2"
+)
+
+func (c *Config) ProcessWebhookDeliveries() error {
+	logger := c.Logger.With().Str("component", "infrastructure.jobs.process_webhook_deliveries").Logger()
+
+	_, err := c.CronScheduler.CronScheduler.NewJob(
+		gocron.DurationJob(15*time.Second), // Every 15 seconds
+		gocron.NewTask(func() { _ = c.WebhookApp.ProcessPendingDeliveries() }),
+		gocron.WithName("ProcessWebhookDeliveries"),
+		gocron.WithSingletonMode(gocron.LimitModeReschedule),
+	)
+	if err != nil {
+		logger.Error().Err(err).Msg("failed to schedule ProcessWebhookDeliveries job")
+	}
+
+	return err
+}
@@ -0,0 +1,25 @@
+package jobs
+
+import (
+	"time"
+
+	"github.com/go-co-op/gocron/v2"
+)
+
+// PublishCollaborationPresence shares the presence of the collaboration rooms
+// of this node with the other nodes, so that it does not expire there
+func (c *Config) PublishCollaborationPresence() error {
+	logger := c.Logger.With().Str("component", "infrastructure.jobs.publish_collaboration_presence").Logger()
+
+	_, err := c.CronScheduler.CronScheduler.NewJob(
+		gocron.DurationJob(30*time.Second), // Every 30 seconds
+		gocron.NewTask(func() { c.CollaborationHub.PublishPresence() }),
+		gocron.WithName("PublishCollaborationPresence"),
+		gocron.WithSingletonMode(gocron.LimitModeReschedule),
+	)
+	if err != nil {
+		logger.Error().Err(err).Msg("failed to schedule PublishCollaborationPresence job")
+	}
+
+	return err
+}
@@ -0,0 +1,27 @@
+package jobs
+
+import (
+	"github.com/go-co-op/gocron/v2"
+)
+
+// RefreshTimeFormulas computes again the stored values of the formulas using
+// now() or today()
+func (c *Config) RefreshTimeFormulas() error {
+	logger := c.Logger.With().Str("component", "infrastructure.jobs.refresh_time_formulas").Logger()
+
+	_, err := c.CronScheduler.CronScheduler.NewJob(
+		gocron.CronJob("0 * * * *", false), // Every hour
+		gocron.NewTask(func() {
+			if err := c.DatabaseApp.RefreshTimeFormulas(); err != nil {
+				logger.Error().Err(err).Msg("failed to refresh time formulas")
+			}
+		}),
+		gocron.WithName("RefreshTimeFormulas"),
+		gocron.WithSingletonMode(gocron.LimitModeReschedule),
+	)
+	if err != nil {
+		logger.Error().Err(err).Msg("failed to schedule RefreshTimeFormulas job")
+	}
+
+	return err
+}
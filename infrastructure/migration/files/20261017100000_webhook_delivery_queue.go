@@ -0,0 +1,74 @@
+package migrations
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/pressly/goose/v3"
+)
+
+func init() {
+	goose.AddMigrationContext(upWebhookDeliveryQueue, downWebhookDeliveryQueue)
+}
+
+func upWebhookDeliveryQueue(ctx context.Context, tx *sql.Tx) error {
+	var query string
+	dialect, _ := ctx.Value("dbDialect").(string)
+	switch dialect {
+	case "sqlite":
+		query = `
+		ALTER TABLE webhook ADD COLUMN consecutive_failures INTEGER DEFAULT 0;
+
+		ALTER TABLE webhook_delivery ADD COLUMN status TEXT NOT NULL DEFAULT 'succeeded';
+		ALTER TABLE webhook_delivery ADD COLUMN attempts INTEGER DEFAULT 1;
+		ALTER TABLE webhook_delivery ADD COLUMN next_attempt_at TIMESTAMP;
+		ALTER TABLE webhook_delivery ADD COLUMN error TEXT;
+		ALTER TABLE webhook_delivery ADD COLUMN updated_at TIMESTAMP;
+		UPDATE webhook_delivery SET status = 'failed' WHERE success = 0;
+		UPDATE webhook_delivery SET updated_at = created_at;
+		CREATE INDEX IF NOT EXISTS idx_webhook_delivery_status_next_attempt ON webhook_delivery(status, next_attempt_at);
+		`
+	case "postgres":
+		query = `
+		ALTER TABLE webhook ADD COLUMN consecutive_failures INTEGER DEFAULT 0;
+
+		ALTER TABLE webhook_delivery ADD COLUMN status TEXT NOT NULL DEFAULT 'succeeded';
+		ALTER TABLE webhook_delivery ADD COLUMN attempts INTEGER DEFAULT 1;
+		ALTER TABLE webhook_delivery ADD COLUMN next_attempt_at TIMESTAMPTZ;
+		ALTER TABLE webhook_delivery ADD COLUMN error TEXT;
+		ALTER TABLE webhook_delivery ADD COLUMN updated_at TIMESTAMPTZ;
+		UPDATE webhook_delivery SET status = 'failed' WHERE success = FALSE;
+		UPDATE webhook_delivery SET updated_at = created_at;
+		CREATE INDEX IF NOT EXISTS idx_webhook_delivery_status_next_attempt ON webhook_delivery(status, next_attempt_at);
+		`
+	default:
+		return fmt.Errorf("unsupported dialect: %s", dialect)
+	}
+
+	_, err := tx.ExecContext(ctx, query)
+	return err
+}
+
+func downWebhookDeliveryQueue(ctx context.Context, tx *sql.Tx) error {
+	dialect, _ := ctx.Value("dbDialect").(string)
+	switch dialect {
+	case "sqlite":
+		// SQLite doesn't support DROP COLUMN before 3.35.0
+		_, err := tx.ExecContext(ctx, `DROP INDEX IF EXISTS idx_webhook_delivery_status_next_attempt;`)
+		return err
+	case "postgres":
+		_, err := tx.ExecContext(ctx, `
+		DROP INDEX IF EXISTS idx_webhook_delivery_status_next_attempt;
+		ALTER TABLE webhook_delivery DROP COLUMN IF EXISTS status;
+		ALTER TABLE webhook_delivery DROP COLUMN IF EXISTS attempts;
+		ALTER TABLE webhook_delivery DROP COLUMN IF EXISTS next_attempt_at;
+		ALTER TABLE webhook_delivery DROP COLUMN IF EXISTS error;
+		ALTER TABLE webhook_delivery DROP COLUMN IF EXISTS updated_at;
+		ALTER TABLE webhook DROP COLUMN IF EXISTS consecutive_failures;
+		`)
+		return err
+	default:
+		return fmt.Errorf("unsupported dialect: %s", dialect)
+	}
+}
@@ -0,0 +1,44 @@
+package migrations
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/pressly/goose/v3"
+)
+
+func init() {
+	goose.AddMigrationContext(upDatabaseRowPropertiesIndex, downDatabaseRowPropertiesIndex)
+}
+
+func upDatabaseRowPropertiesIndex(ctx context.Context, tx *sql.Tx) error {
+	dialect, _ := ctx.Value("dbDialect").(string)
+	switch dialect {
+	case "sqlite":
+		// No equivalent: SQLite cannot index the content of a JSON document
+		return nil
+	case "postgres":
+		// Row equality filters are expressed as containment (properties @> ...)
+		// which jsonb_path_ops GIN indexes support
+		_, err := tx.ExecContext(ctx, `
+		CREATE INDEX IF NOT EXISTS idx_database_row_properties ON database_row USING GIN (properties jsonb_path_ops);
+		`)
+		return err
+	default:
+		return fmt.Errorf("unsupported dialect: %s", dialect)
+	}
+}
+
+func downDatabaseRowPropertiesIndex(ctx context.Context, tx *sql.Tx) error {
+	dialect, _ := ctx.Value("dbDialect").(string)
+	switch dialect {
+	case "sqlite":
+		return nil
+	case "postgres":
+		_, err := tx.ExecContext(ctx, `DROP INDEX IF EXISTS idx_database_row_properties;`)
+		return err
+	default:
+		return fmt.Errorf("unsupported dialect: %s", dialect)
+	}
+}
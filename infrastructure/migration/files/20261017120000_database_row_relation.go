@@ -0,0 +1,62 @@
+package migrations
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/pressly/goose/v3"
+)
+
+func init() {
+	goose.AddMigrationContext(upDatabaseRowRelation, downDatabaseRowRelation)
+}
+
+func upDatabaseRowRelation(ctx context.Context, tx *sql.Tx) error {
+	var query string
+	dialect, _ := ctx.Value("dbDialect").(string)
+	switch dialect {
+	case "sqlite":
+		query = `
+		CREATE TABLE IF NOT EXISTS database_row_relation (
+			id TEXT PRIMARY KEY,
+			property_id TEXT NOT NULL,
+			database_id TEXT NOT NULL,
+			row_id TEXT NOT NULL,
+			related_database_id TEXT NOT NULL,
+			related_row_id TEXT NOT NULL,
+			position INTEGER DEFAULT 0,
+			created_at TIMESTAMP NOT NULL,
+			FOREIGN KEY (row_id) REFERENCES database_row(id) ON DELETE CASCADE,
+			FOREIGN KEY (related_row_id) REFERENCES database_row(id) ON DELETE CASCADE,
+			UNIQUE (property_id, row_id, related_row_id)
+		);
+		CREATE INDEX IF NOT EXISTS idx_database_row_relation_related_row_id ON database_row_relation(related_row_id);
+		`
+	case "postgres":
+		query = `
+		CREATE TABLE IF NOT EXISTS database_row_relation (
+			id UUID PRIMARY KEY,
+			property_id TEXT NOT NULL,
+			database_id UUID NOT NULL,
+			row_id UUID NOT NULL REFERENCES database_row(id) ON DELETE CASCADE,
+			related_database_id UUID NOT NULL,
+			related_row_id UUID NOT NULL REFERENCES database_row(id) ON DELETE CASCADE,
+			position INTEGER DEFAULT 0,
+			created_at TIMESTAMPTZ NOT NULL,
+			UNIQUE (property_id, row_id, related_row_id)
+		);
+		CREATE INDEX IF NOT EXISTS idx_database_row_relation_related_row_id ON database_row_relation(related_row_id);
+		`
+	default:
+		return fmt.Errorf("unsupported dialect: %s", dialect)
+	}
+
+	_, err := tx.ExecContext(ctx, query)
+	return err
+}
+
+func downDatabaseRowRelation(ctx context.Context, tx *sql.Tx) error {
+	_, err := tx.ExecContext(ctx, `DROP TABLE IF EXISTS database_row_relation;`)
+	return err
+}
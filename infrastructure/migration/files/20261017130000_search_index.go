@@ -0,0 +1,84 @@
+package migrations
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/pressly/goose/v3"
+)
+
+func init() {
+	goose.AddMigrationContext(upSearchIndex, downSearchIndex)
+}
+
+// The SQLite FTS5 table over search_index is created by the search
+// persistence, as the fts5 module depends on how the binary is built.
+func upSearchIndex(ctx context.Context, tx *sql.Tx) error {
+	var query string
+	dialect, _ := ctx.Value("dbDialect").(string)
+	switch dialect {
+	case "sqlite":
+		query = `
+		CREATE TABLE IF NOT EXISTS search_index (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			resource_type TEXT NOT NULL,
+			resource_id TEXT NOT NULL,
+			space_id TEXT NOT NULL,
+			document_id TEXT,
+			database_id TEXT,
+			title TEXT NOT NULL DEFAULT '',
+			icon TEXT NOT NULL DEFAULT '',
+			content TEXT NOT NULL DEFAULT '',
+			blocks TEXT,
+			updated_at TIMESTAMP NOT NULL,
+			UNIQUE (resource_type, resource_id)
+		);
+		CREATE INDEX IF NOT EXISTS idx_search_index_space_id ON search_index(space_id);
+		`
+	case "postgres":
+		query = `
+		CREATE TABLE IF NOT EXISTS search_index (
+			id BIGSERIAL PRIMARY KEY,
+			resource_type TEXT NOT NULL,
+			resource_id UUID NOT NULL,
+			space_id UUID NOT NULL,
+			document_id UUID,
+			database_id UUID,
+			title TEXT NOT NULL DEFAULT '',
+			icon TEXT NOT NULL DEFAULT '',
+			content TEXT NOT NULL DEFAULT '',
+			blocks JSONB,
+			updated_at TIMESTAMPTZ NOT NULL,
+			search_vector tsvector GENERATED ALWAYS AS (
+				setweight(to_tsvector('simple', title), 'A') ||
+				setweight(to_tsvector('simple', content), 'B')
+			) STORED,
+			UNIQUE (resource_type, resource_id)
+		);
+		CREATE INDEX IF NOT EXISTS idx_search_index_space_id ON search_index(space_id);
+		CREATE INDEX IF NOT EXISTS idx_search_index_search_vector ON search_index USING GIN (search_vector);
+		`
+	default:
+		return fmt.Errorf("unsupported dialect: %s", dialect)
+	}
+
+	_, err := tx.ExecContext(ctx, query)
+	return err
+}
+
+func downSearchIndex(ctx context.Context, tx *sql.Tx) error {
+	dialect, _ := ctx.Value("dbDialect").(string)
+	if dialect == "sqlite" {
+		if _, err := tx.ExecContext(ctx, `
+		DROP TRIGGER IF EXISTS search_index_fts_insert;
+		DROP TRIGGER IF EXISTS search_index_fts_delete;
+		DROP TRIGGER IF EXISTS search_index_fts_update;
+		DROP TABLE IF EXISTS search_index_fts;
+		`); err != nil {
+			return err
+		}
+	}
+	_, err := tx.ExecContext(ctx, `DROP TABLE IF EXISTS search_index;`)
+	return err
+}
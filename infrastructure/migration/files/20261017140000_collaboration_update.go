@@ -0,0 +1,54 @@
+package migrations
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/pressly/goose/v3"
+)
+
+func init() {
+	goose.AddMigrationContext(upCollaborationUpdate, downCollaborationUpdate)
+}
+
+func upCollaborationUpdate(ctx context.Context, tx *sql.Tx) error {
+	var query string
+	dialect, _ := ctx.Value("dbDialect").(string)
+	switch dialect {
+	case "sqlite":
+		query = `
+		CREATE TABLE IF NOT EXISTS collaboration_update (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			room_id TEXT NOT NULL,
+			data BLOB NOT NULL,
+			user_id TEXT NOT NULL DEFAULT '',
+			compacted BOOLEAN NOT NULL DEFAULT FALSE,
+			created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+		);
+		CREATE INDEX IF NOT EXISTS idx_collaboration_update_room_id ON collaboration_update(room_id, id);
+		`
+	case "postgres":
+		query = `
+		CREATE TABLE IF NOT EXISTS collaboration_update (
+			id BIGSERIAL PRIMARY KEY,
+			room_id TEXT NOT NULL,
+			data BYTEA NOT NULL,
+			user_id TEXT NOT NULL DEFAULT '',
+			compacted BOOLEAN NOT NULL DEFAULT FALSE,
+			created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
+		);
+		CREATE INDEX IF NOT EXISTS idx_collaboration_update_room_id ON collaboration_update(room_id, id);
+		`
+	default:
+		return fmt.Errorf("unsupported dialect: %s", dialect)
+	}
+
+	_, err := tx.ExecContext(ctx, query)
+	return err
+}
+
+func downCollaborationUpdate(ctx context.Context, tx *sql.Tx) error {
+	_, err := tx.ExecContext(ctx, `DROP TABLE IF EXISTS collaboration_update;`)
+	return err
+}
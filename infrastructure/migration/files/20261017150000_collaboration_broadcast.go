@@ -0,0 +1,40 @@
+package migrations
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/pressly/goose/v3"
+)
+
+func init() {
+	goose.AddMigrationContext(upCollaborationBroadcast, downCollaborationBroadcast)
+}
+
+// Messages too large for NOTIFY, shared between the nodes of a PostgreSQL
+// deployment. SQLite deployments are single node and do not need it.
+func upCollaborationBroadcast(ctx context.Context, tx *sql.Tx) error {
+	dialect, _ := ctx.Value("dbDialect").(string)
+	switch dialect {
+	case "sqlite":
+		return nil
+	case "postgres":
+		_, err := tx.ExecContext(ctx, `
+		CREATE TABLE IF NOT EXISTS collaboration_broadcast (
+			id BIGSERIAL PRIMARY KEY,
+			data BYTEA NOT NULL,
+			created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
+		);
+		CREATE INDEX IF NOT EXISTS idx_collaboration_broadcast_created_at ON collaboration_broadcast(created_at);
+		`)
+		return err
+	default:
+		return fmt.Errorf("unsupported dialect: %s", dialect)
+	}
+}
+
+func downCollaborationBroadcast(ctx context.Context, tx *sql.Tx) error {
+	_, err := tx.ExecContext(ctx, `DROP TABLE IF EXISTS collaboration_broadcast;`)
+	return err
+}
@@ -0,0 +1,66 @@
+package migrations
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/pressly/goose/v3"
+)
+
+func init() {
+	goose.AddMigrationContext(upAttachment, downAttachment)
+}
+
+func upAttachment(ctx context.Context, tx *sql.Tx) error {
+	var query string
+	dialect, _ := ctx.Value("dbDialect").(string)
+	switch dialect {
+	case "sqlite":
+		query = `
+		CREATE TABLE IF NOT EXISTS attachment (
+			id TEXT PRIMARY KEY,
+			space_id TEXT NOT NULL,
+			resource_type TEXT NOT NULL CHECK(resource_type IN ('document', 'drawing', 'database')),
+			resource_id TEXT NOT NULL,
+			name TEXT NOT NULL,
+			content_type TEXT NOT NULL DEFAULT 'application/octet-stream',
+			size INTEGER NOT NULL DEFAULT 0,
+			storage_key TEXT NOT NULL,
+			created_by TEXT NOT NULL,
+			created_at TIMESTAMP NOT NULL,
+			FOREIGN KEY (space_id) REFERENCES space(id) ON DELETE CASCADE,
+			FOREIGN KEY (created_by) REFERENCES user(id)
+		);
+		CREATE INDEX IF NOT EXISTS idx_attachment_resource ON attachment(resource_type, resource_id);
+		CREATE INDEX IF NOT EXISTS idx_attachment_space_id ON attachment(space_id);
+		`
+	case "postgres":
+		query = `
+		CREATE TABLE IF NOT EXISTS attachment (
+			id UUID PRIMARY KEY,
+			space_id UUID NOT NULL REFERENCES space(id) ON DELETE CASCADE,
+			resource_type TEXT NOT NULL CHECK(resource_type IN ('document', 'drawing', 'database')),
+			resource_id UUID NOT NULL,
+			name TEXT NOT NULL,
+			content_type TEXT NOT NULL DEFAULT 'application/octet-stream',
+			size BIGINT NOT NULL DEFAULT 0,
+			storage_key TEXT NOT NULL,
+			created_by UUID NOT NULL REFERENCES "user"(id),
+			created_at TIMESTAMPTZ NOT NULL
+		);
+		CREATE INDEX IF NOT EXISTS idx_attachment_resource ON attachment(resource_type, resource_id);
+		CREATE INDEX IF NOT EXISTS idx_attachment_space_id ON attachment(space_id);
+		`
+	default:
+		return fmt.Errorf("unsupported dialect: %s", dialect)
+	}
+
+	_, err := tx.ExecContext(ctx, query)
+	return err
+}
+
+func downAttachment(ctx context.Context, tx *sql.Tx) error {
+	_, err := tx.ExecContext(ctx, `DROP TABLE IF EXISTS attachment;`)
+	return err
+}
@@ -0,0 +1,43 @@
+package migrations
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/pressly/goose/v3"
+)
+
+func init() {
+	goose.AddMigrationContext(upDocumentBreakInheritance, downDocumentBreakInheritance)
+}
+
+func upDocumentBreakInheritance(ctx context.Context, tx *sql.Tx) error {
+	var query string
+	dialect, _ := ctx.Value("dbDialect").(string)
+	switch dialect {
+	case "sqlite":
+		query = `ALTER TABLE document ADD COLUMN break_inheritance BOOLEAN NOT NULL DEFAULT 0;`
+	case "postgres":
+		query = `ALTER TABLE document ADD COLUMN break_inheritance BOOLEAN NOT NULL DEFAULT FALSE;`
+	default:
+		return fmt.Errorf("unsupported dialect: %s", dialect)
+	}
+
+	_, err := tx.ExecContext(ctx, query)
+	return err
+}
+
+func downDocumentBreakInheritance(ctx context.Context, tx *sql.Tx) error {
+	dialect, _ := ctx.Value("dbDialect").(string)
+	switch dialect {
+	case "sqlite":
+		// SQLite doesn't support DROP COLUMN before 3.35.0
+		return nil
+	case "postgres":
+		_, err := tx.ExecContext(ctx, `ALTER TABLE document DROP COLUMN IF EXISTS break_inheritance;`)
+		return err
+	default:
+		return fmt.Errorf("unsupported dialect: %s", dialect)
+	}
+}
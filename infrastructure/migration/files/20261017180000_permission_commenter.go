@@ -0,0 +1,82 @@
+package migrations
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/pressly/goose/v3"
+)
+
+func init() {
+	goose.AddMigrationContext(upPermissionCommenter, downPermissionCommenter)
+}
+
+// permissionRoleCheck rebuilds the check of the permission roles. SQLite
+// can't alter a check constraint, so the table is copied into a new one.
+func permissionRoleCheck(ctx context.Context, tx *sql.Tx, roles string) error {
+	var query string
+	dialect, _ := ctx.Value("dbDialect").(string)
+	switch dialect {
+	case "sqlite":
+		query = `
+		CREATE TABLE permission_new (
+			id TEXT PRIMARY KEY,
+			type TEXT CHECK(type IN ('space', 'document', 'database', 'drawing')) NOT NULL,
+			space_id TEXT,
+			document_id TEXT,
+			database_id TEXT,
+			drawing_id TEXT,
+			user_id TEXT,
+			group_id TEXT,
+			role TEXT CHECK(role IN ` + roles + `) NOT NULL,
+			created_at TIMESTAMP NOT NULL,
+			updated_at TIMESTAMP NOT NULL,
+			deleted_at TIMESTAMP,
+			FOREIGN KEY (space_id) REFERENCES space(id) ON DELETE CASCADE,
+			FOREIGN KEY (document_id) REFERENCES document(id) ON DELETE CASCADE,
+			FOREIGN KEY (database_id) REFERENCES database(id) ON DELETE CASCADE,
+			FOREIGN KEY (drawing_id) REFERENCES drawing(id) ON DELETE CASCADE,
+			FOREIGN KEY (user_id) REFERENCES user(id) ON DELETE CASCADE,
+			FOREIGN KEY (group_id) REFERENCES "group"(id) ON DELETE CASCADE,
+			CHECK((user_id IS NOT NULL AND group_id IS NULL) OR (user_id IS NULL AND group_id IS NOT NULL))
+		);
+		INSERT INTO permission_new (id, type, space_id, document_id, database_id, drawing_id, user_id, group_id, role, created_at, updated_at, deleted_at)
+			SELECT id, type, space_id, document_id, database_id, drawing_id, user_id, group_id, role, created_at, updated_at, deleted_at FROM permission;
+		DROP TABLE permission;
+		ALTER TABLE permission_new RENAME TO permission;
+		CREATE INDEX IF NOT EXISTS idx_permission_type ON permission(type);
+		CREATE INDEX IF NOT EXISTS idx_permission_space_id ON permission(space_id) WHERE space_id IS NOT NULL;
+		CREATE INDEX IF NOT EXISTS idx_permission_document_id ON permission(document_id) WHERE document_id IS NOT NULL;
+		CREATE INDEX IF NOT EXISTS idx_permission_database_id ON permission(database_id) WHERE database_id IS NOT NULL;
+		CREATE INDEX IF NOT EXISTS idx_permission_drawing_id ON permission(drawing_id) WHERE drawing_id IS NOT NULL;
+		CREATE INDEX IF NOT EXISTS idx_permission_user_id ON permission(user_id) WHERE user_id IS NOT NULL;
+		CREATE INDEX IF NOT EXISTS idx_permission_group_id ON permission(group_id) WHERE group_id IS NOT NULL;
+		CREATE INDEX IF NOT EXISTS idx_permission_deleted_at ON permission(deleted_at);
+		CREATE UNIQUE INDEX IF NOT EXISTS idx_permission_resource_user ON permission(type, space_id, document_id, database_id, drawing_id, user_id) WHERE user_id IS NOT NULL AND deleted_at IS NULL;
+		CREATE UNIQUE INDEX IF NOT EXISTS idx_permission_resource_group ON permission(type, space_id, document_id, database_id, drawing_id, group_id) WHERE group_id IS NOT NULL AND deleted_at IS NULL;
+		`
+	case "postgres":
+		query = `
+		ALTER TABLE permission DROP CONSTRAINT IF EXISTS permission_role_check;
+		ALTER TABLE permission ADD CONSTRAINT permission_role_check CHECK(role IN ` + roles + `);
+		`
+	default:
+		return fmt.Errorf("unsupported dialect: %s", dialect)
+	}
+
+	_, err := tx.ExecContext(ctx, query)
+	return err
+}
+
+func upPermissionCommenter(ctx context.Context, tx *sql.Tx) error {
+	return permissionRoleCheck(ctx, tx, "('owner', 'admin', 'editor', 'commenter', 'viewer', 'denied')")
+}
+
+func downPermissionCommenter(ctx context.Context, tx *sql.Tx) error {
+	// The commenters fall back to viewers
+	if _, err := tx.ExecContext(ctx, `UPDATE permission SET role = 'viewer' WHERE role = 'commenter';`); err != nil {
+		return err
+	}
+	return permissionRoleCheck(ctx, tx, "('owner', 'admin', 'editor', 'viewer', 'denied')")
+}
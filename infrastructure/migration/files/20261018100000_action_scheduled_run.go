@@ -0,0 +1,43 @@
+package migrations
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/pressly/goose/v3"
+)
+
+func init() {
+	goose.AddMigrationContext(upActionScheduledRun, downActionScheduledRun)
+}
+
+func upActionScheduledRun(ctx context.Context, tx *sql.Tx) error {
+	var query string
+	dialect, _ := ctx.Value("dbDialect").(string)
+	switch dialect {
+	case "sqlite":
+		query = `ALTER TABLE action ADD COLUMN scheduled_run_at TIMESTAMP;`
+	case "postgres":
+		query = `ALTER TABLE action ADD COLUMN scheduled_run_at TIMESTAMPTZ;`
+	default:
+		return fmt.Errorf("unsupported dialect: %s", dialect)
+	}
+
+	_, err := tx.ExecContext(ctx, query)
+	return err
+}
+
+func downActionScheduledRun(ctx context.Context, tx *sql.Tx) error {
+	dialect, _ := ctx.Value("dbDialect").(string)
+	switch dialect {
+	case "sqlite":
+		// SQLite doesn't support DROP COLUMN before 3.35.0
+		return nil
+	case "postgres":
+		_, err := tx.ExecContext(ctx, `ALTER TABLE action DROP COLUMN IF EXISTS scheduled_run_at;`)
+		return err
+	default:
+		return fmt.Errorf("unsupported dialect: %s", dialect)
+	}
+}
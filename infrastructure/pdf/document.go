@@ -0,0 +1,274 @@
+// Package pdf writes PDF documents with text, shapes, images and links. It
+// uses the standard fonts of the PDF readers, so the documents support the
+// Latin-1 characters only.
+package pdf
+
+import (
+	"bytes"
+	"compress/zlib"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Size of an A4 page, in points
+const (
+	PageWidth  = 595.28
+	PageHeight = 841.89
+)
+
+// Color is a RGB color, with components from 0 to 1
+type Color struct {
+	R, G, B float64
+}
+
+var Black = Color{}
+
+// Document is a PDF document. The coordinates of its pages are in points,
+// from the top left corner of the page.
+type Document struct {
+	title  string
+	pages  []*Page
+	images []*Image
+}
+
+func New(title string) *Document {
+	return &Document{title: title}
+}
+
+// AddPage appends a page to the document
+func (d *Document) AddPage() *Page {
+	page := &Page{}
+	d.pages = append(d.pages, page)
+	return page
+}
+
+// InsertPage inserts a page before the page at index, to add pages whose
+// content depends on the following pages such as a table of contents.
+func (d *Document) InsertPage(index int) *Page {
+	page := &Page{}
+	d.pages = append(d.pages[:index], append([]*Page{page}, d.pages[index:]...)...)
+	return page
+}
+
+// Pages returns the pages of the document, in order
+func (d *Document) Pages() []*Page {
+	return d.pages
+}
+
+// Page is a page of a document, drawn with its methods
+type Page struct {
+	content bytes.Buffer
+	images  []*Image
+	links   []link
+}
+
+type link struct {
+	x, y, w, h float64
+	uri        string
+	target     *Page
+	top        float64
+}
+
+// Text draws text with its baseline at y
+func (p *Page) Text(x, y float64, font Font, size float64, color Color, text string) {
+	fmt.Fprintf(&p.content, "BT %s rg /F%d %s Tf %s %s Td (%s) Tj ET\n",
+		color.operands(), int(font)+1, number(size), number(x), number(PageHeight-y), escape(encode(text)))
+}
+
+// Rect fills a rectangle
+func (p *Page) Rect(x, y, w, h float64, color Color) {
+	fmt.Fprintf(&p.content, "%s rg %s %s %s %s re f\n", color.operands(), number(x), number(PageHeight-y-h), number(w), number(h))
+}
+
+// StrokeRect draws the border of a rectangle
+func (p *Page) StrokeRect(x, y, w, h, width float64, color Color) {
+	fmt.Fprintf(&p.content, "%s RG %s w %s %s %s %s re S\n", color.operands(), number(width), number(x), number(PageHeight-y-h), number(w), number(h))
+}
+
+// Line draws a line
+func (p *Page) Line(x1, y1, x2, y2, width float64, color Color) {
+	fmt.Fprintf(&p.content, "%s RG %s w %s %s m %s %s l S\n", color.operands(), number(width), number(x1), number(PageHeight-y1), number(x2), number(PageHeight-y2))
+}
+
+// Image draws an image of the document in a rectangle
+func (p *Page) Image(image *Image, x, y, w, h float64) {
+	found := false
+	for _, i := range p.images {
+		found = found || i == image
+	}
+	if !found {
+		p.images = append(p.images, image)
+	}
+	fmt.Fprintf(&p.content, "q %s 0 0 %s %s %s cm /Im%d Do Q\n", number(w), number(h), number(x), number(PageHeight-y-h), image.index+1)
+}
+
+// LinkURI makes a rectangle of the page open an url
+func (p *Page) LinkURI(x, y, w, h float64, uri string) {
+	p.links = append(p.links, link{x: x, y: y, w: w, h: h, uri: uri})
+}
+
+// LinkPage makes a rectangle of the page go to another page of the document,
+// scrolled to top.
+func (p *Page) LinkPage(x, y, w, h float64, target *Page, top float64) {
+	p.links = append(p.links, link{x: x, y: y, w: w, h: h, target: target, top: top})
+}
+
+// Write writes the document
+func (d *Document) Write(w io.Writer) error {
+	if len(d.pages) == 0 {
+		d.AddPage()
+	}
+
+	// Objects: catalog, page tree, info, fonts, images, then each page, its
+	// content and its links.
+	const catalog, pageTree, info, firstFont = 1, 2, 3, 4
+	firstImage := firstFont + len(fontNames)
+	pageObjects := make(map[*Page]int, len(d.pages))
+	next := firstImage + len(d.images)
+	for _, page := range d.pages {
+		pageObjects[page] = next
+		next += 2 + len(page.links)
+	}
+
+	out := &writer{w: w}
+	out.printf("%%PDF-1.4\n%%\xe2\xe3\xcf\xd3\n")
+
+	out.object(catalog, fmt.Sprintf("<< /Type /Catalog /Pages %d 0 R >>", pageTree))
+	kids := make([]string, len(d.pages))
+	for i, page := range d.pages {
+		kids[i] = fmt.Sprintf("%d 0 R", pageObjects[page])
+	}
+	out.object(pageTree, fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(kids, " "), len(d.pages)))
+	out.object(info, fmt.Sprintf("<< /Title (%s) /Producer (Nexo) >>", escape(encode(d.title))))
+	for i, name := range fontNames {
+		out.object(firstFont+i, fmt.Sprintf("<< /Type /Font /Subtype /Type1 /BaseFont /%s /Encoding /WinAnsiEncoding >>", name))
+	}
+	for i, image := range d.images {
+		out.stream(firstImage+i, fmt.Sprintf("/Type /XObject /Subtype /Image /Width %d /Height %d /ColorSpace /%s /BitsPerComponent 8 /Filter /%s",
+			image.Width, image.Height, image.colorSpace, image.filter), image.data)
+	}
+
+	fonts := make([]string, len(fontNames))
+	for i := range fontNames {
+		fonts[i] = fmt.Sprintf("/F%d %d 0 R", i+1, firstFont+i)
+	}
+	for _, page := range d.pages {
+		object := pageObjects[page]
+
+		var images []string
+		for _, image := range page.images {
+			images = append(images, fmt.Sprintf("/Im%d %d 0 R", image.index+1, firstImage+image.index))
+		}
+		var annotations []string
+		for i := range page.links {
+			annotations = append(annotations, fmt.Sprintf("%d 0 R", object+2+i))
+		}
+
+		out.object(object, fmt.Sprintf("<< /Type /Page /Parent %d 0 R /MediaBox [0 0 %s %s] /Resources << /Font << %s >> /XObject << %s >> >> /Contents %d 0 R /Annots [%s] >>",
+			pageTree, number(PageWidth), number(PageHeight), strings.Join(fonts, " "), strings.Join(images, " "), object+1, strings.Join(annotations, " ")))
+
+		var content bytes.Buffer
+		compressor := zlib.NewWriter(&content)
+		if _, err := compressor.Write(page.content.Bytes()); err != nil {
+			return err
+		}
+		if err := compressor.Close(); err != nil {
+			return err
+		}
+		out.stream(object+1, "/Filter /FlateDecode", content.Bytes())
+
+		for i, l := range page.links {
+			rect := fmt.Sprintf("[%s %s %s %s]", number(l.x), number(PageHeight-l.y-l.h), number(l.x+l.w), number(PageHeight-l.y))
+			action := fmt.Sprintf("/A << /S /URI /URI (%s) >>", escape([]byte(l.uri)))
+			if l.target != nil {
+				action = fmt.Sprintf("/Dest [%d 0 R /XYZ 0 %s 0]", pageObjects[l.target], number(PageHeight-l.top))
+			}
+			out.object(object+2+i, fmt.Sprintf("<< /Type /Annot /Subtype /Link /Rect %s /Border [0 0 0] %s >>", rect, action))
+		}
+	}
+
+	// Cross-reference table, with the offsets of the objects by number
+	start := out.offset
+	out.printf("xref\n0 %d\n0000000000 65535 f \n", len(out.offsets)+1)
+	for i := 1; i <= len(out.offsets); i++ {
+		out.printf("%010d 00000 n \n", out.offsets[i])
+	}
+	out.printf("trailer\n<< /Size %d /Root %d 0 R /Info %d 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(out.offsets)+1, catalog, info, start)
+	return out.err
+}
+
+// writer writes the objects of a document and records their offsets
+type writer struct {
+	w       io.Writer
+	offset  int
+	offsets map[int]int
+	err     error
+}
+
+func (w *writer) printf(format string, args ...any) {
+	if w.err != nil {
+		return
+	}
+	n, err := fmt.Fprintf(w.w, format, args...)
+	w.offset += n
+	w.err = err
+}
+
+func (w *writer) write(data []byte) {
+	if w.err != nil {
+		return
+	}
+	n, err := w.w.Write(data)
+	w.offset += n
+	w.err = err
+}
+
+func (w *writer) object(number int, value string) {
+	if w.offsets == nil {
+		w.offsets = make(map[int]int)
+	}
+	w.offsets[number] = w.offset
+	w.printf("%d 0 obj\n%s\nendobj\n", number, value)
+}
+
+func (w *writer) stream(number int, dictionary string, data []byte) {
+	if w.offsets == nil {
+		w.offsets = make(map[int]int)
+	}
+	w.offsets[number] = w.offset
+	w.printf("%d 0 obj\n<< %s /Length %d >>\nstream\n", number, dictionary, len(data))
+	w.write(data)
+	w.printf("\nendstream\nendobj\n")
+}
+
+func (c Color) operands() string {
+	return number(c.R) + " " + number(c.G) + " " + number(c.B)
+}
+
+// number formats a number without exponent and useless decimals
+func number(value float64) string {
+	s := fmt.Sprintf("%.3f", value)
+	s = strings.TrimRight(strings.TrimRight(s, "0"), ".")
+	if s == "-0" {
+		return "0"
+	}
+	return s
+}
+
+// escape escapes the delimiters of a string literal
+func escape(text []byte) string {
+	var b strings.Builder
+	for _, c := range text {
+		switch c {
+		case '(', ')', '\\':
+			b.WriteByte('\\')
+			b.WriteByte(c)
+		case '\r', '\n':
+			b.WriteByte(' ')
+		default:
+			b.WriteByte(c)
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,119 @@
+package pdf
+
+// Font is one of the standard fonts every PDF reader provides, so the
+// documents do not embed any font file.
+type Font int
+
+const (
+	Helvetica Font = iota
+	HelveticaBold
+	HelveticaOblique
+	HelveticaBoldOblique
+	Courier
+	CourierBold
+)
+
+var fontNames = [...]string{
+	Helvetica:            "Helvetica",
+	HelveticaBold:        "Helvetica-Bold",
+	HelveticaOblique:     "Helvetica-Oblique",
+	HelveticaBoldOblique: "Helvetica-BoldOblique",
+	Courier:              "Courier",
+	CourierBold:          "Courier-Bold",
+}
+
+// Widths of the characters of the WinAnsi encoding, in thousandths of the
+// font size, from the metrics of the standard fonts.
+var helveticaWidths, helveticaBoldWidths [256]uint16
+
+func init() {
+	fill := func(widths *[256]uint16, from int, values ...uint16) {
+		copy(widths[from:], values)
+	}
+
+	// ' ' to '~'
+	fill(&helveticaWidths, 32,
+		278, 278, 355, 556, 556, 889, 667, 191, 333, 333, 389, 584, 278, 333, 278, 278,
+		556, 556, 556, 556, 556, 556, 556, 556, 556, 556, 278, 278, 584, 584, 584, 556,
+		1015, 667, 667, 722, 722, 667, 611, 778, 722, 278, 500, 667, 556, 833, 722, 778,
+		667, 778, 722, 667, 611, 722, 667, 944, 667, 667, 611, 278, 278, 278, 469, 556,
+		333, 556, 556, 500, 556, 556, 278, 556, 556, 222, 222, 500, 222, 833, 556, 556,
+		556, 556, 333, 500, 278, 556, 500, 722, 500, 500, 500, 334, 260, 334, 584)
+	fill(&helveticaBoldWidths, 32,
+		278, 333, 474, 556, 556, 889, 722, 238, 333, 333, 389, 584, 278, 333, 278, 278,
+		556, 556, 556, 556, 556, 556, 556, 556, 556, 556, 333, 333, 584, 584, 584, 611,
+		975, 722, 722, 722, 722, 667, 611, 778, 722, 278, 556, 722, 611, 833, 722, 778,
+		667, 778, 722, 667, 611, 722, 667, 944, 667, 667, 611, 333, 278, 333, 584, 556,
+		333, 556, 611, 556, 611, 556, 333, 611, 611, 278, 278, 556, 278, 889, 611, 611,
+		611, 611, 389, 556, 333, 611, 556, 778, 556, 556, 500, 389, 280, 389, 584)
+
+	// 0x80 to 0x9F
+	fill(&helveticaWidths, 0x80,
+		556, 0, 222, 556, 333, 1000, 556, 556, 333, 1000, 667, 333, 1000, 0, 611, 0,
+		0, 222, 222, 333, 333, 350, 556, 1000, 333, 1000, 500, 333, 944, 0, 500, 667)
+	fill(&helveticaBoldWidths, 0x80,
+		556, 0, 278, 556, 500, 1000, 556, 556, 333, 1000, 667, 333, 1000, 0, 611, 0,
+		0, 278, 278, 500, 500, 350, 556, 1000, 333, 1000, 556, 333, 944, 0, 500, 667)
+
+	// 0xA0 to 0xFF, the Latin-1 characters
+	fill(&helveticaWidths, 0xA0,
+		278, 333, 556, 556, 556, 556, 260, 556, 333, 737, 370, 556, 584, 333, 737, 333,
+		400, 584, 333, 333, 333, 556, 537, 278, 333, 333, 365, 556, 834, 834, 834, 611,
+		667, 667, 667, 667, 667, 667, 1000, 722, 667, 667, 667, 667, 278, 278, 278, 278,
+		722, 722, 778, 778, 778, 778, 778, 584, 778, 722, 722, 722, 722, 667, 667, 611,
+		556, 556, 556, 556, 556, 556, 889, 500, 556, 556, 556, 556, 278, 278, 278, 278,
+		556, 556, 556, 556, 556, 556, 556, 584, 611, 556, 556, 556, 556, 500, 556, 500)
+	fill(&helveticaBoldWidths, 0xA0,
+		278, 333, 556, 556, 556, 556, 280, 556, 333, 737, 370, 556, 584, 333, 737, 333,
+		400, 584, 333, 333, 333, 611, 556, 278, 333, 333, 365, 556, 834, 834, 834, 611,
+		722, 722, 722, 722, 722, 722, 1000, 722, 667, 667, 667, 667, 278, 278, 278, 278,
+		722, 722, 778, 778, 778, 778, 778, 584, 778, 722, 722, 722, 722, 667, 667, 611,
+		556, 556, 556, 556, 556, 556, 889, 556, 556, 556, 556, 556, 278, 278, 278, 278,
+		611, 611, 611, 611, 611, 611, 611, 584, 611, 611, 611, 611, 611, 556, 611, 556)
+}
+
+// winAnsi maps the characters of the 0x80 to 0x9F range of the WinAnsi
+// encoding, the other characters below 0x100 are the Latin-1 ones.
+var winAnsi = map[rune]byte{
+	'€': 0x80, '‚': 0x82, 'ƒ': 0x83, '„': 0x84, '…': 0x85, '†': 0x86, '‡': 0x87, 'ˆ': 0x88,
+	'‰': 0x89, 'Š': 0x8A, '‹': 0x8B, 'Œ': 0x8C, 'Ž': 0x8E, '‘': 0x91, '’': 0x92, '“': 0x93,
+	'”': 0x94, '•': 0x95, '–': 0x96, '—': 0x97, '˜': 0x98, '™': 0x99, 'š': 0x9A, '›': 0x9B,
+	'œ': 0x9C, 'ž': 0x9E, 'Ÿ': 0x9F,
+}
+
+// encode converts text to the WinAnsi encoding of the standard fonts, the
+// characters it does not have are replaced by a question mark.
+func encode(text string) []byte {
+	encoded := make([]byte, 0, len(text))
+	for _, r := range text {
+		switch {
+		case r == '\t':
+			encoded = append(encoded, ' ')
+		case r >= 0x20 && r < 0x7F, r >= 0xA0 && r <= 0xFF:
+			encoded = append(encoded, byte(r))
+		case winAnsi[r] != 0:
+			encoded = append(encoded, winAnsi[r])
+		case r < 0x20:
+		default:
+			encoded = append(encoded, '?')
+		}
+	}
+	return encoded
+}
+
+// TextWidth returns the width of text in points
+func TextWidth(font Font, size float64, text string) float64 {
+	if font == Courier || font == CourierBold {
+		return float64(len(encode(text))) * 600 * size / 1000
+	}
+	widths := &helveticaWidths
+	if font == HelveticaBold || font == HelveticaBoldOblique {
+		widths = &helveticaBoldWidths
+	}
+
+	total := 0
+	for _, c := range encode(text) {
+		total += int(widths[c])
+	}
+	return float64(total) * size / 1000
+}
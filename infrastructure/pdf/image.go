@@ -0,0 +1,81 @@
+package pdf
+
+import (
+	"bytes"
+	"compress/zlib"
+	"fmt"
+	"image"
+	"image/color"
+	_ "image/gif"
+	_ "image/jpeg"
+	_ "image/png"
+)
+
+// MaxImagePixels limits the size of the images that are decoded to be
+// converted, as their samples are held in memory
+const MaxImagePixels = 4096 * 4096
+
+// Image is an image added to a document, drawn on its pages
+type Image struct {
+	Width, Height int
+
+	index      int
+	colorSpace string
+	filter     string
+	data       []byte
+}
+
+// AddImage adds a JPEG, PNG or GIF image to the document. The JPEG images
+// are embedded as is, the others are converted to RGB on a white background
+// and cannot be larger than MaxImagePixels.
+func (d *Document) AddImage(data []byte) (*Image, error) {
+	config, format, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read image: %w", err)
+	}
+
+	img := &Image{Width: config.Width, Height: config.Height, index: len(d.images)}
+	switch {
+	case format == "jpeg" && config.ColorModel == color.GrayModel:
+		img.colorSpace, img.filter, img.data = "DeviceGray", "DCTDecode", data
+	case format == "jpeg" && config.ColorModel == color.YCbCrModel:
+		img.colorSpace, img.filter, img.data = "DeviceRGB", "DCTDecode", data
+	case int64(config.Width)*int64(config.Height) > MaxImagePixels:
+		return nil, fmt.Errorf("image of %dx%d pixels is too large", config.Width, config.Height)
+	default:
+		decoded, _, err := image.Decode(bytes.NewReader(data))
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode image: %w", err)
+		}
+		img.colorSpace, img.filter = "DeviceRGB", "FlateDecode"
+		if img.data, err = rgb(decoded); err != nil {
+			return nil, err
+		}
+	}
+
+	d.images = append(d.images, img)
+	return img, nil
+}
+
+// rgb compresses the RGB samples of an image, blended on white
+func rgb(img image.Image) ([]byte, error) {
+	bounds := img.Bounds()
+	samples := make([]byte, 0, bounds.Dx()*bounds.Dy()*3)
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			r, g, b, a := img.At(x, y).RGBA()
+			white := 0xffff - a
+			samples = append(samples, byte((r+white)>>8), byte((g+white)>>8), byte((b+white)>>8))
+		}
+	}
+
+	var compressed bytes.Buffer
+	w := zlib.NewWriter(&compressed)
+	if _, err := w.Write(samples); err != nil {
+		return nil, err
+	}
+	if err := w.Close(); err != nil {
+		return nil, err
+	}
+	return compressed.Bytes(), nil
+}
@@ -104,6 +104,16 @@ func (p *actionPers) UpdateLastRun(id string) error {
 		Update("last_run_at", &now).Error
 }
 
+func (p *actionPers) ClaimScheduledRun(id string, runAt time.Time) (bool, error) {
+	result := p.db.Model(&domain.Action{}).
+		Where("id = ? AND (scheduled_run_at IS NULL OR scheduled_run_at < ?)", id, runAt).
+		Update("scheduled_run_at", runAt)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
+
 // ActionRun persistence
 type actionRunPers struct {
 	db *gorm.DB
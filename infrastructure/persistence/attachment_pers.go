@@ -0,0 +1,50 @@
+package persistence
+
+import (
+	"errors"
+
+	"github.com/labbs/nexo/domain"
+	"github.com/labbs/nexo/infrastructure/helpers/apperrors"
+	"gorm.io/gorm"
+)
+
+type attachmentPers struct {
+	db *gorm.DB
+}
+
+func NewAttachmentPers(db *gorm.DB) *attachmentPers {
+	return &attachmentPers{db: db}
+}
+
+func (p *attachmentPers) Create(attachment *domain.Attachment) error {
+	return p.db.Omit("User").Create(attachment).Error
+}
+
+func (p *attachmentPers) GetById(id string) (*domain.Attachment, error) {
+	var attachment domain.Attachment
+	err := p.db.
+		Preload("User").
+		Where("id = ?", id).
+		First(&attachment).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, apperrors.ErrNotFound
+		}
+		return nil, err
+	}
+	return &attachment, nil
+}
+
+func (p *attachmentPers) GetByResource(resourceType domain.AttachmentResourceType, resourceId string) ([]domain.Attachment, error) {
+	var attachments []domain.Attachment
+	err := p.db.
+		Preload("User").
+		Where("resource_type = ? AND resource_id = ?", resourceType, resourceId).
+		Order("created_at ASC").
+		Find(&attachments).Error
+	return attachments, err
+}
+
+func (p *attachmentPers) Delete(id string) error {
+	return p.db.Where("id = ?", id).Delete(&domain.Attachment{}).Error
+}
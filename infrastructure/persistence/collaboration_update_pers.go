@@ -0,0 +1,60 @@
+package persistence
+
+import (
+	"github.com/labbs/nexo/domain"
+	"gorm.io/gorm"
+)
+
+type collaborationUpdatePers struct {
+	db *gorm.DB
+}
+
+func NewCollaborationUpdatePers(db *gorm.DB) *collaborationUpdatePers {
+	return &collaborationUpdatePers{db: db}
+}
+
+func (p *collaborationUpdatePers) Append(update *domain.CollaborationUpdate) error {
+	return p.db.Omit("id").Create(update).Error
+}
+
+func (p *collaborationUpdatePers) GetByRoomId(roomId string) ([]domain.CollaborationUpdate, error) {
+	var updates []domain.CollaborationUpdate
+	err := p.db.
+		Where("room_id = ?", roomId).
+		Order("id ASC").
+		Find(&updates).Error
+	return updates, err
+}
+
+func (p *collaborationUpdatePers) Compact(roomId string, lastId int64, updates []domain.CollaborationUpdate) error {
+	return p.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.
+			Where("room_id = ? AND id <= ?", roomId, lastId).
+			Delete(&domain.CollaborationUpdate{}).Error; err != nil {
+			return err
+		}
+		for i := range updates {
+			updates[i].RoomId = roomId
+			if err := tx.Omit("id").Create(&updates[i]).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func (p *collaborationUpdatePers) GetRoomsToCompact() ([]string, error) {
+	var roomIds []string
+	err := p.db.
+		Model(&domain.CollaborationUpdate{}).
+		Where("compacted = ?", false).
+		Distinct().
+		Pluck("room_id", &roomIds).Error
+	return roomIds, err
+}
+
+func (p *collaborationUpdatePers) DeleteByRoomId(roomId string) error {
+	return p.db.
+		Where("room_id = ?", roomId).
+		Delete(&domain.CollaborationUpdate{}).Error
+}
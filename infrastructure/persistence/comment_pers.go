@@ -26,6 +26,7 @@ func (p *commentPers) GetById(commentId string) (*domain.Comment, error) {
 	err := p.db.
 		Preload("User").
 		Preload("Parent").
+		Preload("Document").
 		Where("id = ?", commentId).
 		First(&comment).Error
 	if err != nil {
@@ -69,6 +69,17 @@ func (p *databasePers) GetByDocumentId(documentId string) ([]domain.Database, er
 	return databases, nil
 }
 
+func (p *databasePers) GetWithFormulas() ([]domain.Database, error) {
+	var databases []domain.Database
+	err := p.db.
+		Where("CAST(schema AS TEXT) LIKE ?", `%"formula"%`).
+		Find(&databases).Error
+	if err != nil {
+		return nil, err
+	}
+	return databases, nil
+}
+
 func (p *databasePers) Update(database *domain.Database) error {
 	return p.db.Debug().Save(database).Error
 }
@@ -86,46 +97,14 @@ func (p *databasePers) Search(query string, userId string, spaceId *string, limi
 
 	searchPattern := "%" + query + "%"
 
-	// Subquery: group IDs the user belongs to
-	userGroupIds := p.db.Table("group_members").Select("group_id").Where("user_id = ?", userId)
-
 	// Subquery: space IDs the user can access (public, owned, or via user/group permission)
-	accessibleSpaceIds := p.db.Table("space").
-		Select("id").
-		Where("deleted_at IS NULL").
-		Where(
-			p.db.Where("type = ?", domain.SpaceTypePublic).
-				Or("owner_id = ?", userId).
-				Or("id IN (?)",
-					p.db.Table("permission").
-						Select("space_id").
-						Where("type = ? AND space_id IS NOT NULL AND deleted_at IS NULL", domain.PermissionTypeSpace).
-						Where("role != ?", domain.PermissionRoleDenied).
-						Where(
-							p.db.Where("user_id = ?", userId).
-								Or("group_id IN (?)", userGroupIds),
-						),
-				),
-		)
+	accessibleSpaceIds := accessibleSpaceIds(p.db, userId)
 
 	// Subquery: database IDs where user is explicitly denied
-	deniedDbIds := p.db.Table("permission").
-		Select("database_id").
-		Where("type = ? AND database_id IS NOT NULL AND deleted_at IS NULL AND role = ?", domain.PermissionTypeDatabase, domain.PermissionRoleDenied).
-		Where(
-			p.db.Where("user_id = ?", userId).
-				Or("group_id IN (?)", userGroupIds),
-		)
+	deniedDbIds := deniedResourceIds(p.db, userId, domain.PermissionTypeDatabase, "database_id")
 
 	// Subquery: database IDs where user has explicit access (viewer+)
-	grantedDbIds := p.db.Table("permission").
-		Select("database_id").
-		Where("type = ? AND database_id IS NOT NULL AND deleted_at IS NULL", domain.PermissionTypeDatabase).
-		Where("role IN ?", []domain.PermissionRole{domain.PermissionRoleViewer, domain.PermissionRoleEditor, domain.PermissionRoleOwner}).
-		Where(
-			p.db.Where("user_id = ?", userId).
-				Or("group_id IN (?)", userGroupIds),
-		)
+	grantedDbIds := grantedResourceIds(p.db, userId, domain.PermissionTypeDatabase, "database_id")
 
 	dbQuery := p.db.
 		Preload("Space").
@@ -226,16 +205,12 @@ func (p *databaseRowPers) GetByDatabaseIdWithOptions(databaseId string, options
 
 	// Apply sorting
 	if len(options.Sort) > 0 {
+		rq := newRowQuery(p.db.Dialector.Name())
 		for _, sort := range options.Sort {
-			direction := "ASC"
-			if sort.Direction == "desc" {
-				direction = "DESC"
-			}
 			if !isValidPropertyName(sort.PropertyId) {
 				continue
 			}
-			// Sort by JSON property value (SQLite compatible)
-			query = query.Order("json_extract(properties, '$." + sort.PropertyId + "') " + direction)
+			query = query.Order(rq.order(sort))
 		}
 	} else {
 		query = query.Order("created_at DESC")
@@ -261,86 +236,14 @@ func (p *databaseRowPers) applyFilters(query *gorm.DB, filter *domain.FilterConf
 		return query
 	}
 
-	// Apply AND filters
-	for _, rule := range filter.And {
-		query = p.applyFilterRule(query, rule, "AND")
-	}
-
-	// Apply OR filters
-	if len(filter.Or) > 0 {
-		orQuery := p.db.Where("1 = 0") // Start with false for OR
-		for _, rule := range filter.Or {
-			orQuery = p.applyFilterRule(orQuery, rule, "OR")
-		}
-		query = query.Where(orQuery)
+	rq := newRowQuery(p.db.Dialector.Name())
+	if condition, args, ok := rq.group(filter.And, filter.Or); ok {
+		query = query.Where(condition, args...)
 	}
 
 	return query
 }
 
-func (p *databaseRowPers) applyFilterRule(query *gorm.DB, rule domain.FilterRule, _ string) *gorm.DB {
-	if !isValidPropertyName(rule.Property) {
-		return query
-	}
-	// Use json_extract for SQLite compatibility
-	propertyPath := "json_extract(properties, '$." + rule.Property + "')"
-
-	// Get string value safely
-	strValue := ""
-	if rule.Value != nil {
-		if s, ok := rule.Value.(string); ok {
-			strValue = s
-		}
-	}
-
-	switch rule.Condition {
-	case "eq":
-		if rule.Value == nil || strValue == "" {
-			return query // Skip empty eq filters
-		}
-		return query.Where(propertyPath+" = ?", rule.Value)
-	case "neq":
-		if rule.Value == nil || strValue == "" {
-			return query // Skip empty neq filters
-		}
-		return query.Where(propertyPath+" != ? OR "+propertyPath+" IS NULL", rule.Value)
-	case "gt":
-		return query.Where("CAST("+propertyPath+" AS REAL) > ?", rule.Value)
-	case "lt":
-		return query.Where("CAST("+propertyPath+" AS REAL) < ?", rule.Value)
-	case "gte":
-		return query.Where("CAST("+propertyPath+" AS REAL) >= ?", rule.Value)
-	case "lte":
-		return query.Where("CAST("+propertyPath+" AS REAL) <= ?", rule.Value)
-	case "contains":
-		if strValue == "" {
-			return query // Skip empty contains filters
-		}
-		return query.Where(propertyPath+" LIKE ? COLLATE NOCASE", "%"+strValue+"%")
-	case "not_contains":
-		if strValue == "" {
-			return query // Skip empty not_contains filters
-		}
-		return query.Where(propertyPath+" NOT LIKE ? COLLATE NOCASE OR "+propertyPath+" IS NULL", "%"+strValue+"%")
-	case "starts_with":
-		if strValue == "" {
-			return query // Skip empty starts_with filters
-		}
-		return query.Where(propertyPath+" LIKE ? COLLATE NOCASE", strValue+"%")
-	case "ends_with":
-		if strValue == "" {
-			return query // Skip empty ends_with filters
-		}
-		return query.Where(propertyPath+" LIKE ? COLLATE NOCASE", "%"+strValue)
-	case "is_empty":
-		return query.Where(propertyPath + " IS NULL OR " + propertyPath + " = ''")
-	case "is_not_empty":
-		return query.Where(propertyPath + " IS NOT NULL AND " + propertyPath + " != ''")
-	default:
-		return query
-	}
-}
-
 func (p *databaseRowPers) GetRowCount(databaseId string) (int64, error) {
 	var count int64
 	err := p.db.Debug().Model(&domain.DatabaseRow{}).
@@ -364,6 +267,12 @@ func (p *databaseRowPers) Update(row *domain.DatabaseRow) error {
 	return p.db.Debug().Save(row).Error
 }
 
+// UpdateProperties replaces the properties of a row without touching its other
+// columns (used to store computed values)
+func (p *databaseRowPers) UpdateProperties(id string, properties domain.JSONB) error {
+	return p.db.Debug().Model(&domain.DatabaseRow{}).Where("id = ?", id).UpdateColumn("properties", properties).Error
+}
+
 func (p *databaseRowPers) Delete(id string) error {
 	return p.db.Debug().Where("id = ?", id).Delete(&domain.DatabaseRow{}).Error
 }
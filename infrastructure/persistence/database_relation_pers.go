@@ -0,0 +1,187 @@
+package persistence
+
+import (
+	"errors"
+	"slices"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/labbs/nexo/domain"
+	"gorm.io/gorm"
+)
+
+// GetByIds returns the rows with the given ids (deleted rows are excluded)
+func (p *databaseRowPers) GetByIds(ids []string) ([]domain.DatabaseRow, error) {
+	var rows []domain.DatabaseRow
+	if len(ids) == 0 {
+		return rows, nil
+	}
+	err := p.db.Debug().Where("id IN ?", ids).Find(&rows).Error
+	return rows, err
+}
+
+// SetRelations replaces the rows related to a row through a relation
+// property. For two-way relations, the links of the other side are added or
+// removed as well, and the relation property of the related rows is updated.
+func (p *databaseRowPers) SetRelations(relation domain.RelationProperty, rowId string, relatedRowIds []string) error {
+	return p.db.Debug().Transaction(func(tx *gorm.DB) error {
+		var current []string
+		if err := tx.Model(&domain.DatabaseRowRelation{}).
+			Where("property_id = ? AND row_id = ?", relation.PropertyId, rowId).
+			Pluck("related_row_id", &current).Error; err != nil {
+			return err
+		}
+
+		if err := tx.Where("property_id = ? AND row_id = ?", relation.PropertyId, rowId).
+			Delete(&domain.DatabaseRowRelation{}).Error; err != nil {
+			return err
+		}
+		now := time.Now()
+		for i, relatedRowId := range relatedRowIds {
+			link := &domain.DatabaseRowRelation{
+				Id:                uuid.New().String(),
+				PropertyId:        relation.PropertyId,
+				DatabaseId:        relation.DatabaseId,
+				RowId:             rowId,
+				RelatedDatabaseId: relation.RelatedDatabaseId,
+				RelatedRowId:      relatedRowId,
+				Position:          i,
+				CreatedAt:         now,
+			}
+			if err := tx.Create(link).Error; err != nil {
+				return err
+			}
+		}
+
+		if relation.RelatedPropertyId == "" {
+			return nil
+		}
+
+		// Other side of a two-way relation
+		for _, relatedRowId := range current {
+			if slices.Contains(relatedRowIds, relatedRowId) {
+				continue
+			}
+			if err := tx.Where("property_id = ? AND row_id = ? AND related_row_id = ?", relation.RelatedPropertyId, relatedRowId, rowId).
+				Delete(&domain.DatabaseRowRelation{}).Error; err != nil {
+				return err
+			}
+			if err := syncRelationProperty(tx, relation.RelatedPropertyId, relatedRowId); err != nil {
+				return err
+			}
+		}
+		for _, relatedRowId := range relatedRowIds {
+			if slices.Contains(current, relatedRowId) {
+				continue
+			}
+			var position int64
+			if err := tx.Model(&domain.DatabaseRowRelation{}).
+				Where("property_id = ? AND row_id = ?", relation.RelatedPropertyId, relatedRowId).
+				Count(&position).Error; err != nil {
+				return err
+			}
+			link := &domain.DatabaseRowRelation{
+				Id:                uuid.New().String(),
+				PropertyId:        relation.RelatedPropertyId,
+				DatabaseId:        relation.RelatedDatabaseId,
+				RowId:             relatedRowId,
+				RelatedDatabaseId: relation.DatabaseId,
+				RelatedRowId:      rowId,
+				Position:          int(position),
+				CreatedAt:         now,
+			}
+			if err := tx.Create(link).Error; err != nil {
+				return err
+			}
+			if err := syncRelationProperty(tx, relation.RelatedPropertyId, relatedRowId); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// GetRelations returns the ids of the rows related to each row through a
+// relation property, in order
+func (p *databaseRowPers) GetRelations(propertyId string, rowIds []string) (map[string][]string, error) {
+	relations := make(map[string][]string, len(rowIds))
+	if len(rowIds) == 0 {
+		return relations, nil
+	}
+
+	var links []domain.DatabaseRowRelation
+	err := p.db.Debug().
+		Where("property_id = ? AND row_id IN ?", propertyId, rowIds).
+		Order("position ASC").
+		Find(&links).Error
+	if err != nil {
+		return nil, err
+	}
+	for _, link := range links {
+		relations[link.RowId] = append(relations[link.RowId], link.RelatedRowId)
+	}
+	return relations, nil
+}
+
+// DeleteRelations removes the links from and to deleted rows, and the deleted
+// rows from the relation properties of the rows that referenced them
+func (p *databaseRowPers) DeleteRelations(rowIds []string) error {
+	if len(rowIds) == 0 {
+		return nil
+	}
+	return p.db.Debug().Transaction(func(tx *gorm.DB) error {
+		var referencing []domain.DatabaseRowRelation
+		if err := tx.Where("related_row_id IN ? AND row_id NOT IN ?", rowIds, rowIds).
+			Find(&referencing).Error; err != nil {
+			return err
+		}
+
+		if err := tx.Where("row_id IN ? OR related_row_id IN ?", rowIds, rowIds).
+			Delete(&domain.DatabaseRowRelation{}).Error; err != nil {
+			return err
+		}
+
+		synced := map[[2]string]bool{}
+		for _, link := range referencing {
+			key := [2]string{link.PropertyId, link.RowId}
+			if synced[key] {
+				continue
+			}
+			synced[key] = true
+			if err := syncRelationProperty(tx, link.PropertyId, link.RowId); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// syncRelationProperty stores the related rows of a row in its relation
+// property, so that relations can be filtered like the other properties
+func syncRelationProperty(tx *gorm.DB, propertyId, rowId string) error {
+	var relatedRowIds []string
+	if err := tx.Model(&domain.DatabaseRowRelation{}).
+		Where("property_id = ? AND row_id = ?", propertyId, rowId).
+		Order("position ASC").
+		Pluck("related_row_id", &relatedRowIds).Error; err != nil {
+		return err
+	}
+
+	var row domain.DatabaseRow
+	if err := tx.Select("id", "properties").Where("id = ?", rowId).First(&row).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil // deleted row
+		}
+		return err
+	}
+	if row.Properties == nil {
+		row.Properties = domain.JSONB{}
+	}
+	values := make([]any, len(relatedRowIds))
+	for i, relatedRowId := range relatedRowIds {
+		values[i] = relatedRowId
+	}
+	row.Properties[propertyId] = values
+
+	return tx.Model(&domain.DatabaseRow{}).Where("id = ?", rowId).UpdateColumn("properties", row.Properties).Error
+}
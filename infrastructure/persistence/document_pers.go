@@ -22,9 +22,9 @@ func (p *documentPers) GetDocumentWithPermissions(documentId, userId string) (*d
 	err := p.db.
 		Preload("Space", func(db *gorm.DB) *gorm.DB {
 			return db.Preload("Owner").
-				Preload("Permissions", "user_id = ? AND deleted_at IS NULL", userId)
+				Scopes(spaceForUser(p.db, userId))
 		}).
-		Preload("Permissions", "user_id = ? AND deleted_at IS NULL", userId).
+		Scopes(preloadUserPermissions(p.db, userId)).
 		Where("id = ?", documentId).
 		First(&doc).Error
 	if err != nil {
@@ -33,6 +33,11 @@ func (p *documentPers) GetDocumentWithPermissions(documentId, userId string) (*d
 		}
 		return nil, err
 	}
+	if doc.ParentId != nil {
+		if doc.Ancestors, err = p.getAncestors(*doc.ParentId, userId); err != nil {
+			return nil, err
+		}
+	}
 	return &doc, nil
 }
 
@@ -43,10 +48,10 @@ func (p *documentPers) GetDocumentByIdOrSlugWithUserPermissions(spaceId string,
 		// Preload the space along with owner and its permissions for the user
 		Preload("Space", func(db *gorm.DB) *gorm.DB {
 			return db.Preload("Owner").
-				Preload("Permissions", "user_id = ? AND deleted_at IS NULL", userId)
+				Scopes(spaceForUser(p.db, userId))
 		}).
 		// Preload only the document's permissions for this user
-		Preload("Permissions", "user_id = ? AND deleted_at IS NULL", userId).
+		Scopes(preloadUserPermissions(p.db, userId)).
 		// Optionally preload the parent if needed
 		Preload("Parent").
 		Where("space_id = ?", spaceId)
@@ -66,6 +71,11 @@ func (p *documentPers) GetDocumentByIdOrSlugWithUserPermissions(spaceId string,
 		}
 		return nil, err
 	}
+	if doc.ParentId != nil {
+		if doc.Ancestors, err = p.getAncestors(*doc.ParentId, userId); err != nil {
+			return nil, err
+		}
+	}
 
 	// Verify if the user has at least viewer permissions
 	if !doc.HasPermission(userId, domain.PermissionRoleViewer) {
@@ -82,10 +92,10 @@ func (p *documentPers) GetRootDocumentsFromSpaceWithUserPermissions(spaceId, use
 		// Preload space with owner and permissions for the user
 		Preload("Space", func(db *gorm.DB) *gorm.DB {
 			return db.Preload("Owner").
-				Preload("Permissions", "user_id = ? AND deleted_at IS NULL", userId)
+				Scopes(spaceForUser(p.db, userId))
 		}).
 		// Preload document permissions for this user
-		Preload("Permissions", "user_id = ? AND deleted_at IS NULL", userId).
+		Scopes(preloadUserPermissions(p.db, userId)).
 		Where("space_id = ? AND parent_id IS NULL AND deleted_at IS NULL", spaceId).
 		Order("position ASC, created_at ASC").
 		Find(&docs).Error
@@ -112,10 +122,10 @@ func (p *documentPers) GetChildDocumentsWithUserPermissions(parentId, userId str
 		// Preload space with owner and permissions for the user
 		Preload("Space", func(db *gorm.DB) *gorm.DB {
 			return db.Preload("Owner").
-				Preload("Permissions", "user_id = ? AND deleted_at IS NULL", userId)
+				Scopes(spaceForUser(p.db, userId))
 		}).
 		// Preload document permissions for this user
-		Preload("Permissions", "user_id = ? AND deleted_at IS NULL", userId).
+		Scopes(preloadUserPermissions(p.db, userId)).
 		// Preload the parent to have the complete context
 		Preload("Parent").
 		Where("parent_id = ? AND deleted_at IS NULL", parentId).
@@ -126,9 +136,16 @@ func (p *documentPers) GetChildDocumentsWithUserPermissions(parentId, userId str
 		return nil, err
 	}
 
+	// The documents share their ancestors, loaded once for all of them
+	ancestors, err := p.getAncestors(parentId, userId)
+	if err != nil {
+		return nil, err
+	}
+
 	// Filter documents based on permissions
 	var accessibleDocs []domain.Document
 	for _, doc := range docs {
+		doc.Ancestors = ancestors
 		if doc.HasPermission(userId, domain.PermissionRoleViewer) {
 			accessibleDocs = append(accessibleDocs, doc)
 		}
@@ -137,6 +154,51 @@ func (p *documentPers) GetChildDocumentsWithUserPermissions(parentId, userId str
 	return accessibleDocs, nil
 }
 
+// getAncestors returns a document and its parent documents, the closest
+// first, with the permissions of the user, in a single query whatever the
+// depth. The chain stops at the first document breaking the inheritance, as
+// the ones above it do not apply.
+func (p *documentPers) getAncestors(documentId, userId string) ([]domain.Document, error) {
+	var docs []domain.Document
+	err := p.db.
+		Scopes(preloadUserPermissions(p.db, userId)).
+		Where("id IN (?)", documentAncestorIds(p.db, documentId)).
+		Find(&docs).Error
+	if err != nil {
+		return nil, err
+	}
+
+	byId := make(map[string]domain.Document, len(docs))
+	for _, doc := range docs {
+		byId[doc.Id] = doc
+	}
+	ancestors := make([]domain.Document, 0, len(docs))
+	for id := &documentId; id != nil; {
+		doc, found := byId[*id]
+		if !found {
+			break
+		}
+		// Removed so that a cycle in the tree cannot loop
+		delete(byId, *id)
+		ancestors = append(ancestors, doc)
+		if doc.BreakInheritance {
+			break
+		}
+		id = doc.ParentId
+	}
+	return ancestors, nil
+}
+
+// documentAncestorIds is the subquery of the ids of a document and of its
+// parent documents, up to the root
+func documentAncestorIds(db *gorm.DB, documentId string) *gorm.DB {
+	return db.Raw(`WITH RECURSIVE ancestors(id, parent_id) AS (
+		SELECT id, parent_id FROM document WHERE id = ?
+		UNION
+		SELECT document.id, document.parent_id FROM document JOIN ancestors ON document.id = ancestors.parent_id
+	) SELECT id FROM ancestors`, documentId)
+}
+
 func (p *documentPers) Create(document *domain.Document, userId string) error {
 	// If the document has a parent, check permissions on the parent
 	if document.ParentId != nil {
@@ -154,7 +216,7 @@ func (p *documentPers) Create(document *domain.Document, userId string) error {
 		var space domain.Space
 		err := p.db.Debug().
 			Preload("Owner").
-			Preload("Permissions", "user_id = ? AND deleted_at IS NULL", userId).
+			Scopes(spaceForUser(p.db, userId)).
 			Where("id = ?", document.SpaceId).
 			First(&space).Error
 		if err != nil {
@@ -277,7 +339,7 @@ func (p *documentPers) GetDeletedDocuments(spaceId, userId string) ([]domain.Doc
 	err := p.db.Unscoped().
 		Preload("Space", func(db *gorm.DB) *gorm.DB {
 			return db.Preload("Owner").
-				Preload("Permissions", "user_id = ? AND deleted_at IS NULL", userId)
+				Scopes(spaceForUser(p.db, userId))
 		}).
 		Where("space_id = ? AND deleted_at IS NOT NULL", spaceId).
 		Order("deleted_at DESC").
@@ -304,7 +366,7 @@ func (p *documentPers) Restore(documentId, userId string) error {
 	err := p.db.Unscoped().
 		Preload("Space", func(db *gorm.DB) *gorm.DB {
 			return db.Preload("Owner").
-				Preload("Permissions", "user_id = ? AND deleted_at IS NULL", userId)
+				Scopes(spaceForUser(p.db, userId))
 		}).
 		Where("id = ?", documentId).
 		First(&doc).Error
@@ -351,6 +413,20 @@ func (p *documentPers) SetPublic(documentId string, public bool, userId string)
 	return p.db.Model(&domain.Document{}).Where("id = ?", documentId).Update("public", public).Error
 }
 
+func (p *documentPers) SetBreakInheritance(documentId string, breakInheritance bool, userId string) error {
+	doc, err := p.GetDocumentWithPermissions(documentId, userId)
+	if err != nil {
+		return fmt.Errorf("document not found: %w", err)
+	}
+
+	// Breaking the inheritance changes who can access the subtree
+	if !doc.CanManagePermissions(userId) {
+		return apperrors.ErrAccessDenied
+	}
+
+	return p.db.Model(&domain.Document{}).Where("id = ?", documentId).Update("break_inheritance", breakInheritance).Error
+}
+
 func (p *documentPers) GetPublicDocument(spaceId string, id *string, slug *string) (*domain.Document, error) {
 	var doc domain.Document
 
@@ -378,90 +454,12 @@ func (p *documentPers) GetPublicDocument(spaceId string, id *string, slug *strin
 	return &doc, nil
 }
 
-func (p *documentPers) Search(query string, userId string, spaceId *string, limit int) ([]domain.Document, error) {
-	var docs []domain.Document
-
-	if limit <= 0 || limit > 50 {
-		limit = 20
-	}
-
-	searchPattern := "%" + query + "%"
-
-	// Subquery: group IDs the user belongs to
-	userGroupIds := p.db.Table("group_members").Select("group_id").Where("user_id = ?", userId)
-
-	// Subquery: space IDs the user can access (public, owned, or via user/group permission)
-	accessibleSpaceIds := p.db.Table("space").
-		Select("id").
-		Where("deleted_at IS NULL").
-		Where(
-			p.db.Where("type = ?", domain.SpaceTypePublic).
-				Or("owner_id = ?", userId).
-				Or("id IN (?)",
-					p.db.Table("permission").
-						Select("space_id").
-						Where("type = ? AND space_id IS NOT NULL AND deleted_at IS NULL", domain.PermissionTypeSpace).
-						Where("role != ?", domain.PermissionRoleDenied).
-						Where(
-							p.db.Where("user_id = ?", userId).
-								Or("group_id IN (?)", userGroupIds),
-						),
-				),
-		)
-
-	// Subquery: document IDs where user is explicitly denied
-	deniedDocIds := p.db.Table("permission").
-		Select("document_id").
-		Where("type = ? AND document_id IS NOT NULL AND deleted_at IS NULL AND role = ?", domain.PermissionTypeDocument, domain.PermissionRoleDenied).
-		Where(
-			p.db.Where("user_id = ?", userId).
-				Or("group_id IN (?)", userGroupIds),
-		)
-
-	// Subquery: document IDs where user has explicit access (viewer+)
-	grantedDocIds := p.db.Table("permission").
-		Select("document_id").
-		Where("type = ? AND document_id IS NOT NULL AND deleted_at IS NULL", domain.PermissionTypeDocument).
-		Where("role IN ?", []domain.PermissionRole{domain.PermissionRoleViewer, domain.PermissionRoleEditor, domain.PermissionRoleOwner}).
-		Where(
-			p.db.Where("user_id = ?", userId).
-				Or("group_id IN (?)", userGroupIds),
-		)
-
-	dbQuery := p.db.
-		Preload("Space").
-		Where("deleted_at IS NULL").
-		Where("(name LIKE ? OR content LIKE ?)", searchPattern, searchPattern).
-		// Exclude documents where user is explicitly denied
-		Where("id NOT IN (?)", deniedDocIds).
-		// Must have either document-level access OR space-level access
-		Where(
-			p.db.Where("id IN (?)", grantedDocIds).
-				Or("space_id IN (?)", accessibleSpaceIds),
-		)
-
-	if spaceId != nil {
-		dbQuery = dbQuery.Where("space_id = ?", *spaceId)
-	}
-
-	err := dbQuery.
-		Order("updated_at DESC").
-		Limit(limit).
-		Find(&docs).Error
-
-	if err != nil {
-		return nil, err
-	}
-
-	return docs, nil
-}
-
 func (p *documentPers) Reorder(spaceId string, items []domain.ReorderItem, userId string) error {
 	// Verify user has editor access on the space
 	var space domain.Space
 	err := p.db.Debug().
 		Preload("Owner").
-		Preload("Permissions", "user_id = ? AND deleted_at IS NULL", userId).
+		Scopes(spaceForUser(p.db, userId)).
 		Where("id = ?", spaceId).
 		First(&space).Error
 	if err != nil {
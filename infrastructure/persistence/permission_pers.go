@@ -46,6 +46,34 @@ func (p *permissionPers) ListByResource(resourceType domain.PermissionType, reso
 	return perms, err
 }
 
+func (p *permissionPers) ListByResourceForUser(resourceType domain.PermissionType, resourceId, userId string) ([]domain.Permission, error) {
+	var perms []domain.Permission
+	column := getResourceColumn(resourceType)
+	if column == "" {
+		return nil, nil
+	}
+
+	err := p.db.Preload("Group.Members", "id = ?", userId).
+		Where("type = ? AND "+column+" = ? AND deleted_at IS NULL", resourceType, resourceId).
+		Where("user_id = ? OR group_id IN (?)", userId, userGroupIds(p.db, userId)).
+		Find(&perms).Error
+	return perms, err
+}
+
+func (p *permissionPers) ListByResourcesForUser(resourceType domain.PermissionType, resourceIds []string, userId string) ([]domain.Permission, error) {
+	var perms []domain.Permission
+	column := getResourceColumn(resourceType)
+	if column == "" || len(resourceIds) == 0 {
+		return nil, nil
+	}
+
+	err := p.db.Preload("Group.Members", "id = ?", userId).
+		Where("type = ? AND "+column+" IN ? AND deleted_at IS NULL", resourceType, resourceIds).
+		Where("user_id = ? OR group_id IN (?)", userId, userGroupIds(p.db, userId)).
+		Find(&perms).Error
+	return perms, err
+}
+
 func (p *permissionPers) GetByResourceAndUser(resourceType domain.PermissionType, resourceId, userId string) (*domain.Permission, error) {
 	var perm domain.Permission
 	column := getResourceColumn(resourceType)
@@ -171,3 +199,150 @@ func (p *permissionPers) DeleteGroup(resourceType domain.PermissionType, resourc
 	return p.db.Where("type = ? AND "+column+" = ? AND group_id = ?", resourceType, resourceId, groupId).
 		Delete(&domain.Permission{}).Error
 }
+
+// userGroupIds is the subquery of the groups a user is a member of
+func userGroupIds(db *gorm.DB, userId string) *gorm.DB {
+	return db.Table("group_members").Select("group_id").Where("user_id = ?", userId)
+}
+
+// preloadUserPermissions preloads the permissions of a resource which apply
+// to a user, directly or through their groups, with the membership of the
+// user in these groups so that domain.ResolvePermissions can apply them.
+func preloadUserPermissions(db *gorm.DB, userId string) func(*gorm.DB) *gorm.DB {
+	return func(tx *gorm.DB) *gorm.DB {
+		return tx.
+			Preload("Permissions", "deleted_at IS NULL AND (user_id = ? OR group_id IN (?))", userId, userGroupIds(db, userId)).
+			Preload("Permissions.Group.Members", "id = ?", userId)
+	}
+}
+
+// resourcePermissions is the subquery of the resources of a type (in the
+// column of their ids) on the permissions matching the conditions
+func resourcePermissions(db *gorm.DB, resourceType domain.PermissionType, column string) *gorm.DB {
+	return db.Table("permission").
+		Select(column).
+		Where("type = ? AND "+column+" IS NOT NULL AND deleted_at IS NULL", resourceType)
+}
+
+// userResourceRoles matches the permissions which resolve the role of a
+// user, following domain.ResolveRole: the permissions of the user, or those
+// of their groups on the resources the user has no permission of their own.
+func userResourceRoles(db *gorm.DB, userId string, resourceType domain.PermissionType, column string) *gorm.DB {
+	return db.Where("user_id = ?", userId).
+		Or(
+			db.Where("group_id IN (?)", userGroupIds(db, userId)).
+				Where(column+" NOT IN (?)", resourcePermissions(db, resourceType, column).Where("user_id = ?", userId)),
+		)
+}
+
+// deniedResourceIds is the subquery of the resources of a type the user is
+// denied, directly or through a group
+func deniedResourceIds(db *gorm.DB, userId string, resourceType domain.PermissionType, column string) *gorm.DB {
+	return resourcePermissions(db, resourceType, column).
+		Where("role = ?", domain.PermissionRoleDenied).
+		Where(userResourceRoles(db, userId, resourceType, column))
+}
+
+// grantedResourceIds is the subquery of the resources of a type on which a
+// permission gives a role to the user, directly or through a group. The
+// resources they are also denied are in deniedResourceIds.
+func grantedResourceIds(db *gorm.DB, userId string, resourceType domain.PermissionType, column string) *gorm.DB {
+	return resourcePermissions(db, resourceType, column).
+		Where("role != ?", domain.PermissionRoleDenied).
+		Where(userResourceRoles(db, userId, resourceType, column))
+}
+
+// guestUserIds is the subquery of the guest users, to whom public spaces
+// give no access
+func guestUserIds(db *gorm.DB) *gorm.DB {
+	return db.Model(&domain.User{}).
+		Select("id").
+		Where("role = ?", domain.RoleGest)
+}
+
+// selectRequester selects the user a space is loaded for along with the
+// space, and their role on the instance, for domain.Space.RequesterRole
+func selectRequester(db *gorm.DB, userId string) func(*gorm.DB) *gorm.DB {
+	return func(tx *gorm.DB) *gorm.DB {
+		return tx.Select("space.*, ? AS requester_id, (?) AS requester_role", userId,
+			db.Model(&domain.User{}).Select("role").Where("id = ?", userId))
+	}
+}
+
+// spaceForUser loads a space for a user, with the permissions of the user
+func spaceForUser(db *gorm.DB, userId string) func(*gorm.DB) *gorm.DB {
+	return func(tx *gorm.DB) *gorm.DB {
+		return tx.Scopes(selectRequester(db, userId), preloadUserPermissions(db, userId))
+	}
+}
+
+// accessibleSpaceIds is the subquery of the spaces the user can read: owned,
+// or public (except for guests) or granted to the user or their groups,
+// unless denied.
+func accessibleSpaceIds(db *gorm.DB, userId string) *gorm.DB {
+	return db.Table("space").
+		Select("id").
+		Where("deleted_at IS NULL").
+		Where(
+			db.Where("owner_id = ?", userId).
+				Or(
+					db.Where(
+						db.Where("type = ? AND ? NOT IN (?)", domain.SpaceTypePublic, userId, guestUserIds(db)).
+							Or("id IN (?)", grantedResourceIds(db, userId, domain.PermissionTypeSpace, "space_id")),
+					).Where("id NOT IN (?)", deniedResourceIds(db, userId, domain.PermissionTypeSpace, "space_id")),
+				),
+		)
+}
+
+// adminSpaceIds is the subquery of the spaces the user owns or administers
+func adminSpaceIds(db *gorm.DB, userId string) *gorm.DB {
+	adminIds := resourcePermissions(db, domain.PermissionTypeSpace, "space_id").
+		Where("role IN ?", []domain.PermissionRole{domain.PermissionRoleOwner, domain.PermissionRoleAdmin}).
+		Where(userResourceRoles(db, userId, domain.PermissionTypeSpace, "space_id"))
+
+	return db.Table("space").
+		Select("id").
+		Where("deleted_at IS NULL").
+		Where(
+			db.Where("owner_id = ?", userId).
+				Or(
+					db.Where("id IN (?)", adminIds).
+						Where("id NOT IN (?)", deniedResourceIds(db, userId, domain.PermissionTypeSpace, "space_id")),
+				),
+		)
+}
+
+// readableDocumentIds is the subquery of the documents the user can read,
+// following domain.Document.EffectivePermission down the tree in a single
+// query: the permissions of a document decide, else its parent's access is
+// inherited, else the access to the space for the root documents. Documents
+// breaking the inheritance are only readable by the admins of the space
+// without a permission of their own.
+func readableDocumentIds(db *gorm.DB, userId string) *gorm.DB {
+	deniedIds := deniedResourceIds(db, userId, domain.PermissionTypeDocument, "document_id")
+	grantedIds := grantedResourceIds(db, userId, domain.PermissionTypeDocument, "document_id")
+	adminIds := adminSpaceIds(db, userId)
+
+	return db.Raw(`WITH RECURSIVE document_access(id, readable) AS (
+		SELECT document.id, CASE
+			WHEN document.id IN (?) THEN 0
+			WHEN document.id IN (?) THEN 1
+			WHEN document.break_inheritance THEN CASE WHEN document.space_id IN (?) THEN 1 ELSE 0 END
+			WHEN document.space_id IN (?) THEN 1
+			ELSE 0 END
+		FROM document
+		WHERE document.parent_id IS NULL AND document.deleted_at IS NULL
+		UNION
+		SELECT document.id, CASE
+			WHEN document.id IN (?) THEN 0
+			WHEN document.id IN (?) THEN 1
+			WHEN document.break_inheritance THEN CASE WHEN document.space_id IN (?) THEN 1 ELSE 0 END
+			ELSE document_access.readable END
+		FROM document
+		JOIN document_access ON document.parent_id = document_access.id
+		WHERE document.deleted_at IS NULL
+	) SELECT id FROM document_access WHERE readable = 1`,
+		deniedIds, grantedIds, adminIds, accessibleSpaceIds(db, userId),
+		deniedIds, grantedIds, adminIds,
+	)
+}
@@ -0,0 +1,530 @@
+package persistence
+
+import (
+	"encoding/json"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/labbs/nexo/domain"
+)
+
+// rowQuery builds the SQL used to filter and sort database rows on their JSON
+// properties, for the dialect of the connection: json_extract on SQLite,
+// ->/->> operators on PostgreSQL. On PostgreSQL, equality is expressed as a
+// containment (properties @> '{"x": value}') so that the GIN index on
+// database_row.properties is used.
+type rowQuery struct {
+	postgres bool
+}
+
+func newRowQuery(dialect string) rowQuery {
+	return rowQuery{postgres: dialect == "postgres"}
+}
+
+// dateValue matches the values compared as dates: YYYY-MM-DD, optionally followed by a time
+var dateValue = regexp.MustCompile(`^\d{4}-\d{2}-\d{2}([T ][0-9:.]+(Z|[+-]\d{2}:?\d{2})?)?$`)
+
+// value returns the property as a typed JSON value, used for sorting
+func (q rowQuery) value(property string) string {
+	if q.postgres {
+		return "(properties -> '" + property + "')"
+	}
+	return `json_extract(properties, '$."` + property + `"')`
+}
+
+// text returns the property as text
+func (q rowQuery) text(property string) string {
+	if q.postgres {
+		return "(properties ->> '" + property + "')"
+	}
+	return `json_extract(properties, '$."` + property + `"')`
+}
+
+// number returns the property as a number, NULL when it is not numeric
+func (q rowQuery) number(property string) string {
+	if q.postgres {
+		text := q.text(property)
+		return "(CASE WHEN " + text + ` ~ '^\s*-{0,1}[0-9]+(\.[0-9]+){0,1}\s*$' THEN ` + text + "::numeric END)"
+	}
+	return "CAST(" + q.text(property) + " AS REAL)"
+}
+
+// date returns the property as a timestamp, NULL when it is not a date.
+// Dates without a time are midnight UTC.
+func (q rowQuery) date(property string) string {
+	if q.postgres {
+		text := q.text(property)
+		return "(CASE WHEN " + text + ` ~ '^\d{4}-\d{2}-\d{2}$' THEN (` + text + " || 'T00:00:00Z')::timestamptz" +
+			" WHEN " + text + ` ~ '^\d{4}-\d{2}-\d{2}' THEN ` + text + "::timestamptz END)"
+	}
+	return "datetime(" + q.text(property) + ")"
+}
+
+// dateParam returns the placeholder of a date parameter
+func (q rowQuery) dateParam() string {
+	if q.postgres {
+		return "?::timestamptz"
+	}
+	return "datetime(?)"
+}
+
+// like returns a case-insensitive LIKE condition on the property
+// (LIKE is case-insensitive on SQLite)
+func (q rowQuery) like(property string, negate bool) string {
+	operator := "LIKE"
+	if q.postgres {
+		operator = "ILIKE"
+	}
+	if negate {
+		operator = "NOT " + operator
+	}
+	return q.text(property) + " " + operator + ` ? ESCAPE '\'`
+}
+
+// equals returns a condition matching rows whose property equals one of the
+// candidate values (see equalityCandidates)
+func (q rowQuery) equals(property string, value any) (string, []any) {
+	candidates := equalityCandidates(value)
+	conditions := make([]string, len(candidates))
+	args := make([]any, len(candidates))
+	for i, candidate := range candidates {
+		if q.postgres {
+			raw, _ := json.Marshal(map[string]any{property: candidate})
+			conditions[i] = "properties @> ?::jsonb"
+			args[i] = string(raw)
+		} else {
+			conditions[i] = q.text(property) + " = ?"
+			args[i] = candidate
+		}
+	}
+	return "(" + strings.Join(conditions, " OR ") + ")", args
+}
+
+// order returns the ORDER BY clause of a sort rule, NULLs last on both dialects
+func (q rowQuery) order(sort domain.SortRule) string {
+	direction := "ASC"
+	if sort.Direction == "desc" {
+		direction = "DESC"
+	}
+	return q.value(sort.PropertyId) + " " + direction + " NULLS LAST"
+}
+
+// rowColumns are the row columns of the property types filled by the server
+var rowColumns = map[domain.PropertyType]string{
+	domain.PropertyTypeCreatedTime: "created_at",
+	domain.PropertyTypeUpdatedTime: "updated_at",
+	domain.PropertyTypeCreatedBy:   "created_by",
+	domain.PropertyTypeUpdatedBy:   "updated_by",
+}
+
+// group returns the condition of a group of rules: every "and" rule must match
+// and, when there are "or" rules, at least one of them must match. ok is false
+// when no rule of the group applies.
+func (q rowQuery) group(and, or []domain.FilterRule) (sql string, args []any, ok bool) {
+	var conditions []string
+	for _, rule := range and {
+		if condition, ruleArgs, ok := q.rule(rule); ok {
+			conditions = append(conditions, condition)
+			args = append(args, ruleArgs...)
+		}
+	}
+
+	var alternatives []string
+	for _, rule := range or {
+		if condition, ruleArgs, ok := q.rule(rule); ok {
+			alternatives = append(alternatives, condition)
+			args = append(args, ruleArgs...)
+		}
+	}
+	if len(alternatives) > 0 {
+		conditions = append(conditions, "("+strings.Join(alternatives, " OR ")+")")
+	}
+
+	if len(conditions) == 0 {
+		return "", nil, false
+	}
+	return "(" + strings.Join(conditions, " AND ") + ")", args, true
+}
+
+// rule returns the condition of a rule or of a nested group
+func (q rowQuery) rule(rule domain.FilterRule) (string, []any, bool) {
+	if rule.IsGroup() {
+		return q.group(rule.And, rule.Or)
+	}
+	return q.condition(rule)
+}
+
+// condition returns the SQL condition of a filter rule, depending on the type
+// of its property. ok is false when the rule must be ignored (invalid
+// property, unknown condition or empty value).
+func (q rowQuery) condition(rule domain.FilterRule) (sql string, args []any, ok bool) {
+	if column, isColumn := rowColumns[rule.Type]; isColumn {
+		return q.columnCondition(column, rule)
+	}
+	if !isValidPropertyName(rule.Property) {
+		return "", nil, false
+	}
+
+	switch {
+	case rule.Type == domain.PropertyTypeCheckbox,
+		rule.Type == domain.PropertyTypeFormula && (rule.Condition == domain.FilterIsChecked || rule.Condition == domain.FilterIsNotChecked):
+		return q.checkboxCondition(rule)
+	case rule.Type.IsList() && rule.Condition != "is_empty" && rule.Condition != "is_not_empty":
+		return q.listCondition(rule)
+	case rule.Type.IsDate() && rule.Condition != "is_empty" && rule.Condition != "is_not_empty":
+		return q.dateCondition(q.date(rule.Property), rule)
+	default:
+		return q.valueCondition(rule)
+	}
+}
+
+// columnCondition returns the condition of a rule on a row column
+func (q rowQuery) columnCondition(column string, rule domain.FilterRule) (string, []any, bool) {
+	if rule.Type.IsDate() {
+		expression := column
+		if !q.postgres {
+			expression = "datetime(" + column + ")"
+		}
+		return q.dateCondition(expression, rule)
+	}
+
+	switch rule.Condition {
+	case domain.FilterEq, domain.FilterNeq:
+		value, ok := rule.Value.(string)
+		if !ok || value == "" {
+			return "", nil, false
+		}
+		if rule.Condition == domain.FilterNeq {
+			return column + " <> ?", []any{value}, true
+		}
+		return column + " = ?", []any{value}, true
+	case domain.FilterIsAnyOf, domain.FilterIsNoneOf:
+		values := filterValues(rule.Value)
+		if len(values) == 0 {
+			return "", nil, false
+		}
+		if rule.Condition == domain.FilterIsNoneOf {
+			return column + " NOT IN ?", []any{values}, true
+		}
+		return column + " IN ?", []any{values}, true
+	default:
+		return "", nil, false
+	}
+}
+
+// checked returns the condition matching rows whose checkbox property is true
+func (q rowQuery) checked(property string) (string, []any) {
+	if q.postgres {
+		raw, _ := json.Marshal(map[string]any{property: true})
+		return "properties @> ?::jsonb", []any{string(raw)}
+	}
+	return `json_type(properties, '$."` + property + `"') IS 'true'`, nil
+}
+
+// checkboxCondition returns the condition of a rule on a checkbox property;
+// unset checkboxes are unchecked
+func (q rowQuery) checkboxCondition(rule domain.FilterRule) (string, []any, bool) {
+	checked := true
+	switch rule.Condition {
+	case domain.FilterIsChecked:
+	case domain.FilterIsNotChecked:
+		checked = false
+	case domain.FilterEq:
+		switch rule.Value {
+		case true, "true":
+		case false, "false":
+			checked = false
+		default:
+			return "", nil, false
+		}
+	default:
+		return "", nil, false
+	}
+
+	sql, args := q.checked(rule.Property)
+	if !checked {
+		sql = "NOT (" + sql + ")"
+	}
+	return sql, args, true
+}
+
+// element returns the condition matching rows whose list property (multi
+// select, person, relation) contains the value. A value stored alone rather
+// than in a list also matches.
+func (q rowQuery) element(property string, value any) (string, []any) {
+	if q.postgres {
+		inList, _ := json.Marshal(map[string]any{property: []any{value}})
+		alone, _ := json.Marshal(map[string]any{property: value})
+		return "(properties @> ?::jsonb OR properties @> ?::jsonb)", []any{string(inList), string(alone)}
+	}
+	return `EXISTS (SELECT 1 FROM json_each(properties, '$."` + property + `"') WHERE json_each.value = ?)`, []any{value}
+}
+
+// listCondition returns the condition of a rule on a list property
+func (q rowQuery) listCondition(rule domain.FilterRule) (string, []any, bool) {
+	values := filterValues(rule.Value)
+	if len(values) == 0 {
+		return "", nil, false
+	}
+
+	var conditions []string
+	var args []any
+	for _, value := range values {
+		condition, valueArgs := q.element(rule.Property, value)
+		conditions = append(conditions, condition)
+		args = append(args, valueArgs...)
+	}
+
+	switch rule.Condition {
+	case domain.FilterContains, domain.FilterContainsAny:
+		return "(" + strings.Join(conditions, " OR ") + ")", args, true
+	case domain.FilterNotContains:
+		return "NOT (" + strings.Join(conditions, " OR ") + ")", args, true
+	case domain.FilterContainsAll:
+		return "(" + strings.Join(conditions, " AND ") + ")", args, true
+	default:
+		return "", nil, false
+	}
+}
+
+// dateCondition returns the condition of a rule on a date expression. A date
+// without a time matches the whole day (in UTC): "before 2026-10-17" excludes
+// the 17th and "after 2026-10-17" starts on the 18th.
+func (q rowQuery) dateCondition(expression string, rule domain.FilterRule) (string, []any, bool) {
+	var from, to time.Time // [from, to) range of matching dates, zero when unbounded
+
+	switch rule.Condition {
+	case domain.FilterEq, domain.FilterBefore, domain.FilterAfter, domain.FilterOnOrBefore, domain.FilterOnOrAfter:
+		start, end, ok := dateBounds(rule.Value)
+		if !ok {
+			return "", nil, false
+		}
+		switch rule.Condition {
+		case domain.FilterEq:
+			from, to = start, end
+		case domain.FilterBefore:
+			to = start
+		case domain.FilterAfter:
+			from = end
+		case domain.FilterOnOrBefore:
+			to = end
+		case domain.FilterOnOrAfter:
+			from = start
+		}
+	case domain.FilterBetween:
+		first, last, ok := rangeBounds(rule.Value)
+		if !ok {
+			return "", nil, false
+		}
+		from, _, _ = dateBounds(first)
+		_, to, _ = dateBounds(last)
+	case domain.FilterRelative:
+		value, _ := rule.Value.(string)
+		start, end, ok := domain.RelativeDateRange(value, time.Now())
+		if !ok {
+			return "", nil, false
+		}
+		from, to = start, end
+	default:
+		return "", nil, false
+	}
+
+	var conditions []string
+	var args []any
+	if !from.IsZero() {
+		conditions = append(conditions, expression+" >= "+q.dateParam())
+		args = append(args, from.Format(time.RFC3339))
+	}
+	if !to.IsZero() {
+		conditions = append(conditions, expression+" < "+q.dateParam())
+		args = append(args, to.Format(time.RFC3339))
+	}
+	if len(conditions) == 0 {
+		return "", nil, false
+	}
+	return "(" + strings.Join(conditions, " AND ") + ")", args, true
+}
+
+// valueCondition returns the condition of a rule on a text, number or select
+// property, or on a property whose type is unknown
+func (q rowQuery) valueCondition(rule domain.FilterRule) (sql string, args []any, ok bool) {
+	property := rule.Property
+
+	// Get string value safely
+	strValue := ""
+	if rule.Value != nil {
+		if s, ok := rule.Value.(string); ok {
+			strValue = s
+		}
+	}
+
+	switch rule.Condition {
+	case "eq":
+		if rule.Value == nil || strValue == "" && isString(rule.Value) {
+			return "", nil, false // Skip empty eq filters
+		}
+		sql, args := q.equals(property, rule.Value)
+		return sql, args, true
+	case "neq":
+		if rule.Value == nil || strValue == "" && isString(rule.Value) {
+			return "", nil, false // Skip empty neq filters
+		}
+		sql, args := q.equals(property, rule.Value)
+		return "(NOT " + sql + " OR " + q.text(property) + " IS NULL)", args, true
+	case "gt", "lt", "gte", "lte":
+		if rule.Value == nil || strValue == "" && isString(rule.Value) {
+			return "", nil, false
+		}
+		operator := map[string]string{"gt": ">", "lt": "<", "gte": ">=", "lte": "<="}[rule.Condition]
+		if number, isNumber := numericValue(rule.Value); isNumber {
+			return q.number(property) + " " + operator + " ?", []any{number}, true
+		}
+		if dateValue.MatchString(strValue) {
+			return q.date(property) + " " + operator + " " + q.dateParam(), []any{strValue}, true
+		}
+		return q.text(property) + " " + operator + " ?", []any{strValue}, true
+	case "contains":
+		if strValue == "" {
+			return "", nil, false // Skip empty contains filters
+		}
+		return q.like(property, false), []any{"%" + escapeLike(strValue) + "%"}, true
+	case "not_contains":
+		if strValue == "" {
+			return "", nil, false // Skip empty not_contains filters
+		}
+		return "(" + q.like(property, true) + " OR " + q.text(property) + " IS NULL)", []any{"%" + escapeLike(strValue) + "%"}, true
+	case "starts_with":
+		if strValue == "" {
+			return "", nil, false // Skip empty starts_with filters
+		}
+		return q.like(property, false), []any{escapeLike(strValue) + "%"}, true
+	case "ends_with":
+		if strValue == "" {
+			return "", nil, false // Skip empty ends_with filters
+		}
+		return q.like(property, false), []any{"%" + escapeLike(strValue)}, true
+	case domain.FilterIsAnyOf, domain.FilterIsNoneOf:
+		var conditions []string
+		for _, value := range filterValues(rule.Value) {
+			condition, valueArgs := q.equals(property, value)
+			conditions = append(conditions, condition)
+			args = append(args, valueArgs...)
+		}
+		if len(conditions) == 0 {
+			return "", nil, false
+		}
+		sql = "(" + strings.Join(conditions, " OR ") + ")"
+		if rule.Condition == domain.FilterIsNoneOf {
+			sql = "(NOT " + sql + " OR " + q.text(property) + " IS NULL)"
+		}
+		return sql, args, true
+	case domain.FilterBetween:
+		from, to, ok := rangeBounds(rule.Value)
+		if !ok {
+			return "", nil, false
+		}
+		var conditions []string
+		if number, isNumber := numericValue(from); isNumber {
+			conditions = append(conditions, q.number(property)+" >= ?")
+			args = append(args, number)
+		}
+		if number, isNumber := numericValue(to); isNumber {
+			conditions = append(conditions, q.number(property)+" <= ?")
+			args = append(args, number)
+		}
+		if len(conditions) == 0 {
+			return "", nil, false
+		}
+		return "(" + strings.Join(conditions, " AND ") + ")", args, true
+	case "is_empty":
+		return "(" + q.text(property) + " IS NULL OR " + q.text(property) + " IN ('', '[]'))", nil, true
+	case "is_not_empty":
+		return "(" + q.text(property) + " IS NOT NULL AND " + q.text(property) + " NOT IN ('', '[]'))", nil, true
+	default:
+		return "", nil, false
+	}
+}
+
+// equalityCandidates returns the values a property may be stored as for an
+// equality filter: numbers typed in a text input are matched both as JSON
+// numbers and as strings.
+func equalityCandidates(value any) []any {
+	switch v := value.(type) {
+	case string:
+		if number, err := strconv.ParseFloat(strings.TrimSpace(v), 64); err == nil {
+			return []any{v, number}
+		}
+	case float64:
+		return []any{v, strconv.FormatFloat(v, 'f', -1, 64)}
+	}
+	return []any{value}
+}
+
+// filterValues returns the values of a list condition; a single value is a
+// list of one value
+func filterValues(value any) []any {
+	switch v := value.(type) {
+	case nil:
+		return nil
+	case []any:
+		values := make([]any, 0, len(v))
+		for _, item := range v {
+			if item != nil && item != "" {
+				values = append(values, item)
+			}
+		}
+		return values
+	case string:
+		if v == "" {
+			return nil
+		}
+	}
+	return []any{value}
+}
+
+// rangeBounds returns the bounds of a [from, to] range value
+func rangeBounds(value any) (from, to any, ok bool) {
+	bounds, isList := value.([]any)
+	if !isList || len(bounds) != 2 {
+		return nil, nil, false
+	}
+	return bounds[0], bounds[1], true
+}
+
+// dateBounds returns the [start, end) range of a date value: the whole day
+// (UTC) for a date, one second for a date and time
+func dateBounds(value any) (start, end time.Time, ok bool) {
+	s, _ := value.(string)
+	if day, err := time.Parse(time.DateOnly, s); err == nil {
+		return day, day.AddDate(0, 0, 1), true
+	}
+	if instant, err := time.Parse(time.RFC3339, s); err == nil {
+		instant = instant.UTC().Truncate(time.Second)
+		return instant, instant.Add(time.Second), true
+	}
+	return time.Time{}, time.Time{}, false
+}
+
+func numericValue(value any) (float64, bool) {
+	switch v := value.(type) {
+	case float64:
+		return v, true
+	case int:
+		return float64(v), true
+	case string:
+		number, err := strconv.ParseFloat(strings.TrimSpace(v), 64)
+		return number, err == nil
+	}
+	return 0, false
+}
+
+func escapeLike(value string) string {
+	return strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(value)
+}
+
+func isString(value any) bool {
+	_, ok := value.(string)
+	return ok
+}
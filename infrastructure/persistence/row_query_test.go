@@ -0,0 +1,354 @@
+package persistence
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/labbs/nexo/domain"
+)
+
+// The expressions of the property "p" on each dialect
+const (
+	sqliteText   = `json_extract(properties, '$."p"')`
+	sqliteNumber = `CAST(json_extract(properties, '$."p"') AS REAL)`
+	sqliteDate   = `datetime(json_extract(properties, '$."p"'))`
+	sqliteEach   = `EXISTS (SELECT 1 FROM json_each(properties, '$."p"') WHERE json_each.value = ?)`
+
+	postgresText   = `(properties ->> 'p')`
+	postgresNumber = `(CASE WHEN (properties ->> 'p') ~ '^\s*-{0,1}[0-9]+(\.[0-9]+){0,1}\s*$' THEN (properties ->> 'p')::numeric END)`
+	postgresDate   = `(CASE WHEN (properties ->> 'p') ~ '^\d{4}-\d{2}-\d{2}$' THEN ((properties ->> 'p') || 'T00:00:00Z')::timestamptz` +
+		` WHEN (properties ->> 'p') ~ '^\d{4}-\d{2}-\d{2}' THEN (properties ->> 'p')::timestamptz END)`
+	postgresEach = `(properties @> ?::jsonb OR properties @> ?::jsonb)`
+)
+
+// dialectSQL is the condition expected on a dialect
+type dialectSQL struct {
+	sql  string
+	args []any
+}
+
+func rule(propertyType domain.PropertyType, condition string, value any) domain.FilterRule {
+	return domain.FilterRule{Property: "p", Type: propertyType, Condition: condition, Value: value}
+}
+
+func TestRowQueryConditions(t *testing.T) {
+	tests := []struct {
+		name             string
+		rule             domain.FilterRule
+		sqlite, postgres dialectSQL
+	}{
+		// Text, number and select properties
+		{
+			"eq",
+			rule(domain.PropertyTypeText, domain.FilterEq, "a"),
+			dialectSQL{"(" + sqliteText + " = ?)", []any{"a"}},
+			dialectSQL{"(properties @> ?::jsonb)", []any{`{"p":"a"}`}},
+		},
+		{
+			"eq number",
+			rule(domain.PropertyTypeNumber, domain.FilterEq, "42"),
+			dialectSQL{"(" + sqliteText + " = ? OR " + sqliteText + " = ?)", []any{"42", float64(42)}},
+			dialectSQL{"(properties @> ?::jsonb OR properties @> ?::jsonb)", []any{`{"p":"42"}`, `{"p":42}`}},
+		},
+		{
+			"neq",
+			rule(domain.PropertyTypeText, domain.FilterNeq, "a"),
+			dialectSQL{"(NOT (" + sqliteText + " = ?) OR " + sqliteText + " IS NULL)", []any{"a"}},
+			dialectSQL{"(NOT (properties @> ?::jsonb) OR " + postgresText + " IS NULL)", []any{`{"p":"a"}`}},
+		},
+		{
+			"gt number",
+			rule(domain.PropertyTypeNumber, domain.FilterGt, float64(5)),
+			dialectSQL{sqliteNumber + " > ?", []any{float64(5)}},
+			dialectSQL{postgresNumber + " > ?", []any{float64(5)}},
+		},
+		{
+			"lte date",
+			rule(domain.PropertyTypeText, domain.FilterLte, "2026-10-17"),
+			dialectSQL{sqliteDate + " <= datetime(?)", []any{"2026-10-17"}},
+			dialectSQL{postgresDate + " <= ?::timestamptz", []any{"2026-10-17"}},
+		},
+		{
+			"lt text",
+			rule(domain.PropertyTypeText, domain.FilterLt, "b"),
+			dialectSQL{sqliteText + " < ?", []any{"b"}},
+			dialectSQL{postgresText + " < ?", []any{"b"}},
+		},
+		{
+			"contains",
+			rule(domain.PropertyTypeText, domain.FilterContains, `50%_\`),
+			dialectSQL{sqliteText + ` LIKE ? ESCAPE '\'`, []any{`%50\%\_\\%`}},
+			dialectSQL{postgresText + ` ILIKE ? ESCAPE '\'`, []any{`%50\%\_\\%`}},
+		},
+		{
+			"not_contains",
+			rule(domain.PropertyTypeText, domain.FilterNotContains, "a"),
+			dialectSQL{"(" + sqliteText + ` NOT LIKE ? ESCAPE '\' OR ` + sqliteText + " IS NULL)", []any{"%a%"}},
+			dialectSQL{"(" + postgresText + ` NOT ILIKE ? ESCAPE '\' OR ` + postgresText + " IS NULL)", []any{"%a%"}},
+		},
+		{
+			"starts_with",
+			rule(domain.PropertyTypeText, domain.FilterStartsWith, "a"),
+			dialectSQL{sqliteText + ` LIKE ? ESCAPE '\'`, []any{"a%"}},
+			dialectSQL{postgresText + ` ILIKE ? ESCAPE '\'`, []any{"a%"}},
+		},
+		{
+			"ends_with",
+			rule(domain.PropertyTypeText, domain.FilterEndsWith, "a"),
+			dialectSQL{sqliteText + ` LIKE ? ESCAPE '\'`, []any{"%a"}},
+			dialectSQL{postgresText + ` ILIKE ? ESCAPE '\'`, []any{"%a"}},
+		},
+		{
+			"is_any_of",
+			rule(domain.PropertyTypeSelect, domain.FilterIsAnyOf, []any{"a", "", "b"}),
+			dialectSQL{"((" + sqliteText + " = ?) OR (" + sqliteText + " = ?))", []any{"a", "b"}},
+			dialectSQL{"((properties @> ?::jsonb) OR (properties @> ?::jsonb))", []any{`{"p":"a"}`, `{"p":"b"}`}},
+		},
+		{
+			"is_none_of",
+			rule(domain.PropertyTypeSelect, domain.FilterIsNoneOf, "a"),
+			dialectSQL{"(NOT ((" + sqliteText + " = ?)) OR " + sqliteText + " IS NULL)", []any{"a"}},
+			dialectSQL{"(NOT ((properties @> ?::jsonb)) OR " + postgresText + " IS NULL)", []any{`{"p":"a"}`}},
+		},
+		{
+			"between",
+			rule(domain.PropertyTypeNumber, domain.FilterBetween, []any{float64(1), "10"}),
+			dialectSQL{"(" + sqliteNumber + " >= ? AND " + sqliteNumber + " <= ?)", []any{float64(1), float64(10)}},
+			dialectSQL{"(" + postgresNumber + " >= ? AND " + postgresNumber + " <= ?)", []any{float64(1), float64(10)}},
+		},
+		{
+			"between open",
+			rule(domain.PropertyTypeNumber, domain.FilterBetween, []any{nil, float64(10)}),
+			dialectSQL{"(" + sqliteNumber + " <= ?)", []any{float64(10)}},
+			dialectSQL{"(" + postgresNumber + " <= ?)", []any{float64(10)}},
+		},
+		{
+			"is_empty",
+			rule(domain.PropertyTypeMultiSelect, domain.FilterIsEmpty, nil),
+			dialectSQL{"(" + sqliteText + " IS NULL OR " + sqliteText + " IN ('', '[]'))", nil},
+			dialectSQL{"(" + postgresText + " IS NULL OR " + postgresText + " IN ('', '[]'))", nil},
+		},
+		{
+			"is_not_empty",
+			rule(domain.PropertyTypeDate, domain.FilterIsNotEmpty, nil),
+			dialectSQL{"(" + sqliteText + " IS NOT NULL AND " + sqliteText + " NOT IN ('', '[]'))", nil},
+			dialectSQL{"(" + postgresText + " IS NOT NULL AND " + postgresText + " NOT IN ('', '[]'))", nil},
+		},
+
+		// Checkboxes
+		{
+			"is_checked",
+			rule(domain.PropertyTypeCheckbox, domain.FilterIsChecked, nil),
+			dialectSQL{`json_type(properties, '$."p"') IS 'true'`, nil},
+			dialectSQL{"properties @> ?::jsonb", []any{`{"p":true}`}},
+		},
+		{
+			"is_not_checked",
+			rule(domain.PropertyTypeCheckbox, domain.FilterIsNotChecked, nil),
+			dialectSQL{`NOT (json_type(properties, '$."p"') IS 'true')`, nil},
+			dialectSQL{"NOT (properties @> ?::jsonb)", []any{`{"p":true}`}},
+		},
+		{
+			"checkbox eq",
+			rule(domain.PropertyTypeCheckbox, domain.FilterEq, "false"),
+			dialectSQL{`NOT (json_type(properties, '$."p"') IS 'true')`, nil},
+			dialectSQL{"NOT (properties @> ?::jsonb)", []any{`{"p":true}`}},
+		},
+		{
+			"formula is_checked",
+			rule(domain.PropertyTypeFormula, domain.FilterIsChecked, nil),
+			dialectSQL{`json_type(properties, '$."p"') IS 'true'`, nil},
+			dialectSQL{"properties @> ?::jsonb", []any{`{"p":true}`}},
+		},
+
+		// Lists
+		{
+			"contains_any",
+			rule(domain.PropertyTypeMultiSelect, domain.FilterContainsAny, []any{"a", "b"}),
+			dialectSQL{"(" + sqliteEach + " OR " + sqliteEach + ")", []any{"a", "b"}},
+			dialectSQL{"(" + postgresEach + " OR " + postgresEach + ")", []any{`{"p":["a"]}`, `{"p":"a"}`, `{"p":["b"]}`, `{"p":"b"}`}},
+		},
+		{
+			"contains_all",
+			rule(domain.PropertyTypeRelation, domain.FilterContainsAll, []any{"a", "b"}),
+			dialectSQL{"(" + sqliteEach + " AND " + sqliteEach + ")", []any{"a", "b"}},
+			dialectSQL{"(" + postgresEach + " AND " + postgresEach + ")", []any{`{"p":["a"]}`, `{"p":"a"}`, `{"p":["b"]}`, `{"p":"b"}`}},
+		},
+		{
+			"list not_contains",
+			rule(domain.PropertyTypePerson, domain.FilterNotContains, "a"),
+			dialectSQL{"NOT (" + sqliteEach + ")", []any{"a"}},
+			dialectSQL{"NOT (" + postgresEach + ")", []any{`{"p":["a"]}`, `{"p":"a"}`}},
+		},
+
+		// Dates
+		{
+			"date eq",
+			rule(domain.PropertyTypeDate, domain.FilterEq, "2026-10-17"),
+			dialectSQL{"(" + sqliteDate + " >= datetime(?) AND " + sqliteDate + " < datetime(?))", []any{"2026-10-17T00:00:00Z", "2026-10-18T00:00:00Z"}},
+			dialectSQL{"(" + postgresDate + " >= ?::timestamptz AND " + postgresDate + " < ?::timestamptz)", []any{"2026-10-17T00:00:00Z", "2026-10-18T00:00:00Z"}},
+		},
+		{
+			"before",
+			rule(domain.PropertyTypeDate, domain.FilterBefore, "2026-10-17"),
+			dialectSQL{"(" + sqliteDate + " < datetime(?))", []any{"2026-10-17T00:00:00Z"}},
+			dialectSQL{"(" + postgresDate + " < ?::timestamptz)", []any{"2026-10-17T00:00:00Z"}},
+		},
+		{
+			"after",
+			rule(domain.PropertyTypeDate, domain.FilterAfter, "2026-10-17"),
+			dialectSQL{"(" + sqliteDate + " >= datetime(?))", []any{"2026-10-18T00:00:00Z"}},
+			dialectSQL{"(" + postgresDate + " >= ?::timestamptz)", []any{"2026-10-18T00:00:00Z"}},
+		},
+		{
+			"on_or_before time",
+			rule(domain.PropertyTypeDate, domain.FilterOnOrBefore, "2026-10-17T10:30:00+02:00"),
+			dialectSQL{"(" + sqliteDate + " < datetime(?))", []any{"2026-10-17T08:30:01Z"}},
+			dialectSQL{"(" + postgresDate + " < ?::timestamptz)", []any{"2026-10-17T08:30:01Z"}},
+		},
+		{
+			"date between",
+			rule(domain.PropertyTypeDate, domain.FilterBetween, []any{"2026-10-01", "2026-10-31"}),
+			dialectSQL{"(" + sqliteDate + " >= datetime(?) AND " + sqliteDate + " < datetime(?))", []any{"2026-10-01T00:00:00Z", "2026-11-01T00:00:00Z"}},
+			dialectSQL{"(" + postgresDate + " >= ?::timestamptz AND " + postgresDate + " < ?::timestamptz)", []any{"2026-10-01T00:00:00Z", "2026-11-01T00:00:00Z"}},
+		},
+
+		// Row columns
+		{
+			"created_time",
+			domain.FilterRule{Property: "c", Type: domain.PropertyTypeCreatedTime, Condition: domain.FilterOnOrAfter, Value: "2026-10-17"},
+			dialectSQL{"(datetime(created_at) >= datetime(?))", []any{"2026-10-17T00:00:00Z"}},
+			dialectSQL{"(created_at >= ?::timestamptz)", []any{"2026-10-17T00:00:00Z"}},
+		},
+		{
+			"created_by",
+			domain.FilterRule{Property: "c", Type: domain.PropertyTypeCreatedBy, Condition: domain.FilterNeq, Value: "u1"},
+			dialectSQL{"created_by <> ?", []any{"u1"}},
+			dialectSQL{"created_by <> ?", []any{"u1"}},
+		},
+		{
+			"updated_by",
+			domain.FilterRule{Property: "invalid name", Type: domain.PropertyTypeUpdatedBy, Condition: domain.FilterIsAnyOf, Value: []any{"u1", "u2"}},
+			dialectSQL{"updated_by IN ?", []any{[]any{"u1", "u2"}}},
+			dialectSQL{"updated_by IN ?", []any{[]any{"u1", "u2"}}},
+		},
+	}
+
+	for _, tt := range tests {
+		for dialect, want := range map[string]dialectSQL{"sqlite": tt.sqlite, "postgres": tt.postgres} {
+			sql, args, ok := newRowQuery(dialect).condition(tt.rule)
+			if !ok {
+				t.Errorf("%s (%s): condition is ignored", tt.name, dialect)
+				continue
+			}
+			if sql != want.sql {
+				t.Errorf("%s (%s): sql =\n%s\nwant\n%s", tt.name, dialect, sql, want.sql)
+			}
+			if !reflect.DeepEqual(args, want.args) {
+				t.Errorf("%s (%s): args = %#v, want %#v", tt.name, dialect, args, want.args)
+			}
+		}
+	}
+}
+
+func TestRowQueryIgnoredConditions(t *testing.T) {
+	tests := []struct {
+		name string
+		rule domain.FilterRule
+	}{
+		{"empty eq", rule(domain.PropertyTypeText, domain.FilterEq, "")},
+		{"nil gt", rule(domain.PropertyTypeNumber, domain.FilterGt, nil)},
+		{"empty contains", rule(domain.PropertyTypeText, domain.FilterContains, "")},
+		{"empty is_any_of", rule(domain.PropertyTypeSelect, domain.FilterIsAnyOf, []any{""})},
+		{"between without bounds", rule(domain.PropertyTypeNumber, domain.FilterBetween, []any{nil, "x"})},
+		{"between of one value", rule(domain.PropertyTypeNumber, domain.FilterBetween, []any{float64(1)})},
+		{"unknown condition", rule(domain.PropertyTypeText, "matches", "a")},
+		{"checkbox eq", rule(domain.PropertyTypeCheckbox, domain.FilterEq, "maybe")},
+		{"empty list", rule(domain.PropertyTypeMultiSelect, domain.FilterContains, []any{})},
+		{"list gt", rule(domain.PropertyTypeMultiSelect, domain.FilterGt, "a")},
+		{"invalid date", rule(domain.PropertyTypeDate, domain.FilterBefore, "tomorrow")},
+		{"invalid relative date", rule(domain.PropertyTypeDate, domain.FilterRelative, "someday")},
+		{"date contains", rule(domain.PropertyTypeDate, domain.FilterContains, "2026")},
+		{"empty created_by", domain.FilterRule{Type: domain.PropertyTypeCreatedBy, Condition: domain.FilterEq, Value: ""}},
+		{"created_by contains", domain.FilterRule{Type: domain.PropertyTypeCreatedBy, Condition: domain.FilterContains, Value: "u"}},
+	}
+
+	for _, tt := range tests {
+		for _, dialect := range []string{"sqlite", "postgres"} {
+			if sql, args, ok := newRowQuery(dialect).condition(tt.rule); ok {
+				t.Errorf("%s (%s): condition = %s %v, want it ignored", tt.name, dialect, sql, args)
+			}
+		}
+	}
+}
+
+func TestRowQueryRejectsPropertyNames(t *testing.T) {
+	names := []string{"", "a b", "a'b", `a"b`, "a.b", "a)--", "p') OR 1=1 --", strings.Repeat("a", 101)}
+
+	for _, name := range names {
+		if isValidPropertyName(name) {
+			t.Errorf("isValidPropertyName(%q) = true", name)
+		}
+		for _, dialect := range []string{"sqlite", "postgres"} {
+			r := domain.FilterRule{Property: name, Type: domain.PropertyTypeText, Condition: domain.FilterEq, Value: "a"}
+			if sql, _, ok := newRowQuery(dialect).condition(r); ok {
+				t.Errorf("condition on %q (%s) = %s, want it ignored", name, dialect, sql)
+			}
+		}
+	}
+
+	for _, name := range []string{"a", "prop_1-B", strings.Repeat("a", 100)} {
+		if !isValidPropertyName(name) {
+			t.Errorf("isValidPropertyName(%q) = false", name)
+		}
+	}
+}
+
+func TestRowQueryGroup(t *testing.T) {
+	q := newRowQuery("sqlite")
+	eq := func(property, value string) domain.FilterRule {
+		return domain.FilterRule{Property: property, Type: domain.PropertyTypeText, Condition: domain.FilterEq, Value: value}
+	}
+	text := func(property string) string {
+		return `json_extract(properties, '$."` + property + `"')`
+	}
+
+	sql, args, ok := q.group(
+		[]domain.FilterRule{eq("a", "1"), eq("b", ""), {Or: []domain.FilterRule{eq("c", "x"), eq("d", "y")}}},
+		[]domain.FilterRule{eq("e", "z"), eq("f", "")},
+	)
+	a := "(" + text("a") + " = ? OR " + text("a") + " = ?)"
+	nested := "(((" + text("c") + " = ?) OR (" + text("d") + " = ?)))"
+	or := "((" + text("e") + " = ?))"
+	want := "(" + a + " AND " + nested + " AND " + or + ")"
+	if !ok || sql != want {
+		t.Errorf("group sql =\n%s\nwant\n%s", sql, want)
+	}
+	if wantArgs := []any{"1", float64(1), "x", "y", "z"}; !reflect.DeepEqual(args, wantArgs) {
+		t.Errorf("group args = %#v, want %#v", args, wantArgs)
+	}
+
+	if sql, _, ok := q.group([]domain.FilterRule{eq("a", "")}, []domain.FilterRule{{Or: []domain.FilterRule{eq("b", "")}}}); ok {
+		t.Errorf("group of ignored rules = %s, want it ignored", sql)
+	}
+}
+
+func TestRowQueryOrder(t *testing.T) {
+	tests := []struct {
+		dialect string
+		sort    domain.SortRule
+		want    string
+	}{
+		{"sqlite", domain.SortRule{PropertyId: "p", Direction: "desc"}, `json_extract(properties, '$."p"') DESC NULLS LAST`},
+		{"sqlite", domain.SortRule{PropertyId: "p"}, `json_extract(properties, '$."p"') ASC NULLS LAST`},
+		{"postgres", domain.SortRule{PropertyId: "p", Direction: "asc"}, `(properties -> 'p') ASC NULLS LAST`},
+		{"postgres", domain.SortRule{PropertyId: "p", Direction: "DESC; DROP TABLE x"}, `(properties -> 'p') ASC NULLS LAST`},
+	}
+
+	for _, tt := range tests {
+		if got := newRowQuery(tt.dialect).order(tt.sort); got != tt.want {
+			t.Errorf("order(%+v) on %s = %s, want %s", tt.sort, tt.dialect, got, tt.want)
+		}
+	}
+}
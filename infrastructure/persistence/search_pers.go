@@ -0,0 +1,423 @@
+package persistence
+
+import (
+	"sort"
+	"strings"
+	"sync"
+	"unicode/utf8"
+
+	"github.com/labbs/nexo/domain"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Without the fts5 module (binary built without the sqlite_fts5 tag), SQLite
+// searches fall back to LIKE on the most recent candidates, ranked in Go
+const likeSearchCandidates = 500
+
+type searchPers struct {
+	db *gorm.DB
+
+	ftsOnce sync.Once
+	fts     bool
+}
+
+func NewSearchPers(db *gorm.DB) *searchPers {
+	return &searchPers{db: db}
+}
+
+// searchHitRow is a search hit as scanned from the search queries
+type searchHitRow struct {
+	domain.SearchEntry
+	SpaceName  string
+	Slug       string
+	SearchRank float64
+	Snippet    string
+}
+
+func (p *searchPers) Index(entry *domain.SearchEntry) error {
+	// Set up the SQLite FTS triggers before writing
+	p.sqliteFts()
+
+	if entry.Blocks == nil {
+		entry.Blocks = domain.JSONBArray{}
+	}
+	return p.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "resource_type"}, {Name: "resource_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"space_id", "document_id", "database_id", "title", "icon", "content", "blocks", "updated_at"}),
+	}).Omit("id").Create(entry).Error
+}
+
+func (p *searchPers) Remove(resourceType domain.SearchResourceType, resourceIds []string) error {
+	if len(resourceIds) == 0 {
+		return nil
+	}
+	p.sqliteFts()
+	return p.db.
+		Where("resource_type = ? AND resource_id IN ?", resourceType, resourceIds).
+		Delete(&domain.SearchEntry{}).Error
+}
+
+func (p *searchPers) Search(query domain.SearchQuery) ([]domain.SearchHit, error) {
+	if len(query.Terms) == 0 {
+		return []domain.SearchHit{}, nil
+	}
+
+	if query.Limit <= 0 || query.Limit > 50 {
+		query.Limit = 20
+	}
+	if query.Offset < 0 {
+		query.Offset = 0
+	}
+
+	var rows []searchHitRow
+	var err error
+
+	switch {
+	case p.db.Dialector.Name() == "postgres":
+		rows, err = p.searchPostgres(query)
+	case p.sqliteFts():
+		rows, err = p.searchFts(query)
+	default:
+		rows, err = p.searchLike(query)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	hits := make([]domain.SearchHit, len(rows))
+	for i, row := range rows {
+		hits[i] = domain.SearchHit{
+			ResourceType: row.ResourceType,
+			ResourceId:   row.ResourceId,
+			SpaceId:      row.SpaceId,
+			SpaceName:    row.SpaceName,
+			DocumentId:   row.DocumentId,
+			DatabaseId:   row.DatabaseId,
+			Title:        row.Title,
+			Snippet:      row.Snippet,
+			Rank:         row.SearchRank,
+			Blocks:       row.Blocks,
+			Slug:         row.Slug,
+			Icon:         row.Icon,
+			UpdatedAt:    row.UpdatedAt,
+		}
+	}
+
+	return hits, nil
+}
+
+func (p *searchPers) searchPostgres(query domain.SearchQuery) ([]searchHitRow, error) {
+	prefixes := make([]string, len(query.Terms))
+	for i, term := range query.Terms {
+		prefixes[i] = term + ":*"
+	}
+	tsQuery := strings.Join(prefixes, " & ")
+	headlineOptions := "StartSel=" + domain.SearchHighlightStart + ", StopSel=" + domain.SearchHighlightEnd +
+		", MinWords=8, MaxWords=24, MaxFragments=2, FragmentDelimiter=\" … \""
+
+	var rows []searchHitRow
+	err := p.readableEntries(query).
+		Select(searchHitColumns+
+			", ts_rank(search_index.search_vector, to_tsquery('simple', ?)) AS search_rank"+
+			", ts_headline('simple', search_index.content, to_tsquery('simple', ?), ?) AS snippet",
+			tsQuery, tsQuery, headlineOptions).
+		Where("search_index.search_vector @@ to_tsquery('simple', ?)", tsQuery).
+		Order("search_rank DESC").
+		Order("search_index.updated_at DESC").
+		Offset(query.Offset).
+		Limit(query.Limit).
+		Scan(&rows).Error
+
+	return rows, err
+}
+
+func (p *searchPers) searchFts(query domain.SearchQuery) ([]searchHitRow, error) {
+	phrases := make([]string, len(query.Terms))
+	for i, term := range query.Terms {
+		phrases[i] = `"` + term + `"*`
+	}
+
+	var rows []searchHitRow
+	err := p.readableEntries(query).
+		Select(searchHitColumns+
+			", -bm25(search_index_fts, 10.0, 1.0) AS search_rank"+
+			", snippet(search_index_fts, 1, ?, ?, '…', 16) AS snippet",
+			domain.SearchHighlightStart, domain.SearchHighlightEnd).
+		Joins("JOIN search_index_fts ON search_index_fts.rowid = search_index.id").
+		Where("search_index_fts MATCH ?", strings.Join(phrases, " ")).
+		Order("search_rank DESC").
+		Order("search_index.updated_at DESC").
+		Offset(query.Offset).
+		Limit(query.Limit).
+		Scan(&rows).Error
+
+	return rows, err
+}
+
+func (p *searchPers) searchLike(query domain.SearchQuery) ([]searchHitRow, error) {
+	dbQuery := p.readableEntries(query).Select(searchHitColumns)
+	for _, term := range query.Terms {
+		pattern := "%" + term + "%"
+		dbQuery = dbQuery.Where("(search_index.title LIKE ? OR search_index.content LIKE ?)", pattern, pattern)
+	}
+
+	var rows []searchHitRow
+	err := dbQuery.
+		Order("search_index.updated_at DESC").
+		Limit(likeSearchCandidates).
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range rows {
+		title := strings.ToLower(rows[i].Title)
+		content := strings.ToLower(rows[i].Content)
+		for _, term := range query.Terms {
+			rows[i].SearchRank += 10*float64(strings.Count(title, term)) + float64(strings.Count(content, term))
+		}
+		rows[i].Snippet = likeSnippet(rows[i].Content, query.Terms)
+	}
+	sort.SliceStable(rows, func(i, j int) bool { return rows[i].SearchRank > rows[j].SearchRank })
+
+	if query.Offset >= len(rows) {
+		return []searchHitRow{}, nil
+	}
+	rows = rows[query.Offset:]
+	if len(rows) > query.Limit {
+		rows = rows[:query.Limit]
+	}
+	return rows, nil
+}
+
+const searchHitColumns = "search_index.id, search_index.resource_type, search_index.resource_id, " +
+	"search_index.space_id, search_index.document_id, search_index.database_id, " +
+	"search_index.title, search_index.icon, search_index.content, search_index.blocks, search_index.updated_at, " +
+	"space.name AS space_name, COALESCE(document.slug, '') AS slug"
+
+// readableEntries selects the entries of the query filters that the user can
+// read: documents and their comments with the permissions inherited down the
+// document tree, rows with the permissions of their database and drawings
+// with their own, each falling back to the access to the space. Entries of
+// trashed documents or deleted databases are excluded.
+func (p *searchPers) readableEntries(query domain.SearchQuery) *gorm.DB {
+	userId := query.UserId
+
+	// Subquery: space IDs the user can access (public, owned, or via user/group permission)
+	accessibleSpaceIds := accessibleSpaceIds(p.db, userId)
+
+	documentAccess := p.db.Where("search_index.document_id IN (?)", readableDocumentIds(p.db, userId))
+	databaseAccess := p.resourceAccess(userId, accessibleSpaceIds, domain.PermissionTypeDatabase, "database_id", "search_index.database_id")
+	drawingAccess := p.resourceAccess(userId, accessibleSpaceIds, domain.PermissionTypeDrawing, "drawing_id", "search_index.resource_id")
+
+	dbQuery := p.db.Table("search_index").
+		Joins("LEFT JOIN space ON space.id = search_index.space_id").
+		Joins("LEFT JOIN document ON document.id = search_index.document_id").
+		Joins("LEFT JOIN database ON database.id = search_index.database_id").
+		Joins("LEFT JOIN drawing ON drawing.id = search_index.resource_id AND search_index.resource_type = ?", domain.SearchResourceDrawing).
+		Where("document.deleted_at IS NULL AND database.deleted_at IS NULL AND drawing.deleted_at IS NULL").
+		Where(
+			p.db.Where(p.db.Where("search_index.resource_type IN ?", []domain.SearchResourceType{domain.SearchResourceDocument, domain.SearchResourceComment}).Where(documentAccess)).
+				Or(p.db.Where("search_index.resource_type = ?", domain.SearchResourceRow).Where(databaseAccess)).
+				Or(p.db.Where("search_index.resource_type = ?", domain.SearchResourceDrawing).Where(drawingAccess)),
+		)
+
+	if query.SpaceId != nil {
+		dbQuery = dbQuery.Where("search_index.space_id = ?", *query.SpaceId)
+	}
+	if len(query.Types) > 0 {
+		dbQuery = dbQuery.Where("search_index.resource_type IN ?", query.Types)
+	}
+
+	return dbQuery
+}
+
+// resourceAccess matches the resources of a permission type that the user is
+// not denied and has an explicit permission on or can reach through the space
+func (p *searchPers) resourceAccess(userId string, accessibleSpaceIds *gorm.DB, permissionType domain.PermissionType, permissionColumn, idColumn string) *gorm.DB {
+	// Subquery: resource IDs where user is explicitly denied
+	deniedIds := deniedResourceIds(p.db, userId, permissionType, permissionColumn)
+
+	// Subquery: resource IDs where user has explicit access (viewer+)
+	grantedIds := grantedResourceIds(p.db, userId, permissionType, permissionColumn)
+
+	return p.db.
+		Where(idColumn+" NOT IN (?)", deniedIds).
+		Where(
+			p.db.Where(idColumn+" IN (?)", grantedIds).
+				Or("search_index.space_id IN (?)", accessibleSpaceIds),
+		)
+}
+
+// sqliteFts creates the FTS5 table over search_index and the triggers keeping
+// it in sync on first use, and reports whether it is available. Without the
+// fts5 module, the triggers left by a build that had it are dropped, as they
+// would make every write to search_index fail.
+func (p *searchPers) sqliteFts() bool {
+	if p.db.Dialector.Name() != "sqlite" {
+		return false
+	}
+
+	p.ftsOnce.Do(func() {
+		err := p.db.Exec(`CREATE VIRTUAL TABLE IF NOT EXISTS search_index_fts USING fts5(
+			title, content,
+			content='search_index', content_rowid='id',
+			tokenize='unicode61 remove_diacritics 2'
+		)`).Error
+		if err == nil {
+			err = p.db.Exec("SELECT rowid FROM search_index_fts LIMIT 1").Error
+		}
+		if err != nil {
+			for _, trigger := range searchFtsTriggers {
+				p.db.Exec("DROP TRIGGER IF EXISTS " + trigger)
+			}
+			return
+		}
+
+		var triggers int64
+		p.db.Raw("SELECT COUNT(*) FROM sqlite_master WHERE type = 'trigger' AND name IN ?", searchFtsTriggers).Scan(&triggers)
+		if triggers == int64(len(searchFtsTriggers)) {
+			p.fts = true
+			return
+		}
+
+		// New table, or entries written without the triggers: rebuild
+		err = p.db.Transaction(func(tx *gorm.DB) error {
+			statements := []string{
+				`DROP TRIGGER IF EXISTS search_index_fts_insert`,
+				`DROP TRIGGER IF EXISTS search_index_fts_delete`,
+				`DROP TRIGGER IF EXISTS search_index_fts_update`,
+				`CREATE TRIGGER search_index_fts_insert AFTER INSERT ON search_index BEGIN
+					INSERT INTO search_index_fts(rowid, title, content) VALUES (new.id, new.title, new.content);
+				END`,
+				`CREATE TRIGGER search_index_fts_delete AFTER DELETE ON search_index BEGIN
+					INSERT INTO search_index_fts(search_index_fts, rowid, title, content) VALUES ('delete', old.id, old.title, old.content);
+				END`,
+				`CREATE TRIGGER search_index_fts_update AFTER UPDATE ON search_index BEGIN
+					INSERT INTO search_index_fts(search_index_fts, rowid, title, content) VALUES ('delete', old.id, old.title, old.content);
+					INSERT INTO search_index_fts(rowid, title, content) VALUES (new.id, new.title, new.content);
+				END`,
+				`INSERT INTO search_index_fts(search_index_fts) VALUES ('rebuild')`,
+			}
+			for _, statement := range statements {
+				if err := tx.Exec(statement).Error; err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+		p.fts = err == nil
+	})
+	return p.fts
+}
+
+var searchFtsTriggers = []string{"search_index_fts_insert", "search_index_fts_delete", "search_index_fts_update"}
+
+// likeSnippet returns the text around the first matched term, with the
+// matched terms between the highlight markers
+func likeSnippet(content string, terms []string) string {
+	const before, length = 60, 200
+
+	lower := strings.ToLower(content)
+	start := -1
+	for _, term := range terms {
+		if i := strings.Index(lower, term); i >= 0 && (start < 0 || i < start) {
+			start = i
+		}
+	}
+	if start < 0 || len(lower) != len(content) {
+		// No match in the content, or a case mapping changing the byte
+		// length: no highlight
+		return truncateRunes(content, length)
+	}
+
+	from := max(start-before, 0)
+	for from > 0 && !utf8.RuneStart(content[from]) {
+		from--
+	}
+	to := min(from+length, len(content))
+	for to < len(content) && !utf8.RuneStart(content[to]) {
+		to++
+	}
+
+	var b strings.Builder
+	if from > 0 {
+		b.WriteString("…")
+	}
+	segment, lowerSegment := content[from:to], lower[from:to]
+	for i := 0; i < len(segment); {
+		matched := ""
+		for _, term := range terms {
+			if strings.HasPrefix(lowerSegment[i:], term) && len(term) > len(matched) {
+				matched = term
+			}
+		}
+		if matched == "" {
+			b.WriteByte(segment[i])
+			i++
+			continue
+		}
+		b.WriteString(domain.SearchHighlightStart + segment[i:i+len(matched)] + domain.SearchHighlightEnd)
+		i += len(matched)
+	}
+	if to < len(content) {
+		b.WriteString("…")
+	}
+	return b.String()
+}
+
+func truncateRunes(s string, n int) string {
+	if utf8.RuneCountInString(s) <= n {
+		return s
+	}
+	return string([]rune(s)[:n]) + "…"
+}
+
+func (p *searchPers) UnindexedDocuments(limit int) ([]domain.Document, error) {
+	var docs []domain.Document
+	err := p.db.
+		Where("id NOT IN (?)", p.indexedIds(domain.SearchResourceDocument)).
+		Order("created_at").
+		Limit(limit).
+		Find(&docs).Error
+	return docs, err
+}
+
+func (p *searchPers) UnindexedRows(limit int) ([]domain.DatabaseRow, error) {
+	var rows []domain.DatabaseRow
+	err := p.db.
+		Preload("Database").
+		Where("id NOT IN (?)", p.indexedIds(domain.SearchResourceRow)).
+		Where("database_id IN (?)", p.db.Table("database").Select("id").Where("deleted_at IS NULL")).
+		Order("created_at").
+		Limit(limit).
+		Find(&rows).Error
+	return rows, err
+}
+
+func (p *searchPers) UnindexedDrawings(limit int) ([]domain.Drawing, error) {
+	var drawings []domain.Drawing
+	err := p.db.
+		Where("id NOT IN (?)", p.indexedIds(domain.SearchResourceDrawing)).
+		Order("created_at").
+		Limit(limit).
+		Find(&drawings).Error
+	return drawings, err
+}
+
+func (p *searchPers) UnindexedComments(limit int) ([]domain.Comment, error) {
+	var comments []domain.Comment
+	err := p.db.
+		Preload("Document").
+		Where("id NOT IN (?)", p.indexedIds(domain.SearchResourceComment)).
+		Where("document_id IN (?)", p.db.Table("document").Select("id").Where("deleted_at IS NULL")).
+		Order("created_at").
+		Limit(limit).
+		Find(&comments).Error
+	return comments, err
+}
+
+func (p *searchPers) indexedIds(resourceType domain.SearchResourceType) *gorm.DB {
+	return p.db.Table("search_index").Select("resource_id").Where("resource_type = ?", resourceType)
+}
@@ -24,30 +24,38 @@ func (s *spacePers) GetSpacesForUser(userId string) ([]domain.Space, error) {
 	var spaces []domain.Space
 
 	err := s.db.Preload("Owner").
+		Scopes(selectRequester(s.db, userId)).
 		Preload("Permissions", "type = ?", domain.PermissionTypeSpace).
 		Preload("Permissions.User").
 		Preload("Permissions.Group").
-		Where(
-			s.db.Where("owner_id = ?", userId).
-				Or("id IN (?)",
-					s.db.Table("permission").
-						Select("space_id").
-						Where("type = ? AND user_id = ? AND deleted_at IS NULL", domain.PermissionTypeSpace, userId),
-				).
-				Or("type = ?", domain.SpaceTypePublic),
-		).
+		Preload("Permissions.Group.Members", "id = ?", userId).
+		// Owned, public or granted to the user or their groups, unless denied.
+		// Public spaces are not listed for guests.
+		Where("id IN (?)", accessibleSpaceIds(s.db, userId)).
 		Find(&spaces).Error
 
 	return spaces, err
 }
 
 func (s *spacePers) GetSpaceById(spaceId string) (*domain.Space, error) {
+	return s.getSpace(s.db, spaceId)
+}
+
+func (s *spacePers) GetSpaceForUser(spaceId, userId string) (*domain.Space, error) {
+	return s.getSpace(s.db.Scopes(selectRequester(s.db, userId)), spaceId)
+}
+
+func (s *spacePers) getSpace(db *gorm.DB, spaceId string) (*domain.Space, error) {
 	var space domain.Space
 
-	err := s.db.Preload("Owner").
+	err := db.Preload("Owner").
 		Preload("Permissions", "type = ?", domain.PermissionTypeSpace).
 		Preload("Permissions.User").
 		Preload("Permissions.Group").
+		// The members of the groups resolve their roles
+		Preload("Permissions.Group.Members", func(db *gorm.DB) *gorm.DB {
+			return db.Select("id")
+		}).
 		First(&space, "id = ?", spaceId).Error
 
 	if err != nil {
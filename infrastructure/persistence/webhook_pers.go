@@ -89,9 +89,10 @@ func (p *webhookPers) IncrementSuccess(id string) error {
 	return p.db.Model(&domain.Webhook{}).
 		Where("id = ?", id).
 		Updates(map[string]any{
-			"success_count": gorm.Expr("success_count + 1"),
-			"last_error":    "",
-			"last_error_at": nil,
+			"success_count":        gorm.Expr("success_count + 1"),
+			"consecutive_failures": 0,
+			"last_error":           "",
+			"last_error_at":        nil,
 		}).Error
 }
 
@@ -100,12 +101,26 @@ func (p *webhookPers) RecordFailure(id string, errorMsg string) error {
 	return p.db.Model(&domain.Webhook{}).
 		Where("id = ?", id).
 		Updates(map[string]any{
-			"failure_count": gorm.Expr("failure_count + 1"),
-			"last_error":    errorMsg,
-			"last_error_at": &now,
+			"failure_count":        gorm.Expr("failure_count + 1"),
+			"consecutive_failures": gorm.Expr("consecutive_failures + 1"),
+			"last_error":           errorMsg,
+			"last_error_at":        &now,
 		}).Error
 }
 
+func (p *webhookPers) DisableIfFailing(id string, maxConsecutiveFailures int) (bool, error) {
+	result := p.db.Model(&domain.Webhook{}).
+		Where("id = ? AND active = ? AND consecutive_failures >= ?", id, true, maxConsecutiveFailures).
+		Updates(map[string]any{
+			"active":     false,
+			"updated_at": time.Now(),
+		})
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
+
 // WebhookDelivery persistence
 type webhookDeliveryPers struct {
 	db *gorm.DB
@@ -119,6 +134,21 @@ func (p *webhookDeliveryPers) Create(delivery *domain.WebhookDelivery) error {
 	return p.db.Create(delivery).Error
 }
 
+func (p *webhookDeliveryPers) GetById(id string) (*domain.WebhookDelivery, error) {
+	var delivery domain.WebhookDelivery
+	err := p.db.
+		Preload("Webhook").
+		Where("id = ?", id).
+		First(&delivery).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, apperrors.ErrNotFound
+		}
+		return nil, err
+	}
+	return &delivery, nil
+}
+
 func (p *webhookDeliveryPers) GetByWebhookId(webhookId string, limit int) ([]domain.WebhookDelivery, error) {
 	var deliveries []domain.WebhookDelivery
 	err := p.db.
@@ -131,3 +161,43 @@ func (p *webhookDeliveryPers) GetByWebhookId(webhookId string, limit int) ([]dom
 	}
 	return deliveries, nil
 }
+
+func (p *webhookDeliveryPers) GetDue(now time.Time, limit int) ([]domain.WebhookDelivery, error) {
+	var deliveries []domain.WebhookDelivery
+	err := p.db.
+		Preload("Webhook").
+		Where("status IN ? AND next_attempt_at <= ?", []domain.WebhookDeliveryStatus{
+			domain.WebhookDeliveryStatusPending,
+			domain.WebhookDeliveryStatusRetrying,
+			domain.WebhookDeliveryStatusDelivering, // lease expired (worker crashed or restarted)
+		}, now).
+		Order("next_attempt_at ASC").
+		Limit(limit).
+		Find(&deliveries).Error
+	if err != nil {
+		return nil, err
+	}
+	return deliveries, nil
+}
+
+func (p *webhookDeliveryPers) Claim(id string, now time.Time, leaseUntil time.Time) (bool, error) {
+	result := p.db.Model(&domain.WebhookDelivery{}).
+		Where("id = ? AND status IN ? AND next_attempt_at <= ?", id, []domain.WebhookDeliveryStatus{
+			domain.WebhookDeliveryStatusPending,
+			domain.WebhookDeliveryStatusRetrying,
+			domain.WebhookDeliveryStatusDelivering,
+		}, now).
+		Updates(map[string]any{
+			"status":          domain.WebhookDeliveryStatusDelivering,
+			"next_attempt_at": leaseUntil,
+			"updated_at":      now,
+		})
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
+
+func (p *webhookDeliveryPers) Update(delivery *domain.WebhookDelivery) error {
+	return p.db.Omit("Webhook").Save(delivery).Error
+}
@@ -0,0 +1,144 @@
+package importer
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	importerDto "github.com/labbs/nexo/application/importer/dto"
+	userDto "github.com/labbs/nexo/application/user/dto"
+	"github.com/labbs/nexo/infrastructure"
+	"github.com/labbs/nexo/infrastructure/config"
+	"github.com/labbs/nexo/infrastructure/database"
+	"github.com/labbs/nexo/infrastructure/logger"
+	"github.com/labbs/nexo/interfaces/cli/server"
+
+	"github.com/urfave/cli/v3"
+)
+
+// options are the flags of an import
+type options struct {
+	space  string
+	user   string
+	parent string
+}
+
+// NewInstance creates a new CLI command for importing the exports of other wikis.
+// It's called by the main application to add the "import" command to the CLI.
+func NewInstance(version string) *cli.Command {
+	return &cli.Command{
+		Name:  "import",
+		Usage: "Import a Notion or Confluence export in a space",
+		Commands: []*cli.Command{
+			newSourceCommand(version, importerDto.SourceNotion, "Import a Notion export (Markdown & CSV zip)"),
+			newSourceCommand(version, importerDto.SourceConfluence, "Import a Confluence space export (HTML zip)"),
+		},
+	}
+}
+
+func newSourceCommand(version, source, usage string) *cli.Command {
+	cfg := &config.Config{}
+	cfg.Version = version
+	opts := &options{}
+
+	return &cli.Command{
+		Name:      source,
+		Usage:     usage,
+		ArgsUsage: "<archive.zip>",
+		Flags:     getFlags(cfg, opts),
+		Action: func(ctx context.Context, cmd *cli.Command) error {
+			if cmd.Args().Len() != 1 {
+				return fmt.Errorf("expected the path of the export archive")
+			}
+			return runImport(*cfg, *opts, source, cmd.Args().First())
+		},
+	}
+}
+
+// getFlags returns the list of CLI flags required for the import commands.
+func getFlags(cfg *config.Config, opts *options) (list []cli.Flag) {
+	list = append(list, config.GenericFlags(cfg)...)
+	list = append(list, config.LoggerFlags(cfg)...)
+	list = append(list, config.DatabaseFlags(cfg)...)
+	list = append(list, config.StorageFlags(cfg)...)
+	list = append(list,
+		&cli.StringFlag{
+			Name:        "space",
+			Usage:       "The id of the space to import in",
+			Required:    true,
+			Destination: &opts.space,
+		},
+		&cli.StringFlag{
+			Name:        "user",
+			Usage:       "The email of the user the content is created by, who must be an editor of the space",
+			Required:    true,
+			Destination: &opts.user,
+		},
+		&cli.StringFlag{
+			Name:        "parent",
+			Usage:       "The id of the document to import under (optional)",
+			Destination: &opts.parent,
+		},
+	)
+	return
+}
+
+// runImport initializes the application services and imports an archive.
+func runImport(cfg config.Config, opts options, source, file string) error {
+	var err error
+
+	// Initialize dependencies
+	deps := infrastructure.Deps{
+		Config: cfg,
+	}
+
+	// Initialize logger
+	deps.Logger = logger.NewLogger(cfg.Logger.Level, cfg.Logger.Pretty, cfg.Version)
+	logger := deps.Logger.With().Str("component", "interfaces.cli.importer.runimport").Logger()
+
+	// Initialize database connection
+	deps.Database, err = database.Configure(deps.Config, deps.Logger)
+	if err != nil {
+		logger.Error().Err(err).Str("event", "importer.runimport.database.configure").Msg("Failed to configure database connection")
+		return err
+	}
+	if err := server.SetupApplications(&deps); err != nil {
+		logger.Error().Err(err).Str("event", "importer.runimport.applications.setup").Msg("Failed to setup application services")
+		return err
+	}
+
+	user, err := deps.UserApplication.GetByEmail(userDto.GetByEmailInput{Email: opts.user})
+	if err != nil {
+		return fmt.Errorf("failed to find the user %s: %w", opts.user, err)
+	}
+
+	archive, err := os.Open(file)
+	if err != nil {
+		return err
+	}
+	defer archive.Close()
+	info, err := archive.Stat()
+	if err != nil {
+		return err
+	}
+
+	var parentId *string
+	if opts.parent != "" {
+		parentId = &opts.parent
+	}
+	result, err := deps.ImporterApplication.Import(importerDto.ImportInput{
+		UserId:   user.User.Id,
+		SpaceId:  opts.space,
+		ParentId: parentId,
+		Source:   source,
+		Archive:  archive,
+		Size:     info.Size(),
+	})
+	if result != nil {
+		for _, warning := range result.Warnings {
+			fmt.Println("warning:", warning)
+		}
+		fmt.Printf("Imported %d documents, %d databases, %d rows and %d attachments\n", result.Documents, result.Databases, result.Rows, result.Attachments)
+	}
+	return err
+}
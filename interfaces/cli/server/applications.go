@@ -0,0 +1,145 @@
+package server
+
+import (
+	"fmt"
+
+	"github.com/labbs/nexo/application/action"
+	"github.com/labbs/nexo/application/apikey"
+	"github.com/labbs/nexo/application/attachment"
+	"github.com/labbs/nexo/application/auth"
+	databaseApp "github.com/labbs/nexo/application/database"
+	"github.com/labbs/nexo/application/document"
+	"github.com/labbs/nexo/application/drawing"
+	"github.com/labbs/nexo/application/event"
+	"github.com/labbs/nexo/application/favorite"
+	"github.com/labbs/nexo/application/group"
+	"github.com/labbs/nexo/application/importer"
+	"github.com/labbs/nexo/application/permission"
+	"github.com/labbs/nexo/application/search"
+	"github.com/labbs/nexo/application/session"
+	"github.com/labbs/nexo/application/space"
+	"github.com/labbs/nexo/application/user"
+	"github.com/labbs/nexo/application/webhook"
+	"github.com/labbs/nexo/domain"
+	"github.com/labbs/nexo/infrastructure"
+	"github.com/labbs/nexo/infrastructure/persistence"
+	"github.com/labbs/nexo/infrastructure/storage"
+)
+
+// SetupApplications creates the application services on the database of the
+// dependencies and injects their port dependencies. It's shared by the
+// commands which need the applications, such as the server and the import.
+func SetupApplications(deps *infrastructure.Deps) error {
+	var err error
+
+	userPers := persistence.NewUserPers(deps.Database.Db)
+	oauthProviderPers := persistence.NewOAuthProviderPers(deps.Database.Db)
+	groupPers := persistence.NewGroupPers(deps.Database.Db)
+	sessionPers := persistence.NewSessionPers(deps.Database.Db)
+	spacePers := persistence.NewSpacePers(deps.Database.Db)
+	documentPers := persistence.NewDocumentPers(deps.Database.Db)
+	permissionPers := persistence.NewPermissionPers(deps.Database.Db)
+	favoritePers := persistence.NewFavoritePers(deps.Database.Db)
+	commentPers := persistence.NewCommentPers(deps.Database.Db)
+	documentVersionPers := persistence.NewDocumentVersionPers(deps.Database.Db)
+
+	apiKeyPers := persistence.NewApiKeyPers(deps.Database.Db)
+	webhookPers := persistence.NewWebhookPers(deps.Database.Db)
+	webhookDeliveryPers := persistence.NewWebhookDeliveryPers(deps.Database.Db)
+	databasePers := persistence.NewDatabasePers(deps.Database.Db)
+	databaseRowPers := persistence.NewDatabaseRowPers(deps.Database.Db)
+	drawingPers := persistence.NewDrawingPers(deps.Database.Db)
+	actionPers := persistence.NewActionPers(deps.Database.Db)
+	actionRunPers := persistence.NewActionRunPers(deps.Database.Db)
+	searchPers := persistence.NewSearchPers(deps.Database.Db)
+	attachmentPers := persistence.NewAttachmentPers(deps.Database.Db)
+
+	deps.UserApplication = user.NewUserApplication(deps.Config, deps.Logger, userPers)
+	deps.SessionApplication = session.NewSessionApplication(deps.Config, deps.Logger, sessionPers)
+	deps.SpaceApplication = space.NewSpaceApplication(deps.Config, deps.Logger, spacePers)
+	deps.DocumentApplication = document.NewDocumentApplication(deps.Config, deps.Logger, documentPers, commentPers, documentVersionPers)
+	deps.AuthApplication = auth.NewAuthApplication(deps.Config, deps.Logger)
+	deps.ApiKeyApplication = apikey.NewApiKeyApplication(deps.Config, deps.Logger, apiKeyPers)
+	deps.WebhookApplication = webhook.NewWebhookApplication(deps.Config, deps.Logger, webhookPers, webhookDeliveryPers)
+	deps.DatabaseApplication = databaseApp.NewDatabaseApplication(deps.Config, deps.Logger, databasePers, databaseRowPers)
+	deps.DrawingApplication = drawing.NewDrawingApplication(deps.Config, deps.Logger, drawingPers)
+	deps.ActionApplication = action.NewActionApplication(deps.Config, deps.Logger, actionPers, actionRunPers)
+	deps.EventApplication = event.NewEventApplication(deps.Config, deps.Logger)
+	deps.GroupApplication = group.NewGroupApplication(deps.Config, deps.Logger, groupPers)
+	deps.FavoriteApplication = favorite.NewFavoriteApplication(deps.Config, deps.Logger, favoritePers)
+	deps.PermissionApplication = permission.NewPermissionApplication(deps.Config, deps.Logger, permissionPers)
+	deps.SearchApplication = search.NewSearchApplication(deps.Config, deps.Logger, searchPers)
+
+	var fileStorage domain.FileStorage
+	switch deps.Config.Storage.Backend {
+	case "local":
+		fileStorage, err = storage.NewLocalStorage(deps.Config.Storage.Local.Path)
+	case "s3":
+		fileStorage, err = storage.NewS3Storage(storage.S3Config{
+			Endpoint:        deps.Config.Storage.S3.Endpoint,
+			Region:          deps.Config.Storage.S3.Region,
+			Bucket:          deps.Config.Storage.S3.Bucket,
+			AccessKeyID:     deps.Config.Storage.S3.AccessKeyID,
+			SecretAccessKey: deps.Config.Storage.S3.SecretAccessKey,
+			UsePathStyle:    deps.Config.Storage.S3.UsePathStyle,
+		})
+	default:
+		err = fmt.Errorf("unsupported storage backend: %s", deps.Config.Storage.Backend)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to configure attachment storage: %w", err)
+	}
+	deps.AttachmentApplication = attachment.NewAttachmentApplication(deps.Config, deps.Logger, attachmentPers, fileStorage, documentPers, drawingPers, databasePers)
+	deps.ImporterApplication = importer.NewImporterApplication(deps.Config, deps.Logger)
+	deps.PermissionPers = permissionPers
+	deps.OAuthProviderPers = oauthProviderPers
+
+	// Inject port dependencies (after construction to avoid circular dependencies)
+	deps.AuthApplication.UserApplication = deps.UserApplication
+	deps.AuthApplication.SessionApplication = deps.SessionApplication
+	deps.AuthApplication.SpaceApplication = deps.SpaceApplication
+	deps.AuthApplication.DocumentApplication = deps.DocumentApplication
+	deps.AuthApplication.OAuthProviderPers = oauthProviderPers
+	deps.UserApplication.GroupApplication = deps.GroupApplication
+	deps.FavoriteApplication.DocumentApplication = deps.DocumentApplication
+	deps.SpaceApplication.DocumentApplication = deps.DocumentApplication
+	deps.SpaceApplication.PermissionApplication = deps.PermissionApplication
+	deps.DocumentApplication.SpaceApplication = deps.SpaceApplication
+	deps.DocumentApplication.PermissionApplication = deps.PermissionApplication
+	deps.DrawingApplication.SpaceApplication = deps.SpaceApplication
+	deps.DrawingApplication.PermissionApplication = deps.PermissionApplication
+	deps.DatabaseApplication.SpaceApplication = deps.SpaceApplication
+	deps.DatabaseApplication.PermissionApplication = deps.PermissionApplication
+	deps.GroupApplication.UserApplication = deps.UserApplication
+	deps.PermissionApplication.SpaceApplication = deps.SpaceApplication
+	deps.PermissionApplication.DrawingApplication = deps.DrawingApplication
+	deps.PermissionApplication.DocumentApplication = deps.DocumentApplication
+	deps.PermissionApplication.DatabaseApplication = deps.DatabaseApplication
+	deps.SessionApplication.UserApplication = deps.UserApplication
+	deps.SessionApplication.ApiKeyApplication = deps.ApiKeyApplication
+	deps.SessionApplication.PermissionApplication = deps.PermissionApplication
+	deps.EventApplication.UserApplication = deps.UserApplication
+	deps.EventApplication.WebhookApplication = deps.WebhookApplication
+	deps.EventApplication.ActionApplication = deps.ActionApplication
+	deps.EventApplication.PermissionApplication = deps.PermissionApplication
+	deps.WebhookApplication.PermissionApplication = deps.PermissionApplication
+	deps.DocumentApplication.EventApplication = deps.EventApplication
+	deps.DocumentApplication.SearchApplication = deps.SearchApplication
+	deps.DatabaseApplication.SearchApplication = deps.SearchApplication
+	deps.DrawingApplication.SearchApplication = deps.SearchApplication
+	deps.SpaceApplication.EventApplication = deps.EventApplication
+	deps.DatabaseApplication.EventApplication = deps.EventApplication
+	deps.AttachmentApplication.PermissionApplication = deps.PermissionApplication
+	deps.DocumentApplication.AttachmentApplication = deps.AttachmentApplication
+	deps.ActionApplication.DatabaseApplication = deps.DatabaseApplication
+	deps.ActionApplication.DocumentApplication = deps.DocumentApplication
+	deps.ActionApplication.WebhookApplication = deps.WebhookApplication
+	deps.ActionApplication.PermissionApplication = deps.PermissionApplication
+	deps.ActionApplication.Scheduler = deps.CronScheduler.CronScheduler
+	deps.ImporterApplication.SpaceApplication = deps.SpaceApplication
+	deps.ImporterApplication.DocumentApplication = deps.DocumentApplication
+	deps.ImporterApplication.DatabaseApplication = deps.DatabaseApplication
+	deps.ImporterApplication.AttachmentApplication = deps.AttachmentApplication
+
+	return nil
+}
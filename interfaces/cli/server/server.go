@@ -5,19 +5,6 @@ import (
 	"fmt"
 	"strconv"
 
-	"github.com/labbs/nexo/application/action"
-	"github.com/labbs/nexo/application/apikey"
-	"github.com/labbs/nexo/application/auth"
-	databaseApp "github.com/labbs/nexo/application/database"
-	"github.com/labbs/nexo/application/document"
-	"github.com/labbs/nexo/application/drawing"
-	"github.com/labbs/nexo/application/favorite"
-	"github.com/labbs/nexo/application/group"
-	"github.com/labbs/nexo/application/permission"
-	"github.com/labbs/nexo/application/session"
-	"github.com/labbs/nexo/application/space"
-	"github.com/labbs/nexo/application/user"
-	"github.com/labbs/nexo/application/webhook"
 	"github.com/labbs/nexo/infrastructure"
 	"github.com/labbs/nexo/infrastructure/collaboration"
 	"github.com/labbs/nexo/infrastructure/config"
@@ -58,6 +45,9 @@ func getFlags(cfg *config.Config) (list []cli.Flag) {
 	list = append(list, config.SessionFlags(cfg)...)
 	list = append(list, config.RegistrationFlags(cfg)...)
 	list = append(list, config.SSOFlags(cfg)...)
+	list = append(list, config.WebhookFlags(cfg)...)
+	list = append(list, config.CollaborationFlags(cfg)...)
+	list = append(list, config.StorageFlags(cfg)...)
 	return
 }
 
@@ -95,70 +85,28 @@ func runServer(cfg config.Config) error {
 	}
 
 	// Initialize application services
-	userPers := persistence.NewUserPers(deps.Database.Db)
-	oauthProviderPers := persistence.NewOAuthProviderPers(deps.Database.Db)
-	groupPers := persistence.NewGroupPers(deps.Database.Db)
-	sessionPers := persistence.NewSessionPers(deps.Database.Db)
-	spacePers := persistence.NewSpacePers(deps.Database.Db)
-	documentPers := persistence.NewDocumentPers(deps.Database.Db)
-	permissionPers := persistence.NewPermissionPers(deps.Database.Db)
-	favoritePers := persistence.NewFavoritePers(deps.Database.Db)
-	commentPers := persistence.NewCommentPers(deps.Database.Db)
-	documentVersionPers := persistence.NewDocumentVersionPers(deps.Database.Db)
-
-	apiKeyPers := persistence.NewApiKeyPers(deps.Database.Db)
-	webhookPers := persistence.NewWebhookPers(deps.Database.Db)
-	webhookDeliveryPers := persistence.NewWebhookDeliveryPers(deps.Database.Db)
-	databasePers := persistence.NewDatabasePers(deps.Database.Db)
-	databaseRowPers := persistence.NewDatabaseRowPers(deps.Database.Db)
-	drawingPers := persistence.NewDrawingPers(deps.Database.Db)
-	actionPers := persistence.NewActionPers(deps.Database.Db)
-	actionRunPers := persistence.NewActionRunPers(deps.Database.Db)
-
-	deps.UserApplication = user.NewUserApplication(deps.Config, deps.Logger, userPers)
-	deps.SessionApplication = session.NewSessionApplication(deps.Config, deps.Logger, sessionPers)
-	deps.SpaceApplication = space.NewSpaceApplication(deps.Config, deps.Logger, spacePers)
-	deps.DocumentApplication = document.NewDocumentApplication(deps.Config, deps.Logger, documentPers, commentPers, documentVersionPers)
-	deps.AuthApplication = auth.NewAuthApplication(deps.Config, deps.Logger)
-	deps.ApiKeyApplication = apikey.NewApiKeyApplication(deps.Config, deps.Logger, apiKeyPers)
-	deps.WebhookApplication = webhook.NewWebhookApplication(deps.Config, deps.Logger, webhookPers, webhookDeliveryPers)
-	deps.DatabaseApplication = databaseApp.NewDatabaseApplication(deps.Config, deps.Logger, databasePers, databaseRowPers)
-	deps.DrawingApplication = drawing.NewDrawingApplication(deps.Config, deps.Logger, drawingPers)
-	deps.ActionApplication = action.NewActionApplication(deps.Config, deps.Logger, actionPers, actionRunPers)
-	deps.GroupApplication = group.NewGroupApplication(deps.Config, deps.Logger, groupPers)
-	deps.FavoriteApplication = favorite.NewFavoriteApplication(deps.Config, deps.Logger, favoritePers)
-	deps.PermissionApplication = permission.NewPermissionApplication(deps.Config, deps.Logger, permissionPers)
-	deps.PermissionPers = permissionPers
-	deps.OAuthProviderPers = oauthProviderPers
-
-	// Inject port dependencies (after construction to avoid circular dependencies)
-	deps.AuthApplication.UserApplication = deps.UserApplication
-	deps.AuthApplication.SessionApplication = deps.SessionApplication
-	deps.AuthApplication.SpaceApplication = deps.SpaceApplication
-	deps.AuthApplication.DocumentApplication = deps.DocumentApplication
-	deps.AuthApplication.OAuthProviderPers = oauthProviderPers
-	deps.UserApplication.GroupApplication = deps.GroupApplication
-	deps.FavoriteApplication.DocumentApplication = deps.DocumentApplication
-	deps.SpaceApplication.DocumentApplication = deps.DocumentApplication
-	deps.SpaceApplication.PermissionApplication = deps.PermissionApplication
-	deps.DocumentApplication.SpaceApplication = deps.SpaceApplication
-	deps.DocumentApplication.PermissionApplication = deps.PermissionApplication
-	deps.DrawingApplication.SpaceApplication = deps.SpaceApplication
-	deps.DrawingApplication.PermissionApplication = deps.PermissionApplication
-	deps.DatabaseApplication.SpaceApplication = deps.SpaceApplication
-	deps.DatabaseApplication.PermissionApplication = deps.PermissionApplication
-	deps.GroupApplication.UserApplication = deps.UserApplication
-	deps.PermissionApplication.SpaceApplication = deps.SpaceApplication
-	deps.PermissionApplication.DrawingApplication = deps.DrawingApplication
-	deps.PermissionApplication.DocumentApplication = deps.DocumentApplication
-	deps.PermissionApplication.DatabaseApplication = deps.DatabaseApplication
-	deps.SessionApplication.UserApplication = deps.UserApplication
-	deps.SessionApplication.SpaceApplication = deps.SpaceApplication
-	deps.SessionApplication.DatabaseApplication = deps.DatabaseApplication
-	deps.SessionApplication.DrawingApplication = deps.DrawingApplication
+	if err := SetupApplications(&deps); err != nil {
+		logger.Fatal().Err(err).Str("event", "http.runserver.applications.setup").Msg("Failed to setup application services")
+		return err
+	}
 
 	// Initialize collaboration hub
-	deps.CollaborationHub = collaboration.NewHub(deps.Logger)
+	var broadcaster collaboration.Broadcaster
+	switch {
+	case cfg.Collaboration.Broadcaster == "memory":
+		broadcaster = collaboration.NewMemoryBroadcaster()
+	case cfg.Collaboration.Broadcaster == "postgres" && cfg.Database.Dialect == "postgres":
+		broadcaster = collaboration.NewPostgresBroadcaster(cfg.Database.DSN, deps.Database.Db, deps.Logger)
+	default:
+		logger.Fatal().Str("event", "http.runserver.collaboration.broadcaster").Msgf("Unsupported collaboration broadcaster %q with the %s database", cfg.Collaboration.Broadcaster, cfg.Database.Dialect)
+		return fmt.Errorf("unsupported collaboration broadcaster: %s", cfg.Collaboration.Broadcaster)
+	}
+	collaborationUpdatePers := persistence.NewCollaborationUpdatePers(deps.Database.Db)
+	deps.CollaborationHub = collaboration.NewHub(deps.Logger, broadcaster, collaborationUpdatePers, deps.DocumentApplication)
+	deps.DocumentApplication.CollaborationPresence = deps.CollaborationHub
+	deps.DocumentApplication.CollaborationRooms = deps.CollaborationHub
+	deps.PermissionApplication.CollaborationRooms = deps.CollaborationHub
+	deps.GroupApplication.CollaborationRooms = deps.CollaborationHub
 
 	// Initialize HTTP server (fiber + fiberoapi)
 	deps.Http, err = http.Configure(deps.Config, deps.Logger, deps.SessionApplication, true)
@@ -169,9 +117,14 @@ func runServer(cfg config.Config) error {
 
 	// Setup cron jobs
 	configJobs := jobs.Config{
-		Logger:        deps.Logger,
-		CronScheduler: deps.CronScheduler,
-		SessionApp:    *deps.SessionApplication,
+		Logger:           deps.Logger,
+		CronScheduler:    deps.CronScheduler,
+		SessionApp:       *deps.SessionApplication,
+		WebhookApp:       *deps.WebhookApplication,
+		ActionApp:        deps.ActionApplication,
+		SearchApp:        deps.SearchApplication,
+		DatabaseApp:      deps.DatabaseApplication,
+		CollaborationHub: deps.CollaborationHub,
 	}
 
 	err = configJobs.SetupJobs()
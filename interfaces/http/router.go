@@ -25,7 +25,7 @@ func setupCollaborationRoutes(deps infrastructure.Deps) {
 	logger := deps.Logger.With().Str("component", "http.router.collaboration").Logger()
 	logger.Info().Str("event", "setup_collaboration_routes").Msg("Setting up collaboration WebSocket routes")
 	documentPers := persistence.NewDocumentPers(deps.Database.Db)
-	handler := collaboration.NewHandler(deps.CollaborationHub, deps.SessionApplication, documentPers, deps.Logger)
+	handler := collaboration.NewHandler(deps.CollaborationHub, deps.SessionApplication, deps.UserApplication, documentPers, deps.Logger)
 
 	// The frontend connects to ws://<host>/<roomId>?token=<jwt>
 	// Room formats: "document:<docId>" or "row:<databaseId>:<rowId>"
@@ -93,6 +93,12 @@ func (ctrl *Controller) CreateAction(ctx *fiber.Ctx, req dtos.CreateActionReques
 		Steps:         steps,
 	})
 	if err != nil {
+		if errors.Is(err, apperrors.ErrInvalidInput) {
+			return nil, &fiberoapi.ErrorResponse{Code: fiber.StatusBadRequest, Details: err.Error(), Type: "BAD_REQUEST"}
+		}
+		if errors.Is(err, apperrors.ErrAccessDenied) {
+			return nil, &fiberoapi.ErrorResponse{Code: fiber.StatusForbidden, Details: "Forbidden", Type: "FORBIDDEN"}
+		}
 		logger.Error().Err(err).Msg("failed to create action")
 		return nil, &fiberoapi.ErrorResponse{Code: fiber.StatusInternalServerError, Details: "Failed to create action", Type: "INTERNAL_SERVER_ERROR"}
 	}
@@ -200,6 +206,9 @@ func (ctrl *Controller) UpdateAction(ctx *fiber.Ctx, req dtos.UpdateActionReques
 		if errors.Is(err, apperrors.ErrNotFound) {
 			return nil, &fiberoapi.ErrorResponse{Code: fiber.StatusNotFound, Details: "Action not found", Type: "NOT_FOUND"}
 		}
+		if errors.Is(err, apperrors.ErrInvalidInput) {
+			return nil, &fiberoapi.ErrorResponse{Code: fiber.StatusBadRequest, Details: err.Error(), Type: "BAD_REQUEST"}
+		}
 		logger.Error().Err(err).Msg("failed to update action")
 		return nil, &fiberoapi.ErrorResponse{Code: fiber.StatusInternalServerError, Details: "Failed to update action", Type: "INTERNAL_SERVER_ERROR"}
 	}
@@ -323,6 +332,8 @@ func (ctrl *Controller) GetAvailableSteps(ctx *fiber.Ctx, _ dtos.EmptyRequest) (
 		{Type: "add_comment", Description: "Add a comment", Category: "Other"},
 		{Type: "assign_user", Description: "Assign a user", Category: "Other"},
 		{Type: "set_reminder", Description: "Set a reminder", Category: "Other"},
+		// Control flow
+		{Type: "if", Description: "Run steps only when conditions match, with an optional else branch", Category: "Control flow"},
 	}
 
 	return &dtos.AvailableStepsResponse{Steps: steps}, nil
@@ -1,6 +1,10 @@
 package action
 
-import fiberoapi "github.com/labbs/fiber-oapi"
+import (
+	fiberoapi "github.com/labbs/fiber-oapi"
+	"github.com/labbs/nexo/domain"
+	"github.com/labbs/nexo/infrastructure/http"
+)
 
 func SetupActionRouter(ctrl Controller) {
 	fiberoapi.Get(ctrl.FiberOapi, "/", ctrl.ListActions, fiberoapi.OpenAPIOptions{
@@ -8,6 +12,7 @@ func SetupActionRouter(ctrl Controller) {
 		Description: "List all automation actions for the authenticated user",
 		OperationID: "action.list",
 		Tags:        []string{"Actions"},
+		Security:    http.RequireScopes(domain.ApiKeyScopeManageActions),
 	})
 
 	fiberoapi.Post(ctrl.FiberOapi, "/", ctrl.CreateAction, fiberoapi.OpenAPIOptions{
@@ -15,6 +20,7 @@ func SetupActionRouter(ctrl Controller) {
 		Description: "Create a new automation action",
 		OperationID: "action.create",
 		Tags:        []string{"Actions"},
+		Security:    http.RequireScopes(domain.ApiKeyScopeManageActions),
 	})
 
 	fiberoapi.Get(ctrl.FiberOapi, "/triggers", ctrl.GetAvailableTriggers, fiberoapi.OpenAPIOptions{
@@ -22,6 +28,7 @@ func SetupActionRouter(ctrl Controller) {
 		Description: "List all available trigger types for actions",
 		OperationID: "action.triggers",
 		Tags:        []string{"Actions"},
+		Security:    http.RequireScopes(domain.ApiKeyScopeManageActions),
 	})
 
 	fiberoapi.Get(ctrl.FiberOapi, "/steps", ctrl.GetAvailableSteps, fiberoapi.OpenAPIOptions{
@@ -29,6 +36,7 @@ func SetupActionRouter(ctrl Controller) {
 		Description: "List all available step types for actions",
 		OperationID: "action.steps",
 		Tags:        []string{"Actions"},
+		Security:    http.RequireScopes(domain.ApiKeyScopeManageActions),
 	})
 
 	fiberoapi.Get(ctrl.FiberOapi, "/:action_id", ctrl.GetAction, fiberoapi.OpenAPIOptions{
@@ -36,6 +44,7 @@ func SetupActionRouter(ctrl Controller) {
 		Description: "Get a specific action by ID",
 		OperationID: "action.get",
 		Tags:        []string{"Actions"},
+		Security:    http.RequireScopes(domain.ApiKeyScopeManageActions),
 	})
 
 	fiberoapi.Put(ctrl.FiberOapi, "/:action_id", ctrl.UpdateAction, fiberoapi.OpenAPIOptions{
@@ -43,6 +52,7 @@ func SetupActionRouter(ctrl Controller) {
 		Description: "Update an existing action",
 		OperationID: "action.update",
 		Tags:        []string{"Actions"},
+		Security:    http.RequireScopes(domain.ApiKeyScopeManageActions),
 	})
 
 	fiberoapi.Delete(ctrl.FiberOapi, "/:action_id", ctrl.DeleteAction, fiberoapi.OpenAPIOptions{
@@ -50,6 +60,7 @@ func SetupActionRouter(ctrl Controller) {
 		Description: "Delete an action",
 		OperationID: "action.delete",
 		Tags:        []string{"Actions"},
+		Security:    http.RequireScopes(domain.ApiKeyScopeManageActions),
 	})
 
 	fiberoapi.Get(ctrl.FiberOapi, "/:action_id/runs", ctrl.GetRuns, fiberoapi.OpenAPIOptions{
@@ -57,5 +68,6 @@ func SetupActionRouter(ctrl Controller) {
 		Description: "Get execution history for an action",
 		OperationID: "action.runs",
 		Tags:        []string{"Actions"},
+		Security:    http.RequireScopes(domain.ApiKeyScopeManageActions),
 	})
 }
@@ -0,0 +1,28 @@
+package dtos
+
+import "time"
+
+// Active collaboration rooms
+
+type ListCollaborationRoomsRequest struct{}
+
+type CollaborationUser struct {
+	UserId         string    `json:"user_id"`
+	Username       string    `json:"username"`
+	AvatarUrl      string    `json:"avatar_url,omitempty"`
+	Connections    int       `json:"connections"`
+	ReadOnly       bool      `json:"read_only"`
+	ConnectedAt    time.Time `json:"connected_at"`
+	LastActivityAt time.Time `json:"last_activity_at"`
+}
+
+type CollaborationRoom struct {
+	RoomId       string              `json:"room_id"`
+	ResourceType string              `json:"resource_type"`
+	ResourceId   string              `json:"resource_id"`
+	Users        []CollaborationUser `json:"users"`
+}
+
+type ListCollaborationRoomsResponse struct {
+	Rooms []CollaborationRoom `json:"rooms"`
+}
@@ -0,0 +1,18 @@
+package dtos
+
+// Imports
+
+// ImportRequest is a multipart form: the export archive is sent in the "file" field
+type ImportRequest struct {
+	Source   string `form:"source" validate:"required,oneof=notion confluence"`
+	SpaceId  string `form:"space_id" validate:"required,uuid4"`
+	ParentId string `form:"parent_id" validate:"omitempty,uuid4"`
+}
+
+type ImportResponse struct {
+	Documents   int      `json:"documents"`
+	Databases   int      `json:"databases"`
+	Rows        int      `json:"rows"`
+	Attachments int      `json:"attachments"`
+	Warnings    []string `json:"warnings"`
+}
@@ -89,7 +89,7 @@ type AdminListSpacePermissionsResponse struct {
 type AdminAddSpaceUserPermissionRequest struct {
 	SpaceId string `path:"space_id" validate:"required"`
 	UserId  string `json:"user_id" validate:"required"`
-	Role    string `json:"role" validate:"required,oneof=viewer editor admin"`
+	Role    string `json:"role" validate:"required,oneof=viewer commenter editor admin"`
 }
 
 type AdminAddSpaceUserPermissionResponse struct {
@@ -108,7 +108,7 @@ type AdminRemoveSpaceUserPermissionResponse struct {
 type AdminAddSpaceGroupPermissionRequest struct {
 	SpaceId string `path:"space_id" validate:"required"`
 	GroupId string `json:"group_id" validate:"required"`
-	Role    string `json:"role" validate:"required,oneof=viewer editor admin"`
+	Role    string `json:"role" validate:"required,oneof=viewer commenter editor admin"`
 }
 
 type AdminAddSpaceGroupPermissionResponse struct {
@@ -0,0 +1,11 @@
+package dtos
+
+// Redeliver webhook delivery
+
+type RedeliverWebhookRequest struct {
+	DeliveryId string `path:"delivery_id"`
+}
+
+type RedeliverWebhookResponse struct {
+	DeliveryId string `json:"delivery_id"`
+}
@@ -1,10 +1,14 @@
 package admin
 
 import (
+	"errors"
+
 	"github.com/gofiber/fiber/v2"
 	fiberoapi "github.com/labbs/fiber-oapi"
 	groupDto "github.com/labbs/nexo/application/group/dto"
+	importerDto "github.com/labbs/nexo/application/importer/dto"
 	"github.com/labbs/nexo/domain"
+	"github.com/labbs/nexo/infrastructure/helpers/apperrors"
 	"github.com/labbs/nexo/interfaces/http/v1/admin/dtos"
 )
 
@@ -285,6 +289,66 @@ func (ctrl *Controller) RevokeApiKey(ctx *fiber.Ctx, req dtos.RevokeApiKeyReques
 	}, nil
 }
 
+// Webhooks
+
+func (ctrl *Controller) RedeliverWebhook(ctx *fiber.Ctx, req dtos.RedeliverWebhookRequest) (*dtos.RedeliverWebhookResponse, *fiberoapi.ErrorResponse) {
+	requestId := ctx.Locals("requestid").(string)
+	logger := ctrl.Logger.With().Str("request_id", requestId).Str("component", "http.api.v1.admin.redeliver_webhook").Logger()
+
+	result, err := ctrl.WebhookApplication.AdminRedeliver(req.DeliveryId)
+	if err != nil {
+		if errors.Is(err, apperrors.ErrNotFound) || errors.Is(err, apperrors.ErrWebhookNotFound) {
+			return nil, &fiberoapi.ErrorResponse{
+				Code:    fiber.StatusNotFound,
+				Details: "Delivery not found",
+				Type:    "NOT_FOUND",
+			}
+		}
+		logger.Error().Err(err).Msg("failed to redeliver webhook")
+		return nil, &fiberoapi.ErrorResponse{
+			Code:    fiber.StatusInternalServerError,
+			Details: "Failed to redeliver webhook",
+			Type:    "INTERNAL_SERVER_ERROR",
+		}
+	}
+
+	return &dtos.RedeliverWebhookResponse{
+		DeliveryId: result.DeliveryId,
+	}, nil
+}
+
+// Collaboration
+
+func (ctrl *Controller) ListCollaborationRooms(ctx *fiber.Ctx, req dtos.ListCollaborationRoomsRequest) (*dtos.ListCollaborationRoomsResponse, *fiberoapi.ErrorResponse) {
+	resp := &dtos.ListCollaborationRoomsResponse{Rooms: []dtos.CollaborationRoom{}}
+	if ctrl.CollaborationPresence == nil {
+		return resp, nil
+	}
+
+	for _, room := range ctrl.CollaborationPresence.ActiveRooms() {
+		users := make([]dtos.CollaborationUser, len(room.Users))
+		for i, u := range room.Users {
+			users[i] = dtos.CollaborationUser{
+				UserId:         u.UserId,
+				Username:       u.Username,
+				AvatarUrl:      u.AvatarUrl,
+				Connections:    u.Connections,
+				ReadOnly:       u.ReadOnly,
+				ConnectedAt:    u.ConnectedAt,
+				LastActivityAt: u.LastActivityAt,
+			}
+		}
+		resp.Rooms = append(resp.Rooms, dtos.CollaborationRoom{
+			RoomId:       room.RoomId,
+			ResourceType: room.ResourceType,
+			ResourceId:   room.ResourceId,
+			Users:        users,
+		})
+	}
+
+	return resp, nil
+}
+
 // Groups
 
 func (ctrl *Controller) ListGroups(ctx *fiber.Ctx, req dtos.ListGroupsRequest) (*dtos.ListGroupsResponse, *fiberoapi.ErrorResponse) {
@@ -678,3 +742,56 @@ func (ctrl *Controller) AdminRemoveSpaceGroupPermission(ctx *fiber.Ctx, req dtos
 		Message: "Group permission removed successfully",
 	}, nil
 }
+
+// Imports
+
+func (ctrl *Controller) Import(ctx *fiber.Ctx, req dtos.ImportRequest) (*dtos.ImportResponse, *fiberoapi.ErrorResponse) {
+	requestId := ctx.Locals("requestid").(string)
+	logger := ctrl.Logger.With().Str("request_id", requestId).Str("component", "http.api.v1.admin.import").Logger()
+
+	authCtx, _ := fiberoapi.GetAuthContext(ctx)
+
+	header, err := ctx.FormFile("file")
+	if err != nil {
+		return nil, &fiberoapi.ErrorResponse{Code: fiber.StatusBadRequest, Details: "Missing file", Type: "BAD_REQUEST"}
+	}
+	file, err := header.Open()
+	if err != nil {
+		logger.Error().Err(err).Msg("failed to open uploaded file")
+		return nil, &fiberoapi.ErrorResponse{Code: fiber.StatusBadRequest, Details: "Invalid file", Type: "BAD_REQUEST"}
+	}
+	defer file.Close()
+
+	var parentId *string
+	if req.ParentId != "" {
+		parentId = &req.ParentId
+	}
+	result, err := ctrl.ImporterApplication.Import(importerDto.ImportInput{
+		UserId:   authCtx.UserID,
+		SpaceId:  req.SpaceId,
+		ParentId: parentId,
+		Source:   req.Source,
+		Archive:  file,
+		Size:     header.Size,
+	})
+	if err != nil {
+		logger.Error().Err(err).Str("space_id", req.SpaceId).Str("source", req.Source).Msg("failed to import archive")
+		switch {
+		case errors.Is(err, apperrors.ErrInvalidInput):
+			return nil, &fiberoapi.ErrorResponse{Code: fiber.StatusBadRequest, Details: err.Error(), Type: "BAD_REQUEST"}
+		case errors.Is(err, apperrors.ErrAccessDenied):
+			return nil, &fiberoapi.ErrorResponse{Code: fiber.StatusForbidden, Details: "Access denied to the space", Type: "FORBIDDEN"}
+		case errors.Is(err, apperrors.ErrSpaceNotFound), errors.Is(err, apperrors.ErrDocumentNotFound):
+			return nil, &fiberoapi.ErrorResponse{Code: fiber.StatusNotFound, Details: "Space or parent document not found", Type: "NOT_FOUND"}
+		}
+		return nil, &fiberoapi.ErrorResponse{Code: fiber.StatusInternalServerError, Details: "Failed to import archive", Type: "INTERNAL_SERVER_ERROR"}
+	}
+
+	return &dtos.ImportResponse{
+		Documents:   result.Documents,
+		Databases:   result.Databases,
+		Rows:        result.Rows,
+		Attachments: result.Attachments,
+		Warnings:    result.Warnings,
+	}, nil
+}
@@ -4,22 +4,30 @@ import (
 	fiberoapi "github.com/labbs/fiber-oapi"
 	"github.com/labbs/nexo/application/apikey"
 	"github.com/labbs/nexo/application/group"
+	"github.com/labbs/nexo/application/importer"
+	"github.com/labbs/nexo/application/ports"
 	"github.com/labbs/nexo/application/space"
 	"github.com/labbs/nexo/application/user"
+	"github.com/labbs/nexo/application/webhook"
 	"github.com/labbs/nexo/domain"
 	"github.com/labbs/nexo/infrastructure/config"
+	"github.com/labbs/nexo/infrastructure/http"
 	"github.com/rs/zerolog"
 )
 
 type Controller struct {
-	Config            config.Config
-	Logger            zerolog.Logger
-	FiberOapi         *fiberoapi.OApiGroup
-	UserApplication   *user.UserApplication
-	SpaceApplication  *space.SpaceApplication
-	ApiKeyApplication *apikey.ApiKeyApplication
-	GroupApplication  *group.GroupApplication
-	PermissionPers    domain.PermissionPers
+	Config             config.Config
+	Logger             zerolog.Logger
+	FiberOapi          *fiberoapi.OApiGroup
+	UserApplication    *user.UserApplication
+	SpaceApplication   *space.SpaceApplication
+	ApiKeyApplication  *apikey.ApiKeyApplication
+	GroupApplication   *group.GroupApplication
+	WebhookApplication *webhook.WebhookApplication
+	PermissionPers     domain.PermissionPers
+
+	ImporterApplication   *importer.ImporterApplication
+	CollaborationPresence ports.PresencePort
 }
 
 func SetupAdminRouter(controller Controller) {
@@ -30,6 +38,7 @@ func SetupAdminRouter(controller Controller) {
 		OperationID:   "admin.listUsers",
 		Tags:          []string{"Admin"},
 		RequiredRoles: []string{"admin"},
+		Security:      http.SessionOnly(),
 	})
 	fiberoapi.Put(controller.FiberOapi, "/users/:user_id/role", controller.UpdateUserRole, fiberoapi.OpenAPIOptions{
 		Summary:       "Update user role",
@@ -37,6 +46,7 @@ func SetupAdminRouter(controller Controller) {
 		OperationID:   "admin.updateUserRole",
 		Tags:          []string{"Admin"},
 		RequiredRoles: []string{"admin"},
+		Security:      http.SessionOnly(),
 	})
 	fiberoapi.Put(controller.FiberOapi, "/users/:user_id/active", controller.UpdateUserActive, fiberoapi.OpenAPIOptions{
 		Summary:       "Update user active status",
@@ -44,6 +54,7 @@ func SetupAdminRouter(controller Controller) {
 		OperationID:   "admin.updateUserActive",
 		Tags:          []string{"Admin"},
 		RequiredRoles: []string{"admin"},
+		Security:      http.SessionOnly(),
 	})
 	fiberoapi.Delete(controller.FiberOapi, "/users/:user_id", controller.DeleteUser, fiberoapi.OpenAPIOptions{
 		Summary:       "Delete user",
@@ -51,6 +62,7 @@ func SetupAdminRouter(controller Controller) {
 		OperationID:   "admin.deleteUser",
 		Tags:          []string{"Admin"},
 		RequiredRoles: []string{"admin"},
+		Security:      http.SessionOnly(),
 	})
 	fiberoapi.Post(controller.FiberOapi, "/users/invite", controller.InviteUser, fiberoapi.OpenAPIOptions{
 		Summary:       "Invite user",
@@ -58,6 +70,7 @@ func SetupAdminRouter(controller Controller) {
 		OperationID:   "admin.inviteUser",
 		Tags:          []string{"Admin"},
 		RequiredRoles: []string{"admin"},
+		Security:      http.SessionOnly(),
 	})
 
 	// Spaces management
@@ -67,6 +80,7 @@ func SetupAdminRouter(controller Controller) {
 		OperationID:   "admin.listAllSpaces",
 		Tags:          []string{"Admin"},
 		RequiredRoles: []string{"admin"},
+		Security:      http.SessionOnly(),
 	})
 	fiberoapi.Post(controller.FiberOapi, "/spaces", controller.AdminCreateSpace, fiberoapi.OpenAPIOptions{
 		Summary:       "Create space",
@@ -74,6 +88,7 @@ func SetupAdminRouter(controller Controller) {
 		OperationID:   "admin.createSpace",
 		Tags:          []string{"Admin"},
 		RequiredRoles: []string{"admin"},
+		Security:      http.SessionOnly(),
 	})
 	fiberoapi.Put(controller.FiberOapi, "/spaces/:space_id", controller.AdminUpdateSpace, fiberoapi.OpenAPIOptions{
 		Summary:       "Update space",
@@ -81,6 +96,7 @@ func SetupAdminRouter(controller Controller) {
 		OperationID:   "admin.updateSpace",
 		Tags:          []string{"Admin"},
 		RequiredRoles: []string{"admin"},
+		Security:      http.SessionOnly(),
 	})
 	fiberoapi.Delete(controller.FiberOapi, "/spaces/:space_id", controller.AdminDeleteSpace, fiberoapi.OpenAPIOptions{
 		Summary:       "Delete space",
@@ -88,6 +104,7 @@ func SetupAdminRouter(controller Controller) {
 		OperationID:   "admin.deleteSpace",
 		Tags:          []string{"Admin"},
 		RequiredRoles: []string{"admin"},
+		Security:      http.SessionOnly(),
 	})
 	fiberoapi.Get(controller.FiberOapi, "/spaces/:space_id/permissions", controller.AdminListSpacePermissions, fiberoapi.OpenAPIOptions{
 		Summary:       "List space permissions",
@@ -95,6 +112,7 @@ func SetupAdminRouter(controller Controller) {
 		OperationID:   "admin.listSpacePermissions",
 		Tags:          []string{"Admin"},
 		RequiredRoles: []string{"admin"},
+		Security:      http.SessionOnly(),
 	})
 	fiberoapi.Post(controller.FiberOapi, "/spaces/:space_id/permissions/users", controller.AdminAddSpaceUserPermission, fiberoapi.OpenAPIOptions{
 		Summary:       "Add user permission",
@@ -102,6 +120,7 @@ func SetupAdminRouter(controller Controller) {
 		OperationID:   "admin.addSpaceUserPermission",
 		Tags:          []string{"Admin"},
 		RequiredRoles: []string{"admin"},
+		Security:      http.SessionOnly(),
 	})
 	fiberoapi.Delete(controller.FiberOapi, "/spaces/:space_id/permissions/users/:user_id", controller.AdminRemoveSpaceUserPermission, fiberoapi.OpenAPIOptions{
 		Summary:       "Remove user permission",
@@ -109,6 +128,7 @@ func SetupAdminRouter(controller Controller) {
 		OperationID:   "admin.removeSpaceUserPermission",
 		Tags:          []string{"Admin"},
 		RequiredRoles: []string{"admin"},
+		Security:      http.SessionOnly(),
 	})
 	fiberoapi.Post(controller.FiberOapi, "/spaces/:space_id/permissions/groups", controller.AdminAddSpaceGroupPermission, fiberoapi.OpenAPIOptions{
 		Summary:       "Add group permission",
@@ -116,6 +136,7 @@ func SetupAdminRouter(controller Controller) {
 		OperationID:   "admin.addSpaceGroupPermission",
 		Tags:          []string{"Admin"},
 		RequiredRoles: []string{"admin"},
+		Security:      http.SessionOnly(),
 	})
 	fiberoapi.Delete(controller.FiberOapi, "/spaces/:space_id/permissions/groups/:group_id", controller.AdminRemoveSpaceGroupPermission, fiberoapi.OpenAPIOptions{
 		Summary:       "Remove group permission",
@@ -123,6 +144,7 @@ func SetupAdminRouter(controller Controller) {
 		OperationID:   "admin.removeSpaceGroupPermission",
 		Tags:          []string{"Admin"},
 		RequiredRoles: []string{"admin"},
+		Security:      http.SessionOnly(),
 	})
 
 	// API Keys management
@@ -132,6 +154,7 @@ func SetupAdminRouter(controller Controller) {
 		OperationID:   "admin.listAllApiKeys",
 		Tags:          []string{"Admin"},
 		RequiredRoles: []string{"admin"},
+		Security:      http.SessionOnly(),
 	})
 	fiberoapi.Delete(controller.FiberOapi, "/apikeys/:apikey_id", controller.RevokeApiKey, fiberoapi.OpenAPIOptions{
 		Summary:       "Revoke API key",
@@ -139,6 +162,27 @@ func SetupAdminRouter(controller Controller) {
 		OperationID:   "admin.revokeApiKey",
 		Tags:          []string{"Admin"},
 		RequiredRoles: []string{"admin"},
+		Security:      http.SessionOnly(),
+	})
+
+	// Webhooks management
+	fiberoapi.Post(controller.FiberOapi, "/webhooks/deliveries/:delivery_id/redeliver", controller.RedeliverWebhook, fiberoapi.OpenAPIOptions{
+		Summary:       "Redeliver webhook delivery",
+		Description:   "Queue a new delivery with the payload of an existing one (admin only)",
+		OperationID:   "admin.redeliverWebhook",
+		Tags:          []string{"Admin"},
+		RequiredRoles: []string{"admin"},
+		Security:      http.SessionOnly(),
+	})
+
+	// Collaboration
+	fiberoapi.Get(controller.FiberOapi, "/collaboration/rooms", controller.ListCollaborationRooms, fiberoapi.OpenAPIOptions{
+		Summary:       "List active collaboration rooms",
+		Description:   "Retrieve the collaboration rooms with their connected users, on every node (admin only)",
+		OperationID:   "admin.listCollaborationRooms",
+		Tags:          []string{"Admin"},
+		RequiredRoles: []string{"admin"},
+		Security:      http.SessionOnly(),
 	})
 
 	// Groups management
@@ -148,6 +192,7 @@ func SetupAdminRouter(controller Controller) {
 		OperationID:   "admin.listGroups",
 		Tags:          []string{"Admin"},
 		RequiredRoles: []string{"admin"},
+		Security:      http.SessionOnly(),
 	})
 	fiberoapi.Post(controller.FiberOapi, "/groups", controller.CreateGroup, fiberoapi.OpenAPIOptions{
 		Summary:       "Create group",
@@ -155,6 +200,7 @@ func SetupAdminRouter(controller Controller) {
 		OperationID:   "admin.createGroup",
 		Tags:          []string{"Admin"},
 		RequiredRoles: []string{"admin"},
+		Security:      http.SessionOnly(),
 	})
 	fiberoapi.Put(controller.FiberOapi, "/groups/:group_id", controller.UpdateGroup, fiberoapi.OpenAPIOptions{
 		Summary:       "Update group",
@@ -162,6 +208,7 @@ func SetupAdminRouter(controller Controller) {
 		OperationID:   "admin.updateGroup",
 		Tags:          []string{"Admin"},
 		RequiredRoles: []string{"admin"},
+		Security:      http.SessionOnly(),
 	})
 	fiberoapi.Delete(controller.FiberOapi, "/groups/:group_id", controller.DeleteGroup, fiberoapi.OpenAPIOptions{
 		Summary:       "Delete group",
@@ -169,6 +216,7 @@ func SetupAdminRouter(controller Controller) {
 		OperationID:   "admin.deleteGroup",
 		Tags:          []string{"Admin"},
 		RequiredRoles: []string{"admin"},
+		Security:      http.SessionOnly(),
 	})
 	fiberoapi.Get(controller.FiberOapi, "/groups/:group_id/members", controller.GetGroupMembers, fiberoapi.OpenAPIOptions{
 		Summary:       "Get group members",
@@ -176,6 +224,7 @@ func SetupAdminRouter(controller Controller) {
 		OperationID:   "admin.getGroupMembers",
 		Tags:          []string{"Admin"},
 		RequiredRoles: []string{"admin"},
+		Security:      http.SessionOnly(),
 	})
 	fiberoapi.Post(controller.FiberOapi, "/groups/:group_id/members", controller.AddGroupMember, fiberoapi.OpenAPIOptions{
 		Summary:       "Add group member",
@@ -183,6 +232,7 @@ func SetupAdminRouter(controller Controller) {
 		OperationID:   "admin.addGroupMember",
 		Tags:          []string{"Admin"},
 		RequiredRoles: []string{"admin"},
+		Security:      http.SessionOnly(),
 	})
 	fiberoapi.Delete(controller.FiberOapi, "/groups/:group_id/members/:user_id", controller.RemoveGroupMember, fiberoapi.OpenAPIOptions{
 		Summary:       "Remove group member",
@@ -190,5 +240,16 @@ func SetupAdminRouter(controller Controller) {
 		OperationID:   "admin.removeGroupMember",
 		Tags:          []string{"Admin"},
 		RequiredRoles: []string{"admin"},
+		Security:      http.SessionOnly(),
+	})
+
+	// Imports
+	fiberoapi.Post(controller.FiberOapi, "/import", controller.Import, fiberoapi.OpenAPIOptions{
+		Summary:       "Import a Notion or Confluence export",
+		Description:   "Import the pages, databases and files of a Notion (Markdown & CSV) or Confluence (HTML) export zip in a space (admin only)",
+		OperationID:   "admin.import",
+		Tags:          []string{"Admin"},
+		RequiredRoles: []string{"admin"},
+		Security:      http.SessionOnly(),
 	})
 }
@@ -69,6 +69,9 @@ func (ctrl *Controller) CreateApiKey(ctx *fiber.Ctx, req dtos.CreateApiKeyReques
 		ExpiresAt: req.ExpiresAt,
 	})
 	if err != nil {
+		if errors.Is(err, apperrors.ErrInvalidInput) {
+			return nil, &fiberoapi.ErrorResponse{Code: fiber.StatusBadRequest, Details: err.Error(), Type: "BAD_REQUEST"}
+		}
 		logger.Error().Err(err).Msg("failed to create API key")
 		return nil, &fiberoapi.ErrorResponse{Code: fiber.StatusInternalServerError, Details: "Failed to create API key", Type: "INTERNAL_SERVER_ERROR"}
 	}
@@ -107,6 +110,9 @@ func (ctrl *Controller) UpdateApiKey(ctx *fiber.Ctx, req dtos.UpdateApiKeyReques
 		if errors.Is(err, apperrors.ErrNotFound) {
 			return nil, &fiberoapi.ErrorResponse{Code: fiber.StatusNotFound, Details: "API key not found", Type: "NOT_FOUND"}
 		}
+		if errors.Is(err, apperrors.ErrInvalidInput) {
+			return nil, &fiberoapi.ErrorResponse{Code: fiber.StatusBadRequest, Details: err.Error(), Type: "BAD_REQUEST"}
+		}
 		logger.Error().Err(err).Msg("failed to update API key")
 		return nil, &fiberoapi.ErrorResponse{Code: fiber.StatusInternalServerError, Details: "Failed to update API key", Type: "INTERNAL_SERVER_ERROR"}
 	}
@@ -151,7 +157,11 @@ func (ctrl *Controller) GetAvailableScopes(ctx *fiber.Ctx, _ dtos.EmptyRequest)
 		{Scope: "read:comments", Description: "Read access to comments"},
 		{Scope: "write:comments", Description: "Write access to comments"},
 		{Scope: "manage:webhooks", Description: "Manage webhooks"},
-		{Scope: "manage:databases", Description: "Manage databases"},
+		{Scope: "read:databases", Description: "Read access to databases and their rows"},
+		{Scope: "manage:databases", Description: "Manage databases (schema, views, rows)"},
+		{Scope: "read:drawings", Description: "Read access to drawings"},
+		{Scope: "write:drawings", Description: "Write access to drawings (create, update, delete)"},
+		{Scope: "manage:actions", Description: "Manage automations"},
 	}
 
 	return &dtos.AvailableScopesResponse{Scopes: scopes}, nil
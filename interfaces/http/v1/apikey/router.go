@@ -4,6 +4,7 @@ import (
 	fiberoapi "github.com/labbs/fiber-oapi"
 	"github.com/labbs/nexo/application/apikey"
 	"github.com/labbs/nexo/infrastructure/config"
+	"github.com/labbs/nexo/infrastructure/http"
 	"github.com/rs/zerolog"
 )
 
@@ -20,24 +21,29 @@ func SetupApiKeyRouter(controller Controller) {
 		Description: "List all API keys for the current user",
 		OperationID: "apikey.list",
 		Tags:        []string{"API Keys"},
+		Security:    http.SessionOnly(),
 	})
 	fiberoapi.Post(controller.FiberOapi, "/", controller.CreateApiKey, fiberoapi.OpenAPIOptions{
-		Summary:     "Create API key",
-		Description: "Create a new API key. The key is only shown once.",
-		OperationID: "apikey.create",
-		Tags:        []string{"API Keys"},
+		Summary:       "Create API key",
+		Description:   "Create a new API key. The key is only shown once.",
+		OperationID:   "apikey.create",
+		Tags:          []string{"API Keys"},
+		RequiredRoles: http.MemberRoles(),
+		Security:      http.SessionOnly(),
 	})
 	fiberoapi.Put(controller.FiberOapi, "/:api_key_id", controller.UpdateApiKey, fiberoapi.OpenAPIOptions{
 		Summary:     "Update API key",
 		Description: "Update an API key's name or scopes",
 		OperationID: "apikey.update",
 		Tags:        []string{"API Keys"},
+		Security:    http.SessionOnly(),
 	})
 	fiberoapi.Delete(controller.FiberOapi, "/:api_key_id", controller.DeleteApiKey, fiberoapi.OpenAPIOptions{
 		Summary:     "Delete API key",
 		Description: "Revoke and delete an API key",
 		OperationID: "apikey.delete",
 		Tags:        []string{"API Keys"},
+		Security:    http.SessionOnly(),
 	})
 	fiberoapi.Get(controller.FiberOapi, "/scopes", controller.GetAvailableScopes, fiberoapi.OpenAPIOptions{
 		Summary:     "Get available scopes",
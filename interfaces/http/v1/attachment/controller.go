@@ -0,0 +1,17 @@
+package attachment
+
+import (
+	fiberoapi "github.com/labbs/fiber-oapi"
+	"github.com/labbs/nexo/application/attachment"
+	"github.com/labbs/nexo/application/session"
+	"github.com/labbs/nexo/infrastructure/config"
+	"github.com/rs/zerolog"
+)
+
+type Controller struct {
+	Config                config.Config
+	Logger                zerolog.Logger
+	FiberOapi             *fiberoapi.OApiGroup
+	AttachmentApplication *attachment.AttachmentApplication
+	SessionApplication    *session.SessionApplication
+}
@@ -0,0 +1,57 @@
+package dtos
+
+import "time"
+
+// Request DTOs
+
+// UploadAttachmentRequest is a multipart form: the file is sent in the "file" field
+type UploadAttachmentRequest struct {
+	ResourceType string `form:"resource_type" validate:"required,oneof=document drawing database"`
+	ResourceId   string `form:"resource_id" validate:"required,uuid4"`
+}
+
+type ListAttachmentsRequest struct {
+	ResourceType string `query:"resource_type" validate:"required,oneof=document drawing database"`
+	ResourceId   string `query:"resource_id" validate:"required,uuid4"`
+}
+
+type GetAttachmentRequest struct {
+	AttachmentId string `path:"attachment_id" validate:"required,uuid4"`
+}
+
+type DeleteAttachmentRequest struct {
+	AttachmentId string `path:"attachment_id" validate:"required,uuid4"`
+}
+
+// Response DTOs
+
+type Attachment struct {
+	Id            string    `json:"id"`
+	SpaceId       string    `json:"space_id"`
+	ResourceType  string    `json:"resource_type"`
+	ResourceId    string    `json:"resource_id"`
+	Name          string    `json:"name"`
+	ContentType   string    `json:"content_type"`
+	Size          int64     `json:"size"`
+	CreatedBy     string    `json:"created_by"`
+	CreatedByName string    `json:"created_by_name"`
+	CreatedAt     time.Time `json:"created_at"`
+	// Url downloads the content of the file
+	Url string `json:"url"`
+}
+
+type UploadAttachmentResponse struct {
+	Attachment Attachment `json:"attachment"`
+}
+
+type ListAttachmentsResponse struct {
+	Attachments []Attachment `json:"attachments"`
+}
+
+type GetAttachmentResponse struct {
+	Attachment Attachment `json:"attachment"`
+}
+
+type DeleteAttachmentResponse struct {
+	Message string `json:"message"`
+}
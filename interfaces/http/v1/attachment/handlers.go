@@ -0,0 +1,251 @@
+package attachment
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	fiberoapi "github.com/labbs/fiber-oapi"
+	attachmentDto "github.com/labbs/nexo/application/attachment/dto"
+	sessionDto "github.com/labbs/nexo/application/session/dto"
+	"github.com/labbs/nexo/domain"
+	"github.com/labbs/nexo/infrastructure/helpers/apperrors"
+	"github.com/labbs/nexo/interfaces/http/v1/attachment/dtos"
+)
+
+// Scopes an API key needs to read and change the attachments of each resource type
+var (
+	readScopes = map[string]domain.ApiKeyScope{
+		string(domain.AttachmentResourceDocument): domain.ApiKeyScopeReadDocuments,
+		string(domain.AttachmentResourceDrawing):  domain.ApiKeyScopeReadDrawings,
+		string(domain.AttachmentResourceDatabase): domain.ApiKeyScopeReadDatabases,
+	}
+	writeScopes = map[string]domain.ApiKeyScope{
+		string(domain.AttachmentResourceDocument): domain.ApiKeyScopeWriteDocuments,
+		string(domain.AttachmentResourceDrawing):  domain.ApiKeyScopeWriteDrawings,
+		string(domain.AttachmentResourceDatabase): domain.ApiKeyScopeManageDatabases,
+	}
+)
+
+// Content types shown in the browser, others are downloaded
+var inlineContentTypes = []string{"image/png", "image/jpeg", "image/gif", "image/webp", "image/avif", "application/pdf", "audio/", "video/"}
+
+func (ctrl *Controller) Upload(ctx *fiber.Ctx, req dtos.UploadAttachmentRequest) (*dtos.UploadAttachmentResponse, *fiberoapi.ErrorResponse) {
+	requestId := ctx.Locals("requestid").(string)
+	logger := ctrl.Logger.With().Str("request_id", requestId).Str("component", "http.api.v1.attachment.upload").Logger()
+
+	authCtx, err := fiberoapi.GetAuthContext(ctx)
+	if err != nil {
+		logger.Error().Err(err).Msg("failed to get auth context")
+		return nil, &fiberoapi.ErrorResponse{Code: fiber.StatusUnauthorized, Details: "Authentication required", Type: "AUTHENTICATION_REQUIRED"}
+	}
+	if errResp := ctrl.requireScope(authCtx, writeScopes[req.ResourceType]); errResp != nil {
+		return nil, errResp
+	}
+
+	header, err := ctx.FormFile("file")
+	if err != nil {
+		return nil, &fiberoapi.ErrorResponse{Code: fiber.StatusBadRequest, Details: "Missing file", Type: "BAD_REQUEST"}
+	}
+	file, err := header.Open()
+	if err != nil {
+		logger.Error().Err(err).Msg("failed to open uploaded file")
+		return nil, &fiberoapi.ErrorResponse{Code: fiber.StatusBadRequest, Details: "Invalid file", Type: "BAD_REQUEST"}
+	}
+	defer file.Close()
+
+	result, err := ctrl.AttachmentApplication.Upload(attachmentDto.UploadInput{
+		UserId:       authCtx.UserID,
+		ResourceType: req.ResourceType,
+		ResourceId:   req.ResourceId,
+		Name:         header.Filename,
+		ContentType:  header.Header.Get("Content-Type"),
+		Size:         header.Size,
+		Content:      file,
+	})
+	if err != nil {
+		logger.Error().Err(err).Str("resource_type", req.ResourceType).Str("resource_id", req.ResourceId).Msg("failed to upload attachment")
+		return nil, errorResponse(err, "Failed to upload attachment")
+	}
+
+	return &dtos.UploadAttachmentResponse{Attachment: toResponse(result.Attachment)}, nil
+}
+
+func (ctrl *Controller) ListAttachments(ctx *fiber.Ctx, req dtos.ListAttachmentsRequest) (*dtos.ListAttachmentsResponse, *fiberoapi.ErrorResponse) {
+	requestId := ctx.Locals("requestid").(string)
+	logger := ctrl.Logger.With().Str("request_id", requestId).Str("component", "http.api.v1.attachment.list").Logger()
+
+	authCtx, err := fiberoapi.GetAuthContext(ctx)
+	if err != nil {
+		logger.Error().Err(err).Msg("failed to get auth context")
+		return nil, &fiberoapi.ErrorResponse{Code: fiber.StatusUnauthorized, Details: "Authentication required", Type: "AUTHENTICATION_REQUIRED"}
+	}
+	if errResp := ctrl.requireScope(authCtx, readScopes[req.ResourceType]); errResp != nil {
+		return nil, errResp
+	}
+
+	result, err := ctrl.AttachmentApplication.ListAttachments(attachmentDto.ListAttachmentsInput{
+		UserId:       authCtx.UserID,
+		ResourceType: req.ResourceType,
+		ResourceId:   req.ResourceId,
+	})
+	if err != nil {
+		logger.Error().Err(err).Str("resource_type", req.ResourceType).Str("resource_id", req.ResourceId).Msg("failed to list attachments")
+		return nil, errorResponse(err, "Failed to list attachments")
+	}
+
+	resp := &dtos.ListAttachmentsResponse{Attachments: make([]dtos.Attachment, len(result.Attachments))}
+	for i, a := range result.Attachments {
+		resp.Attachments[i] = toResponse(a)
+	}
+	return resp, nil
+}
+
+func (ctrl *Controller) GetAttachment(ctx *fiber.Ctx, req dtos.GetAttachmentRequest) (*dtos.GetAttachmentResponse, *fiberoapi.ErrorResponse) {
+	requestId := ctx.Locals("requestid").(string)
+	logger := ctrl.Logger.With().Str("request_id", requestId).Str("component", "http.api.v1.attachment.get").Logger()
+
+	authCtx, err := fiberoapi.GetAuthContext(ctx)
+	if err != nil {
+		logger.Error().Err(err).Msg("failed to get auth context")
+		return nil, &fiberoapi.ErrorResponse{Code: fiber.StatusUnauthorized, Details: "Authentication required", Type: "AUTHENTICATION_REQUIRED"}
+	}
+
+	result, err := ctrl.AttachmentApplication.GetAttachment(attachmentDto.GetAttachmentInput{
+		UserId:       authCtx.UserID,
+		AttachmentId: req.AttachmentId,
+	})
+	if err != nil {
+		logger.Error().Err(err).Str("attachment_id", req.AttachmentId).Msg("failed to get attachment")
+		return nil, errorResponse(err, "Failed to get attachment")
+	}
+	if errResp := ctrl.requireScope(authCtx, readScopes[result.Attachment.ResourceType]); errResp != nil {
+		return nil, errResp
+	}
+
+	return &dtos.GetAttachmentResponse{Attachment: toResponse(result.Attachment)}, nil
+}
+
+func (ctrl *Controller) DeleteAttachment(ctx *fiber.Ctx, req dtos.DeleteAttachmentRequest) (*dtos.DeleteAttachmentResponse, *fiberoapi.ErrorResponse) {
+	requestId := ctx.Locals("requestid").(string)
+	logger := ctrl.Logger.With().Str("request_id", requestId).Str("component", "http.api.v1.attachment.delete").Logger()
+
+	authCtx, err := fiberoapi.GetAuthContext(ctx)
+	if err != nil {
+		logger.Error().Err(err).Msg("failed to get auth context")
+		return nil, &fiberoapi.ErrorResponse{Code: fiber.StatusUnauthorized, Details: "Authentication required", Type: "AUTHENTICATION_REQUIRED"}
+	}
+
+	// The scope depends on the resource of the attachment
+	attachment, err := ctrl.AttachmentApplication.GetAttachment(attachmentDto.GetAttachmentInput{
+		UserId:       authCtx.UserID,
+		AttachmentId: req.AttachmentId,
+	})
+	if err != nil {
+		logger.Error().Err(err).Str("attachment_id", req.AttachmentId).Msg("failed to get attachment")
+		return nil, errorResponse(err, "Failed to delete attachment")
+	}
+	if errResp := ctrl.requireScope(authCtx, writeScopes[attachment.Attachment.ResourceType]); errResp != nil {
+		return nil, errResp
+	}
+
+	err = ctrl.AttachmentApplication.DeleteAttachment(attachmentDto.DeleteAttachmentInput{
+		UserId:       authCtx.UserID,
+		AttachmentId: req.AttachmentId,
+	})
+	if err != nil {
+		logger.Error().Err(err).Str("attachment_id", req.AttachmentId).Msg("failed to delete attachment")
+		return nil, errorResponse(err, "Failed to delete attachment")
+	}
+
+	return &dtos.DeleteAttachmentResponse{Message: "Attachment deleted"}, nil
+}
+
+// Download streams the content of an attachment. The token is read from the
+// Authorization header or, for <img> and <a> elements, the token query
+// parameter.
+func (ctrl *Controller) Download(ctx *fiber.Ctx) error {
+	requestId, _ := ctx.Locals("requestid").(string)
+	logger := ctrl.Logger.With().Str("request_id", requestId).Str("component", "http.api.v1.attachment.download").Logger()
+
+	token := strings.TrimPrefix(ctx.Get(fiber.HeaderAuthorization), "Bearer ")
+	if token == "" {
+		token = ctx.Query("token")
+	}
+	if token == "" {
+		return ctx.Status(fiber.StatusUnauthorized).JSON(fiberoapi.ErrorResponse{Code: fiber.StatusUnauthorized, Details: "Authentication required", Type: "AUTHENTICATION_REQUIRED"})
+	}
+	session, err := ctrl.SessionApplication.ValidateToken(sessionDto.ValidateTokenInput{Token: token})
+	if err != nil {
+		return ctx.Status(fiber.StatusUnauthorized).JSON(fiberoapi.ErrorResponse{Code: fiber.StatusUnauthorized, Details: "Invalid token", Type: "AUTHENTICATION_REQUIRED"})
+	}
+	authCtx := session.AuthContext
+
+	result, err := ctrl.AttachmentApplication.GetContent(attachmentDto.GetContentInput{
+		UserId:       authCtx.UserID,
+		AttachmentId: ctx.Params("attachment_id"),
+	})
+	if err != nil {
+		logger.Error().Err(err).Str("attachment_id", ctx.Params("attachment_id")).Msg("failed to get attachment content")
+		errResp := errorResponse(err, "Failed to download attachment")
+		return ctx.Status(errResp.Code).JSON(errResp)
+	}
+	if errResp := ctrl.requireScope(authCtx, readScopes[result.Attachment.ResourceType]); errResp != nil {
+		result.Content.Close()
+		return ctx.Status(errResp.Code).JSON(errResp)
+	}
+
+	disposition := "attachment"
+	for _, inline := range inlineContentTypes {
+		if strings.HasPrefix(result.Attachment.ContentType, inline) {
+			disposition = "inline"
+			break
+		}
+	}
+	ctx.Set(fiber.HeaderContentType, result.Attachment.ContentType)
+	ctx.Set(fiber.HeaderContentDisposition, disposition+`; filename="`+strings.ReplaceAll(result.Attachment.Name, `"`, "")+`"`)
+	ctx.Set(fiber.HeaderCacheControl, "private, max-age=3600")
+	// The content type is the one given by the uploader: browsers must not
+	// guess another one
+	ctx.Set(fiber.HeaderXContentTypeOptions, "nosniff")
+	return ctx.SendStream(result.Content, int(result.Attachment.Size))
+}
+
+// requireScope checks that an API key was granted the scope; sessions hold
+// every scope.
+func (ctrl *Controller) requireScope(authCtx *fiberoapi.AuthContext, scope domain.ApiKeyScope) *fiberoapi.ErrorResponse {
+	if !ctrl.SessionApplication.HasScope(sessionDto.HasScopeInput{Context: authCtx, Scope: string(scope)}) {
+		return &fiberoapi.ErrorResponse{Code: fiber.StatusForbidden, Details: "missing required scope: " + string(scope), Type: "FORBIDDEN"}
+	}
+	return nil
+}
+
+func errorResponse(err error, message string) *fiberoapi.ErrorResponse {
+	switch {
+	case errors.Is(err, apperrors.ErrAccessDenied):
+		return &fiberoapi.ErrorResponse{Code: fiber.StatusForbidden, Details: "Forbidden", Type: "FORBIDDEN"}
+	case errors.Is(err, apperrors.ErrInvalidInput):
+		return &fiberoapi.ErrorResponse{Code: fiber.StatusBadRequest, Details: err.Error(), Type: "BAD_REQUEST"}
+	case errors.Is(err, apperrors.ErrNotFound), errors.Is(err, apperrors.ErrDocumentNotFound),
+		errors.Is(err, apperrors.ErrDrawingNotFound), errors.Is(err, apperrors.ErrDatabaseNotFound):
+		return &fiberoapi.ErrorResponse{Code: fiber.StatusNotFound, Details: "Not found", Type: "NOT_FOUND"}
+	default:
+		return &fiberoapi.ErrorResponse{Code: fiber.StatusInternalServerError, Details: message, Type: "INTERNAL_SERVER_ERROR"}
+	}
+}
+
+func toResponse(a attachmentDto.Attachment) dtos.Attachment {
+	return dtos.Attachment{
+		Id:            a.Id,
+		SpaceId:       a.SpaceId,
+		ResourceType:  a.ResourceType,
+		ResourceId:    a.ResourceId,
+		Name:          a.Name,
+		ContentType:   a.ContentType,
+		Size:          a.Size,
+		CreatedBy:     a.CreatedBy,
+		CreatedByName: a.CreatedByName,
+		CreatedAt:     a.CreatedAt,
+		Url:           strings.Replace(contentPath, ":attachment_id", a.Id, 1),
+	}
+}
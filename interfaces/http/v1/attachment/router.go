@@ -0,0 +1,39 @@
+package attachment
+
+import (
+	"github.com/gofiber/fiber/v2"
+	fiberoapi "github.com/labbs/fiber-oapi"
+)
+
+// contentPath is the download route of attachments. It streams the file, so
+// it is a plain Fiber route outside of the OpenAPI routes.
+const contentPath = "/api/v1/attachment/:attachment_id/content"
+
+func SetupAttachmentRouter(ctrl Controller, app *fiber.App) {
+	fiberoapi.Post(ctrl.FiberOapi, "/", ctrl.Upload, fiberoapi.OpenAPIOptions{
+		Summary:     "Upload an attachment",
+		Description: "Upload a file (multipart field \"file\") attached to a document, a drawing or a database the user can edit. The content is downloaded from the url of the attachment.",
+		OperationID: "attachment.upload",
+		Tags:        []string{"Attachment"},
+	})
+	fiberoapi.Get(ctrl.FiberOapi, "/", ctrl.ListAttachments, fiberoapi.OpenAPIOptions{
+		Summary:     "List attachments",
+		Description: "List the files attached to a document, a drawing or a database",
+		OperationID: "attachment.list",
+		Tags:        []string{"Attachment"},
+	})
+	fiberoapi.Get(ctrl.FiberOapi, "/:attachment_id", ctrl.GetAttachment, fiberoapi.OpenAPIOptions{
+		Summary:     "Get an attachment",
+		Description: "Get the metadata of an attachment",
+		OperationID: "attachment.get",
+		Tags:        []string{"Attachment"},
+	})
+	fiberoapi.Delete(ctrl.FiberOapi, "/:attachment_id", ctrl.DeleteAttachment, fiberoapi.OpenAPIOptions{
+		Summary:     "Delete an attachment",
+		Description: "Delete an attachment and its content",
+		OperationID: "attachment.delete",
+		Tags:        []string{"Attachment"},
+	})
+
+	app.Get(contentPath, ctrl.Download)
+}
@@ -4,6 +4,7 @@ import (
 	fiberoapi "github.com/labbs/fiber-oapi"
 	"github.com/labbs/nexo/application/auth"
 	"github.com/labbs/nexo/infrastructure/config"
+	"github.com/labbs/nexo/infrastructure/http"
 	"github.com/rs/zerolog"
 )
 
@@ -28,6 +29,7 @@ func SetupAuthRouter(controller Controller) {
 		Description: "Invalidate user session",
 		OperationID: "auth.logout",
 		Tags:        []string{"Auth"},
+		Security:    http.SessionOnly(),
 	})
 
 	fiberoapi.Post(controller.FiberOapi, "/register", controller.Register, fiberoapi.OpenAPIOptions{
@@ -39,8 +39,10 @@ type CreateDatabaseRequest struct {
 	Type        string           `json:"type,omitempty"` // "spreadsheet" or "document", defaults to "spreadsheet"
 }
 
+// ListDatabasesRequest has no space access tag: the databases shared with
+// users who cannot read the space are listed too
 type ListDatabasesRequest struct {
-	SpaceId string `query:"space_id" resource:"space" action:"read"`
+	SpaceId string `query:"space_id"`
 }
 
 type GetDatabaseRequest struct {
@@ -136,11 +138,13 @@ type MoveDatabaseResponse struct {
 	DocumentId *string `json:"document_id,omitempty"`
 }
 
-// Filter rule for querying rows
+// Filter rule for querying rows, or a nested group when And or Or is set
 type FilterRule struct {
-	Property  string `json:"property"`
-	Condition string `json:"condition"` // eq, neq, gt, lt, gte, lte, contains, is_empty, is_not_empty
-	Value     any    `json:"value,omitempty"`
+	Property  string       `json:"property,omitempty"`
+	Condition string       `json:"condition,omitempty"` // see GET /types for the conditions of each property type
+	Value     any          `json:"value,omitempty"`
+	And       []FilterRule `json:"and,omitempty"`
+	Or        []FilterRule `json:"or,omitempty"`
 }
 
 type FilterConfig struct {
@@ -255,12 +259,21 @@ type CreateViewResponse struct {
 
 // Available property types
 type AvailableTypesResponse struct {
-	Types []TypeInfo `json:"types"`
+	Types         []TypeInfo `json:"types"`
+	RelativeDates []string   `json:"relative_dates"` // values of the "relative" filter condition
 }
 
 type TypeInfo struct {
-	Type        string `json:"type"`
-	Description string `json:"description"`
+	Type        string          `json:"type"`
+	Description string          `json:"description"`
+	Conditions  []ConditionInfo `json:"conditions"` // filter conditions allowed on the type
+}
+
+// ConditionInfo describes a filter condition and the value it expects
+// (none, text, number, boolean, option, options, date, range or relative)
+type ConditionInfo struct {
+	Condition string `json:"condition"`
+	Value     string `json:"value"`
 }
 
 // Search
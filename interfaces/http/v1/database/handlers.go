@@ -7,6 +7,7 @@ import (
 	fiberoapi "github.com/labbs/fiber-oapi"
 	"github.com/labbs/nexo/infrastructure/helpers/apperrors"
 	databaseDto "github.com/labbs/nexo/application/database/dto"
+	"github.com/labbs/nexo/domain"
 	"github.com/labbs/nexo/interfaces/http/v1/database/dtos"
 )
 
@@ -53,6 +54,9 @@ func (ctrl *Controller) CreateDatabase(ctx *fiber.Ctx, req dtos.CreateDatabaseRe
 		if errors.Is(err, apperrors.ErrAccessDenied) {
 			return nil, &fiberoapi.ErrorResponse{Code: fiber.StatusForbidden, Details: "Forbidden", Type: "FORBIDDEN"}
 		}
+		if errors.Is(err, apperrors.ErrInvalidInput) {
+			return nil, &fiberoapi.ErrorResponse{Code: fiber.StatusBadRequest, Details: err.Error(), Type: "BAD_REQUEST"}
+		}
 		logger.Error().Err(err).Msg("failed to create database")
 		return nil, &fiberoapi.ErrorResponse{Code: fiber.StatusInternalServerError, Details: "Failed to create database", Type: "INTERNAL_SERVER_ERROR"}
 	}
@@ -236,6 +240,9 @@ func (ctrl *Controller) UpdateDatabase(ctx *fiber.Ctx, req dtos.UpdateDatabaseRe
 		if errors.Is(err, apperrors.ErrNotFound) {
 			return nil, &fiberoapi.ErrorResponse{Code: fiber.StatusNotFound, Details: "Database not found", Type: "NOT_FOUND"}
 		}
+		if errors.Is(err, apperrors.ErrInvalidInput) {
+			return nil, &fiberoapi.ErrorResponse{Code: fiber.StatusBadRequest, Details: err.Error(), Type: "BAD_REQUEST"}
+		}
 		logger.Error().Err(err).Msg("failed to update database")
 		return nil, &fiberoapi.ErrorResponse{Code: fiber.StatusInternalServerError, Details: "Failed to update database", Type: "INTERNAL_SERVER_ERROR"}
 	}
@@ -285,8 +292,8 @@ func (ctrl *Controller) GetAvailableTypes(ctx *fiber.Ctx, _ dtos.EmptyRequest) (
 		{Type: "email", Description: "Email address"},
 		{Type: "phone", Description: "Phone number"},
 		{Type: "image", Description: "Image URL with preview"},
-		{Type: "relation", Description: "Relation to another database"},
-		{Type: "rollup", Description: "Rollup from relation"},
+		{Type: "relation", Description: "Relation to rows of a database of the same space"},
+		{Type: "rollup", Description: "Count, sum, average, min, max or percent checked of related rows"},
 		{Type: "formula", Description: "Calculated formula"},
 		{Type: "created_time", Description: "Auto-populated creation time"},
 		{Type: "updated_time", Description: "Auto-populated update time"},
@@ -296,7 +303,17 @@ func (ctrl *Controller) GetAvailableTypes(ctx *fiber.Ctx, _ dtos.EmptyRequest) (
 		{Type: "person", Description: "Person/user reference"},
 	}
 
-	return &dtos.AvailableTypesResponse{Types: types}, nil
+	for i, t := range types {
+		types[i].Conditions = []dtos.ConditionInfo{}
+		for _, condition := range domain.PropertyType(t.Type).FilterConditions() {
+			types[i].Conditions = append(types[i].Conditions, dtos.ConditionInfo{
+				Condition: condition.Condition,
+				Value:     string(condition.Value),
+			})
+		}
+	}
+
+	return &dtos.AvailableTypesResponse{Types: types, RelativeDates: domain.RelativeDates}, nil
 }
 
 // View handlers
@@ -343,6 +360,9 @@ func (ctrl *Controller) CreateView(ctx *fiber.Ctx, req dtos.CreateViewRequest) (
 		if errors.Is(err, apperrors.ErrNotFound) {
 			return nil, &fiberoapi.ErrorResponse{Code: fiber.StatusNotFound, Details: "Database not found", Type: "NOT_FOUND"}
 		}
+		if errors.Is(err, apperrors.ErrInvalidInput) {
+			return nil, &fiberoapi.ErrorResponse{Code: fiber.StatusBadRequest, Details: err.Error(), Type: "BAD_REQUEST"}
+		}
 		logger.Error().Err(err).Msg("failed to create view")
 		return nil, &fiberoapi.ErrorResponse{Code: fiber.StatusInternalServerError, Details: "Failed to create view", Type: "INTERNAL_SERVER_ERROR"}
 	}
@@ -406,6 +426,9 @@ func (ctrl *Controller) UpdateView(ctx *fiber.Ctx, req dtos.UpdateViewRequest) (
 		if errors.Is(err, apperrors.ErrNotFound) {
 			return nil, &fiberoapi.ErrorResponse{Code: fiber.StatusNotFound, Details: "View not found", Type: "NOT_FOUND"}
 		}
+		if errors.Is(err, apperrors.ErrInvalidInput) {
+			return nil, &fiberoapi.ErrorResponse{Code: fiber.StatusBadRequest, Details: err.Error(), Type: "BAD_REQUEST"}
+		}
 		logger.Error().Err(err).Msg("failed to update view")
 		return nil, &fiberoapi.ErrorResponse{Code: fiber.StatusInternalServerError, Details: "Failed to update view", Type: "INTERNAL_SERVER_ERROR"}
 	}
@@ -503,6 +526,9 @@ func (ctrl *Controller) ListRows(ctx *fiber.Ctx, req dtos.ListRowsRequest) (*dto
 		if errors.Is(err, apperrors.ErrAccessDenied) {
 			return nil, &fiberoapi.ErrorResponse{Code: fiber.StatusForbidden, Details: "Forbidden", Type: "FORBIDDEN"}
 		}
+		if errors.Is(err, apperrors.ErrInvalidInput) {
+			return nil, &fiberoapi.ErrorResponse{Code: fiber.StatusBadRequest, Details: err.Error(), Type: "BAD_REQUEST"}
+		}
 		if errors.Is(err, apperrors.ErrNotFound) {
 			return nil, &fiberoapi.ErrorResponse{Code: fiber.StatusNotFound, Details: "Database not found", Type: "NOT_FOUND"}
 		}
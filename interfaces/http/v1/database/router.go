@@ -1,6 +1,10 @@
 package database
 
-import fiberoapi "github.com/labbs/fiber-oapi"
+import (
+	fiberoapi "github.com/labbs/fiber-oapi"
+	"github.com/labbs/nexo/domain"
+	"github.com/labbs/nexo/infrastructure/http"
+)
 
 func SetupDatabaseRouter(ctrl Controller) {
 	// Database endpoints
@@ -9,6 +13,7 @@ func SetupDatabaseRouter(ctrl Controller) {
 		Description: "List all databases in a space",
 		OperationID: "database.list",
 		Tags:        []string{"Databases"},
+		Security:    http.RequireScopes(domain.ApiKeyScopeReadDatabases),
 	})
 
 	fiberoapi.Post(ctrl.FiberOapi, "/", ctrl.CreateDatabase, fiberoapi.OpenAPIOptions{
@@ -16,13 +21,15 @@ func SetupDatabaseRouter(ctrl Controller) {
 		Description: "Create a new database",
 		OperationID: "database.create",
 		Tags:        []string{"Databases"},
+		Security:    http.RequireScopes(domain.ApiKeyScopeManageDatabases),
 	})
 
 	fiberoapi.Get(ctrl.FiberOapi, "/types", ctrl.GetAvailableTypes, fiberoapi.OpenAPIOptions{
 		Summary:     "Get available property types",
-		Description: "List all available property types for database columns",
+		Description: "List all available property types for database columns, with the filter conditions allowed on each type",
 		OperationID: "database.types",
 		Tags:        []string{"Databases"},
+		Security:    http.RequireScopes(domain.ApiKeyScopeReadDatabases),
 	})
 
 	fiberoapi.Get(ctrl.FiberOapi, "/search", ctrl.SearchDatabases, fiberoapi.OpenAPIOptions{
@@ -30,6 +37,7 @@ func SetupDatabaseRouter(ctrl Controller) {
 		Description: "Search databases by name or description",
 		OperationID: "database.search",
 		Tags:        []string{"Databases", "Search"},
+		Security:    http.RequireScopes(domain.ApiKeyScopeReadDatabases),
 	})
 
 	fiberoapi.Get(ctrl.FiberOapi, "/:database_id", ctrl.GetDatabase, fiberoapi.OpenAPIOptions{
@@ -37,6 +45,7 @@ func SetupDatabaseRouter(ctrl Controller) {
 		Description: "Get a specific database by ID",
 		OperationID: "database.get",
 		Tags:        []string{"Databases"},
+		Security:    http.RequireScopes(domain.ApiKeyScopeReadDatabases),
 	})
 
 	fiberoapi.Put(ctrl.FiberOapi, "/:database_id", ctrl.UpdateDatabase, fiberoapi.OpenAPIOptions{
@@ -44,6 +53,7 @@ func SetupDatabaseRouter(ctrl Controller) {
 		Description: "Update an existing database",
 		OperationID: "database.update",
 		Tags:        []string{"Databases"},
+		Security:    http.RequireScopes(domain.ApiKeyScopeManageDatabases),
 	})
 
 	fiberoapi.Delete(ctrl.FiberOapi, "/:database_id", ctrl.DeleteDatabase, fiberoapi.OpenAPIOptions{
@@ -51,6 +61,7 @@ func SetupDatabaseRouter(ctrl Controller) {
 		Description: "Delete a database and all its rows",
 		OperationID: "database.delete",
 		Tags:        []string{"Databases"},
+		Security:    http.RequireScopes(domain.ApiKeyScopeManageDatabases),
 	})
 
 	fiberoapi.Patch(ctrl.FiberOapi, "/:database_id/move", ctrl.MoveDatabase, fiberoapi.OpenAPIOptions{
@@ -58,6 +69,7 @@ func SetupDatabaseRouter(ctrl Controller) {
 		Description: "Move a database to a document or to root level",
 		OperationID: "database.move",
 		Tags:        []string{"Databases"},
+		Security:    http.RequireScopes(domain.ApiKeyScopeManageDatabases),
 	})
 
 	// View endpoints
@@ -66,6 +78,7 @@ func SetupDatabaseRouter(ctrl Controller) {
 		Description: "Create a new view for a database",
 		OperationID: "database.view.create",
 		Tags:        []string{"Database Views"},
+		Security:    http.RequireScopes(domain.ApiKeyScopeManageDatabases),
 	})
 
 	fiberoapi.Put(ctrl.FiberOapi, "/:database_id/views/:view_id", ctrl.UpdateView, fiberoapi.OpenAPIOptions{
@@ -73,6 +86,7 @@ func SetupDatabaseRouter(ctrl Controller) {
 		Description: "Update an existing view",
 		OperationID: "database.view.update",
 		Tags:        []string{"Database Views"},
+		Security:    http.RequireScopes(domain.ApiKeyScopeManageDatabases),
 	})
 
 	fiberoapi.Delete(ctrl.FiberOapi, "/:database_id/views/:view_id", ctrl.DeleteView, fiberoapi.OpenAPIOptions{
@@ -80,6 +94,7 @@ func SetupDatabaseRouter(ctrl Controller) {
 		Description: "Delete a view",
 		OperationID: "database.view.delete",
 		Tags:        []string{"Database Views"},
+		Security:    http.RequireScopes(domain.ApiKeyScopeManageDatabases),
 	})
 
 	// Row endpoints
@@ -88,6 +103,7 @@ func SetupDatabaseRouter(ctrl Controller) {
 		Description: "List all rows in a database",
 		OperationID: "database.row.list",
 		Tags:        []string{"Database Rows"},
+		Security:    http.RequireScopes(domain.ApiKeyScopeReadDatabases),
 	})
 
 	fiberoapi.Post(ctrl.FiberOapi, "/:database_id/rows", ctrl.CreateRow, fiberoapi.OpenAPIOptions{
@@ -95,6 +111,7 @@ func SetupDatabaseRouter(ctrl Controller) {
 		Description: "Create a new row in a database",
 		OperationID: "database.row.create",
 		Tags:        []string{"Database Rows"},
+		Security:    http.RequireScopes(domain.ApiKeyScopeManageDatabases),
 	})
 
 	fiberoapi.Delete(ctrl.FiberOapi, "/:database_id/rows", ctrl.BulkDeleteRows, fiberoapi.OpenAPIOptions{
@@ -102,6 +119,7 @@ func SetupDatabaseRouter(ctrl Controller) {
 		Description: "Delete multiple rows at once",
 		OperationID: "database.row.bulk_delete",
 		Tags:        []string{"Database Rows"},
+		Security:    http.RequireScopes(domain.ApiKeyScopeManageDatabases),
 	})
 
 	fiberoapi.Get(ctrl.FiberOapi, "/:database_id/rows/:row_id", ctrl.GetRow, fiberoapi.OpenAPIOptions{
@@ -109,6 +127,7 @@ func SetupDatabaseRouter(ctrl Controller) {
 		Description: "Get a specific row by ID",
 		OperationID: "database.row.get",
 		Tags:        []string{"Database Rows"},
+		Security:    http.RequireScopes(domain.ApiKeyScopeReadDatabases),
 	})
 
 	fiberoapi.Put(ctrl.FiberOapi, "/:database_id/rows/:row_id", ctrl.UpdateRow, fiberoapi.OpenAPIOptions{
@@ -116,6 +135,7 @@ func SetupDatabaseRouter(ctrl Controller) {
 		Description: "Update an existing row",
 		OperationID: "database.row.update",
 		Tags:        []string{"Database Rows"},
+		Security:    http.RequireScopes(domain.ApiKeyScopeManageDatabases),
 	})
 
 	fiberoapi.Delete(ctrl.FiberOapi, "/:database_id/rows/:row_id", ctrl.DeleteRow, fiberoapi.OpenAPIOptions{
@@ -123,6 +143,7 @@ func SetupDatabaseRouter(ctrl Controller) {
 		Description: "Delete a row",
 		OperationID: "database.row.delete",
 		Tags:        []string{"Database Rows"},
+		Security:    http.RequireScopes(domain.ApiKeyScopeManageDatabases),
 	})
 
 	// Permission endpoints
@@ -131,6 +152,7 @@ func SetupDatabaseRouter(ctrl Controller) {
 		Description: "List all permissions for a database",
 		OperationID: "database.permission.list",
 		Tags:        []string{"Database Permissions"},
+		Security:    http.RequireScopes(domain.ApiKeyScopeReadDatabases),
 	})
 
 	fiberoapi.Post(ctrl.FiberOapi, "/:database_id/permissions", ctrl.UpsertDatabasePermission, fiberoapi.OpenAPIOptions{
@@ -138,6 +160,7 @@ func SetupDatabaseRouter(ctrl Controller) {
 		Description: "Add or update a permission for a database",
 		OperationID: "database.permission.upsert",
 		Tags:        []string{"Database Permissions"},
+		Security:    http.RequireScopes(domain.ApiKeyScopeManageDatabases),
 	})
 
 	fiberoapi.Delete(ctrl.FiberOapi, "/:database_id/permissions", ctrl.DeleteDatabasePermission, fiberoapi.OpenAPIOptions{
@@ -145,5 +168,6 @@ func SetupDatabaseRouter(ctrl Controller) {
 		Description: "Delete a permission from a database",
 		OperationID: "database.permission.delete",
 		Tags:        []string{"Database Permissions"},
+		Security:    http.RequireScopes(domain.ApiKeyScopeManageDatabases),
 	})
 }
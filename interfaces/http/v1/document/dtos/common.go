@@ -1,6 +1,8 @@
 package dtos
 
 import (
+	"bytes"
+	"encoding/json"
 	"time"
 
 	spaceDtos "github.com/labbs/nexo/interfaces/http/v1/space/dtos"
@@ -40,12 +42,77 @@ type Block struct {
 	Props    map[string]any  `json:"props"`
 	Content  []InlineContent `json:"content"`
 	Children []Block         `json:"children"`
+
+	// TableContent replaces Content for table blocks
+	TableContent *TableContent `json:"-"`
+}
+
+// TableContent représente les lignes d'un bloc table
+type TableContent struct {
+	Type         string     `json:"type"`
+	ColumnWidths []any      `json:"columnWidths,omitempty"`
+	HeaderRows   int        `json:"headerRows,omitempty"`
+	Rows         []TableRow `json:"rows"`
+}
+
+type TableRow struct {
+	Cells []TableCell `json:"cells"`
+}
+
+type TableCell struct {
+	Type    string          `json:"type"`
+	Props   map[string]any  `json:"props,omitempty"`
+	Content []InlineContent `json:"content"`
+}
+
+func (b Block) MarshalJSON() ([]byte, error) {
+	type block Block
+	if b.TableContent == nil {
+		return json.Marshal(block(b))
+	}
+	return json.Marshal(struct {
+		block
+		Content *TableContent `json:"content"`
+	}{block(b), b.TableContent})
+}
+
+func (b *Block) UnmarshalJSON(data []byte) error {
+	type block Block
+	var raw struct {
+		block
+		Content json.RawMessage `json:"content"`
+	}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	*b = Block(raw.block)
+
+	content := bytes.TrimSpace(raw.Content)
+	switch {
+	case len(content) == 0 || bytes.Equal(content, []byte("null")):
+	case content[0] == '{':
+		b.TableContent = &TableContent{}
+		return json.Unmarshal(content, b.TableContent)
+	default:
+		return json.Unmarshal(content, &b.Content)
+	}
+	return nil
+}
+
+func (c *TableCell) UnmarshalJSON(data []byte) error {
+	type cell TableCell
+	if data = bytes.TrimSpace(data); len(data) > 0 && data[0] == '[' {
+		*c = TableCell{Type: "tableCell"}
+		return json.Unmarshal(data, &c.Content)
+	}
+	return json.Unmarshal(data, (*cell)(c))
 }
 
 // InlineContent représente le contenu inline (texte, liens, etc.)
 type InlineContent struct {
-	Type   string          `json:"type"`
-	Text   string          `json:"text,omitempty"`
-	Href   string          `json:"href,omitempty"`
-	Styles map[string]bool `json:"styles"`
+	Type   string         `json:"type"`
+	Text   string         `json:"text,omitempty"`
+	Href   string         `json:"href,omitempty"`
+	Styles map[string]any `json:"styles"`
+	Props  map[string]any `json:"props,omitempty"`
 }
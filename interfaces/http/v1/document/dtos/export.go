@@ -0,0 +1,21 @@
+package dtos
+
+// Request DTOs
+
+type ImportMarkdownRequest struct {
+	SpaceId    string  `path:"space_id" validate:"required,uuid4"`
+	Name       string  `json:"name,omitempty" validate:"omitempty,max=255"`
+	Markdown   string  `json:"markdown" validate:"required"`
+	ParentId   *string `json:"parent_id,omitempty" validate:"omitempty"`
+	DocumentId *string `json:"document_id,omitempty" validate:"omitempty"`
+}
+
+// Response DTOs
+
+type ImportMarkdownResponse struct {
+	Id       string  `json:"id"`
+	Name     string  `json:"name"`
+	Slug     string  `json:"slug"`
+	SpaceId  string  `json:"space_id"`
+	ParentId *string `json:"parent_id,omitempty"`
+}
@@ -27,7 +27,8 @@ type GetDocumentResponse struct {
 	Config   DocumentConfig `json:"config"`
 	Metadata map[string]any `json:"metadata"`
 
-	Public bool `json:"public"`
+	Public           bool `json:"public"`
+	BreakInheritance bool `json:"break_inheritance"`
 
 	CreatedAt time.Time `json:"created_at"`
 	UpdatedAt time.Time `json:"updated_at"`
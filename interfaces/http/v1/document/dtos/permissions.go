@@ -20,7 +20,7 @@ type UpsertDocumentUserPermissionRequest struct {
 	SpaceId    string `path:"space_id" validate:"required,uuid4"`
 	DocumentId string `path:"document_id" validate:"required,uuid4"`
 	UserId     string `json:"user_id" validate:"required,uuid4"`
-	Role       string `json:"role" validate:"required,oneof=owner editor viewer denied"`
+	Role       string `json:"role" validate:"required,oneof=owner editor commenter viewer denied"`
 }
 
 type UpsertDocumentUserPermissionResponse struct {
@@ -0,0 +1,26 @@
+package dtos
+
+import "time"
+
+// Request DTOs
+
+type GetPresenceRequest struct {
+	SpaceId    string `path:"space_id" validate:"required,uuid4"`
+	DocumentId string `path:"document_id" validate:"required"`
+}
+
+// Response DTOs
+
+type PresenceUser struct {
+	UserId         string    `json:"user_id"`
+	Username       string    `json:"username"`
+	AvatarUrl      string    `json:"avatar_url,omitempty"`
+	Connections    int       `json:"connections"`
+	ReadOnly       bool      `json:"read_only"`
+	ConnectedAt    time.Time `json:"connected_at"`
+	LastActivityAt time.Time `json:"last_activity_at"`
+}
+
+type GetPresenceResponse struct {
+	Users []PresenceUser `json:"users"`
+}
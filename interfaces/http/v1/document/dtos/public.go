@@ -11,6 +11,17 @@ type SetPublicResponse struct {
 	Public  bool   `json:"public"`
 }
 
+type SetBreakInheritanceRequest struct {
+	SpaceId          string `path:"space_id" validate:"required,uuid4"`
+	DocumentId       string `path:"document_id" validate:"required,uuid4"`
+	BreakInheritance bool   `json:"break_inheritance"`
+}
+
+type SetBreakInheritanceResponse struct {
+	Message          string `json:"message"`
+	BreakInheritance bool   `json:"break_inheritance"`
+}
+
 type GetPublicDocumentRequest struct {
 	SpaceId    string `path:"space_id" validate:"required,uuid4"`
 	Identifier string `path:"identifier" validate:"required"`
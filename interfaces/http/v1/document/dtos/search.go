@@ -15,6 +15,8 @@ type SearchResultItem struct {
 	SpaceId   string    `json:"space_id"`
 	SpaceName string    `json:"space_name"`
 	Icon      string    `json:"icon,omitempty"`
+	Snippet   string    `json:"snippet,omitempty"`
+	BlockId   *string   `json:"block_id,omitempty"`
 	UpdatedAt time.Time `json:"updated_at"`
 }
 
@@ -149,16 +149,7 @@ func (ctrl *Controller) GetDocument(ctx *fiber.Ctx, req dtos.GetDocumentRequest)
 
 	// Convert Content from application DTO blocks to HTTP response blocks
 	if len(result.Document.Content) > 0 {
-		resp.Content = make([]dtos.Block, len(result.Document.Content))
-		for i, b := range result.Document.Content {
-			resp.Content[i] = dtos.Block{
-				ID:       b.ID,
-				Type:     b.Type,
-				Props:    b.Props,
-				Content:  convertToHttpInlineContent(b.Content),
-				Children: convertToHttpBlocks(b.Children),
-			}
-		}
+		resp.Content = convertToHttpBlocks(result.Document.Content)
 	}
 
 	logger.Debug().Interface("resp.Content", resp.Content).Msg("Mapped document content")
@@ -300,16 +291,7 @@ func (ctrl *Controller) UpdateDocument(ctx *fiber.Ctx, req dtos.UpdateDocumentRe
 	// Convert HTTP blocks to application blocks if provided
 	var appContent *[]docDto.Block
 	if req.Content != nil {
-		blocks := make([]docDto.Block, len(*req.Content))
-		for i, b := range *req.Content {
-			blocks[i] = docDto.Block{
-				ID:       b.ID,
-				Type:     b.Type,
-				Props:    b.Props,
-				Content:  convertInlineContent(b.Content),
-				Children: convertBlocks(b.Children),
-			}
-		}
+		blocks := convertBlocks(*req.Content)
 		appContent = &blocks
 	}
 
@@ -429,11 +411,12 @@ func convertInlineContent(content []dtos.InlineContent) []docDto.InlineContent {
 	result := make([]docDto.InlineContent, len(content))
 	for i, c := range content {
 		result[i] = docDto.InlineContent{
-			Type:   c.Type,
-			Text:   c.Text,
-			Href:   c.Href,
-			Styles: c.Styles,
+			Type:  c.Type,
+			Text:  c.Text,
+			Href:  c.Href,
+			Props: c.Props,
 		}
+		result[i].SetStyles(c.Styles)
 	}
 	return result
 }
@@ -449,6 +432,21 @@ func convertBlocks(blocks []dtos.Block) []docDto.Block {
 			Content:  convertInlineContent(b.Content),
 			Children: convertBlocks(b.Children),
 		}
+		if b.TableContent != nil {
+			result[i].TableContent = &docDto.TableContent{
+				Type:         b.TableContent.Type,
+				ColumnWidths: b.TableContent.ColumnWidths,
+				HeaderRows:   b.TableContent.HeaderRows,
+				Rows:         make([]docDto.TableRow, len(b.TableContent.Rows)),
+			}
+			for r, row := range b.TableContent.Rows {
+				cells := make([]docDto.TableCell, len(row.Cells))
+				for c, cell := range row.Cells {
+					cells[c] = docDto.TableCell{Type: cell.Type, Props: cell.Props, Content: convertInlineContent(cell.Content)}
+				}
+				result[i].TableContent.Rows[r].Cells = cells
+			}
+		}
 	}
 	return result
 }
@@ -509,7 +507,7 @@ func (ctrl *Controller) UpsertDocumentUserPermission(ctx *fiber.Ctx, req dtos.Up
 
 	role := req.Role
 	switch role {
-	case "owner", "editor", "denied", "viewer":
+	case "owner", "editor", "commenter", "denied", "viewer":
 		// valid role
 	default:
 		role = "viewer"
@@ -664,6 +662,41 @@ func (ctrl *Controller) SetPublic(ctx *fiber.Ctx, req dtos.SetPublicRequest) (*d
 	return &dtos.SetPublicResponse{Message: message, Public: req.Public}, nil
 }
 
+func (ctrl *Controller) SetBreakInheritance(ctx *fiber.Ctx, req dtos.SetBreakInheritanceRequest) (*dtos.SetBreakInheritanceResponse, *fiberoapi.ErrorResponse) {
+	requestId := ctx.Locals("requestid").(string)
+	logger := ctrl.Logger.With().Str("request_id", requestId).Str("component", "http.api.v1.document.set_break_inheritance").Logger()
+
+	authCtx, err := fiberoapi.GetAuthContext(ctx)
+	if err != nil {
+		logger.Error().Err(err).Msg("failed to get auth context")
+		return nil, &fiberoapi.ErrorResponse{Code: fiber.StatusUnauthorized, Details: "Authentication required", Type: "AUTHENTICATION_REQUIRED"}
+	}
+
+	err = ctrl.DocumentApplication.SetBreakInheritance(docDto.SetBreakInheritanceInput{
+		UserId:           authCtx.UserID,
+		SpaceId:          req.SpaceId,
+		DocumentId:       req.DocumentId,
+		BreakInheritance: req.BreakInheritance,
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, apperrors.ErrAccessDenied):
+			return nil, &fiberoapi.ErrorResponse{Code: fiber.StatusForbidden, Details: "Forbidden", Type: "FORBIDDEN"}
+		case errors.Is(err, apperrors.ErrDocumentNotFound):
+			return nil, &fiberoapi.ErrorResponse{Code: fiber.StatusNotFound, Details: "Document not found", Type: "DOCUMENT_NOT_FOUND"}
+		}
+		logger.Error().Err(err).Msg("failed to set document inheritance")
+		return nil, &fiberoapi.ErrorResponse{Code: fiber.StatusInternalServerError, Details: "Failed to update document", Type: "INTERNAL_SERVER_ERROR"}
+	}
+
+	message := "Document inherits the permissions of its parent"
+	if req.BreakInheritance {
+		message = "Document no longer inherits the permissions of its parent"
+	}
+
+	return &dtos.SetBreakInheritanceResponse{Message: message, BreakInheritance: req.BreakInheritance}, nil
+}
+
 func (ctrl *Controller) GetPublicDocument(ctx *fiber.Ctx, req dtos.GetPublicDocumentRequest) (*dtos.GetDocumentResponse, *fiberoapi.ErrorResponse) {
 	requestId := ctx.Locals("requestid").(string)
 	logger := ctrl.Logger.With().Str("request_id", requestId).Str("component", "http.api.v1.document.get_public_document").Logger()
@@ -881,6 +914,9 @@ func (ctrl *Controller) SearchDocuments(ctx *fiber.Ctx, req dtos.SearchRequest)
 		Limit:   req.Limit,
 	})
 	if err != nil {
+		if errors.Is(err, apperrors.ErrInvalidInput) {
+			return nil, &fiberoapi.ErrorResponse{Code: fiber.StatusBadRequest, Details: err.Error(), Type: "BAD_REQUEST"}
+		}
 		logger.Error().Err(err).Msg("failed to search documents")
 		return nil, &fiberoapi.ErrorResponse{Code: fiber.StatusInternalServerError, Details: "Failed to search documents", Type: "INTERNAL_SERVER_ERROR"}
 	}
@@ -894,6 +930,8 @@ func (ctrl *Controller) SearchDocuments(ctx *fiber.Ctx, req dtos.SearchRequest)
 			SpaceId:   r.SpaceId,
 			SpaceName: r.SpaceName,
 			Icon:      r.Icon,
+			Snippet:   r.Snippet,
+			BlockId:   r.BlockId,
 			UpdatedAt: r.UpdatedAt,
 		}
 	}
@@ -975,16 +1013,7 @@ func (ctrl *Controller) GetVersion(ctx *fiber.Ctx, req dtos.GetVersionRequest) (
 	}
 
 	// Convert content blocks
-	content := make([]dtos.Block, len(result.Content))
-	for i, b := range result.Content {
-		content[i] = dtos.Block{
-			ID:       b.ID,
-			Type:     b.Type,
-			Props:    b.Props,
-			Content:  convertToHttpInlineContent(b.Content),
-			Children: convertToHttpBlocks(b.Children),
-		}
-	}
+	content := convertToHttpBlocks(result.Content)
 
 	return &dtos.GetVersionResponse{
 		Id:         result.Id,
@@ -1106,7 +1135,8 @@ func convertToHttpInlineContent(content []docDto.InlineContent) []dtos.InlineCon
 			Type:   c.Type,
 			Text:   c.Text,
 			Href:   c.Href,
-			Styles: c.Styles,
+			Styles: c.AllStyles(),
+			Props:  c.Props,
 		}
 	}
 	return result
@@ -1122,6 +1152,21 @@ func convertToHttpBlocks(blocks []docDto.Block) []dtos.Block {
 			Content:  convertToHttpInlineContent(b.Content),
 			Children: convertToHttpBlocks(b.Children),
 		}
+		if b.TableContent != nil {
+			result[i].TableContent = &dtos.TableContent{
+				Type:         b.TableContent.Type,
+				ColumnWidths: b.TableContent.ColumnWidths,
+				HeaderRows:   b.TableContent.HeaderRows,
+				Rows:         make([]dtos.TableRow, len(b.TableContent.Rows)),
+			}
+			for r, row := range b.TableContent.Rows {
+				cells := make([]dtos.TableCell, len(row.Cells))
+				for c, cell := range row.Cells {
+					cells[c] = dtos.TableCell{Type: cell.Type, Props: cell.Props, Content: convertToHttpInlineContent(cell.Content)}
+				}
+				result[i].TableContent.Rows[r].Cells = cells
+			}
+		}
 	}
 	return result
 }
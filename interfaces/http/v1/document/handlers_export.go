@@ -0,0 +1,102 @@
+package document
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	fiberoapi "github.com/labbs/fiber-oapi"
+	docDto "github.com/labbs/nexo/application/document/dto"
+	sessionDto "github.com/labbs/nexo/application/session/dto"
+	"github.com/labbs/nexo/domain"
+	"github.com/labbs/nexo/infrastructure/helpers/apperrors"
+	"github.com/labbs/nexo/interfaces/http/v1/document/dtos"
+)
+
+// ExportDocument downloads the content of a document converted to the
+// format of the "format" query parameter (markdown by default). With
+// "recursive=true" the documents under it are exported too.
+func (ctrl *Controller) ExportDocument(ctx *fiber.Ctx) error {
+	requestId, _ := ctx.Locals("requestid").(string)
+	logger := ctrl.Logger.With().Str("request_id", requestId).Str("component", "http.api.v1.document.export_document").Logger()
+
+	token := strings.TrimPrefix(ctx.Get(fiber.HeaderAuthorization), "Bearer ")
+	if token == "" {
+		token = ctx.Query("token")
+	}
+	if token == "" {
+		return ctx.Status(fiber.StatusUnauthorized).JSON(fiberoapi.ErrorResponse{Code: fiber.StatusUnauthorized, Details: "Authentication required", Type: "AUTHENTICATION_REQUIRED"})
+	}
+	session, err := ctrl.SessionApplication.ValidateToken(sessionDto.ValidateTokenInput{Token: token})
+	if err != nil {
+		return ctx.Status(fiber.StatusUnauthorized).JSON(fiberoapi.ErrorResponse{Code: fiber.StatusUnauthorized, Details: "Invalid token", Type: "AUTHENTICATION_REQUIRED"})
+	}
+	authCtx := session.AuthContext
+	if !ctrl.SessionApplication.HasScope(sessionDto.HasScopeInput{Context: authCtx, Scope: string(domain.ApiKeyScopeReadDocuments)}) {
+		return ctx.Status(fiber.StatusForbidden).JSON(fiberoapi.ErrorResponse{Code: fiber.StatusForbidden, Details: "missing required scope: " + string(domain.ApiKeyScopeReadDocuments), Type: "FORBIDDEN"})
+	}
+
+	result, err := ctrl.DocumentApplication.ExportDocument(docDto.ExportDocumentInput{
+		UserId:     authCtx.UserID,
+		SpaceId:    ctx.Params("space_id"),
+		DocumentId: ctx.Params("document_id"),
+		Format:     ctx.Query("format", docDto.ExportFormatMarkdown),
+		Recursive:  ctx.QueryBool("recursive"),
+	})
+	if err != nil {
+		logger.Error().Err(err).Str("spaceId", ctx.Params("space_id")).Str("documentId", ctx.Params("document_id")).Msg("failed to export document")
+		switch {
+		case errors.Is(err, apperrors.ErrInvalidInput):
+			return ctx.Status(fiber.StatusBadRequest).JSON(fiberoapi.ErrorResponse{Code: fiber.StatusBadRequest, Details: err.Error(), Type: "BAD_REQUEST"})
+		case errors.Is(err, apperrors.ErrDocumentNotFound):
+			return ctx.Status(fiber.StatusNotFound).JSON(fiberoapi.ErrorResponse{Code: fiber.StatusNotFound, Details: "Document not found", Type: "DOCUMENT_NOT_FOUND"})
+		default:
+			return ctx.Status(fiber.StatusInternalServerError).JSON(fiberoapi.ErrorResponse{Code: fiber.StatusInternalServerError, Details: "Failed to export document", Type: "INTERNAL_SERVER_ERROR"})
+		}
+	}
+
+	ctx.Set(fiber.HeaderContentType, result.ContentType)
+	ctx.Set(fiber.HeaderContentDisposition, `attachment; filename="`+strings.ReplaceAll(result.Filename, `"`, "")+`"`)
+	return ctx.Send(result.Content)
+}
+
+func (ctrl *Controller) ImportMarkdown(ctx *fiber.Ctx, req dtos.ImportMarkdownRequest) (*dtos.ImportMarkdownResponse, *fiberoapi.ErrorResponse) {
+	requestId := ctx.Locals("requestid").(string)
+	logger := ctrl.Logger.With().Str("request_id", requestId).Str("component", "http.api.v1.document.import_markdown").Logger()
+
+	authCtx, err := fiberoapi.GetAuthContext(ctx)
+	if err != nil {
+		logger.Error().Err(err).Msg("failed to get auth context")
+		return nil, &fiberoapi.ErrorResponse{Code: fiber.StatusUnauthorized, Details: "Authentication required", Type: "AUTHENTICATION_REQUIRED"}
+	}
+
+	result, err := ctrl.DocumentApplication.ImportMarkdown(docDto.ImportMarkdownInput{
+		UserId:     authCtx.UserID,
+		SpaceId:    req.SpaceId,
+		ParentId:   req.ParentId,
+		DocumentId: req.DocumentId,
+		Name:       req.Name,
+		Markdown:   req.Markdown,
+	})
+	if err != nil {
+		logger.Error().Err(err).Str("spaceId", req.SpaceId).Msg("failed to import markdown")
+		switch {
+		case errors.Is(err, apperrors.ErrAccessDenied):
+			return nil, &fiberoapi.ErrorResponse{Code: fiber.StatusForbidden, Details: "Forbidden", Type: "FORBIDDEN"}
+		case errors.Is(err, apperrors.ErrInvalidInput):
+			return nil, &fiberoapi.ErrorResponse{Code: fiber.StatusBadRequest, Details: err.Error(), Type: "BAD_REQUEST"}
+		case errors.Is(err, apperrors.ErrDocumentNotFound):
+			return nil, &fiberoapi.ErrorResponse{Code: fiber.StatusNotFound, Details: "Document not found", Type: "DOCUMENT_NOT_FOUND"}
+		default:
+			return nil, &fiberoapi.ErrorResponse{Code: fiber.StatusInternalServerError, Details: "Failed to import markdown", Type: "INTERNAL_SERVER_ERROR"}
+		}
+	}
+
+	return &dtos.ImportMarkdownResponse{
+		Id:       result.Document.Id,
+		Name:     result.Document.Name,
+		Slug:     result.Document.Slug,
+		SpaceId:  result.Document.SpaceId,
+		ParentId: result.Document.ParentId,
+	}, nil
+}
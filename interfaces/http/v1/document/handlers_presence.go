@@ -0,0 +1,61 @@
+package document
+
+import (
+	"errors"
+
+	"github.com/gofiber/fiber/v2"
+	fiberoapi "github.com/labbs/fiber-oapi"
+	docDto "github.com/labbs/nexo/application/document/dto"
+	"github.com/labbs/nexo/infrastructure/helpers/apperrors"
+	"github.com/labbs/nexo/infrastructure/helpers/validator"
+	"github.com/labbs/nexo/interfaces/http/v1/document/dtos"
+)
+
+func (ctrl *Controller) GetPresence(ctx *fiber.Ctx, req dtos.GetPresenceRequest) (*dtos.GetPresenceResponse, *fiberoapi.ErrorResponse) {
+	requestId := ctx.Locals("requestid").(string)
+	logger := ctrl.Logger.With().Str("request_id", requestId).Str("component", "http.api.v1.document.get_presence").Logger()
+
+	authCtx, err := fiberoapi.GetAuthContext(ctx)
+	if err != nil {
+		logger.Error().Err(err).Msg("failed to get auth context")
+		return nil, &fiberoapi.ErrorResponse{Code: fiber.StatusUnauthorized, Details: "Authentication required", Type: "AUTHENTICATION_REQUIRED"}
+	}
+
+	var id *string
+	var slug *string
+
+	if validator.IsValidUUID(req.DocumentId) {
+		id = &req.DocumentId
+	} else {
+		slug = &req.DocumentId
+	}
+
+	result, err := ctrl.DocumentApplication.GetPresence(docDto.GetPresenceInput{
+		UserId:     authCtx.UserID,
+		SpaceId:    req.SpaceId,
+		DocumentId: id,
+		Slug:       slug,
+	})
+	if err != nil {
+		logger.Error().Err(err).Str("spaceId", req.SpaceId).Str("documentId", req.DocumentId).Msg("failed to get presence")
+		if errors.Is(err, apperrors.ErrAccessDenied) {
+			return nil, &fiberoapi.ErrorResponse{Code: fiber.StatusForbidden, Details: "Forbidden", Type: "FORBIDDEN"}
+		}
+		return nil, &fiberoapi.ErrorResponse{Code: fiber.StatusNotFound, Details: "Document not found", Type: "DOCUMENT_NOT_FOUND"}
+	}
+
+	resp := &dtos.GetPresenceResponse{Users: make([]dtos.PresenceUser, len(result.Users))}
+	for i, u := range result.Users {
+		resp.Users[i] = dtos.PresenceUser{
+			UserId:         u.UserId,
+			Username:       u.Username,
+			AvatarUrl:      u.AvatarUrl,
+			Connections:    u.Connections,
+			ReadOnly:       u.ReadOnly,
+			ConnectedAt:    u.ConnectedAt,
+			LastActivityAt: u.LastActivityAt,
+		}
+	}
+
+	return resp, nil
+}
@@ -1,11 +1,15 @@
 package document
 
 import (
+	"github.com/gofiber/fiber/v2"
 	fiberoapi "github.com/labbs/fiber-oapi"
 	"github.com/labbs/nexo/application/document"
 	"github.com/labbs/nexo/application/permission"
+	"github.com/labbs/nexo/application/session"
 	"github.com/labbs/nexo/application/space"
+	"github.com/labbs/nexo/domain"
 	"github.com/labbs/nexo/infrastructure/config"
+	"github.com/labbs/nexo/infrastructure/http"
 	"github.com/rs/zerolog"
 )
 
@@ -16,15 +20,21 @@ type Controller struct {
 	SpaceApplication      *space.SpaceApp
 	DocumentApplication   *document.DocumentApplication
 	PermissionApplication *permission.PermissionApplication
+	SessionApplication    *session.SessionApplication
 }
 
-func SetupDocumentRouter(controller Controller) {
+// exportPath is the download route of document exports. It returns the
+// file, so it is a plain Fiber route outside of the OpenAPI routes.
+const exportPath = "/api/v1/document/space/:space_id/:document_id/export"
+
+func SetupDocumentRouter(controller Controller, app *fiber.App) {
 	// Search - must be before parameterized routes
 	fiberoapi.Get(controller.FiberOapi, "/search", controller.SearchDocuments, fiberoapi.OpenAPIOptions{
 		Summary:     "Search documents",
-		Description: "Search documents by name or content",
+		Description: "Full-text search of documents by name or content, best matches first, with a highlighted snippet and the matching block",
 		OperationID: "document.search",
 		Tags:        []string{"Document", "Search"},
+		Security:    http.RequireScopes(domain.ApiKeyScopeReadDocuments),
 	})
 
 	// Public document - before space routes
@@ -41,24 +51,35 @@ func SetupDocumentRouter(controller Controller) {
 		Description: "Retrieve documents from a specific space",
 		OperationID: "document.getDocumentsFromSpace",
 		Tags:        []string{"Document"},
+		Security:    http.RequireScopes(domain.ApiKeyScopeReadDocuments),
 	})
 	fiberoapi.Post(controller.FiberOapi, "/space/:space_id", controller.CreateDocument, fiberoapi.OpenAPIOptions{
 		Summary:     "Create a new document",
 		Description: "Create a new document in a specified space",
 		OperationID: "document.createDocument",
 		Tags:        []string{"Document"},
+		Security:    http.RequireScopes(domain.ApiKeyScopeWriteDocuments),
 	})
 	fiberoapi.Get(controller.FiberOapi, "/space/:space_id/trash", controller.GetTrash, fiberoapi.OpenAPIOptions{
 		Summary:     "Get trash",
 		Description: "Get all deleted documents in a space",
 		OperationID: "document.getTrash",
 		Tags:        []string{"Document", "Trash"},
+		Security:    http.RequireScopes(domain.ApiKeyScopeReadDocuments),
+	})
+	fiberoapi.Post(controller.FiberOapi, "/space/:space_id/import", controller.ImportMarkdown, fiberoapi.OpenAPIOptions{
+		Summary:     "Import markdown",
+		Description: "Create a document from GitHub Flavored Markdown, or replace the content of an existing document with document_id. A level 1 heading at the start of the markdown is the name of the document when no name is given. The markdown export of a document is available at GET /api/v1/document/space/{space_id}/{document_id}/export?format=markdown",
+		OperationID: "document.importMarkdown",
+		Tags:        []string{"Document", "Import"},
+		Security:    http.RequireScopes(domain.ApiKeyScopeWriteDocuments),
 	})
 	fiberoapi.Patch(controller.FiberOapi, "/space/:space_id/reorder", controller.ReorderDocuments, fiberoapi.OpenAPIOptions{
 		Summary:     "Reorder documents",
 		Description: "Reorder documents within a space by updating their positions",
 		OperationID: "document.reorderDocuments",
 		Tags:        []string{"Document"},
+		Security:    http.RequireScopes(domain.ApiKeyScopeWriteDocuments),
 	})
 
 	// Routes with specific suffixes - MUST be before generic /:identifier routes
@@ -68,24 +89,37 @@ func SetupDocumentRouter(controller Controller) {
 		Description: "Get version history for a document",
 		OperationID: "document.listVersions",
 		Tags:        []string{"Document", "Versions"},
+		Security:    http.RequireScopes(domain.ApiKeyScopeReadDocuments),
 	})
 	fiberoapi.Get(controller.FiberOapi, "/space/:space_id/:document_id/versions/:version_id", controller.GetVersion, fiberoapi.OpenAPIOptions{
 		Summary:     "Get document version",
 		Description: "Get a specific version of a document",
 		OperationID: "document.getVersion",
 		Tags:        []string{"Document", "Versions"},
+		Security:    http.RequireScopes(domain.ApiKeyScopeReadDocuments),
 	})
 	fiberoapi.Post(controller.FiberOapi, "/space/:space_id/:document_id/versions", controller.CreateVersion, fiberoapi.OpenAPIOptions{
 		Summary:     "Create version snapshot",
 		Description: "Manually create a version snapshot of the current document state",
 		OperationID: "document.createVersion",
 		Tags:        []string{"Document", "Versions"},
+		Security:    http.RequireScopes(domain.ApiKeyScopeWriteDocuments),
 	})
 	fiberoapi.Post(controller.FiberOapi, "/space/:space_id/:document_id/versions/:version_id/restore", controller.RestoreVersion, fiberoapi.OpenAPIOptions{
 		Summary:     "Restore document version",
 		Description: "Restore a document to a previous version",
 		OperationID: "document.restoreVersion",
 		Tags:        []string{"Document", "Versions"},
+		Security:    http.RequireScopes(domain.ApiKeyScopeWriteDocuments),
+	})
+
+	// Presence
+	fiberoapi.Get(controller.FiberOapi, "/space/:space_id/:document_id/presence", controller.GetPresence, fiberoapi.OpenAPIOptions{
+		Summary:     "Get document presence",
+		Description: "Get the users editing a document in its collaboration room",
+		OperationID: "document.getPresence",
+		Tags:        []string{"Document", "Collaboration"},
+		Security:    http.RequireScopes(domain.ApiKeyScopeReadDocuments),
 	})
 
 	// Comments
@@ -94,30 +128,35 @@ func SetupDocumentRouter(controller Controller) {
 		Description: "Get all comments for a document",
 		OperationID: "document.getComments",
 		Tags:        []string{"Document", "Comments"},
+		Security:    http.RequireScopes(domain.ApiKeyScopeReadComments),
 	})
 	fiberoapi.Post(controller.FiberOapi, "/space/:space_id/:document_id/comments", controller.CreateComment, fiberoapi.OpenAPIOptions{
 		Summary:     "Create comment",
 		Description: "Create a new comment on a document",
 		OperationID: "document.createComment",
 		Tags:        []string{"Document", "Comments"},
+		Security:    http.RequireScopes(domain.ApiKeyScopeWriteComments),
 	})
 	fiberoapi.Put(controller.FiberOapi, "/space/:space_id/:document_id/comments/:comment_id", controller.UpdateComment, fiberoapi.OpenAPIOptions{
 		Summary:     "Update comment",
 		Description: "Update an existing comment",
 		OperationID: "document.updateComment",
 		Tags:        []string{"Document", "Comments"},
+		Security:    http.RequireScopes(domain.ApiKeyScopeWriteComments),
 	})
 	fiberoapi.Delete(controller.FiberOapi, "/space/:space_id/:document_id/comments/:comment_id", controller.DeleteComment, fiberoapi.OpenAPIOptions{
 		Summary:     "Delete comment",
 		Description: "Delete a comment",
 		OperationID: "document.deleteComment",
 		Tags:        []string{"Document", "Comments"},
+		Security:    http.RequireScopes(domain.ApiKeyScopeWriteComments),
 	})
 	fiberoapi.Patch(controller.FiberOapi, "/space/:space_id/:document_id/comments/:comment_id/resolve", controller.ResolveComment, fiberoapi.OpenAPIOptions{
 		Summary:     "Resolve/unresolve comment",
 		Description: "Mark a comment as resolved or unresolved",
 		OperationID: "document.resolveComment",
 		Tags:        []string{"Document", "Comments"},
+		Security:    http.RequireScopes(domain.ApiKeyScopeWriteComments),
 	})
 
 	// Document permissions
@@ -126,18 +165,21 @@ func SetupDocumentRouter(controller Controller) {
 		Description: "List all permissions for a specific document",
 		OperationID: "document.listPermissions",
 		Tags:        []string{"Document", "Permissions"},
+		Security:    http.RequireScopes(domain.ApiKeyScopeReadDocuments),
 	})
 	fiberoapi.Put(controller.FiberOapi, "/space/:space_id/:document_id/permissions", controller.UpsertDocumentUserPermission, fiberoapi.OpenAPIOptions{
 		Summary:     "Upsert document user permission",
 		Description: "Create or update a user permission for a document",
 		OperationID: "document.upsertUserPermission",
 		Tags:        []string{"Document", "Permissions"},
+		Security:    http.RequireScopes(domain.ApiKeyScopeWriteDocuments),
 	})
 	fiberoapi.Delete(controller.FiberOapi, "/space/:space_id/:document_id/permissions/:user_id", controller.DeleteDocumentUserPermission, fiberoapi.OpenAPIOptions{
 		Summary:     "Delete document user permission",
 		Description: "Remove a user permission from a document",
 		OperationID: "document.deleteUserPermission",
 		Tags:        []string{"Document", "Permissions"},
+		Security:    http.RequireScopes(domain.ApiKeyScopeWriteDocuments),
 	})
 
 	// Other document-specific routes with suffixes
@@ -146,18 +188,28 @@ func SetupDocumentRouter(controller Controller) {
 		Description: "Restore a deleted document from trash",
 		OperationID: "document.restoreDocument",
 		Tags:        []string{"Document", "Trash"},
+		Security:    http.RequireScopes(domain.ApiKeyScopeWriteDocuments),
 	})
 	fiberoapi.Put(controller.FiberOapi, "/space/:space_id/:document_id/public", controller.SetPublic, fiberoapi.OpenAPIOptions{
 		Summary:     "Set document public status",
 		Description: "Make a document public or private",
 		OperationID: "document.setPublic",
 		Tags:        []string{"Document", "Public"},
+		Security:    http.RequireScopes(domain.ApiKeyScopeWriteDocuments),
+	})
+	fiberoapi.Put(controller.FiberOapi, "/space/:space_id/:document_id/inheritance", controller.SetBreakInheritance, fiberoapi.OpenAPIOptions{
+		Summary:     "Set document permission inheritance",
+		Description: "Break or restore the inheritance of permissions on a document and its sub pages. With the inheritance broken, only the permissions of the subtree apply, and the admins of the space keep edit access.",
+		OperationID: "document.setBreakInheritance",
+		Tags:        []string{"Document", "Permissions"},
+		Security:    http.RequireScopes(domain.ApiKeyScopeWriteDocuments),
 	})
 	fiberoapi.Patch(controller.FiberOapi, "/space/:space_id/:id/move", controller.MoveDocument, fiberoapi.OpenAPIOptions{
 		Summary:     "Move document",
 		Description: "Move a document to a new parent (or root)",
 		OperationID: "document.moveDocument",
 		Tags:        []string{"Document"},
+		Security:    http.RequireScopes(domain.ApiKeyScopeWriteDocuments),
 	})
 
 	// Generic document routes - MUST be LAST
@@ -166,17 +218,22 @@ func SetupDocumentRouter(controller Controller) {
 		Description: "Retrieve a specific document by its ID",
 		OperationID: "document.getDocument",
 		Tags:        []string{"Document"},
+		Security:    http.RequireScopes(domain.ApiKeyScopeReadDocuments),
 	})
 	fiberoapi.Put(controller.FiberOapi, "/space/:space_id/:id", controller.UpdateDocument, fiberoapi.OpenAPIOptions{
 		Summary:     "Update document",
 		Description: "Update a specific document",
 		OperationID: "document.updateDocument",
 		Tags:        []string{"Document"},
+		Security:    http.RequireScopes(domain.ApiKeyScopeWriteDocuments),
 	})
 	fiberoapi.Delete(controller.FiberOapi, "/space/:space_id/:identifier", controller.DeleteDocument, fiberoapi.OpenAPIOptions{
 		Summary:     "Delete document",
 		Description: "Delete a specific document",
 		OperationID: "document.deleteDocument",
 		Tags:        []string{"Document"},
+		Security:    http.RequireScopes(domain.ApiKeyScopeWriteDocuments),
 	})
+
+	app.Get(exportPath, controller.ExportDocument)
 }
@@ -15,8 +15,10 @@ type CreateDrawingRequest struct {
 	Thumbnail  string         `json:"thumbnail,omitempty"`
 }
 
+// ListDrawingsRequest has no space access tag: the drawings shared with
+// users who cannot read the space are listed too
 type ListDrawingsRequest struct {
-	SpaceId string `query:"space_id" resource:"space" action:"read"`
+	SpaceId string `query:"space_id"`
 }
 
 type GetDrawingRequest struct {
@@ -1,6 +1,10 @@
 package drawing
 
-import fiberoapi "github.com/labbs/fiber-oapi"
+import (
+	fiberoapi "github.com/labbs/fiber-oapi"
+	"github.com/labbs/nexo/domain"
+	"github.com/labbs/nexo/infrastructure/http"
+)
 
 func SetupDrawingRouter(ctrl Controller) {
 	fiberoapi.Get(ctrl.FiberOapi, "/", ctrl.ListDrawings, fiberoapi.OpenAPIOptions{
@@ -8,6 +12,7 @@ func SetupDrawingRouter(ctrl Controller) {
 		Description: "List all drawings in a space",
 		OperationID: "drawing.list",
 		Tags:        []string{"Drawings"},
+		Security:    http.RequireScopes(domain.ApiKeyScopeReadDrawings),
 	})
 
 	fiberoapi.Post(ctrl.FiberOapi, "/", ctrl.CreateDrawing, fiberoapi.OpenAPIOptions{
@@ -15,6 +20,7 @@ func SetupDrawingRouter(ctrl Controller) {
 		Description: "Create a new Excalidraw drawing",
 		OperationID: "drawing.create",
 		Tags:        []string{"Drawings"},
+		Security:    http.RequireScopes(domain.ApiKeyScopeWriteDrawings),
 	})
 
 	fiberoapi.Get(ctrl.FiberOapi, "/:drawing_id", ctrl.GetDrawing, fiberoapi.OpenAPIOptions{
@@ -22,6 +28,7 @@ func SetupDrawingRouter(ctrl Controller) {
 		Description: "Get a specific drawing by ID",
 		OperationID: "drawing.get",
 		Tags:        []string{"Drawings"},
+		Security:    http.RequireScopes(domain.ApiKeyScopeReadDrawings),
 	})
 
 	fiberoapi.Put(ctrl.FiberOapi, "/:drawing_id", ctrl.UpdateDrawing, fiberoapi.OpenAPIOptions{
@@ -29,6 +36,7 @@ func SetupDrawingRouter(ctrl Controller) {
 		Description: "Update an existing drawing",
 		OperationID: "drawing.update",
 		Tags:        []string{"Drawings"},
+		Security:    http.RequireScopes(domain.ApiKeyScopeWriteDrawings),
 	})
 
 	fiberoapi.Delete(ctrl.FiberOapi, "/:drawing_id", ctrl.DeleteDrawing, fiberoapi.OpenAPIOptions{
@@ -36,6 +44,7 @@ func SetupDrawingRouter(ctrl Controller) {
 		Description: "Delete a drawing",
 		OperationID: "drawing.delete",
 		Tags:        []string{"Drawings"},
+		Security:    http.RequireScopes(domain.ApiKeyScopeWriteDrawings),
 	})
 
 	fiberoapi.Patch(ctrl.FiberOapi, "/:drawing_id/move", ctrl.MoveDrawing, fiberoapi.OpenAPIOptions{
@@ -43,6 +52,7 @@ func SetupDrawingRouter(ctrl Controller) {
 		Description: "Move a drawing to a document or to root level",
 		OperationID: "drawing.move",
 		Tags:        []string{"Drawings"},
+		Security:    http.RequireScopes(domain.ApiKeyScopeWriteDrawings),
 	})
 
 	// Permission routes
@@ -51,6 +61,7 @@ func SetupDrawingRouter(ctrl Controller) {
 		Description: "List all permissions for a drawing",
 		OperationID: "drawing.permissions.list",
 		Tags:        []string{"Drawings"},
+		Security:    http.RequireScopes(domain.ApiKeyScopeReadDrawings),
 	})
 
 	fiberoapi.Put(ctrl.FiberOapi, "/:drawing_id/permissions/user", ctrl.UpsertDrawingUserPermission, fiberoapi.OpenAPIOptions{
@@ -58,6 +69,7 @@ func SetupDrawingRouter(ctrl Controller) {
 		Description: "Create or update a user permission for a drawing",
 		OperationID: "drawing.permissions.upsert_user",
 		Tags:        []string{"Drawings"},
+		Security:    http.RequireScopes(domain.ApiKeyScopeWriteDrawings),
 	})
 
 	fiberoapi.Delete(ctrl.FiberOapi, "/:drawing_id/permissions/user/:user_id", ctrl.DeleteDrawingUserPermission, fiberoapi.OpenAPIOptions{
@@ -65,5 +77,6 @@ func SetupDrawingRouter(ctrl Controller) {
 		Description: "Delete a user permission from a drawing",
 		OperationID: "drawing.permissions.delete_user",
 		Tags:        []string{"Drawings"},
+		Security:    http.RequireScopes(domain.ApiKeyScopeWriteDrawings),
 	})
 }
@@ -0,0 +1,15 @@
+package permission
+
+import (
+	fiberoapi "github.com/labbs/fiber-oapi"
+	"github.com/labbs/nexo/application/permission"
+	"github.com/labbs/nexo/infrastructure/config"
+	"github.com/rs/zerolog"
+)
+
+type Controller struct {
+	Config                config.Config
+	Logger                zerolog.Logger
+	FiberOapi             *fiberoapi.OApiGroup
+	PermissionApplication *permission.PermissionApplication
+}
@@ -0,0 +1,26 @@
+package dtos
+
+type GetEffectivePermissionRequest struct {
+	ResourceType string `query:"resource_type" validate:"required,oneof=space document database drawing"`
+	ResourceId   string `query:"resource_id" validate:"required,uuid4"`
+	UserId       string `query:"user_id" validate:"omitempty,uuid4"`
+}
+
+type PermissionGrant struct {
+	Source       string  `json:"source"` // owner, user, group, public, space
+	ResourceType string  `json:"resource_type"`
+	ResourceId   string  `json:"resource_id"`
+	GroupId      *string `json:"group_id,omitempty"`
+	GroupName    string  `json:"group_name,omitempty"`
+	Role         string  `json:"role"`
+	Applied      bool    `json:"applied"`
+}
+
+type GetEffectivePermissionResponse struct {
+	ResourceType string            `json:"resource_type"`
+	ResourceId   string            `json:"resource_id"`
+	SpaceId      string            `json:"space_id"`
+	UserId       string            `json:"user_id"`
+	Role         *string           `json:"role"`
+	Grants       []PermissionGrant `json:"grants"`
+}
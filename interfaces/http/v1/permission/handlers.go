@@ -0,0 +1,94 @@
+package permission
+
+import (
+	"errors"
+
+	"github.com/gofiber/fiber/v2"
+	fiberoapi "github.com/labbs/fiber-oapi"
+	permissionDto "github.com/labbs/nexo/application/permission/dto"
+	"github.com/labbs/nexo/domain"
+	"github.com/labbs/nexo/infrastructure/helpers/apperrors"
+	"github.com/labbs/nexo/interfaces/http/v1/permission/dtos"
+)
+
+// Read scope an API key needs to explain the permissions of each resource type
+var resourceTypeScopes = map[domain.PermissionType]domain.ApiKeyScope{
+	domain.PermissionTypeSpace:    domain.ApiKeyScopeReadSpaces,
+	domain.PermissionTypeDocument: domain.ApiKeyScopeReadDocuments,
+	domain.PermissionTypeDatabase: domain.ApiKeyScopeReadDatabases,
+	domain.PermissionTypeDrawing:  domain.ApiKeyScopeReadDrawings,
+}
+
+func (ctrl *Controller) GetEffectivePermission(ctx *fiber.Ctx, req dtos.GetEffectivePermissionRequest) (*dtos.GetEffectivePermissionResponse, *fiberoapi.ErrorResponse) {
+	requestId := ctx.Locals("requestid").(string)
+	logger := ctrl.Logger.With().Str("request_id", requestId).Str("component", "http.api.v1.permission.get_effective_permission").Logger()
+
+	authCtx, err := fiberoapi.GetAuthContext(ctx)
+	if err != nil {
+		logger.Error().Err(err).Msg("failed to get auth context")
+		return nil, &fiberoapi.ErrorResponse{Code: fiber.StatusUnauthorized, Details: "Authentication required", Type: "AUTHENTICATION_REQUIRED"}
+	}
+
+	// API keys need the read scope of the resource type
+	if _, ok := authCtx.Claims["api_key_id"]; ok {
+		scope := resourceTypeScopes[domain.PermissionType(req.ResourceType)]
+		if !hasValue(authCtx.Scopes, string(scope)) {
+			return nil, &fiberoapi.ErrorResponse{Code: fiber.StatusForbidden, Details: "missing required scope: " + string(scope), Type: "FORBIDDEN"}
+		}
+	}
+
+	result, err := ctrl.PermissionApplication.GetEffectivePermission(permissionDto.GetEffectivePermissionInput{
+		RequesterId:      authCtx.UserID,
+		RequesterIsAdmin: hasValue(authCtx.Roles, string(domain.RoleAdmin)),
+		ResourceType:     req.ResourceType,
+		ResourceId:       req.ResourceId,
+		UserId:           req.UserId,
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, apperrors.ErrForbidden):
+			return nil, &fiberoapi.ErrorResponse{Code: fiber.StatusForbidden, Details: "Forbidden", Type: "FORBIDDEN"}
+		case errors.Is(err, apperrors.ErrInvalidInput):
+			return nil, &fiberoapi.ErrorResponse{Code: fiber.StatusBadRequest, Details: err.Error(), Type: "BAD_REQUEST"}
+		case errors.Is(err, apperrors.ErrSpaceNotFound), errors.Is(err, apperrors.ErrDocumentNotFound),
+			errors.Is(err, apperrors.ErrDatabaseNotFound), errors.Is(err, apperrors.ErrDrawingNotFound):
+			return nil, &fiberoapi.ErrorResponse{Code: fiber.StatusNotFound, Details: "Resource not found", Type: "NOT_FOUND"}
+		}
+		logger.Error().Err(err).Msg("failed to get effective permission")
+		return nil, &fiberoapi.ErrorResponse{Code: fiber.StatusInternalServerError, Details: "Failed to get effective permission", Type: "INTERNAL_SERVER_ERROR"}
+	}
+
+	resp := &dtos.GetEffectivePermissionResponse{
+		ResourceType: req.ResourceType,
+		ResourceId:   req.ResourceId,
+		SpaceId:      result.SpaceId,
+		UserId:       result.UserId,
+		Grants:       make([]dtos.PermissionGrant, len(result.Permission.Grants)),
+	}
+	if result.Permission.Role != nil {
+		role := string(*result.Permission.Role)
+		resp.Role = &role
+	}
+	for i, grant := range result.Permission.Grants {
+		resp.Grants[i] = dtos.PermissionGrant{
+			Source:       string(grant.Source),
+			ResourceType: string(grant.ResourceType),
+			ResourceId:   grant.ResourceId,
+			GroupId:      grant.GroupId,
+			GroupName:    grant.GroupName,
+			Role:         string(grant.Role),
+			Applied:      grant.Applied,
+		}
+	}
+
+	return resp, nil
+}
+
+func hasValue(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
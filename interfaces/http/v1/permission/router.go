@@ -0,0 +1,14 @@
+package permission
+
+import (
+	fiberoapi "github.com/labbs/fiber-oapi"
+)
+
+func SetupPermissionRouter(ctrl Controller) {
+	fiberoapi.Get(ctrl.FiberOapi, "/effective", ctrl.GetEffectivePermission, fiberoapi.OpenAPIOptions{
+		Summary:     "Get effective permission",
+		Description: "Effective role of a user on a space, document, database or drawing (the requester by default), with the chain of grants it results from: ownership, permissions of the user and of their groups, role inherited from the space, public access and explicit denials. Explaining the permissions of another user requires to be admin of the resource or of its space. API keys need the read scope of the resource type.",
+		OperationID: "permission.effective",
+		Tags:        []string{"Permissions"},
+	})
+}
@@ -6,10 +6,13 @@ import (
 	"github.com/labbs/nexo/interfaces/http/v1/action"
 	"github.com/labbs/nexo/interfaces/http/v1/admin"
 	"github.com/labbs/nexo/interfaces/http/v1/apikey"
+	"github.com/labbs/nexo/interfaces/http/v1/attachment"
 	"github.com/labbs/nexo/interfaces/http/v1/auth"
 	"github.com/labbs/nexo/interfaces/http/v1/database"
 	"github.com/labbs/nexo/interfaces/http/v1/document"
 	"github.com/labbs/nexo/interfaces/http/v1/drawing"
+	"github.com/labbs/nexo/interfaces/http/v1/permission"
+	"github.com/labbs/nexo/interfaces/http/v1/search"
 	"github.com/labbs/nexo/interfaces/http/v1/space"
 	"github.com/labbs/nexo/interfaces/http/v1/user"
 	"github.com/labbs/nexo/interfaces/http/v1/webhook"
@@ -54,8 +57,9 @@ func SetupRouterV1(deps infrastructure.Deps) {
 		SpaceApplication:      deps.SpaceApplication,
 		DocumentApplication:   deps.DocumentApplication,
 		PermissionApplication: deps.PermissionApplication,
+		SessionApplication:    deps.SessionApplication,
 	}
-	document.SetupDocumentRouter(documentCtrl)
+	document.SetupDocumentRouter(documentCtrl, deps.Http.Fiber)
 
 	apiKeyCtrl := apikey.Controller{
 		Config:            deps.Config,
@@ -91,6 +95,31 @@ func SetupRouterV1(deps infrastructure.Deps) {
 	}
 	drawing.SetupDrawingRouter(drawingCtrl)
 
+	permissionCtrl := permission.Controller{
+		Config:                deps.Config,
+		Logger:                deps.Logger,
+		FiberOapi:             grp.Group("/permissions"),
+		PermissionApplication: deps.PermissionApplication,
+	}
+	permission.SetupPermissionRouter(permissionCtrl)
+
+	searchCtrl := search.Controller{
+		Config:            deps.Config,
+		Logger:            deps.Logger,
+		FiberOapi:         grp.Group("/search"),
+		SearchApplication: deps.SearchApplication,
+	}
+	search.SetupSearchRouter(searchCtrl)
+
+	attachmentCtrl := attachment.Controller{
+		Config:                deps.Config,
+		Logger:                deps.Logger,
+		FiberOapi:             grp.Group("/attachment"),
+		AttachmentApplication: deps.AttachmentApplication,
+		SessionApplication:    deps.SessionApplication,
+	}
+	attachment.SetupAttachmentRouter(attachmentCtrl, deps.Http.Fiber)
+
 	actionCtrl := action.Controller{
 		Config:            deps.Config,
 		Logger:            deps.Logger,
@@ -100,14 +129,18 @@ func SetupRouterV1(deps infrastructure.Deps) {
 	action.SetupActionRouter(actionCtrl)
 
 	adminCtrl := admin.Controller{
-		Config:            deps.Config,
-		Logger:            deps.Logger,
-		FiberOapi:         grp.Group("/admin"),
-		UserApplication:   deps.UserApplication,
-		SpaceApplication:  deps.SpaceApplication,
-		ApiKeyApplication: deps.ApiKeyApplication,
-		GroupApplication:  deps.GroupApplication,
-		PermissionPers:    deps.PermissionPers,
+		Config:             deps.Config,
+		Logger:             deps.Logger,
+		FiberOapi:          grp.Group("/admin"),
+		UserApplication:    deps.UserApplication,
+		SpaceApplication:   deps.SpaceApplication,
+		ApiKeyApplication:  deps.ApiKeyApplication,
+		GroupApplication:   deps.GroupApplication,
+		WebhookApplication: deps.WebhookApplication,
+		PermissionPers:     deps.PermissionPers,
+
+		ImporterApplication:   deps.ImporterApplication,
+		CollaborationPresence: deps.CollaborationHub,
 	}
 	admin.SetupAdminRouter(adminCtrl)
 
@@ -0,0 +1,15 @@
+package search
+
+import (
+	fiberoapi "github.com/labbs/fiber-oapi"
+	"github.com/labbs/nexo/application/search"
+	"github.com/labbs/nexo/infrastructure/config"
+	"github.com/rs/zerolog"
+)
+
+type Controller struct {
+	Config            config.Config
+	Logger            zerolog.Logger
+	FiberOapi         *fiberoapi.OApiGroup
+	SearchApplication *search.SearchApplication
+}
@@ -0,0 +1,31 @@
+package dtos
+
+import "time"
+
+type SearchRequest struct {
+	Query   string  `query:"q"`
+	SpaceId *string `query:"space_id"`
+	Types   string  `query:"types"` // comma-separated: document, row, drawing, comment
+	Limit   int     `query:"limit"`
+	Offset  int     `query:"offset"`
+}
+
+type SearchResult struct {
+	Type       string    `json:"type"`
+	Id         string    `json:"id"`
+	Title      string    `json:"title"`
+	Snippet    string    `json:"snippet,omitempty"`
+	Icon       string    `json:"icon,omitempty"`
+	SpaceId    string    `json:"space_id"`
+	SpaceName  string    `json:"space_name"`
+	DocumentId *string   `json:"document_id,omitempty"`
+	Slug       string    `json:"slug,omitempty"`
+	DatabaseId *string   `json:"database_id,omitempty"`
+	BlockId    *string   `json:"block_id,omitempty"`
+	Rank       float64   `json:"rank"`
+	UpdatedAt  time.Time `json:"updated_at"`
+}
+
+type SearchResponse struct {
+	Results []SearchResult `json:"results"`
+}
@@ -0,0 +1,109 @@
+package search
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	fiberoapi "github.com/labbs/fiber-oapi"
+	searchDto "github.com/labbs/nexo/application/search/dto"
+	"github.com/labbs/nexo/domain"
+	"github.com/labbs/nexo/infrastructure/helpers/apperrors"
+	"github.com/labbs/nexo/interfaces/http/v1/search/dtos"
+)
+
+// Read scope an API key needs to search each resource type
+var searchTypeScopes = map[domain.SearchResourceType]domain.ApiKeyScope{
+	domain.SearchResourceDocument: domain.ApiKeyScopeReadDocuments,
+	domain.SearchResourceRow:      domain.ApiKeyScopeReadDatabases,
+	domain.SearchResourceDrawing:  domain.ApiKeyScopeReadDrawings,
+	domain.SearchResourceComment:  domain.ApiKeyScopeReadComments,
+}
+
+func (ctrl *Controller) Search(ctx *fiber.Ctx, req dtos.SearchRequest) (*dtos.SearchResponse, *fiberoapi.ErrorResponse) {
+	requestId := ctx.Locals("requestid").(string)
+	logger := ctrl.Logger.With().Str("request_id", requestId).Str("component", "http.api.v1.search.search").Logger()
+
+	authCtx, err := fiberoapi.GetAuthContext(ctx)
+	if err != nil {
+		logger.Error().Err(err).Msg("failed to get auth context")
+		return nil, &fiberoapi.ErrorResponse{Code: fiber.StatusUnauthorized, Details: "Authentication required", Type: "AUTHENTICATION_REQUIRED"}
+	}
+
+	var types []string
+	for _, t := range strings.Split(req.Types, ",") {
+		if t = strings.TrimSpace(t); t != "" {
+			types = append(types, t)
+		}
+	}
+
+	// API keys search the types their scopes allow to read
+	if _, ok := authCtx.Claims["api_key_id"]; ok {
+		requested := types
+		if len(requested) == 0 {
+			for _, t := range domain.SearchResourceTypes {
+				requested = append(requested, string(t))
+			}
+		}
+		types = nil
+		for _, t := range requested {
+			scope, known := searchTypeScopes[domain.SearchResourceType(t)]
+			if known && !hasScope(authCtx.Scopes, scope) {
+				if len(req.Types) > 0 {
+					return nil, &fiberoapi.ErrorResponse{Code: fiber.StatusForbidden, Details: "missing required scope: " + string(scope), Type: "FORBIDDEN"}
+				}
+				continue
+			}
+			types = append(types, t)
+		}
+		if len(types) == 0 {
+			return &dtos.SearchResponse{Results: []dtos.SearchResult{}}, nil
+		}
+	}
+
+	result, err := ctrl.SearchApplication.Search(searchDto.SearchInput{
+		UserId:  authCtx.UserID,
+		Query:   req.Query,
+		SpaceId: req.SpaceId,
+		Types:   types,
+		Limit:   req.Limit,
+		Offset:  req.Offset,
+	})
+	if err != nil {
+		if errors.Is(err, apperrors.ErrInvalidInput) {
+			return nil, &fiberoapi.ErrorResponse{Code: fiber.StatusBadRequest, Details: err.Error(), Type: "BAD_REQUEST"}
+		}
+		logger.Error().Err(err).Msg("failed to search")
+		return nil, &fiberoapi.ErrorResponse{Code: fiber.StatusInternalServerError, Details: "Failed to search", Type: "INTERNAL_SERVER_ERROR"}
+	}
+
+	resp := &dtos.SearchResponse{Results: make([]dtos.SearchResult, len(result.Results))}
+	for i, r := range result.Results {
+		resp.Results[i] = dtos.SearchResult{
+			Type:       r.Type,
+			Id:         r.Id,
+			Title:      r.Title,
+			Snippet:    r.Snippet,
+			Icon:       r.Icon,
+			SpaceId:    r.SpaceId,
+			SpaceName:  r.SpaceName,
+			DocumentId: r.DocumentId,
+			Slug:       r.Slug,
+			DatabaseId: r.DatabaseId,
+			BlockId:    r.BlockId,
+			Rank:       r.Rank,
+			UpdatedAt:  r.UpdatedAt,
+		}
+	}
+
+	return resp, nil
+}
+
+func hasScope(scopes []string, scope domain.ApiKeyScope) bool {
+	for _, s := range scopes {
+		if s == string(scope) {
+			return true
+		}
+	}
+	return false
+}
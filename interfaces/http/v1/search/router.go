@@ -0,0 +1,14 @@
+package search
+
+import (
+	fiberoapi "github.com/labbs/fiber-oapi"
+)
+
+func SetupSearchRouter(ctrl Controller) {
+	fiberoapi.Get(ctrl.FiberOapi, "/", ctrl.Search, fiberoapi.OpenAPIOptions{
+		Summary:     "Search",
+		Description: "Full-text search of the documents, database rows, drawings and comments the user can read, best matches first. Each result has a snippet with the matched terms in <mark> elements and, for documents and comments, the matching block. API keys only search the types their read scopes cover.",
+		OperationID: "search.search",
+		Tags:        []string{"Search"},
+	})
+}
@@ -20,7 +20,7 @@ type ListSpacePermissionsResponse struct {
 type UpsertSpaceUserPermissionRequest struct {
 	SpaceId string `path:"space_id" validate:"required,uuid4" resource:"space" action:"write"`
 	UserId  string `json:"user_id" validate:"required,uuid4"`
-	Role    string `json:"role" validate:"required,oneof=owner admin editor viewer"`
+	Role    string `json:"role" validate:"required,oneof=owner admin editor commenter viewer"`
 }
 
 type UpsertSpaceUserPermissionResponse struct {
@@ -182,7 +182,7 @@ func (ctrl *Controller) UpsertUserPermission(ctx *fiber.Ctx, req dtos.UpsertSpac
 
 	role := req.Role
 	switch role {
-	case "owner", "admin", "editor", "viewer":
+	case "owner", "admin", "editor", "commenter", "viewer":
 		// valid role
 	default:
 		role = "viewer"
@@ -4,7 +4,9 @@ import (
 	fiberoapi "github.com/labbs/fiber-oapi"
 	"github.com/labbs/nexo/application/permission"
 	"github.com/labbs/nexo/application/space"
+	"github.com/labbs/nexo/domain"
 	"github.com/labbs/nexo/infrastructure/config"
+	"github.com/labbs/nexo/infrastructure/http"
 	"github.com/rs/zerolog"
 )
 
@@ -18,10 +20,12 @@ type Controller struct {
 
 func SetupSpaceRouter(controller Controller) {
 	fiberoapi.Post(controller.FiberOapi, "", controller.CreateSpace, fiberoapi.OpenAPIOptions{
-		Summary:     "Create a new space",
-		Description: "Create a new space for the authenticated user",
-		OperationID: "space.createSpace",
-		Tags:        []string{"Space"},
+		Summary:       "Create a new space",
+		Description:   "Create a new space for the authenticated user",
+		OperationID:   "space.createSpace",
+		Tags:          []string{"Space"},
+		RequiredRoles: http.MemberRoles(),
+		Security:      http.RequireScopes(domain.ApiKeyScopeWriteSpaces),
 	})
 
 	fiberoapi.Put(controller.FiberOapi, "/:space_id", controller.UpdateSpace, fiberoapi.OpenAPIOptions{
@@ -29,6 +33,7 @@ func SetupSpaceRouter(controller Controller) {
 		Description: "Update space properties",
 		OperationID: "space.updateSpace",
 		Tags:        []string{"Space"},
+		Security:    http.RequireScopes(domain.ApiKeyScopeWriteSpaces),
 	})
 
 	fiberoapi.Delete(controller.FiberOapi, "/:space_id", controller.DeleteSpace, fiberoapi.OpenAPIOptions{
@@ -36,6 +41,7 @@ func SetupSpaceRouter(controller Controller) {
 		Description: "Soft delete a space",
 		OperationID: "space.deleteSpace",
 		Tags:        []string{"Space"},
+		Security:    http.RequireScopes(domain.ApiKeyScopeWriteSpaces),
 	})
 
 	// Permissions (MVP: user-level)
@@ -44,6 +50,7 @@ func SetupSpaceRouter(controller Controller) {
 		Description: "List user permissions for a space",
 		OperationID: "space.listPermissions",
 		Tags:        []string{"Space"},
+		Security:    http.RequireScopes(domain.ApiKeyScopeReadSpaces),
 	})
 
 	fiberoapi.Put(controller.FiberOapi, "/:space_id/permissions", controller.UpsertUserPermission, fiberoapi.OpenAPIOptions{
@@ -51,6 +58,7 @@ func SetupSpaceRouter(controller Controller) {
 		Description: "Create or update a user's permission on a space",
 		OperationID: "space.upsertUserPermission",
 		Tags:        []string{"Space"},
+		Security:    http.RequireScopes(domain.ApiKeyScopeWriteSpaces),
 	})
 
 	fiberoapi.Delete(controller.FiberOapi, "/:space_id/permissions/:user_id", controller.DeleteUserPermission, fiberoapi.OpenAPIOptions{
@@ -58,5 +66,6 @@ func SetupSpaceRouter(controller Controller) {
 		Description: "Remove user's permission from a space",
 		OperationID: "space.deleteUserPermission",
 		Tags:        []string{"Space"},
+		Security:    http.RequireScopes(domain.ApiKeyScopeWriteSpaces),
 	})
 }
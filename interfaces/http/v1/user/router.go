@@ -7,6 +7,7 @@ import (
 	"github.com/labbs/nexo/application/user"
 	"github.com/labbs/nexo/domain"
 	"github.com/labbs/nexo/infrastructure/config"
+	"github.com/labbs/nexo/infrastructure/http"
 	"github.com/rs/zerolog"
 )
 
@@ -26,30 +27,35 @@ func SetupUserRouter(controller Controller) {
 		Description: "Retrieve the profile of the authenticated user",
 		OperationID: "user.getProfile",
 		Tags:        []string{"User"},
+		Security:    http.SessionOnly(),
 	})
 	fiberoapi.Get(controller.FiberOapi, "/my-spaces", controller.GetMySpaces, fiberoapi.OpenAPIOptions{
 		Summary:     "Get my spaces",
 		Description: "Retrieve the spaces of the authenticated user",
 		OperationID: "user.getMySpaces",
 		Tags:        []string{"User"},
+		Security:    http.RequireScopes(domain.ApiKeyScopeReadSpaces),
 	})
 	fiberoapi.Get(controller.FiberOapi, "/my-favorites", controller.GetMyFavorites, fiberoapi.OpenAPIOptions{
 		Summary:     "Get my favorites",
 		Description: "Retrieve the favorite items of the authenticated user",
 		OperationID: "user.getMyFavorites",
 		Tags:        []string{"User"},
+		Security:    http.RequireScopes(domain.ApiKeyScopeReadDocuments),
 	})
 	fiberoapi.Post(controller.FiberOapi, "/favorite/:space_id/:document_id", controller.AddFavorite, fiberoapi.OpenAPIOptions{
 		Summary:     "Add favorite",
 		Description: "Add a document to the user's favorites",
 		OperationID: "user.addFavorite",
 		Tags:        []string{"User"},
+		Security:    http.RequireScopes(domain.ApiKeyScopeWriteDocuments),
 	})
 	fiberoapi.Delete(controller.FiberOapi, "/favorite/:favorite_id", controller.RemoveFavorite, fiberoapi.OpenAPIOptions{
 		Summary:     "Remove favorite",
 		Description: "Remove a document from the user's favorites",
 		OperationID: "user.removeFavorite",
 		Tags:        []string{"User"},
+		Security:    http.RequireScopes(domain.ApiKeyScopeWriteDocuments),
 	})
 
 	fiberoapi.Put(controller.FiberOapi, "/favorite/:favorite_id/position", controller.UpdateFavoritePosition, fiberoapi.OpenAPIOptions{
@@ -57,6 +63,7 @@ func SetupUserRouter(controller Controller) {
 		Description: "Reorder favorites by updating a favorite's position",
 		OperationID: "user.updateFavoritePosition",
 		Tags:        []string{"User"},
+		Security:    http.RequireScopes(domain.ApiKeyScopeWriteDocuments),
 	})
 
 	// Profile management
@@ -65,12 +72,14 @@ func SetupUserRouter(controller Controller) {
 		Description: "Update the authenticated user's profile (username, avatar, preferences)",
 		OperationID: "user.updateProfile",
 		Tags:        []string{"User"},
+		Security:    http.SessionOnly(),
 	})
 	fiberoapi.Post(controller.FiberOapi, "/change-password", controller.ChangePassword, fiberoapi.OpenAPIOptions{
 		Summary:     "Change password",
 		Description: "Change the authenticated user's password",
 		OperationID: "user.changePassword",
 		Tags:        []string{"User"},
+		Security:    http.SessionOnly(),
 	})
 
 	// Space order preferences
@@ -79,6 +88,7 @@ func SetupUserRouter(controller Controller) {
 		Description: "Update the order of spaces in the sidebar (user preference)",
 		OperationID: "user.updateSpaceOrder",
 		Tags:        []string{"User", "Preferences"},
+		Security:    http.SessionOnly(),
 	})
 
 	fiberoapi.Get(controller.FiberOapi, "/list", controller.ListUsers, fiberoapi.OpenAPIOptions{
@@ -86,5 +96,6 @@ func SetupUserRouter(controller Controller) {
 		Description: "Get a simplified list of all users (id, username, avatar) for use in person pickers",
 		OperationID: "user.listUsers",
 		Tags:        []string{"User"},
+		Security:    http.SessionOnly(),
 	})
 }
@@ -34,6 +34,11 @@ type GetDeliveriesRequest struct {
 	Limit     int    `query:"limit"`
 }
 
+type RedeliverRequest struct {
+	WebhookId  string `path:"webhook_id"`
+	DeliveryId string `path:"delivery_id"`
+}
+
 // Response DTOs
 
 type MessageResponse struct {
@@ -86,18 +91,27 @@ type GetWebhookResponse struct {
 }
 
 type DeliveryItem struct {
-	Id         string    `json:"id"`
-	Event      string    `json:"event"`
-	StatusCode int       `json:"status_code"`
-	Success    bool      `json:"success"`
-	Duration   int       `json:"duration_ms"`
-	CreatedAt  time.Time `json:"created_at"`
+	Id            string     `json:"id"`
+	Event         string     `json:"event"`
+	Status        string     `json:"status"` // pending, delivering, retrying, succeeded, failed
+	Attempts      int        `json:"attempts"`
+	NextAttemptAt *time.Time `json:"next_attempt_at,omitempty"`
+	Error         string     `json:"error,omitempty"`
+	StatusCode    int        `json:"status_code"`
+	Success       bool       `json:"success"`
+	Duration      int        `json:"duration_ms"`
+	CreatedAt     time.Time  `json:"created_at"`
+	UpdatedAt     time.Time  `json:"updated_at"`
 }
 
 type GetDeliveriesResponse struct {
 	Deliveries []DeliveryItem `json:"deliveries"`
 }
 
+type RedeliverResponse struct {
+	DeliveryId string `json:"delivery_id"`
+}
+
 // Available events for reference
 type AvailableEventsResponse struct {
 	Events []EventInfo `json:"events"`
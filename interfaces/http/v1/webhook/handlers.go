@@ -2,43 +2,15 @@ package webhook
 
 import (
 	"errors"
-	"net"
-	"net/url"
 
 	"github.com/gofiber/fiber/v2"
 	fiberoapi "github.com/labbs/fiber-oapi"
 	"github.com/labbs/nexo/infrastructure/helpers/apperrors"
+	"github.com/labbs/nexo/infrastructure/helpers/safehttp"
 	webhookDto "github.com/labbs/nexo/application/webhook/dto"
 	"github.com/labbs/nexo/interfaces/http/v1/webhook/dtos"
 )
 
-// validateWebhookURL checks the URL is valid HTTP/HTTPS and not targeting internal networks.
-func validateWebhookURL(rawURL string) error {
-	u, err := url.ParseRequestURI(rawURL)
-	if err != nil {
-		return errors.New("invalid URL format")
-	}
-	if u.Scheme != "http" && u.Scheme != "https" {
-		return errors.New("URL must use http or https")
-	}
-	host := u.Hostname()
-	ips, err := net.LookupHost(host)
-	if err != nil {
-		// DNS failure — allow it (server may not have external DNS in all envs)
-		return nil
-	}
-	for _, ipStr := range ips {
-		ip := net.ParseIP(ipStr)
-		if ip == nil {
-			continue
-		}
-		if ip.IsLoopback() || ip.IsPrivate() || ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() {
-			return errors.New("URL must not target internal or private addresses")
-		}
-	}
-	return nil
-}
-
 func (ctrl *Controller) ListWebhooks(ctx *fiber.Ctx, _ dtos.EmptyRequest) (*dtos.ListWebhooksResponse, *fiberoapi.ErrorResponse) {
 	requestId := ctx.Locals("requestid").(string)
 	logger := ctrl.Logger.With().Str("request_id", requestId).Str("component", "http.api.v1.webhook.list").Logger()
@@ -95,7 +67,7 @@ func (ctrl *Controller) CreateWebhook(ctx *fiber.Ctx, req dtos.CreateWebhookRequ
 	if req.Url == "" {
 		return nil, &fiberoapi.ErrorResponse{Code: fiber.StatusBadRequest, Details: "URL is required", Type: "BAD_REQUEST"}
 	}
-	if err := validateWebhookURL(req.Url); err != nil {
+	if err := safehttp.ValidateURL(req.Url); err != nil {
 		return nil, &fiberoapi.ErrorResponse{Code: fiber.StatusBadRequest, Details: err.Error(), Type: "BAD_REQUEST"}
 	}
 
@@ -111,6 +83,9 @@ func (ctrl *Controller) CreateWebhook(ctx *fiber.Ctx, req dtos.CreateWebhookRequ
 		Events:  req.Events,
 	})
 	if err != nil {
+		if errors.Is(err, apperrors.ErrAccessDenied) {
+			return nil, &fiberoapi.ErrorResponse{Code: fiber.StatusForbidden, Details: "Forbidden", Type: "FORBIDDEN"}
+		}
 		logger.Error().Err(err).Msg("failed to create webhook")
 		return nil, &fiberoapi.ErrorResponse{Code: fiber.StatusInternalServerError, Details: "Failed to create webhook", Type: "INTERNAL_SERVER_ERROR"}
 	}
@@ -178,6 +153,12 @@ func (ctrl *Controller) UpdateWebhook(ctx *fiber.Ctx, req dtos.UpdateWebhookRequ
 		return nil, &fiberoapi.ErrorResponse{Code: fiber.StatusUnauthorized, Details: "Authentication required", Type: "AUTHENTICATION_REQUIRED"}
 	}
 
+	if req.Url != nil {
+		if err := safehttp.ValidateURL(*req.Url); err != nil {
+			return nil, &fiberoapi.ErrorResponse{Code: fiber.StatusBadRequest, Details: err.Error(), Type: "BAD_REQUEST"}
+		}
+	}
+
 	err = ctrl.WebhookApplication.UpdateWebhook(webhookDto.UpdateWebhookInput{
 		UserId:    authCtx.UserID,
 		WebhookId: req.WebhookId,
@@ -262,27 +243,70 @@ func (ctrl *Controller) GetDeliveries(ctx *fiber.Ctx, req dtos.GetDeliveriesRequ
 	resp := &dtos.GetDeliveriesResponse{Deliveries: make([]dtos.DeliveryItem, len(result.Deliveries))}
 	for i, d := range result.Deliveries {
 		resp.Deliveries[i] = dtos.DeliveryItem{
-			Id:         d.Id,
-			Event:      d.Event,
-			StatusCode: d.StatusCode,
-			Success:    d.Success,
-			Duration:   d.Duration,
-			CreatedAt:  d.CreatedAt,
+			Id:            d.Id,
+			Event:         d.Event,
+			Status:        d.Status,
+			Attempts:      d.Attempts,
+			NextAttemptAt: d.NextAttemptAt,
+			Error:         d.Error,
+			StatusCode:    d.StatusCode,
+			Success:       d.Success,
+			Duration:      d.Duration,
+			CreatedAt:     d.CreatedAt,
+			UpdatedAt:     d.UpdatedAt,
 		}
 	}
 
 	return resp, nil
 }
 
+func (ctrl *Controller) Redeliver(ctx *fiber.Ctx, req dtos.RedeliverRequest) (*dtos.RedeliverResponse, *fiberoapi.ErrorResponse) {
+	requestId := ctx.Locals("requestid").(string)
+	logger := ctrl.Logger.With().Str("request_id", requestId).Str("component", "http.api.v1.webhook.redeliver").Logger()
+
+	authCtx, err := fiberoapi.GetAuthContext(ctx)
+	if err != nil {
+		logger.Error().Err(err).Msg("failed to get auth context")
+		return nil, &fiberoapi.ErrorResponse{Code: fiber.StatusUnauthorized, Details: "Authentication required", Type: "AUTHENTICATION_REQUIRED"}
+	}
+
+	result, err := ctrl.WebhookApplication.Redeliver(webhookDto.RedeliverInput{
+		UserId:     authCtx.UserID,
+		WebhookId:  req.WebhookId,
+		DeliveryId: req.DeliveryId,
+	})
+	if err != nil {
+		if errors.Is(err, apperrors.ErrAccessDenied) {
+			return nil, &fiberoapi.ErrorResponse{Code: fiber.StatusForbidden, Details: "Forbidden", Type: "FORBIDDEN"}
+		}
+		if errors.Is(err, apperrors.ErrNotFound) || errors.Is(err, apperrors.ErrWebhookNotFound) {
+			return nil, &fiberoapi.ErrorResponse{Code: fiber.StatusNotFound, Details: "Delivery not found", Type: "NOT_FOUND"}
+		}
+		logger.Error().Err(err).Msg("failed to redeliver webhook")
+		return nil, &fiberoapi.ErrorResponse{Code: fiber.StatusInternalServerError, Details: "Failed to redeliver webhook", Type: "INTERNAL_SERVER_ERROR"}
+	}
+
+	return &dtos.RedeliverResponse{DeliveryId: result.DeliveryId}, nil
+}
+
 func (ctrl *Controller) GetAvailableEvents(ctx *fiber.Ctx, _ dtos.EmptyRequest) (*dtos.AvailableEventsResponse, *fiberoapi.ErrorResponse) {
 	events := []dtos.EventInfo{
 		{Event: "document.created", Description: "Triggered when a document is created"},
 		{Event: "document.updated", Description: "Triggered when a document is updated"},
 		{Event: "document.deleted", Description: "Triggered when a document is deleted"},
+		{Event: "document.moved", Description: "Triggered when a document is moved to another parent"},
+		{Event: "document.restored", Description: "Triggered when a document is restored from the trash"},
 		{Event: "comment.created", Description: "Triggered when a comment is created"},
+		{Event: "comment.updated", Description: "Triggered when a comment is edited"},
+		{Event: "comment.deleted", Description: "Triggered when a comment is deleted"},
 		{Event: "comment.resolved", Description: "Triggered when a comment is resolved"},
 		{Event: "space.created", Description: "Triggered when a space is created"},
 		{Event: "space.updated", Description: "Triggered when a space is updated"},
+		{Event: "space.deleted", Description: "Triggered when a space is deleted"},
+		{Event: "row.created", Description: "Triggered when a database row is created"},
+		{Event: "row.updated", Description: "Triggered when a database row is updated"},
+		{Event: "row.deleted", Description: "Triggered when a database row is deleted"},
+		{Event: "property.changed", Description: "Triggered once per property whose value changed in a row update"},
 	}
 
 	return &dtos.AvailableEventsResponse{Events: events}, nil
@@ -1,6 +1,10 @@
 package webhook
 
-import fiberoapi "github.com/labbs/fiber-oapi"
+import (
+	fiberoapi "github.com/labbs/fiber-oapi"
+	"github.com/labbs/nexo/domain"
+	"github.com/labbs/nexo/infrastructure/http"
+)
 
 func SetupWebhookRouter(ctrl Controller) {
 	fiberoapi.Get(ctrl.FiberOapi, "/", ctrl.ListWebhooks, fiberoapi.OpenAPIOptions{
@@ -8,6 +12,7 @@ func SetupWebhookRouter(ctrl Controller) {
 		Description: "List all webhooks for the authenticated user",
 		OperationID: "webhook.list",
 		Tags:        []string{"Webhooks"},
+		Security:    http.RequireScopes(domain.ApiKeyScopeManageWebhooks),
 	})
 
 	fiberoapi.Post(ctrl.FiberOapi, "/", ctrl.CreateWebhook, fiberoapi.OpenAPIOptions{
@@ -15,6 +20,7 @@ func SetupWebhookRouter(ctrl Controller) {
 		Description: "Create a new webhook",
 		OperationID: "webhook.create",
 		Tags:        []string{"Webhooks"},
+		Security:    http.RequireScopes(domain.ApiKeyScopeManageWebhooks),
 	})
 
 	fiberoapi.Get(ctrl.FiberOapi, "/events", ctrl.GetAvailableEvents, fiberoapi.OpenAPIOptions{
@@ -22,6 +28,7 @@ func SetupWebhookRouter(ctrl Controller) {
 		Description: "Get list of available webhook events",
 		OperationID: "webhook.events",
 		Tags:        []string{"Webhooks"},
+		Security:    http.RequireScopes(domain.ApiKeyScopeManageWebhooks),
 	})
 
 	fiberoapi.Get(ctrl.FiberOapi, "/:webhook_id", ctrl.GetWebhook, fiberoapi.OpenAPIOptions{
@@ -29,6 +36,7 @@ func SetupWebhookRouter(ctrl Controller) {
 		Description: "Get a specific webhook by ID",
 		OperationID: "webhook.get",
 		Tags:        []string{"Webhooks"},
+		Security:    http.RequireScopes(domain.ApiKeyScopeManageWebhooks),
 	})
 
 	fiberoapi.Put(ctrl.FiberOapi, "/:webhook_id", ctrl.UpdateWebhook, fiberoapi.OpenAPIOptions{
@@ -36,6 +44,7 @@ func SetupWebhookRouter(ctrl Controller) {
 		Description: "Update an existing webhook",
 		OperationID: "webhook.update",
 		Tags:        []string{"Webhooks"},
+		Security:    http.RequireScopes(domain.ApiKeyScopeManageWebhooks),
 	})
 
 	fiberoapi.Delete(ctrl.FiberOapi, "/:webhook_id", ctrl.DeleteWebhook, fiberoapi.OpenAPIOptions{
@@ -43,6 +52,7 @@ func SetupWebhookRouter(ctrl Controller) {
 		Description: "Delete a webhook",
 		OperationID: "webhook.delete",
 		Tags:        []string{"Webhooks"},
+		Security:    http.RequireScopes(domain.ApiKeyScopeManageWebhooks),
 	})
 
 	fiberoapi.Get(ctrl.FiberOapi, "/:webhook_id/deliveries", ctrl.GetDeliveries, fiberoapi.OpenAPIOptions{
@@ -50,5 +60,14 @@ func SetupWebhookRouter(ctrl Controller) {
 		Description: "Get delivery history for a webhook",
 		OperationID: "webhook.deliveries",
 		Tags:        []string{"Webhooks"},
+		Security:    http.RequireScopes(domain.ApiKeyScopeManageWebhooks),
+	})
+
+	fiberoapi.Post(ctrl.FiberOapi, "/:webhook_id/deliveries/:delivery_id/redeliver", ctrl.Redeliver, fiberoapi.OpenAPIOptions{
+		Summary:     "Redeliver webhook delivery",
+		Description: "Queue a new delivery with the payload of an existing one",
+		OperationID: "webhook.redeliver",
+		Tags:        []string{"Webhooks"},
+		Security:    http.RequireScopes(domain.ApiKeyScopeManageWebhooks),
 	})
 }